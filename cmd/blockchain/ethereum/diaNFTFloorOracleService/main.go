@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia/nft/nftOracle"
+	diaNFTOracleService "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaNFTOracleService"
+	restClient "github.com/diadata-org/diadata/pkg/http/restClient"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+// The feeder pushes the floor prices of the collections in config/nftOracle/<CONFIG_NAME>.json to the nft
+// oracle contract at DEPLOYED_CONTRACT and records each update in postgres.
+func main() {
+	key := utils.Getenv("PRIVATE_KEY", "")
+	keyPassword := utils.Getenv("PRIVATE_KEY_PASSWORD", "")
+	deployedContract := utils.Getenv("DEPLOYED_CONTRACT", "")
+	blockchainNode := utils.Getenv("BLOCKCHAIN_NODE", "")
+	configName := utils.Getenv("CONFIG_NAME", "NFTFloorOracle")
+	apiURL := utils.Getenv("DIA_API_URL", restClient.DefaultBaseURL)
+	frequencySeconds, err := strconv.Atoi(utils.Getenv("FREQUENCY_SECONDS", "120"))
+	if err != nil {
+		log.Fatalf("Failed to parse frequencySeconds: %v", err)
+	}
+	chainID, err := strconv.ParseInt(utils.Getenv("CHAIN_ID", "1"), 10, 64)
+	if err != nil {
+		log.Fatalf("Failed to parse chainId: %v", err)
+	}
+	if deployedContract == "" {
+		log.Fatal("DEPLOYED_CONTRACT is not set")
+	}
+
+	conf, err := nftoracle.LoadConfig(configName)
+	if err != nil {
+		log.Fatalf("Failed to load config %s: %v", configName, err)
+	}
+	signer, err := keystore.DecryptKey([]byte(key), keyPassword)
+	if err != nil {
+		log.Fatalf("Failed to decrypt private key: %v", err)
+	}
+
+	conn, err := ethclient.Dial(blockchainNode)
+	if err != nil {
+		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	}
+	contract, err := diaNFTOracleService.NewDIANFTOracle(common.HexToAddress(deployedContract), conn)
+	if err != nil {
+		log.Fatalf("Failed to bind contract: %v", err)
+	}
+	rdb, err := models.NewRelDataStore()
+	if err != nil {
+		log.Fatal("relational datastore error: ", err)
+	}
+
+	target := nftoracle.Target{Contract: contract, Backend: conn, Address: common.HexToAddress(deployedContract), ChainID: chainID}
+	feeder, err := nftoracle.NewFeeder(conf, restClient.NewClient(apiURL), target, rdb, signer.PrivateKey)
+	if err != nil {
+		log.Fatalf("Failed to create feeder: %v", err)
+	}
+
+	// Initial run.
+	update(feeder, len(conf.Collections))
+
+	// Afterwards, run every FREQUENCY_SECONDS.
+	ticker := time.NewTicker(time.Duration(frequencySeconds) * time.Second)
+	for range ticker.C {
+		update(feeder, len(conf.Collections))
+	}
+}
+
+// update pushes the floors of all collections which are due.
+func update(feeder *nftoracle.Feeder, numCollections int) {
+	updates, err := feeder.Update(context.Background(), time.Now())
+	if err != nil {
+		log.Error("update oracle: ", err)
+	}
+	log.Infof("pushed %d of %d collections", len(updates), numCollections)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia/rateOracle"
+	diaOracleServiceV2 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV2"
+	restClient "github.com/diadata-org/diadata/pkg/http/restClient"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+// The feeder pushes the rates of the feeds in config/rateOracle/<CONFIG_NAME>.json to the key value oracle
+// contract at DEPLOYED_CONTRACT.
+func main() {
+	key := utils.Getenv("PRIVATE_KEY", "")
+	keyPassword := utils.Getenv("PRIVATE_KEY_PASSWORD", "")
+	deployedContract := utils.Getenv("DEPLOYED_CONTRACT", "")
+	blockchainNode := utils.Getenv("BLOCKCHAIN_NODE", "")
+	configName := utils.Getenv("CONFIG_NAME", "RateOracle")
+	apiURL := utils.Getenv("DIA_API_URL", restClient.DefaultBaseURL)
+	frequencySeconds, err := strconv.Atoi(utils.Getenv("FREQUENCY_SECONDS", "300"))
+	if err != nil {
+		log.Fatalf("Failed to parse frequencySeconds: %v", err)
+	}
+	chainID, err := strconv.ParseInt(utils.Getenv("CHAIN_ID", "1"), 10, 64)
+	if err != nil {
+		log.Fatalf("Failed to parse chainId: %v", err)
+	}
+	if deployedContract == "" {
+		log.Fatal("DEPLOYED_CONTRACT is not set")
+	}
+
+	conf, err := rateoracle.LoadConfig(configName)
+	if err != nil {
+		log.Fatalf("Failed to load config %s: %v", configName, err)
+	}
+	auth, err := bind.NewTransactorWithChainID(strings.NewReader(key), keyPassword, big.NewInt(chainID))
+	if err != nil {
+		log.Fatalf("Failed to create authorized transactor: %v", err)
+	}
+
+	conn, err := ethclient.Dial(blockchainNode)
+	if err != nil {
+		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	}
+	contract, err := diaOracleServiceV2.NewDIAOracleV2(common.HexToAddress(deployedContract), conn)
+	if err != nil {
+		log.Fatalf("Failed to bind contract: %v", err)
+	}
+
+	feeder := rateoracle.NewFeeder(conf, restClient.NewClient(apiURL), contract, conn, auth)
+
+	// Initial run.
+	update(feeder, len(conf.Feeds))
+
+	// Afterwards, run every FREQUENCY_SECONDS.
+	ticker := time.NewTicker(time.Duration(frequencySeconds) * time.Second)
+	for range ticker.C {
+		update(feeder, len(conf.Feeds))
+	}
+}
+
+// update pushes the rates of all feeds which are due.
+func update(feeder *rateoracle.Feeder, numFeeds int) {
+	updates, err := feeder.Update(context.Background(), time.Now())
+	if err != nil {
+		log.Error("update oracle: ", err)
+	}
+	log.Infof("pushed %d of %d feeds", len(updates), numFeeds)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"github.com/diadata-org/diadata/internal/pkg/defiscrapers"
+	"strings"
 	"sync"
 
 	"github.com/diadata-org/diadata/pkg/dia"
@@ -46,9 +47,14 @@ func handleDefiState(c chan *dia.DefiProtocolState, wg *sync.WaitGroup, ds model
 }
 
 func main() {
-	rateType := flag.String("type", "DYDX", "Type of Defi rate")
+	rateType := flag.String("type", "DYDX", "Comma separated names of the protocols in config/defiProtocols.json")
 	flag.Parse()
 
+	protocols, err := defiscrapers.LoadProtocolConfigs(strings.Split(*rateType, ",")...)
+	if err != nil {
+		log.Fatal("load protocol config: ", err)
+	}
+
 	wg := sync.WaitGroup{}
 	ds, err := models.NewDataStore()
 
@@ -56,7 +62,10 @@ func main() {
 		log.Errorln("NewDataStore:", err)
 	} else {
 
-		sRate := defiscrapers.SpawnDefiScraper(ds, *rateType)
+		sRate, err := defiscrapers.SpawnDefiScraper(ds, protocols)
+		if err != nil {
+			log.Fatal("spawn defi scraper: ", err)
+		}
 		defer func() {
 			err := sRate.Close()
 			if err != nil {
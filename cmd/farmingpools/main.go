@@ -45,6 +45,9 @@ func handlerate(c chan *models.FarmingPool, wg *sync.WaitGroup, ds models.Datast
 			log.Error("error")
 			return
 		}
+		if err := pool.NormaliseYield(ds, pr); err != nil {
+			log.Warnf("normalising yield of pool %s on %s: %v", pr.PoolID, pr.ProtocolName, err)
+		}
 		log.Print("Write pool info: ", pr)
 		err := ds.SetFarmingPool(pr)
 		if err != nil {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/utils"
@@ -70,6 +71,8 @@ const (
 	cachingTimeShort  = time.Minute * 2
 	// cachingTimeMedium = time.Minute * 10
 	cachingTimeLong = time.Minute * 100
+
+	apiUsageFlushInterval = time.Minute * 5
 )
 
 var identityKey = "id"
@@ -195,8 +198,54 @@ func main() {
 		RelDB:     *relStore,
 	}
 
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware.MiddlewareFunc())
+	{
+		admin.POST("/apiKeys", diaApiEnv.PostAPIKey)
+		admin.DELETE("/apiKeys/:keyID", diaApiEnv.RevokeAPIKey)
+		admin.GET("/apiKeys/:keyID/usage", diaApiEnv.GetAPIKeyUsage)
+		admin.POST("/apiPlans", diaApiEnv.PostAPIPlan)
+	}
+
+	apiKeyRequired, err := strconv.ParseBool(utils.Getenv("API_KEY_REQUIRED", "false"))
+	if err != nil {
+		log.Error("parse API_KEY_REQUIRED: ", err)
+	}
+	// Anonymous callers are limited by IP, which is only taken from X-Forwarded-For behind these proxies.
+	trustedProxies, err := diaApi.ParseTrustedProxies(utils.Getenv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatal("parse TRUSTED_PROXIES: ", err)
+	}
+	go diaApiEnv.FlushAPIUsage(apiUsageFlushInterval)
+
+	registerAPIRoutes(r, diaApiEnv, memoryStore, authMiddleware.MiddlewareFunc(), diaApiEnv.APIKeyAuth(apiKeyRequired, trustedProxies))
+
+	r.Use(static.Serve("/v1/chart", static.LocalFile("/charts", true)))
+
+	AddEndpoints(r)
+
+	// This environment variable is either set in docker-compose or empty
+	executionMode := utils.Getenv("EXEC_MODE", "")
+	if executionMode == "production" {
+		err = r.Run(utils.Getenv("LISTEN_PORT", ":8080"))
+		if err != nil {
+			log.Error(err)
+		}
+	} else {
+		err = r.Run(":8081")
+		if err != nil {
+			log.Error(err)
+		}
+
+	}
+
+}
+
+// registerAPIRoutes registers the /v1 routes. @auth protects the routes writing data,
+// @apiKeyAuth identifies and rate limits the callers of all public routes.
+func registerAPIRoutes(r *gin.Engine, diaApiEnv *diaApi.Env, memoryStore persistence.CacheStore, auth gin.HandlerFunc, apiKeyAuth gin.HandlerFunc) {
 	diaAuth := r.Group("/v1")
-	diaAuth.Use(authMiddleware.MiddlewareFunc())
+	diaAuth.Use(auth)
 	{
 		diaAuth.POST("/supply", diaApiEnv.PostSupply)
 		diaAuth.POST("/indexRebalance/:symbol", diaApiEnv.PostIndexRebalance)
@@ -204,10 +253,15 @@ func main() {
 	}
 
 	diaGroup := r.Group("/v1")
+	diaGroup.Use(apiKeyAuth)
 	{
+		diaGroup.GET("/openapi.json", diaApi.OpenAPIHandler(r))
+
 		// Endpoints for cryptocurrencies/exchanges
 		diaGroup.GET("/quotation/:symbol", cache.CachePageAtomic(memoryStore, cachingTime20Secs, diaApiEnv.GetQuotation))
 		diaGroup.GET("/assetQuotation/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTime20Secs, diaApiEnv.GetAssetQuotation))
+		diaGroup.POST("/assetQuotations", diaApiEnv.PostAssetQuotations)
+		diaGroup.GET("/assetQuotationAt/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTime20Secs, diaApiEnv.GetAssetQuotationAt))
 		diaGroup.GET("/lastTrades/:symbol", diaApiEnv.GetLastTrades)
 		diaGroup.GET("/lastTradesAsset/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetLastTradesAsset))
 		diaGroup.GET("/supply/:symbol", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetSupply))
@@ -231,6 +285,15 @@ func main() {
 		diaGroup.GET("/defiLendingRate/:protocol/:asset/:time", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetDefiRate))
 		diaGroup.GET("/defiLendingState/:protocol", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetDefiState))
 		diaGroup.GET("/defiLendingState/:protocol/:time", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetDefiState))
+		diaGroup.GET("/defiCollateralAtRisk/:protocol/:asset", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetDefiCollateralAtRisk))
+		diaGroup.GET("/defiHealthFactors/:protocol", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetDefiHealthFactors))
+		diaGroup.GET("/defiLiquidations/:protocol", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetDefiLiquidations))
+		diaGroup.GET("/defiMakerDAOIlks", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetMakerDAOIlks))
+		diaGroup.GET("/defiMakerDAOIlk/:ilk", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetMakerDAOIlk))
+		diaGroup.GET("/defiMakerDAOVaults/:ilk", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetMakerDAOVaults))
+		diaGroup.GET("/defiMakerDAOSavings", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetMakerDAOSavings))
+		diaGroup.GET("/defiTVL/:protocol", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetDefiTVL))
+		diaGroup.GET("/defiTVLAssets/:protocol", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetDefiTVLAssets))
 
 		diaGroup.GET("/missingToken/:exchange", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetMissingExchangeSymbol))
 		diaGroup.GET("/token/:symbol", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetAsset))
@@ -238,11 +301,17 @@ func main() {
 
 		diaGroup.GET("/blockchains", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetAllBlockchains))
 
+		diaGroup.GET("/farmingPools", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPools))
+		diaGroup.GET("/farmingPoolData/:protocol/:poolID", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPoolData))
+		diaGroup.GET("/farmingPoolData/:protocol/:poolID/:time", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPoolData))
+
+		diaGroup.GET("/cryptoDerivatives/:type/:name", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCryptoDerivative))
+
+		// Deprecated: capitalized aliases kept for existing clients.
 		diaGroup.GET("/FarmingPools", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPools))
 		diaGroup.GET("/FarmingPoolData/:protocol/:poolID", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPoolData))
 		diaGroup.GET("/FarmingPoolData/:protocol/:poolID/:time", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPoolData))
-
-		diaGroup.GET("CryptoDerivatives/:type/:name", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCryptoDerivative))
+		diaGroup.GET("/CryptoDerivatives/:type/:name", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCryptoDerivative))
 
 		// Endpoints for interestrates
 		diaGroup.GET("/interestrates", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetRates))
@@ -250,6 +319,8 @@ func main() {
 		diaGroup.GET("/interestrate/:symbol/:time", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetInterestRate))
 		diaGroup.GET("/compoundedRate/:symbol/:dpy", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedRate))
 		diaGroup.GET("/compoundedRate/:symbol/:dpy/:time", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedRate))
+		diaGroup.GET("/compoundedRateInArrears/:symbol/:dpy", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedRateInArrears))
+		diaGroup.GET("/compoundedRateFallback/:ibor/:tenor", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetIBORFallbackRate))
 		diaGroup.GET("/compoundedAvg/:symbol/:days/:dpy", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedAvg))
 		diaGroup.GET("/compoundedAvg/:symbol/:days/:dpy/:time", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedAvg))
 		diaGroup.GET("/compoundedAvgDIA/:symbol/:days/:dpy", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedAvgDIA))
@@ -292,29 +363,16 @@ func main() {
 		diaGroup.GET("/NFTTrades/:blockchain/:address/:id", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTTrades))
 		diaGroup.GET("/NFTTradesCurrent/:blockchain/:address/:id", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTTradesCurrent))
 		diaGroup.GET("/NFTFloor/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTFloor))
+		diaGroup.GET("/NFTValuation/:blockchain/:address/:id", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTValuation))
+		diaGroup.GET("/NFTRarity/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTClassRarity))
+		diaGroup.GET("/NFTRarity/:blockchain/:address/:id", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTRarity))
+		diaGroup.GET("/NFTMarketStats/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTMarketStats))
+		diaGroup.GET("/NFTOrders/:blockchain/:address/:id", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetNFTOrders))
+		diaGroup.GET("/NFTBestBidOffer/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetNFTBestBidOffer))
+		diaGroup.GET("/NFTOracleUpdates/:chainID/:oracle/:key", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetNFTOracleUpdates))
 		diaGroup.GET("/NFTFloorMA/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTFloorMA))
 		diaGroup.GET("/NFTDownday/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetNFTDownday))
 		diaGroup.GET("/feedStats/:blockchain/:address", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetFeedStats))
 
 	}
-
-	r.Use(static.Serve("/v1/chart", static.LocalFile("/charts", true)))
-
-	AddEndpoints(r)
-
-	// This environment variable is either set in docker-compose or empty
-	executionMode := utils.Getenv("EXEC_MODE", "")
-	if executionMode == "production" {
-		err = r.Run(utils.Getenv("LISTEN_PORT", ":8080"))
-		if err != nil {
-			log.Error(err)
-		}
-	} else {
-		err = r.Run(":8081")
-		if err != nil {
-			log.Error(err)
-		}
-
-	}
-
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/http/restServer/diaApi"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-contrib/cache/persistence"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// newTestRelDB returns a RelDB on a fresh schema of the database at POSTGRES_TEST_URL initialised with pginit.sql.
+// The test is skipped if POSTGRES_TEST_URL is not set.
+func newTestRelDB(t *testing.T) *models.RelDB {
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+	schema := fmt.Sprintf("restserver_test_%d", time.Now().UnixNano())
+	if _, err = admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+		admin.Close(ctx)
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatalf("parse postgres url: %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	pool, err := pgxpool.ConnectConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	init, err := ioutil.ReadFile("../../../deployments/config/pginit.sql")
+	if err != nil {
+		t.Fatalf("read pginit.sql: %v", err)
+	}
+	// The extension is shared by all schemas of the database and may exist already.
+	initSQL := strings.Replace(string(init), `CREATE EXTENSION "pgcrypto";`, `CREATE EXTENSION IF NOT EXISTS "pgcrypto";`, 1)
+	if _, err = pool.Exec(ctx, initSQL); err != nil {
+		t.Fatalf("load pginit.sql: %v", err)
+	}
+	return models.NewPostgresDataStoreWithPool(pool)
+}
+
+// testQuotationStore returns the quotation @price for every asset.
+type testQuotationStore struct {
+	models.Datastore
+	price float64
+}
+
+func (s *testQuotationStore) GetAssetQuotation(asset dia.Asset, timestamp time.Time) (*models.AssetQuotation, error) {
+	return &models.AssetQuotation{Asset: asset, Price: s.price, Source: dia.Diadata, Time: timestamp}, nil
+}
+
+// TestLPTokenQuotation checks that the price of a registered LP token is served by the asset quotation endpoint.
+func TestLPTokenQuotation(t *testing.T) {
+	relDB := newTestRelDB(t)
+	pool := &dia.Pool{
+		Exchange:   dia.UniswapExchange,
+		Blockchain: dia.ETHEREUM,
+		Address:    "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc",
+		LPToken: dia.Asset{
+			Symbol:     "UNI-V2",
+			Name:       "Uniswap V2",
+			Address:    "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc",
+			Decimals:   18,
+			Blockchain: dia.ETHEREUM,
+		},
+	}
+	// Registering a known LP token again is not an error.
+	for i := 0; i < 2; i++ {
+		if err := relDB.SetLPToken(pool); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	noop := func(c *gin.Context) {}
+	env := &diaApi.Env{DataStore: &testQuotationStore{price: 52.3}, RelDB: *relDB}
+	registerAPIRoutes(r, env, persistence.NewInMemoryStore(time.Second), noop, noop)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/assetQuotation/"+dia.ETHEREUM+"/"+pool.LPToken.Address, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status was incorrect, got: %v, want: %v. body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var quotation models.AssetQuotationFull
+	if err := json.Unmarshal(w.Body.Bytes(), &quotation); err != nil {
+		t.Fatal(err)
+	}
+	if quotation.Symbol != "UNI-V2" || quotation.Address != pool.LPToken.Address || quotation.Price != 52.3 {
+		t.Errorf("quotation was incorrect, got: %+v.", quotation)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/http/openapi"
+	"github.com/diadata-org/diadata/pkg/http/restServer/diaApi"
+	"github.com/gin-contrib/cache/persistence"
+	"github.com/gin-gonic/gin"
+)
+
+// TestOpenAPISpecCoversRoutes fails if a route is registered without an entry in diaApi.OpenAPISpec.
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	noop := func(c *gin.Context) {}
+	registerAPIRoutes(r, &diaApi.Env{}, persistence.NewInMemoryStore(time.Second), noop, noop)
+
+	for _, key := range diaApi.OpenAPISpec.Missing(r.Routes()) {
+		t.Errorf("route %s has no entry in diaApi.OpenAPISpec", key)
+	}
+
+	doc, err := diaApi.OpenAPISpec.Document(r.Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Errorf("marshal openapi document: %v", err)
+	}
+}
+
+// TestOpenAPISpecHasNoStaleEntries fails if the spec documents a route that is not registered.
+func TestOpenAPISpecHasNoStaleEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	noop := func(c *gin.Context) {}
+	registerAPIRoutes(r, &diaApi.Env{}, persistence.NewInMemoryStore(time.Second), noop, noop)
+
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		registered[openapi.Key(route.Method, route.Path)] = true
+	}
+	for key := range diaApi.OpenAPISpec.Routes {
+		if !registered[key] {
+			t.Errorf("spec entry %s does not belong to a registered route", key)
+		}
+	}
+}
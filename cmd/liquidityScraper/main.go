@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	scrapers "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers"
+	liquidityscrapers "github.com/diadata-org/diadata/pkg/dia/scraper/liquidity-scrapers"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// Reads the pools of an AMM, stores their states and the fair prices of their LP tokens. LP tokens are
+// registered as assets, such that their prices are served by the asset quotation endpoint.
+func main() {
+	exchangeName := flag.String("exchange", dia.UniswapExchange, "Name of the AMM whose pools are read")
+	period := flag.Duration("period", 10*time.Minute, "Period between two reads of all pools")
+	flag.Parse()
+
+	exchange, ok := scrapers.Exchanges[*exchangeName]
+	if !ok {
+		log.Fatalf("unknown exchange %s", *exchangeName)
+	}
+	datastore, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("datastore: ", err)
+	}
+	relDB, err := models.NewPostgresDataStore()
+	if err != nil {
+		log.Fatal("relational datastore: ", err)
+	}
+	scraper, err := liquidityscrapers.NewLiquidityScraper(exchange, *period)
+	if err != nil {
+		log.Fatal("liquidity scraper: ", err)
+	}
+	defer func() {
+		if err := scraper.Close(); err != nil {
+			log.Error(err)
+		}
+	}()
+
+	for pool := range scraper.PoolChannel() {
+		if err := datastore.SetLiquidityPool(pool); err != nil {
+			log.Errorf("store pool %s: %v", pool.Address, err)
+		}
+		if err := relDB.SetLPToken(pool); err != nil {
+			log.Errorf("register LP token of pool %s: %v", pool.Address, err)
+			continue
+		}
+		quotation, err := models.SetLPTokenQuotation(datastore, pool)
+		if err != nil {
+			log.Warnf("price of LP token of pool %s: %v", pool.Address, err)
+			continue
+		}
+		log.Infof("fair price of %s on %s: %v", pool.LPToken.Symbol, pool.Exchange, quotation.Price)
+	}
+}
@@ -37,8 +37,9 @@ func main() {
 		}
 	}
 
-	wg.Add(1)
+	wg.Add(2)
 	go handleBids(scraper.GetBidChannel(), &wg, rdb)
+	go handleOrders(scraper.GetOrderChannel(), &wg, rdb)
 	defer wg.Wait()
 
 }
@@ -69,3 +70,23 @@ func handleBids(bidChannel chan dia.NFTBid, wg *sync.WaitGroup, rdb *models.RelD
 		}
 	}
 }
+
+// handleOrders applies the order events of the scraper to the order book.
+func handleOrders(orderChannel chan dia.NFTOrderEvent, wg *sync.WaitGroup, rdb *models.RelDB) {
+	defer wg.Done()
+	for {
+		event, ok := <-orderChannel
+		if !ok {
+			log.Error("order channel closed")
+			return
+		}
+		applied, err := rdb.ApplyNFTOrderEvent(event)
+		if err != nil {
+			log.Errorf("apply %s event of order %s in tx %s: %v", event.Type, event.OrderID, event.TxHash, err)
+			continue
+		}
+		if applied {
+			log.Infof("order %s %s in tx %s", event.OrderID, event.Type, event.TxHash)
+		}
+	}
+}
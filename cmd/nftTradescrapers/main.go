@@ -62,10 +62,45 @@ func main() {
 	case "LooksRare":
 		log.Println("NFT Trades Scraper: Start scraping trades from LooksRare")
 		scraper = nfttradescrapers.NewLooksRareScraper(rdb)
+	case "Flow":
+		// The contracts scraped on Flow are listed in the config.
+		conf, err := nfttradescrapers.LoadFlowNFTConfig(*scraperType)
+		if err != nil {
+			log.Fatalf("load config of %s: %v", *scraperType, err)
+		}
+		var ds models.Datastore
+		if datastore, err := models.NewDataStore(); err != nil {
+			log.Error("datastore error, trades are stored without usd price: ", err)
+		} else {
+			ds = datastore
+		}
+		log.Printf("NFT Trades Scraper: Start scraping trades of %d contracts on Flow", len(conf.Contracts))
+		flowScraper := nfttradescrapers.NewFlowScraper(rdb, ds, conf)
+		if flowScraper == nil {
+			log.Fatalf("could not initialize scraper for %s", conf.Name)
+		}
+		scraper = flowScraper
 	default:
-		for {
-			time.Sleep(24 * time.Hour)
+		// Marketplaces without a dedicated scraper are scraped by the generic scraper if they have a config.
+		conf, err := nfttradescrapers.LoadNFTMarketplaceConfig(*scraperType)
+		if err != nil {
+			log.Errorf("no scraper for %s: %v", *scraperType, err)
+			for {
+				time.Sleep(24 * time.Hour)
+			}
+		}
+		var ds models.Datastore
+		if datastore, err := models.NewDataStore(); err != nil {
+			log.Error("datastore error, trades are stored without usd price: ", err)
+		} else {
+			ds = datastore
+		}
+		log.Printf("NFT Trades Scraper: Start scraping trades from %s", conf.Name)
+		genericScraper := nfttradescrapers.NewGenericScraper(rdb, ds, conf)
+		if genericScraper == nil {
+			log.Fatalf("could not initialize scraper for %s", conf.Name)
 		}
+		scraper = genericScraper
 	}
 
 	wg.Add(1)
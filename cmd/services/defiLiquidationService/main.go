@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	log           = logrus.New()
+	protocolNames *string
+	period        *time.Duration
+	startBlock    *uint64
+	blockBatch    *uint64
+	confirmations *uint64
+	refresh       *time.Duration
+)
+
+// positionState is the scraper state of the position indexing of a protocol.
+type positionState struct {
+	LastBlock uint64 `json:"last_block"`
+}
+
+// protocolIndexer indexes the positions of a protocol instance.
+type protocolIndexer struct {
+	conf    defiscrapers.ProtocolConfig
+	indexer defiscrapers.PositionIndexer
+	client  *ethclient.Client
+	// lastRefresh is the time the positions of all borrowers were last read.
+	lastRefresh time.Time
+}
+
+func init() {
+	protocolNames = flag.String("protocols", "AAVEv2,COMPOUND", "Comma separated names of the protocols in config/defiProtocols.json")
+	period = flag.Duration("period", 10*time.Minute, "Period between two updates of the positions of all protocols")
+	startBlock = flag.Uint64("startBlock", 0, "Block from which on events are scanned for protocols without position state")
+	blockBatch = flag.Uint64("blockBatch", 2000, "Number of blocks scanned for events per request")
+	confirmations = flag.Uint64("confirmations", 12, "Number of confirmations before events are indexed")
+	refresh = flag.Duration("refresh", 6*time.Hour, "Period between two reads of the positions of all borrowers, which updates the accrued interest")
+	flag.Parse()
+}
+
+func main() {
+
+	relDB, err := models.NewRelDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to asset DB: ", err)
+		return
+	}
+	datastore, err := models.NewDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to data store: ", err)
+		return
+	}
+	protocols, err := defiscrapers.LoadProtocolConfigs(strings.Split(*protocolNames, ",")...)
+	if err != nil {
+		log.Fatal("load protocol config: ", err)
+	}
+
+	var indexers []protocolIndexer
+	for _, conf := range protocols {
+		indexer, client, err := defiscrapers.NewPositionIndexer(context.Background(), conf)
+		if err != nil {
+			log.Fatalf("position indexer of %s: %v", conf.Name, err)
+		}
+		indexers = append(indexers, protocolIndexer{conf: conf, indexer: indexer, client: client})
+	}
+
+	// Initial run.
+	updatePositions(relDB, datastore, indexers)
+
+	// Afterwards, run every @period.
+	ticker := time.NewTicker(*period)
+	for range ticker.C {
+		updatePositions(relDB, datastore, indexers)
+	}
+}
+
+func updatePositions(relDB *models.RelDB, datastore *models.DB, indexers []protocolIndexer) {
+	for i := range indexers {
+		p := &indexers[i]
+		if err := indexPositions(relDB, datastore, *p); err != nil {
+			log.Errorf("index positions of %s: %v", p.conf.Name, err)
+			continue
+		}
+		if time.Since(p.lastRefresh) < *refresh {
+			continue
+		}
+		if err := refreshPositions(relDB, *p); err != nil {
+			log.Errorf("refresh positions of %s: %v", p.conf.Name, err)
+			continue
+		}
+		p.lastRefresh = time.Now()
+	}
+}
+
+// indexPositions applies the events of the protocol since the last indexed block to the positions and stores
+// its liquidations. The last indexed block is kept as scraper state per protocol and stored in the same
+// transaction as the events of each batch, such that indexing resumes after a restart without applying
+// events twice.
+func indexPositions(relDB *models.RelDB, datastore *models.DB, p protocolIndexer) error {
+	ctx := context.Background()
+	stateName := fmt.Sprintf("defiPositions-%s", p.conf.Name)
+
+	state := &positionState{}
+	if err := relDB.GetScraperState(ctx, stateName, state); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		log.Infof("no position state for %s, scanning from block %d", p.conf.Name, *startBlock)
+		if *startBlock > 0 {
+			state.LastBlock = *startBlock - 1
+		}
+	}
+
+	head, err := p.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if head < *confirmations {
+		return nil
+	}
+	head -= *confirmations
+
+	prices := make(map[string]float64)
+	for from := state.LastBlock + 1; from <= head; from += *blockBatch {
+		to := from + *blockBatch - 1
+		if to > head {
+			to = head
+		}
+		changes, liquidations, err := p.indexer.IndexPositions(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		for i := range liquidations {
+			liquidations[i].ValueUSD = liquidations[i].DebtAmount * assetPrice(datastore, prices, liquidations[i].DebtAsset)
+		}
+		state.LastBlock = to
+		if err := relDB.SetDefiPositionEvents(p.conf.Name, changes, liquidations, stateName, state); err != nil {
+			return err
+		}
+		if len(liquidations) > 0 {
+			log.Infof("%d liquidations on %s in blocks %d to %d", len(liquidations), p.conf.Name, from, to)
+		}
+	}
+	return nil
+}
+
+// refreshPositions reads the positions of all borrowers of the protocol at the last confirmed block, such that
+// the interest accrued since their last change is included.
+func refreshPositions(relDB *models.RelDB, p protocolIndexer) error {
+	ctx := context.Background()
+	positions, err := relDB.GetDefiBorrowerPositions(p.conf.Name)
+	if err != nil {
+		return err
+	}
+	head, err := p.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if head < *confirmations {
+		return nil
+	}
+	changes, err := p.indexer.ReadPositions(ctx, positions, head-*confirmations)
+	if err != nil {
+		return err
+	}
+	log.Infof("refreshed %d positions on %s", len(changes), p.conf.Name)
+	return relDB.UpdateDefiPositions(p.conf.Name, changes)
+}
+
+// assetPrice returns the latest price of @asset in USD, or zero if there is none. Prices are cached in @prices.
+func assetPrice(datastore *models.DB, prices map[string]float64, asset dia.Asset) float64 {
+	price, ok := prices[asset.Address]
+	if !ok {
+		quotation, err := datastore.GetAssetQuotationLatest(asset)
+		if err != nil {
+			log.Warnf("price of %s: %v", asset.Symbol, err)
+		} else {
+			price = quotation.Price
+		}
+		prices[asset.Address] = price
+	}
+	return price
+}
@@ -0,0 +1,10 @@
+module github.com/diadata-org/diadata/services/nftMarketStatsService
+
+go 1.14
+
+require (
+	github.com/diadata-org/diadata v1.4.0
+	github.com/ethereum/go-ethereum v1.10.10
+	github.com/jackc/pgx/v4 v4.11.0
+	github.com/sirupsen/logrus v1.8.1
+)
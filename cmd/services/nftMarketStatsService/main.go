@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	log           = logrus.New()
+	blockchain    *string
+	period        *time.Duration
+	startBlock    *uint64
+	blockBatch    *uint64
+	maxBlocks     *uint64
+	confirmations *uint64
+
+	transferEventID       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleEventID = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchEventID  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+	// transferBatchData are the unindexed fields of an ERC1155 TransferBatch log.
+	transferBatchData abi.Arguments
+)
+
+// holderState is the scraper state of the holder tracking of a collection.
+type holderState struct {
+	LastBlock uint64 `json:"last_block"`
+}
+
+func init() {
+	blockchain = flag.String("blockchain", dia.ETHEREUM, "Blockchain of the nft collections")
+	period = flag.Duration("period", time.Hour, "Period between two updates of the market statistics of all collections")
+	startBlock = flag.Uint64("startBlock", 0, "Block from which on transfers are scanned for collections without holder state. If zero, the deploy block of the collection is used")
+	blockBatch = flag.Uint64("blockBatch", 5000, "Number of blocks scanned for transfers per request")
+	maxBlocks = flag.Uint64("maxBlocks", 500000, "Maximal number of blocks scanned per collection and period, such that a collection catching up does not hold up the others")
+	confirmations = flag.Uint64("confirmations", 12, "Number of confirmations before transfers are counted")
+	flag.Parse()
+
+	uint256Array, err := abi.NewType("uint256[]", "", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	transferBatchData = abi.Arguments{{Name: "ids", Type: uint256Array}, {Name: "values", Type: uint256Array}}
+}
+
+func main() {
+
+	relDB, err := models.NewRelDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to asset DB: ", err)
+		return
+	}
+	client, err := ethclient.Dial(utils.Getenv("ETH_URI_REST", ""))
+	if err != nil {
+		log.Errorln("Error connecting to node: ", err)
+		return
+	}
+
+	// Initial run.
+	updateNFTClasses(relDB, client, *blockchain)
+
+	// Afterwards, run every @period.
+	ticker := time.NewTicker(*period)
+	for range ticker.C {
+		updateNFTClasses(relDB, client, *blockchain)
+	}
+}
+
+// updateNFTClasses updates the holders of all collections on @blockchain and stores the market statistics
+// of the last completed hour and day.
+func updateNFTClasses(relDB *models.RelDB, client *ethclient.Client, blockchain string) {
+	nftClasses, err := relDB.GetAllNFTClasses(blockchain)
+	if err != nil {
+		log.Error("get nft classes: ", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, nftClass := range nftClasses {
+		synced, err := updateHolders(relDB, client, nftClass)
+		if err != nil {
+			log.Errorf("update holders of nft class %s: %v", nftClass.Address, err)
+		}
+		for _, interval := range []string{models.NFTMarketStatsHourly, models.NFTMarketStatsDaily} {
+			duration, err := models.NFTMarketStatsPeriod(interval)
+			if err != nil {
+				log.Fatal(err)
+			}
+			starttime := now.Truncate(duration).Add(-duration)
+			stats, err := relDB.ComputeNFTClassMarketStats(nftClass, interval, starttime)
+			if err != nil {
+				log.Warnf("compute %s market stats of nft class %s: %v", interval, nftClass.Address, err)
+				continue
+			}
+			// The balances of a collection still catching up are incomplete, the stored holder count is kept.
+			if !synced {
+				stats.HolderCount = 0
+			}
+			if err := relDB.SetNFTMarketStats(stats); err != nil {
+				log.Errorf("set %s market stats of nft class %s: %v", interval, nftClass.Address, err)
+			}
+		}
+	}
+	log.Infof("updated market stats of %d nft classes", len(nftClasses))
+}
+
+// updateHolders applies the ERC721 and ERC1155 transfers of @nftClass since the last scanned block to the holder
+// balances. At most maxBlocks blocks are scanned per call. The last scanned block is kept as scraper state per
+// collection and stored along with the balances of each batch, such that scanning resumes after a restart.
+// Collections without state are scanned from their deploy block. It returns true if the balances are up to date.
+func updateHolders(relDB *models.RelDB, client *ethclient.Client, nftClass dia.NFTClass) (synced bool, err error) {
+	ctx := context.Background()
+	stateName := fmt.Sprintf("nftHolders-%s-%s", nftClass.Blockchain, nftClass.Address)
+	contract := common.HexToAddress(nftClass.Address)
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	if head < *confirmations {
+		return
+	}
+	head -= *confirmations
+
+	state := &holderState{}
+	if err = relDB.GetScraperState(ctx, stateName, state); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return
+		}
+		first := *startBlock
+		if first == 0 {
+			first, err = deployBlock(ctx, client, contract, head)
+			if err != nil {
+				log.Warnf("deploy block of nft class %s: %v", nftClass.Address, err)
+			}
+		}
+		log.Infof("no holder state for nft class %s, scanning from block %d", nftClass.Address, first)
+		if first > 0 {
+			state.LastBlock = first - 1
+		}
+		err = nil
+	}
+
+	synced = head <= state.LastBlock+*maxBlocks
+	if !synced {
+		head = state.LastBlock + *maxBlocks
+	}
+
+	for from := state.LastBlock + 1; from <= head; from += *blockBatch {
+		to := from + *blockBatch - 1
+		if to > head {
+			to = head
+		}
+		logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{contract},
+			Topics:    [][]common.Hash{{transferEventID, transferSingleEventID, transferBatchEventID}},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		deltas := make(map[string]int64)
+		for _, txLog := range logs {
+			if txLog.Removed {
+				continue
+			}
+			sender, receiver, amount, ok := decodeTransfer(txLog)
+			if !ok {
+				continue
+			}
+			// Mints and burns do not change the balance of a holder on the zero address side.
+			if sender != (common.Address{}) {
+				deltas[strings.ToLower(sender.Hex())] -= amount
+			}
+			if receiver != (common.Address{}) {
+				deltas[strings.ToLower(receiver.Hex())] += amount
+			}
+		}
+		state.LastBlock = to
+		if err = relDB.UpdateNFTHolderBalances(nftClass, deltas, stateName, state); err != nil {
+			return false, err
+		}
+	}
+	return
+}
+
+// deployBlock returns the block in which @contract was deployed by a binary search for the first block up to @head
+// with code at @contract. This requires the state of historic blocks, as kept by archive nodes.
+func deployBlock(ctx context.Context, client *ethclient.Client, contract common.Address, head uint64) (uint64, error) {
+	low, high := uint64(0), head
+	for low < high {
+		mid := low + (high-low)/2
+		code, err := client.CodeAt(ctx, contract, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, err
+		}
+		if len(code) > 0 {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
+
+// decodeTransfer returns sender, receiver and number of tokens of the ERC721 Transfer or ERC1155 TransferSingle
+// or TransferBatch log @txLog. It returns false for other logs.
+func decodeTransfer(txLog types.Log) (sender common.Address, receiver common.Address, amount int64, ok bool) {
+	if len(txLog.Topics) != 4 {
+		// ERC20 transfers share the event signature but do not index the token id.
+		return
+	}
+	switch txLog.Topics[0] {
+	case transferEventID:
+		return common.BytesToAddress(txLog.Topics[1].Bytes()), common.BytesToAddress(txLog.Topics[2].Bytes()), 1, true
+	case transferSingleEventID:
+		if len(txLog.Data) != 64 {
+			return
+		}
+		value := new(big.Int).SetBytes(txLog.Data[32:])
+		if !value.IsInt64() {
+			return
+		}
+		amount = value.Int64()
+	case transferBatchEventID:
+		values, err := transferBatchData.Unpack(txLog.Data)
+		if err != nil {
+			log.Warnf("unable to decode TransferBatch log(tx: %s, logIndex: %d): %v", txLog.TxHash.Hex(), txLog.Index, err)
+			return
+		}
+		batchValues, isBigInts := values[1].([]*big.Int)
+		if !isBigInts {
+			return
+		}
+		for _, value := range batchValues {
+			if !value.IsInt64() {
+				return
+			}
+			amount += value.Int64()
+		}
+	default:
+		return
+	}
+	return common.BytesToAddress(txLog.Topics[2].Bytes()), common.BytesToAddress(txLog.Topics[3].Bytes()), amount, true
+}
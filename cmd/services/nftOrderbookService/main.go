@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/sirupsen/logrus"
+)
+
+// The order book is fed by the order events of the nft bid and offer scrapers. This service
+// marks the orders which have passed their expiry, so that the stored state stays current
+// for orders which are not touched by events anymore.
+
+var (
+	log    = logrus.New()
+	period *time.Duration
+)
+
+func init() {
+	period = flag.Duration("period", time.Minute, "Period between two expiry runs of the nft order book")
+	flag.Parse()
+}
+
+func main() {
+
+	relDB, err := models.NewRelDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to asset DB: ", err)
+		return
+	}
+
+	// Initial run.
+	expireOrders(relDB)
+
+	// Afterwards, run every @period.
+	ticker := time.NewTicker(*period)
+	for range ticker.C {
+		expireOrders(relDB)
+	}
+}
+
+// expireOrders marks all open orders with expiry in the past as expired.
+func expireOrders(relDB *models.RelDB) {
+	expired, err := relDB.ExpireNFTOrders(time.Now().UTC())
+	if err != nil {
+		log.Error("expire nft orders: ", err)
+		return
+	}
+	if expired > 0 {
+		log.Infof("expired %d nft orders", expired)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	log        = logrus.New()
+	blockchain *string
+	period     *time.Duration
+)
+
+func init() {
+	blockchain = flag.String("blockchain", dia.ETHEREUM, "Blockchain of the nft collections to score")
+	period = flag.Duration("period", 24*time.Hour, "Period between two computations of the rarity of all collections")
+	flag.Parse()
+}
+
+func main() {
+
+	relDB, err := models.NewRelDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to asset DB: ", err)
+		return
+	}
+
+	// Initial run.
+	scoreNFTClasses(relDB, *blockchain)
+
+	// Afterwards, run every @period.
+	ticker := time.NewTicker(*period)
+	for range ticker.C {
+		scoreNFTClasses(relDB, *blockchain)
+	}
+}
+
+// scoreNFTClasses stores the rarity of all nfts of the collections on @blockchain.
+func scoreNFTClasses(relDB *models.RelDB, blockchain string) {
+	nftClasses, err := relDB.GetAllNFTClasses(blockchain)
+	if err != nil {
+		log.Error("get nft classes: ", err)
+		return
+	}
+
+	timestamp := time.Now()
+	for _, nftClass := range nftClasses {
+		rarities, err := relDB.ComputeNFTClassRarity(nftClass, timestamp)
+		if err != nil {
+			log.Warnf("compute rarity of nft class %s on %s: %v", nftClass.Address, nftClass.Blockchain, err)
+			continue
+		}
+		for _, rarity := range rarities {
+			if err := relDB.SetNFTRarity(rarity); err != nil {
+				log.Errorf("set rarity of nft %s in class %s: %v", rarity.NFT.TokenID, nftClass.Address, err)
+			}
+		}
+		log.Infof("scored %d nfts of class %s", len(rarities), nftClass.Address)
+	}
+}
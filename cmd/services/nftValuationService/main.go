@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	log        = logrus.New()
+	blockchain *string
+	period     *time.Duration
+)
+
+func init() {
+	blockchain = flag.String("blockchain", dia.ETHEREUM, "Blockchain of the nft collections to appraise")
+	period = flag.Duration("period", 24*time.Hour, "Period between two appraisals of all collections")
+	flag.Parse()
+}
+
+func main() {
+
+	relDB, err := models.NewRelDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to asset DB: ", err)
+		return
+	}
+
+	// Initial run.
+	appraiseNFTClasses(relDB, *blockchain)
+
+	// Afterwards, run every @period.
+	ticker := time.NewTicker(*period)
+	for range ticker.C {
+		appraiseNFTClasses(relDB, *blockchain)
+	}
+}
+
+// appraiseNFTClasses stores the valuations of all nfts of the collections on @blockchain.
+// Collections without recent sales have no floor price and are skipped.
+func appraiseNFTClasses(relDB *models.RelDB, blockchain string) {
+	nftClasses, err := relDB.GetAllNFTClasses(blockchain)
+	if err != nil {
+		log.Error("get nft classes: ", err)
+		return
+	}
+
+	timestamp := time.Now()
+	for _, nftClass := range nftClasses {
+		valuations, err := relDB.AppraiseNFTClass(nftClass, timestamp)
+		if err != nil {
+			log.Warnf("appraise nft class %s on %s: %v", nftClass.Address, nftClass.Blockchain, err)
+			continue
+		}
+		if err := relDB.SetNFTValuations(valuations); err != nil {
+			log.Errorf("set valuations of class %s: %v", nftClass.Address, err)
+			continue
+		}
+		log.Infof("appraised %d nfts of class %s", len(valuations), nftClass.Address)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	log           = logrus.New()
+	protocolNames *string
+	period        *time.Duration
+	refresh       *time.Duration
+)
+
+// protocolTVL reads the holdings of a protocol instance.
+type protocolTVL struct {
+	conf     defiscrapers.ProtocolConfig
+	client   *ethclient.Client
+	holdings []defiscrapers.TVLHolding
+	native   dia.Asset
+	// holdingsTime is the time the holdings were last read.
+	holdingsTime time.Time
+}
+
+func init() {
+	protocolNames = flag.String("protocols", "", "Comma separated names of the protocols in config/defiProtocols.json. Defaults to all protocols with known holdings")
+	period = flag.Duration("period", time.Hour, "Period between two updates of the TVL of all protocols")
+	refresh = flag.Duration("refresh", 24*time.Hour, "Period between two reads of the holdings of a protocol, such as its markets and pools")
+	flag.Parse()
+}
+
+func main() {
+
+	relDB, err := models.NewRelDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to asset DB: ", err)
+		return
+	}
+	datastore, err := models.NewDataStore()
+	if err != nil {
+		log.Errorln("Error connecting to data store: ", err)
+		return
+	}
+	var names []string
+	if *protocolNames != "" {
+		names = strings.Split(*protocolNames, ",")
+	}
+	protocols, err := defiscrapers.LoadProtocolConfigs(names...)
+	if err != nil {
+		log.Fatal("load protocol config: ", err)
+	}
+
+	var tvls []protocolTVL
+	var uncovered []string
+	for _, conf := range protocols {
+		if !conf.HasTVL() {
+			if len(names) > 0 {
+				log.Fatalf("holdings of %s are not known", conf.Name)
+			}
+			uncovered = append(uncovered, conf.Name)
+			continue
+		}
+		client, err := conf.ETHClient()
+		if err != nil {
+			log.Fatalf("client of %s: %v", conf.Name, err)
+		}
+		blockchain, err := relDB.GetBlockchain(conf.Blockchain)
+		if err != nil {
+			log.Fatalf("native token of %s: %v", conf.Blockchain, err)
+		}
+		tvls = append(tvls, protocolTVL{conf: conf, client: client, native: blockchain.NativeToken})
+	}
+
+	if len(uncovered) > 0 {
+		log.Warnf("holdings of %s are not known, add tvl holdings to their config to cover them", strings.Join(uncovered, ", "))
+	}
+
+	// Initial run.
+	updateTVL(datastore, tvls)
+
+	// Afterwards, run every @period.
+	ticker := time.NewTicker(*period)
+	for range ticker.C {
+		updateTVL(datastore, tvls)
+	}
+}
+
+// updateTVL stores the TVL of all protocol instances. All values of a run share its timestamp,
+// such that they can be summed over blockchains.
+func updateTVL(datastore *models.DB, tvls []protocolTVL) {
+	timestamp := time.Now()
+	prices := make(map[string]*pricedQuotation)
+	for i := range tvls {
+		p := &tvls[i]
+		values, err := readTVL(p, timestamp)
+		if err != nil {
+			log.Errorf("read tvl of %s: %v", p.conf.Name, err)
+			continue
+		}
+		var total float64
+		for j := range values {
+			priceTVL(datastore, prices, &values[j])
+			total += values[j].ValueUSD
+		}
+		if err := datastore.SetDefiTVL(values); err != nil {
+			log.Errorf("store tvl of %s: %v", p.conf.Name, err)
+			continue
+		}
+		log.Infof("tvl of %s on %s: %.0f USD in %d assets", p.conf.TVLProtocol(), p.conf.Blockchain, total, len(values))
+	}
+}
+
+// readTVL returns the balances of the assets held by the instance @p. The holdings are cached and read again
+// every @refresh, such that markets and pools added to the protocol are covered. If they cannot be read again,
+// the cached holdings are used.
+func readTVL(p *protocolTVL, timestamp time.Time) ([]models.DefiTVL, error) {
+	ctx := context.Background()
+	if p.holdings == nil || timestamp.Sub(p.holdingsTime) >= *refresh {
+		holdings, err := defiscrapers.TVLHoldings(ctx, p.conf, p.client)
+		if err != nil {
+			if p.holdings == nil {
+				return nil, err
+			}
+			log.Warnf("refresh holdings of %s, using cached holdings: %v", p.conf.Name, err)
+		} else {
+			p.holdings = holdings
+			p.holdingsTime = timestamp
+		}
+	}
+	return defiscrapers.ReadTVLBalances(ctx, p.conf, p.client, p.holdings, p.native, timestamp)
+}
+
+// priceTVL values @tvl with the cached DIA price of its asset, falling back to the latest stored price.
+// Quotations are cached in @prices for the run.
+func priceTVL(datastore *models.DB, prices map[string]*pricedQuotation, tvl *models.DefiTVL) {
+	key := tvl.Asset.Blockchain + "-" + tvl.Asset.Address
+	quotation, ok := prices[key]
+	if !ok {
+		quotation = lookupPrice(datastore, tvl.Asset)
+		prices[key] = quotation
+	}
+	if quotation == nil {
+		return
+	}
+	tvl.Price = quotation.Price
+	tvl.ValueUSD = tvl.Balance * quotation.Price
+	tvl.PriceSource = quotation.source
+	tvl.PriceTime = quotation.Time
+}
+
+// pricedQuotation is a quotation along with the lookup it was found by.
+type pricedQuotation struct {
+	*models.AssetQuotation
+	source string
+}
+
+// lookupPrice returns the cached quotation of @asset or else its latest stored quotation, or nil if there is none.
+func lookupPrice(datastore *models.DB, asset dia.Asset) *pricedQuotation {
+	quotation, err := datastore.GetAssetQuotationCache(asset)
+	if err == nil && quotation.Price > 0 {
+		return &pricedQuotation{AssetQuotation: quotation, source: quotationSource(quotation, models.DefiTVLPriceCache)}
+	}
+	quotation, err = datastore.GetAssetQuotationLatest(asset)
+	if err == nil && quotation.Price > 0 {
+		return &pricedQuotation{AssetQuotation: quotation, source: quotationSource(quotation, models.DefiTVLPriceStored)}
+	}
+	log.Warnf("no price of %s on %s: %v", asset.Symbol, asset.Blockchain, err)
+	return nil
+}
+
+func quotationSource(quotation *models.AssetQuotation, lookup string) string {
+	source := quotation.Source
+	if source == "" {
+		source = dia.Diadata
+	}
+	return source + "/" + lookup
+}
@@ -104,17 +104,30 @@ CREATE TABLE nfttrade (
     price_usd numeric,
     transfer_from text,
     transfer_to text,
-    currency_symbol text,
-    currency_address text,
-    currency_decimals numeric,
+    currency_id uuid REFERENCES asset(asset_id),
     block_number numeric,
     trade_time timestamp,
-    tx_hash text,    
+    tx_hash text,
     marketplace text,
+    quantity numeric DEFAULT 1,
+    bundle_id text,
+    bundle_size numeric DEFAULT 1,
     UNIQUE(sale_id),
     UNIQUE(nft_id, trade_time)
 );
 
+-- nfttradecurrent is the table the nft trade scrapers write to and the API reads from,
+-- nfttradesumeria is an alternative target of the scrapers. Both share the layout of nfttrade.
+CREATE TABLE nfttradecurrent (LIKE nfttrade INCLUDING ALL);
+ALTER TABLE nfttradecurrent ADD FOREIGN KEY (nftclass_id) REFERENCES nftclass(nftclass_id);
+ALTER TABLE nfttradecurrent ADD FOREIGN KEY (nft_id) REFERENCES nft(nft_id);
+ALTER TABLE nfttradecurrent ADD FOREIGN KEY (currency_id) REFERENCES asset(asset_id);
+
+CREATE TABLE nfttradesumeria (LIKE nfttrade INCLUDING ALL);
+ALTER TABLE nfttradesumeria ADD FOREIGN KEY (nftclass_id) REFERENCES nftclass(nftclass_id);
+ALTER TABLE nfttradesumeria ADD FOREIGN KEY (nft_id) REFERENCES nft(nft_id);
+ALTER TABLE nfttradesumeria ADD FOREIGN KEY (currency_id) REFERENCES asset(asset_id);
+
 CREATE TABLE nftbid (
     bid_id UUID DEFAULT gen_random_uuid(),
     nft_id uuid REFERENCES nft(nft_id),
@@ -152,6 +165,139 @@ CREATE TABLE nftoffer (
     UNIQUE(nft_id, from_address, offer_time)
 );
 
+CREATE TABLE nftvaluation (
+    valuation_id UUID DEFAULT gen_random_uuid(),
+    nft_id uuid REFERENCES nft(nft_id),
+    valuation_time timestamp,
+    value numeric,
+    value_usd numeric,
+    lower_bound numeric,
+    upper_bound numeric,
+    currency_id uuid REFERENCES asset(asset_id),
+    components jsonb,
+    UNIQUE(valuation_id),
+    UNIQUE(nft_id, valuation_time)
+);
+
+CREATE TABLE nftrarity (
+    nft_id uuid REFERENCES nft(nft_id),
+    statistical_rarity double precision,
+    statistical_rank integer,
+    information_content double precision,
+    information_content_rank integer,
+    trait_count integer,
+    traits jsonb,
+    compute_time timestamp,
+    UNIQUE(nft_id)
+);
+
+CREATE TABLE nftmarketstats (
+    nftclass_id uuid REFERENCES nftclass(nftclass_id),
+    interval text,
+    time_start timestamp,
+    currency_id uuid REFERENCES asset(asset_id),
+    volume double precision,
+    volume_usd double precision,
+    num_sales integer,
+    unique_buyers integer,
+    unique_sellers integer,
+    holder_count integer,
+    floor_price double precision,
+    median_price double precision,
+    mean_price double precision,
+    UNIQUE(nftclass_id, interval, time_start)
+);
+
+CREATE TABLE nftholder (
+    nftclass_id uuid REFERENCES nftclass(nftclass_id),
+    address text,
+    balance numeric,
+    UNIQUE(nftclass_id, address)
+);
+
+-- nftorder is the current state of the bids and offers of the nft order book.
+CREATE TABLE nftorder (
+    nft_id uuid REFERENCES nft(nft_id),
+    nftclass_id uuid REFERENCES nftclass(nftclass_id),
+    marketplace text,
+    order_id text,
+    side text,
+    status text,
+    maker text,
+    price text,
+    end_price text,
+    duration numeric,
+    currency_id uuid REFERENCES asset(asset_id),
+    expiry timestamp,
+    order_time timestamp,
+    update_time timestamp,
+    blocknumber numeric,
+    blockposition numeric,
+    tx_hash text,
+    UNIQUE(marketplace, order_id)
+);
+
+-- nftoracleupdate is the audit trail of the values pushed to nft oracle contracts.
+CREATE TABLE nftoracleupdate (
+    oracle_address text NOT NULL,
+    chain_id numeric NOT NULL,
+    oracle_key text NOT NULL,
+    nftclass_id uuid REFERENCES nftclass(nftclass_id),
+    floor double precision,
+    floor_ma double precision,
+    floor_usd double precision,
+    currency text,
+    confidence double precision,
+    num_trades integer,
+    value0 numeric,
+    value1 numeric,
+    value2 numeric,
+    value3 numeric,
+    value4 numeric,
+    value_time timestamp NOT NULL,
+    reason text,
+    signer text,
+    signature text,
+    tx_hash text,
+    update_time timestamp NOT NULL,
+    status text NOT NULL DEFAULT 'confirmed',
+    UNIQUE(tx_hash)
+);
+
+-- defiposition is the collateral and the debt of an account in a market of a lending protocol.
+CREATE TABLE defiposition (
+    protocol text NOT NULL,
+    account text NOT NULL,
+    asset_address text NOT NULL,
+    asset_blockchain text NOT NULL,
+    asset_symbol text,
+    collateral double precision NOT NULL DEFAULT 0,
+    debt double precision NOT NULL DEFAULT 0,
+    update_time timestamp,
+    UNIQUE(protocol, account, asset_address)
+);
+
+-- defiliquidation are the liquidations of borrowers on lending protocols.
+CREATE TABLE defiliquidation (
+    protocol text NOT NULL,
+    borrower text NOT NULL,
+    liquidator text,
+    collateral_address text,
+    collateral_blockchain text,
+    collateral_symbol text,
+    collateral_amount double precision,
+    debt_address text,
+    debt_blockchain text,
+    debt_symbol text,
+    debt_amount double precision,
+    value_usd double precision,
+    block_number bigint,
+    tx_hash text NOT NULL,
+    log_index integer NOT NULL,
+    liquidation_time timestamp NOT NULL,
+    UNIQUE(protocol, tx_hash, log_index)
+);
+
 CREATE TABLE IF NOT EXISTS scrapers (
     name character varying(255) NOT NULL,
 	conf json,
@@ -203,4 +349,40 @@ CREATE TABLE tradesdistribution (
     compute_time timestamp
 );
 
+CREATE TABLE apiplan (
+    apiplan_id UUID DEFAULT gen_random_uuid(),
+    name text not null,
+    -- token bucket refilled with rate_per_second up to burst tokens
+    rate_per_second numeric not null,
+    burst numeric not null,
+    -- maximal number of requests per day, 0 for unlimited
+    daily_quota numeric not null default 0,
+    -- limits overriding the default limit for single routes
+    route_limits jsonb,
+    UNIQUE(name),
+    UNIQUE(apiplan_id)
+);
+
+CREATE TABLE apikey (
+    apikey_id UUID DEFAULT gen_random_uuid(),
+    -- sha256 hash of the key, the plain key is never stored
+    key_hash text not null,
+    prefix text,
+    owner text,
+    plan text REFERENCES apiplan(name),
+    created_at timestamp not null,
+    revoked_at timestamp,
+    UNIQUE(key_hash),
+    UNIQUE(apikey_id)
+);
+
+CREATE TABLE apiusage (
+    apiusage_id UUID DEFAULT gen_random_uuid(),
+    apikey_id uuid REFERENCES apikey(apikey_id),
+    endpoint text not null,
+    usage_date date not null,
+    requests numeric,
+    UNIQUE(apikey_id, endpoint, usage_date)
+);
 
+INSERT INTO apiplan (name,rate_per_second,burst,daily_quota) VALUES ('free',1,10,10000);
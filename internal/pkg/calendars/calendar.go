@@ -0,0 +1,206 @@
+package calendars
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// expiryWarning is how long before the end of its validity a loaded calendar is reported,
+	// such that the holidays of the following year are added in time.
+	expiryWarning = 90 * 24 * time.Hour
+)
+
+var log = logrus.New()
+
+// Names are the calendars in the config folder calendars.
+var Names = []string{"TARGET2", "USSIFMA", "UK"}
+
+// RollConvention determines how a date that is not a business day is moved to one.
+type RollConvention string
+
+const (
+	// Following moves a date to the next business day.
+	Following RollConvention = "following"
+	// ModifiedFollowing moves a date to the next business day, unless it is in the next month.
+	// The date is then moved to the previous business day.
+	ModifiedFollowing RollConvention = "modifiedFollowing"
+	// Preceding moves a date to the previous business day.
+	Preceding RollConvention = "preceding"
+)
+
+// Calendar holds the business days of a currency. Business days are all weekdays that are not holidays.
+// Holidays are only known from ValidFrom to ValidTo.
+type Calendar struct {
+	Name        string   `json:"name"`
+	Currency    string   `json:"currency"`
+	Description string   `json:"description"`
+	Rates       []string `json:"rates"`
+	ValidFrom   string   `json:"validFrom"`
+	ValidTo     string   `json:"validTo"`
+	Holidays    []string `json:"holidays"`
+
+	validFrom time.Time
+	validTo   time.Time
+	holidays  map[time.Time]bool
+}
+
+// Parse returns the calendar in the json @data.
+func Parse(data []byte) (*Calendar, error) {
+	c := &Calendar{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	var err error
+	if c.validFrom, err = time.Parse(dateLayout, c.ValidFrom); err != nil {
+		return nil, fmt.Errorf("validFrom of calendar %s: %v", c.Name, err)
+	}
+	if c.validTo, err = time.Parse(dateLayout, c.ValidTo); err != nil {
+		return nil, fmt.Errorf("validTo of calendar %s: %v", c.Name, err)
+	}
+	if c.validTo.Before(c.validFrom) {
+		return nil, fmt.Errorf("calendar %s ends before it starts", c.Name)
+	}
+	c.holidays = make(map[time.Time]bool, len(c.Holidays))
+	for _, holiday := range c.Holidays {
+		date, err := time.Parse(dateLayout, holiday)
+		if err != nil {
+			return nil, fmt.Errorf("holiday of calendar %s: %v", c.Name, err)
+		}
+		if date.Before(c.validFrom) || date.After(c.validTo) {
+			return nil, fmt.Errorf("holiday %s is outside of calendar %s", holiday, c.Name)
+		}
+		c.holidays[date] = true
+	}
+	return c, nil
+}
+
+// Load returns the calendar @name from the config folder calendars.
+func Load(name string) (*Calendar, error) {
+	data, err := configCollectors.ReadJSONFromConfig("calendars/" + name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// ErrNoCalendar is returned by ForRate for rates without a calendar.
+var ErrNoCalendar = errors.New("no calendar")
+
+var (
+	loadMu sync.Mutex
+	byRate map[string]*Calendar
+)
+
+// ForRate returns the calendar of the interest rate @symbol. The calendars @Names are loaded on the first call.
+// A failed load is retried on the next call.
+func ForRate(symbol string) (*Calendar, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	if byRate == nil {
+		calendars := make(map[string]*Calendar)
+		for _, name := range Names {
+			c, err := Load(name)
+			if err != nil {
+				return nil, fmt.Errorf("load calendar %s: %v", name, err)
+			}
+			if c.ExpiresBefore(time.Now().Add(expiryWarning)) {
+				log.Warnf("calendar %s is only valid to %s, add the holidays of the following year", c.Name, c.ValidTo)
+			}
+			for _, rate := range c.Rates {
+				calendars[rate] = c
+			}
+		}
+		byRate = calendars
+	}
+	c, ok := byRate[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoCalendar, symbol)
+	}
+	return c, nil
+}
+
+// Covers returns true if the holidays on @date are known.
+func (c *Calendar) Covers(date time.Time) bool {
+	date = day(date)
+	return !date.Before(c.validFrom) && !date.After(c.validTo)
+}
+
+// ExpiresBefore returns true if the holidays are not known up to @date.
+func (c *Calendar) ExpiresBefore(date time.Time) bool {
+	return c.validTo.Before(day(date))
+}
+
+// IsBusinessDay returns true if @date is neither a weekend nor a holiday.
+func (c *Calendar) IsBusinessDay(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !c.holidays[day(date)]
+}
+
+// HolidaysBetween returns the holidays from @dateInit to @dateFinal, both included, that are not on a weekend.
+// This is the form in which holidays are passed to the rate derivatives. Only days the calendar covers are
+// considered.
+func (c *Calendar) HolidaysBetween(dateInit, dateFinal time.Time) []time.Time {
+	holidays := []time.Time{}
+	for date := day(dateInit); !date.After(day(dateFinal)); date = date.AddDate(0, 0, 1) {
+		if c.holidays[date] {
+			holidays = append(holidays, date)
+		}
+	}
+	return holidays
+}
+
+// Roll moves @date to a business day according to @convention. Business days are returned unchanged.
+func (c *Calendar) Roll(date time.Time, convention RollConvention) (time.Time, error) {
+	switch convention {
+	case Following:
+		return c.step(date, 1)
+	case Preceding:
+		return c.step(date, -1)
+	case ModifiedFollowing:
+		rolled, err := c.step(date, 1)
+		if err != nil || rolled.Month() == date.Month() {
+			return rolled, err
+		}
+		return c.step(date, -1)
+	}
+	return time.Time{}, fmt.Errorf("unknown roll convention %q", convention)
+}
+
+// AddBusinessDays moves @date by @n business days, backwards for negative @n.
+// @date itself does not need to be a business day.
+func (c *Calendar) AddBusinessDays(date time.Time, n int) (time.Time, error) {
+	direction := 1
+	if n < 0 {
+		direction, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		var err error
+		if date, err = c.step(date.AddDate(0, 0, direction), direction); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return date, nil
+}
+
+// step moves @date by a day in @direction until it is a business day.
+func (c *Calendar) step(date time.Time, direction int) (time.Time, error) {
+	for !c.IsBusinessDay(date) {
+		date = date.AddDate(0, 0, direction)
+	}
+	if !c.Covers(date) {
+		return time.Time{}, errors.New("date is outside of calendar " + c.Name)
+	}
+	return date, nil
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
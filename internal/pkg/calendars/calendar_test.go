@@ -0,0 +1,155 @@
+package calendars
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func loadTestCalendar(t *testing.T, name string) *Calendar {
+	data, err := ioutil.ReadFile("../../../config/calendars/" + name + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse calendar %s: %v", name, err)
+	}
+	return c
+}
+
+func date(s string) time.Time {
+	d, _ := time.Parse(dateLayout, s)
+	return d
+}
+
+func TestConfigCalendars(t *testing.T) {
+	for _, c := range []struct {
+		calendar string
+		date     string
+		want     bool
+	}{
+		{"TARGET2", "2021-04-02", false},
+		{"TARGET2", "2021-04-05", false},
+		{"TARGET2", "2021-05-03", true},
+		{"TARGET2", "2021-12-24", true},
+		{"UK", "2021-05-03", false},
+		{"UK", "2021-12-28", false},
+		{"UK", "2022-09-19", false},
+		{"UK", "2021-07-05", true},
+		{"USSIFMA", "2021-04-02", true},
+		{"USSIFMA", "2021-07-05", false},
+		{"USSIFMA", "2021-12-24", false},
+		{"USSIFMA", "2021-12-31", true},
+		{"USSIFMA", "2022-06-20", false},
+		{"USSIFMA", "2023-11-10", true},
+		{"USSIFMA", "2026-04-03", true},
+		{"USSIFMA", "2026-07-03", false},
+		{"USSIFMA", "2027-12-24", false},
+	} {
+		if got := loadTestCalendar(t, c.calendar).IsBusinessDay(date(c.date)); got != c.want {
+			t.Errorf("business day %s of %s was incorrect, got: %v, want: %v.", c.date, c.calendar, got, c.want)
+		}
+	}
+}
+
+// TestConfigCalendarsValidity fails ahead of the end of a calendar, such that the holidays of the following
+// year are added before rates are compounded over unknown days.
+func TestConfigCalendarsValidity(t *testing.T) {
+	for _, name := range Names {
+		if c := loadTestCalendar(t, name); c.ExpiresBefore(time.Now().Add(expiryWarning)) {
+			t.Errorf("calendar %s is only valid to %s, add the holidays of the following year.", name, c.ValidTo)
+		}
+	}
+}
+
+func TestHolidaysBetween(t *testing.T) {
+	c := loadTestCalendar(t, "UK")
+	holidays := c.HolidaysBetween(date("2021-12-20"), date("2022-01-10"))
+	want := []string{"2021-12-27", "2021-12-28", "2022-01-03"}
+	if len(holidays) != len(want) {
+		t.Fatalf("holidays were incorrect, got: %v, want: %v.", holidays, want)
+	}
+	for i := range want {
+		if !holidays[i].Equal(date(want[i])) {
+			t.Errorf("holiday was incorrect, got: %v, want: %v.", holidays[i], want[i])
+		}
+	}
+	if c.Covers(date("2018-12-25")) || !c.Covers(date("2019-01-01")) {
+		t.Errorf("coverage of calendar was incorrect")
+	}
+}
+
+func TestRoll(t *testing.T) {
+	c := loadTestCalendar(t, "TARGET2")
+	for _, r := range []struct {
+		date       string
+		convention RollConvention
+		want       string
+	}{
+		{"2021-06-15", Following, "2021-06-15"},
+		{"2021-04-02", Following, "2021-04-06"},
+		{"2021-04-02", Preceding, "2021-04-01"},
+		{"2021-07-31", Following, "2021-08-02"},
+		{"2021-07-31", ModifiedFollowing, "2021-07-30"},
+		{"2021-05-01", ModifiedFollowing, "2021-05-03"},
+	} {
+		got, err := c.Roll(date(r.date), r.convention)
+		if err != nil || !got.Equal(date(r.want)) {
+			t.Errorf("roll of %s with %s was incorrect, got: %v, %v, want: %v.", r.date, r.convention, got, err, r.want)
+		}
+	}
+	if _, err := c.Roll(date("2021-04-02"), "nearest"); err == nil {
+		t.Errorf("unknown convention was accepted")
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	c := loadTestCalendar(t, "TARGET2")
+	for _, a := range []struct {
+		date string
+		n    int
+		want string
+	}{
+		{"2021-04-01", 1, "2021-04-06"},
+		{"2021-04-06", -2, "2021-03-31"},
+		{"2021-04-03", 0, "2021-04-03"},
+	} {
+		got, err := c.AddBusinessDays(date(a.date), a.n)
+		if err != nil || !got.Equal(date(a.want)) {
+			t.Errorf("%d business days from %s were incorrect, got: %v, %v, want: %v.", a.n, a.date, got, err, a.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"name":"X","validFrom":"2021-01-01","validTo":"2020-12-31"}`,
+		`{"name":"X","validFrom":"2021-01-01","validTo":"2021-12-31","holidays":["2022-01-03"]}`,
+		`{"name":"X","validFrom":"2021-01-01","validTo":"2021-12-31","holidays":["03.01.2021"]}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("calendar %s was accepted", data)
+		}
+	}
+}
+
+func TestForRateErrors(t *testing.T) {
+	names := Names
+	defer func() {
+		Names = names
+		byRate = nil
+	}()
+
+	Names = []string{"MISSING"}
+	byRate = nil
+	if _, err := ForRate("SOFR"); err == nil || errors.Is(err, ErrNoCalendar) {
+		t.Errorf("expected load error, got: %v", err)
+	}
+	// The failed load is not cached.
+	Names = nil
+	if _, err := ForRate("SOFR"); !errors.Is(err, ErrNoCalendar) {
+		t.Errorf("expected no calendar, got: %v", err)
+	}
+}
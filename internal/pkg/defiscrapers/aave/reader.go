@@ -17,16 +17,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	// https://docs.aave.com/developers/deployed-contracts/deployed-contracts
-	// LendingPoolAddressesProviderRegistry
-	addrProviderRegistry = "0x52D306e36E3B6B02c153d0266ff0f85d18BCD413"
-
-	// we use the main market
-	marketID = "Aave genesis market"
-)
-
-func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiProtocol, chRate chan *dia.DefiRate, chState chan *dia.DefiProtocolState) error {
+// Read sends the state of all reserves of the market with the LendingPoolAddressesProvider at @addrProviderAddr
+// to @chRate and the state of the market to @chState. Either channel may be nil.
+// The addresses providers of all markets are listed at https://docs.aave.com/developers/v/2.0/deployed-contracts/deployed-contracts
+func Read(ctx context.Context, ethClient *ethclient.Client, addrProviderAddr common.Address, protocol dia.DefiProtocol, chRate chan *dia.DefiRate, chState chan *dia.DefiProtocolState) error {
 	log := logrus.WithFields(logrus.Fields{
 		"comp": "aave-scraper",
 	})
@@ -48,9 +42,9 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 		BlockNumber: new(big.Int).SetUint64(blockNum),
 	}
 
-	addrProvider, err := getAddrProvider(callOpts, ethClient)
+	addrProvider, err := contract.NewILendingPoolAddressesProvider(addrProviderAddr, ethClient)
 	if err != nil {
-		return errors.WithStack(err)
+		return errors.Wrapf(err, "unable to bind the contract LendingPoolAddressesProvider at %s: %s", addrProviderAddr.String(), err)
 	}
 
 	lendingPool, err := lendingPoolContract(callOpts, ethClient, addrProvider)
@@ -69,6 +63,7 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 	}
 
 	totalLockedInUSD := new(big.Float)
+	totalBorrowInUSD := new(big.Float)
 
 	for _, reserveAddr := range reserveAddrs {
 		reserveData, err := lendingPool.GetReserveData(callOpts, reserveAddr)
@@ -92,9 +87,11 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 			}
 		}
 
+		// Markets on other chains list assets without a price, which are left out of the totals.
 		priceInUSD, err := utils.GetCoinPrice(underlyingAssetSymbol)
 		if err != nil {
-			return errors.Wrapf(err, "unable to find the price in USD for underlying asset %s: %s", underlyingAssetSymbol, err.Error())
+			log.WithError(err).Warnf("unable to find the price in USD for underlying asset %s", underlyingAssetSymbol)
+			priceInUSD = 0
 		}
 
 		aToken, err := contract.NewERC20(reserveData.ATokenAddress, ethClient)
@@ -122,6 +119,26 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 			totalLockedAssetAmount,
 		)
 
+		totalBorrowAsset, err := totalDebt(callOpts, ethClient, reserveData)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		totalBorrowAssetAmount := new(big.Float).Quo(
+			new(big.Float).SetInt(totalBorrowAsset),
+			big.NewFloat(math.Pow10(int(aTokenDecimals))),
+		)
+
+		availableLiquidityAsset, err := underlyingAsset.BalanceOf(callOpts, reserveData.ATokenAddress)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read balance of aToken at %s: %s", reserveData.ATokenAddress.String(), err.Error())
+		}
+
+		availableLiquidityAmount := new(big.Float).Quo(
+			new(big.Float).SetInt(availableLiquidityAsset),
+			big.NewFloat(math.Pow10(int(aTokenDecimals))),
+		)
+
 		liquidityRate := new(big.Float).Quo(
 			new(big.Float).SetInt(reserveData.CurrentLiquidityRate),
 			big.NewFloat(math.Pow10(25)),
@@ -132,6 +149,13 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 			big.NewFloat(math.Pow10(25)),
 		)
 
+		variableBorrowRate := new(big.Float).Quo(
+			new(big.Float).SetInt(reserveData.CurrentVariableBorrowRate),
+			big.NewFloat(math.Pow10(25)),
+		)
+
+		config := decodeReserveConfiguration(reserveData.Configuration.Data)
+
 		log.WithFields(logrus.Fields{
 			"asset":          underlyingAssetSymbol,
 			"locked":         totalLockedAssetAmount.Text('f', 2),
@@ -145,8 +169,17 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 			totalLockedAssetInUSD,
 		)
 
+		totalBorrowInUSD = new(big.Float).Add(
+			totalBorrowInUSD,
+			new(big.Float).Mul(big.NewFloat(priceInUSD), totalBorrowAssetAmount),
+		)
+
 		liquidityRate64, _ := liquidityRate.Float64()
 		stableBorrowRate64, _ := stableBorrowRate.Float64()
+		variableBorrowRate64, _ := variableBorrowRate.Float64()
+		totalSupplied64, _ := totalLockedAssetAmount.Float64()
+		totalBorrowed64, _ := totalBorrowAssetAmount.Float64()
+		availableLiquidity64, _ := availableLiquidityAmount.Float64()
 
 		if chRate != nil {
 			select {
@@ -154,11 +187,22 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 				return errors.WithStack(ctx.Err())
 
 			case chRate <- &dia.DefiRate{
-				Timestamp:     date,
-				Protocol:      protocol.Name,
-				Asset:         underlyingAssetSymbol,
-				LendingRate:   liquidityRate64,
-				BorrowingRate: stableBorrowRate64,
+				Timestamp:            date,
+				Protocol:             protocol.Name,
+				Asset:                underlyingAssetSymbol,
+				AssetAddress:         reserveAddr.Hex(),
+				AssetBlockchain:      protocol.UnderlyingBlockchain,
+				LendingRate:          liquidityRate64,
+				BorrowingRate:        stableBorrowRate64,
+				StableBorrowRate:     stableBorrowRate64,
+				VariableBorrowRate:   variableBorrowRate64,
+				TotalSupplied:        totalSupplied64,
+				TotalBorrowed:        totalBorrowed64,
+				AvailableLiquidity:   availableLiquidity64,
+				Utilisation:          dia.DefiUtilisation(totalBorrowed64, totalSupplied64),
+				ReserveFactor:        config.ReserveFactor,
+				CollateralFactor:     config.LTV,
+				LiquidationThreshold: config.LiquidationThreshold,
 			}:
 			}
 		}
@@ -176,6 +220,7 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 
 	totalLockedInUSD64, _ := totalLockedInUSD.Float64()
 	totalLockedInETH64, _ := totalLockedInETH.Float64()
+	totalBorrowInUSD64, _ := totalBorrowInUSD.Float64()
 
 	if chState != nil {
 		select {
@@ -183,10 +228,11 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 			return errors.WithStack(ctx.Err())
 
 		case chState <- &dia.DefiProtocolState{
-			Timestamp: date,
-			Protocol:  protocol,
-			TotalUSD:  totalLockedInUSD64,
-			TotalETH:  totalLockedInETH64,
+			Timestamp:      date,
+			Protocol:       protocol,
+			TotalUSD:       totalLockedInUSD64,
+			TotalETH:       totalLockedInETH64,
+			TotalBorrowUSD: totalBorrowInUSD64,
 		}:
 		}
 	}
@@ -194,6 +240,58 @@ func Read(ctx context.Context, ethClient *ethclient.Client, protocol dia.DefiPro
 	return nil
 }
 
+// totalDebt returns the sum of stable and variable rate borrows of the reserve with @reserveData.
+func totalDebt(callOpts *bind.CallOpts, backend bind.ContractBackend, reserveData contract.DataTypesReserveData) (*big.Int, error) {
+	total := new(big.Int)
+	for _, debtTokenAddr := range []common.Address{reserveData.StableDebtTokenAddress, reserveData.VariableDebtTokenAddress} {
+		debtToken, err := contract.NewERC20(debtTokenAddr, backend)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to initiate ERC20 contract for debt token at %s: %s", debtTokenAddr.String(), err.Error())
+		}
+		debt, err := debtToken.TotalSupply(callOpts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read total supply for debt token at %s: %s", debtTokenAddr.String(), err.Error())
+		}
+		total.Add(total, debt)
+	}
+	return total, nil
+}
+
+// reserveConfiguration is the decoded configuration bitmap of a reserve. Factors are fractions in [0,1].
+// https://docs.aave.com/developers/v/2.0/the-core-protocol/lendingpool#getconfiguration
+type reserveConfiguration struct {
+	LTV                    float64
+	LiquidationThreshold   float64
+	LiquidationBonus       float64
+	Decimals               int
+	Active                 bool
+	Frozen                 bool
+	BorrowingEnabled       bool
+	StableBorrowingEnabled bool
+	ReserveFactor          float64
+}
+
+func decodeReserveConfiguration(data *big.Int) (config reserveConfiguration) {
+	if data == nil {
+		return
+	}
+	bits := func(start, length uint) uint64 {
+		mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), length), big.NewInt(1))
+		return new(big.Int).And(new(big.Int).Rsh(data, start), mask).Uint64()
+	}
+	// Percentages are given in basis points.
+	config.LTV = float64(bits(0, 16)) / 1e4
+	config.LiquidationThreshold = float64(bits(16, 16)) / 1e4
+	config.LiquidationBonus = float64(bits(32, 16)) / 1e4
+	config.Decimals = int(bits(48, 8))
+	config.Active = bits(56, 1) == 1
+	config.Frozen = bits(57, 1) == 1
+	config.BorrowingEnabled = bits(58, 1) == 1
+	config.StableBorrowingEnabled = bits(59, 1) == 1
+	config.ReserveFactor = float64(bits(64, 16)) / 1e4
+	return
+}
+
 func lendingPoolContract(callOpts *bind.CallOpts, backend bind.ContractBackend, addrProvider *contract.ILendingPoolAddressesProvider) (*contract.LendingPool, error) {
 	lendingPoolAddr, err := addrProvider.GetLendingPool(callOpts)
 	if err != nil {
@@ -207,38 +305,3 @@ func lendingPoolContract(callOpts *bind.CallOpts, backend bind.ContractBackend,
 
 	return lendingPool, nil
 }
-
-// getAddrProvider returns the binding of address provider contract for the market specified by marketID
-func getAddrProvider(callOpts *bind.CallOpts, backend bind.ContractBackend) (*contract.ILendingPoolAddressesProvider, error) {
-	addProviderReg, err := contract.NewILendingPoolAddressesProviderRegistry(
-		common.HexToAddress(addrProviderRegistry),
-		backend,
-	)
-
-	if err != nil {
-		return nil, errors.Wrapf(err, "unable to bind the contract LendingPoolAddressesProviderRegistry at %s: %s", addrProviderRegistry, err)
-	}
-
-	addrProviders, err := addProviderReg.GetAddressesProvidersList(callOpts)
-	if err != nil {
-		return nil, errors.Wrapf(err, "unable to get the list of address providers: %s", err.Error())
-	}
-
-	for _, addrProviderAddr := range addrProviders {
-		addrProvider, err := contract.NewILendingPoolAddressesProvider(addrProviderAddr, backend)
-		if err != nil {
-			return nil, errors.Wrapf(err, "unable to bind the contract LendingPoolAddressesProvider at %s: %s", addrProviderAddr, err)
-		}
-
-		mid, err := addrProvider.GetMarketId(callOpts)
-		if err != nil {
-			return nil, errors.Wrapf(err, "unable to get market ID from the LendingPoolAddressesProvider at %s: %s", addrProviderAddr, err)
-		}
-
-		if mid == marketID {
-			return addrProvider, nil
-		}
-	}
-
-	return nil, errors.Errorf("unable to find the market at %s: %s", addrProviderRegistry, marketID)
-}
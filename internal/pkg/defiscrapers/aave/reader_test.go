@@ -3,11 +3,13 @@ package aave
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,10 +47,33 @@ func TestReader(t *testing.T) {
 
 	defer ethC.Close()
 
-	assert.Nil(t, Read(ctx, ethC, dia.DefiProtocol{
+	assert.Nil(t, Read(ctx, ethC, common.HexToAddress("0xB53C1a33016B2DC2fF3653530bfF1848a515c8c5"), dia.DefiProtocol{
 		Name:                 "AAVE",
 		Address:              "0x3dfd23A6c5E8BbcFc9581d2E864a68feb6a076d3",
 		UnderlyingBlockchain: "Ethereum",
 		Token:                "",
 	}, chRate, chState))
 }
+
+func TestDecodeReserveConfiguration(t *testing.T) {
+	// Configuration of the USDC reserve: LTV 80%, liquidation threshold 85%, liquidation bonus 105%,
+	// 6 decimals, active, borrowing and stable rate borrowing enabled, reserve factor 10%.
+	data := new(big.Int).Lsh(big.NewInt(1000), 64)
+	data.Or(data, new(big.Int).SetUint64(8000|8500<<16|10500<<32|6<<48|1<<56|1<<58|1<<59))
+	want := reserveConfiguration{
+		LTV:                    0.8,
+		LiquidationThreshold:   0.85,
+		LiquidationBonus:       1.05,
+		Decimals:               6,
+		Active:                 true,
+		BorrowingEnabled:       true,
+		StableBorrowingEnabled: true,
+		ReserveFactor:          0.1,
+	}
+	if got := decodeReserveConfiguration(data); got != want {
+		t.Errorf("configuration was incorrect, got: %+v, want: %+v.", got, want)
+	}
+	if got := decodeReserveConfiguration(nil); got != (reserveConfiguration{}) {
+		t.Errorf("empty configuration was incorrect, got: %+v.", got)
+	}
+}
@@ -0,0 +1,562 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package compoundcontract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// ComptrollerMetaData contains all meta data concerning the Comptroller contract.
+var ComptrollerMetaData = &bind.MetaData{
+	ABI: "[{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"markets\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"isListed\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"collateralFactorMantissa\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isComped\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"contractCToken\",\"name\":\"cToken\",\"type\":\"address\"}],\"name\":\"checkMembership\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getAssetsIn\",\"outputs\":[{\"internalType\":\"contractCToken[]\",\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"contractCToken\",\"name\":\"cToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"MarketEntered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"contractCToken\",\"name\":\"cToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"MarketExited\",\"type\":\"event\"}]",
+}
+
+// ComptrollerABI is the input ABI used to generate the binding from.
+// Deprecated: Use ComptrollerMetaData.ABI instead.
+var ComptrollerABI = ComptrollerMetaData.ABI
+
+// Comptroller is an auto generated Go binding around an Ethereum contract.
+type Comptroller struct {
+	ComptrollerCaller     // Read-only binding to the contract
+	ComptrollerTransactor // Write-only binding to the contract
+	ComptrollerFilterer   // Log filterer for contract events
+}
+
+// ComptrollerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type ComptrollerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ComptrollerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ComptrollerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ComptrollerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ComptrollerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ComptrollerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ComptrollerSession struct {
+	Contract     *Comptroller      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ComptrollerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ComptrollerCallerSession struct {
+	Contract *ComptrollerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// ComptrollerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ComptrollerTransactorSession struct {
+	Contract     *ComptrollerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// ComptrollerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ComptrollerRaw struct {
+	Contract *Comptroller // Generic contract binding to access the raw methods on
+}
+
+// ComptrollerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ComptrollerCallerRaw struct {
+	Contract *ComptrollerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ComptrollerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ComptrollerTransactorRaw struct {
+	Contract *ComptrollerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewComptroller creates a new instance of Comptroller, bound to a specific deployed contract.
+func NewComptroller(address common.Address, backend bind.ContractBackend) (*Comptroller, error) {
+	contract, err := bindComptroller(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Comptroller{ComptrollerCaller: ComptrollerCaller{contract: contract}, ComptrollerTransactor: ComptrollerTransactor{contract: contract}, ComptrollerFilterer: ComptrollerFilterer{contract: contract}}, nil
+}
+
+// NewComptrollerCaller creates a new read-only instance of Comptroller, bound to a specific deployed contract.
+func NewComptrollerCaller(address common.Address, caller bind.ContractCaller) (*ComptrollerCaller, error) {
+	contract, err := bindComptroller(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerCaller{contract: contract}, nil
+}
+
+// NewComptrollerTransactor creates a new write-only instance of Comptroller, bound to a specific deployed contract.
+func NewComptrollerTransactor(address common.Address, transactor bind.ContractTransactor) (*ComptrollerTransactor, error) {
+	contract, err := bindComptroller(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerTransactor{contract: contract}, nil
+}
+
+// NewComptrollerFilterer creates a new log filterer instance of Comptroller, bound to a specific deployed contract.
+func NewComptrollerFilterer(address common.Address, filterer bind.ContractFilterer) (*ComptrollerFilterer, error) {
+	contract, err := bindComptroller(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerFilterer{contract: contract}, nil
+}
+
+// bindComptroller binds a generic wrapper to an already deployed contract.
+func bindComptroller(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(ComptrollerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Comptroller *ComptrollerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Comptroller.Contract.ComptrollerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Comptroller *ComptrollerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Comptroller.Contract.ComptrollerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Comptroller *ComptrollerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Comptroller.Contract.ComptrollerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Comptroller *ComptrollerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Comptroller.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Comptroller *ComptrollerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Comptroller.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Comptroller *ComptrollerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Comptroller.Contract.contract.Transact(opts, method, params...)
+}
+
+// CheckMembership is a free data retrieval call binding the contract method 0x929fe9a1.
+//
+// Solidity: function checkMembership(address account, address cToken) view returns(bool)
+func (_Comptroller *ComptrollerCaller) CheckMembership(opts *bind.CallOpts, account common.Address, cToken common.Address) (bool, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "checkMembership", account, cToken)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// CheckMembership is a free data retrieval call binding the contract method 0x929fe9a1.
+//
+// Solidity: function checkMembership(address account, address cToken) view returns(bool)
+func (_Comptroller *ComptrollerSession) CheckMembership(account common.Address, cToken common.Address) (bool, error) {
+	return _Comptroller.Contract.CheckMembership(&_Comptroller.CallOpts, account, cToken)
+}
+
+// CheckMembership is a free data retrieval call binding the contract method 0x929fe9a1.
+//
+// Solidity: function checkMembership(address account, address cToken) view returns(bool)
+func (_Comptroller *ComptrollerCallerSession) CheckMembership(account common.Address, cToken common.Address) (bool, error) {
+	return _Comptroller.Contract.CheckMembership(&_Comptroller.CallOpts, account, cToken)
+}
+
+// GetAssetsIn is a free data retrieval call binding the contract method 0xabfceffc.
+//
+// Solidity: function getAssetsIn(address account) view returns(address[])
+func (_Comptroller *ComptrollerCaller) GetAssetsIn(opts *bind.CallOpts, account common.Address) ([]common.Address, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "getAssetsIn", account)
+
+	if err != nil {
+		return *new([]common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]common.Address)).(*[]common.Address)
+
+	return out0, err
+
+}
+
+// GetAssetsIn is a free data retrieval call binding the contract method 0xabfceffc.
+//
+// Solidity: function getAssetsIn(address account) view returns(address[])
+func (_Comptroller *ComptrollerSession) GetAssetsIn(account common.Address) ([]common.Address, error) {
+	return _Comptroller.Contract.GetAssetsIn(&_Comptroller.CallOpts, account)
+}
+
+// GetAssetsIn is a free data retrieval call binding the contract method 0xabfceffc.
+//
+// Solidity: function getAssetsIn(address account) view returns(address[])
+func (_Comptroller *ComptrollerCallerSession) GetAssetsIn(account common.Address) ([]common.Address, error) {
+	return _Comptroller.Contract.GetAssetsIn(&_Comptroller.CallOpts, account)
+}
+
+// Markets is a free data retrieval call binding the contract method 0x8e8f294b.
+//
+// Solidity: function markets(address ) view returns(bool isListed, uint256 collateralFactorMantissa, bool isComped)
+func (_Comptroller *ComptrollerCaller) Markets(opts *bind.CallOpts, arg0 common.Address) (struct {
+	IsListed                 bool
+	CollateralFactorMantissa *big.Int
+	IsComped                 bool
+}, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "markets", arg0)
+
+	outstruct := new(struct {
+		IsListed                 bool
+		CollateralFactorMantissa *big.Int
+		IsComped                 bool
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.IsListed = *abi.ConvertType(out[0], new(bool)).(*bool)
+	outstruct.CollateralFactorMantissa = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.IsComped = *abi.ConvertType(out[2], new(bool)).(*bool)
+
+	return *outstruct, err
+
+}
+
+// Markets is a free data retrieval call binding the contract method 0x8e8f294b.
+//
+// Solidity: function markets(address ) view returns(bool isListed, uint256 collateralFactorMantissa, bool isComped)
+func (_Comptroller *ComptrollerSession) Markets(arg0 common.Address) (struct {
+	IsListed                 bool
+	CollateralFactorMantissa *big.Int
+	IsComped                 bool
+}, error) {
+	return _Comptroller.Contract.Markets(&_Comptroller.CallOpts, arg0)
+}
+
+// Markets is a free data retrieval call binding the contract method 0x8e8f294b.
+//
+// Solidity: function markets(address ) view returns(bool isListed, uint256 collateralFactorMantissa, bool isComped)
+func (_Comptroller *ComptrollerCallerSession) Markets(arg0 common.Address) (struct {
+	IsListed                 bool
+	CollateralFactorMantissa *big.Int
+	IsComped                 bool
+}, error) {
+	return _Comptroller.Contract.Markets(&_Comptroller.CallOpts, arg0)
+}
+
+// ComptrollerMarketEnteredIterator is returned from FilterMarketEntered and is used to iterate over the raw logs and unpacked data for MarketEntered events raised by the Comptroller contract.
+type ComptrollerMarketEnteredIterator struct {
+	Event *ComptrollerMarketEntered // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ComptrollerMarketEnteredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ComptrollerMarketEntered)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ComptrollerMarketEntered)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ComptrollerMarketEnteredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ComptrollerMarketEnteredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ComptrollerMarketEntered represents a MarketEntered event raised by the Comptroller contract.
+type ComptrollerMarketEntered struct {
+	CToken  common.Address
+	Account common.Address
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterMarketEntered is a free log retrieval operation binding the contract event 0x3ab23ab0d51cccc0c3085aec51f99228625aa1a922b3a8ca89a26b0f2027a1a5.
+//
+// Solidity: event MarketEntered(address cToken, address account)
+func (_Comptroller *ComptrollerFilterer) FilterMarketEntered(opts *bind.FilterOpts) (*ComptrollerMarketEnteredIterator, error) {
+
+	logs, sub, err := _Comptroller.contract.FilterLogs(opts, "MarketEntered")
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerMarketEnteredIterator{contract: _Comptroller.contract, event: "MarketEntered", logs: logs, sub: sub}, nil
+}
+
+// WatchMarketEntered is a free log subscription operation binding the contract event 0x3ab23ab0d51cccc0c3085aec51f99228625aa1a922b3a8ca89a26b0f2027a1a5.
+//
+// Solidity: event MarketEntered(address cToken, address account)
+func (_Comptroller *ComptrollerFilterer) WatchMarketEntered(opts *bind.WatchOpts, sink chan<- *ComptrollerMarketEntered) (event.Subscription, error) {
+
+	logs, sub, err := _Comptroller.contract.WatchLogs(opts, "MarketEntered")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ComptrollerMarketEntered)
+				if err := _Comptroller.contract.UnpackLog(event, "MarketEntered", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseMarketEntered is a log parse operation binding the contract event 0x3ab23ab0d51cccc0c3085aec51f99228625aa1a922b3a8ca89a26b0f2027a1a5.
+//
+// Solidity: event MarketEntered(address cToken, address account)
+func (_Comptroller *ComptrollerFilterer) ParseMarketEntered(log types.Log) (*ComptrollerMarketEntered, error) {
+	event := new(ComptrollerMarketEntered)
+	if err := _Comptroller.contract.UnpackLog(event, "MarketEntered", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// ComptrollerMarketExitedIterator is returned from FilterMarketExited and is used to iterate over the raw logs and unpacked data for MarketExited events raised by the Comptroller contract.
+type ComptrollerMarketExitedIterator struct {
+	Event *ComptrollerMarketExited // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ComptrollerMarketExitedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ComptrollerMarketExited)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ComptrollerMarketExited)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ComptrollerMarketExitedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ComptrollerMarketExitedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ComptrollerMarketExited represents a MarketExited event raised by the Comptroller contract.
+type ComptrollerMarketExited struct {
+	CToken  common.Address
+	Account common.Address
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterMarketExited is a free log retrieval operation binding the contract event 0xe699a64c18b07ac5b7301aa273f36a2287239eb9501d81950672794afba29a0d.
+//
+// Solidity: event MarketExited(address cToken, address account)
+func (_Comptroller *ComptrollerFilterer) FilterMarketExited(opts *bind.FilterOpts) (*ComptrollerMarketExitedIterator, error) {
+
+	logs, sub, err := _Comptroller.contract.FilterLogs(opts, "MarketExited")
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerMarketExitedIterator{contract: _Comptroller.contract, event: "MarketExited", logs: logs, sub: sub}, nil
+}
+
+// WatchMarketExited is a free log subscription operation binding the contract event 0xe699a64c18b07ac5b7301aa273f36a2287239eb9501d81950672794afba29a0d.
+//
+// Solidity: event MarketExited(address cToken, address account)
+func (_Comptroller *ComptrollerFilterer) WatchMarketExited(opts *bind.WatchOpts, sink chan<- *ComptrollerMarketExited) (event.Subscription, error) {
+
+	logs, sub, err := _Comptroller.contract.WatchLogs(opts, "MarketExited")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ComptrollerMarketExited)
+				if err := _Comptroller.contract.UnpackLog(event, "MarketExited", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseMarketExited is a log parse operation binding the contract event 0xe699a64c18b07ac5b7301aa273f36a2287239eb9501d81950672794afba29a0d.
+//
+// Solidity: event MarketExited(address cToken, address account)
+func (_Comptroller *ComptrollerFilterer) ParseMarketExited(log types.Log) (*ComptrollerMarketExited, error) {
+	event := new(ComptrollerMarketExited)
+	if err := _Comptroller.contract.UnpackLog(event, "MarketExited", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
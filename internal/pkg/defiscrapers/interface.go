@@ -13,6 +13,12 @@ type DeFIHelper interface {
 	UpdateState() error
 }
 
+// protocolHelper is the helper of a protocol instance.
+type protocolHelper struct {
+	protocol dia.DefiProtocol
+	helper   DeFIHelper
+}
+
 type DefiScraper struct {
 	// signaling channels
 	shutdown     chan nothing
@@ -28,4 +34,7 @@ type DefiScraper struct {
 	datastore     models.Datastore
 	chanDefiRate  chan *dia.DefiRate
 	chanDefiState chan *dia.DefiProtocolState
+	// helpers holds the helpers of the scraped protocol instances by name, protocols their names in order.
+	helpers   map[string]protocolHelper
+	protocols []string
 }
@@ -0,0 +1,466 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package cdpmanagercontract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// CdpmanagercontractMetaData contains all meta data concerning the Cdpmanagercontract contract.
+var CdpmanagercontractMetaData = &bind.MetaData{
+	ABI: "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"usr\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"own\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"cdp\",\"type\":\"uint256\"}],\"name\":\"NewCdp\",\"type\":\"event\"},{\"constant\":true,\"inputs\":[],\"name\":\"cdpi\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"ilks\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"owns\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"urns\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// CdpmanagercontractABI is the input ABI used to generate the binding from.
+// Deprecated: Use CdpmanagercontractMetaData.ABI instead.
+var CdpmanagercontractABI = CdpmanagercontractMetaData.ABI
+
+// Cdpmanagercontract is an auto generated Go binding around an Ethereum contract.
+type Cdpmanagercontract struct {
+	CdpmanagercontractCaller     // Read-only binding to the contract
+	CdpmanagercontractTransactor // Write-only binding to the contract
+	CdpmanagercontractFilterer   // Log filterer for contract events
+}
+
+// CdpmanagercontractCaller is an auto generated read-only Go binding around an Ethereum contract.
+type CdpmanagercontractCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CdpmanagercontractTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type CdpmanagercontractTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CdpmanagercontractFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type CdpmanagercontractFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CdpmanagercontractSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type CdpmanagercontractSession struct {
+	Contract     *Cdpmanagercontract // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts       // Call options to use throughout this session
+	TransactOpts bind.TransactOpts   // Transaction auth options to use throughout this session
+}
+
+// CdpmanagercontractCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type CdpmanagercontractCallerSession struct {
+	Contract *CdpmanagercontractCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts             // Call options to use throughout this session
+}
+
+// CdpmanagercontractTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type CdpmanagercontractTransactorSession struct {
+	Contract     *CdpmanagercontractTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts             // Transaction auth options to use throughout this session
+}
+
+// CdpmanagercontractRaw is an auto generated low-level Go binding around an Ethereum contract.
+type CdpmanagercontractRaw struct {
+	Contract *Cdpmanagercontract // Generic contract binding to access the raw methods on
+}
+
+// CdpmanagercontractCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type CdpmanagercontractCallerRaw struct {
+	Contract *CdpmanagercontractCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// CdpmanagercontractTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type CdpmanagercontractTransactorRaw struct {
+	Contract *CdpmanagercontractTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewCdpmanagercontract creates a new instance of Cdpmanagercontract, bound to a specific deployed contract.
+func NewCdpmanagercontract(address common.Address, backend bind.ContractBackend) (*Cdpmanagercontract, error) {
+	contract, err := bindCdpmanagercontract(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Cdpmanagercontract{CdpmanagercontractCaller: CdpmanagercontractCaller{contract: contract}, CdpmanagercontractTransactor: CdpmanagercontractTransactor{contract: contract}, CdpmanagercontractFilterer: CdpmanagercontractFilterer{contract: contract}}, nil
+}
+
+// NewCdpmanagercontractCaller creates a new read-only instance of Cdpmanagercontract, bound to a specific deployed contract.
+func NewCdpmanagercontractCaller(address common.Address, caller bind.ContractCaller) (*CdpmanagercontractCaller, error) {
+	contract, err := bindCdpmanagercontract(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CdpmanagercontractCaller{contract: contract}, nil
+}
+
+// NewCdpmanagercontractTransactor creates a new write-only instance of Cdpmanagercontract, bound to a specific deployed contract.
+func NewCdpmanagercontractTransactor(address common.Address, transactor bind.ContractTransactor) (*CdpmanagercontractTransactor, error) {
+	contract, err := bindCdpmanagercontract(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CdpmanagercontractTransactor{contract: contract}, nil
+}
+
+// NewCdpmanagercontractFilterer creates a new log filterer instance of Cdpmanagercontract, bound to a specific deployed contract.
+func NewCdpmanagercontractFilterer(address common.Address, filterer bind.ContractFilterer) (*CdpmanagercontractFilterer, error) {
+	contract, err := bindCdpmanagercontract(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &CdpmanagercontractFilterer{contract: contract}, nil
+}
+
+// bindCdpmanagercontract binds a generic wrapper to an already deployed contract.
+func bindCdpmanagercontract(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(CdpmanagercontractABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Cdpmanagercontract *CdpmanagercontractRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Cdpmanagercontract.Contract.CdpmanagercontractCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Cdpmanagercontract *CdpmanagercontractRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Cdpmanagercontract.Contract.CdpmanagercontractTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Cdpmanagercontract *CdpmanagercontractRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Cdpmanagercontract.Contract.CdpmanagercontractTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Cdpmanagercontract *CdpmanagercontractCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Cdpmanagercontract.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Cdpmanagercontract *CdpmanagercontractTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Cdpmanagercontract.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Cdpmanagercontract *CdpmanagercontractTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Cdpmanagercontract.Contract.contract.Transact(opts, method, params...)
+}
+
+// Cdpi is a free data retrieval call binding the contract method 0xb3d178f2.
+//
+// Solidity: function cdpi() view returns(uint256)
+func (_Cdpmanagercontract *CdpmanagercontractCaller) Cdpi(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Cdpmanagercontract.contract.Call(opts, &out, "cdpi")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Cdpi is a free data retrieval call binding the contract method 0xb3d178f2.
+//
+// Solidity: function cdpi() view returns(uint256)
+func (_Cdpmanagercontract *CdpmanagercontractSession) Cdpi() (*big.Int, error) {
+	return _Cdpmanagercontract.Contract.Cdpi(&_Cdpmanagercontract.CallOpts)
+}
+
+// Cdpi is a free data retrieval call binding the contract method 0xb3d178f2.
+//
+// Solidity: function cdpi() view returns(uint256)
+func (_Cdpmanagercontract *CdpmanagercontractCallerSession) Cdpi() (*big.Int, error) {
+	return _Cdpmanagercontract.Contract.Cdpi(&_Cdpmanagercontract.CallOpts)
+}
+
+// Ilks is a free data retrieval call binding the contract method 0x2c2cb9fd.
+//
+// Solidity: function ilks(uint256 ) view returns(bytes32)
+func (_Cdpmanagercontract *CdpmanagercontractCaller) Ilks(opts *bind.CallOpts, arg0 *big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _Cdpmanagercontract.contract.Call(opts, &out, "ilks", arg0)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// Ilks is a free data retrieval call binding the contract method 0x2c2cb9fd.
+//
+// Solidity: function ilks(uint256 ) view returns(bytes32)
+func (_Cdpmanagercontract *CdpmanagercontractSession) Ilks(arg0 *big.Int) ([32]byte, error) {
+	return _Cdpmanagercontract.Contract.Ilks(&_Cdpmanagercontract.CallOpts, arg0)
+}
+
+// Ilks is a free data retrieval call binding the contract method 0x2c2cb9fd.
+//
+// Solidity: function ilks(uint256 ) view returns(bytes32)
+func (_Cdpmanagercontract *CdpmanagercontractCallerSession) Ilks(arg0 *big.Int) ([32]byte, error) {
+	return _Cdpmanagercontract.Contract.Ilks(&_Cdpmanagercontract.CallOpts, arg0)
+}
+
+// Owns is a free data retrieval call binding the contract method 0x8161b120.
+//
+// Solidity: function owns(uint256 ) view returns(address)
+func (_Cdpmanagercontract *CdpmanagercontractCaller) Owns(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _Cdpmanagercontract.contract.Call(opts, &out, "owns", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owns is a free data retrieval call binding the contract method 0x8161b120.
+//
+// Solidity: function owns(uint256 ) view returns(address)
+func (_Cdpmanagercontract *CdpmanagercontractSession) Owns(arg0 *big.Int) (common.Address, error) {
+	return _Cdpmanagercontract.Contract.Owns(&_Cdpmanagercontract.CallOpts, arg0)
+}
+
+// Owns is a free data retrieval call binding the contract method 0x8161b120.
+//
+// Solidity: function owns(uint256 ) view returns(address)
+func (_Cdpmanagercontract *CdpmanagercontractCallerSession) Owns(arg0 *big.Int) (common.Address, error) {
+	return _Cdpmanagercontract.Contract.Owns(&_Cdpmanagercontract.CallOpts, arg0)
+}
+
+// Urns is a free data retrieval call binding the contract method 0x2726b073.
+//
+// Solidity: function urns(uint256 ) view returns(address)
+func (_Cdpmanagercontract *CdpmanagercontractCaller) Urns(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _Cdpmanagercontract.contract.Call(opts, &out, "urns", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Urns is a free data retrieval call binding the contract method 0x2726b073.
+//
+// Solidity: function urns(uint256 ) view returns(address)
+func (_Cdpmanagercontract *CdpmanagercontractSession) Urns(arg0 *big.Int) (common.Address, error) {
+	return _Cdpmanagercontract.Contract.Urns(&_Cdpmanagercontract.CallOpts, arg0)
+}
+
+// Urns is a free data retrieval call binding the contract method 0x2726b073.
+//
+// Solidity: function urns(uint256 ) view returns(address)
+func (_Cdpmanagercontract *CdpmanagercontractCallerSession) Urns(arg0 *big.Int) (common.Address, error) {
+	return _Cdpmanagercontract.Contract.Urns(&_Cdpmanagercontract.CallOpts, arg0)
+}
+
+// CdpmanagercontractNewCdpIterator is returned from FilterNewCdp and is used to iterate over the raw logs and unpacked data for NewCdp events raised by the Cdpmanagercontract contract.
+type CdpmanagercontractNewCdpIterator struct {
+	Event *CdpmanagercontractNewCdp // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *CdpmanagercontractNewCdpIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(CdpmanagercontractNewCdp)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(CdpmanagercontractNewCdp)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *CdpmanagercontractNewCdpIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *CdpmanagercontractNewCdpIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// CdpmanagercontractNewCdp represents a NewCdp event raised by the Cdpmanagercontract contract.
+type CdpmanagercontractNewCdp struct {
+	Usr common.Address
+	Own common.Address
+	Cdp *big.Int
+	Raw types.Log // Blockchain specific contextual infos
+}
+
+// FilterNewCdp is a free log retrieval operation binding the contract event 0xd6be0bc178658a382ff4f91c8c68b542aa6b71685b8fe427966b87745c3ea7a2.
+//
+// Solidity: event NewCdp(address indexed usr, address indexed own, uint256 indexed cdp)
+func (_Cdpmanagercontract *CdpmanagercontractFilterer) FilterNewCdp(opts *bind.FilterOpts, usr []common.Address, own []common.Address, cdp []*big.Int) (*CdpmanagercontractNewCdpIterator, error) {
+
+	var usrRule []interface{}
+	for _, usrItem := range usr {
+		usrRule = append(usrRule, usrItem)
+	}
+	var ownRule []interface{}
+	for _, ownItem := range own {
+		ownRule = append(ownRule, ownItem)
+	}
+	var cdpRule []interface{}
+	for _, cdpItem := range cdp {
+		cdpRule = append(cdpRule, cdpItem)
+	}
+
+	logs, sub, err := _Cdpmanagercontract.contract.FilterLogs(opts, "NewCdp", usrRule, ownRule, cdpRule)
+	if err != nil {
+		return nil, err
+	}
+	return &CdpmanagercontractNewCdpIterator{contract: _Cdpmanagercontract.contract, event: "NewCdp", logs: logs, sub: sub}, nil
+}
+
+// WatchNewCdp is a free log subscription operation binding the contract event 0xd6be0bc178658a382ff4f91c8c68b542aa6b71685b8fe427966b87745c3ea7a2.
+//
+// Solidity: event NewCdp(address indexed usr, address indexed own, uint256 indexed cdp)
+func (_Cdpmanagercontract *CdpmanagercontractFilterer) WatchNewCdp(opts *bind.WatchOpts, sink chan<- *CdpmanagercontractNewCdp, usr []common.Address, own []common.Address, cdp []*big.Int) (event.Subscription, error) {
+
+	var usrRule []interface{}
+	for _, usrItem := range usr {
+		usrRule = append(usrRule, usrItem)
+	}
+	var ownRule []interface{}
+	for _, ownItem := range own {
+		ownRule = append(ownRule, ownItem)
+	}
+	var cdpRule []interface{}
+	for _, cdpItem := range cdp {
+		cdpRule = append(cdpRule, cdpItem)
+	}
+
+	logs, sub, err := _Cdpmanagercontract.contract.WatchLogs(opts, "NewCdp", usrRule, ownRule, cdpRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(CdpmanagercontractNewCdp)
+				if err := _Cdpmanagercontract.contract.UnpackLog(event, "NewCdp", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseNewCdp is a log parse operation binding the contract event 0xd6be0bc178658a382ff4f91c8c68b542aa6b71685b8fe427966b87745c3ea7a2.
+//
+// Solidity: event NewCdp(address indexed usr, address indexed own, uint256 indexed cdp)
+func (_Cdpmanagercontract *CdpmanagercontractFilterer) ParseNewCdp(log types.Log) (*CdpmanagercontractNewCdp, error) {
+	event := new(CdpmanagercontractNewCdp)
+	if err := _Cdpmanagercontract.contract.UnpackLog(event, "NewCdp", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
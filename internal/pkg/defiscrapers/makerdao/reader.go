@@ -0,0 +1,252 @@
+package makerdao
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/cdpmanagercontract"
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/jugcontract"
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/potcontract"
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/spotcontract"
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/vatcontract"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MakerDAO stores amounts as fixed point numbers with 18 (wad), 27 (ray) or 45 (rad) decimals.
+const (
+	wadDecimals = 18
+	rayDecimals = 27
+	radDecimals = 45
+
+	secondsPerYear = 365 * 24 * 3600
+)
+
+// Contracts are the addresses of the core contracts of a MakerDAO deployment.
+type Contracts struct {
+	Vat        common.Address
+	Pot        common.Address
+	Jug        common.Address
+	Spot       common.Address
+	CdpManager common.Address
+}
+
+// MainnetContracts are the contracts of MakerDAO on Ethereum, see https://chainlog.makerdao.com.
+var MainnetContracts = Contracts{
+	Vat:        common.HexToAddress("0x35D1b3F3D7966A1DFe207aa4514C12a259A0492B"),
+	Pot:        common.HexToAddress("0x197E90f9FAD81970bA7976f33CbD77088E5D7cf7"),
+	Jug:        common.HexToAddress("0x19c0976f590D67707E62397C87829d896Dc0f1F1"),
+	Spot:       common.HexToAddress("0x65C79fcB50Ca1594B025960e539eD7A9a6D434A3"),
+	CdpManager: common.HexToAddress("0x5ef30b9986345249bc32d8928B7ee64DE9435E39"),
+}
+
+// Reader reads the state of the ilks, the DSR and the vaults of a MakerDAO deployment.
+type Reader struct {
+	vat        *vatcontract.VatcontractCaller
+	pot        *potcontract.PotcontractCaller
+	jug        *jugcontract.JugcontractCaller
+	spot       *spotcontract.SpotcontractCaller
+	cdpManager *cdpmanagercontract.CdpmanagercontractCaller
+	// vaults caches the urns and ilks of the vaults by id, as they never change.
+	vaults []vaultRef
+}
+
+// vaultRef is the urn of a vault in the vat.
+type vaultRef struct {
+	ilk [32]byte
+	urn common.Address
+}
+
+// NewReader returns a reader of the MakerDAO deployment @contracts.
+func NewReader(caller bind.ContractCaller, contracts Contracts) (*Reader, error) {
+	vat, err := vatcontract.NewVatcontractCaller(contracts.Vat, caller)
+	if err != nil {
+		return nil, err
+	}
+	pot, err := potcontract.NewPotcontractCaller(contracts.Pot, caller)
+	if err != nil {
+		return nil, err
+	}
+	jug, err := jugcontract.NewJugcontractCaller(contracts.Jug, caller)
+	if err != nil {
+		return nil, err
+	}
+	spot, err := spotcontract.NewSpotcontractCaller(contracts.Spot, caller)
+	if err != nil {
+		return nil, err
+	}
+	cdpManager, err := cdpmanagercontract.NewCdpmanagercontractCaller(contracts.CdpManager, caller)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{vat: vat, pot: pot, jug: jug, spot: spot, cdpManager: cdpManager}, nil
+}
+
+// Ilk returns the state of the collateral type @ilk such as ETH-A. The time of the state is left to the caller.
+func (r *Reader) Ilk(opts *bind.CallOpts, ilk string) (state models.MakerDAOIlk, err error) {
+	name := IlkBytes(ilk)
+	vatIlk, err := r.vat.Ilks(opts, name)
+	if err != nil {
+		return state, fmt.Errorf("vat ilk %s: %v", ilk, err)
+	}
+	if vatIlk.Rate == nil || vatIlk.Rate.Sign() == 0 {
+		return state, fmt.Errorf("ilk %s is not initialised", ilk)
+	}
+	jugIlk, err := r.jug.Ilks(opts, name)
+	if err != nil {
+		return state, fmt.Errorf("jug ilk %s: %v", ilk, err)
+	}
+	base, err := r.jug.Base(opts)
+	if err != nil {
+		return state, fmt.Errorf("jug base: %v", err)
+	}
+	spotIlk, err := r.spot.Ilks(opts, name)
+	if err != nil {
+		return state, fmt.Errorf("spot ilk %s: %v", ilk, err)
+	}
+	par, err := r.spot.Par(opts)
+	if err != nil {
+		return state, fmt.Errorf("spot par: %v", err)
+	}
+
+	state.Ilk = ilk
+	// The debt is the normalised debt Art (wad) times the accumulated rate (ray).
+	state.Debt = fixedPoint(new(big.Int).Mul(vatIlk.Art, vatIlk.Rate), wadDecimals+rayDecimals)
+	state.DebtCeiling = fixedPoint(vatIlk.Line, radDecimals)
+	if state.DebtCeiling > 0 {
+		state.DebtCeilingUsage = state.Debt / state.DebtCeiling
+	}
+	state.DebtFloor = fixedPoint(vatIlk.Dust, radDecimals)
+	state.StabilityFee = AnnualRate(new(big.Int).Add(base, jugIlk.Duty))
+	state.LiquidationRatio = fixedPoint(spotIlk.Mat, rayDecimals)
+	// The spot price is the price in units of par, discounted by the liquidation ratio.
+	state.Price = fixedPoint(vatIlk.Spot, rayDecimals) * state.LiquidationRatio * fixedPoint(par, rayDecimals)
+	return state, nil
+}
+
+// Savings returns the state of the DSR along with the total debt. The time of the state is left to the caller.
+func (r *Reader) Savings(opts *bind.CallOpts) (savings models.MakerDAOSavings, err error) {
+	dsr, err := r.pot.Dsr(opts)
+	if err != nil {
+		return savings, fmt.Errorf("pot dsr: %v", err)
+	}
+	chi, err := r.pot.Chi(opts)
+	if err != nil {
+		return savings, fmt.Errorf("pot chi: %v", err)
+	}
+	pie, err := r.pot.Pie(opts)
+	if err != nil {
+		return savings, fmt.Errorf("pot pie: %v", err)
+	}
+	debt, err := r.vat.Debt(opts)
+	if err != nil {
+		return savings, fmt.Errorf("vat debt: %v", err)
+	}
+	line, err := r.vat.Line(opts)
+	if err != nil {
+		return savings, fmt.Errorf("vat line: %v", err)
+	}
+	savings.DSR = AnnualRate(dsr)
+	savings.Chi = fixedPoint(chi, rayDecimals)
+	// The savings are the normalised savings Pie (wad) times the accumulated rate chi (ray).
+	savings.TotalSavings = fixedPoint(new(big.Int).Mul(pie, chi), wadDecimals+rayDecimals)
+	savings.TotalDebt = fixedPoint(debt, radDecimals)
+	savings.DebtCeiling = fixedPoint(line, radDecimals)
+	return savings, nil
+}
+
+// Vaults returns the vaults of @ilks opened through the CDP manager. Vaults opened directly on the vat
+// are not included.
+func (r *Reader) Vaults(opts *bind.CallOpts, ilks []string) ([]models.MakerDAOVault, error) {
+	// The accumulated rates convert the normalised debt of the vaults into DAI.
+	rates := make(map[string]*big.Int)
+	for _, ilk := range ilks {
+		vatIlk, err := r.vat.Ilks(opts, IlkBytes(ilk))
+		if err != nil {
+			return nil, fmt.Errorf("vat ilk %s: %v", ilk, err)
+		}
+		rates[ilk] = vatIlk.Rate
+	}
+	if err := r.updateVaultRefs(opts); err != nil {
+		return nil, err
+	}
+
+	var vaults []models.MakerDAOVault
+	for i, ref := range r.vaults {
+		ilk := IlkString(ref.ilk)
+		rate, ok := rates[ilk]
+		if !ok {
+			continue
+		}
+		urn, err := r.vat.Urns(opts, ref.ilk, ref.urn)
+		if err != nil {
+			return nil, fmt.Errorf("urn of vault %d: %v", i+1, err)
+		}
+		if urn.Ink.Sign() == 0 && urn.Art.Sign() == 0 {
+			continue
+		}
+		vaults = append(vaults, models.MakerDAOVault{
+			ID:  uint64(i + 1),
+			Ilk: ilk,
+			Urn: ref.urn.Hex(),
+			// Collateral is stored with 18 decimals in the vat regardless of the token.
+			Collateral: fixedPoint(urn.Ink, wadDecimals),
+			Debt:       fixedPoint(new(big.Int).Mul(urn.Art, rate), wadDecimals+rayDecimals),
+		})
+	}
+	return vaults, nil
+}
+
+// updateVaultRefs fetches the urns of the vaults opened since the last call. Vault ids start at 1.
+func (r *Reader) updateVaultRefs(opts *bind.CallOpts) error {
+	cdpi, err := r.cdpManager.Cdpi(opts)
+	if err != nil {
+		return fmt.Errorf("cdp manager cdpi: %v", err)
+	}
+	for id := uint64(len(r.vaults)) + 1; id <= cdpi.Uint64(); id++ {
+		cdp := new(big.Int).SetUint64(id)
+		urn, err := r.cdpManager.Urns(opts, cdp)
+		if err != nil {
+			return fmt.Errorf("urn of vault %d: %v", id, err)
+		}
+		ilk, err := r.cdpManager.Ilks(opts, cdp)
+		if err != nil {
+			return fmt.Errorf("ilk of vault %d: %v", id, err)
+		}
+		r.vaults = append(r.vaults, vaultRef{ilk: ilk, urn: urn})
+	}
+	return nil
+}
+
+// IlkBytes returns the name of an ilk as stored in the contracts.
+func IlkBytes(ilk string) (name [32]byte) {
+	copy(name[:], ilk)
+	return
+}
+
+// IlkString returns the name of an ilk as stored in the contracts as a string.
+func IlkString(name [32]byte) string {
+	return string(bytes.TrimRight(name[:], "\x00"))
+}
+
+// AnnualRate returns the yearly rate in per cent of the per second rate @perSecond, which is a ray such as
+// the DSR or the base rate plus the duty of an ilk.
+func AnnualRate(perSecond *big.Int) float64 {
+	if perSecond == nil {
+		return 0
+	}
+	return (math.Pow(fixedPoint(perSecond, rayDecimals), secondsPerYear) - 1) * 100
+}
+
+// fixedPoint returns the fixed point number @x with @decimals decimals as a float.
+func fixedPoint(x *big.Int, decimals int) float64 {
+	if x == nil {
+		return 0
+	}
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(x), scale).Float64()
+	return f
+}
@@ -0,0 +1,49 @@
+package makerdao
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestAnnualRate(t *testing.T) {
+	for _, c := range []struct {
+		perSecond string
+		want      float64
+	}{
+		{"1000000000000000000000000000", 0},
+		// 1% and 2% per year as set in the jug and pot.
+		{"1000000000315522921573372069", 1},
+		{"1000000000627937192491029810", 2},
+	} {
+		perSecond, _ := new(big.Int).SetString(c.perSecond, 10)
+		if got := AnnualRate(perSecond); math.Abs(got-c.want) > 1e-6 {
+			t.Errorf("annual rate of %s was incorrect, got: %v, want: %v.", c.perSecond, got, c.want)
+		}
+	}
+}
+
+func TestFixedPoint(t *testing.T) {
+	rad, _ := new(big.Int).SetString("15000000000000000000000000000000000000000000000000000", 10)
+	for _, c := range []struct {
+		x        *big.Int
+		decimals int
+		want     float64
+	}{
+		{rad, radDecimals, 15e6},
+		{big.NewInt(1500000000000000000), wadDecimals, 1.5},
+		{nil, wadDecimals, 0},
+	} {
+		if got := fixedPoint(c.x, c.decimals); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("fixed point of %v was incorrect, got: %v, want: %v.", c.x, got, c.want)
+		}
+	}
+}
+
+func TestIlkNames(t *testing.T) {
+	for _, ilk := range []string{"ETH-A", "UNIV2DAIETH-A", ""} {
+		if got := IlkString(IlkBytes(ilk)); got != ilk {
+			t.Errorf("ilk name was incorrect, got: %q, want: %q.", got, ilk)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package spotcontract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// SpotcontractMetaData contains all meta data concerning the Spotcontract contract.
+var SpotcontractMetaData = &bind.MetaData{
+	ABI: "[{\"constant\":true,\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"ilks\",\"outputs\":[{\"internalType\":\"contractPipLike\",\"name\":\"pip\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"mat\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"par\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// SpotcontractABI is the input ABI used to generate the binding from.
+// Deprecated: Use SpotcontractMetaData.ABI instead.
+var SpotcontractABI = SpotcontractMetaData.ABI
+
+// Spotcontract is an auto generated Go binding around an Ethereum contract.
+type Spotcontract struct {
+	SpotcontractCaller     // Read-only binding to the contract
+	SpotcontractTransactor // Write-only binding to the contract
+	SpotcontractFilterer   // Log filterer for contract events
+}
+
+// SpotcontractCaller is an auto generated read-only Go binding around an Ethereum contract.
+type SpotcontractCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SpotcontractTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type SpotcontractTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SpotcontractFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type SpotcontractFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SpotcontractSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type SpotcontractSession struct {
+	Contract     *Spotcontract     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// SpotcontractCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type SpotcontractCallerSession struct {
+	Contract *SpotcontractCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// SpotcontractTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type SpotcontractTransactorSession struct {
+	Contract     *SpotcontractTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// SpotcontractRaw is an auto generated low-level Go binding around an Ethereum contract.
+type SpotcontractRaw struct {
+	Contract *Spotcontract // Generic contract binding to access the raw methods on
+}
+
+// SpotcontractCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type SpotcontractCallerRaw struct {
+	Contract *SpotcontractCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// SpotcontractTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type SpotcontractTransactorRaw struct {
+	Contract *SpotcontractTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewSpotcontract creates a new instance of Spotcontract, bound to a specific deployed contract.
+func NewSpotcontract(address common.Address, backend bind.ContractBackend) (*Spotcontract, error) {
+	contract, err := bindSpotcontract(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Spotcontract{SpotcontractCaller: SpotcontractCaller{contract: contract}, SpotcontractTransactor: SpotcontractTransactor{contract: contract}, SpotcontractFilterer: SpotcontractFilterer{contract: contract}}, nil
+}
+
+// NewSpotcontractCaller creates a new read-only instance of Spotcontract, bound to a specific deployed contract.
+func NewSpotcontractCaller(address common.Address, caller bind.ContractCaller) (*SpotcontractCaller, error) {
+	contract, err := bindSpotcontract(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SpotcontractCaller{contract: contract}, nil
+}
+
+// NewSpotcontractTransactor creates a new write-only instance of Spotcontract, bound to a specific deployed contract.
+func NewSpotcontractTransactor(address common.Address, transactor bind.ContractTransactor) (*SpotcontractTransactor, error) {
+	contract, err := bindSpotcontract(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SpotcontractTransactor{contract: contract}, nil
+}
+
+// NewSpotcontractFilterer creates a new log filterer instance of Spotcontract, bound to a specific deployed contract.
+func NewSpotcontractFilterer(address common.Address, filterer bind.ContractFilterer) (*SpotcontractFilterer, error) {
+	contract, err := bindSpotcontract(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &SpotcontractFilterer{contract: contract}, nil
+}
+
+// bindSpotcontract binds a generic wrapper to an already deployed contract.
+func bindSpotcontract(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(SpotcontractABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Spotcontract *SpotcontractRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Spotcontract.Contract.SpotcontractCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Spotcontract *SpotcontractRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Spotcontract.Contract.SpotcontractTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Spotcontract *SpotcontractRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Spotcontract.Contract.SpotcontractTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Spotcontract *SpotcontractCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Spotcontract.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Spotcontract *SpotcontractTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Spotcontract.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Spotcontract *SpotcontractTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Spotcontract.Contract.contract.Transact(opts, method, params...)
+}
+
+// Ilks is a free data retrieval call binding the contract method 0xd9638d36.
+//
+// Solidity: function ilks(bytes32 ) view returns(address pip, uint256 mat)
+func (_Spotcontract *SpotcontractCaller) Ilks(opts *bind.CallOpts, arg0 [32]byte) (struct {
+	Pip common.Address
+	Mat *big.Int
+}, error) {
+	var out []interface{}
+	err := _Spotcontract.contract.Call(opts, &out, "ilks", arg0)
+
+	outstruct := new(struct {
+		Pip common.Address
+		Mat *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Pip = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Mat = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// Ilks is a free data retrieval call binding the contract method 0xd9638d36.
+//
+// Solidity: function ilks(bytes32 ) view returns(address pip, uint256 mat)
+func (_Spotcontract *SpotcontractSession) Ilks(arg0 [32]byte) (struct {
+	Pip common.Address
+	Mat *big.Int
+}, error) {
+	return _Spotcontract.Contract.Ilks(&_Spotcontract.CallOpts, arg0)
+}
+
+// Ilks is a free data retrieval call binding the contract method 0xd9638d36.
+//
+// Solidity: function ilks(bytes32 ) view returns(address pip, uint256 mat)
+func (_Spotcontract *SpotcontractCallerSession) Ilks(arg0 [32]byte) (struct {
+	Pip common.Address
+	Mat *big.Int
+}, error) {
+	return _Spotcontract.Contract.Ilks(&_Spotcontract.CallOpts, arg0)
+}
+
+// Par is a free data retrieval call binding the contract method 0x495d32cb.
+//
+// Solidity: function par() view returns(uint256)
+func (_Spotcontract *SpotcontractCaller) Par(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Spotcontract.contract.Call(opts, &out, "par")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Par is a free data retrieval call binding the contract method 0x495d32cb.
+//
+// Solidity: function par() view returns(uint256)
+func (_Spotcontract *SpotcontractSession) Par() (*big.Int, error) {
+	return _Spotcontract.Contract.Par(&_Spotcontract.CallOpts)
+}
+
+// Par is a free data retrieval call binding the contract method 0x495d32cb.
+//
+// Solidity: function par() view returns(uint256)
+func (_Spotcontract *SpotcontractCallerSession) Par() (*big.Int, error) {
+	return _Spotcontract.Contract.Par(&_Spotcontract.CallOpts)
+}
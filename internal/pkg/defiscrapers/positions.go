@@ -0,0 +1,100 @@
+package defiscrapers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// PositionIndexer reads the positions of accounts and the liquidations on a lending protocol.
+// IndexPositions returns the positions changed by the protocol events in a block range, read from the
+// protocol contracts at the end of the range, and the liquidations in the range. ReadPositions reads the
+// current positions of the accounts and markets of @positions at @blockNumber, e.g. to update accrued interest.
+type PositionIndexer interface {
+	IndexPositions(ctx context.Context, fromBlock, toBlock uint64) ([]models.DefiPositionChange, []models.DefiLiquidation, error)
+	ReadPositions(ctx context.Context, positions []models.DefiPosition, blockNumber uint64) ([]models.DefiPositionChange, error)
+}
+
+// PositionIndexerFactory returns the position indexer of the protocol instance @conf.
+type PositionIndexerFactory func(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) (PositionIndexer, error)
+
+var positionIndexerFactories = map[string]PositionIndexerFactory{
+	"AAVEv2": func(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) (PositionIndexer, error) {
+		return NewAAVEv2PositionIndexer(ctx, conf, client)
+	},
+	"COMPOUND": func(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) (PositionIndexer, error) {
+		return NewCompoundPositionIndexer(ctx, conf, client)
+	},
+}
+
+// NewPositionIndexer returns the position indexer of the protocol instance @conf
+// together with the client it reads the events with.
+func NewPositionIndexer(ctx context.Context, conf ProtocolConfig) (PositionIndexer, *ethclient.Client, error) {
+	factory, ok := positionIndexerFactories[conf.Type]
+	if !ok {
+		return nil, nil, fmt.Errorf("positions of protocol type %s of %s cannot be indexed", conf.Type, conf.Name)
+	}
+	client, err := conf.ETHClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	indexer, err := factory(ctx, conf, client)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	return indexer, client, nil
+}
+
+// blockTimes caches the timestamps of blocks while indexing a block range.
+type blockTimes struct {
+	client *ethclient.Client
+	times  map[uint64]time.Time
+}
+
+func newBlockTimes(client *ethclient.Client) *blockTimes {
+	return &blockTimes{client: client, times: make(map[uint64]time.Time)}
+}
+
+func (b *blockTimes) time(ctx context.Context, blockNumber uint64) (time.Time, error) {
+	if timestamp, ok := b.times[blockNumber]; ok {
+		return timestamp, nil
+	}
+	header, err := b.client.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return time.Time{}, err
+	}
+	timestamp := time.Unix(int64(header.Time), 0).UTC()
+	b.times[blockNumber] = timestamp
+	return timestamp, nil
+}
+
+// positionKey is the position of an account in a market, identified by the address of its
+// aToken or cToken, or of its reserve.
+type positionKey struct {
+	account common.Address
+	market  common.Address
+}
+
+// touchedPositions are the positions changed by events, each with the position of the last of these events.
+type touchedPositions map[positionKey]models.DefiPositionChange
+
+// add marks the position of @account in @market as changed by @txLog. Transfers from and to the
+// zero address are mints and burns, which are also indexed as the protocol event causing them.
+func (touched touchedPositions) add(account, market common.Address, txLog types.Log, timestamp time.Time) {
+	if account == (common.Address{}) {
+		return
+	}
+	touched[positionKey{account: account, market: market}] = models.DefiPositionChange{
+		Account:     account.Hex(),
+		BlockNumber: txLog.BlockNumber,
+		LogIndex:    txLog.Index,
+		Time:        timestamp,
+	}
+}
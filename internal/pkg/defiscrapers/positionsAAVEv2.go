@@ -0,0 +1,339 @@
+package defiscrapers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/aave/contract"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// AAVEv2PositionIndexer indexes the positions and liquidations on the lending pool of an AAVE v2 market.
+type AAVEv2PositionIndexer struct {
+	protocol    dia.DefiProtocol
+	client      *ethclient.Client
+	poolAddress common.Address
+	pool        *contract.LendingPoolFilterer
+	poolCaller  *contract.LendingPoolCaller
+	aToken      *contract.ATokenFilterer
+	events      map[string]common.Hash
+	// reserves caches the reserves by address, aTokens maps the aTokens to their reserves.
+	reserves map[common.Address]aaveReserve
+	aTokens  map[common.Address]common.Address
+}
+
+// aaveReserve is a reserve of the lending pool together with the tokens of its deposits and debts.
+type aaveReserve struct {
+	asset    dia.Asset
+	decimals int
+	// id is the index of the reserve in the user configuration bitmap.
+	id           uint8
+	aToken       *contract.ERC20Caller
+	stableDebt   *contract.ERC20Caller
+	variableDebt *contract.ERC20Caller
+}
+
+// NewAAVEv2PositionIndexer returns the position indexer of the AAVE v2 market @conf.
+func NewAAVEv2PositionIndexer(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) (*AAVEv2PositionIndexer, error) {
+	provider, ok := conf.Contracts[aaveAddressesProvider]
+	if !ok {
+		return nil, fmt.Errorf("aave v2 protocol %s needs contract %s", conf.Name, aaveAddressesProvider)
+	}
+	addrProvider, err := contract.NewILendingPoolAddressesProviderCaller(common.HexToAddress(provider), client)
+	if err != nil {
+		return nil, err
+	}
+	poolAddress, err := addrProvider.GetLendingPool(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("lending pool of %s: %v", conf.Name, err)
+	}
+	pool, err := contract.NewLendingPoolFilterer(poolAddress, client)
+	if err != nil {
+		return nil, err
+	}
+	poolCaller, err := contract.NewLendingPoolCaller(poolAddress, client)
+	if err != nil {
+		return nil, err
+	}
+	// All aTokens share the events, so a single filterer parses the logs of all reserves.
+	aToken, err := contract.NewATokenFilterer(common.Address{}, client)
+	if err != nil {
+		return nil, err
+	}
+	poolABI, err := abi.JSON(strings.NewReader(contract.LendingPoolABI))
+	if err != nil {
+		return nil, err
+	}
+	aTokenABI, err := abi.JSON(strings.NewReader(contract.ATokenABI))
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[string]common.Hash)
+	for _, name := range []string{"Deposit", "Withdraw", "Borrow", "Repay", "LiquidationCall", "ReserveUsedAsCollateralEnabled", "ReserveUsedAsCollateralDisabled"} {
+		events[name] = poolABI.Events[name].ID
+	}
+	events["BalanceTransfer"] = aTokenABI.Events["BalanceTransfer"].ID
+	return &AAVEv2PositionIndexer{
+		protocol:    conf.Protocol(),
+		client:      client,
+		poolAddress: poolAddress,
+		pool:        pool,
+		poolCaller:  poolCaller,
+		aToken:      aToken,
+		events:      events,
+		reserves:    make(map[common.Address]aaveReserve),
+		aTokens:     make(map[common.Address]common.Address),
+	}, nil
+}
+
+// IndexPositions returns the positions changed in the blocks [@fromBlock, @toBlock] and the liquidations.
+// Deposits, withdrawals, borrows, repayments, liquidations, transfers of aTokens, including those to
+// liquidators, and changes of the collateral usage mark the positions of the involved accounts as changed.
+// These are read at @toBlock from the aToken and debt token balances, which include the accrued interest.
+func (indexer *AAVEv2PositionIndexer) IndexPositions(ctx context.Context, fromBlock, toBlock uint64) (changes []models.DefiPositionChange, liquidations []models.DefiLiquidation, err error) {
+	reserveAddresses, err := indexer.poolCaller.GetReservesList(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(toBlock)})
+	if err != nil {
+		err = fmt.Errorf("reserves of %s: %v", indexer.protocol.Name, err)
+		return
+	}
+	addresses := []common.Address{indexer.poolAddress}
+	for _, reserveAddress := range reserveAddresses {
+		if _, err = indexer.reserve(ctx, reserveAddress); err != nil {
+			return
+		}
+	}
+	for aToken := range indexer.aTokens {
+		addresses = append(addresses, aToken)
+	}
+	var topics []common.Hash
+	for _, id := range indexer.events {
+		topics = append(topics, id)
+	}
+	logs, err := indexer.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: addresses,
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		return
+	}
+
+	times := newBlockTimes(indexer.client)
+	touched := make(touchedPositions)
+	for _, txLog := range logs {
+		if txLog.Removed || len(txLog.Topics) == 0 {
+			continue
+		}
+		var timestamp time.Time
+		if timestamp, err = times.time(ctx, txLog.BlockNumber); err != nil {
+			return
+		}
+
+		switch txLog.Topics[0] {
+		case indexer.events["Deposit"]:
+			var event *contract.LendingPoolDeposit
+			if event, err = indexer.pool.ParseDeposit(txLog); err != nil {
+				return
+			}
+			touched.add(event.OnBehalfOf, event.Reserve, txLog, timestamp)
+
+		case indexer.events["Withdraw"]:
+			var event *contract.LendingPoolWithdraw
+			if event, err = indexer.pool.ParseWithdraw(txLog); err != nil {
+				return
+			}
+			touched.add(event.User, event.Reserve, txLog, timestamp)
+
+		case indexer.events["Borrow"]:
+			var event *contract.LendingPoolBorrow
+			if event, err = indexer.pool.ParseBorrow(txLog); err != nil {
+				return
+			}
+			touched.add(event.OnBehalfOf, event.Reserve, txLog, timestamp)
+
+		case indexer.events["Repay"]:
+			var event *contract.LendingPoolRepay
+			if event, err = indexer.pool.ParseRepay(txLog); err != nil {
+				return
+			}
+			touched.add(event.User, event.Reserve, txLog, timestamp)
+
+		case indexer.events["ReserveUsedAsCollateralEnabled"]:
+			var event *contract.LendingPoolReserveUsedAsCollateralEnabled
+			if event, err = indexer.pool.ParseReserveUsedAsCollateralEnabled(txLog); err != nil {
+				return
+			}
+			touched.add(event.User, event.Reserve, txLog, timestamp)
+
+		case indexer.events["ReserveUsedAsCollateralDisabled"]:
+			var event *contract.LendingPoolReserveUsedAsCollateralDisabled
+			if event, err = indexer.pool.ParseReserveUsedAsCollateralDisabled(txLog); err != nil {
+				return
+			}
+			touched.add(event.User, event.Reserve, txLog, timestamp)
+
+		case indexer.events["BalanceTransfer"]:
+			reserveAddress, ok := indexer.aTokens[txLog.Address]
+			if !ok {
+				continue
+			}
+			var event *contract.ATokenBalanceTransfer
+			if event, err = indexer.aToken.ParseBalanceTransfer(txLog); err != nil {
+				return
+			}
+			touched.add(event.From, reserveAddress, txLog, timestamp)
+			touched.add(event.To, reserveAddress, txLog, timestamp)
+
+		case indexer.events["LiquidationCall"]:
+			var liquidation models.DefiLiquidation
+			if liquidation, err = indexer.liquidation(ctx, txLog, timestamp); err != nil {
+				return
+			}
+			liquidations = append(liquidations, liquidation)
+			borrower := common.HexToAddress(liquidation.Borrower)
+			touched.add(borrower, common.HexToAddress(liquidation.DebtAsset.Address), txLog, timestamp)
+			touched.add(borrower, common.HexToAddress(liquidation.CollateralAsset.Address), txLog, timestamp)
+		}
+	}
+	changes, err = indexer.readPositions(ctx, touched, toBlock)
+	return
+}
+
+// ReadPositions returns the positions of the accounts and reserves of @positions at @blockNumber.
+func (indexer *AAVEv2PositionIndexer) ReadPositions(ctx context.Context, positions []models.DefiPosition, blockNumber uint64) ([]models.DefiPositionChange, error) {
+	timestamp, err := newBlockTimes(indexer.client).time(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	touched := make(touchedPositions)
+	for _, position := range positions {
+		touched.add(common.HexToAddress(position.Account), common.HexToAddress(position.Asset.Address), types.Log{BlockNumber: blockNumber}, timestamp)
+	}
+	return indexer.readPositions(ctx, touched, blockNumber)
+}
+
+// readPositions reads the @touched positions at @blockNumber. The collateral of a position is its aToken
+// balance if the account uses the reserve as collateral and zero otherwise, its debt the sum of the stable
+// and variable debt token balances.
+func (indexer *AAVEv2PositionIndexer) readPositions(ctx context.Context, touched touchedPositions, blockNumber uint64) (changes []models.DefiPositionChange, err error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber)}
+	configurations := make(map[common.Address]*big.Int)
+	for key, change := range touched {
+		var reserve aaveReserve
+		if reserve, err = indexer.reserve(ctx, key.market); err != nil {
+			return
+		}
+		configuration, ok := configurations[key.account]
+		if !ok {
+			var userConfiguration contract.DataTypesUserConfigurationMap
+			if userConfiguration, err = indexer.poolCaller.GetUserConfiguration(opts, key.account); err != nil {
+				err = fmt.Errorf("configuration of %s at block %d: %v", change.Account, blockNumber, err)
+				return
+			}
+			configuration = userConfiguration.Data
+			configurations[key.account] = configuration
+		}
+		var balance, stableDebt, variableDebt *big.Int
+		if balance, err = reserve.aToken.BalanceOf(opts, key.account); err != nil {
+			err = fmt.Errorf("%s deposits of %s at block %d: %v", reserve.asset.Symbol, change.Account, blockNumber, err)
+			return
+		}
+		if stableDebt, err = reserve.stableDebt.BalanceOf(opts, key.account); err != nil {
+			err = fmt.Errorf("%s stable debt of %s at block %d: %v", reserve.asset.Symbol, change.Account, blockNumber, err)
+			return
+		}
+		if variableDebt, err = reserve.variableDebt.BalanceOf(opts, key.account); err != nil {
+			err = fmt.Errorf("%s variable debt of %s at block %d: %v", reserve.asset.Symbol, change.Account, blockNumber, err)
+			return
+		}
+
+		change.Asset = reserve.asset
+		// Bit 2*id+1 of the user configuration is set if the reserve is used as collateral.
+		if configuration.Bit(2*int(reserve.id)+1) == 1 {
+			change.Collateral = bigAmount(balance, reserve.decimals)
+		}
+		change.Debt = bigAmount(new(big.Int).Add(stableDebt, variableDebt), reserve.decimals)
+		change.SetCollateral, change.SetDebt = true, true
+		changes = append(changes, change)
+	}
+	return
+}
+
+func (indexer *AAVEv2PositionIndexer) liquidation(ctx context.Context, txLog types.Log, timestamp time.Time) (liquidation models.DefiLiquidation, err error) {
+	event, err := indexer.pool.ParseLiquidationCall(txLog)
+	if err != nil {
+		return
+	}
+	liquidation = models.DefiLiquidation{
+		Protocol:    indexer.protocol.Name,
+		Borrower:    event.User.Hex(),
+		Liquidator:  event.Liquidator.Hex(),
+		BlockNumber: txLog.BlockNumber,
+		TxHash:      txLog.TxHash.Hex(),
+		LogIndex:    txLog.Index,
+		Time:        timestamp,
+	}
+	if liquidation.DebtAmount, liquidation.DebtAsset, err = indexer.amount(ctx, event.DebtAsset, event.DebtToCover); err != nil {
+		return
+	}
+	liquidation.CollateralAmount, liquidation.CollateralAsset, err = indexer.amount(ctx, event.CollateralAsset, event.LiquidatedCollateralAmount)
+	return
+}
+
+// amount returns @amount in units of the reserve @reserveAddress.
+func (indexer *AAVEv2PositionIndexer) amount(ctx context.Context, reserveAddress common.Address, amount *big.Int) (float64, dia.Asset, error) {
+	reserve, err := indexer.reserve(ctx, reserveAddress)
+	if err != nil {
+		return 0, dia.Asset{}, err
+	}
+	return bigAmount(amount, reserve.decimals), reserve.asset, nil
+}
+
+// reserve returns the reserve @reserveAddress of the lending pool.
+func (indexer *AAVEv2PositionIndexer) reserve(ctx context.Context, reserveAddress common.Address) (aaveReserve, error) {
+	if reserve, ok := indexer.reserves[reserveAddress]; ok {
+		return reserve, nil
+	}
+	token, err := contract.NewERC20Caller(reserveAddress, indexer.client)
+	if err != nil {
+		return aaveReserve{}, err
+	}
+	decimals, err := token.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return aaveReserve{}, fmt.Errorf("decimals of reserve %s: %v", reserveAddress.Hex(), err)
+	}
+	data, err := indexer.poolCaller.GetReserveData(&bind.CallOpts{Context: ctx}, reserveAddress)
+	if err != nil {
+		return aaveReserve{}, fmt.Errorf("data of reserve %s: %v", reserveAddress.Hex(), err)
+	}
+	reserve := aaveReserve{
+		asset:    dia.Asset{Address: reserveAddress.Hex(), Blockchain: indexer.protocol.UnderlyingBlockchain},
+		decimals: int(decimals),
+		id:       data.Id,
+	}
+	if reserve.aToken, err = contract.NewERC20Caller(data.ATokenAddress, indexer.client); err != nil {
+		return aaveReserve{}, err
+	}
+	if reserve.stableDebt, err = contract.NewERC20Caller(data.StableDebtTokenAddress, indexer.client); err != nil {
+		return aaveReserve{}, err
+	}
+	if reserve.variableDebt, err = contract.NewERC20Caller(data.VariableDebtTokenAddress, indexer.client); err != nil {
+		return aaveReserve{}, err
+	}
+	// Some tokens such as MKR return their symbol as bytes32, it is then left empty.
+	reserve.asset.Symbol, _ = token.Symbol(&bind.CallOpts{Context: ctx})
+	indexer.reserves[reserveAddress] = reserve
+	indexer.aTokens[data.ATokenAddress] = reserveAddress
+	return reserve, nil
+}
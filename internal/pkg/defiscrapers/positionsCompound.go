@@ -0,0 +1,348 @@
+package defiscrapers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	compoundcontract "github.com/diadata-org/diadata/internal/pkg/defiscrapers/compound"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// CompoundPositionIndexer indexes the positions and liquidations on the markets of Compound and its forks.
+type CompoundPositionIndexer struct {
+	protocol           dia.DefiProtocol
+	client             *ethclient.Client
+	cToken             *compoundcontract.CTokenFilterer
+	comptrollerAddress common.Address
+	comptroller        *compoundcontract.ComptrollerFilterer
+	comptrollerCaller  *compoundcontract.ComptrollerCaller
+	events             map[string]common.Hash
+	markets            map[common.Address]compoundMarket
+	// cTokens maps the addresses of the underlying assets to their cTokens.
+	cTokens map[string]common.Address
+}
+
+// compoundMarket is the underlying asset of a cToken.
+type compoundMarket struct {
+	asset    dia.Asset
+	decimals int
+	cToken   *compoundcontract.CTokenCaller
+}
+
+// NewCompoundPositionIndexer returns the position indexer of the Compound instance @conf,
+// whose assets map the symbols of the underlying assets to the addresses of their cTokens.
+func NewCompoundPositionIndexer(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) (*CompoundPositionIndexer, error) {
+	if len(conf.Assets) == 0 {
+		return nil, fmt.Errorf("compound protocol %s needs assets", conf.Name)
+	}
+	markets := make(map[common.Address]compoundMarket)
+	cTokens := make(map[string]common.Address)
+	var comptrollerAddress common.Address
+	for symbol, address := range conf.Assets {
+		cTokenCaller, err := compoundcontract.NewCTokenCaller(common.HexToAddress(address), client)
+		if err != nil {
+			return nil, err
+		}
+		market := compoundMarket{
+			asset:    dia.Asset{Symbol: symbol, Address: common.Address{}.Hex(), Blockchain: conf.Blockchain},
+			decimals: 18,
+			cToken:   cTokenCaller,
+		}
+		if symbol != "ETH" {
+			cErc20, err := compoundcontract.NewCErc20Caller(common.HexToAddress(address), client)
+			if err != nil {
+				return nil, err
+			}
+			underlying, err := cErc20.Underlying(&bind.CallOpts{Context: ctx})
+			if err != nil {
+				return nil, fmt.Errorf("underlying of %s: %v", symbol, err)
+			}
+			token, err := compoundcontract.NewCErc20Caller(underlying, client)
+			if err != nil {
+				return nil, err
+			}
+			decimals, err := token.Decimals(&bind.CallOpts{Context: ctx})
+			if err != nil {
+				return nil, fmt.Errorf("decimals of %s: %v", symbol, err)
+			}
+			market.asset.Address = underlying.Hex()
+			market.decimals = int(decimals.Int64())
+		}
+		if comptrollerAddress == (common.Address{}) {
+			if comptrollerAddress, err = cTokenCaller.Comptroller(&bind.CallOpts{Context: ctx}); err != nil {
+				return nil, fmt.Errorf("comptroller of %s: %v", symbol, err)
+			}
+		}
+		markets[common.HexToAddress(address)] = market
+		cTokens[market.asset.Address] = common.HexToAddress(address)
+	}
+
+	// All cTokens share the events, so a single filterer parses the logs of all markets.
+	cToken, err := compoundcontract.NewCTokenFilterer(common.Address{}, client)
+	if err != nil {
+		return nil, err
+	}
+	comptroller, err := compoundcontract.NewComptrollerFilterer(comptrollerAddress, client)
+	if err != nil {
+		return nil, err
+	}
+	comptrollerCaller, err := compoundcontract.NewComptrollerCaller(comptrollerAddress, client)
+	if err != nil {
+		return nil, err
+	}
+	cTokenABI, err := abi.JSON(strings.NewReader(compoundcontract.CTokenABI))
+	if err != nil {
+		return nil, err
+	}
+	comptrollerABI, err := abi.JSON(strings.NewReader(compoundcontract.ComptrollerABI))
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[string]common.Hash)
+	for _, name := range []string{"Mint", "Redeem", "Borrow", "RepayBorrow", "LiquidateBorrow", "Transfer"} {
+		events[name] = cTokenABI.Events[name].ID
+	}
+	for _, name := range []string{"MarketEntered", "MarketExited"} {
+		events[name] = comptrollerABI.Events[name].ID
+	}
+	return &CompoundPositionIndexer{
+		protocol:           conf.Protocol(),
+		client:             client,
+		cToken:             cToken,
+		comptrollerAddress: comptrollerAddress,
+		comptroller:        comptroller,
+		comptrollerCaller:  comptrollerCaller,
+		events:             events,
+		markets:            markets,
+		cTokens:            cTokens,
+	}, nil
+}
+
+// IndexPositions returns the positions changed in the blocks [@fromBlock, @toBlock] and the liquidations.
+// Mints, redemptions, borrows, repayments, liquidations, transfers of cTokens, including the cTokens
+// seized by liquidators, and entering and exiting markets mark the positions of the involved accounts
+// as changed. These are read at @toBlock from the account snapshots of the cTokens, which include the
+// accrued interest.
+func (indexer *CompoundPositionIndexer) IndexPositions(ctx context.Context, fromBlock, toBlock uint64) (changes []models.DefiPositionChange, liquidations []models.DefiLiquidation, err error) {
+	addresses := []common.Address{indexer.comptrollerAddress}
+	for address := range indexer.markets {
+		addresses = append(addresses, address)
+	}
+	var topics []common.Hash
+	for _, id := range indexer.events {
+		topics = append(topics, id)
+	}
+	logs, err := indexer.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: addresses,
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		return
+	}
+
+	times := newBlockTimes(indexer.client)
+	touched := make(touchedPositions)
+	// exchangeRates caches the exchange rates of the cTokens seized in liquidations by block.
+	exchangeRates := make(map[compoundRateKey]*big.Int)
+	for _, txLog := range logs {
+		if txLog.Removed || len(txLog.Topics) == 0 {
+			continue
+		}
+		var timestamp time.Time
+		if timestamp, err = times.time(ctx, txLog.BlockNumber); err != nil {
+			return
+		}
+
+		if txLog.Address == indexer.comptrollerAddress {
+			var cToken, account common.Address
+			switch txLog.Topics[0] {
+			case indexer.events["MarketEntered"]:
+				var event *compoundcontract.ComptrollerMarketEntered
+				if event, err = indexer.comptroller.ParseMarketEntered(txLog); err != nil {
+					return
+				}
+				cToken, account = event.CToken, event.Account
+			case indexer.events["MarketExited"]:
+				var event *compoundcontract.ComptrollerMarketExited
+				if event, err = indexer.comptroller.ParseMarketExited(txLog); err != nil {
+					return
+				}
+				cToken, account = event.CToken, event.Account
+			default:
+				continue
+			}
+			if _, ok := indexer.markets[cToken]; ok {
+				touched.add(account, cToken, txLog, timestamp)
+			}
+			continue
+		}
+
+		market := indexer.markets[txLog.Address]
+		switch txLog.Topics[0] {
+		case indexer.events["Mint"]:
+			var event *compoundcontract.CTokenMint
+			if event, err = indexer.cToken.ParseMint(txLog); err != nil {
+				return
+			}
+			touched.add(event.Minter, txLog.Address, txLog, timestamp)
+
+		case indexer.events["Redeem"]:
+			var event *compoundcontract.CTokenRedeem
+			if event, err = indexer.cToken.ParseRedeem(txLog); err != nil {
+				return
+			}
+			touched.add(event.Redeemer, txLog.Address, txLog, timestamp)
+
+		case indexer.events["Borrow"]:
+			var event *compoundcontract.CTokenBorrow
+			if event, err = indexer.cToken.ParseBorrow(txLog); err != nil {
+				return
+			}
+			touched.add(event.Borrower, txLog.Address, txLog, timestamp)
+
+		case indexer.events["RepayBorrow"]:
+			var event *compoundcontract.CTokenRepayBorrow
+			if event, err = indexer.cToken.ParseRepayBorrow(txLog); err != nil {
+				return
+			}
+			touched.add(event.Borrower, txLog.Address, txLog, timestamp)
+
+		case indexer.events["Transfer"]:
+			var event *compoundcontract.CTokenTransfer
+			if event, err = indexer.cToken.ParseTransfer(txLog); err != nil {
+				return
+			}
+			// Mints and redemptions transfer from and to the cToken itself.
+			for _, account := range []common.Address{event.From, event.To} {
+				if account != txLog.Address {
+					touched.add(account, txLog.Address, txLog, timestamp)
+				}
+			}
+
+		case indexer.events["LiquidateBorrow"]:
+			var event *compoundcontract.CTokenLiquidateBorrow
+			if event, err = indexer.cToken.ParseLiquidateBorrow(txLog); err != nil {
+				return
+			}
+			collateralMarket, ok := indexer.markets[event.CTokenCollateral]
+			if !ok {
+				err = fmt.Errorf("liquidation in %s seizes unknown cToken %s", txLog.TxHash.Hex(), event.CTokenCollateral.Hex())
+				return
+			}
+			var seized float64
+			if seized, err = indexer.seizedAmount(ctx, exchangeRates, event.CTokenCollateral, txLog.BlockNumber, event.SeizeTokens, collateralMarket.decimals); err != nil {
+				return
+			}
+			liquidations = append(liquidations, models.DefiLiquidation{
+				Protocol:         indexer.protocol.Name,
+				Borrower:         event.Borrower.Hex(),
+				Liquidator:       event.Liquidator.Hex(),
+				CollateralAsset:  collateralMarket.asset,
+				CollateralAmount: seized,
+				DebtAsset:        market.asset,
+				DebtAmount:       bigAmount(event.RepayAmount, market.decimals),
+				BlockNumber:      txLog.BlockNumber,
+				TxHash:           txLog.TxHash.Hex(),
+				LogIndex:         txLog.Index,
+				Time:             timestamp,
+			})
+			touched.add(event.Borrower, txLog.Address, txLog, timestamp)
+			touched.add(event.Borrower, event.CTokenCollateral, txLog, timestamp)
+			touched.add(event.Liquidator, event.CTokenCollateral, txLog, timestamp)
+		}
+	}
+	changes, err = indexer.readPositions(ctx, touched, toBlock)
+	return
+}
+
+// ReadPositions returns the positions of the accounts and markets of @positions at @blockNumber.
+// Positions in markets missing in the config are skipped.
+func (indexer *CompoundPositionIndexer) ReadPositions(ctx context.Context, positions []models.DefiPosition, blockNumber uint64) ([]models.DefiPositionChange, error) {
+	timestamp, err := newBlockTimes(indexer.client).time(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	touched := make(touchedPositions)
+	for _, position := range positions {
+		cToken, ok := indexer.cTokens[position.Asset.Address]
+		if !ok {
+			continue
+		}
+		touched.add(common.HexToAddress(position.Account), cToken, types.Log{BlockNumber: blockNumber}, timestamp)
+	}
+	return indexer.readPositions(ctx, touched, blockNumber)
+}
+
+// readPositions reads the @touched positions at @blockNumber. The collateral of a position is the value of
+// its cTokens in the underlying asset if the account entered the market and zero otherwise, its debt the
+// borrow balance.
+func (indexer *CompoundPositionIndexer) readPositions(ctx context.Context, touched touchedPositions, blockNumber uint64) (changes []models.DefiPositionChange, err error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber)}
+	for key, change := range touched {
+		market := indexer.markets[key.market]
+		errCode, balance, borrowBalance, exchangeRate, err := market.cToken.GetAccountSnapshot(opts, key.account)
+		if err == nil && errCode.Sign() != 0 {
+			err = fmt.Errorf("error code %s", errCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s snapshot of %s at block %d: %v", market.asset.Symbol, change.Account, blockNumber, err)
+		}
+		isCollateral, err := indexer.comptrollerCaller.CheckMembership(opts, key.account, key.market)
+		if err != nil {
+			return nil, fmt.Errorf("%s membership of %s at block %d: %v", market.asset.Symbol, change.Account, blockNumber, err)
+		}
+
+		change.Asset = market.asset
+		if isCollateral {
+			// The exchange rate is a mantissa scaled by 1e18.
+			change.Collateral = bigAmount(new(big.Int).Div(new(big.Int).Mul(balance, exchangeRate), big.NewInt(1e18)), market.decimals)
+		}
+		change.Debt = bigAmount(borrowBalance, market.decimals)
+		change.SetCollateral, change.SetDebt = true, true
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// compoundRateKey is the exchange rate of a cToken at a block.
+type compoundRateKey struct {
+	cToken      common.Address
+	blockNumber uint64
+}
+
+// seizedAmount returns the amount of the underlying asset of @seizeTokens of @cTokenAddress seized in @blockNumber.
+// The exchange rate is read at @blockNumber, after the liquidation accrued the interest of the market,
+// which requires an archive node for blocks older than the pruning window.
+func (indexer *CompoundPositionIndexer) seizedAmount(ctx context.Context, exchangeRates map[compoundRateKey]*big.Int, cTokenAddress common.Address, blockNumber uint64, seizeTokens *big.Int, decimals int) (float64, error) {
+	key := compoundRateKey{cToken: cTokenAddress, blockNumber: blockNumber}
+	rate, ok := exchangeRates[key]
+	if !ok {
+		cToken, err := compoundcontract.NewCTokenCaller(cTokenAddress, indexer.client)
+		if err != nil {
+			return 0, err
+		}
+		rate, err = cToken.ExchangeRateStored(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber)})
+		if err != nil {
+			return 0, fmt.Errorf("exchange rate of %s at block %d: %v", cTokenAddress.Hex(), blockNumber, err)
+		}
+		exchangeRates[key] = rate
+	}
+	if seizeTokens == nil {
+		return 0, nil
+	}
+	// The exchange rate is a mantissa scaled by 1e18.
+	underlying := new(big.Int).Div(new(big.Int).Mul(seizeTokens, rate), big.NewInt(1e18))
+	return bigAmount(underlying, decimals), nil
+}
@@ -0,0 +1,170 @@
+package defiscrapers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// defiProtocolsConfig is the name of the config file holding all protocol instances.
+const defiProtocolsConfig = "defiProtocols"
+
+// ProtocolConfig is an instance of a lending protocol on a blockchain. Name identifies the
+// instance and is the name the data is stored under. Type is the registered DeFIHelper.
+type ProtocolConfig struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Blockchain string `json:"blockchain"`
+	Address    string `json:"address"`
+	Token      string `json:"token"`
+	// Node is the url of the rpc node, NodeEnv an environment variable overriding it.
+	// Without either, instances on Ethereum use the default client of ethhelper.
+	Node    string `json:"node"`
+	NodeEnv string `json:"node_env"`
+	// Contracts holds further contracts of the instance by role, Assets the markets by asset symbol.
+	Contracts map[string]string `json:"contracts"`
+	Assets    map[string]string `json:"assets"`
+	// TVL configures the TVL of the instance, see TVLConfig.
+	TVL *TVLConfig `json:"tvl"`
+}
+
+// Protocol returns the protocol as stored in the database.
+func (conf ProtocolConfig) Protocol() dia.DefiProtocol {
+	return dia.DefiProtocol{
+		Name:                 conf.Name,
+		Address:              conf.Address,
+		UnderlyingBlockchain: conf.Blockchain,
+		Token:                conf.Token,
+	}
+}
+
+// ETHClient connects to the rpc node of the instance.
+func (conf ProtocolConfig) ETHClient() (*ethclient.Client, error) {
+	node := utils.Getenv(conf.NodeEnv, conf.Node)
+	if node == "" {
+		if conf.Blockchain != dia.ETHEREUM {
+			return nil, fmt.Errorf("protocol %s on %s needs a node", conf.Name, conf.Blockchain)
+		}
+		return ethhelper.NewETHClient()
+	}
+	return ethclient.Dial(node)
+}
+
+// DeFIHelperFactory returns the helper of the protocol instance @conf.
+type DeFIHelperFactory func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error)
+
+var helperFactories = map[string]DeFIHelperFactory{
+	"AAVE": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewAAVE(scraper, conf.Protocol()), nil
+	},
+	"AAVEv2": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewAAVEv2(scraper, conf)
+	},
+	"BITFINEX": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewBitfinex(scraper, conf.Protocol()), nil
+	},
+	"BZX": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewBZX(scraper, conf.Protocol()), nil
+	},
+	"COMPOUND": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewCompound(scraper, conf)
+	},
+	"DDEX": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewDDEX(scraper, conf.Protocol()), nil
+	},
+	"DHARMA": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewDHARMA(scraper, conf.Protocol()), nil
+	},
+	"DYDX": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewDYDX(scraper, conf.Protocol()), nil
+	},
+	"FORTUBE": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewForTube(scraper, conf.Protocol()), nil
+	},
+	"MAKERDAO": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewMakerdao(scraper, conf)
+	},
+	"NUO": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewNuo(scraper, conf.Protocol()), nil
+	},
+	"RAY": func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return NewRAY(scraper, conf.Protocol()), nil
+	},
+}
+
+// RegisterDeFIHelper registers @factory for protocol instances of type @protocolType.
+func RegisterDeFIHelper(protocolType string, factory DeFIHelperFactory) {
+	helperFactories[protocolType] = factory
+}
+
+// newDeFIHelper returns the helper of the protocol instance @conf.
+func newDeFIHelper(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+	factory, ok := helperFactories[conf.Type]
+	if !ok {
+		return nil, fmt.Errorf("protocol type %s of %s is not registered", conf.Type, conf.Name)
+	}
+	return factory(scraper, conf)
+}
+
+// LoadProtocolConfigs returns the protocol instances with @names from the config file.
+// All instances are returned if no names are given.
+func LoadProtocolConfigs(names ...string) ([]ProtocolConfig, error) {
+	data, err := configCollectors.ReadJSONFromConfig(defiProtocolsConfig)
+	if err != nil {
+		return nil, err
+	}
+	protocols, err := parseProtocolConfigs(data)
+	if err != nil {
+		return nil, err
+	}
+	return selectProtocolConfigs(protocols, names)
+}
+
+// parseProtocolConfigs decodes and validates the protocol instances in @data.
+func parseProtocolConfigs(data []byte) ([]ProtocolConfig, error) {
+	var config struct {
+		Protocols []ProtocolConfig `json:"protocols"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, conf := range config.Protocols {
+		if conf.Name == "" || conf.Type == "" || conf.Blockchain == "" {
+			return nil, errors.New("protocol needs a name, a type and a blockchain")
+		}
+		if names[conf.Name] {
+			return nil, fmt.Errorf("protocol %s is configured twice", conf.Name)
+		}
+		names[conf.Name] = true
+		if _, ok := helperFactories[conf.Type]; !ok {
+			return nil, fmt.Errorf("protocol type %s of %s is not registered", conf.Type, conf.Name)
+		}
+	}
+	return config.Protocols, nil
+}
+
+func selectProtocolConfigs(protocols []ProtocolConfig, names []string) ([]ProtocolConfig, error) {
+	if len(names) == 0 {
+		return protocols, nil
+	}
+	byName := make(map[string]ProtocolConfig)
+	for _, conf := range protocols {
+		byName[conf.Name] = conf
+	}
+	var selected []ProtocolConfig
+	for _, name := range names {
+		conf, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("protocol %s is not configured", name)
+		}
+		selected = append(selected, conf)
+	}
+	return selected, nil
+}
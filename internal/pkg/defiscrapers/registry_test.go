@@ -0,0 +1,98 @@
+package defiscrapers
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+type testHelper struct {
+	conf ProtocolConfig
+}
+
+func (h *testHelper) UpdateRate() error  { return nil }
+func (h *testHelper) UpdateState() error { return nil }
+
+func TestProtocolConfigs(t *testing.T) {
+	data, err := ioutil.ReadFile("../../../config/defiProtocols.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	protocols, err := parseProtocolConfigs(data)
+	if err != nil {
+		t.Fatalf("protocol config is invalid: %v", err)
+	}
+
+	selected, err := selectProtocolConfigs(protocols, []string{"AAVEv2", "AAVEv2-Polygon", "CREAM"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	aave, polygon, cream := selected[0], selected[1], selected[2]
+	if aave.Type != "AAVEv2" || polygon.Type != "AAVEv2" || polygon.Protocol().UnderlyingBlockchain != "Polygon" {
+		t.Errorf("aave instances were incorrect, got: %+v %+v.", aave, polygon)
+	}
+	if aave.Contracts[aaveAddressesProvider] == polygon.Contracts[aaveAddressesProvider] {
+		t.Errorf("aave instances share the addresses provider %s.", aave.Contracts[aaveAddressesProvider])
+	}
+	if cream.Type != "COMPOUND" || cream.Assets["ETH"] != "0xD06527D5e56A3495252A528C4987003b712860eE" {
+		t.Errorf("cream instance was incorrect, got: %+v.", cream)
+	}
+	if _, err := selectProtocolConfigs(protocols, []string{"AAVEv3"}); err == nil {
+		t.Error("expected error for unknown protocol")
+	}
+	// Only protocols whose funds are not held in known contracts lack a TVL.
+	var noTVL []string
+	for _, conf := range protocols {
+		if !conf.HasTVL() {
+			noTVL = append(noTVL, conf.Name)
+		}
+	}
+	if len(noTVL) != 5 {
+		t.Errorf("protocols without tvl were incorrect, got: %v.", noTVL)
+	}
+	makerdao, err := selectProtocolConfigs(protocols, []string{"MAKERDAO"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings, err := TVLHoldings(context.Background(), makerdao[0], nil); err != nil || len(holdings) != len(collaterals) {
+		t.Errorf("makerdao tvl holdings were incorrect, got: %v %v.", holdings, err)
+	}
+	if all, _ := selectProtocolConfigs(protocols, nil); len(all) != len(protocols) {
+		t.Errorf("number of protocols was incorrect, got: %v, want: %v.", len(all), len(protocols))
+	}
+
+	for _, invalid := range []string{
+		`{"protocols":[{"name":"AAVEv2","type":"AAVEv2"}]}`,
+		`{"protocols":[{"name":"AAVEv3","type":"AAVEv3","blockchain":"Ethereum"}]}`,
+		`{"protocols":[{"name":"DYDX","type":"DYDX","blockchain":"Ethereum"},{"name":"DYDX","type":"DYDX","blockchain":"Polygon"}]}`,
+	} {
+		if _, err := parseProtocolConfigs([]byte(invalid)); err == nil {
+			t.Errorf("expected error for config %s", invalid)
+		}
+	}
+}
+
+func TestNewDeFIHelper(t *testing.T) {
+	RegisterDeFIHelper("TEST", func(scraper *DefiScraper, conf ProtocolConfig) (DeFIHelper, error) {
+		return &testHelper{conf: conf}, nil
+	})
+	defer delete(helperFactories, "TEST")
+
+	helper, err := newDeFIHelper(nil, ProtocolConfig{Name: "TEST-Polygon", Type: "TEST", Blockchain: "Polygon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, ok := helper.(*testHelper); !ok || h.conf.Name != "TEST-Polygon" {
+		t.Errorf("helper was incorrect, got: %+v.", helper)
+	}
+
+	for _, conf := range []ProtocolConfig{
+		{Name: "AAVEv3", Type: "AAVEv3", Blockchain: "Ethereum"},
+		{Name: "AAVEv2", Type: "AAVEv2", Blockchain: "Ethereum"},
+		{Name: "COMPOUND", Type: "COMPOUND", Blockchain: "Ethereum"},
+	} {
+		if _, err := newDeFIHelper(nil, conf); err == nil {
+			t.Errorf("expected error for protocol %+v", conf)
+		}
+	}
+}
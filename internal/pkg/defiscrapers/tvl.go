@@ -0,0 +1,206 @@
+package defiscrapers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/aave/contract"
+	compoundcontract "github.com/diadata-org/diadata/internal/pkg/defiscrapers/compound"
+	supplyservice "github.com/diadata-org/diadata/internal/pkg/supplyService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TVLConfig configures the TVL of a protocol instance. Protocol is the name the TVL is stored under, which
+// defaults to the name of the instance. Instances of a protocol on several blockchains share it.
+// Holdings lists contracts holding funds of the instance in addition to those derived from its type.
+type TVLConfig struct {
+	Protocol string `json:"protocol"`
+	Holdings []struct {
+		Holder string `json:"holder"`
+		// Tokens are the addresses of the tokens held, where the zero address denotes the native coin.
+		Tokens []string `json:"tokens"`
+	} `json:"holdings"`
+}
+
+// TVLHolding is a token held by a contract of a protocol instance. The zero token address denotes the native coin.
+type TVLHolding struct {
+	Holder common.Address
+	Token  common.Address
+}
+
+// TVLHoldingsFactory returns the holdings of the protocol instance @conf derived from its contracts.
+type TVLHoldingsFactory func(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) ([]TVLHolding, error)
+
+var tvlHoldingsFactories = map[string]TVLHoldingsFactory{
+	"AAVEv2":   aaveV2TVLHoldings,
+	"COMPOUND": compoundTVLHoldings,
+	"MAKERDAO": makerdaoTVLHoldings,
+}
+
+// TVLProtocol returns the name the TVL of the instance @conf is stored under.
+func (conf ProtocolConfig) TVLProtocol() string {
+	if conf.TVL != nil && conf.TVL.Protocol != "" {
+		return conf.TVL.Protocol
+	}
+	return conf.Name
+}
+
+// HasTVL returns whether the holdings of the instance @conf are known.
+func (conf ProtocolConfig) HasTVL() bool {
+	_, ok := tvlHoldingsFactories[conf.Type]
+	return ok || (conf.TVL != nil && len(conf.TVL.Holdings) > 0)
+}
+
+// TVLHoldings returns the holdings of the protocol instance @conf, both derived from its type and configured.
+func TVLHoldings(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) (holdings []TVLHolding, err error) {
+	if factory, ok := tvlHoldingsFactories[conf.Type]; ok {
+		if holdings, err = factory(ctx, conf, client); err != nil {
+			return
+		}
+	}
+	if conf.TVL != nil {
+		for _, h := range conf.TVL.Holdings {
+			if !common.IsHexAddress(h.Holder) {
+				return nil, fmt.Errorf("tvl holder %s of %s is not an address", h.Holder, conf.Name)
+			}
+			for _, token := range h.Tokens {
+				if !common.IsHexAddress(token) {
+					return nil, fmt.Errorf("tvl token %s of %s is not an address", token, conf.Name)
+				}
+				holdings = append(holdings, TVLHolding{Holder: common.HexToAddress(h.Holder), Token: common.HexToAddress(token)})
+			}
+		}
+	}
+	if len(holdings) == 0 {
+		return nil, fmt.Errorf("no tvl holdings of protocol %s", conf.Name)
+	}
+	return
+}
+
+// ReadTVLBalances returns the balances of the tokens in @holdings summed over their holders, one per token.
+// The native coin is returned as @native. Prices are left to the caller.
+func ReadTVLBalances(ctx context.Context, conf ProtocolConfig, client *ethclient.Client, holdings []TVLHolding, native dia.Asset, timestamp time.Time) ([]models.DefiTVL, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	var tokens []common.Address
+	balances := make(map[common.Address]*big.Int)
+	for _, h := range holdings {
+		var balance *big.Int
+		var err error
+		if h.Token == (common.Address{}) {
+			balance, err = client.BalanceAt(ctx, h.Holder, nil)
+		} else {
+			var token *supplyservice.ERC20Caller
+			if token, err = supplyservice.NewERC20Caller(h.Token, client); err != nil {
+				return nil, err
+			}
+			balance, err = token.BalanceOf(opts, h.Holder)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("balance of %s held by %s: %v", h.Token.Hex(), h.Holder.Hex(), err)
+		}
+		if _, ok := balances[h.Token]; !ok {
+			tokens = append(tokens, h.Token)
+			balances[h.Token] = new(big.Int)
+		}
+		balances[h.Token].Add(balances[h.Token], balance)
+	}
+
+	tvls := make([]models.DefiTVL, 0, len(tokens))
+	for _, address := range tokens {
+		asset := native
+		if address != (common.Address{}) {
+			token, err := supplyservice.NewERC20Caller(address, client)
+			if err != nil {
+				return nil, err
+			}
+			decimals, err := token.Decimals(opts)
+			if err != nil {
+				return nil, fmt.Errorf("decimals of %s: %v", address.Hex(), err)
+			}
+			asset = dia.Asset{Address: address.Hex(), Blockchain: conf.Blockchain, Decimals: decimals}
+			// Some tokens such as MKR return their symbol as bytes32, it is then left empty.
+			asset.Symbol, _ = token.Symbol(opts)
+		}
+		tvls = append(tvls, models.DefiTVL{
+			Time:       timestamp,
+			Protocol:   conf.TVLProtocol(),
+			Blockchain: conf.Blockchain,
+			Asset:      asset,
+			Balance:    bigAmount(balances[address], int(asset.Decimals)),
+		})
+	}
+	return tvls, nil
+}
+
+// aaveV2TVLHoldings returns the reserves held by the aTokens of the AAVE v2 market @conf.
+// Borrowed funds are not held by the aTokens, so the TVL is the liquidity available.
+func aaveV2TVLHoldings(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) ([]TVLHolding, error) {
+	provider, ok := conf.Contracts[aaveAddressesProvider]
+	if !ok {
+		return nil, fmt.Errorf("aave v2 protocol %s needs contract %s", conf.Name, aaveAddressesProvider)
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	addrProvider, err := contract.NewILendingPoolAddressesProviderCaller(common.HexToAddress(provider), client)
+	if err != nil {
+		return nil, err
+	}
+	poolAddress, err := addrProvider.GetLendingPool(opts)
+	if err != nil {
+		return nil, fmt.Errorf("lending pool of %s: %v", conf.Name, err)
+	}
+	pool, err := contract.NewLendingPoolCaller(poolAddress, client)
+	if err != nil {
+		return nil, err
+	}
+	reserves, err := pool.GetReservesList(opts)
+	if err != nil {
+		return nil, fmt.Errorf("reserves of %s: %v", conf.Name, err)
+	}
+	var holdings []TVLHolding
+	for _, reserve := range reserves {
+		data, err := pool.GetReserveData(opts, reserve)
+		if err != nil {
+			return nil, fmt.Errorf("reserve data of %s: %v", reserve.Hex(), err)
+		}
+		holdings = append(holdings, TVLHolding{Holder: data.ATokenAddress, Token: reserve})
+	}
+	return holdings, nil
+}
+
+// compoundTVLHoldings returns the underlying assets held by the cTokens of the Compound instance @conf.
+// Borrowed funds are not held by the cTokens, so the TVL is the liquidity available.
+func compoundTVLHoldings(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) ([]TVLHolding, error) {
+	var holdings []TVLHolding
+	for symbol, address := range conf.Assets {
+		holding := TVLHolding{Holder: common.HexToAddress(address)}
+		if symbol != "ETH" {
+			cErc20, err := compoundcontract.NewCErc20Caller(holding.Holder, client)
+			if err != nil {
+				return nil, err
+			}
+			if holding.Token, err = cErc20.Underlying(&bind.CallOpts{Context: ctx}); err != nil {
+				return nil, fmt.Errorf("underlying of %s: %v", symbol, err)
+			}
+		}
+		holdings = append(holdings, holding)
+	}
+	return holdings, nil
+}
+
+// makerdaoTVLHoldings returns the collateral held by the join adapters of the MakerDAO collaterals on Ethereum.
+func makerdaoTVLHoldings(ctx context.Context, conf ProtocolConfig, client *ethclient.Client) ([]TVLHolding, error) {
+	if conf.Blockchain != dia.ETHEREUM {
+		return nil, fmt.Errorf("makerdao collaterals of %s are only known on %s", conf.Name, dia.ETHEREUM)
+	}
+	holdings := make([]TVLHolding, len(collaterals))
+	for i, col := range collaterals {
+		holdings[i] = TVLHolding{Holder: common.HexToAddress(col.vaultAddr), Token: common.HexToAddress(col.tokenAddr)}
+	}
+	return holdings, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum/common"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -44,6 +45,12 @@ type Reserve struct {
 	VariableBorrowRate string `json:"variableBorrowRate"`
 	TotalLiquidity     string `json:"totalLiquidity"`
 	Decimals           int    `json:"decimals"`
+	AvailableLiquidity string `json:"availableLiquidity"`
+	TotalBorrows       string `json:"totalBorrows"`
+	// BaseLTVasCollateral and ReserveLiquidationThreshold are given in per cent.
+	BaseLTVasCollateral         string `json:"baseLTVasCollateral"`
+	ReserveLiquidationThreshold string `json:"reserveLiquidationThreshold"`
+	UsageAsCollateralEnabled    bool   `json:"usageAsCollateralEnabled"`
 }
 
 func fetchAAVEMarkets() (aaverate AAVEMarket, err error) {
@@ -65,6 +72,11 @@ func fetchAAVEMarkets() (aaverate AAVEMarket, err error) {
 			stableBorrowRate
 			lastUpdateTimestamp
 			totalLiquidity
+			availableLiquidity
+			totalBorrows
+			baseLTVasCollateral
+			reserveLiquidationThreshold
+			usageAsCollateralEnabled
 			decimals
 		  }
 		}
@@ -82,29 +94,114 @@ func fetchAAVEMarkets() (aaverate AAVEMarket, err error) {
 	return
 }
 
-// getTotalSupply returns the total supply in ETH locked in the protocol
-func getTotalSupplyAAVE() (float64, error) {
+// getTotalsAAVE returns the total supply and the total borrows in ETH of the protocol
+func getTotalsAAVE() (totalSupply float64, totalBorrow float64, err error) {
 	markets, err := fetchAAVEMarkets()
 	if err != nil {
-		return 0, err
+		return
 	}
-	sum := float64(0)
 	for _, market := range markets.Data.Reserves {
-		marketLiquidity, err := strconv.ParseFloat(market.TotalLiquidity, 64)
+		var marketLiquidity, marketBorrows, marketPrice float64
+		marketLiquidity, err = strconv.ParseFloat(market.TotalLiquidity, 64)
 		if err != nil {
-			return 0, err
+			return
 		}
 		marketLiquidity /= math.Pow10(market.Decimals)
-		marketPrice, err := strconv.ParseFloat(market.Price.PriceInEth, 64)
+		marketBorrows, err = parseAAVEAmount(market.TotalBorrows, market.Decimals)
+		if err != nil {
+			return
+		}
+		marketPrice, err = strconv.ParseFloat(market.Price.PriceInEth, 64)
 		if err != nil {
-			return 0, err
+			return
 		}
 		marketPrice /= math.Pow10(18)
 
-		marketInEth := marketLiquidity * marketPrice
-		sum += marketInEth
+		totalSupply += marketLiquidity * marketPrice
+		totalBorrow += marketBorrows * marketPrice
 	}
-	return sum, nil
+	return
+}
+
+// parseAAVERate returns the ray @rate in per cent.
+func parseAAVERate(rate string) float64 {
+	// https://docs.aave.com/developers/integrating-aave/using-graphql#common-gotchas
+	// as per the DOC value of rate need to be converted to power of 27
+	// As we compute rates in per cent and they're given as absolute here, factor is 10^25
+	value := new(big.Float)
+	value.SetString(rate)
+	value = new(big.Float).Quo(value, big.NewFloat(math.Pow10(25)))
+	result, _ := value.Float64()
+	return result
+}
+
+// parseAAVEAmount returns the raw @amount in units of a token with @decimals. Empty amounts are zero.
+func parseAAVEAmount(amount string, decimals int) (float64, error) {
+	if amount == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, err
+	}
+	return value / math.Pow10(decimals), nil
+}
+
+// defiRate returns the lending market data of @market. The reserve ID is the address of the
+// underlying asset, with 0xeee...e standing for ETH. AAVE v1 has no reserve factor.
+func (market Reserve) defiRate(protocol dia.DefiProtocol) (*dia.DefiRate, error) {
+	totalSupplied, err := parseAAVEAmount(market.TotalLiquidity, market.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	totalBorrowed, err := parseAAVEAmount(market.TotalBorrows, market.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	availableLiquidity, err := parseAAVEAmount(market.AvailableLiquidity, market.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	rate := &dia.DefiRate{
+		Timestamp:          time.Now(),
+		Asset:              market.Symbol,
+		AssetAddress:       aaveAssetAddress(market.ID),
+		AssetBlockchain:    protocol.UnderlyingBlockchain,
+		Protocol:           protocol.Name,
+		LendingRate:        parseAAVERate(market.LiquidityRate),
+		BorrowingRate:      parseAAVERate(market.StableBorrowRate),
+		StableBorrowRate:   parseAAVERate(market.StableBorrowRate),
+		VariableBorrowRate: parseAAVERate(market.VariableBorrowRate),
+		TotalSupplied:      totalSupplied,
+		TotalBorrowed:      totalBorrowed,
+		AvailableLiquidity: availableLiquidity,
+		Utilisation:        dia.DefiUtilisation(totalBorrowed, totalSupplied),
+	}
+	if market.UsageAsCollateralEnabled {
+		ltv, err := parseAAVEAmount(market.BaseLTVasCollateral, 2)
+		if err != nil {
+			return nil, err
+		}
+		liquidationThreshold, err := parseAAVEAmount(market.ReserveLiquidationThreshold, 2)
+		if err != nil {
+			return nil, err
+		}
+		rate.CollateralFactor = ltv
+		rate.LiquidationThreshold = liquidationThreshold
+	}
+	return rate, nil
+}
+
+// aaveAssetAddress returns the checksummed address of the reserve @id, the zero address for ETH.
+func aaveAssetAddress(id string) string {
+	if len(id) > 42 {
+		id = id[:42]
+	}
+	address := common.HexToAddress(id)
+	if address == common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE") {
+		address = common.Address{}
+	}
+	return address.Hex()
 }
 
 func (proto *AAVEProtocol) UpdateRate() error {
@@ -115,26 +212,11 @@ func (proto *AAVEProtocol) UpdateRate() error {
 		return err
 	}
 
-	// https://docs.aave.com/developers/integrating-aave/using-graphql#common-gotchas
-	// as per the DOC value of rate need to be converted to power of 27
-	// As we compute rates in per cent and they're given as absolute here, factor is 10^25
 	for _, market := range markets.Data.Reserves {
-		totalSupplyAPR := new(big.Float)
-		totalSupplyAPR.SetString(market.LiquidityRate)
-		totalSupplyAPR = new(big.Float).Quo(totalSupplyAPR, big.NewFloat(math.Pow10(25)))
-		totalSupplyAPRPOW25, _ := totalSupplyAPR.Float64()
-
-		totalBorrowAPR := new(big.Float)
-		totalBorrowAPR.SetString(market.StableBorrowRate)
-		totalBorrowAPR = new(big.Float).Quo(totalBorrowAPR, big.NewFloat(math.Pow10(25)))
-		totalBorrowAPRPOW25, _ := totalBorrowAPR.Float64()
-
-		asset := &dia.DefiRate{
-			Timestamp:     time.Now(),
-			Asset:         market.Symbol,
-			Protocol:      proto.protocol.Name,
-			LendingRate:   totalSupplyAPRPOW25,
-			BorrowingRate: totalBorrowAPRPOW25,
+		asset, err := market.defiRate(proto.protocol)
+		if err != nil {
+			log.Errorf("parsing market %s: %v", market.Symbol, err)
+			continue
 		}
 		log.Printf("writing DEFI rate for  %#v in %v\n", asset, proto.scraper.RateChannel())
 		proto.scraper.RateChannel() <- asset
@@ -160,7 +242,7 @@ func (proto *AAVEProtocol) UpdateRate() error {
 
 func (proto *AAVEProtocol) UpdateState() error {
 	log.Printf("Updating DEFI state for %+v\n ", proto.protocol.Name)
-	totalSupplyETH, err := getTotalSupplyAAVE()
+	totalSupplyETH, totalBorrowETH, err := getTotalsAAVE()
 	if err != nil {
 		return err
 	}
@@ -177,10 +259,11 @@ func (proto *AAVEProtocol) UpdateState() error {
 	// }
 
 	deFIState := &dia.DefiProtocolState{
-		TotalUSD:  totalSupplyUSD,
-		TotalETH:  totalSupplyETH,
-		Protocol:  proto.protocol,
-		Timestamp: time.Now(),
+		TotalUSD:       totalSupplyUSD,
+		TotalETH:       totalSupplyETH,
+		TotalBorrowUSD: totalBorrowETH * PriceETH,
+		Protocol:       proto.protocol,
+		Timestamp:      time.Now(),
 	}
 	proto.scraper.StateChannel() <- deFIState
 	log.Printf("writing DEFI state for  %#v in %v\n", deFIState, proto.scraper.StateChannel())
@@ -2,37 +2,48 @@ package defiscrapers
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/aave"
 	"github.com/diadata-org/diadata/pkg/dia"
-	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 )
 
+// aaveAddressesProvider is the role of the LendingPoolAddressesProvider of a market in ProtocolConfig.Contracts.
+const aaveAddressesProvider = "lendingPoolAddressesProvider"
+
 type AAVEv2Protocol struct {
-	ethC     *ethclient.Client
-	protocol dia.DefiProtocol
-	scraper  *DefiScraper
+	ethC         *ethclient.Client
+	addrProvider common.Address
+	protocol     dia.DefiProtocol
+	scraper      *DefiScraper
 }
 
-func NewAAVEv2(scraper *DefiScraper, protocol dia.DefiProtocol) *AAVEv2Protocol {
-	ethC, err := ethhelper.NewETHClient()
+// NewAAVEv2 returns the helper of the AAVE v2 market with the LendingPoolAddressesProvider given in @conf.
+func NewAAVEv2(scraper *DefiScraper, conf ProtocolConfig) (*AAVEv2Protocol, error) {
+	addrProvider := conf.Contracts[aaveAddressesProvider]
+	if !common.IsHexAddress(addrProvider) {
+		return nil, fmt.Errorf("aave protocol %s needs the address of its %s", conf.Name, aaveAddressesProvider)
+	}
+	ethC, err := conf.ETHClient()
 	if err != nil {
-		panic(err) // TODO: add an error to the function signature
+		return nil, err
 	}
 
 	return &AAVEv2Protocol{
-		ethC:     ethC,
-		protocol: protocol,
-		scraper:  scraper,
-	}
+		ethC:         ethC,
+		addrProvider: common.HexToAddress(addrProvider),
+		protocol:     conf.Protocol(),
+		scraper:      scraper,
+	}, nil
 }
 
 func (proto *AAVEv2Protocol) UpdateRate() error {
-	return errors.WithStack(aave.Read(context.Background(), proto.ethC, proto.protocol, proto.scraper.chanDefiRate, nil))
+	return errors.WithStack(aave.Read(context.Background(), proto.ethC, proto.addrProvider, proto.protocol, proto.scraper.chanDefiRate, nil))
 }
 
 func (proto *AAVEv2Protocol) UpdateState() error {
-	return errors.WithStack(aave.Read(context.Background(), proto.ethC, proto.protocol, nil, proto.scraper.chanDefiState))
+	return errors.WithStack(aave.Read(context.Background(), proto.ethC, proto.addrProvider, proto.protocol, nil, proto.scraper.chanDefiState))
 }
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
-	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -26,6 +25,11 @@ type CompoundRate struct {
 	Cash          *big.Int
 	Decimal       int
 	Symbol        string
+	// Underlying is the address of the underlying asset, the zero address for ETH.
+	Underlying common.Address
+	// ReserveFactor and CollateralFactor are mantissas scaled by 1e18.
+	ReserveFactor    *big.Int
+	CollateralFactor *big.Int
 }
 
 type CompoundProtocol struct {
@@ -33,96 +37,20 @@ type CompoundProtocol struct {
 	protocol   dia.DefiProtocol
 	connection *ethclient.Client
 	assets     map[string]string // assetname and address
-	decimals   map[string]int
 }
 
-func NewCompound(scraper *DefiScraper, protocol dia.DefiProtocol) *CompoundProtocol {
-	assets := make(map[string]string)
-	//https://compound.finance/docs#networks
-	assets["BAT"] = "0x6c8c6b02e7b2be14d4fa6022dfd6d75921d90e4e"
-	assets["COMP"] = "0x70e36f6bf80a52b3b46b3af8e106cc0ed743e8e4"
-	assets["DAI"] = "0x5d3a536e4d6dbd6114cc1ead35777bab948e3643"
-	assets["ETH"] = "0x4ddc2d193948926d02f9b1fe9e1daa0718270ed5"
-	assets["REP"] = "0x158079ee67fce2f58472a96584a73c7ab9ac95c1"
-	assets["UNI"] = "0x35a18000230da775cac24873d00ff85bccded550"
-	assets["USDC"] = "0x39aa39c021dfbae8fac545936693ac917d5e7563"
-	assets["USDT"] = "0xf650c3d88d12db855b8bf7d11be6c55a4e07dcc9"
-	assets["WBTC"] = "0xc11b1268c1a384e55c48c2391d8d480264a3a7f4"
-	assets["ZRX"] = "0xb3319f5d18bc0d84dd1b4825dcde5d5f7266d407"
-
-	decimals := make(map[string]int)
-	decimals["BAT"] = 18
-	decimals["COMP"] = 18
-	decimals["DAI"] = 18
-	decimals["ETH"] = 18
-	decimals["REP"] = 18
-	decimals["UNI"] = 18
-	decimals["USDC"] = 6
-	decimals["USDT"] = 6
-	decimals["WBTC"] = 8
-	decimals["ZRX"] = 18
-
-	connection, err := ethhelper.NewETHClient()
-	if err != nil {
-		log.Error("Error connecting Eth Client")
+// NewCompound returns the helper of Compound and its forks such as Cream Finance.
+// The assets of @conf map the symbols of the underlying assets to the addresses of their cTokens,
+// see https://compound.finance/docs#networks for Compound.
+func NewCompound(scraper *DefiScraper, conf ProtocolConfig) (*CompoundProtocol, error) {
+	if len(conf.Assets) == 0 {
+		return nil, fmt.Errorf("compound protocol %s needs assets", conf.Name)
 	}
-
-	return &CompoundProtocol{scraper: scraper, protocol: protocol, assets: assets, decimals: decimals, connection: connection}
-}
-
-func NewCreamFinance(scraper *DefiScraper, protocol dia.DefiProtocol) *CompoundProtocol {
-	assets := make(map[string]string)
-	//https://compound.finance/docs#networks
-	assets["ETH"] = "0xD06527D5e56A3495252A528C4987003b712860eE"   //done
-	assets["USDC"] = "0x44fbeBd2F576670a6C33f6Fc0B00aA8c5753b322"  //done
-	assets["USDT"] = "0x797AAB1ce7c01eB727ab980762bA88e7133d2157"  //done
-	assets["MTA"] = "0x3623387773010d9214B10C551d6e7fc375D31F58"   //done
-	assets["COMP"] = "0x19D1666f543D42ef17F66E376944A22aEa1a8E46"  //done
-	assets["BAL"] = "0xcE4Fe9b4b8Ff61949DCfeB7e03bc9FAca59D2Eb3"   //done
-	assets["YFI"] = "0xCbaE0A83f4f9926997c8339545fb8eE32eDc6b76"   //done
-	assets["yCRV"] = "0x9baF8a5236d44AC410c0186Fe39178d5AAD0Bb87"  //done
-	assets["LINK"] = "0x697256CAA3cCaFD62BB6d3Aa1C7C5671786A5fD9"  //done
-	assets["CREAM"] = "0x892B14321a4FCba80669aE30Bd0cd99a7ECF6aC0" //done
-	assets["LEND"] = "0x8B86e0598616a8d4F1fdAE8b59E55FB5Bc33D0d6"  //done
-	assets["CRV"] = "0xc7Fd8Dcee4697ceef5a2fd4608a7BD6A94C77480"   //done
-	assets["BUSD"] = "0x1FF8CDB51219a8838b52E9cAc09b71e591BC998e"  //done
-	assets["yUSD"] = "0x4EE15f44c6F0d8d1136c83EfD2e8E4AC768954c6"  //done
-	assets["SUSHI"] = "0x338286C0BC081891A4Bda39C7667ae150bf5D206" //done
-	assets["FTT"] = "0x10FDBD1e48eE2fD9336a482D746138AE19e649Db"   //done
-	assets["yETH"] = "0x01da76DEa59703578040012357b81ffE62015C2d"  //done
-	assets["SRM"] = "0xef58b2d5A1b8D3cDE67b8aB054dC5C831E9Bc025"   //done
-	assets["UNI"] = "0xe89a6D0509faF730BD707bf868d9A2A744a363C7"   //done
-
-	assets["renBTC"] = "0x17107f40d70f4470d20CB3f138a052cAE8EbD4bE" //done
-
-	decimals := make(map[string]int)
-	decimals["ETH"] = 18
-	decimals["USDT"] = 6
-	decimals["USDC"] = 6
-	decimals["COMP"] = 18
-	decimals["BAL"] = 18
-	decimals["YFI"] = 18
-	decimals["yCRV"] = 18
-	decimals["LINK"] = 18
-	decimals["CREAM"] = 18
-	decimals["LEND"] = 18
-	decimals["CRV"] = 18
-	decimals["renBTC"] = 8
-	decimals["BUSD"] = 18
-	decimals["MTA"] = 18
-	decimals["yUSD"] = 18
-	decimals["SUSHI"] = 18
-	decimals["FTT"] = 18
-	decimals["yETH"] = 18
-	decimals["SRM"] = 6
-	decimals["UNI"] = 18
-
-	connection, err := ethhelper.NewETHClient()
+	connection, err := conf.ETHClient()
 	if err != nil {
-		log.Error("Error connecting Eth Client")
+		return nil, err
 	}
-
-	return &CompoundProtocol{scraper: scraper, protocol: protocol, decimals: decimals, assets: assets, connection: connection}
+	return &CompoundProtocol{scraper: scraper, protocol: conf.Protocol(), assets: conf.Assets, connection: connection}, nil
 }
 
 func (proto *CompoundProtocol) fetch(asset string) (CompoundRate, error) {
@@ -134,6 +62,7 @@ func (proto *CompoundProtocol) fetch(asset string) (CompoundRate, error) {
 
 	// Get decimals of underlying token for computation of tvl
 	var decs *big.Int
+	var address common.Address
 	if asset == "ETH" {
 		decs = big.NewInt(18)
 	} else {
@@ -142,7 +71,6 @@ func (proto *CompoundProtocol) fetch(asset string) (CompoundRate, error) {
 		if err != nil {
 			return CompoundRate{}, err
 		}
-		var address common.Address
 		address, err = cContract.Underlying(&bind.CallOpts{})
 		if err != nil {
 			log.Error(err)
@@ -179,19 +107,87 @@ func (proto *CompoundProtocol) fetch(asset string) (CompoundRate, error) {
 	if err != nil {
 		return CompoundRate{}, err
 	}
+	reserveFactor, err := contract.ReserveFactorMantissa(&bind.CallOpts{})
+	if err != nil {
+		return CompoundRate{}, err
+	}
+	// Not all forks expose the markets of their comptroller, so the collateral factor is optional.
+	collateralFactor, err := proto.collateralFactor(contract, common.HexToAddress(proto.assets[asset]))
+	if err != nil {
+		log.Warnf("collateral factor of %s: %v", asset, err)
+	}
 	rate := CompoundRate{
-		Symbol:        asset,
-		Decimal:       int(decs.Int64()),
-		BorrowRate:    proto.calculateAPY(borrowInterestRate),
-		SupplyRate:    proto.calculateAPY(supplyInterestRate),
-		TotalSupply:   totalSupply,
-		TotalBorrow:   totalBorrow,
-		TotalReserves: totalReserves,
-		Cash:          cash,
+		Symbol:           asset,
+		Decimal:          int(decs.Int64()),
+		BorrowRate:       proto.calculateAPY(borrowInterestRate),
+		SupplyRate:       proto.calculateAPY(supplyInterestRate),
+		TotalSupply:      totalSupply,
+		TotalBorrow:      totalBorrow,
+		TotalReserves:    totalReserves,
+		Cash:             cash,
+		Underlying:       address,
+		ReserveFactor:    reserveFactor,
+		CollateralFactor: collateralFactor,
 	}
 	return rate, nil
 }
 
+// collateralFactor returns the collateral factor of the market @cToken as set in its comptroller.
+func (proto *CompoundProtocol) collateralFactor(contract *compoundcontract.CTokenCaller, cToken common.Address) (*big.Int, error) {
+	comptrollerAddress, err := contract.Comptroller(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+	comptroller, err := compoundcontract.NewComptrollerCaller(comptrollerAddress, proto.connection)
+	if err != nil {
+		return nil, err
+	}
+	market, err := comptroller.Markets(&bind.CallOpts{}, cToken)
+	if err != nil {
+		return nil, err
+	}
+	return market.CollateralFactorMantissa, nil
+}
+
+// defiRate returns the lending market data of @market. In Compound, the underlying
+// supplied to a market is its cash plus the outstanding borrows minus the reserves.
+// Borrows are variable rate only and a position is liquidated once its borrows
+// exceed the collateral factor.
+func (market CompoundRate) defiRate(protocol dia.DefiProtocol) *dia.DefiRate {
+	cash := bigAmount(market.Cash, market.Decimal)
+	totalBorrowed := bigAmount(market.TotalBorrow, market.Decimal)
+	totalReserves := bigAmount(market.TotalReserves, market.Decimal)
+	totalSupplied := cash + totalBorrowed - totalReserves
+	collateralFactor := bigAmount(market.CollateralFactor, 18)
+	return &dia.DefiRate{
+		Timestamp:            time.Now(),
+		Asset:                market.Symbol,
+		AssetAddress:         market.Underlying.Hex(),
+		AssetBlockchain:      protocol.UnderlyingBlockchain,
+		Protocol:             protocol.Name,
+		LendingRate:          market.SupplyRate,
+		BorrowingRate:        market.BorrowRate,
+		VariableBorrowRate:   market.BorrowRate,
+		TotalSupplied:        totalSupplied,
+		TotalBorrowed:        totalBorrowed,
+		AvailableLiquidity:   cash,
+		TotalReserves:        totalReserves,
+		Utilisation:          dia.DefiUtilisation(totalBorrowed, totalSupplied),
+		ReserveFactor:        bigAmount(market.ReserveFactor, 18),
+		CollateralFactor:     collateralFactor,
+		LiquidationThreshold: collateralFactor,
+	}
+}
+
+// bigAmount returns @amount in units of a token with @decimals.
+func bigAmount(amount *big.Int, decimals int) float64 {
+	if amount == nil {
+		return 0
+	}
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetFloat64(math.Pow10(decimals))).Float64()
+	return value
+}
+
 func (proto *CompoundProtocol) calculateAPY(rate *big.Int) float64 {
 	// https://compound.finance/docs#protocol-math
 	//Calculate APY
@@ -224,45 +220,40 @@ func (proto *CompoundProtocol) UpdateRate() error {
 	markets := proto.fetchALL()
 
 	for _, market := range markets {
-		asset := &dia.DefiRate{
-			Timestamp:     time.Now(),
-			Asset:         market.Symbol,
-			Protocol:      proto.protocol.Name,
-			LendingRate:   market.SupplyRate,
-			BorrowingRate: market.BorrowRate,
-		}
+		asset := market.defiRate(proto.protocol)
 		log.Printf("writing DEFI rate for  %#v in %v\n", asset, proto.scraper.RateChannel())
-		proto.connection.Close()
 		proto.scraper.RateChannel() <- asset
 	}
 	log.Info("Update complete")
 	return nil
 }
 
-func (proto *CompoundProtocol) getTotalSupply() (float64, error) {
+// getTotals returns the value locked in and the value borrowed from all markets in USD.
+func (proto *CompoundProtocol) getTotals() (totalSupply float64, totalBorrow float64, err error) {
 	// total supply for a market is explained here:
 	// https://compound.finance/docs/ctokens#get-cash
 	markets := proto.fetchALL()
 
-	sum := float64(0)
 	for i := 0; i < len(markets); i++ {
-		supply, err := strconv.ParseFloat(markets[i].Cash.String(), 64)
+		var supply float64
+		supply, err = strconv.ParseFloat(markets[i].Cash.String(), 64)
 		if err != nil {
-			return 0, err
+			return
 		}
 		normalizedSupply := supply / math.Pow10(int(markets[i].Decimal))
-		price, err := utils.GetCoinPrice(markets[i].Symbol)
-		if err != nil {
-			fmt.Println("error getting prices: ", err)
+		price, priceErr := utils.GetCoinPrice(markets[i].Symbol)
+		if priceErr != nil {
+			fmt.Println("error getting prices: ", priceErr)
 		}
-		sum += normalizedSupply * price
+		totalSupply += normalizedSupply * price
+		totalBorrow += bigAmount(markets[i].TotalBorrow, markets[i].Decimal) * price
 	}
-	return sum, nil
+	return totalSupply, totalBorrow, nil
 }
 
 func (proto *CompoundProtocol) UpdateState() error {
 	log.Printf("Updating DEFI state for %+v\n ", proto.protocol)
-	totalValueLocked, err := proto.getTotalSupply()
+	totalValueLocked, totalBorrow, err := proto.getTotals()
 	if err != nil {
 		log.Error(err)
 	}
@@ -271,10 +262,11 @@ func (proto *CompoundProtocol) UpdateState() error {
 		return err
 	}
 	defistate := &dia.DefiProtocolState{
-		TotalUSD:  totalValueLocked,
-		TotalETH:  totalValueLocked / ETHPrice,
-		Protocol:  proto.protocol,
-		Timestamp: time.Now(),
+		TotalUSD:       totalValueLocked,
+		TotalETH:       totalValueLocked / ETHPrice,
+		TotalBorrowUSD: totalBorrow,
+		Protocol:       proto.protocol,
+		Timestamp:      time.Now(),
 	}
 	proto.scraper.StateChannel() <- defistate
 	log.Printf("writing DEFI state for  %#v in %v\n", defistate, proto.scraper.StateChannel())
@@ -1,7 +1,9 @@
 package defiscrapers
 
 import (
+	"context"
 	"fmt"
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao"
 	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/erc20contract"
 	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/jugcontract"
 	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/makerdao/potcontract"
@@ -11,7 +13,7 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
-	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -26,11 +28,19 @@ type Collateral struct {
 	decimals  float64
 }
 
+// makerdaoVaultsPeriod is the time between two scans of the vaults, which take a call per vault.
+const makerdaoVaultsPeriod = time.Hour
+
 type MakerdaoProtocol struct {
 	scraper     *DefiScraper
 	protocol    dia.DefiProtocol
 	connection  *ethclient.Client
 	collaterals []Collateral
+	contracts   makerdao.Contracts
+	reader      *makerdao.Reader
+	// vaultStats receives the statistics of the vaults of the ilks from scanVaults. They are stored with the
+	// analytics, as the datastore is only written by the scraper's main loop.
+	vaultStats chan []models.MakerDAOVaultStats
 }
 
 var (
@@ -170,27 +180,58 @@ var (
 	}
 )
 
-func NewMakerdao(scraper *DefiScraper, protocol dia.DefiProtocol) *MakerdaoProtocol {
-	connection, err := ethhelper.NewETHClient()
+// NewMakerdao returns the helper of the MakerDAO deployment @conf. The mainnet contracts are used
+// unless overridden by the roles vat, pot, jug, spot and cdpManager in the contracts of @conf.
+func NewMakerdao(scraper *DefiScraper, conf ProtocolConfig) (*MakerdaoProtocol, error) {
+	contracts := makerdao.MainnetContracts
+	for role, address := range map[string]*common.Address{
+		"vat":        &contracts.Vat,
+		"pot":        &contracts.Pot,
+		"jug":        &contracts.Jug,
+		"spot":       &contracts.Spot,
+		"cdpManager": &contracts.CdpManager,
+	} {
+		if hex, ok := conf.Contracts[role]; ok {
+			if !common.IsHexAddress(hex) {
+				return nil, fmt.Errorf("makerdao protocol %s has invalid %s address %s", conf.Name, role, hex)
+			}
+			*address = common.HexToAddress(hex)
+		}
+	}
+	connection, err := conf.ETHClient()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := makerdao.NewReader(connection, contracts)
 	if err != nil {
-		log.Error("Error connecting Eth Client")
+		return nil, err
 	}
 
-	return &MakerdaoProtocol{scraper: scraper, protocol: protocol, collaterals: collaterals, connection: connection}
+	proto := &MakerdaoProtocol{
+		scraper:     scraper,
+		protocol:    conf.Protocol(),
+		collaterals: collaterals,
+		connection:  connection,
+		contracts:   contracts,
+		reader:      reader,
+		vaultStats:  make(chan []models.MakerDAOVaultStats, 1),
+	}
+	go proto.scanVaults()
+	return proto, nil
 }
 
 func (proto *MakerdaoProtocol) UpdateRate() error {
 	// DAI lending rate
-	pot, err := potcontract.NewPotcontract(common.HexToAddress("0x197E90f9FAD81970bA7976f33CbD77088E5D7cf7"), proto.connection)
+	pot, err := potcontract.NewPotcontract(proto.contracts.Pot, proto.connection)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	log.Printf("Updating DEFI Rate for %+v\n ", proto.protocol.Name)
 
 	daiLendingInstantRate, err := pot.Dsr(&bind.CallOpts{})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	daiLendingApy := getYearlyFromInstantaneous(convertBigintToFloat64(daiLendingInstantRate))
@@ -206,14 +247,14 @@ func (proto *MakerdaoProtocol) UpdateRate() error {
 	proto.scraper.RateChannel() <- asset
 
 	// Collaterals borrowing rates
-	jug, err := jugcontract.NewJugcontract(common.HexToAddress("0x19c0976f590D67707E62397C87829d896Dc0f1F1"), proto.connection)
+	jug, err := jugcontract.NewJugcontract(proto.contracts.Jug, proto.connection)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	base, err := jug.Base(&bind.CallOpts{})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	for _, col := range proto.collaterals {
@@ -221,7 +262,7 @@ func (proto *MakerdaoProtocol) UpdateRate() error {
 
 		ilks, err := jug.Ilks(&bind.CallOpts{}, collateral)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 
 		baseCopy := *base
@@ -243,7 +284,6 @@ func (proto *MakerdaoProtocol) UpdateRate() error {
 		proto.scraper.RateChannel() <- asset
 	}
 
-	proto.connection.Close()
 	log.Info("Update complete")
 
 	return nil
@@ -289,11 +329,122 @@ func (proto *MakerdaoProtocol) UpdateState() error {
 
 	proto.scraper.StateChannel() <- defistate
 	log.Printf("writing DEFI state for  %#v in %v\n", defistate, proto.scraper.StateChannel())
+
+	if err := proto.updateAnalytics(); err != nil {
+		return err
+	}
 	log.Info("Update State complete")
 
 	return nil
 }
 
+// updateAnalytics stores the state of the ilks and the DSR and the latest vault statistics of scanVaults, if any.
+func (proto *MakerdaoProtocol) updateAnalytics() error {
+	ctx := context.Background()
+	ilks, block, err := proto.readIlks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ilk := range ilks {
+		if err := proto.scraper.datastore.SetMakerDAOIlk(ilk); err != nil {
+			return err
+		}
+	}
+
+	savings, err := proto.reader.Savings(&bind.CallOpts{Context: ctx, BlockNumber: block.number})
+	if err != nil {
+		return err
+	}
+	savings.Time = block.time
+	if err := proto.scraper.datastore.SetMakerDAOSavings(savings); err != nil {
+		return err
+	}
+
+	select {
+	case stats := <-proto.vaultStats:
+		for _, s := range stats {
+			if err := proto.scraper.datastore.SetMakerDAOVaultStats(s); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+	return nil
+}
+
+// makerdaoBlock is a block the state of MakerDAO is read at.
+type makerdaoBlock struct {
+	number *big.Int
+	time   time.Time
+}
+
+// readIlks returns the state of the ilks of the collaterals at the latest block along with the block.
+// Ilks which cannot be read are skipped.
+func (proto *MakerdaoProtocol) readIlks(ctx context.Context) ([]models.MakerDAOIlk, makerdaoBlock, error) {
+	header, err := proto.connection.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, makerdaoBlock{}, err
+	}
+	// Ilks and savings are read at the same block to be consistent with each other.
+	block := makerdaoBlock{number: header.Number, time: time.Unix(int64(header.Time), 0)}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+
+	var ilks []models.MakerDAOIlk
+	for _, col := range proto.collaterals {
+		ilk, err := proto.reader.Ilk(opts, col.name)
+		if err != nil {
+			log.Errorf("reading makerdao ilk %s: %v", col.name, err)
+			continue
+		}
+		ilk.Time = block.time
+		ilks = append(ilks, ilk)
+	}
+	return ilks, block, nil
+}
+
+// scanVaults computes the collateralisation distribution of the vaults of the ilks every makerdaoVaultsPeriod
+// and hands it to updateAnalytics. It runs in its own goroutine, as the scan takes a call per vault and the
+// first one, which fetches the urns of all vaults, takes hours.
+func (proto *MakerdaoProtocol) scanVaults() {
+	for {
+		stats, err := proto.computeVaultStats(context.Background())
+		if err != nil {
+			log.Errorf("scanning makerdao vaults: %v", err)
+		} else {
+			// Stats which have not been stored yet are replaced by the new ones.
+			select {
+			case <-proto.vaultStats:
+			default:
+			}
+			proto.vaultStats <- stats
+		}
+		time.Sleep(makerdaoVaultsPeriod)
+	}
+}
+
+// computeVaultStats returns the statistics of the vaults of each ilk.
+func (proto *MakerdaoProtocol) computeVaultStats(ctx context.Context) ([]models.MakerDAOVaultStats, error) {
+	ilks, _, err := proto.readIlks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ilk := range ilks {
+		names = append(names, ilk.Ilk)
+	}
+	// The scan spans many blocks, so it reads the latest state which is available on all nodes.
+	vaults, err := proto.reader.Vaults(&bind.CallOpts{Context: ctx}, names)
+	if err != nil {
+		return nil, err
+	}
+	var stats []models.MakerDAOVaultStats
+	for _, ilk := range ilks {
+		stats = append(stats, models.ComputeMakerDAOVaultStats(ilk, vaults, models.MakerDAOCollateralisationBounds))
+	}
+	log.Infof("computed collateralisation of %d makerdao vaults", len(vaults))
+	return stats, nil
+}
+
 func convertStringToBytes32(value string) [32]byte {
 	var bytes32 [32]byte
 	copy(bytes32[:], string(value))
@@ -0,0 +1,235 @@
+package ratederivatives
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/utils"
+)
+
+// Convention determines which daily rates are compounded in arrears over an interest period and how they are
+// weighted. Shifts are given in business days, i.e. days with a fixing of the rate.
+type Convention string
+
+const (
+	// ConventionPlain compounds the rate of each business day of the interest period with the calendar days
+	// until the next business day.
+	ConventionPlain Convention = "plain"
+	// ConventionLookback compounds the rate observed the shift before each business day of the interest period,
+	// weighted with the calendar days of the interest period.
+	ConventionLookback Convention = "lookback"
+	// ConventionObservationShift compounds the rates of the observation period, which is the interest period
+	// moved back by the shift, weighted with the calendar days of the observation period. It is the convention of
+	// the ISDA IBOR fallbacks with a shift of 2 business days.
+	ConventionObservationShift Convention = "observationShift"
+	// ConventionLockout compounds as ConventionPlain, but the rates of the business days from the rate cut-off
+	// date, which is the shift before the end of the interest period, are the rate on the cut-off date.
+	ConventionLockout Convention = "lockout"
+)
+
+// ParseConvention returns the convention named @name.
+func ParseConvention(name string) (Convention, error) {
+	switch convention := Convention(name); convention {
+	case ConventionPlain, ConventionLookback, ConventionObservationShift, ConventionLockout:
+		return convention, nil
+	}
+	return "", fmt.Errorf("unknown convention %q", name)
+}
+
+// ErrInvalidPeriod is returned for interest periods and conventions a rate cannot be compounded in arrears over,
+// such as periods starting on a holiday.
+var ErrInvalidPeriod = errors.New("invalid interest period")
+
+// Fixing is the value in percent of a rate on a business day.
+type Fixing struct {
+	Date  time.Time
+	Value float64
+}
+
+// InArrears is the result of compounding a rate in arrears over an interest period.
+type InArrears struct {
+	// Factor is the compounded growth factor over the period and Rate the annualised rate in percent.
+	Factor float64
+	Rate   float64
+	// ObservationStart and ObservationEnd delimit the days whose rates were compounded.
+	ObservationStart time.Time
+	ObservationEnd   time.Time
+	// Days is the number of calendar days the rate is annualised over.
+	Days int
+}
+
+// CompoundInArrears compounds the rates @fixings in arrears over the interest period from @dateInit to @dateFinal
+// with the @convention and a shift of @shift business days. @fixings must hold the rates of all business days
+// from @shift business days before @dateInit up to @dateFinal, as days without fixing are taken as holidays.
+// @dateInit must be a business day, @dateFinal is taken as one. Rates are simple interest on @daysPerYear days.
+// Errors due to the period, convention or shift wrap ErrInvalidPeriod.
+func CompoundInArrears(fixings []Fixing, dateInit, dateFinal time.Time, convention Convention, shift int, daysPerYear int) (InArrears, error) {
+	if daysPerYear <= 0 {
+		return InArrears{}, fmt.Errorf("%w: days per year must be a positive integer", ErrInvalidPeriod)
+	}
+	if shift < 0 || shift > MaxShift {
+		return InArrears{}, fmt.Errorf("%w: shift must be an integer from 0 to %d", ErrInvalidPeriod, MaxShift)
+	}
+	if !utils.AfterDay(dateFinal, dateInit) {
+		return InArrears{}, fmt.Errorf("%w: the final date must be after the initial date", ErrInvalidPeriod)
+	}
+
+	// Business days before @dateFinal followed by @dateFinal.
+	sorted := make([]Fixing, 0, len(fixings)+1)
+	for _, fixing := range fixings {
+		if utils.AfterDay(dateFinal, fixing.Date) {
+			sorted = append(sorted, Fixing{Date: day(fixing.Date), Value: fixing.Value})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	sorted = append(sorted, Fixing{Date: day(dateFinal)})
+
+	start := -1
+	for i, fixing := range sorted {
+		if fixing.Date.Equal(day(dateInit)) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return InArrears{}, fmt.Errorf("%w: no fixing on the initial date %s", ErrInvalidPeriod, dateInit.Format("2006-01-02"))
+	}
+	end := len(sorted) - 1
+	if start < shift {
+		return InArrears{}, fmt.Errorf("%w: %d business days of fixings before the initial date needed", ErrInvalidPeriod, shift)
+	}
+	if convention == ConventionLockout && end-shift < start {
+		return InArrears{}, fmt.Errorf("%w: lockout is longer than the interest period", ErrInvalidPeriod)
+	}
+
+	result := InArrears{Factor: 1, ObservationStart: sorted[start].Date, ObservationEnd: sorted[end].Date}
+	for i := start; i < end; i++ {
+		var fixing Fixing
+		days := calendarDays(sorted[i].Date, sorted[i+1].Date)
+		switch convention {
+		case ConventionPlain:
+			fixing = sorted[i]
+		case ConventionLookback:
+			fixing = sorted[i-shift]
+		case ConventionObservationShift:
+			fixing = sorted[i-shift]
+			days = calendarDays(sorted[i-shift].Date, sorted[i+1-shift].Date)
+		case ConventionLockout:
+			fixing = sorted[min(i, end-shift)]
+		default:
+			return InArrears{}, fmt.Errorf("%w: unknown convention %q", ErrInvalidPeriod, convention)
+		}
+		result.Factor *= 1 + fixing.Value/100*float64(days)/float64(daysPerYear)
+	}
+	if convention == ConventionObservationShift || convention == ConventionLookback {
+		result.ObservationStart = sorted[start-shift].Date
+		result.ObservationEnd = sorted[end-shift].Date
+	}
+
+	result.Days = calendarDays(sorted[start].Date, sorted[end].Date)
+	if convention == ConventionObservationShift {
+		result.Days = calendarDays(result.ObservationStart, result.ObservationEnd)
+	}
+	result.Rate = (result.Factor - 1) * float64(daysPerYear) / float64(result.Days) * 100
+	return result, nil
+}
+
+// IBORFallback is the ISDA fallback of an IBOR: its risk-free rate compounded in arrears with an observation
+// shift of 2 business days, plus a spread adjustment per tenor.
+type IBORFallback struct {
+	IBOR        string
+	RFR         string
+	DaysPerYear int
+	// SpreadAdjustments are the spread adjustments in percent per tenor as fixed by Bloomberg on 5 March 2021.
+	SpreadAdjustments map[string]float64
+}
+
+// FallbackShift is the observation shift in business days of the ISDA IBOR fallbacks.
+const FallbackShift = 2
+
+// MaxShift is the largest shift in business days a rate is compounded in arrears with. Market conventions
+// shift by 2 to 5 business days.
+const MaxShift = 9
+
+// IBORFallbacks are the fallbacks of the IBORs whose risk-free rates are scraped.
+var IBORFallbacks = map[string]IBORFallback{
+	"USD-LIBOR": {
+		IBOR: "USD-LIBOR", RFR: "SOFR", DaysPerYear: 360,
+		SpreadAdjustments: map[string]float64{
+			"ON": 0.00644, "1W": 0.03839, "1M": 0.11448, "2M": 0.18456, "3M": 0.26161, "6M": 0.42826, "12M": 0.71513,
+		},
+	},
+	"GBP-LIBOR": {
+		IBOR: "GBP-LIBOR", RFR: "SONIA", DaysPerYear: 365,
+		SpreadAdjustments: map[string]float64{
+			"ON": -0.0024, "1W": 0.0168, "1M": 0.0326, "2M": 0.0633, "3M": 0.1193, "6M": 0.2766, "12M": 0.4644,
+		},
+	},
+}
+
+// SpreadAdjustment returns the spread adjustment of @tenor of the fallback @f.
+func (f IBORFallback) SpreadAdjustment(tenor string) (float64, error) {
+	spread, ok := f.SpreadAdjustments[tenor]
+	if !ok {
+		return 0, fmt.Errorf("no spread adjustment for tenor %s of %s", tenor, f.IBOR)
+	}
+	return spread, nil
+}
+
+// TenorEnd returns the unadjusted end of the period of @tenor starting on @date. Tenors are ON for overnight or
+// a number followed by W for weeks or M for months.
+func TenorEnd(date time.Time, tenor string) (time.Time, error) {
+	if tenor == "ON" {
+		return date.AddDate(0, 0, 1), nil
+	}
+	var n int
+	var unit string
+	if _, err := fmt.Sscanf(tenor, "%d%s", &n, &unit); err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("invalid tenor %s", tenor)
+	}
+	switch unit {
+	case "W":
+		return date.AddDate(0, 0, 7*n), nil
+	case "M":
+		// Periods starting at the end of a longer month end at the end of the month.
+		end := date.AddDate(0, n, 0)
+		if end.Day() != date.Day() {
+			end = end.AddDate(0, 0, -end.Day())
+		}
+		return end, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid tenor %s", tenor)
+}
+
+// SpreadAdjustment returns the ISDA spread adjustment from the historical spreads between an IBOR and its
+// compounded risk-free rate, which is their median over the five years before the announcement of the
+// cessation of the IBOR.
+func SpreadAdjustment(spreads []float64) (float64, error) {
+	if len(spreads) == 0 {
+		return 0, errors.New("no spreads")
+	}
+	sorted := append([]float64(nil), spreads...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2], nil
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2, nil
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func calendarDays(from, to time.Time) int {
+	return int(day(to).Sub(day(from)).Hours()/24 + 0.5)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,192 @@
+package ratederivatives
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+)
+
+// inArrearsFixture holds fixings and reference values of rates compounded in arrears, see its description.
+type inArrearsFixture struct {
+	Fixings []struct {
+		Date  string  `json:"date"`
+		Value float64 `json:"value"`
+	} `json:"fixings"`
+	Cases []struct {
+		DateInit    string     `json:"dateInit"`
+		DateFinal   string     `json:"dateFinal"`
+		Convention  Convention `json:"convention"`
+		Shift       int        `json:"shift"`
+		DaysPerYear int        `json:"daysPerYear"`
+		Factor      float64    `json:"factor"`
+		Rate        float64    `json:"rate"`
+	} `json:"cases"`
+}
+
+func TestCompoundInArrears(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/inArrears.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture inArrearsFixture
+	if err = json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	var fixings []Fixing
+	for _, f := range fixture.Fixings {
+		date, _ := time.Parse("2006-01-02", f.Date)
+		fixings = append(fixings, Fixing{Date: date, Value: f.Value})
+	}
+
+	for _, c := range fixture.Cases {
+		dateInit, _ := time.Parse("2006-01-02", c.DateInit)
+		dateFinal, _ := time.Parse("2006-01-02", c.DateFinal)
+		result, err := CompoundInArrears(fixings, dateInit, dateFinal, c.Convention, c.Shift, c.DaysPerYear)
+		if err != nil {
+			t.Errorf("%s %d from %s to %s: unexpected error: %v", c.Convention, c.Shift, c.DateInit, c.DateFinal, err)
+			continue
+		}
+		if math.Abs(result.Factor-c.Factor) > 1e-11 || math.Abs(result.Rate-c.Rate) > 1e-9 {
+			t.Errorf("%s %d from %s to %s was incorrect, got: %v, %v, want: %v, %v.", c.Convention, c.Shift, c.DateInit, c.DateFinal, result.Factor, result.Rate, c.Factor, c.Rate)
+		}
+	}
+}
+
+// sofrFixture holds SOFR and the SOFR Index as published by the New York Fed, see its description.
+type sofrFixture struct {
+	Fixings []struct {
+		Date  string  `json:"date"`
+		Value float64 `json:"value"`
+	} `json:"fixings"`
+	Index []struct {
+		Date  string  `json:"date"`
+		Value float64 `json:"value"`
+	} `json:"index"`
+	Cases []struct {
+		DateInit         string     `json:"dateInit"`
+		DateFinal        string     `json:"dateFinal"`
+		Convention       Convention `json:"convention"`
+		Shift            int        `json:"shift"`
+		ObservationStart string     `json:"observationStart"`
+		ObservationEnd   string     `json:"observationEnd"`
+		Rate             float64    `json:"rate"`
+	} `json:"cases"`
+}
+
+func TestCompoundInArrearsSOFRIndex(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/sofr.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture sofrFixture
+	if err = json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	var fixings []Fixing
+	for _, f := range fixture.Fixings {
+		date, _ := time.Parse("2006-01-02", f.Date)
+		fixings = append(fixings, Fixing{Date: date, Value: f.Value})
+	}
+	index := make(map[string]float64)
+	for _, i := range fixture.Index {
+		index[i.Date] = i.Value
+	}
+
+	for _, c := range fixture.Cases {
+		dateInit, _ := time.Parse("2006-01-02", c.DateInit)
+		dateFinal, _ := time.Parse("2006-01-02", c.DateFinal)
+		result, err := CompoundInArrears(fixings, dateInit, dateFinal, c.Convention, c.Shift, 360)
+		if err != nil {
+			t.Errorf("%s %d from %s to %s: unexpected error: %v", c.Convention, c.Shift, c.DateInit, c.DateFinal, err)
+			continue
+		}
+		if result.ObservationStart.Format("2006-01-02") != c.ObservationStart || result.ObservationEnd.Format("2006-01-02") != c.ObservationEnd {
+			t.Errorf("%s %d from %s to %s: observation period was incorrect, got: %v to %v, want: %s to %s.", c.Convention, c.Shift, c.DateInit, c.DateFinal, result.ObservationStart, result.ObservationEnd, c.ObservationStart, c.ObservationEnd)
+			continue
+		}
+		// The index is published with 8 decimals and the averages with 5.
+		factor := index[c.ObservationEnd] / index[c.ObservationStart]
+		if math.Abs(result.Factor-factor) > 1e-8 || math.Abs(result.Rate-c.Rate) > 5e-6 {
+			t.Errorf("%s %d from %s to %s was incorrect, got: %v, %v, want: %v, %v.", c.Convention, c.Shift, c.DateInit, c.DateFinal, result.Factor, result.Rate, factor, c.Rate)
+		}
+	}
+}
+
+func TestCompoundInArrearsErrors(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	fixings := []Fixing{{date("2021-04-05"), 1}, {date("2021-04-06"), 1}, {date("2021-04-07"), 1}}
+	for _, c := range []struct {
+		dateInit, dateFinal string
+		convention          Convention
+		shift               int
+	}{
+		// Initial date without fixing.
+		{"2021-04-04", "2021-04-07", ConventionPlain, 0},
+		// Not enough fixings before the initial date.
+		{"2021-04-06", "2021-04-08", ConventionObservationShift, 2},
+		// Lockout longer than the period.
+		{"2021-04-06", "2021-04-07", ConventionLockout, 2},
+		{"2021-04-06", "2021-04-07", Convention("unknown"), 0},
+		{"2021-04-07", "2021-04-06", ConventionPlain, 0},
+		// Shift beyond MaxShift.
+		{"2021-04-06", "2021-04-07", ConventionLookback, MaxShift + 1},
+	} {
+		if _, err := CompoundInArrears(fixings, date(c.dateInit), date(c.dateFinal), c.convention, c.shift, 360); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("%s %d from %s to %s: expected invalid period, got: %v", c.convention, c.shift, c.dateInit, c.dateFinal, err)
+		}
+	}
+}
+
+func TestSpreadAdjustment(t *testing.T) {
+	for _, c := range []struct {
+		spreads []float64
+		want    float64
+	}{
+		{[]float64{0.3, 0.1, 0.2}, 0.2},
+		{[]float64{0.4, 0.1, 0.2, 0.3}, 0.25},
+	} {
+		if got, err := SpreadAdjustment(c.spreads); err != nil || math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("spread adjustment of %v was incorrect, got: %v, want: %v.", c.spreads, got, c.want)
+		}
+	}
+	if spread, err := IBORFallbacks["USD-LIBOR"].SpreadAdjustment("3M"); err != nil || spread != 0.26161 {
+		t.Errorf("spread adjustment of 3M USD LIBOR was incorrect, got: %v, want: %v.", spread, 0.26161)
+	}
+}
+
+func TestTenorEnd(t *testing.T) {
+	date := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		tenor string
+		want  time.Time
+	}{
+		{"ON", time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"1W", time.Date(2021, 2, 7, 0, 0, 0, 0, time.UTC)},
+		{"1M", time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{"3M", time.Date(2021, 4, 30, 0, 0, 0, 0, time.UTC)},
+	} {
+		if got, err := TenorEnd(date, c.tenor); err != nil || !got.Equal(c.want) {
+			t.Errorf("end of %s was incorrect, got: %v, want: %v.", c.tenor, got, c.want)
+		}
+	}
+	if _, err := TenorEnd(date, "3Y"); err == nil {
+		t.Errorf("expected error for tenor 3Y")
+	}
+}
+
+func TestParseConvention(t *testing.T) {
+	for _, name := range []string{"plain", "lookback", "observationShift", "lockout"} {
+		if convention, err := ParseConvention(name); err != nil || string(convention) != name {
+			t.Errorf("convention %s was incorrect, got: %v, %v.", name, convention, err)
+		}
+	}
+	if _, err := ParseConvention("shift"); err == nil {
+		t.Errorf("unknown convention was accepted")
+	}
+}
@@ -125,6 +125,12 @@ type NFTTrade struct {
 	Timestamp   time.Time
 	TxHash      string
 	Exchange    string
+	// Quantity is the number of items of the token sold. It is 1 except for ERC1155 tokens.
+	Quantity uint64
+	// BundleID is shared by the trades of all tokens sold in a single order and empty otherwise.
+	// Price and PriceUSD of such a trade are the share of the bundle price allocated to the token.
+	BundleID   string
+	BundleSize int
 }
 
 // MarshalBinary for DefiProtocolState
@@ -205,6 +211,47 @@ func (no *NFTOffer) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+const (
+	// NFTOrderSideBid and NFTOrderSideOffer are the sides of an order in the order book of an nft.
+	NFTOrderSideBid   = "bid"
+	NFTOrderSideOffer = "offer"
+
+	// Types of an NFTOrderEvent and statuses of an order.
+	NFTOrderPlaced    = "placed"
+	NFTOrderFilled    = "filled"
+	NFTOrderCancelled = "cancelled"
+	NFTOrderExpired   = "expired"
+)
+
+// NFTOrderEvent is a change in the lifecycle of a bid or an offer on a marketplace.
+type NFTOrderEvent struct {
+	NFT NFT
+	// Side is NFTOrderSideBid or NFTOrderSideOffer.
+	Side string
+	// Type is one of NFTOrderPlaced, NFTOrderFilled, NFTOrderCancelled and NFTOrderExpired.
+	Type string
+	// OrderID identifies the order on Exchange. Marketplaces which allow one order per token and side
+	// use an ID derived from the token, so a new order replaces the previous one.
+	OrderID string
+	// Maker is the address which placed the order. If set on events other than placements,
+	// the event only applies to an order of this maker.
+	Maker string
+	// Price of the order in units of Currency. For auctions Price is the start and EndPrice
+	// the end price, reached linearly after Duration. Otherwise EndPrice is nil.
+	Price    *big.Int
+	EndPrice *big.Int
+	Duration time.Duration
+	Currency Asset
+	// Expiry is the time the order expires and zero for orders without expiry.
+	Expiry time.Time
+
+	BlockNumber   uint64
+	BlockPosition uint64
+	Timestamp     time.Time
+	TxHash        string
+	Exchange      string
+}
+
 // BlockData stores information on a specific block in a given blockchain.
 type BlockData struct {
 	// Name of the blockchain, as found for instance in dia.ETHEREUM
@@ -388,6 +435,47 @@ type Trade struct {
 	VerifiedPair      bool // will be filled by the pairDiscoveryService
 }
 
+// PoolType is the invariant of the liquidity pool of an AMM.
+type PoolType string
+
+const (
+	// ConstantProductPool is a pool of two assets of equal weight whose reserves keep a constant product,
+	// such as the pools of UniswapV2 and its forks.
+	ConstantProductPool PoolType = "ConstantProduct"
+	// WeightedPool keeps the product of its reserves raised to their weights constant, such as Balancer pools.
+	WeightedPool PoolType = "Weighted"
+	// StableSwapPool is a pool of assets pegged to each other which follows the stableswap invariant,
+	// such as Curve pools and Balancer stable pools.
+	StableSwapPool PoolType = "StableSwap"
+)
+
+// PoolAsset is an asset held by a liquidity pool along with its reserve.
+type PoolAsset struct {
+	Asset   Asset
+	Reserve float64
+	// Weight is the normalised weight of the asset in weighted pools and zero otherwise.
+	Weight float64
+}
+
+// Pool is the state of a liquidity pool of an AMM.
+type Pool struct {
+	Exchange   string
+	Blockchain string
+	Address    string
+	Type       PoolType
+	Assets     []PoolAsset
+	// LPToken is the token of the liquidity providers and TotalSupply its amount outstanding.
+	LPToken     Asset
+	TotalSupply float64
+	// Fee is the swap fee as a fraction of the amount swapped.
+	Fee float64
+	// Amplification is the amplification parameter of stableswap pools as stored by the pool, i.e. A·n^(n-1)
+	// in terms of the stableswap whitepaper.
+	Amplification float64
+	BlockNumber   uint64
+	Time          time.Time
+}
+
 type ItinToken struct {
 	Itin               string
 	Symbol             string
@@ -449,19 +537,47 @@ type DefiProtocol struct {
 	Token                string
 }
 
+// DefiProtocolState is the state of a lending protocol. TotalUSD and TotalETH are the
+// value locked in the protocol, TotalBorrowUSD is the value of all outstanding borrows.
 type DefiProtocolState struct {
-	TotalUSD  float64
-	TotalETH  float64
-	Timestamp time.Time
-	Protocol  DefiProtocol
+	TotalUSD       float64
+	TotalETH       float64
+	TotalBorrowUSD float64
+	Timestamp      time.Time
+	Protocol       DefiProtocol
 }
 
+// DefiRate is the state of the lending market of an asset on a protocol.
+// Rates are annual rates in per cent. Utilisation and the factors are fractions in [0,1].
 type DefiRate struct {
 	Timestamp     time.Time
 	LendingRate   float64
 	BorrowingRate float64
 	Asset         string
 	Protocol      string
+	// AssetAddress and AssetBlockchain identify the underlying asset of the market.
+	AssetAddress    string
+	AssetBlockchain string
+	// StableBorrowRate is zero for protocols that only offer variable rate borrows.
+	StableBorrowRate   float64
+	VariableBorrowRate float64
+	// TotalSupplied, TotalBorrowed, AvailableLiquidity and TotalReserves are in units of the asset.
+	TotalSupplied        float64
+	TotalBorrowed        float64
+	AvailableLiquidity   float64
+	TotalReserves        float64
+	Utilisation          float64
+	ReserveFactor        float64
+	CollateralFactor     float64
+	LiquidationThreshold float64
+}
+
+// DefiUtilisation returns the share of the supplied funds of a lending market which is borrowed.
+func DefiUtilisation(totalBorrowed, totalSupplied float64) float64 {
+	if totalSupplied <= 0 {
+		return 0
+	}
+	return totalBorrowed / totalSupplied
 }
 
 type TradesBlockData struct {
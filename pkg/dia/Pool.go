@@ -0,0 +1,124 @@
+package dia
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	stableSwapIterations = 255
+	stableSwapPrecision  = 1e-12
+)
+
+// FairValue returns the value in USD of the reserves of the pool, given the USD @prices of its assets in the order
+// of p.Assets. Instead of the spot reserves, which can be moved at will within a block, the value is computed from
+// the invariant of the pool and the external @prices:
+//
+// For weighted and constant product pools with invariant V = Π r_i^w_i, the reserves an arbitrageur leaves when
+// aligning the pool with @prices are r_i = V·w_i/p_i·Π (p_j/w_j)^w_j, whose value is V·Π (p_j/w_j)^w_j.
+// Constant product pools weight their assets equally.
+//
+// Stableswap pools are valued at their invariant D, which is the value of the reserves when all assets trade at
+// par, times the lowest of the @prices. This is a lower bound of the value if an asset loses its peg.
+func (p *Pool) FairValue(prices []float64) (float64, error) {
+	if len(prices) != len(p.Assets) {
+		return 0, fmt.Errorf("%d prices for %d assets of pool %s", len(prices), len(p.Assets), p.Address)
+	}
+	if len(p.Assets) < 2 {
+		return 0, fmt.Errorf("pool %s has less than 2 assets", p.Address)
+	}
+	for i, price := range prices {
+		if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+			return 0, fmt.Errorf("invalid price %v of %s in pool %s", price, p.Assets[i].Asset.Symbol, p.Address)
+		}
+	}
+
+	switch p.Type {
+	case ConstantProductPool, WeightedPool:
+		// Computed in logarithms so that large reserves do not overflow.
+		var logValue, totalWeight float64
+		for i, asset := range p.Assets {
+			weight := asset.Weight
+			if p.Type == ConstantProductPool {
+				weight = 1 / float64(len(p.Assets))
+			}
+			if asset.Reserve <= 0 || weight <= 0 {
+				return 0, nil
+			}
+			logValue += weight * (math.Log(asset.Reserve) + math.Log(prices[i]/weight))
+			totalWeight += weight
+		}
+		if math.Abs(totalWeight-1) > 1e-6 {
+			return 0, fmt.Errorf("weights of pool %s sum to %v", p.Address, totalWeight)
+		}
+		return math.Exp(logValue), nil
+	case StableSwapPool:
+		reserves := make([]float64, len(p.Assets))
+		minPrice := math.Inf(1)
+		for i, asset := range p.Assets {
+			reserves[i] = asset.Reserve
+			minPrice = math.Min(minPrice, prices[i])
+		}
+		invariant, err := StableSwapInvariant(reserves, p.Amplification)
+		if err != nil {
+			return 0, fmt.Errorf("invariant of pool %s: %v", p.Address, err)
+		}
+		return invariant * minPrice, nil
+	default:
+		return 0, fmt.Errorf("unknown type %q of pool %s", p.Type, p.Address)
+	}
+}
+
+// FairLPTokenPrice returns the price in USD of the LP token of the pool, which is its FairValue over the
+// total supply of LP tokens.
+func (p *Pool) FairLPTokenPrice(prices []float64) (float64, error) {
+	if p.TotalSupply <= 0 {
+		return 0, fmt.Errorf("no LP tokens of pool %s outstanding", p.Address)
+	}
+	value, err := p.FairValue(prices)
+	if err != nil {
+		return 0, err
+	}
+	return value / p.TotalSupply, nil
+}
+
+// StableSwapInvariant returns the invariant D of a stableswap pool with @reserves and the amplification
+// parameter @amp as stored by Curve pools, i.e. A·n^(n-1). It solves
+// A·n^n·Σx + D = A·D·n^n + D^(n+1)/(n^n·Πx) with Newton's method as the pools do.
+func StableSwapInvariant(reserves []float64, amp float64) (float64, error) {
+	n := float64(len(reserves))
+	if amp <= 0 {
+		return 0, errors.New("amplification must be positive")
+	}
+	var sum float64
+	for _, x := range reserves {
+		if x < 0 {
+			return 0, errors.New("negative reserve")
+		}
+		sum += x
+	}
+	if sum == 0 {
+		return 0, nil
+	}
+	for _, x := range reserves {
+		if x == 0 {
+			return 0, errors.New("pool is drained of an asset")
+		}
+	}
+
+	ann := amp * n
+	d := sum
+	for i := 0; i < stableSwapIterations; i++ {
+		dP := d
+		for _, x := range reserves {
+			dP = dP * d / (x * n)
+		}
+		previous := d
+		d = (ann*sum + dP*n) * d / ((ann-1)*d + (n+1)*dP)
+		if math.Abs(d-previous) <= stableSwapPrecision*d {
+			return d, nil
+		}
+	}
+	return 0, errors.New("invariant did not converge")
+}
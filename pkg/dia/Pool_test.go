@@ -0,0 +1,107 @@
+package dia
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFairValue(t *testing.T) {
+	weth := Asset{Symbol: "WETH"}
+	usdc := Asset{Symbol: "USDC"}
+	bal := Asset{Symbol: "BAL"}
+	for _, c := range []struct {
+		name   string
+		pool   Pool
+		prices []float64
+		want   float64
+	}{
+		{
+			name: "balanced constant product",
+			pool: Pool{Type: ConstantProductPool, Assets: []PoolAsset{{Asset: weth, Reserve: 1000}, {Asset: usdc, Reserve: 2e6}}},
+			// 2·sqrt(1000·2e6·2000·1)
+			prices: []float64{2000, 1},
+			want:   4e6,
+		},
+		{
+			// Same invariant as above after a swap moved the spot price to 500 USDC, spot value would be 5e6.
+			name:   "manipulated constant product",
+			pool:   Pool{Type: ConstantProductPool, Assets: []PoolAsset{{Asset: weth, Reserve: 2000}, {Asset: usdc, Reserve: 1e6}}},
+			prices: []float64{2000, 1},
+			want:   4e6,
+		},
+		{
+			name:   "balanced weighted",
+			pool:   Pool{Type: WeightedPool, Assets: []PoolAsset{{Asset: bal, Reserve: 8000, Weight: 0.8}, {Asset: weth, Reserve: 10, Weight: 0.2}}},
+			prices: []float64{10, 2000},
+			want:   1e5,
+		},
+		{
+			name:   "balanced stableswap",
+			pool:   Pool{Type: StableSwapPool, Amplification: 200, Assets: []PoolAsset{{Asset: usdc, Reserve: 1e6}, {Asset: Asset{Symbol: "DAI"}, Reserve: 1e6}}},
+			prices: []float64{1, 0.98},
+			want:   1.96e6,
+		},
+	} {
+		got, err := c.pool.FairValue(c.prices)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if math.Abs(got-c.want) > 1e-6*c.want {
+			t.Errorf("%s: fair value was incorrect, got: %v, want: %v.", c.name, got, c.want)
+		}
+	}
+}
+
+func TestFairValueErrors(t *testing.T) {
+	pool := Pool{Type: WeightedPool, Assets: []PoolAsset{{Reserve: 1, Weight: 0.5}, {Reserve: 1, Weight: 0.4}}}
+	if _, err := pool.FairValue([]float64{1, 1}); err == nil {
+		t.Errorf("expected error for weights not summing to 1")
+	}
+	pool.Assets[1].Weight = 0.5
+	if _, err := pool.FairValue([]float64{1}); err == nil {
+		t.Errorf("expected error for missing price")
+	}
+	if _, err := pool.FairValue([]float64{1, 0}); err == nil {
+		t.Errorf("expected error for zero price")
+	}
+	if _, err := pool.FairLPTokenPrice([]float64{1, 1}); err == nil {
+		t.Errorf("expected error for zero LP token supply")
+	}
+	pool.TotalSupply = 4
+	if price, err := pool.FairLPTokenPrice([]float64{1, 1}); err != nil || math.Abs(price-0.5) > 1e-12 {
+		t.Errorf("lp token price was incorrect, got: %v, %v, want: %v.", price, err, 0.5)
+	}
+}
+
+func TestStableSwapInvariant(t *testing.T) {
+	for _, c := range []struct {
+		reserves []float64
+		amp      float64
+	}{
+		{[]float64{1.5e6, 0.5e6}, 100},
+		{[]float64{3e6, 1e6, 2e6}, 2000},
+		{[]float64{1e6, 1}, 10},
+	} {
+		d, err := StableSwapInvariant(c.reserves, c.amp)
+		if err != nil {
+			t.Errorf("invariant of %v: unexpected error: %v", c.reserves, err)
+			continue
+		}
+		// Check A·n^n·Σx + D = A·D·n^n + D^(n+1)/(n^n·Πx) with A·n^n = amp·n.
+		n := float64(len(c.reserves))
+		sum, prod := 0.0, 1.0
+		for _, x := range c.reserves {
+			sum += x
+			prod *= x
+		}
+		lhs := c.amp*n*sum + d
+		rhs := c.amp*n*d + math.Pow(d, n+1)/(math.Pow(n, n)*prod)
+		if math.Abs(lhs-rhs) > 1e-9*lhs || d > sum {
+			t.Errorf("invariant of %v was incorrect, got: %v, residual: %v.", c.reserves, d, lhs-rhs)
+		}
+	}
+	if _, err := StableSwapInvariant([]float64{1, 0}, 100); err == nil {
+		t.Errorf("expected error for drained pool")
+	}
+}
@@ -0,0 +1,114 @@
+package flowhelper
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// UFix64Decimals is the number of decimals of UFix64 values, the type of all fungible token amounts on Flow.
+const UFix64Decimals = 8
+
+// EventType returns the qualified type of the event @event declared in the contract @contract
+// deployed at @address, e.g. A.0b2a3299cc857e29.TopShot.Deposit.
+func EventType(address string, contract string, event string) string {
+	return "A." + strings.TrimPrefix(strings.ToLower(address), "0x") + "." + contract + "." + event
+}
+
+// SporkEndHeight returns the last block height served by the access node of the spork containing @height.
+// The returned bool is false if @height belongs to the current spork.
+func SporkEndHeight(height uint64) (uint64, bool) {
+	for _, root := range RootHeights {
+		if height < root {
+			return root - 1, true
+		}
+	}
+	return 0, false
+}
+
+// EventField returns the value of the field @name of @event.
+func EventField(event cadence.Event, name string) (cadence.Value, error) {
+	if event.EventType == nil {
+		return nil, fmt.Errorf("event without type has no field %s", name)
+	}
+	for i, field := range event.EventType.Fields {
+		if field.Identifier == name && i < len(event.Fields) {
+			return event.Fields[i], nil
+		}
+	}
+	return nil, fmt.Errorf("event %s has no field %s", event.EventType.QualifiedIdentifier, name)
+}
+
+// UInt64Field returns the UInt64 field @name of @event.
+func UInt64Field(event cadence.Event, name string) (uint64, error) {
+	value, err := EventField(event, name)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := value.(cadence.UInt64)
+	if !ok {
+		return 0, fmt.Errorf("field %s is not an UInt64", name)
+	}
+	return uint64(v), nil
+}
+
+// UFix64Field returns the UFix64 field @name of @event in units of 10^-UFix64Decimals.
+func UFix64Field(event cadence.Event, name string) (uint64, error) {
+	value, err := EventField(event, name)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := value.(cadence.UFix64)
+	if !ok {
+		return 0, fmt.Errorf("field %s is not an UFix64", name)
+	}
+	return uint64(v), nil
+}
+
+// BoolField returns the Bool field @name of @event.
+func BoolField(event cadence.Event, name string) (bool, error) {
+	value, err := EventField(event, name)
+	if err != nil {
+		return false, err
+	}
+	v, ok := value.(cadence.Bool)
+	if !ok {
+		return false, fmt.Errorf("field %s is not a Bool", name)
+	}
+	return bool(v), nil
+}
+
+// AddressField returns the Address or optional Address field @name of @event as 0x prefixed hex string.
+// An empty optional is returned as empty string.
+func AddressField(event cadence.Event, name string) (string, error) {
+	value, err := EventField(event, name)
+	if err != nil {
+		return "", err
+	}
+	if optional, ok := value.(cadence.Optional); ok {
+		if optional.Value == nil {
+			return "", nil
+		}
+		value = optional.Value
+	}
+	v, ok := value.(cadence.Address)
+	if !ok {
+		return "", fmt.Errorf("field %s is not an Address", name)
+	}
+	return "0x" + hex.EncodeToString(v.Bytes()), nil
+}
+
+// TypeField returns the qualified identifier of the Type field @name of @event.
+func TypeField(event cadence.Event, name string) (string, error) {
+	value, err := EventField(event, name)
+	if err != nil {
+		return "", err
+	}
+	v, ok := value.(cadence.TypeValue)
+	if !ok {
+		return "", fmt.Errorf("field %s is not a Type", name)
+	}
+	return v.StaticType, nil
+}
@@ -0,0 +1,70 @@
+// Package oraclehelper holds what the feeders of the oracle contracts have in common: the update thresholds,
+// the reasons of updates and waiting for the receipts of their transactions.
+package oraclehelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reasons an update is pushed for.
+const (
+	ReasonInitial   = "initial"
+	ReasonHeartbeat = "heartbeat"
+	ReasonDeviation = "deviation"
+)
+
+// receiptTimeout bounds the wait for the transaction of an update to be mined.
+const receiptTimeout = 5 * time.Minute
+
+// ErrTxFailed is returned if the transaction of an update was mined but reverted.
+var ErrTxFailed = errors.New("oracle transaction failed")
+
+// UpdateReason returns why an update at @timestamp should be pushed given the push time @lastPush of the last
+// update, or an empty string if it is not due yet. @lastPush is zero if no update was pushed. @deviates
+// tells whether the values of the update deviate from the last ones and is only called if there are any.
+func UpdateReason(lastPush, timestamp time.Time, heartbeat time.Duration, deviates func() bool) string {
+	switch {
+	case lastPush.IsZero():
+		return ReasonInitial
+	case heartbeat > 0 && timestamp.Sub(lastPush) >= heartbeat:
+		return ReasonHeartbeat
+	case deviates():
+		return ReasonDeviation
+	}
+	return ""
+}
+
+// Deviates returns true if @new moves by more than @permille of @old and by more than @minDeviation.
+// Any change of a zero value by more than @minDeviation deviates.
+func Deviates(old, new float64, permille int, minDeviation float64) bool {
+	change := math.Abs(new - old)
+	return change > math.Abs(old)*float64(permille)/1000 && change > minDeviation
+}
+
+// TransactOpts returns a copy of @auth for transactions sent with @ctx.
+func TransactOpts(ctx context.Context, auth *bind.TransactOpts) *bind.TransactOpts {
+	opts := *auth
+	opts.Context = ctx
+	return &opts
+}
+
+// WaitMined waits for @tx to be mined on @backend and returns ErrTxFailed if it reverted.
+func WaitMined(ctx context.Context, backend bind.DeployBackend, tx *types.Transaction) error {
+	ctx, cancel := context.WithTimeout(ctx, receiptTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, backend, tx)
+	if err != nil {
+		return fmt.Errorf("wait for tx %s: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%w: tx %s in block %v", ErrTxFailed, tx.Hash().Hex(), receipt.BlockNumber)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package oraclehelper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateReason(t *testing.T) {
+	start := time.Unix(1640995200, 0)
+	for _, c := range []struct {
+		lastPush time.Time
+		elapsed  time.Duration
+		deviates bool
+		want     string
+	}{
+		{time.Time{}, 0, false, ReasonInitial},
+		{start, time.Minute, false, ""},
+		{start, time.Minute, true, ReasonDeviation},
+		{start, time.Hour, false, ReasonHeartbeat},
+		{start, time.Hour, true, ReasonHeartbeat},
+	} {
+		got := UpdateReason(c.lastPush, start.Add(c.elapsed), time.Hour, func() bool { return c.deviates })
+		if got != c.want {
+			t.Errorf("reason after %v was incorrect, got: %q, want: %q.", c.elapsed, got, c.want)
+		}
+	}
+}
+
+func TestDeviates(t *testing.T) {
+	for _, c := range []struct {
+		old, new     float64
+		minDeviation float64
+		want         bool
+	}{
+		{100, 100.5, 0, false},
+		{100, 101.5, 0, true},
+		{-100, -101.5, 0, true},
+		{0, 0.001, 0, true},
+		{0, 0.001, 0.01, false},
+		{0.1, 0.105, 0.01, false},
+		{0, 0, 0, false},
+	} {
+		if got := Deviates(c.old, c.new, 10, c.minDeviation); got != c.want {
+			t.Errorf("deviation from %v to %v was incorrect, got: %v, want: %v.", c.old, c.new, got, c.want)
+		}
+	}
+}
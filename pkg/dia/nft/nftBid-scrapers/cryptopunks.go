@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -46,6 +48,7 @@ func NewCryptoPunksScraper(rdb *models.RelDB) *CryptoPunksScraper {
 		ethConnection: connection,
 		datastore:     rdb,
 		chanBid:       make(chan dia.NFTBid),
+		chanOrder:     make(chan dia.NFTOrderEvent),
 	}
 	s := &CryptoPunksScraper{
 		contractAddress: common.HexToAddress("0xb47e3cd837dDF8e4c57F05d70Ab865de6e193BBB"),
@@ -112,6 +115,7 @@ func (scraper *CryptoPunksScraper) FetchBids() error {
 	if err != nil {
 		log.Error("fetching cryptopunk nft class: ", err)
 	}
+	var events []dia.NFTOrderEvent
 	// Reduce the window size while there is a query limit error.
 	for {
 
@@ -165,20 +169,114 @@ func (scraper *CryptoPunksScraper) FetchBids() error {
 			}
 			log.Infof("got bid at time %v: %v\n", bid.Timestamp, bid)
 			scraper.GetBidChannel() <- bid
+
+			events = append(events, dia.NFTOrderEvent{
+				NFT:           bid.NFT,
+				Side:          dia.NFTOrderSideBid,
+				Type:          dia.NFTOrderPlaced,
+				OrderID:       cryptoPunksBidID(iterBid.Event.PunkIndex),
+				Maker:         bid.FromAddress,
+				Price:         bid.Value,
+				Currency:      etherCurrency,
+				BlockNumber:   bid.BlockNumber,
+				BlockPosition: bid.BlockPosition,
+				Timestamp:     bid.Timestamp,
+				TxHash:        bid.TxHash,
+				Exchange:      bid.Exchange,
+			})
 		}
 		break
 	}
 
+	lifecycleEvents, err := scraper.fetchBidLifecycle(filterer, nftclass, endBlockNumber)
+	if err != nil {
+		return err
+	}
+	sendOrderEvents(append(events, lifecycleEvents...), scraper.GetOrderChannel())
+
 	// Update the last lastBlockNumber value.
 	scraper.lastBlockNumber = endBlockNumber
 	return nil
 }
 
+// fetchBidLifecycle returns the withdrawals and acceptances of bids up to @endBlockNumber. The contract keeps
+// one bid per punk, which is withdrawn when its bidder buys the punk.
+func (scraper *CryptoPunksScraper) fetchBidLifecycle(filterer *cryptopunk.CryptoPunksMarketFilterer, nftclass dia.NFTClass, endBlockNumber uint64) (events []dia.NFTOrderEvent, err error) {
+	opts := &bind.FilterOpts{
+		Start: scraper.lastBlockNumber,
+		End:   &endBlockNumber,
+	}
+	newEvent := func(punkIndex *big.Int, eventType string, maker string, raw types.Log) dia.NFTOrderEvent {
+		timestamp, err := ethhelper.GetBlockTimeEth(int64(raw.BlockNumber), scraper.bidScraper.datastore, scraper.bidScraper.ethConnection)
+		if err != nil {
+			log.Errorf("getting block time: %+v", err)
+		}
+		return dia.NFTOrderEvent{
+			NFT:           dia.NFT{NFTClass: nftclass, TokenID: punkIndex.String()},
+			Side:          dia.NFTOrderSideBid,
+			Type:          eventType,
+			OrderID:       cryptoPunksBidID(punkIndex),
+			Maker:         maker,
+			BlockNumber:   raw.BlockNumber,
+			BlockPosition: uint64(raw.Index),
+			Timestamp:     timestamp,
+			TxHash:        raw.TxHash.Hex(),
+			Exchange:      "CryptopunkMarket",
+		}
+	}
+
+	iterWithdrawn, err := filterer.FilterPunkBidWithdrawn(opts, nil, nil)
+	if err != nil {
+		return
+	}
+	var withdrawn []*cryptopunk.CryptoPunksMarketPunkBidWithdrawn
+	for iterWithdrawn.Next() {
+		withdrawn = append(withdrawn, iterWithdrawn.Event)
+	}
+
+	iterBought, err := filterer.FilterPunkBought(opts, nil, nil, nil)
+	if err != nil {
+		return
+	}
+	var bought []*cryptopunk.CryptoPunksMarketPunkBought
+	for iterBought.Next() {
+		bought = append(bought, iterBought.Event)
+	}
+	return cryptoPunksBidEvents(withdrawn, bought, newEvent), nil
+}
+
+// cryptoPunksBidEvents returns the order events of the bids changed by the withdrawals @withdrawn and the purchases
+// @bought, created by @newEvent. A purchase with zero value is an accepted bid, the contract emits the event after
+// deleting the bid. Any other purchase refunds a bid of the buyer.
+func cryptoPunksBidEvents(withdrawn []*cryptopunk.CryptoPunksMarketPunkBidWithdrawn, bought []*cryptopunk.CryptoPunksMarketPunkBought, newEvent func(punkIndex *big.Int, eventType string, maker string, raw types.Log) dia.NFTOrderEvent) (events []dia.NFTOrderEvent) {
+	for _, event := range withdrawn {
+		events = append(events, newEvent(event.PunkIndex, dia.NFTOrderCancelled, event.FromAddress.Hex(), event.Raw))
+	}
+	for _, event := range bought {
+		if event.Value.Sign() == 0 {
+			events = append(events, newEvent(event.PunkIndex, dia.NFTOrderFilled, "", event.Raw))
+			continue
+		}
+		events = append(events, newEvent(event.PunkIndex, dia.NFTOrderCancelled, event.ToAddress.Hex(), event.Raw))
+	}
+	return
+}
+
+// cryptoPunksBidID returns the order ID of the bid on @punkIndex.
+func cryptoPunksBidID(punkIndex *big.Int) string {
+	return "bid-" + punkIndex.String()
+}
+
 // GetDataChannel returns the scrapers data channel.
 func (scraper *CryptoPunksScraper) GetBidChannel() chan dia.NFTBid {
 	return scraper.bidScraper.chanBid
 }
 
+// GetOrderChannel returns the scrapers order event channel.
+func (scraper *CryptoPunksScraper) GetOrderChannel() chan dia.NFTOrderEvent {
+	return scraper.bidScraper.chanOrder
+}
+
 // closes all connected Scrapers. Must only be called from mainLoop
 func (scraper *CryptoPunksScraper) cleanup(err error) {
 	scraper.bidScraper.errorLock.Lock()
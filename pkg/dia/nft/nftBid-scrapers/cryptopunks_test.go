@@ -0,0 +1,43 @@
+package nftbidscrapers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/diadata-org/diadata/config/nftContracts/cryptopunk"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCryptoPunksBidEvents(t *testing.T) {
+	newEvent := func(punkIndex *big.Int, eventType string, maker string, raw types.Log) dia.NFTOrderEvent {
+		return dia.NFTOrderEvent{Type: eventType, OrderID: cryptoPunksBidID(punkIndex), Maker: maker}
+	}
+	buyer := common.HexToAddress("0xb")
+	bidder := common.HexToAddress("0xc")
+
+	withdrawn := []*cryptopunk.CryptoPunksMarketPunkBidWithdrawn{
+		{PunkIndex: big.NewInt(3), FromAddress: bidder},
+	}
+	bought := []*cryptopunk.CryptoPunksMarketPunkBought{
+		// The bid on punk 2 is accepted, the buyer of punk 1 is refunded a bid.
+		{PunkIndex: big.NewInt(1), Value: big.NewInt(1e18), ToAddress: buyer},
+		{PunkIndex: big.NewInt(2), Value: big.NewInt(0), ToAddress: bidder},
+	}
+
+	events := cryptoPunksBidEvents(withdrawn, bought, newEvent)
+	want := []dia.NFTOrderEvent{
+		{Type: dia.NFTOrderCancelled, OrderID: "bid-3", Maker: bidder.Hex()},
+		{Type: dia.NFTOrderCancelled, OrderID: "bid-1", Maker: buyer.Hex()},
+		{Type: dia.NFTOrderFilled, OrderID: "bid-2"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events were incorrect, got: %+v, want: %+v.", events, want)
+	}
+	for i := range want {
+		if events[i].Type != want[i].Type || events[i].OrderID != want[i].OrderID || events[i].Maker != want[i].Maker {
+			t.Errorf("event %d was incorrect, got: %+v, want: %+v.", i, events[i], want[i])
+		}
+	}
+}
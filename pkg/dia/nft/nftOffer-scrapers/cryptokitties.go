@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -45,6 +47,7 @@ func NewCryptokittiesScraper(rdb *models.RelDB) *CryptokittiesScraper {
 		ethConnection: connection,
 		datastore:     rdb,
 		chanOffer:     make(chan dia.NFTOffer),
+		chanOrder:     make(chan dia.NFTOrderEvent),
 	}
 	s := &CryptokittiesScraper{
 		contractAddress: common.HexToAddress("0xb1690C08E213a35Ed9bAb7B318DE14420FB57d8C"),
@@ -112,6 +115,7 @@ func (scraper *CryptokittiesScraper) FetchOffers() error {
 		log.Error("fetching cryptokitties nft class: ", err)
 	}
 
+	var events []dia.NFTOrderEvent
 	for {
 		iter, err := filterer.FilterAuctionCreated(&bind.FilterOpts{
 			Start: scraper.lastBlockNumber,
@@ -183,20 +187,95 @@ func (scraper *CryptokittiesScraper) FetchOffers() error {
 			}
 			fmt.Printf("got offer at time %v: %v\n", offer.Timestamp, offer)
 			scraper.GetOfferChannel() <- offer
+
+			events = append(events, dia.NFTOrderEvent{
+				NFT:           offer.NFT,
+				Side:          dia.NFTOrderSideOffer,
+				Type:          dia.NFTOrderPlaced,
+				OrderID:       cryptoKittiesAuctionID(iter.Event.TokenId),
+				Maker:         offer.FromAddress,
+				Price:         iter.Event.StartingPrice,
+				EndPrice:      iter.Event.EndingPrice,
+				Duration:      time.Duration(iter.Event.Duration.Int64()) * time.Second,
+				Currency:      etherCurrency,
+				BlockNumber:   offer.BlockNumber,
+				BlockPosition: offer.BlockPosition,
+				Timestamp:     offer.Timestamp,
+				TxHash:        offer.TxHash,
+				Exchange:      offer.Exchange,
+			})
 		}
 		break
 	}
 
+	lifecycleEvents, err := scraper.fetchAuctionLifecycle(filterer, nftclass, endBlockNumber)
+	if err != nil {
+		return err
+	}
+	sendOrderEvents(append(events, lifecycleEvents...), scraper.GetOrderChannel())
+
 	// Update the last lastBlockNumber value.
 	scraper.lastBlockNumber = endBlockNumber
 	return nil
 }
 
+// fetchAuctionLifecycle returns the successful and cancelled auctions up to @endBlockNumber.
+func (scraper *CryptokittiesScraper) fetchAuctionLifecycle(filterer *cryptokitties.SaleClockAuctionFilterer, nftclass dia.NFTClass, endBlockNumber uint64) (events []dia.NFTOrderEvent, err error) {
+	opts := &bind.FilterOpts{
+		Start: scraper.lastBlockNumber,
+		End:   &endBlockNumber,
+	}
+	newEvent := func(tokenID *big.Int, eventType string, raw types.Log) dia.NFTOrderEvent {
+		timestamp, err := ethhelper.GetBlockTimeEth(int64(raw.BlockNumber), scraper.offerScraper.datastore, scraper.offerScraper.ethConnection)
+		if err != nil {
+			log.Errorf("getting block time: %+v", err)
+		}
+		return dia.NFTOrderEvent{
+			NFT:           dia.NFT{NFTClass: nftclass, TokenID: tokenID.String()},
+			Side:          dia.NFTOrderSideOffer,
+			Type:          eventType,
+			OrderID:       cryptoKittiesAuctionID(tokenID),
+			BlockNumber:   raw.BlockNumber,
+			BlockPosition: uint64(raw.Index),
+			Timestamp:     timestamp,
+			TxHash:        raw.TxHash.Hex(),
+			Exchange:      "CrypoKittiesMarket",
+		}
+	}
+
+	iterSuccessful, err := filterer.FilterAuctionSuccessful(opts)
+	if err != nil {
+		return
+	}
+	for iterSuccessful.Next() {
+		events = append(events, newEvent(iterSuccessful.Event.TokenId, dia.NFTOrderFilled, iterSuccessful.Event.Raw))
+	}
+
+	iterCancelled, err := filterer.FilterAuctionCancelled(opts)
+	if err != nil {
+		return
+	}
+	for iterCancelled.Next() {
+		events = append(events, newEvent(iterCancelled.Event.TokenId, dia.NFTOrderCancelled, iterCancelled.Event.Raw))
+	}
+	return
+}
+
+// cryptoKittiesAuctionID returns the order ID of the sale auction of @tokenID.
+func cryptoKittiesAuctionID(tokenID *big.Int) string {
+	return "auction-" + tokenID.String()
+}
+
 // GetDataChannel returns the scrapers data channel.
 func (scraper *CryptokittiesScraper) GetOfferChannel() chan dia.NFTOffer {
 	return scraper.offerScraper.chanOffer
 }
 
+// GetOrderChannel returns the scrapers order event channel.
+func (scraper *CryptokittiesScraper) GetOrderChannel() chan dia.NFTOrderEvent {
+	return scraper.offerScraper.chanOrder
+}
+
 // closes all connected Scrapers. Must only be called from mainLoop
 func (scraper *CryptokittiesScraper) cleanup(err error) {
 	scraper.offerScraper.errorLock.Lock()
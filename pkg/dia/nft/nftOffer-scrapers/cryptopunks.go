@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -43,6 +45,7 @@ func NewCryptoPunksScraper(rdb *models.RelDB) *CryptoPunksScraper {
 		ethConnection: connection,
 		datastore:     rdb,
 		chanOffer:     make(chan dia.NFTOffer),
+		chanOrder:     make(chan dia.NFTOrderEvent),
 	}
 	s := &CryptoPunksScraper{
 		contractAddress: common.HexToAddress("0xb47e3cd837dDF8e4c57F05d70Ab865de6e193BBB"),
@@ -114,6 +117,7 @@ func (scraper *CryptoPunksScraper) FetchOffers() error {
 		log.Error("fetching cryptopunks nft class: ", err)
 	}
 
+	var events []dia.NFTOrderEvent
 	for {
 		iter, err := filterer.FilterPunkOffered(&bind.FilterOpts{
 			Start: scraper.lastBlockNumber,
@@ -176,20 +180,118 @@ func (scraper *CryptoPunksScraper) FetchOffers() error {
 			log.Infof("got offer for id %v from address %s at time %v with startValue %v ", offer.NFT.TokenID, offer.FromAddress, offer.Timestamp, offer.StartValue)
 
 			scraper.GetOfferChannel() <- offer
+
+			events = append(events, dia.NFTOrderEvent{
+				NFT:           offer.NFT,
+				Side:          dia.NFTOrderSideOffer,
+				Type:          dia.NFTOrderPlaced,
+				OrderID:       cryptoPunksOfferID(iter.Event.PunkIndex),
+				Maker:         offer.FromAddress,
+				Price:         offer.StartValue,
+				Currency:      etherCurrency,
+				BlockNumber:   offer.BlockNumber,
+				BlockPosition: offer.BlockPosition,
+				Timestamp:     offer.Timestamp,
+				TxHash:        offer.TxHash,
+				Exchange:      offer.Exchange,
+			})
 		}
 		break
 	}
 
+	lifecycleEvents, err := scraper.fetchOfferLifecycle(filterer, nftclass, endBlockNumber)
+	if err != nil {
+		return err
+	}
+	sendOrderEvents(append(events, lifecycleEvents...), scraper.GetOrderChannel())
+
 	// Update the last lastBlockNumber value.
 	scraper.lastBlockNumber = endBlockNumber
 	return nil
 }
 
+// fetchOfferLifecycle returns the purchases and withdrawals of offers up to @endBlockNumber.
+func (scraper *CryptoPunksScraper) fetchOfferLifecycle(filterer *cryptopunk.CryptoPunksMarketFilterer, nftclass dia.NFTClass, endBlockNumber uint64) (events []dia.NFTOrderEvent, err error) {
+	opts := &bind.FilterOpts{
+		Start: scraper.lastBlockNumber,
+		End:   &endBlockNumber,
+	}
+	newEvent := func(punkIndex *big.Int, eventType string, raw types.Log) dia.NFTOrderEvent {
+		timestamp, err := ethhelper.GetBlockTimeEth(int64(raw.BlockNumber), scraper.offerScraper.datastore, scraper.offerScraper.ethConnection)
+		if err != nil {
+			log.Errorf("getting block time: %+v", err)
+		}
+		return dia.NFTOrderEvent{
+			NFT:           dia.NFT{NFTClass: nftclass, TokenID: punkIndex.String()},
+			Side:          dia.NFTOrderSideOffer,
+			Type:          eventType,
+			OrderID:       cryptoPunksOfferID(punkIndex),
+			BlockNumber:   raw.BlockNumber,
+			BlockPosition: uint64(raw.Index),
+			Timestamp:     timestamp,
+			TxHash:        raw.TxHash.Hex(),
+			Exchange:      "CrypoPunksMarket",
+		}
+	}
+
+	iterBought, err := filterer.FilterPunkBought(opts, nil, nil, nil)
+	if err != nil {
+		return
+	}
+	var bought []*cryptopunk.CryptoPunksMarketPunkBought
+	for iterBought.Next() {
+		bought = append(bought, iterBought.Event)
+	}
+
+	iterWithdrawn, err := filterer.FilterPunkNoLongerForSale(opts, nil)
+	if err != nil {
+		return
+	}
+	var withdrawn []*cryptopunk.CryptoPunksMarketPunkNoLongerForSale
+	for iterWithdrawn.Next() {
+		withdrawn = append(withdrawn, iterWithdrawn.Event)
+	}
+	return cryptoPunksOfferEvents(bought, withdrawn, newEvent), nil
+}
+
+// cryptoPunksOfferEvents returns the order events of the offers changed by the purchases @bought and the withdrawals
+// @withdrawn, created by @newEvent. A purchase withdraws the offer before emitting PunkBought, so the withdrawal
+// is not a cancellation. A purchase with zero value is an accepted bid, which resets the offer of the punk without
+// emitting PunkNoLongerForSale and is therefore a cancellation of the offer. The bid itself is filled by the bid scraper.
+func cryptoPunksOfferEvents(bought []*cryptopunk.CryptoPunksMarketPunkBought, withdrawn []*cryptopunk.CryptoPunksMarketPunkNoLongerForSale, newEvent func(punkIndex *big.Int, eventType string, raw types.Log) dia.NFTOrderEvent) (events []dia.NFTOrderEvent) {
+	filled := make(map[string]bool)
+	for _, event := range bought {
+		if event.Value.Sign() == 0 {
+			events = append(events, newEvent(event.PunkIndex, dia.NFTOrderCancelled, event.Raw))
+			continue
+		}
+		filled[event.Raw.TxHash.Hex()+"-"+event.PunkIndex.String()] = true
+		events = append(events, newEvent(event.PunkIndex, dia.NFTOrderFilled, event.Raw))
+	}
+	for _, event := range withdrawn {
+		if filled[event.Raw.TxHash.Hex()+"-"+event.PunkIndex.String()] {
+			continue
+		}
+		events = append(events, newEvent(event.PunkIndex, dia.NFTOrderCancelled, event.Raw))
+	}
+	return
+}
+
+// cryptoPunksOfferID returns the order ID of the offer of @punkIndex.
+func cryptoPunksOfferID(punkIndex *big.Int) string {
+	return "offer-" + punkIndex.String()
+}
+
 // GetDataChannel returns the scrapers data channel.
 func (scraper *CryptoPunksScraper) GetOfferChannel() chan dia.NFTOffer {
 	return scraper.offerScraper.chanOffer
 }
 
+// GetOrderChannel returns the scrapers order event channel.
+func (scraper *CryptoPunksScraper) GetOrderChannel() chan dia.NFTOrderEvent {
+	return scraper.offerScraper.chanOrder
+}
+
 // closes all connected Scrapers. Must only be called from mainLoop
 func (scraper *CryptoPunksScraper) cleanup(err error) {
 	scraper.offerScraper.errorLock.Lock()
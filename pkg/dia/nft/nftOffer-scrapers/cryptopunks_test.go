@@ -0,0 +1,45 @@
+package nftofferscrapers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/diadata-org/diadata/config/nftContracts/cryptopunk"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCryptoPunksOfferEvents(t *testing.T) {
+	newEvent := func(punkIndex *big.Int, eventType string, raw types.Log) dia.NFTOrderEvent {
+		return dia.NFTOrderEvent{Type: eventType, OrderID: cryptoPunksOfferID(punkIndex), TxHash: raw.TxHash.Hex()}
+	}
+	buyTx := types.Log{TxHash: common.HexToHash("0x1")}
+	acceptTx := types.Log{TxHash: common.HexToHash("0x2")}
+	withdrawTx := types.Log{TxHash: common.HexToHash("0x3")}
+
+	bought := []*cryptopunk.CryptoPunksMarketPunkBought{
+		// Punk 1 is bought from its offer, punk 2 is sold by accepting a bid.
+		{PunkIndex: big.NewInt(1), Value: big.NewInt(1e18), Raw: buyTx},
+		{PunkIndex: big.NewInt(2), Value: big.NewInt(0), Raw: acceptTx},
+	}
+	withdrawn := []*cryptopunk.CryptoPunksMarketPunkNoLongerForSale{
+		{PunkIndex: big.NewInt(1), Raw: buyTx},
+		{PunkIndex: big.NewInt(3), Raw: withdrawTx},
+	}
+
+	events := cryptoPunksOfferEvents(bought, withdrawn, newEvent)
+	want := []dia.NFTOrderEvent{
+		{Type: dia.NFTOrderFilled, OrderID: "offer-1", TxHash: buyTx.TxHash.Hex()},
+		{Type: dia.NFTOrderCancelled, OrderID: "offer-2", TxHash: acceptTx.TxHash.Hex()},
+		{Type: dia.NFTOrderCancelled, OrderID: "offer-3", TxHash: withdrawTx.TxHash.Hex()},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events were incorrect, got: %+v, want: %+v.", events, want)
+	}
+	for i := range want {
+		if events[i].Type != want[i].Type || events[i].OrderID != want[i].OrderID || events[i].TxHash != want[i].TxHash {
+			t.Errorf("event %d was incorrect, got: %+v, want: %+v.", i, events[i], want[i])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package nftofferscrapers
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/diadata-org/diadata/pkg/dia"
@@ -13,6 +14,9 @@ type nothing struct{}
 type NFTOfferScraper interface {
 	// NFT bids should be streamed through dia.NFTBid channel.
 	GetOfferChannel() chan dia.NFTOffer
+	// Lifecycle events of the offers, i.e. placements, fills and cancellations,
+	// should be streamed through dia.NFTOrderEvent channel in the order they occurred on chain.
+	GetOrderChannel() chan dia.NFTOrderEvent
 	// Should fetch bids and send them to the channel.
 	FetchOffers() error
 }
@@ -30,4 +34,27 @@ type OfferScraper struct {
 	ethConnection *ethclient.Client
 	datastore     *models.RelDB
 	chanOffer     chan dia.NFTOffer
+	chanOrder     chan dia.NFTOrderEvent
+}
+
+// etherCurrency is the currency of orders placed in Ether.
+var etherCurrency = dia.Asset{
+	Symbol:     "ETH",
+	Name:       "Ether",
+	Address:    "0x0000000000000000000000000000000000000000",
+	Decimals:   18,
+	Blockchain: dia.ETHEREUM,
+}
+
+// sendOrderEvents sends @events to @chanOrder ordered by their position on chain.
+func sendOrderEvents(events []dia.NFTOrderEvent, chanOrder chan dia.NFTOrderEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].BlockPosition < events[j].BlockPosition
+	})
+	for _, event := range events {
+		chanOrder <- event
+	}
 }
@@ -0,0 +1,359 @@
+package nftoracle
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/oraclehelper"
+	restClient "github.com/diadata-org/diadata/pkg/http/restClient"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// nftOracleConfigDir is the folder in the config directory holding the feeder configs.
+	nftOracleConfigDir = "nftOracle/"
+
+	// ValueDecimals is the number of decimals of the prices and the confidence written to the oracle.
+	ValueDecimals = 8
+
+	ReasonInitial   = oraclehelper.ReasonInitial
+	ReasonHeartbeat = oraclehelper.ReasonHeartbeat
+	ReasonDeviation = oraclehelper.ReasonDeviation
+	// ReasonCurrencyChange is the reason of an update whose floor is in another currency than the last pushed update.
+	ReasonCurrencyChange = "currency"
+)
+
+var log = logrus.New()
+
+// ErrInvalidSignature is returned by VerifyUpdate if an update was not signed by its signer.
+var ErrInvalidSignature = errors.New("invalid update signature")
+
+// ErrTxFailed is returned if the transaction of an update was mined but reverted.
+var ErrTxFailed = oraclehelper.ErrTxFailed
+
+// ErrCurrencyMismatch is returned if the floor of a collection is not in the currency of its moving average,
+// as the oracle values carry no currency.
+var ErrCurrencyMismatch = errors.New("floor currency mismatch")
+
+// Config lists the collections pushed by a Feeder. The update thresholds of a collection default to the ones of the config.
+type Config struct {
+	Collections []Collection `json:"collections"`
+	// An update is pushed if the floor or the moving average floor deviates by more than DeviationPermille
+	// from the last pushed value, or if the last update is older than HeartbeatSeconds.
+	DeviationPermille int   `json:"deviation_permille"`
+	HeartbeatSeconds  int64 `json:"heartbeat_seconds"`
+	// FloorWindowSeconds is the window of sales the floor is computed from, MALookbackSeconds the period
+	// of the moving average. Zero values use the defaults of the API.
+	FloorWindowSeconds int64 `json:"floor_window_seconds"`
+	MALookbackSeconds  int64 `json:"ma_lookback_seconds"`
+}
+
+// Collection is an nft collection whose floor is stored under Key in the oracle contract.
+type Collection struct {
+	Key               string `json:"key"`
+	Blockchain        string `json:"blockchain"`
+	Address           string `json:"address"`
+	DeviationPermille int    `json:"deviation_permille"`
+	HeartbeatSeconds  int64  `json:"heartbeat_seconds"`
+}
+
+// FloorSource provides the floor statistics of collections, e.g. the DIA REST API.
+type FloorSource interface {
+	NFTFloor(ctx context.Context, blockchain, address string, timestamp time.Time, floorWindow time.Duration, quantile float64) (restClient.NFTFloor, error)
+	NFTFloorMA(ctx context.Context, blockchain, address string, lookback, floorWindow time.Duration) (restClient.NFTFloorMA, error)
+}
+
+// Oracle is the setter of the nft oracle contract, see diaNFTOracleService.DIANFTOracleTransactor.
+type Oracle interface {
+	SetValue(opts *bind.TransactOpts, key string, value0 uint64, value1 uint64, value2 uint64, value3 uint64, value4 uint64, timestamp uint64) (*types.Transaction, error)
+}
+
+// AuditStore keeps the record of pushed updates. GetLastNFTOracleUpdate returns the last confirmed update.
+type AuditStore interface {
+	SetNFTOracleUpdate(update models.NFTOracleUpdate) error
+	SetNFTOracleUpdateStatus(oracle string, chainID int64, txHash string, status string) error
+	GetLastNFTOracleUpdate(oracle string, chainID int64, key string) (models.NFTOracleUpdate, error)
+}
+
+// Target is the oracle contract at Address on the chain with ChainID updated by a Feeder.
+// Backend provides the receipts of the update transactions.
+type Target struct {
+	Contract Oracle
+	Backend  bind.DeployBackend
+	Address  common.Address
+	ChainID  int64
+}
+
+// Feeder pushes the floor prices of the collections in its config to an nft oracle contract.
+// Each update is signed by the feeder's key and recorded as pending in the audit store once its transaction
+// is sent. It is considered pushed once its transaction succeeded and it is recorded as confirmed.
+type Feeder struct {
+	conf   Config
+	source FloorSource
+	target Target
+	store  AuditStore
+	key    *ecdsa.PrivateKey
+	auth   *bind.TransactOpts
+
+	// last holds the last pushed update by key, nil if there is none.
+	last map[string]*models.NFTOracleUpdate
+}
+
+// LoadConfig reads the feeder config @name from the config directory.
+func LoadConfig(name string) (conf Config, err error) {
+	data, err := ioutil.ReadFile(configCollectors.ConfigFileConnectors(nftOracleConfigDir+name, ".json"))
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	if len(conf.Collections) == 0 {
+		err = fmt.Errorf("no collections in config %s", name)
+		return
+	}
+	for _, c := range conf.Collections {
+		if c.Key == "" || c.Address == "" || c.Blockchain == "" {
+			err = fmt.Errorf("collection of %s needs a key, a blockchain and an address", name)
+			return
+		}
+	}
+	return
+}
+
+// NewFeeder returns a feeder pushing the collections in @conf to @target with transactions and signatures of @key.
+func NewFeeder(conf Config, source FloorSource, target Target, store AuditStore, key *ecdsa.PrivateKey) (*Feeder, error) {
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(target.ChainID))
+	if err != nil {
+		return nil, err
+	}
+	return &Feeder{
+		conf:   conf,
+		source: source,
+		target: target,
+		store:  store,
+		key:    key,
+		auth:   auth,
+		last:   make(map[string]*models.NFTOracleUpdate),
+	}, nil
+}
+
+// Update pushes the floor of each collection whose update is due at @timestamp and returns the pushed updates.
+// A failing collection does not stop the others, the last error is returned.
+func (f *Feeder) Update(ctx context.Context, timestamp time.Time) (updates []models.NFTOracleUpdate, err error) {
+	for _, c := range f.conf.Collections {
+		update, collectionErr := f.updateCollection(ctx, c, timestamp)
+		if collectionErr != nil {
+			log.Errorf("update %s: %v", c.Key, collectionErr)
+			err = collectionErr
+			continue
+		}
+		if update != nil {
+			updates = append(updates, *update)
+		}
+	}
+	return
+}
+
+// updateCollection pushes the floor of @c if its update is due at @timestamp. It returns nil if no update was pushed.
+func (f *Feeder) updateCollection(ctx context.Context, c Collection, timestamp time.Time) (*models.NFTOracleUpdate, error) {
+	last, err := f.lastUpdate(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	floorWindow := time.Duration(f.conf.FloorWindowSeconds) * time.Second
+	floor, err := f.source.NFTFloor(ctx, c.Blockchain, c.Address, timestamp, floorWindow, 0)
+	if err != nil {
+		return nil, err
+	}
+	if floor.Floor <= 0 {
+		log.Warnf("no floor for %s at %v, skip update", c.Key, timestamp)
+		return nil, nil
+	}
+	floorMA, err := f.source.NFTFloorMA(ctx, c.Blockchain, c.Address, time.Duration(f.conf.MALookbackSeconds)*time.Second, floorWindow)
+	if err != nil {
+		return nil, err
+	}
+	if floorMA.Currency != floor.Currency {
+		return nil, fmt.Errorf("%w: floor in %s, moving average in %s", ErrCurrencyMismatch, floor.Currency, floorMA.Currency)
+	}
+
+	update := models.NFTOracleUpdate{
+		Oracle:     f.target.Address.Hex(),
+		ChainID:    f.target.ChainID,
+		Key:        c.Key,
+		NFTClass:   dia.NFTClass{Address: c.Address, Blockchain: c.Blockchain},
+		Floor:      floor.Floor,
+		FloorMA:    floorMA.Floor,
+		FloorUSD:   floor.FloorUSD,
+		Currency:   floor.Currency,
+		Confidence: floor.Confidence.Score,
+		NumTrades:  floor.Confidence.NumTrades,
+		ValueTime:  timestamp,
+		Time:       timestamp,
+	}
+	update.Values = EncodeValues(update)
+
+	deviationPermille, heartbeat := c.DeviationPermille, c.HeartbeatSeconds
+	if deviationPermille == 0 {
+		deviationPermille = f.conf.DeviationPermille
+	}
+	if heartbeat == 0 {
+		heartbeat = f.conf.HeartbeatSeconds
+	}
+	update.Reason = UpdateReason(last, update, deviationPermille, time.Duration(heartbeat)*time.Second)
+	// Deviations from values in another currency are meaningless, so a change of the floor currency is pushed at once.
+	if last != nil && last.Currency != "" && last.Currency != update.Currency {
+		log.Warnf("floor currency of %s changed from %s to %s", c.Key, last.Currency, update.Currency)
+		update.Reason = ReasonCurrencyChange
+	}
+	if update.Reason == "" {
+		return nil, nil
+	}
+
+	if err = SignUpdate(&update, f.key); err != nil {
+		return nil, err
+	}
+	tx, err := f.target.Contract.SetValue(oraclehelper.TransactOpts(ctx, f.auth), update.Key, update.Values[0], update.Values[1], update.Values[2], update.Values[3], update.Values[4], uint64(update.ValueTime.Unix()))
+	if err != nil {
+		return nil, err
+	}
+	update.TxHash = tx.Hash().Hex()
+
+	// An update which is not recorded is not marked as pushed either, such that it is pushed and recorded again.
+	update.Status = models.NFTOracleUpdatePending
+	if err = f.store.SetNFTOracleUpdate(update); err != nil {
+		return nil, fmt.Errorf("record update with tx %s: %w", update.TxHash, err)
+	}
+	if err = oraclehelper.WaitMined(ctx, f.target.Backend, tx); err != nil {
+		// The update stays pending if the outcome of its transaction is unknown.
+		if errors.Is(err, ErrTxFailed) {
+			if statusErr := f.setStatus(update, models.NFTOracleUpdateFailed); statusErr != nil {
+				log.Error(statusErr)
+			}
+		}
+		return nil, err
+	}
+	update.Status = models.NFTOracleUpdateConfirmed
+	if err = f.setStatus(update, update.Status); err != nil {
+		return nil, err
+	}
+	log.Infof("pushed %s (%s): floor %v %s, moving average %v, tx %s", update.Key, update.Reason, update.Floor, update.Currency, update.FloorMA, update.TxHash)
+	f.last[c.Key] = &update
+	return &update, nil
+}
+
+// setStatus records @status for the audit record of @update.
+func (f *Feeder) setStatus(update models.NFTOracleUpdate, status string) error {
+	if err := f.store.SetNFTOracleUpdateStatus(update.Oracle, update.ChainID, update.TxHash, status); err != nil {
+		return fmt.Errorf("record %s update with tx %s: %w", status, update.TxHash, err)
+	}
+	return nil
+}
+
+// lastUpdate returns the last update of @key, read from the audit store on first use.
+func (f *Feeder) lastUpdate(key string) (*models.NFTOracleUpdate, error) {
+	if last, ok := f.last[key]; ok {
+		return last, nil
+	}
+	last, err := f.store.GetLastNFTOracleUpdate(f.target.Address.Hex(), f.target.ChainID, key)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		f.last[key] = nil
+		return nil, nil
+	}
+	f.last[key] = &last
+	return &last, nil
+}
+
+// EncodeValues returns the values written to the oracle for @update: the floor, the moving average floor,
+// the floor in USD and the confidence score with ValueDecimals decimals, and the number of sales in the floor window.
+func EncodeValues(update models.NFTOracleUpdate) [5]uint64 {
+	return [5]uint64{
+		scaleValue(update.Floor),
+		scaleValue(update.FloorMA),
+		scaleValue(update.FloorUSD),
+		scaleValue(update.Confidence),
+		uint64(update.NumTrades),
+	}
+}
+
+func scaleValue(value float64) uint64 {
+	if value <= 0 {
+		return 0
+	}
+	return uint64(math.Round(value * math.Pow10(ValueDecimals)))
+}
+
+// UpdateReason returns why @update should be pushed given the @last pushed update, or an empty string
+// if it is not due yet.
+func UpdateReason(last *models.NFTOracleUpdate, update models.NFTOracleUpdate, deviationPermille int, heartbeat time.Duration) string {
+	if last == nil {
+		return ReasonInitial
+	}
+	return oraclehelper.UpdateReason(last.Time, update.Time, heartbeat, func() bool {
+		return oraclehelper.Deviates(last.Floor, update.Floor, deviationPermille, 0) || oraclehelper.Deviates(last.FloorMA, update.FloorMA, deviationPermille, 0)
+	})
+}
+
+// UpdateDigest returns the EIP-191 hash of the values of @update. It covers chain, oracle and key, such that
+// a signature cannot be replayed for another oracle, and is the hash of abi.encodePacked(uint256 chainID,
+// address oracle, string key, uint64 value0, ..., uint64 value4, uint64 timestamp).
+func UpdateDigest(update models.NFTOracleUpdate) []byte {
+	data := common.LeftPadBytes(big.NewInt(update.ChainID).Bytes(), 32)
+	data = append(data, common.HexToAddress(update.Oracle).Bytes()...)
+	data = append(data, []byte(update.Key)...)
+	word := make([]byte, 8)
+	for _, value := range append(update.Values[:], uint64(update.ValueTime.Unix())) {
+		binary.BigEndian.PutUint64(word, value)
+		data = append(data, word...)
+	}
+	return accounts.TextHash(crypto.Keccak256(data))
+}
+
+// SignUpdate sets signer and signature of @update. The signature has recovery id 27 or 28 as expected by ecrecover.
+func SignUpdate(update *models.NFTOracleUpdate, key *ecdsa.PrivateKey) error {
+	signature, err := crypto.Sign(UpdateDigest(*update), key)
+	if err != nil {
+		return err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	update.Signer = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	update.Signature = hexutil.Encode(signature)
+	return nil
+}
+
+// VerifyUpdate returns ErrInvalidSignature if the signature of @update was not made by its signer over its values.
+func VerifyUpdate(update models.NFTOracleUpdate) error {
+	signature, err := hexutil.Decode(update.Signature)
+	if err != nil || len(signature) != crypto.SignatureLength || signature[crypto.RecoveryIDOffset] < 27 {
+		return ErrInvalidSignature
+	}
+	signature[crypto.RecoveryIDOffset] -= 27
+	pubKey, err := crypto.SigToPub(UpdateDigest(update), signature)
+	if err != nil || crypto.PubkeyToAddress(*pubKey) != common.HexToAddress(update.Signer) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
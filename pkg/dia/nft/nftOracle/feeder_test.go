@@ -0,0 +1,262 @@
+package nftoracle
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	restClient "github.com/diadata-org/diadata/pkg/http/restClient"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+)
+
+type testFloorSource struct {
+	floor      float64
+	floorMA    float64
+	currency   string
+	currencyMA string
+}
+
+func (s *testFloorSource) NFTFloor(ctx context.Context, blockchain, address string, timestamp time.Time, floorWindow time.Duration, quantile float64) (floor restClient.NFTFloor, err error) {
+	floor.Floor = s.floor
+	floor.FloorUSD = 3000 * s.floor
+	floor.Currency = "ETH"
+	if s.currency != "" {
+		floor.Currency = s.currency
+	}
+	floor.Confidence.Score = 0.75
+	floor.Confidence.NumTrades = 12
+	return
+}
+
+func (s *testFloorSource) NFTFloorMA(ctx context.Context, blockchain, address string, lookback, floorWindow time.Duration) (floor restClient.NFTFloorMA, err error) {
+	floor.Floor = s.floorMA
+	floor.Currency = "ETH"
+	if s.currencyMA != "" {
+		floor.Currency = s.currencyMA
+	}
+	return
+}
+
+type testOracle struct {
+	calls    [][6]uint64
+	nonce    uint64
+	failed   bool
+	reverted bool
+	// receipts are the receipts of the sent transactions by hash.
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (o *testOracle) SetValue(opts *bind.TransactOpts, key string, value0 uint64, value1 uint64, value2 uint64, value3 uint64, value4 uint64, timestamp uint64) (*types.Transaction, error) {
+	if o.failed {
+		return nil, errors.New("transaction failed")
+	}
+	o.calls = append(o.calls, [6]uint64{value0, value1, value2, value3, value4, timestamp})
+	o.nonce++
+	tx := types.NewTransaction(o.nonce, common.Address{}, big.NewInt(0), 100000, big.NewInt(1), nil)
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(o.nonce)}
+	if o.reverted {
+		receipt.Status = types.ReceiptStatusFailed
+	}
+	if o.receipts == nil {
+		o.receipts = make(map[common.Hash]*types.Receipt)
+	}
+	o.receipts[tx.Hash()] = receipt
+	return tx, nil
+}
+
+func (o *testOracle) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := o.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (o *testOracle) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+type testAuditStore struct {
+	updates []models.NFTOracleUpdate
+	failed  bool
+}
+
+func (s *testAuditStore) SetNFTOracleUpdate(update models.NFTOracleUpdate) error {
+	if s.failed {
+		return errors.New("store failed")
+	}
+	s.updates = append(s.updates, update)
+	return nil
+}
+
+func (s *testAuditStore) SetNFTOracleUpdateStatus(oracle string, chainID int64, txHash string, status string) error {
+	for i := range s.updates {
+		if s.updates[i].Oracle == oracle && s.updates[i].ChainID == chainID && s.updates[i].TxHash == txHash {
+			s.updates[i].Status = status
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+func (s *testAuditStore) GetLastNFTOracleUpdate(oracle string, chainID int64, key string) (models.NFTOracleUpdate, error) {
+	for i := len(s.updates) - 1; i >= 0; i-- {
+		if s.updates[i].Oracle == oracle && s.updates[i].ChainID == chainID && s.updates[i].Key == key && s.updates[i].Status == models.NFTOracleUpdateConfirmed {
+			return s.updates[i], nil
+		}
+	}
+	return models.NFTOracleUpdate{}, pgx.ErrNoRows
+}
+
+func TestFeederUpdate(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := Config{
+		Collections:       []Collection{{Key: "CryptoPunks", Blockchain: "Ethereum", Address: "0xb47e3cd837dDF8e4c57F05d70Ab865de6e193BBB"}},
+		DeviationPermille: 10,
+		HeartbeatSeconds:  3600,
+	}
+	source := &testFloorSource{floor: 60, floorMA: 65}
+	oracle := &testOracle{}
+	store := &testAuditStore{}
+	target := Target{Contract: oracle, Backend: oracle, Address: common.HexToAddress("0x42"), ChainID: 1}
+	feeder, err := NewFeeder(conf, source, target, store, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1640995200, 0)
+
+	for _, c := range []struct {
+		name       string
+		floor      float64
+		floorMA    float64
+		elapsed    time.Duration
+		wantReason string
+	}{
+		{"initial", 60, 65, 0, ReasonInitial},
+		{"unchanged", 60, 65, time.Minute, ""},
+		{"small deviation", 60.5, 65, 2 * time.Minute, ""},
+		{"deviation of floor", 61, 65, 3 * time.Minute, ReasonDeviation},
+		{"deviation of moving average", 61, 64, 4 * time.Minute, ReasonDeviation},
+		{"heartbeat", 61, 64, 64 * time.Minute, ReasonHeartbeat},
+		{"no floor", 0, 64, 200 * time.Minute, ""},
+	} {
+		source.floor, source.floorMA = c.floor, c.floorMA
+		numCalls := len(oracle.calls)
+		updates, err := feeder.Update(context.Background(), start.Add(c.elapsed))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if c.wantReason == "" {
+			if len(updates) != 0 || len(oracle.calls) != numCalls {
+				t.Errorf("%s: unexpected update %+v.", c.name, updates)
+			}
+			continue
+		}
+		if len(updates) != 1 || updates[0].Reason != c.wantReason {
+			t.Errorf("%s: updates were incorrect, got: %+v, want reason: %v.", c.name, updates, c.wantReason)
+			continue
+		}
+		call := oracle.calls[len(oracle.calls)-1]
+		if call[0] != uint64(c.floor*1e8) || call[1] != uint64(c.floorMA*1e8) || call[3] != 75000000 || call[4] != 12 || call[5] != uint64(start.Add(c.elapsed).Unix()) {
+			t.Errorf("%s: oracle values were incorrect, got: %v.", c.name, call)
+		}
+	}
+
+	if len(store.updates) != 4 {
+		t.Fatalf("number of audit records was incorrect, got: %v, want: %v.", len(store.updates), 4)
+	}
+	record := store.updates[0]
+	if record.TxHash == "" || record.Currency != "ETH" || record.Status != models.NFTOracleUpdateConfirmed || record.Signer != crypto.PubkeyToAddress(key.PublicKey).Hex() || record.Oracle != target.Address.Hex() {
+		t.Errorf("audit record was incorrect, got: %+v.", record)
+	}
+
+	// A restarted feeder continues from the audit store.
+	restarted, err := NewFeeder(conf, source, target, store, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updates, err := restarted.Update(context.Background(), start.Add(65*time.Minute)); err != nil || len(updates) != 0 {
+		t.Errorf("restarted feeder pushed update, got: %+v %v.", updates, err)
+	}
+
+	oracle.failed = true
+	source.floor = 100
+	if _, err := feeder.Update(context.Background(), start.Add(70*time.Minute)); err == nil || len(store.updates) != 4 {
+		t.Errorf("failed transaction was incorrect, got: %v %v.", err, len(store.updates))
+	}
+
+	// Neither a reverted transaction nor an unrecorded update count as pushed, so the deviation is pushed again.
+	// The reverted transaction is recorded as failed.
+	oracle.failed, oracle.reverted = false, true
+	if _, err := feeder.Update(context.Background(), start.Add(71*time.Minute)); !errors.Is(err, ErrTxFailed) || len(store.updates) != 5 || store.updates[4].Status != models.NFTOracleUpdateFailed {
+		t.Errorf("reverted transaction was incorrect, got: %v %+v.", err, store.updates)
+	}
+	oracle.reverted, store.failed = false, true
+	if _, err := feeder.Update(context.Background(), start.Add(72*time.Minute)); err == nil {
+		t.Error("failed audit record was not returned")
+	}
+	store.failed = false
+	if updates, err := feeder.Update(context.Background(), start.Add(73*time.Minute)); err != nil || len(updates) != 1 || updates[0].Reason != ReasonDeviation || len(store.updates) != 6 {
+		t.Errorf("update after failures was incorrect, got: %+v %v.", updates, err)
+	}
+
+	source.currencyMA = "WETH"
+	if _, err := feeder.Update(context.Background(), start.Add(74*time.Minute)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("currency mismatch was incorrect, got: %v, want: %v.", err, ErrCurrencyMismatch)
+	}
+
+	// A change of the floor currency is pushed without deviation.
+	source.currency = "WETH"
+	if updates, err := feeder.Update(context.Background(), start.Add(75*time.Minute)); err != nil || len(updates) != 1 || updates[0].Reason != ReasonCurrencyChange || updates[0].Currency != "WETH" {
+		t.Errorf("currency change was incorrect, got: %+v %v.", updates, err)
+	}
+	if updates, err := feeder.Update(context.Background(), start.Add(76*time.Minute)); err != nil || len(updates) != 0 {
+		t.Errorf("update after currency change was incorrect, got: %+v %v.", updates, err)
+	}
+}
+
+func TestVerifyUpdate(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := models.NFTOracleUpdate{
+		Oracle:    "0x0000000000000000000000000000000000000042",
+		ChainID:   1,
+		Key:       "CryptoPunks",
+		Floor:     60,
+		ValueTime: time.Unix(1640995200, 0),
+	}
+	update.Values = EncodeValues(update)
+	if err := SignUpdate(&update, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyUpdate(update); err != nil {
+		t.Errorf("signature was invalid: %v", err)
+	}
+
+	for name, tamper := range map[string]func(*models.NFTOracleUpdate){
+		"value":     func(u *models.NFTOracleUpdate) { u.Values[0]++ },
+		"timestamp": func(u *models.NFTOracleUpdate) { u.ValueTime = u.ValueTime.Add(time.Second) },
+		"chain":     func(u *models.NFTOracleUpdate) { u.ChainID = 137 },
+		"key":       func(u *models.NFTOracleUpdate) { u.Key = "BAYC" },
+		"signer":    func(u *models.NFTOracleUpdate) { u.Signer = "0x0000000000000000000000000000000000000001" },
+		"signature": func(u *models.NFTOracleUpdate) { u.Signature = "0x00" },
+	} {
+		tampered := update
+		tamper(&tampered)
+		if err := VerifyUpdate(tampered); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("update with tampered %s was incorrect, got: %v, want: %v.", name, err, ErrInvalidSignature)
+		}
+	}
+}
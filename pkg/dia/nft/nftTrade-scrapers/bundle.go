@@ -0,0 +1,103 @@
+package nfttradescrapers
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	transferBatchEventID = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+	// transferBatchData are the unindexed fields ids and values of an ERC1155 TransferBatch log.
+	transferBatchData abi.Arguments
+)
+
+func init() {
+	uint256Array, err := abi.NewType("uint256[]", "", nil)
+	if err != nil {
+		panic(err)
+	}
+	transferBatchData = abi.Arguments{{Name: "ids", Type: uint256Array}, {Name: "values", Type: uint256Array}}
+}
+
+// decodeTransferBatch returns a transfer for each token id of the ERC1155 TransferBatch log @txLog.
+func decodeTransferBatch(txLog *types.Log) ([]nftTransfer, error) {
+	if len(txLog.Topics) != 4 || txLog.Topics[0] != transferBatchEventID {
+		return nil, errors.New("not a TransferBatch log")
+	}
+	values, err := transferBatchData.Unpack(txLog.Data)
+	if err != nil {
+		return nil, err
+	}
+	ids, ok := values[0].([]*big.Int)
+	if !ok {
+		return nil, errors.New("unexpected type of ids")
+	}
+	amounts, ok := values[1].([]*big.Int)
+	if !ok {
+		return nil, errors.New("unexpected type of values")
+	}
+	if len(ids) != len(amounts) {
+		return nil, fmt.Errorf("number of ids %d and values %d differ", len(ids), len(amounts))
+	}
+
+	transfers := make([]nftTransfer, len(ids))
+	for i := range ids {
+		transfers[i] = nftTransfer{
+			Standard: nftStandardERC1155,
+			Contract: txLog.Address,
+			From:     common.BytesToAddress(txLog.Topics[2].Bytes()),
+			To:       common.BytesToAddress(txLog.Topics[3].Bytes()),
+			TokenID:  ids[i],
+			Amount:   amounts[i],
+		}
+	}
+	return transfers, nil
+}
+
+// allocateBundlePrice splits @price of a bundle across its items pro rata to the @amounts of the items.
+// The shares are rounded down and the remainder is allocated to the first item, such that the shares
+// sum up to @price. Items with non-positive amount are counted as a single item.
+func allocateBundlePrice(price *big.Int, amounts []*big.Int) []*big.Int {
+	shares := make([]*big.Int, len(amounts))
+	if len(amounts) == 0 {
+		return shares
+	}
+
+	weights := make([]*big.Int, len(amounts))
+	total := new(big.Int)
+	for i, amount := range amounts {
+		weights[i] = big.NewInt(1)
+		if amount != nil && amount.Sign() > 0 {
+			weights[i] = amount
+		}
+		total.Add(total, weights[i])
+	}
+
+	allocated := new(big.Int)
+	for i, weight := range weights {
+		shares[i] = new(big.Int).Div(new(big.Int).Mul(price, weight), total)
+		allocated.Add(allocated, shares[i])
+	}
+	shares[0].Add(shares[0], new(big.Int).Sub(price, allocated))
+	return shares
+}
+
+// nftBundleID identifies the bundle sold by the match event at @logIndex of the transaction @txHash.
+func nftBundleID(txHash common.Hash, logIndex uint) string {
+	return fmt.Sprintf("%s-%d", txHash.Hex(), logIndex)
+}
+
+// nftQuantity returns @amount as quantity of a trade, which is 1 for ERC721 transfers.
+func nftQuantity(amount *big.Int) uint64 {
+	if amount == nil || amount.Sign() <= 0 || !amount.IsUint64() {
+		return 1
+	}
+	return amount.Uint64()
+}
@@ -0,0 +1,59 @@
+package nfttradescrapers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeTransferBatch(t *testing.T) {
+	seller := common.HexToAddress("0xa")
+	buyer := common.HexToAddress("0xb")
+	nft := common.HexToAddress("0xc")
+
+	data, err := transferBatchData.Pack([]*big.Int{big.NewInt(7), big.NewInt(8)}, []*big.Int{big.NewInt(2), big.NewInt(1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logs := []*types.Log{
+		{Address: nft, Topics: []common.Hash{transferBatchEventID, seller.Hash(), seller.Hash(), buyer.Hash()}, Data: data},
+	}
+
+	transfers := findNFTTransfers(logs)
+	if len(transfers) != 2 {
+		t.Fatalf("number of transfers was incorrect, got: %v, want: %v.", len(transfers), 2)
+	}
+	for i, want := range []struct{ tokenID, amount int64 }{{7, 2}, {8, 1}} {
+		transfer := transfers[i]
+		if transfer.Standard != nftStandardERC1155 || transfer.Contract != nft || transfer.From != seller || transfer.To != buyer || transfer.TokenID.Int64() != want.tokenID || transfer.Amount.Int64() != want.amount {
+			t.Errorf("transfer %d was incorrect, got: %+v.", i, transfer)
+		}
+	}
+}
+
+func TestAllocateBundlePrice(t *testing.T) {
+	cases := []struct {
+		price   int64
+		amounts []*big.Int
+		want    []int64
+	}{
+		{price: 100, amounts: []*big.Int{big.NewInt(1)}, want: []int64{100}},
+		{price: 100, amounts: []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(1)}, want: []int64{34, 33, 33}},
+		{price: 100, amounts: []*big.Int{big.NewInt(3), big.NewInt(1)}, want: []int64{75, 25}},
+		{price: 10, amounts: []*big.Int{nil, big.NewInt(0), big.NewInt(2)}, want: []int64{3, 2, 5}},
+	}
+
+	for i, c := range cases {
+		shares := allocateBundlePrice(big.NewInt(c.price), c.amounts)
+		if len(shares) != len(c.want) {
+			t.Fatalf("case %d: number of shares was incorrect, got: %v, want: %v.", i, len(shares), len(c.want))
+		}
+		for j := range shares {
+			if shares[j].Int64() != c.want[j] {
+				t.Errorf("case %d: share %d was incorrect, got: %v, want: %v.", i, j, shares[j], c.want[j])
+			}
+		}
+	}
+}
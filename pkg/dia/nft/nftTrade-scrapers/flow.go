@@ -0,0 +1,813 @@
+package nfttradescrapers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/flowhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/jackc/pgx/v4"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/client"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// flowMarketplacePurchase is a marketplace emitting one event per sale with token id, price and seller.
+	flowMarketplacePurchase = "purchase"
+	// flowMarketplaceStorefront is a deployment of the NFTStorefront contract. Its sales are joined from
+	// ListingAvailable and ListingCompleted events, the open listings are kept in the nft order book.
+	flowMarketplaceStorefront = "storefront"
+
+	flowNFTContractType = "non-fungible"
+
+	// flowRetryDelay is the wait before the first retry after an error, doubled with each consecutive error
+	// up to flowMaxRetryDelay.
+	flowRetryDelay    = 5 * time.Second
+	flowMaxRetryDelay = 10 * time.Minute
+)
+
+var errFlowShutdownRequest = errors.New("shutdown requested")
+
+// FlowNFTConfig describes the Cadence NFT contracts scraped by the FlowScraper and the marketplaces
+// they are traded on. It is read from config/nftMarketplaces/<Name>.json.
+type FlowNFTConfig struct {
+	Name         string            `json:"name"`
+	Contracts    []FlowNFTContract `json:"contracts"`
+	Marketplaces []FlowMarketplace `json:"marketplaces"`
+	// Currencies maps the fungible token vault types trades are paid with, e.g.
+	// A.ead892083b3e2c6c.DapperUtilityCoin.Vault, to assets.
+	Currencies map[string]FlowCurrency `json:"currencies"`
+
+	// maximum number of blocks queried at once, limited by flowhelper.RequestLimit
+	BatchSize uint64 `json:"batch_size"`
+
+	// wait for a while between batches once all contracts are synced
+	WaitPeriodSeconds int `json:"wait_per_batch_seconds"`
+
+	// indicates the number of retries to scrape a transaction
+	// in case of an unexpected error
+	MaxRetry int `json:"max_retry"`
+}
+
+// FlowNFTContract is a contract implementing the NonFungibleToken interface. Its Deposit and Withdraw
+// events give buyer and seller of a sale.
+type FlowNFTContract struct {
+	Address string `json:"address"`
+	// Name is the name of the contract, e.g. TopShot.
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Category string `json:"category"`
+	// StartHeight is the block the scraper starts from if there is no state yet, usually the deployment block.
+	StartHeight uint64 `json:"start_height"`
+}
+
+// FlowMarketplace describes a marketplace contract on Flow.
+type FlowMarketplace struct {
+	// Name is used as exchange of the trades.
+	Name string `json:"name"`
+	// Type is either purchase or storefront.
+	Type     string `json:"type"`
+	Address  string `json:"address"`
+	Contract string `json:"contract"`
+	// Contracts restricts the marketplace to the nft contracts with these identifiers,
+	// e.g. A.0b2a3299cc857e29.TopShot. An empty list allows all contracts.
+	Contracts []string `json:"contracts"`
+
+	// Event names the sale event of a purchase marketplace and TokenID, Price and Seller its fields.
+	// The seller is taken from the Withdraw event of the nft if Seller is empty.
+	Event   string `json:"event"`
+	TokenID string `json:"token_id"`
+	Price   string `json:"price"`
+	Seller  string `json:"seller"`
+	// Currency is the vault type a purchase marketplace is paid with.
+	Currency string `json:"currency"`
+}
+
+// FlowCurrency references an asset. Symbol and Decimals are used if the asset is not in the asset table.
+type FlowCurrency struct {
+	Address    string `json:"address"`
+	Blockchain string `json:"blockchain"`
+	Symbol     string `json:"symbol"`
+	Decimals   uint8  `json:"decimals"`
+}
+
+// FlowScraper scrapes the trades of the contracts in a FlowNFTConfig. The progress of each contract is
+// stored as separate scraper state, such that contracts can be added to the config at any time.
+type FlowScraper struct {
+	tradeScraper TradeScraper
+	// datastore is used for usd prices and may be nil.
+	datastore models.Datastore
+
+	mu   sync.Mutex
+	conf FlowNFTConfig
+	// synced is false while any contract is behind the latest sealed block.
+	synced bool
+	// errCounter is the largest number of consecutive errors on a transaction of any contract in the last run.
+	errCounter int
+
+	// clients holds a client for each spork, keyed by the spork's last height and 0 for the current spork.
+	clients    map[uint64]*client.Client
+	assetCache map[string]dia.Asset
+}
+
+// flowTx holds the events of a transaction relevant to a contract, ordered by event index.
+type flowTx struct {
+	ID        flow.Identifier
+	Height    uint64
+	Index     int
+	Timestamp time.Time
+	Events    []flow.Event
+}
+
+// LoadFlowNFTConfig reads the config @name from the marketplace config directory.
+func LoadFlowNFTConfig(name string) (FlowNFTConfig, error) {
+	data, err := ioutil.ReadFile(configCollectors.ConfigFileConnectors(nftMarketplacesConfigDir+name, ".json"))
+	if err != nil {
+		return FlowNFTConfig{}, err
+	}
+	return parseFlowNFTConfig(data)
+}
+
+// parseFlowNFTConfig decodes and validates a Flow config.
+func parseFlowNFTConfig(data []byte) (conf FlowNFTConfig, err error) {
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	if conf.Name == "" || len(conf.Contracts) == 0 {
+		err = errors.New("flow config needs a name and contracts")
+		return
+	}
+	if conf.BatchSize == 0 || conf.BatchSize > flowhelper.RequestLimit {
+		conf.BatchSize = flowhelper.RequestLimit
+	}
+	for _, contract := range conf.Contracts {
+		if contract.Address == "" || contract.Name == "" {
+			err = fmt.Errorf("contract of %s needs an address and a name", conf.Name)
+			return
+		}
+	}
+	for _, m := range conf.Marketplaces {
+		if m.Name == "" || m.Address == "" || m.Contract == "" {
+			err = fmt.Errorf("marketplace of %s needs a name, an address and a contract", conf.Name)
+			return
+		}
+		switch m.Type {
+		case flowMarketplacePurchase:
+			if m.Event == "" || m.TokenID == "" || m.Price == "" {
+				err = fmt.Errorf("purchase marketplace %s needs an event with token id and price", m.Name)
+				return
+			}
+			if _, ok := conf.Currencies[m.Currency]; !ok {
+				err = fmt.Errorf("currency %q of marketplace %s is unknown", m.Currency, m.Name)
+				return
+			}
+		case flowMarketplaceStorefront:
+		default:
+			err = fmt.Errorf("marketplace %s has unknown type %q", m.Name, m.Type)
+			return
+		}
+	}
+	return
+}
+
+// Identifier returns the identifier of the contract, e.g. A.0b2a3299cc857e29.TopShot.
+func (c FlowNFTContract) Identifier() string {
+	return "A." + strings.TrimPrefix(strings.ToLower(c.Address), "0x") + "." + c.Name
+}
+
+// NFTClass returns the nft class of the contract.
+func (c FlowNFTContract) NFTClass() dia.NFTClass {
+	return dia.NFTClass{
+		Address:      c.Address,
+		Symbol:       c.Symbol,
+		Name:         c.Name,
+		Blockchain:   dia.FLOW,
+		ContractType: flowNFTContractType,
+		Category:     c.Category,
+	}
+}
+
+// trades returns true if @contract is traded on the marketplace.
+func (m FlowMarketplace) trades(contract FlowNFTContract) bool {
+	if len(m.Contracts) == 0 {
+		return true
+	}
+	for _, identifier := range m.Contracts {
+		if identifier == contract.Identifier() {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTypes returns the event types searched for to scrape the trades of @contract.
+func (conf FlowNFTConfig) eventTypes(contract FlowNFTContract) []string {
+	eventTypes := []string{
+		flowhelper.EventType(contract.Address, contract.Name, "Deposit"),
+		flowhelper.EventType(contract.Address, contract.Name, "Withdraw"),
+	}
+	for _, m := range conf.Marketplaces {
+		if !m.trades(contract) {
+			continue
+		}
+		switch m.Type {
+		case flowMarketplacePurchase:
+			eventTypes = append(eventTypes, flowhelper.EventType(m.Address, m.Contract, m.Event))
+		case flowMarketplaceStorefront:
+			eventTypes = append(eventTypes,
+				flowhelper.EventType(m.Address, m.Contract, "ListingAvailable"),
+				flowhelper.EventType(m.Address, m.Contract, "ListingCompleted"),
+			)
+		}
+	}
+	return eventTypes
+}
+
+// groupFlowEvents returns the events in @blockEvents grouped by transaction in chain order.
+func groupFlowEvents(blockEvents []client.BlockEvents) (txs []flowTx) {
+	var events []flowTx
+	for _, block := range blockEvents {
+		for _, event := range block.Events {
+			events = append(events, flowTx{ID: event.TransactionID, Height: block.Height, Index: event.TransactionIndex, Timestamp: block.BlockTimestamp, Events: []flow.Event{event}})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Height != events[j].Height {
+			return events[i].Height < events[j].Height
+		}
+		if events[i].Index != events[j].Index {
+			return events[i].Index < events[j].Index
+		}
+		return events[i].Events[0].EventIndex < events[j].Events[0].EventIndex
+	})
+	for _, event := range events {
+		if n := len(txs); n > 0 && txs[n-1].ID == event.ID {
+			txs[n-1].Events = append(txs[n-1].Events, event.Events...)
+			continue
+		}
+		txs = append(txs, event)
+	}
+	return
+}
+
+// flowAmount returns the UFix64 amount @amount in units of its currency.
+func flowAmount(amount uint64) decimal.Decimal {
+	return decimal.NewFromBigInt(new(big.Int).SetUint64(amount), -flowhelper.UFix64Decimals)
+}
+
+// flowBlockPosition returns the position of @event in its block used to order nft order events.
+func flowBlockPosition(event flow.Event) uint64 {
+	return uint64(event.TransactionIndex)<<32 | uint64(event.EventIndex)
+}
+
+// NewFlowScraper returns a scraper for the contracts in @conf. @ds is used for the usd prices
+// of trades not paid in usd and may be nil.
+func NewFlowScraper(rdb *models.RelDB, ds models.Datastore, conf FlowNFTConfig) *FlowScraper {
+	s := &FlowScraper{
+		datastore:  ds,
+		conf:       conf,
+		clients:    make(map[uint64]*client.Client),
+		assetCache: make(map[string]dia.Asset),
+		tradeScraper: TradeScraper{
+			shutdown:     make(chan nothing),
+			shutdownDone: make(chan nothing),
+			datastore:    rdb,
+			chanTrade:    make(chan dia.NFTTrade),
+			source:       conf.Name,
+		},
+	}
+
+	if _, err := s.client(flowhelper.RootHeightCurrent); err != nil {
+		log.Errorf("%s scraper could not connect to flow: %s", conf.Name, err.Error())
+		return nil
+	}
+
+	go s.mainLoop()
+
+	return s
+}
+
+func (s *FlowScraper) mainLoop() {
+	defer func() {
+		s.tradeScraper.closed = true
+
+		close(s.tradeScraper.chanTrade)
+		close(s.tradeScraper.shutdownDone)
+	}()
+
+	waitPeriod := time.Duration(s.conf.WaitPeriodSeconds) * time.Second
+	log.Infof("%s scraper has been started (contracts: %d, batch: %d, period: %s)", s.conf.Name, len(s.conf.Contracts), s.conf.BatchSize, waitPeriod.String())
+
+	failures := 0
+	for stop := false; !stop; {
+		if err := s.FetchTrades(); err != nil {
+			if errors.Is(err, errFlowShutdownRequest) {
+				stop = true
+				continue
+			}
+			failures++
+		} else {
+			failures = 0
+		}
+
+		wait := waitPeriod
+		if !s.synced {
+			// The stored error counter carries the retries of a transaction over restarts.
+			if s.errCounter > failures {
+				failures = s.errCounter
+			}
+			wait = flowBackoff(failures)
+		}
+		log.Debugf("wait for %s", wait)
+
+		select {
+		case <-time.After(wait):
+		case <-s.tradeScraper.shutdown:
+			stop = true
+		}
+	}
+}
+
+// FetchTrades scrapes the next block range of each contract.
+func (s *FlowScraper) FetchTrades() error {
+	ctx := context.Background()
+
+	// it must be run once at a time
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.client(flowhelper.RootHeightCurrent)
+	if err != nil {
+		return err
+	}
+	latest, err := current.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		log.Warnf("unable to get latest sealed flow block: %s", err.Error())
+		return err
+	}
+
+	s.synced = true
+	s.errCounter = 0
+	var lastErr error
+	for _, contract := range s.conf.Contracts {
+		if err := s.fetchContractTrades(ctx, contract, latest.Height); err != nil {
+			if errors.Is(err, errFlowShutdownRequest) {
+				return err
+			}
+			log.Warnf("unable to fetch trades of %s: %s", contract.Identifier(), err.Error())
+			s.synced = false
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// flowBackoff returns the wait before the next run after @failures consecutive errors.
+func flowBackoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	wait := flowRetryDelay
+	for i := 1; i < failures && wait < flowMaxRetryDelay; i++ {
+		wait *= 2
+	}
+	if wait > flowMaxRetryDelay {
+		wait = flowMaxRetryDelay
+	}
+	return wait
+}
+
+// fetchContractTrades processes the next batch of blocks not beyond @latestHeight for @contract.
+func (s *FlowScraper) fetchContractTrades(ctx context.Context, contract FlowNFTContract, latestHeight uint64) error {
+	stateName := s.conf.Name + "-" + contract.Identifier()
+	state := &GenericScraperState{}
+	if err := s.tradeScraper.datastore.GetScraperState(ctx, stateName, state); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		state = &GenericScraperState{LastBlockNum: contract.StartHeight}
+	}
+	if state.LastBlockNum > latestHeight {
+		return nil
+	}
+
+	startHeight := state.LastBlockNum
+	endHeight := startHeight + s.conf.BatchSize - 1
+	if endHeight > latestHeight {
+		endHeight = latestHeight
+	}
+	if sporkEnd, ok := flowhelper.SporkEndHeight(startHeight); ok && endHeight > sporkEnd {
+		endHeight = sporkEnd
+	}
+	flowClient, err := s.client(startHeight)
+	if err != nil {
+		return err
+	}
+
+	var blockEvents []client.BlockEvents
+	for _, eventType := range s.conf.eventTypes(contract) {
+		for {
+			events, err := flowClient.GetEventsForHeightRange(ctx, client.EventRangeQuery{Type: eventType, StartHeight: startHeight, EndHeight: endHeight})
+			if err != nil {
+				if strings.Contains(err.Error(), "ResourceExhausted") && endHeight > startHeight {
+					log.Warn("resource exhausted, decrease number of queried blocks.")
+					endHeight = startHeight + (endHeight-startHeight)/2
+					continue
+				}
+				return err
+			}
+			blockEvents = append(blockEvents, events...)
+			break
+		}
+	}
+	// Earlier event types may have been queried for a larger range before it was decreased.
+	var inRange []client.BlockEvents
+	for _, block := range blockEvents {
+		if block.Height <= endHeight {
+			inRange = append(inRange, block)
+		}
+	}
+	txs := groupFlowEvents(inRange)
+
+	log.Infof("found %d transactions of %s in blocks %d to %d (latest: %d)", len(txs), contract.Identifier(), startHeight, endHeight, latestHeight)
+
+	numTrades := 0
+	for _, tx := range txs {
+		if tx.Height == state.LastBlockNum && uint(tx.Index) < state.LastTxIndex {
+			continue
+		}
+		state.LastBlockNum = tx.Height
+		state.LastTxIndex = uint(tx.Index)
+		state.LastErr = ""
+
+		trades, err := s.processTx(contract, tx)
+		if err != nil {
+			state.ErrCounter++
+			if state.ErrCounter > s.errCounter {
+				s.errCounter = state.ErrCounter
+			}
+
+			if state.ErrCounter <= s.conf.MaxRetry {
+				state.LastErr = fmt.Sprintf("unable to process transaction(%s): %s", tx.ID.Hex(), err.Error())
+				log.Error(state.LastErr)
+				if err := s.tradeScraper.datastore.SetScraperState(ctx, stateName, state); err != nil {
+					log.Warnf("unable to store scraper state: %s", err.Error())
+				}
+				return err
+			}
+
+			log.Warnf("SKIPPING PERMANENTLY! block: %d, tx index: %d - error: %s", tx.Height, tx.Index, err.Error())
+		}
+
+		for _, trade := range trades {
+			select {
+			case s.tradeScraper.chanTrade <- trade:
+			case <-s.tradeScraper.shutdown:
+				return errFlowShutdownRequest
+			}
+		}
+		numTrades += len(trades)
+
+		// reset consecutive error counter
+		state.ErrCounter = 0
+
+		// move next
+		state.LastTxIndex = uint(tx.Index) + 1
+
+		if err := s.tradeScraper.datastore.SetScraperState(ctx, stateName, state); err != nil {
+			log.Warnf("unable to store scraper state: %s", err.Error())
+			return err
+		}
+	}
+
+	state.LastBlockNum = endHeight + 1
+	state.LastTxIndex = 0
+	if err := s.tradeScraper.datastore.SetScraperState(ctx, stateName, state); err != nil {
+		log.Warnf("unable to store scraper state: %s", err.Error())
+		return err
+	}
+	if endHeight < latestHeight {
+		s.synced = false
+	}
+
+	log.Infof("processed %d trades of %s", numTrades, contract.Identifier())
+
+	return nil
+}
+
+// processTx returns the trades of @contract in @tx and applies its storefront listings to the order book.
+func (s *FlowScraper) processTx(contract FlowNFTContract, tx flowTx) (trades []dia.NFTTrade, err error) {
+	depositType := flowhelper.EventType(contract.Address, contract.Name, "Deposit")
+	withdrawType := flowhelper.EventType(contract.Address, contract.Name, "Withdraw")
+	nftType := flowhelper.EventType(contract.Address, contract.Name, "NFT")
+
+	// Receivers and senders of the nfts moved in the transaction by token id.
+	recipients := make(map[uint64]string)
+	senders := make(map[uint64]string)
+	for _, event := range tx.Events {
+		switch event.Type {
+		case depositType:
+			id, err := flowhelper.UInt64Field(event.Value, "id")
+			if err != nil {
+				return nil, err
+			}
+			if recipients[id], err = flowhelper.AddressField(event.Value, "to"); err != nil {
+				return nil, err
+			}
+		case withdrawType:
+			id, err := flowhelper.UInt64Field(event.Value, "id")
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := senders[id]; ok {
+				continue
+			}
+			if senders[id], err = flowhelper.AddressField(event.Value, "from"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, m := range s.conf.Marketplaces {
+		if !m.trades(contract) {
+			continue
+		}
+		for _, event := range tx.Events {
+			var trade *dia.NFTTrade
+			switch {
+			case m.Type == flowMarketplacePurchase && event.Type == flowhelper.EventType(m.Address, m.Contract, m.Event):
+				trade, err = s.purchase(contract, m, tx, event, recipients, senders)
+			case m.Type == flowMarketplaceStorefront && event.Type == flowhelper.EventType(m.Address, m.Contract, "ListingAvailable"):
+				err = s.listingAvailable(contract, m, tx, event, nftType)
+			case m.Type == flowMarketplaceStorefront && event.Type == flowhelper.EventType(m.Address, m.Contract, "ListingCompleted"):
+				trade, err = s.listingCompleted(contract, m, tx, event, recipients)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if trade != nil {
+				trades = append(trades, *trade)
+			}
+		}
+	}
+	return
+}
+
+// purchase returns the trade of the sale @event of the purchase marketplace @m.
+func (s *FlowScraper) purchase(contract FlowNFTContract, m FlowMarketplace, tx flowTx, event flow.Event, recipients, senders map[uint64]string) (*dia.NFTTrade, error) {
+	id, err := flowhelper.UInt64Field(event.Value, m.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	price, err := flowhelper.UFix64Field(event.Value, m.Price)
+	if err != nil {
+		return nil, err
+	}
+	seller := senders[id]
+	if m.Seller != "" {
+		if seller, err = flowhelper.AddressField(event.Value, m.Seller); err != nil {
+			return nil, err
+		}
+	}
+	nft, err := s.createOrReadNFT(contract, id)
+	if err != nil {
+		return nil, err
+	}
+	currency := s.currency(m.Currency)
+	return &dia.NFTTrade{
+		NFT:         nft,
+		Price:       flowAmount(price).Shift(int32(currency.Decimals)).BigInt(),
+		PriceUSD:    s.calcUSDPrice(currency, flowAmount(price), tx.Timestamp),
+		FromAddress: seller,
+		ToAddress:   recipients[id],
+		Currency:    currency,
+		BlockNumber: tx.Height,
+		Timestamp:   tx.Timestamp,
+		TxHash:      tx.ID.Hex(),
+		Exchange:    m.Name,
+		Quantity:    1,
+	}, nil
+}
+
+// listingAvailable places the listing @event of the storefront @m as offer if it lists an nft of @nftType.
+func (s *FlowScraper) listingAvailable(contract FlowNFTContract, m FlowMarketplace, tx flowTx, event flow.Event, nftType string) error {
+	listedType, err := flowhelper.TypeField(event.Value, "nftType")
+	if err != nil || listedType != nftType {
+		return err
+	}
+	listingID, err := flowhelper.UInt64Field(event.Value, "listingResourceID")
+	if err != nil {
+		return err
+	}
+	id, err := flowhelper.UInt64Field(event.Value, "nftID")
+	if err != nil {
+		return err
+	}
+	vaultType, err := flowhelper.TypeField(event.Value, "ftVaultType")
+	if err != nil {
+		return err
+	}
+	price, err := flowhelper.UFix64Field(event.Value, "price")
+	if err != nil {
+		return err
+	}
+	maker, err := flowhelper.AddressField(event.Value, "storefrontAddress")
+	if err != nil {
+		return err
+	}
+	if _, ok := s.conf.Currencies[vaultType]; !ok {
+		log.Warnf("skip listing %d of %s in unknown currency %s", listingID, contract.Identifier(), vaultType)
+		return nil
+	}
+	nft, err := s.createOrReadNFT(contract, id)
+	if err != nil {
+		return err
+	}
+	currency := s.currency(vaultType)
+	_, err = s.tradeScraper.datastore.ApplyNFTOrderEvent(dia.NFTOrderEvent{
+		NFT:           nft,
+		Side:          dia.NFTOrderSideOffer,
+		Type:          dia.NFTOrderPlaced,
+		OrderID:       strconv.FormatUint(listingID, 10),
+		Maker:         maker,
+		Price:         flowAmount(price).Shift(int32(currency.Decimals)).BigInt(),
+		Currency:      currency,
+		BlockNumber:   tx.Height,
+		BlockPosition: flowBlockPosition(event),
+		Timestamp:     tx.Timestamp,
+		TxHash:        tx.ID.Hex(),
+		Exchange:      m.Name,
+	})
+	return err
+}
+
+// listingCompleted closes the listing of @event in the order book and returns the trade if it was purchased.
+// The trade is returned as well if the listing was filled by @tx before, such that a replayed transaction
+// emits its trades again. Listings of other contracts are not in the order book of @contract and are ignored.
+func (s *FlowScraper) listingCompleted(contract FlowNFTContract, m FlowMarketplace, tx flowTx, event flow.Event, recipients map[uint64]string) (*dia.NFTTrade, error) {
+	listingID, err := flowhelper.UInt64Field(event.Value, "listingResourceID")
+	if err != nil {
+		return nil, err
+	}
+	purchased, err := flowhelper.BoolField(event.Value, "purchased")
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.tradeScraper.datastore.GetNFTOrder(contract.NFTClass(), m.Name, strconv.FormatUint(listingID, 10))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	id, err := strconv.ParseUint(order.NFT.TokenID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	nft, err := s.createOrReadNFT(contract, id)
+	if err != nil {
+		return nil, err
+	}
+
+	orderEvent := dia.NFTOrderEvent{
+		NFT:           nft,
+		Side:          dia.NFTOrderSideOffer,
+		Type:          dia.NFTOrderCancelled,
+		OrderID:       order.OrderID,
+		BlockNumber:   tx.Height,
+		BlockPosition: flowBlockPosition(event),
+		Timestamp:     tx.Timestamp,
+		TxHash:        tx.ID.Hex(),
+		Exchange:      m.Name,
+	}
+	if purchased {
+		orderEvent.Type = dia.NFTOrderFilled
+	}
+	applied, err := s.tradeScraper.datastore.ApplyNFTOrderEvent(orderEvent)
+	if err != nil || !purchased {
+		return nil, err
+	}
+	if !applied && !(order.Status == dia.NFTOrderFilled && order.TxHash == tx.ID.Hex()) {
+		return nil, nil
+	}
+
+	return &dia.NFTTrade{
+		NFT:         nft,
+		Price:       order.Price,
+		PriceUSD:    s.calcUSDPrice(order.Currency, decimal.NewFromBigInt(order.Price, -int32(order.Currency.Decimals)), tx.Timestamp),
+		FromAddress: order.Maker,
+		ToAddress:   recipients[id],
+		Currency:    order.Currency,
+		BlockNumber: tx.Height,
+		Timestamp:   tx.Timestamp,
+		TxHash:      tx.ID.Hex(),
+		Exchange:    m.Name,
+		Quantity:    1,
+	}, nil
+}
+
+// client returns a client of the access node serving @height.
+func (s *FlowScraper) client(height uint64) (*client.Client, error) {
+	sporkEnd, _ := flowhelper.SporkEndHeight(height)
+	if flowClient, ok := s.clients[sporkEnd]; ok {
+		return flowClient, nil
+	}
+	flowClient, err := flowhelper.GetFlowClient(height)
+	if err != nil {
+		return nil, err
+	}
+	s.clients[sporkEnd] = flowClient
+	return flowClient, nil
+}
+
+// currency returns the asset paid with vaults of @vaultType.
+func (s *FlowScraper) currency(vaultType string) dia.Asset {
+	if asset, ok := s.assetCache[vaultType]; ok {
+		return asset
+	}
+	ref := s.conf.Currencies[vaultType]
+	asset, err := s.tradeScraper.datastore.GetAsset(ref.Address, ref.Blockchain)
+	if err != nil {
+		log.Warnf("cannot fetch asset %s -- %s: %v", ref.Blockchain, ref.Address, err)
+		asset = dia.Asset{Address: ref.Address, Blockchain: ref.Blockchain, Symbol: ref.Symbol, Decimals: ref.Decimals}
+	}
+	s.assetCache[vaultType] = asset
+	return asset
+}
+
+// calcUSDPrice returns the usd value of @amount of @currency at @timestamp. Amounts in usd are
+// taken as is, others need a quotation.
+func (s *FlowScraper) calcUSDPrice(currency dia.Asset, amount decimal.Decimal, timestamp time.Time) float64 {
+	if currency.Blockchain == dia.FIAT && currency.Address == "840" {
+		f, _ := amount.Float64()
+		return f
+	}
+	if s.datastore == nil {
+		return 0
+	}
+	quotation, err := s.datastore.GetAssetQuotation(currency, timestamp)
+	if err != nil {
+		log.Warnf("no quotation for %s at %v: %v", currency.Symbol, timestamp, err)
+		return 0
+	}
+	f, _ := amount.Mul(decimal.NewFromFloat(quotation.Price)).Float64()
+	return f
+}
+
+func (s *FlowScraper) createOrReadNFT(contract FlowNFTContract, id uint64) (dia.NFT, error) {
+	nftClass := contract.NFTClass()
+	tokenID := strconv.FormatUint(id, 10)
+	nft, err := s.tradeScraper.datastore.GetNFT(nftClass.Address, nftClass.Blockchain, tokenID)
+	if err == nil {
+		return nft, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		log.Warnf("unable to read nft from reldb: %s", err.Error())
+		return nft, err
+	}
+
+	if _, err := s.tradeScraper.datastore.GetNFTClassID(nftClass.Address, nftClass.Blockchain); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Warnf("unable to read nftclass from reldb: %s", err.Error())
+			return nft, err
+		}
+		if err = s.tradeScraper.datastore.SetNFTClass(nftClass); err != nil {
+			log.Warnf("unable to create nftclass on reldb: %s", err.Error())
+			return nft, err
+		}
+	}
+
+	nft = dia.NFT{NFTClass: nftClass, TokenID: tokenID}
+	if err = s.tradeScraper.datastore.SetNFT(nft); err != nil {
+		log.Warnf("unable to create nft on reldb: %s", err.Error())
+		return nft, err
+	}
+	return nft, nil
+}
+
+// GetTradeChannel returns the scrapers data channel.
+func (s *FlowScraper) GetTradeChannel() chan dia.NFTTrade {
+	return s.tradeScraper.chanTrade
+}
+
+func (s *FlowScraper) Close() error {
+	if s.tradeScraper.closed {
+		return errors.New("scraper already closed")
+	}
+
+	close(s.tradeScraper.shutdown)
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package nfttradescrapers
+
+import (
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia/helpers/flowhelper"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/client"
+)
+
+func TestFlowNFTConfig(t *testing.T) {
+	data, err := ioutil.ReadFile("../../../../config/nftMarketplaces/Flow.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf, err := parseFlowNFTConfig(data)
+	if err != nil {
+		t.Fatalf("config of Flow is invalid: %v", err)
+	}
+	topshot := conf.Contracts[0]
+	if topshot.Identifier() != "A.0b2a3299cc857e29.TopShot" || topshot.NFTClass().Address != TopshotAddress {
+		t.Errorf("contract was incorrect, got: %v %v.", topshot.Identifier(), topshot.NFTClass().Address)
+	}
+	wantTypes := []string{
+		"A.0b2a3299cc857e29.TopShot.Deposit",
+		"A.0b2a3299cc857e29.TopShot.Withdraw",
+		"A.c1e4f4f4c4257510.Market.MomentPurchased",
+		"A.4eb8a10cb9f87357.NFTStorefront.ListingAvailable",
+		"A.4eb8a10cb9f87357.NFTStorefront.ListingCompleted",
+	}
+	if got := conf.eventTypes(topshot); !reflect.DeepEqual(got, wantTypes) {
+		t.Errorf("event types were incorrect, got: %v, want: %v.", got, wantTypes)
+	}
+	if conf.Marketplaces[0].trades(FlowNFTContract{Address: "0x921ea449dffec68a", Name: "Flovatar"}) {
+		t.Error("Top Shot market trades other contracts.")
+	}
+
+	for _, invalid := range []string{
+		`{"name":"Flow"}`,
+		`{"name":"Flow","contracts":[{"address":"0x0b2a3299cc857e29"}]}`,
+		`{"name":"Flow","contracts":[{"address":"0x0b2a3299cc857e29","name":"TopShot"}],"marketplaces":[{"name":"m","type":"auction","address":"0x1","contract":"M"}]}`,
+		`{"name":"Flow","contracts":[{"address":"0x0b2a3299cc857e29","name":"TopShot"}],"marketplaces":[{"name":"m","type":"purchase","address":"0x1","contract":"M","event":"Sold","token_id":"id","price":"price","currency":"A.1.X.Vault"}]}`,
+	} {
+		if _, err := parseFlowNFTConfig([]byte(invalid)); err == nil {
+			t.Errorf("expected error for config %s", invalid)
+		}
+	}
+}
+
+func TestFlowEventFields(t *testing.T) {
+	event := cadence.NewEvent([]cadence.Value{
+		cadence.NewUInt64(42),
+		cadence.UFix64(1250000000),
+		cadence.NewOptional(cadence.NewAddress([8]byte{0x0b, 0x2a, 0x32, 0x99, 0xcc, 0x85, 0x7e, 0x29})),
+		cadence.NewOptional(nil),
+		cadence.TypeValue{StaticType: "A.0b2a3299cc857e29.TopShot.NFT"},
+		cadence.NewBool(true),
+	}).WithType(&cadence.EventType{
+		QualifiedIdentifier: "Market.MomentPurchased",
+		Fields: []cadence.Field{
+			{Identifier: "id"},
+			{Identifier: "price"},
+			{Identifier: "seller"},
+			{Identifier: "buyer"},
+			{Identifier: "nftType"},
+			{Identifier: "purchased"},
+		},
+	})
+
+	if id, err := flowhelper.UInt64Field(event, "id"); err != nil || id != 42 {
+		t.Errorf("id was incorrect, got: %v %v, want: %v.", id, err, 42)
+	}
+	price, err := flowhelper.UFix64Field(event, "price")
+	if err != nil || flowAmount(price).String() != "12.5" {
+		t.Errorf("price was incorrect, got: %v %v, want: %v.", flowAmount(price), err, "12.5")
+	}
+	if got := flowAmount(price).Shift(6).BigInt(); got.Cmp(big.NewInt(12500000)) != 0 {
+		t.Errorf("price in 6 decimals was incorrect, got: %v, want: %v.", got, 12500000)
+	}
+	if seller, err := flowhelper.AddressField(event, "seller"); err != nil || seller != TopshotAddress {
+		t.Errorf("seller was incorrect, got: %v %v, want: %v.", seller, err, TopshotAddress)
+	}
+	if buyer, err := flowhelper.AddressField(event, "buyer"); err != nil || buyer != "" {
+		t.Errorf("empty buyer was incorrect, got: %v %v.", buyer, err)
+	}
+	if nftType, err := flowhelper.TypeField(event, "nftType"); err != nil || nftType != "A.0b2a3299cc857e29.TopShot.NFT" {
+		t.Errorf("nft type was incorrect, got: %v %v.", nftType, err)
+	}
+	if purchased, err := flowhelper.BoolField(event, "purchased"); err != nil || !purchased {
+		t.Errorf("purchased was incorrect, got: %v %v.", purchased, err)
+	}
+	if _, err := flowhelper.UInt64Field(event, "price"); err == nil {
+		t.Error("expected error for UFix64 read as UInt64")
+	}
+	if _, err := flowhelper.UInt64Field(event, "listingResourceID"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestGroupFlowEvents(t *testing.T) {
+	tx := func(b byte) flow.Identifier { return flow.Identifier{b} }
+	event := func(eventType string, txID flow.Identifier, txIndex, eventIndex int) flow.Event {
+		return flow.Event{Type: eventType, TransactionID: txID, TransactionIndex: txIndex, EventIndex: eventIndex}
+	}
+	timestamp := time.Unix(1640995200, 0)
+	// Events of different types are queried separately and arrive grouped by type.
+	blockEvents := []client.BlockEvents{
+		{Height: 11, BlockTimestamp: timestamp.Add(time.Second), Events: []flow.Event{event("Deposit", tx(3), 0, 2)}},
+		{Height: 10, BlockTimestamp: timestamp, Events: []flow.Event{event("Deposit", tx(2), 1, 3), event("Deposit", tx(1), 0, 1)}},
+		{Height: 10, BlockTimestamp: timestamp, Events: []flow.Event{event("MomentPurchased", tx(2), 1, 1), event("Withdraw", tx(1), 0, 0)}},
+	}
+
+	txs := groupFlowEvents(blockEvents)
+	if len(txs) != 3 {
+		t.Fatalf("number of transactions was incorrect, got: %v, want: %v.", len(txs), 3)
+	}
+	for i, want := range []struct {
+		id     flow.Identifier
+		height uint64
+		events []string
+	}{
+		{tx(1), 10, []string{"Withdraw", "Deposit"}},
+		{tx(2), 10, []string{"MomentPurchased", "Deposit"}},
+		{tx(3), 11, []string{"Deposit"}},
+	} {
+		var events []string
+		for _, event := range txs[i].Events {
+			events = append(events, event.Type)
+		}
+		if txs[i].ID != want.id || txs[i].Height != want.height || !reflect.DeepEqual(events, want.events) {
+			t.Errorf("transaction %d was incorrect, got: %v %v %v, want: %v %v %v.", i, txs[i].ID, txs[i].Height, events, want.id, want.height, want.events)
+		}
+	}
+}
+
+func TestFlowSporkEndHeight(t *testing.T) {
+	for _, c := range []struct {
+		height  uint64
+		want    uint64
+		wantOld bool
+	}{
+		{flowhelper.RootHeight1, flowhelper.RootHeight2 - 1, true},
+		{flowhelper.RootHeight2 - 1, flowhelper.RootHeight2 - 1, true},
+		{flowhelper.RootHeight16, flowhelper.RootHeightCurrent - 1, true},
+		{flowhelper.RootHeightCurrent, 0, false},
+	} {
+		if got, old := flowhelper.SporkEndHeight(c.height); got != c.want || old != c.wantOld {
+			t.Errorf("spork end of %d was incorrect, got: %v %v, want: %v %v.", c.height, got, old, c.want, c.wantOld)
+		}
+	}
+}
+
+func TestFlowBackoff(t *testing.T) {
+	for _, c := range []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, flowRetryDelay},
+		{3, 4 * flowRetryDelay},
+		{100, flowMaxRetryDelay},
+	} {
+		if got := flowBackoff(c.failures); got != c.want {
+			t.Errorf("backoff after %d failures was incorrect, got: %v, want: %v.", c.failures, got, c.want)
+		}
+	}
+}
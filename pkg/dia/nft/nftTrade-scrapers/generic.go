@@ -0,0 +1,905 @@
+package nfttradescrapers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/config/nftContracts/erc20"
+	"github.com/diadata-org/diadata/config/nftContracts/erc721"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// nftMarketplacesConfigDir is the folder in the config directory holding the marketplace configs.
+	nftMarketplacesConfigDir = "nftMarketplaces/"
+
+	nftStandardERC721  = "ERC721"
+	nftStandardERC1155 = "ERC1155"
+)
+
+var (
+	errGenericShutdownRequest = errors.New("shutdown requested")
+
+	// Topics of the token transfer events searched for in the transactions of a match event.
+	// ERC20 and ERC721 Transfer share their signature and differ in the number of indexed fields.
+	transferEventID       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleEventID = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+)
+
+// NFTMarketplaceConfig describes a marketplace scraped by the GenericScraper. It is read from
+// config/nftMarketplaces/<Name>.json, such that a marketplace can be added without a new scraper.
+type NFTMarketplaceConfig struct {
+	// Name is used as exchange of the trades and as identifier of the scraper state in postgres.
+	Name         string `json:"name"`
+	Blockchain   string `json:"blockchain"`
+	ContractAddr string `json:"contract_addr"`
+	// StartBlock is the block the scraper starts from if there is no state yet, usually the deployment block.
+	StartBlock uint64                `json:"start_block"`
+	Events     []NFTMarketplaceEvent `json:"events"`
+
+	// indicates the batch size during read the filtered events
+	BatchSize int `json:"batch_size"`
+
+	// wait for a while between batch retrieval of filtered events
+	WaitPeriodSeconds int `json:"wait_per_batch_seconds"`
+
+	// it enables read contract data from the event's block
+	// height instead of the last state
+	FollowDist int `json:"following_distance_blocks"`
+
+	// if set it will read token metadata at the currently
+	// processing block
+	UseArchiveNode bool `json:"use_archive_node_features"`
+
+	// indicates the number of retries to scrape the target
+	// in case of an unexpected error
+	MaxRetry int `json:"max_retry"`
+
+	// it limits read bytes for NFT's metadata from external url
+	MaxMetadataSize int `json:"max_metadata_size"`
+
+	// it limits duration of read for NFT's metadata from external url
+	MetadataTimeoutSeconds int `json:"metadata_timeout_seconds"`
+}
+
+// NFTMarketplaceEvent describes a match event emitted by the marketplace contract on a sale.
+// The mappings name inputs of the event in ABI. Empty mappings are derived from the transaction.
+type NFTMarketplaceEvent struct {
+	// ABI is the json ABI of the event.
+	ABI json.RawMessage `json:"abi"`
+	// Price maps the sale price in the smallest unit of the currency. It is mandatory.
+	Price string `json:"price"`
+	// Currency maps the ERC20 address of the currency, the zero address denoting the native currency.
+	// If empty, the currency is native if the transaction has value and otherwise the ERC20 token
+	// transferred with amount price in the same transaction.
+	Currency string `json:"currency"`
+	// Seller and Buyer map the addresses of seller and buyer, e.g. maker and taker of a taker bid.
+	// If empty, they are taken from the nft transfer.
+	Seller string `json:"seller"`
+	Buyer  string `json:"buyer"`
+	// Collection and TokenID map the nft sold. If empty, the nft is given by the unique ERC721 Transfer
+	// or ERC1155 TransferSingle of the transaction that matches seller and buyer.
+	Collection string `json:"collection"`
+	TokenID    string `json:"token_id"`
+	// Amount maps the number of items sold. If empty, a single item is sold.
+	Amount string `json:"amount"`
+	// Standard is the token standard of the nfts sold, ERC721 or ERC1155. If empty, it is taken from
+	// the transfer of the nft in the transaction.
+	Standard string `json:"standard"`
+
+	event abi.Event
+}
+
+// GenericScraperState is the state of a GenericScraper stored in postgres.
+type GenericScraperState struct {
+	// last block number has been processed
+	LastBlockNum uint64 `json:"last_block_num"`
+
+	// last transaction index in the block(curr) has been processed
+	LastTxIndex uint `json:"last_tx_index"`
+
+	// holds the latest error message that occurred while scraping
+	LastErr string `json:"last_error"`
+
+	// indicates the number of consecutive error, reset on any successful operation
+	ErrCounter int `json:"count_of_error"`
+}
+
+// GenericScraper scrapes the trades of any marketplace described by a NFTMarketplaceConfig.
+type GenericScraper struct {
+	tradeScraper TradeScraper
+	// datastore is used for usd prices and may be nil.
+	datastore models.Datastore
+
+	mu    sync.Mutex
+	conf  NFTMarketplaceConfig
+	state *GenericScraperState
+
+	events     map[common.Hash]*NFTMarketplaceEvent
+	assetCache map[string]dia.Asset
+}
+
+// nftMarketplaceMatch holds the fields of a match event. Fields not mapped by the config are nil.
+type nftMarketplaceMatch struct {
+	Price      *big.Int
+	Currency   *common.Address
+	Seller     *common.Address
+	Buyer      *common.Address
+	Collection *common.Address
+	TokenID    *big.Int
+	Amount     *big.Int
+}
+
+// nftTransfer is an ERC721 Transfer, an ERC1155 TransferSingle log or an item of an ERC1155 TransferBatch log.
+type nftTransfer struct {
+	Standard string
+	Contract common.Address
+	From     common.Address
+	To       common.Address
+	TokenID  *big.Int
+	Amount   *big.Int
+}
+
+// LoadNFTMarketplaceConfig reads the config of the marketplace @name from the config directory.
+func LoadNFTMarketplaceConfig(name string) (NFTMarketplaceConfig, error) {
+	data, err := ioutil.ReadFile(configCollectors.ConfigFileConnectors(nftMarketplacesConfigDir+name, ".json"))
+	if err != nil {
+		return NFTMarketplaceConfig{}, err
+	}
+	return parseNFTMarketplaceConfig(data)
+}
+
+// parseNFTMarketplaceConfig decodes and validates a marketplace config including the ABIs of its events.
+func parseNFTMarketplaceConfig(data []byte) (conf NFTMarketplaceConfig, err error) {
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	if conf.Name == "" || !common.IsHexAddress(conf.ContractAddr) {
+		err = errors.New("marketplace config needs a name and a contract address")
+		return
+	}
+	if conf.Blockchain == "" {
+		conf.Blockchain = dia.ETHEREUM
+	}
+	if len(conf.Events) == 0 {
+		err = fmt.Errorf("no events in config of %s", conf.Name)
+		return
+	}
+	for i := range conf.Events {
+		var contractABI abi.ABI
+		contractABI, err = abi.JSON(bytes.NewReader(append(append([]byte("["), conf.Events[i].ABI...), ']')))
+		if err != nil {
+			err = fmt.Errorf("abi of event %d of %s: %w", i, conf.Name, err)
+			return
+		}
+		if len(contractABI.Events) != 1 {
+			err = fmt.Errorf("abi of event %d of %s must contain exactly one event", i, conf.Name)
+			return
+		}
+		for _, event := range contractABI.Events {
+			conf.Events[i].event = event
+		}
+		if err = conf.Events[i].validate(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// validate checks that all mapped fields are inputs of the event.
+func (ev *NFTMarketplaceEvent) validate() error {
+	if ev.Price == "" {
+		return fmt.Errorf("event %s has no price mapping", ev.event.Name)
+	}
+	if ev.Standard != "" && ev.Standard != nftStandardERC721 && ev.Standard != nftStandardERC1155 {
+		return fmt.Errorf("event %s has unknown standard %s", ev.event.Name, ev.Standard)
+	}
+	for _, field := range []string{ev.Price, ev.Currency, ev.Seller, ev.Buyer, ev.Collection, ev.TokenID, ev.Amount} {
+		if field == "" {
+			continue
+		}
+		found := false
+		for _, input := range ev.event.Inputs {
+			found = found || input.Name == field
+		}
+		if !found {
+			return fmt.Errorf("event %s has no input %s", ev.event.Name, field)
+		}
+	}
+	return nil
+}
+
+// decode returns the mapped fields of @txLog.
+func (ev *NFTMarketplaceEvent) decode(txLog types.Log) (match nftMarketplaceMatch, err error) {
+	values := make(map[string]interface{})
+	if err = ev.event.Inputs.UnpackIntoMap(values, txLog.Data); err != nil {
+		return
+	}
+	var indexed abi.Arguments
+	for _, input := range ev.event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(txLog.Topics) < 1 {
+		err = errors.New("log without topics")
+		return
+	}
+	if err = abi.ParseTopicsIntoMap(values, indexed, txLog.Topics[1:]); err != nil {
+		return
+	}
+
+	if match.Price, err = bigIntField(values, ev.Price); err != nil {
+		return
+	}
+	if match.Currency, err = addressField(values, ev.Currency); err != nil {
+		return
+	}
+	if match.Seller, err = addressField(values, ev.Seller); err != nil {
+		return
+	}
+	if match.Buyer, err = addressField(values, ev.Buyer); err != nil {
+		return
+	}
+	if match.Collection, err = addressField(values, ev.Collection); err != nil {
+		return
+	}
+	if ev.TokenID != "" {
+		if match.TokenID, err = bigIntField(values, ev.TokenID); err != nil {
+			return
+		}
+	}
+	if ev.Amount != "" {
+		match.Amount, err = bigIntField(values, ev.Amount)
+	}
+	return
+}
+
+func bigIntField(values map[string]interface{}, name string) (*big.Int, error) {
+	value, ok := values[name].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("field %s is not an uint", name)
+	}
+	return value, nil
+}
+
+func addressField(values map[string]interface{}, name string) (*common.Address, error) {
+	if name == "" {
+		return nil, nil
+	}
+	value, ok := values[name].(common.Address)
+	if !ok {
+		return nil, fmt.Errorf("field %s is not an address", name)
+	}
+	return &value, nil
+}
+
+// findNFTTransfers returns all ERC721 Transfer and ERC1155 TransferSingle and TransferBatch logs in @logs. ERC721
+// contracts with unindexed token id cannot be told apart from ERC20 transfers and are left out.
+func findNFTTransfers(logs []*types.Log) (transfers []nftTransfer) {
+	for _, txLog := range logs {
+		switch {
+		case len(txLog.Topics) == 4 && txLog.Topics[0] == transferEventID:
+			transfers = append(transfers, nftTransfer{
+				Standard: nftStandardERC721,
+				Contract: txLog.Address,
+				From:     common.BytesToAddress(txLog.Topics[1].Bytes()),
+				To:       common.BytesToAddress(txLog.Topics[2].Bytes()),
+				TokenID:  txLog.Topics[3].Big(),
+				Amount:   big.NewInt(1),
+			})
+		case len(txLog.Topics) == 4 && txLog.Topics[0] == transferSingleEventID && len(txLog.Data) == 64:
+			transfers = append(transfers, nftTransfer{
+				Standard: nftStandardERC1155,
+				Contract: txLog.Address,
+				From:     common.BytesToAddress(txLog.Topics[2].Bytes()),
+				To:       common.BytesToAddress(txLog.Topics[3].Bytes()),
+				TokenID:  new(big.Int).SetBytes(txLog.Data[:32]),
+				Amount:   new(big.Int).SetBytes(txLog.Data[32:]),
+			})
+		case len(txLog.Topics) == 4 && txLog.Topics[0] == transferBatchEventID:
+			batch, err := decodeTransferBatch(txLog)
+			if err != nil {
+				log.Warnf("unable to decode TransferBatch log(tx: %s, logIndex: %d): %s", txLog.TxHash.Hex(), txLog.Index, err.Error())
+				continue
+			}
+			transfers = append(transfers, batch...)
+		}
+	}
+	return
+}
+
+// nftTransferStandard returns the standard of the transfer in @transfers of the nft sold in @transfer.
+// Tokens without such a transfer are taken to be ERC721.
+func nftTransferStandard(transfers []nftTransfer, transfer nftTransfer) string {
+	for _, t := range transfers {
+		if t.Contract == transfer.Contract && t.TokenID.Cmp(transfer.TokenID) == 0 {
+			return t.Standard
+		}
+	}
+	return nftStandardERC721
+}
+
+// findERC20Payment returns the address of the ERC20 token transferred with amount @price in @logs.
+func findERC20Payment(logs []*types.Log, price *big.Int) (common.Address, bool) {
+	var tokens []common.Address
+	for _, txLog := range logs {
+		if len(txLog.Topics) == 3 && txLog.Topics[0] == transferEventID && len(txLog.Data) == 32 && new(big.Int).SetBytes(txLog.Data).Cmp(price) == 0 {
+			tokens = append(tokens, txLog.Address)
+		}
+	}
+	if len(tokens) != 1 {
+		return common.Address{}, false
+	}
+	return tokens[0], true
+}
+
+// selectNFTTransfers returns the transfers in @transfers compatible with @match.
+func selectNFTTransfers(transfers []nftTransfer, match nftMarketplaceMatch) (candidates []nftTransfer) {
+	for _, transfer := range transfers {
+		if match.Seller != nil && transfer.From != *match.Seller {
+			continue
+		}
+		if match.Buyer != nil && transfer.To != *match.Buyer {
+			continue
+		}
+		if match.Collection != nil && transfer.Contract != *match.Collection {
+			continue
+		}
+		if match.TokenID != nil && transfer.TokenID.Cmp(match.TokenID) != 0 {
+			continue
+		}
+		candidates = append(candidates, transfer)
+	}
+	return
+}
+
+// NewGenericScraper returns a scraper for the marketplace described by @conf. @ds is used for
+// the usd prices of the trades and may be nil.
+func NewGenericScraper(rdb *models.RelDB, ds models.Datastore, conf NFTMarketplaceConfig) *GenericScraper {
+	ctx := context.Background()
+
+	eth, err := ethclient.Dial(utils.Getenv("ETH_URI_REST", ""))
+	if err != nil {
+		log.Error("Error connecting Eth Client")
+	}
+
+	s := &GenericScraper{
+		datastore:  ds,
+		conf:       conf,
+		state:      &GenericScraperState{},
+		events:     make(map[common.Hash]*NFTMarketplaceEvent),
+		assetCache: make(map[string]dia.Asset),
+		tradeScraper: TradeScraper{
+			shutdown:      make(chan nothing),
+			shutdownDone:  make(chan nothing),
+			datastore:     rdb,
+			chanTrade:     make(chan dia.NFTTrade),
+			source:        conf.Name,
+			ethConnection: eth,
+		},
+	}
+	for i := range s.conf.Events {
+		s.events[s.conf.Events[i].event.ID] = &s.conf.Events[i]
+	}
+
+	if err := s.initScraper(ctx); err != nil {
+		log.Errorf("%s scraper could not be initialized: %s", conf.Name, err.Error())
+		return nil
+	}
+
+	go s.mainLoop()
+
+	return s
+}
+
+// init scraper
+// if there is no state stored previously, start from the configured block
+func (s *GenericScraper) initScraper(ctx context.Context) error {
+	if err := s.loadState(ctx); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Errorf("unable to read scraper state from rdb: %s", err.Error())
+			return err
+		}
+		s.state = &GenericScraperState{LastBlockNum: s.conf.StartBlock}
+		return s.storeState(ctx)
+	}
+	return nil
+}
+
+func (s *GenericScraper) loadState(ctx context.Context) error {
+	return s.tradeScraper.datastore.GetScraperState(ctx, s.conf.Name, s.state)
+}
+
+func (s *GenericScraper) storeState(ctx context.Context) error {
+	return s.tradeScraper.datastore.SetScraperState(ctx, s.conf.Name, s.state)
+}
+
+func (s *GenericScraper) mainLoop() {
+	defer func() {
+		s.tradeScraper.closed = true
+
+		close(s.tradeScraper.chanTrade)
+		close(s.tradeScraper.shutdownDone)
+	}()
+
+	waitPeriod := time.Duration(s.conf.WaitPeriodSeconds) * time.Second
+	log.Infof("%s scraper has been started (batch: %d, period: %s)", s.conf.Name, s.conf.BatchSize, waitPeriod.String())
+
+	for stop := false; !stop; {
+		if err := s.FetchTrades(); err != nil {
+			if errors.Is(err, errGenericShutdownRequest) {
+				stop = true
+				continue
+			}
+		}
+
+		log.Debugf("wait for %s", waitPeriod)
+
+		select {
+		case <-time.After(waitPeriod):
+		case <-s.tradeScraper.shutdown:
+			stop = true
+		}
+	}
+}
+
+// FetchTrades searches for trades on-chain by the next block range
+func (s *GenericScraper) FetchTrades() error {
+	var err error
+
+	ctx := context.Background()
+
+	// it must be run once at a time
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.loadState(ctx); err != nil {
+		log.Warnf("unable to load scraper state: %s", err.Error())
+		return err
+	}
+
+	log.Infof("fetching %s trade transactions from block %d(+%d)", s.conf.Name, s.state.LastBlockNum, s.conf.BatchSize)
+
+	criteria := utils.EthTxFilterCriteria{
+		StartBlockNum:      s.state.LastBlockNum,
+		StartTxIndex:       s.state.LastTxIndex,
+		LimitBlocks:        s.conf.BatchSize,
+		BehindHighestBlock: s.conf.FollowDist,
+	}
+	for id := range s.events {
+		criteria.EvAddrs = append(criteria.EvAddrs, common.HexToAddress(s.conf.ContractAddr))
+		criteria.Events = append(criteria.Events, id)
+	}
+	res, err := utils.EthFilterTXs(ctx, s.tradeScraper.ethConnection, criteria)
+	if err != nil {
+		log.Warnf("unable to filter %s trades: %s", s.conf.Name, err.Error())
+		return err
+	}
+
+	log.Infof("found %d trade(logs: %d) transactions in %d blocks(from %d [tx index offset: %d] to %d, sync: %t[stay behind: -%d]), exploring details...", res.NumTXs, res.NumLogs, res.NumBlocks, s.state.LastBlockNum, s.state.LastTxIndex, res.LastBlockNum, res.Synced, s.conf.FollowDist)
+
+	numTrades := 0
+
+	// process trade transactions
+	for _, tx := range res.TXs {
+		s.state.LastBlockNum = tx.BlockNum
+		s.state.LastTxIndex = tx.TXIndex
+		s.state.LastErr = ""
+
+		n, err := s.processTx(ctx, tx)
+		if err != nil {
+			if errors.Is(err, errGenericShutdownRequest) {
+				return err
+			}
+			s.state.ErrCounter++
+
+			if s.state.ErrCounter <= s.conf.MaxRetry {
+				s.state.LastErr = fmt.Sprintf("unable to process trade transaction(%s): %s", tx.TXHash.Hex(), err.Error())
+				log.Error(s.state.LastErr)
+				if err := s.storeState(ctx); err != nil {
+					log.Warnf("unable to store scraper state: %s", err.Error())
+					return err
+				}
+				return err
+			}
+
+			log.Warnf("SKIPPING PERMANENTLY! block: %d, tx index: %d - error: %s", s.state.LastBlockNum, s.state.LastTxIndex, err.Error())
+		}
+		numTrades += n
+
+		// reset consecutive error counter
+		s.state.ErrCounter = 0
+
+		// move next
+		s.state.LastTxIndex = tx.TXIndex + 1
+
+		if err := s.storeState(ctx); err != nil {
+			log.Warnf("unable to store scraper state: %s", err.Error())
+			return err
+		}
+	}
+
+	s.state.LastBlockNum = res.LastBlockNum + 1
+	s.state.LastTxIndex = 0
+
+	if err := s.storeState(ctx); err != nil {
+		log.Warnf("unable to store scraper state: %s", err.Error())
+		return err
+	}
+
+	log.Infof("processed %d trades", numTrades)
+
+	return nil
+}
+
+// processTx emits a trade for each match event in @tx and returns the number of trades.
+func (s *GenericScraper) processTx(ctx context.Context, tx *utils.EthFilteredTx) (int, error) {
+	log.Tracef("process tx -> block: %d, tx index: %d, tx hash: %s", tx.BlockNum, tx.TXIndex, tx.TXHash.Hex())
+
+	var receipt *types.Receipt
+	var txValue *big.Int
+	numTrades := 0
+
+	for _, txLog := range tx.Logs {
+		if len(txLog.Topics) == 0 {
+			continue
+		}
+		ev, ok := s.events[txLog.Topics[0]]
+		if !ok {
+			continue
+		}
+		match, err := ev.decode(txLog)
+		if err != nil {
+			log.Errorf("unable to decode %s event(tx: %s, logIndex: %d) (SKIPPED!): %s", ev.event.Name, tx.TXHash, txLog.Index, err.Error())
+			continue
+		}
+
+		// Transfers of the transaction are only needed if the event does not identify nft and currency.
+		needsTransfers := match.Collection == nil || match.TokenID == nil || match.Seller == nil || match.Buyer == nil
+		if receipt == nil && (needsTransfers || ev.Standard == "" || match.Currency == nil) {
+			receipt, err = s.tradeScraper.ethConnection.TransactionReceipt(ctx, tx.TXHash)
+			if err != nil {
+				log.Errorf("unable to read transaction(%s) receipt: %s", tx.TXHash, err.Error())
+				return numTrades, err
+			}
+			txData, _, err := s.tradeScraper.ethConnection.TransactionByHash(ctx, tx.TXHash)
+			if err != nil {
+				log.Errorf("unable to read transaction(%s): %s", tx.TXHash, err.Error())
+				return numTrades, err
+			}
+			txValue = txData.Value()
+		}
+
+		transfer := nftTransfer{Standard: ev.Standard, Amount: big.NewInt(1)}
+		if match.Amount != nil {
+			transfer.Amount = match.Amount
+		}
+		if match.Collection != nil {
+			transfer.Contract = *match.Collection
+		}
+		if match.TokenID != nil {
+			transfer.TokenID = match.TokenID
+		}
+		if match.Seller != nil {
+			transfer.From = *match.Seller
+		}
+		if match.Buyer != nil {
+			transfer.To = *match.Buyer
+		}
+		if !needsTransfers && transfer.Standard == "" {
+			transfer.Standard = nftTransferStandard(findNFTTransfers(receipt.Logs), transfer)
+		}
+		transfers := []nftTransfer{transfer}
+		if needsTransfers {
+			transfers = selectNFTTransfers(findNFTTransfers(receipt.Logs), match)
+			// Several transfers are a bundle sold by the event, unless the event names the token or the
+			// transaction holds further match events the transfers could belong to.
+			if len(transfers) == 0 || (len(transfers) > 1 && (match.TokenID != nil || s.numMatchEvents(tx) > 1)) {
+				log.Tracef("event(block: %d, tx: %s) skipped due to it has no unique nft transfer", txLog.BlockNumber, tx.TXHash.Hex())
+				continue
+			}
+		}
+
+		currency := common.Address{}
+		if match.Currency != nil {
+			currency = *match.Currency
+		} else if txValue.Sign() == 0 {
+			if currency, ok = findERC20Payment(receipt.Logs, match.Price); !ok {
+				log.Tracef("event(block: %d, tx: %s) skipped due to it has no unique payment", txLog.BlockNumber, tx.TXHash.Hex())
+				continue
+			}
+		}
+
+		if err := s.notifyTrades(ctx, txLog, transfers, match.Price, currency); err != nil {
+			if !errors.Is(err, errGenericShutdownRequest) {
+				log.Warnf("event(block: %d, tx index: %d, tx: %s) couldn't processed: %s", txLog.BlockNumber, txLog.TxIndex, txLog.TxHash.Hex(), err.Error())
+			}
+			return numTrades, err
+		}
+		numTrades += len(transfers)
+	}
+
+	return numTrades, nil
+}
+
+// numMatchEvents returns the number of logs in @tx that are match events of the marketplace.
+func (s *GenericScraper) numMatchEvents(tx *utils.EthFilteredTx) (count int) {
+	for _, txLog := range tx.Logs {
+		if len(txLog.Topics) > 0 && s.events[txLog.Topics[0]] != nil {
+			count++
+		}
+	}
+	return
+}
+
+// notifyTrades emits a trade for each of the @transfers sold by the event @txLog. If several
+// transfers are sold together @price is allocated across them by allocateBundlePrice.
+func (s *GenericScraper) notifyTrades(ctx context.Context, txLog types.Log, transfers []nftTransfer, price *big.Int, currencyAddr common.Address) error {
+	// Get block time.
+	timestamp, err := ethhelper.GetBlockTimeEth(int64(txLog.BlockNumber), s.tradeScraper.datastore, s.tradeScraper.ethConnection)
+	if err != nil {
+		log.Errorf("getting block time: %+v", err)
+	}
+
+	currency := s.currency(ctx, currencyAddr, txLog.BlockNumber)
+
+	amounts := make([]*big.Int, len(transfers))
+	for i, transfer := range transfers {
+		amounts[i] = transfer.Amount
+	}
+	prices := allocateBundlePrice(price, amounts)
+
+	var bundleID string
+	if len(transfers) > 1 {
+		bundleID = nftBundleID(txLog.TxHash, txLog.Index)
+	}
+
+	for i, transfer := range transfers {
+		nftClass, err := s.createOrReadNFTClass(ctx, transfer, txLog.BlockNumber)
+		if err != nil {
+			return err
+		}
+
+		nft, err := s.createOrReadNFT(ctx, nftClass, transfer, txLog.BlockNumber)
+		if err != nil {
+			return err
+		}
+
+		trade := dia.NFTTrade{
+			NFT:         *nft,
+			Price:       prices[i],
+			PriceUSD:    s.calcUSDPrice(currency, prices[i], timestamp),
+			FromAddress: transfer.From.Hex(),
+			ToAddress:   transfer.To.Hex(),
+			Currency:    currency,
+			BlockNumber: txLog.BlockNumber,
+			Timestamp:   timestamp,
+			TxHash:      txLog.TxHash.Hex(),
+			Exchange:    s.conf.Name,
+			Quantity:    nftQuantity(transfer.Amount),
+			BundleID:    bundleID,
+			BundleSize:  len(transfers),
+		}
+
+		log.Debugf("found trade: %v", trade)
+
+		// handle close request if the chanTrade not consumed immediately
+		select {
+		case s.tradeScraper.chanTrade <- trade:
+		case <-s.tradeScraper.shutdown:
+			return errGenericShutdownRequest
+		}
+	}
+
+	return nil
+}
+
+// currency returns the asset with @address, the zero address denoting the native currency. Assets
+// unknown to the asset table are completed from the ERC20 metadata.
+func (s *GenericScraper) currency(ctx context.Context, address common.Address, blockNumber uint64) dia.Asset {
+	key := s.conf.Blockchain + "-" + address.Hex()
+	if asset, ok := s.assetCache[key]; ok {
+		return asset
+	}
+	if address == (common.Address{}) {
+		asset := s.nativeCurrency()
+		s.assetCache[key] = asset
+		return asset
+	}
+	asset, err := s.tradeScraper.datastore.GetAsset(address.Hex(), s.conf.Blockchain)
+	if err != nil {
+		log.Warnf("cannot fetch asset %s -- %s: %v", s.conf.Blockchain, address.Hex(), err)
+		asset = dia.Asset{Address: address.Hex(), Blockchain: s.conf.Blockchain}
+		if metadata, err := erc20.NewERC20Metadata(address, s.tradeScraper.ethConnection); err == nil {
+			callOpts := s.callOpts(ctx, blockNumber)
+			if symbol, err := metadata.Symbol(callOpts); err == nil {
+				asset.Symbol = symbol
+			}
+			if decimals, err := metadata.Decimals(callOpts); err == nil {
+				asset.Decimals = decimals
+			}
+		}
+	}
+	s.assetCache[key] = asset
+	return asset
+}
+
+// nativeCurrency returns the native coin of the blockchain of the marketplace, which payments from the zero
+// address are made in. It is the native token of the blockchain in the blockchain table.
+func (s *GenericScraper) nativeCurrency() dia.Asset {
+	blockchain, err := s.tradeScraper.datastore.GetBlockchain(s.conf.Blockchain)
+	if err != nil {
+		// The native coins of all EVM chains have the 18 decimals of Ether.
+		log.Warnf("cannot fetch native token of %s: %v", s.conf.Blockchain, err)
+		return dia.Asset{Address: common.Address{}.Hex(), Decimals: 18, Blockchain: s.conf.Blockchain}
+	}
+	asset, err := s.tradeScraper.datastore.GetAsset(blockchain.NativeToken.Address, s.conf.Blockchain)
+	if err != nil {
+		log.Warnf("cannot fetch native token %s of %s: %v", blockchain.NativeToken.Symbol, s.conf.Blockchain, err)
+		return dia.Asset{Symbol: blockchain.NativeToken.Symbol, Address: blockchain.NativeToken.Address, Decimals: 18, Blockchain: s.conf.Blockchain}
+	}
+	return asset
+}
+
+// calcUSDPrice returns the usd value of @price units of @currency at @timestamp, or zero if no quotation is available.
+func (s *GenericScraper) calcUSDPrice(currency dia.Asset, price *big.Int, timestamp time.Time) float64 {
+	if s.datastore == nil {
+		return 0
+	}
+	quotation, err := s.datastore.GetAssetQuotation(currency, timestamp)
+	if err != nil {
+		log.Warnf("no quotation for %s at %v: %v", currency.Symbol, timestamp, err)
+		return 0
+	}
+	normPrice := decimal.NewFromBigInt(price, -int32(currency.Decimals))
+	f, _ := normPrice.Mul(decimal.NewFromFloat(quotation.Price)).Float64()
+	return f
+}
+
+func (s *GenericScraper) callOpts(ctx context.Context, blockNumber uint64) *bind.CallOpts {
+	callOpts := &bind.CallOpts{Context: ctx}
+	if s.conf.UseArchiveNode {
+		callOpts.BlockNumber = new(big.Int).SetUint64(blockNumber)
+	}
+	return callOpts
+}
+
+func (s *GenericScraper) createOrReadNFTClass(ctx context.Context, transfer nftTransfer, blockNumber uint64) (*dia.NFTClass, error) {
+	nftClass, err := s.tradeScraper.datastore.GetNFTClass(transfer.Contract.Hex(), s.conf.Blockchain)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Warnf("unable to read nftclass from reldb: %s", err.Error())
+			return nil, err
+		}
+
+		nftClass = dia.NFTClass{
+			Address:      transfer.Contract.Hex(),
+			Blockchain:   s.conf.Blockchain,
+			ContractType: transfer.Standard,
+		}
+
+		if md, err := erc721.NewERC721Metadata(transfer.Contract, s.tradeScraper.ethConnection); err == nil {
+			callOpts := s.callOpts(ctx, blockNumber)
+			if name, err := md.Name(callOpts); err == nil {
+				nftClass.Name = name
+			}
+			if symbol, err := md.Symbol(callOpts); err == nil {
+				nftClass.Symbol = symbol
+			}
+		}
+
+		if err = s.tradeScraper.datastore.SetNFTClass(nftClass); err != nil {
+			log.Warnf("unable to create nftclass on reldb: %s", err.Error())
+			return nil, err
+		}
+	}
+
+	return &nftClass, nil
+}
+
+func (s *GenericScraper) createOrReadNFT(ctx context.Context, nftClass *dia.NFTClass, transfer nftTransfer, blockNumber uint64) (*dia.NFT, error) {
+	nft, err := s.tradeScraper.datastore.GetNFT(nftClass.Address, s.conf.Blockchain, transfer.TokenID.String())
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Warnf("unable to read nft from reldb: %s", err.Error())
+			return nil, err
+		}
+
+		nft = dia.NFT{
+			NFTClass: *nftClass,
+			TokenID:  transfer.TokenID.String(),
+		}
+
+		// ERC1155 token uris are not read, their uri function differs from ERC721's tokenURI.
+		if transfer.Standard == nftStandardERC721 {
+			if md, err := erc721.NewERC721Metadata(transfer.Contract, s.tradeScraper.ethConnection); err == nil {
+				if tokenURI, err := md.TokenURI(s.callOpts(ctx, blockNumber), transfer.TokenID); err != nil {
+					log.Warnf("unable to find token(%s) uri: %s", transfer.TokenID.String(), err.Error())
+				} else if attrs, err := s.readNFTAttr(ctx, tokenURI); err != nil {
+					log.Warnf("unable to read token(%s) attributes: %s", transfer.TokenID.String(), err.Error())
+				} else {
+					nft.URI = tokenURI
+					nft.Attributes = attrs
+				}
+			}
+		}
+
+		if err = s.tradeScraper.datastore.SetNFT(nft); err != nil {
+			log.Warnf("unable to create nft on reldb: %s", err.Error())
+			return nil, err
+		}
+	}
+
+	return &nft, nil
+}
+
+func (s *GenericScraper) readNFTAttr(ctx context.Context, uri string) (map[string]interface{}, error) {
+	if uri == "" || strings.HasPrefix(uri, "ipfs://") {
+		return nil, nil
+	}
+
+	attrs := make(map[string]interface{})
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.conf.MetadataTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.New("unable to read token attributes: " + resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, int64(s.conf.MaxMetadataSize))).Decode(&attrs); err != nil {
+		return nil, err
+	}
+
+	return attrs, nil
+}
+
+// GetTradeChannel returns the scrapers data channel.
+func (s *GenericScraper) GetTradeChannel() chan dia.NFTTrade {
+	return s.tradeScraper.chanTrade
+}
+
+func (s *GenericScraper) Close() error {
+	if s.tradeScraper.closed {
+		return errors.New("scraper already closed")
+	}
+
+	close(s.tradeScraper.shutdown)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package nfttradescrapers
+
+import (
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The configs in testdata describe marketplaces with dedicated scrapers. They are not shipped in the
+// config directory, as the generic scraper would then compete with the dedicated ones.
+func TestGenericMarketplaceConfigs(t *testing.T) {
+	for _, name := range []string{"LooksRare", "OpenSea"} {
+		data, err := ioutil.ReadFile("testdata/" + name + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf, err := parseNFTMarketplaceConfig(data)
+		if err != nil {
+			t.Errorf("config of %s is invalid: %v", name, err)
+			continue
+		}
+		if conf.Name != name || len(conf.Events) == 0 {
+			t.Errorf("config of %s was incorrect, got: %+v.", name, conf)
+		}
+	}
+
+	if _, err := parseNFTMarketplaceConfig([]byte(`{"name":"x","contract_addr":"0x59728544B08AB483533076417FbBB2fD0B17CE3a","events":[{"abi":{"type":"event","name":"Sale","inputs":[{"name":"amount","type":"uint256"}]},"price":"price"}]}`)); err == nil {
+		t.Error("expected error for unknown price field")
+	}
+}
+
+func TestGenericDecodeMatch(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/LooksRare.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf, err := parseNFTMarketplaceConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	takerBid := conf.Events[1]
+
+	taker := common.HexToAddress("0x1")
+	maker := common.HexToAddress("0x2")
+	weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	collection := common.HexToAddress("0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D")
+	price := new(big.Int).Mul(big.NewInt(85), big.NewInt(1e17))
+
+	logData, err := takerBid.event.Inputs.NonIndexed().Pack([32]byte{}, big.NewInt(7), weth, collection, big.NewInt(42), big.NewInt(3), price)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txLog := types.Log{
+		Topics: []common.Hash{takerBid.event.ID, taker.Hash(), maker.Hash(), common.HexToAddress("0x3").Hash()},
+		Data:   logData,
+	}
+
+	match, err := takerBid.decode(txLog)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if match.Price.Cmp(price) != 0 || *match.Currency != weth || *match.Seller != maker || *match.Buyer != taker || *match.Collection != collection || match.TokenID.Int64() != 42 || match.Amount.Int64() != 3 {
+		t.Errorf("match was incorrect, got: %+v.", match)
+	}
+}
+
+func TestGenericFindTransfers(t *testing.T) {
+	seller := common.HexToAddress("0xa")
+	buyer := common.HexToAddress("0xb")
+	nft := common.HexToAddress("0xc")
+	token := common.HexToAddress("0xd")
+	price := big.NewInt(5000)
+
+	logs := []*types.Log{
+		// ERC20 payment and ERC721 transfer.
+		{Address: token, Topics: []common.Hash{transferEventID, buyer.Hash(), seller.Hash()}, Data: common.LeftPadBytes(price.Bytes(), 32)},
+		{Address: nft, Topics: []common.Hash{transferEventID, seller.Hash(), buyer.Hash(), common.BigToHash(big.NewInt(9))}},
+		// ERC1155 transfer of 3 items of token 5 to another address.
+		{Address: nft, Topics: []common.Hash{transferSingleEventID, seller.Hash(), seller.Hash(), common.HexToAddress("0xe").Hash()}, Data: append(common.LeftPadBytes([]byte{5}, 32), common.LeftPadBytes([]byte{3}, 32)...)},
+	}
+
+	transfers := findNFTTransfers(logs)
+	if len(transfers) != 2 || transfers[1].Standard != nftStandardERC1155 || transfers[1].TokenID.Int64() != 5 || transfers[1].Amount.Int64() != 3 {
+		t.Fatalf("transfers were incorrect, got: %+v.", transfers)
+	}
+	selected := selectNFTTransfers(transfers, nftMarketplaceMatch{Price: price, Buyer: &buyer})
+	if len(selected) != 1 || selected[0].Standard != nftStandardERC721 || selected[0].TokenID.Int64() != 9 {
+		t.Errorf("selected transfers were incorrect, got: %+v.", selected)
+	}
+	if selected = selectNFTTransfers(transfers, nftMarketplaceMatch{Price: price}); len(selected) != 2 {
+		t.Errorf("number of selected transfers was incorrect, got: %v, want: %v.", len(selected), 2)
+	}
+	if standard := nftTransferStandard(transfers, nftTransfer{Contract: nft, TokenID: big.NewInt(5)}); standard != nftStandardERC1155 {
+		t.Errorf("standard was incorrect, got: %s, want: %s.", standard, nftStandardERC1155)
+	}
+	if standard := nftTransferStandard(transfers, nftTransfer{Contract: nft, TokenID: big.NewInt(6)}); standard != nftStandardERC721 {
+		t.Errorf("standard was incorrect, got: %s, want: %s.", standard, nftStandardERC721)
+	}
+	if currency, ok := findERC20Payment(logs, price); !ok || currency != token {
+		t.Errorf("payment was incorrect, got: %s.", currency.Hex())
+	}
+}
@@ -30,7 +30,7 @@ import (
 )
 
 const (
-	// we assume all of the NFTs traded on LooksRare are ERC721(1155 is an extension of it)
+	// contract type of NFTs whose transfer doesn't tell the standard
 	looksRareNFTContractType = "ERC721"
 
 	LooksRare = "LooksRare"
@@ -92,6 +92,7 @@ type LooksRareScraper struct {
 }
 
 type erc721Metadata struct {
+	Standard   string
 	NFTAddress common.Address
 	Name       *string
 	Symbol     *string
@@ -439,6 +440,21 @@ func (s *LooksRareScraper) processTx(ctx context.Context, tx *utils.EthFilteredT
 		return false, err
 	}
 
+	receipt, err := s.tradeScraper.ethConnection.TransactionReceipt(ctx, tx.TXHash)
+	if err != nil {
+		log.Errorf("unable to read transaction(%s) receipt: %s", tx.TXHash, err.Error())
+		return false, err
+	}
+
+	// the collection is an erc1155 contract if the token is transferred by TransferSingle or TransferBatch
+	erc721.Standard = nftStandardERC721
+	for _, transfer := range findNFTTransfers(receipt.Logs) {
+		if transfer.Standard == nftStandardERC1155 && transfer.Contract == ev.Collection && transfer.TokenID.Cmp(ev.TokenId) == 0 {
+			erc721.Standard = nftStandardERC1155
+			break
+		}
+	}
+
 	normPrice := decimal.NewFromBigInt(ev.Price, 0).Div(decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(currDecimals))))
 
 	usdPrice, err := s.calcUSDPrice(ev.Raw.BlockNumber, currAddr, currSymbol, normPrice)
@@ -513,6 +529,7 @@ func (s *LooksRareScraper) notifyTrade(ev *looksRareTakerBidAskEvent, erc721 *er
 		Timestamp:   timestamp,
 		TxHash:      ev.Raw.TxHash.Hex(),
 		Exchange:    "LooksRare",
+		Quantity:    nftQuantity(ev.Amount),
 	}
 
 	if asset, ok := assetCacheLooksrare[dia.ETHEREUM+"-"+currAddr.Hex()]; ok {
@@ -552,6 +569,10 @@ func (s *LooksRareScraper) createOrReadNFTClass(erc721 *erc721Metadata) (*dia.NF
 			ContractType: looksRareNFTContractType,
 		}
 
+		if erc721.Standard != "" {
+			nftClass.ContractType = erc721.Standard
+		}
+
 		if erc721.Name != nil {
 			nftClass.Name = *erc721.Name
 		}
@@ -31,7 +31,7 @@ import (
 )
 
 const (
-	// we assume all of the NFTs traded on OpenSea are ERC721(1155 is an extension of it)
+	// contract type of NFTs whose transfer doesn't tell the standard
 	openSeaNFTContractType = "ERC721"
 )
 
@@ -99,7 +99,9 @@ type erc20Transfer struct {
 	Decimals    int
 }
 
+// erc721Transfer is an ERC721 transfer or an ERC1155 transfer of Amount items of TokenID.
 type erc721Transfer struct {
+	Standard    string
 	NFTAddress  common.Address
 	Name        *string
 	Symbol      *string
@@ -107,6 +109,7 @@ type erc721Transfer struct {
 	From        common.Address
 	To          common.Address
 	TokenID     *big.Int
+	Amount      *big.Int
 	TokenURI    *string
 	TokenAttrs  map[string]interface{}
 }
@@ -203,7 +206,7 @@ func NewOpenSeaScraper(rdb *models.RelDB) *OpenSeaScraper {
 		return nil
 	}
 
-	log.Info("scraper %s starts at block: %s", OpenSea, s.state.LastBlockNum)
+	log.Infof("scraper %s starts at block: %d", OpenSea, s.state.LastBlockNum)
 	time.Sleep(2 * time.Minute)
 	go s.mainLoop()
 
@@ -440,49 +443,83 @@ func (s *OpenSeaScraper) processTx(ctx context.Context, tx *utils.EthFilteredTx)
 		}
 	}
 
-	transfers, err := s.findERC721Transfers(ctx, receipt)
+	erc721Transfers, err := s.findERC721Transfers(ctx, receipt)
 	if err != nil {
 		log.Errorf("unable to find transfers of the event(block: %d, tx index: %d, tx: %s): %s", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex(), err.Error())
 		return false, err
 	}
 
+	// erc20 transfers of the payment currency comply to the compat transfer event and are left out
+	transfers := make([]*erc721Transfer, 0, len(erc721Transfers))
+	for _, transfer := range erc721Transfers {
+		if currAddr != (common.Address{}) && transfer.NFTAddress == currAddr {
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	transfers = append(transfers, s.findERC1155Transfers(ctx, receipt)...)
+
 	// skip if the event has no transfer
 	if len(transfers) == 0 {
-		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it has no erc721 transfer log", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
+		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it has no nft transfer log", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
 		return true, nil
 	}
 
-	// skip if the event has multiple transfers due to we can't calculate the price of trade
+	// multiple transfers are a bundle sold by the order, all of its items are transferred between maker and taker
 	if len(transfers) > 1 {
-		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it has multiple erc721 transfer logs", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
-		return true, nil
+		for _, transfer := range transfers {
+			if !isOpenSeaCounterparty(ev, transfer.From) || !isOpenSeaCounterparty(ev, transfer.To) {
+				log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it has nft transfers to third parties", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
+				return true, nil
+			}
+		}
 	}
 
-	if transfers[0].NFTAddress == common.HexToAddress("0xA5c807A62CD6774d6BF518dD2dEc0aE17446Ad8d") {
-		log.Warnf("skip class %s because of decoding error.", "0xA5c807A62CD6774d6BF518dD2dEc0aE17446Ad8d")
-		return true, nil
+	for _, transfer := range transfers {
+		if transfer.NFTAddress == common.HexToAddress("0xA5c807A62CD6774d6BF518dD2dEc0aE17446Ad8d") {
+			log.Warnf("skip class %s because of decoding error.", "0xA5c807A62CD6774d6BF518dD2dEc0aE17446Ad8d")
+			return true, nil
+		}
 	}
 
-	normPrice := decimal.NewFromBigInt(ev.Price, 0).Div(decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(currDecimals))))
+	amounts := make([]*big.Int, len(transfers))
+	for i, transfer := range transfers {
+		amounts[i] = transfer.Amount
+	}
+	prices := allocateBundlePrice(ev.Price, amounts)
 
-	usdPrice, err := s.calcUSDPrice(ev.Raw.BlockNumber, currAddr, currSymbol, normPrice)
-	if err != nil {
-		log.Errorf("unable to calculate usd price of the event(block: %d, log: %d, tx: %s): %s", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex(), err.Error())
-		return false, err
+	var bundleID string
+	if len(transfers) > 1 {
+		bundleID = nftBundleID(ev.Raw.TxHash, ev.Raw.Index)
 	}
 
-	if err := s.notifyTrade(ev, transfers[0], ev.Price, normPrice, usdPrice, currSymbol, currAddr); err != nil {
-		if !errors.Is(err, errOpenSeaShutdownRequest) {
-			log.Warnf("event(block: %d, tx index: %d, tx: %s) couldn't processed: %s", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex(), err.Error())
+	for i, transfer := range transfers {
+		normPrice := decimal.NewFromBigInt(prices[i], 0).Div(decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(currDecimals))))
+
+		usdPrice, err := s.calcUSDPrice(ev.Raw.BlockNumber, currAddr, currSymbol, normPrice)
+		if err != nil {
+			log.Errorf("unable to calculate usd price of the event(block: %d, log: %d, tx: %s): %s", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex(), err.Error())
+			return false, err
 		}
 
-		return false, err
+		if err := s.notifyTrade(ev, transfer, prices[i], normPrice, usdPrice, currSymbol, currAddr, bundleID, len(transfers)); err != nil {
+			if !errors.Is(err, errOpenSeaShutdownRequest) {
+				log.Warnf("event(block: %d, tx index: %d, tx: %s) couldn't processed: %s", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex(), err.Error())
+			}
+
+			return false, err
+		}
 	}
 
 	return false, nil
 }
 
-func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer *erc721Transfer, price *big.Int, priceDec decimal.Decimal, usdPrice float64, currSymbol string, currAddr common.Address) error {
+// isOpenSeaCounterparty returns true if @address is maker or taker of the order match @ev.
+func isOpenSeaCounterparty(ev *opensea.ContractOrdersMatched, address common.Address) bool {
+	return address == ev.Maker || address == ev.Taker
+}
+
+func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer *erc721Transfer, price *big.Int, priceDec decimal.Decimal, usdPrice float64, currSymbol string, currAddr common.Address, bundleID string, bundleSize int) error {
 	nftClass, err := s.createOrReadNFTClass(transfer)
 	if err != nil {
 		return err
@@ -509,6 +546,9 @@ func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer
 		Timestamp:   timestamp,
 		TxHash:      ev.Raw.TxHash.Hex(),
 		Exchange:    "OpenSea",
+		Quantity:    nftQuantity(transfer.Amount),
+		BundleID:    bundleID,
+		BundleSize:  bundleSize,
 	}
 
 	if asset, ok := assetCacheOpensea[dia.ETHEREUM+"-"+currAddr.Hex()]; ok {
@@ -548,6 +588,10 @@ func (s *OpenSeaScraper) createOrReadNFTClass(transfer *erc721Transfer) (*dia.NF
 			ContractType: openSeaNFTContractType,
 		}
 
+		if transfer.Standard != "" {
+			nftClass.ContractType = transfer.Standard
+		}
+
 		if transfer.Name != nil {
 			nftClass.Name = *transfer.Name
 		}
@@ -833,10 +877,12 @@ func (s *OpenSeaScraper) findERC721Transfers(ctx context.Context, receipt *types
 		}
 
 		transfer := &erc721Transfer{
+			Standard:   nftStandardERC721,
 			NFTAddress: txLog.Address,
 			From:       transferLog.From,
 			To:         transferLog.To,
 			TokenID:    transferLog.TokenId,
+			Amount:     big.NewInt(1),
 			TokenAttrs: make(map[string]interface{}),
 		}
 
@@ -877,6 +923,48 @@ func (s *OpenSeaScraper) findERC721Transfers(ctx context.Context, receipt *types
 	return transfers, nil
 }
 
+// it finds the TransferSingle and TransferBatch events of ERC1155 in the given transaction
+func (s *OpenSeaScraper) findERC1155Transfers(ctx context.Context, receipt *types.Receipt) []*erc721Transfer {
+	transfers := make([]*erc721Transfer, 0)
+
+	for _, t := range findNFTTransfers(receipt.Logs) {
+		if t.Standard != nftStandardERC1155 {
+			continue
+		}
+
+		transfer := &erc721Transfer{
+			Standard:   nftStandardERC1155,
+			NFTAddress: t.Contract,
+			From:       t.From,
+			To:         t.To,
+			TokenID:    t.TokenID,
+			Amount:     t.Amount,
+			TokenAttrs: make(map[string]interface{}),
+		}
+
+		callOpts := &bind.CallOpts{Context: ctx}
+
+		if s.conf.UseArchiveNode {
+			callOpts.BlockNumber = receipt.BlockNumber
+		}
+
+		// name and symbol are optional for erc1155, token uris differ from erc721's tokenURI and are not read
+		if md, err := erc721.NewERC721Metadata(t.Contract, s.tradeScraper.ethConnection); err == nil {
+			if nftName, err := md.Name(callOpts); err == nil {
+				transfer.Name = &nftName
+			}
+
+			if nftSymbol, err := md.Symbol(callOpts); err == nil {
+				transfer.Symbol = &nftSymbol
+			}
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers
+}
+
 func (s *OpenSeaScraper) readNFTAttr(ctx context.Context, uri string) (map[string]interface{}, error) {
 	if uri == "" {
 		return nil, nil
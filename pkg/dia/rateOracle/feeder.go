@@ -0,0 +1,433 @@
+package rateoracle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/oraclehelper"
+	diaOracleServiceV2 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV2"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// rateOracleConfigDir is the folder in the config directory holding the feeder configs.
+	rateOracleConfigDir = "rateOracle/"
+
+	// ValueDecimals is the number of decimals of the rates written to the oracle. Rates are in percent.
+	ValueDecimals = 8
+
+	ReasonInitial   = oraclehelper.ReasonInitial
+	ReasonHeartbeat = oraclehelper.ReasonHeartbeat
+	ReasonDeviation = oraclehelper.ReasonDeviation
+
+	// eventBatchBlocks is the number of blocks whose oracle updates are read at once.
+	eventBatchBlocks = 5000
+)
+
+// Types of the values a Feed publishes.
+const (
+	// FeedDefiRate is the lending or borrowing rate of an asset on a DeFi lending protocol.
+	FeedDefiRate = "defiRate"
+	// FeedInterestRate is the latest published value of an interest rate such as SOFR.
+	FeedInterestRate = "interestRate"
+	// FeedCompoundedAvgDIA is the compounded average of an interest rate over Days calendar days
+	// following the DIA methodology, see /v1/compoundedAvgDIA.
+	FeedCompoundedAvgDIA = "compoundedAvgDIA"
+)
+
+// Fields of a DefiRate a Feed of type FeedDefiRate publishes.
+const (
+	FieldLendingRate   = "lendingRate"
+	FieldBorrowingRate = "borrowingRate"
+)
+
+var log = logrus.New()
+
+// ErrTxFailed is returned if the transaction of an update was mined but reverted.
+var ErrTxFailed = oraclehelper.ErrTxFailed
+
+// Config lists the feeds pushed by a Feeder. The update thresholds of a feed default to the ones of the config.
+type Config struct {
+	Feeds []Feed `json:"feeds"`
+	// An update is pushed if the value deviates by more than DeviationPermille from the last pushed value, but
+	// at least by MinDeviation percentage points, or if the last update is older than HeartbeatSeconds.
+	// MinDeviation keeps rates close to zero from being pushed on every small change.
+	DeviationPermille int     `json:"deviation_permille"`
+	MinDeviation      float64 `json:"min_deviation"`
+	HeartbeatSeconds  int64   `json:"heartbeat_seconds"`
+}
+
+// Feed is a rate stored under Key in the oracle contract. DefiRate feeds are given by Protocol, Asset and
+// Field, interest rate feeds by Symbol and, for compounded averages, by Days and DaysPerYear.
+type Feed struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Protocol    string `json:"protocol"`
+	Asset       string `json:"asset"`
+	Field       string `json:"field"`
+	Symbol      string `json:"symbol"`
+	Days        int    `json:"days"`
+	DaysPerYear int    `json:"days_per_year"`
+
+	DeviationPermille int     `json:"deviation_permille"`
+	MinDeviation      float64 `json:"min_deviation"`
+	HeartbeatSeconds  int64   `json:"heartbeat_seconds"`
+}
+
+// RateSource provides the rates, e.g. the DIA REST API.
+type RateSource interface {
+	DefiLendingRate(ctx context.Context, protocol, asset string, timestamp time.Time) (dia.DefiRate, error)
+	InterestRate(ctx context.Context, symbol string, date time.Time) (models.InterestRate, error)
+	CompoundedAvgDIA(ctx context.Context, symbol string, calDays, daysPerYear int, date time.Time) ([]models.InterestRate, error)
+}
+
+// Oracle is the key value oracle contract, see diaOracleServiceV2.DIAOracleV2. Values and timestamps are uint128.
+type Oracle interface {
+	GetValue(opts *bind.CallOpts, key string) (*big.Int, *big.Int, error)
+	SetValue(opts *bind.TransactOpts, key string, value *big.Int, timestamp *big.Int) (*types.Transaction, error)
+	FilterOracleUpdate(opts *bind.FilterOpts) (*diaOracleServiceV2.DIAOracleV2OracleUpdateIterator, error)
+}
+
+// Backend is the node the receipts of the transactions to the oracle and the times of its updates are read from.
+type Backend interface {
+	bind.DeployBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Update is a value pushed to the oracle.
+type Update struct {
+	Key   string
+	Value float64
+	// ValueTime is the time of the value at its source, which is written to the oracle along with it.
+	ValueTime time.Time
+	// Time is the time the update was pushed.
+	Time   time.Time
+	Reason string
+	TxHash string
+}
+
+// Feeder pushes the rates of the feeds in its config to an oracle contract.
+type Feeder struct {
+	conf    Config
+	source  RateSource
+	oracle  Oracle
+	backend Backend
+	auth    *bind.TransactOpts
+
+	// last holds the last pushed update by key, nil if the oracle holds no value.
+	last map[string]*Update
+}
+
+// LoadConfig reads the feeder config @name from the config directory.
+func LoadConfig(name string) (conf Config, err error) {
+	data, err := ioutil.ReadFile(configCollectors.ConfigFileConnectors(rateOracleConfigDir+name, ".json"))
+	if err != nil {
+		return
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig decodes and validates the feeder config in @data.
+func ParseConfig(data []byte) (conf Config, err error) {
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	if len(conf.Feeds) == 0 {
+		err = errors.New("no feeds in config")
+		return
+	}
+	keys := make(map[string]bool)
+	for _, feed := range conf.Feeds {
+		if err = feed.validate(); err != nil {
+			return
+		}
+		if keys[feed.Key] {
+			err = fmt.Errorf("duplicate key %s", feed.Key)
+			return
+		}
+		keys[feed.Key] = true
+	}
+	return
+}
+
+func (feed Feed) validate() error {
+	if feed.Key == "" {
+		return errors.New("feed needs a key")
+	}
+	switch feed.Type {
+	case FeedDefiRate:
+		if feed.Protocol == "" || feed.Asset == "" {
+			return fmt.Errorf("feed %s needs a protocol and an asset", feed.Key)
+		}
+		if feed.Field != FieldLendingRate && feed.Field != FieldBorrowingRate {
+			return fmt.Errorf("feed %s needs field %s or %s", feed.Key, FieldLendingRate, FieldBorrowingRate)
+		}
+	case FeedInterestRate:
+		if feed.Symbol == "" {
+			return fmt.Errorf("feed %s needs a symbol", feed.Key)
+		}
+	case FeedCompoundedAvgDIA:
+		if feed.Symbol == "" || feed.Days <= 0 || feed.DaysPerYear <= 0 {
+			return fmt.Errorf("feed %s needs a symbol, days and days per year", feed.Key)
+		}
+	default:
+		return fmt.Errorf("unknown type %q of feed %s", feed.Type, feed.Key)
+	}
+	return nil
+}
+
+// NewFeeder returns a feeder pushing the feeds in @conf to @oracle with transactions of @auth, whose receipts
+// are read from @backend.
+func NewFeeder(conf Config, source RateSource, oracle Oracle, backend Backend, auth *bind.TransactOpts) *Feeder {
+	return &Feeder{
+		conf:    conf,
+		source:  source,
+		oracle:  oracle,
+		backend: backend,
+		auth:    auth,
+		last:    make(map[string]*Update),
+	}
+}
+
+// Update pushes the value of each feed whose update is due at @timestamp and returns the pushed updates.
+// A failing feed does not stop the others, the last error is returned.
+func (f *Feeder) Update(ctx context.Context, timestamp time.Time) (updates []Update, err error) {
+	if err = f.loadLast(ctx, timestamp); err != nil {
+		return nil, err
+	}
+	for _, feed := range f.conf.Feeds {
+		update, feedErr := f.updateFeed(ctx, feed, timestamp)
+		if feedErr != nil {
+			log.Errorf("update %s: %v", feed.Key, feedErr)
+			err = feedErr
+			continue
+		}
+		if update != nil {
+			updates = append(updates, *update)
+		}
+	}
+	return
+}
+
+// updateFeed pushes the value of @feed if its update is due at @timestamp. It returns nil if no update was pushed.
+func (f *Feeder) updateFeed(ctx context.Context, feed Feed, timestamp time.Time) (*Update, error) {
+	last := f.last[feed.Key]
+	value, valueTime, err := f.value(ctx, feed, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if valueTime.IsZero() {
+		log.Warnf("no value for %s at %v, skip update", feed.Key, timestamp)
+		return nil, nil
+	}
+
+	update := Update{Key: feed.Key, Value: value, ValueTime: valueTime, Time: timestamp}
+	deviationPermille, minDeviation, heartbeat := f.thresholds(feed)
+	update.Reason = UpdateReason(last, update, deviationPermille, minDeviation, heartbeat)
+	if update.Reason == "" {
+		return nil, nil
+	}
+
+	encoded, err := EncodeValue(update.Value)
+	if err != nil {
+		return nil, fmt.Errorf("encode %v: %v", update.Value, err)
+	}
+	tx, err := f.oracle.SetValue(oraclehelper.TransactOpts(ctx, f.auth), update.Key, encoded, big.NewInt(update.ValueTime.Unix()))
+	if err != nil {
+		return nil, err
+	}
+	update.TxHash = tx.Hash().Hex()
+	// An update whose transaction is not mined is not marked as pushed, such that it is pushed again.
+	if err = oraclehelper.WaitMined(ctx, f.backend, tx); err != nil {
+		return nil, err
+	}
+	log.Infof("pushed %s (%s): %v at %v, tx %s", update.Key, update.Reason, update.Value, update.ValueTime, update.TxHash)
+	f.last[feed.Key] = &update
+	return &update, nil
+}
+
+// thresholds returns the deviation thresholds and the heartbeat of @feed, defaulting to the ones of the config.
+func (f *Feeder) thresholds(feed Feed) (deviationPermille int, minDeviation float64, heartbeat time.Duration) {
+	deviationPermille, minDeviation, heartbeatSeconds := feed.DeviationPermille, feed.MinDeviation, feed.HeartbeatSeconds
+	if deviationPermille == 0 {
+		deviationPermille = f.conf.DeviationPermille
+	}
+	if minDeviation == 0 {
+		minDeviation = f.conf.MinDeviation
+	}
+	if heartbeatSeconds == 0 {
+		heartbeatSeconds = f.conf.HeartbeatSeconds
+	}
+	return deviationPermille, minDeviation, time.Duration(heartbeatSeconds) * time.Second
+}
+
+// value returns the value of @feed at @timestamp along with its time at the source. The time is zero if
+// there is no value.
+func (f *Feeder) value(ctx context.Context, feed Feed, timestamp time.Time) (float64, time.Time, error) {
+	switch feed.Type {
+	case FeedDefiRate:
+		rate, err := f.source.DefiLendingRate(ctx, feed.Protocol, feed.Asset, timestamp)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		if feed.Field == FieldBorrowingRate {
+			return rate.BorrowingRate, rate.Timestamp, nil
+		}
+		return rate.LendingRate, rate.Timestamp, nil
+	case FeedInterestRate:
+		rate, err := f.source.InterestRate(ctx, feed.Symbol, timestamp)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		return rate.Value, rate.EffectiveDate, nil
+	case FeedCompoundedAvgDIA:
+		rates, err := f.source.CompoundedAvgDIA(ctx, feed.Symbol, feed.Days, feed.DaysPerYear, timestamp)
+		if err != nil || len(rates) == 0 {
+			return 0, time.Time{}, err
+		}
+		rate := rates[len(rates)-1]
+		return rate.Value, rate.EffectiveDate, nil
+	}
+	return 0, time.Time{}, fmt.Errorf("unknown type %q of feed %s", feed.Type, feed.Key)
+}
+
+// loadLast reads the last updates of the feeds without one from the oracle. Their push time is the block time
+// of their last OracleUpdate event, which is only searched back to the longest heartbeat before @timestamp.
+// Updates without an event in that range take the time of their value, as their heartbeat is due either way.
+func (f *Feeder) loadLast(ctx context.Context, timestamp time.Time) error {
+	pending := make(map[string]*Update)
+	var maxHeartbeat time.Duration
+	for _, feed := range f.conf.Feeds {
+		if _, ok := f.last[feed.Key]; ok {
+			continue
+		}
+		value, valueTimestamp, err := f.oracle.GetValue(&bind.CallOpts{Context: ctx}, feed.Key)
+		if err != nil {
+			return fmt.Errorf("read oracle value of %s: %v", feed.Key, err)
+		}
+		if valueTimestamp.Sign() == 0 {
+			f.last[feed.Key] = nil
+			continue
+		}
+		valueTime := time.Unix(valueTimestamp.Int64(), 0)
+		pending[feed.Key] = &Update{Key: feed.Key, Value: DecodeValue(value), ValueTime: valueTime, Time: valueTime}
+		if _, _, heartbeat := f.thresholds(feed); heartbeat > maxHeartbeat {
+			maxHeartbeat = heartbeat
+		}
+	}
+	if len(pending) > 0 && maxHeartbeat > 0 {
+		if err := f.readPushTimes(ctx, pending, timestamp.Add(-maxHeartbeat)); err != nil {
+			return err
+		}
+	}
+	for key, last := range pending {
+		f.last[key] = last
+	}
+	return nil
+}
+
+// readPushTimes sets the push time of the @pending updates by key to the block time of their last OracleUpdate
+// event. Blocks are read backwards from the head until all updates are found or the blocks are older than @since.
+func (f *Feeder) readPushTimes(ctx context.Context, pending map[string]*Update, since time.Time) error {
+	head, err := f.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("read head: %v", err)
+	}
+	found := make(map[string]bool)
+	for end := head.Number.Uint64(); ; {
+		start := uint64(0)
+		if end >= eventBatchBlocks {
+			start = end - eventBatchBlocks + 1
+		}
+		batchEnd := end
+		it, err := f.oracle.FilterOracleUpdate(&bind.FilterOpts{Start: start, End: &batchEnd, Context: ctx})
+		if err != nil {
+			return fmt.Errorf("read oracle updates of blocks %d to %d: %v", start, end, err)
+		}
+		// Events are in chain order, so the last event of a key in the batch is its last update.
+		lastBlocks := make(map[string]uint64)
+		for it.Next() {
+			if _, ok := pending[it.Event.Key]; ok && !found[it.Event.Key] {
+				lastBlocks[it.Event.Key] = it.Event.Raw.BlockNumber
+			}
+		}
+		err = it.Error()
+		it.Close()
+		if err != nil {
+			return fmt.Errorf("read oracle updates of blocks %d to %d: %v", start, end, err)
+		}
+		for key, blockNumber := range lastBlocks {
+			header, err := f.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+			if err != nil {
+				return fmt.Errorf("read block %d: %v", blockNumber, err)
+			}
+			pending[key].Time = time.Unix(int64(header.Time), 0)
+			found[key] = true
+		}
+		if len(found) == len(pending) || start == 0 {
+			return nil
+		}
+		header, err := f.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(start))
+		if err != nil {
+			return fmt.Errorf("read block %d: %v", start, err)
+		}
+		if time.Unix(int64(header.Time), 0).Before(since) {
+			return nil
+		}
+		end = start - 1
+	}
+}
+
+var (
+	valueScale  = new(big.Float).SetFloat64(math.Pow10(ValueDecimals))
+	uint128     = new(big.Int).Lsh(big.NewInt(1), 128)
+	maxInt128   = new(big.Int).Sub(new(big.Int).Rsh(uint128, 1), big.NewInt(1))
+	errOverflow = errors.New("value does not fit into int128")
+)
+
+// EncodeValue returns @value with ValueDecimals decimals as uint128. As rates can be negative, the value is the
+// two's complement of an int128, i.e. consumers read it with int128(value).
+func EncodeValue(value float64) (*big.Int, error) {
+	scaled := math.Round(value * math.Pow10(ValueDecimals))
+	if math.IsNaN(scaled) || math.Abs(scaled) >= math.Ldexp(1, 127) {
+		return nil, errOverflow
+	}
+	encoded, _ := big.NewFloat(scaled).Int(nil)
+	if encoded.Sign() < 0 {
+		encoded.Add(encoded, uint128)
+	}
+	return encoded, nil
+}
+
+// DecodeValue returns the rate encoded by EncodeValue.
+func DecodeValue(encoded *big.Int) float64 {
+	value := new(big.Int).Set(encoded)
+	if value.Cmp(maxInt128) > 0 {
+		value.Sub(value, uint128)
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(value), valueScale).Float64()
+	return f
+}
+
+// UpdateReason returns why @update should be pushed given the @last pushed update, or an empty string if it is
+// not due yet. The value deviates if it moves by more than @deviationPermille of the last value and by more than
+// @minDeviation.
+func UpdateReason(last *Update, update Update, deviationPermille int, minDeviation float64, heartbeat time.Duration) string {
+	if last == nil {
+		return ReasonInitial
+	}
+	return oraclehelper.UpdateReason(last.Time, update.Time, heartbeat, func() bool {
+		return oraclehelper.Deviates(last.Value, update.Value, deviationPermille, minDeviation)
+	})
+}
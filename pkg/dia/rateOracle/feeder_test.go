@@ -0,0 +1,306 @@
+package rateoracle
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	diaOracleServiceV2 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV2"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type testRateSource struct {
+	defiRate     dia.DefiRate
+	interestRate models.InterestRate
+	compounded   []models.InterestRate
+}
+
+func (s *testRateSource) DefiLendingRate(ctx context.Context, protocol, asset string, timestamp time.Time) (dia.DefiRate, error) {
+	return s.defiRate, nil
+}
+
+func (s *testRateSource) InterestRate(ctx context.Context, symbol string, date time.Time) (models.InterestRate, error) {
+	return s.interestRate, nil
+}
+
+func (s *testRateSource) CompoundedAvgDIA(ctx context.Context, symbol string, calDays, daysPerYear int, date time.Time) ([]models.InterestRate, error) {
+	return s.compounded, nil
+}
+
+// simulatedOracle deploys the key value oracle on a simulated chain and returns it along with the transactor
+// of its updater.
+func simulatedOracle(t *testing.T) (*backends.SimulatedBackend, *diaOracleServiceV2.DIAOracleV2, *bind.TransactOpts) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: balance}}, 10000000)
+	_, _, oracle, err := diaOracleServiceV2.DeployDIAOracleV2(auth, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+	return backend, oracle, auth
+}
+
+// setChainTime mines an empty block at @target, such that the following blocks are mined right after it.
+func setChainTime(t *testing.T, backend *backends.SimulatedBackend, target time.Time) {
+	head := int64(backend.Blockchain().CurrentBlock().Time())
+	// A block is mined 10 seconds after its parent.
+	if err := backend.AdjustTime(time.Duration(target.Unix()-head-10) * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+}
+
+// minedBackend mines the pending transactions of the simulated chain before reading a receipt.
+type minedBackend struct {
+	*backends.SimulatedBackend
+}
+
+func (b minedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.Commit()
+	return b.SimulatedBackend.TransactionReceipt(ctx, txHash)
+}
+
+// testOracle is an empty oracle whose transactions are mined right away and revert if @reverted is set.
+type testOracle struct {
+	calls    int
+	reverted bool
+	// receipts are the receipts of the sent transactions by hash.
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (o *testOracle) GetValue(opts *bind.CallOpts, key string) (*big.Int, *big.Int, error) {
+	return big.NewInt(0), big.NewInt(0), nil
+}
+
+func (o *testOracle) SetValue(opts *bind.TransactOpts, key string, value *big.Int, timestamp *big.Int) (*types.Transaction, error) {
+	o.calls++
+	tx := types.NewTransaction(uint64(o.calls), common.Address{}, big.NewInt(0), 100000, big.NewInt(1), nil)
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: big.NewInt(int64(o.calls))}
+	if o.reverted {
+		receipt.Status = types.ReceiptStatusFailed
+	}
+	if o.receipts == nil {
+		o.receipts = make(map[common.Hash]*types.Receipt)
+	}
+	o.receipts[tx.Hash()] = receipt
+	return tx, nil
+}
+
+func (o *testOracle) FilterOracleUpdate(opts *bind.FilterOpts) (*diaOracleServiceV2.DIAOracleV2OracleUpdateIterator, error) {
+	return nil, errors.New("no oracle updates")
+}
+
+func (o *testOracle) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(int64(o.calls))}, nil
+}
+
+func (o *testOracle) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := o.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (o *testOracle) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func TestFeederUpdate(t *testing.T) {
+	backend, oracle, auth := simulatedOracle(t)
+	defer backend.Close()
+
+	conf, err := ParseConfig([]byte(`{
+		"deviation_permille": 10,
+		"min_deviation": 0.01,
+		"heartbeat_seconds": 3600,
+		"feeds": [
+			{"key": "AAVE-USDC-borrowingRate", "type": "defiRate", "protocol": "AAVE", "asset": "USDC", "field": "borrowingRate"},
+			{"key": "ESTER", "type": "interestRate", "symbol": "ESTER", "heartbeat_seconds": 86400},
+			{"key": "SOFR30-DIA", "type": "compoundedAvgDIA", "symbol": "SOFR", "days": 30, "days_per_year": 360}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1640995200, 0)
+	source := &testRateSource{
+		defiRate:     dia.DefiRate{Timestamp: start, LendingRate: 2.1, BorrowingRate: 3.5},
+		interestRate: models.InterestRate{Symbol: "ESTER", Value: -0.573, EffectiveDate: start.AddDate(0, 0, -1)},
+		compounded:   []models.InterestRate{{Symbol: "SOFR30_compounded_by_DIA", Value: 0.04997, EffectiveDate: start}},
+	}
+	feeder := NewFeeder(conf, source, oracle, minedBackend{backend}, auth)
+
+	for _, c := range []struct {
+		name      string
+		borrowing float64
+		ester     float64
+		elapsed   time.Duration
+		want      map[string]string
+	}{
+		{"initial", 3.5, -0.573, 0, map[string]string{"AAVE-USDC-borrowingRate": ReasonInitial, "ESTER": ReasonInitial, "SOFR30-DIA": ReasonInitial}},
+		{"unchanged", 3.5, -0.573, time.Minute, map[string]string{}},
+		{"small deviation", 3.52, -0.575, 2 * time.Minute, map[string]string{}},
+		{"deviation", 3.6, -0.59, 3 * time.Minute, map[string]string{"AAVE-USDC-borrowingRate": ReasonDeviation, "ESTER": ReasonDeviation}},
+		{"heartbeat", 3.6, -0.59, 64 * time.Minute, map[string]string{"AAVE-USDC-borrowingRate": ReasonHeartbeat, "SOFR30-DIA": ReasonHeartbeat}},
+	} {
+		source.defiRate.BorrowingRate, source.interestRate.Value = c.borrowing, c.ester
+		setChainTime(t, backend, start.Add(c.elapsed))
+		updates, err := feeder.Update(context.Background(), start.Add(c.elapsed))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		backend.Commit()
+		got := make(map[string]string)
+		for _, update := range updates {
+			got[update.Key] = update.Reason
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: updates were incorrect, got: %v, want: %v.", c.name, got, c.want)
+			continue
+		}
+		for key, reason := range c.want {
+			if got[key] != reason {
+				t.Errorf("%s: reason of %s was incorrect, got: %v, want: %v.", c.name, key, got[key], reason)
+			}
+		}
+	}
+
+	for _, c := range []struct {
+		key       string
+		want      float64
+		valueTime time.Time
+	}{
+		{"AAVE-USDC-borrowingRate", 3.6, start},
+		{"ESTER", -0.59, start.AddDate(0, 0, -1)},
+		{"SOFR30-DIA", 0.04997, start},
+	} {
+		value, timestamp, err := oracle.GetValue(&bind.CallOpts{}, c.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := DecodeValue(value); math.Abs(got-c.want) > 1e-9 || timestamp.Int64() != c.valueTime.Unix() {
+			t.Errorf("oracle value of %s was incorrect, got: %v at %v, want: %v at %v.", c.key, got, timestamp, c.want, c.valueTime.Unix())
+		}
+	}
+
+	// A restarted feeder continues from the values in the oracle, taking the block times of their last updates
+	// as push times. ESTER was pushed after its value was published and is not due for a heartbeat.
+	restarted := NewFeeder(conf, source, oracle, minedBackend{backend}, auth)
+	for _, c := range []struct {
+		elapsed time.Duration
+		want    []string
+	}{
+		{90 * time.Minute, nil},
+		{125 * time.Minute, []string{"AAVE-USDC-borrowingRate", "SOFR30-DIA"}},
+	} {
+		setChainTime(t, backend, start.Add(c.elapsed))
+		updates, err := restarted.Update(context.Background(), start.Add(c.elapsed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(updates) != len(c.want) {
+			t.Errorf("updates of restarted feeder after %v were incorrect, got: %+v, want: %v.", c.elapsed, updates, c.want)
+			continue
+		}
+		for i, key := range c.want {
+			if updates[i].Key != key || updates[i].Reason != ReasonHeartbeat {
+				t.Errorf("update of restarted feeder after %v was incorrect, got: %+v, want: %s heartbeat.", c.elapsed, updates[i], key)
+			}
+		}
+		backend.Commit()
+	}
+}
+
+func TestFeederRevertedUpdate(t *testing.T) {
+	conf, err := ParseConfig([]byte(`{"feeds": [{"key": "ESTER", "type": "interestRate", "symbol": "ESTER"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1640995200, 0)
+	source := &testRateSource{interestRate: models.InterestRate{Symbol: "ESTER", Value: -0.573, EffectiveDate: start}}
+	oracle := &testOracle{reverted: true}
+	feeder := NewFeeder(conf, source, oracle, oracle, &bind.TransactOpts{})
+
+	// A reverted update is not marked as pushed and is pushed again.
+	if updates, err := feeder.Update(context.Background(), start); !errors.Is(err, ErrTxFailed) || len(updates) != 0 {
+		t.Errorf("reverted update was incorrect, got: %+v, %v.", updates, err)
+	}
+	oracle.reverted = false
+	updates, err := feeder.Update(context.Background(), start.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0].Reason != ReasonInitial || oracle.calls != 2 {
+		t.Errorf("update after revert was incorrect, got: %+v after %d calls.", updates, oracle.calls)
+	}
+}
+
+func TestEncodeValue(t *testing.T) {
+	for _, c := range []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{3.5, "350000000"},
+		{0.123456789, "12345679"},
+		{-0.573, "340282366920938463463374607431710911456"},
+	} {
+		encoded, err := EncodeValue(c.value)
+		if err != nil || encoded.String() != c.want {
+			t.Errorf("encoding of %v was incorrect, got: %v, %v, want: %v.", c.value, encoded, err, c.want)
+			continue
+		}
+		if got := DecodeValue(encoded); math.Abs(got-c.value) > 1e-8 {
+			t.Errorf("decoding of %v was incorrect, got: %v.", c.value, got)
+		}
+	}
+	for _, value := range []float64{math.NaN(), math.Inf(1), 2e30} {
+		if _, err := EncodeValue(value); err == nil {
+			t.Errorf("value %v was encoded", value)
+		}
+	}
+}
+
+func TestShippedConfig(t *testing.T) {
+	data, err := ioutil.ReadFile("../../../config/rateOracle/RateOracle.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseConfig(data); err != nil {
+		t.Errorf("config was rejected: %v", err)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"feeds": []}`,
+		`{"feeds": [{"key": "X", "type": "price"}]}`,
+		`{"feeds": [{"key": "X", "type": "defiRate", "protocol": "AAVE", "asset": "USDC", "field": "supplyRate"}]}`,
+		`{"feeds": [{"key": "X", "type": "compoundedAvgDIA", "symbol": "SOFR"}]}`,
+		`{"feeds": [{"key": "X", "type": "interestRate", "symbol": "SOFR"}, {"key": "X", "type": "interestRate", "symbol": "SONIA"}]}`,
+	} {
+		if _, err := ParseConfig([]byte(data)); err == nil {
+			t.Errorf("config %s was accepted", data)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package pool
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+const (
+	// sharePriceLookback is the minimum age of the state the growth of a share price is measured from.
+	sharePriceLookback = 24 * time.Hour
+	// barnbridgeEpochsPerYear is the number of weekly BarnBridge epochs per year.
+	barnbridgeEpochsPerYear = 52
+	// pegTolerance is the maximal relative deviation of the prices of the input assets of a pool from their mean
+	// for them to be valued at a common price.
+	pegTolerance = 0.02
+)
+
+// yieldModel sets the yield fields of a pool state from its raw Rate and Balance.
+// The methodologies of the models are documented in models.FarmingPoolMethodology.
+type yieldModel func(datastore models.Datastore, pool *models.FarmingPool) error
+
+var yieldModels = map[string]yieldModel{
+	// BAL liquidity mining rewards are not covered.
+	"BALANCER": withoutAPY(sharePriceModel(func(pool *models.FarmingPool) float64 { return pool.Rate }, nil)),
+	// The virtual price of a Curve pool token is in units of the peg of the pool's coins.
+	// CRV gauge rewards are not covered.
+	"CURVEFI": withoutAPY(sharePriceModel(func(pool *models.FarmingPool) float64 { return pool.Rate }, func(pool *models.FarmingPool) float64 {
+		return pool.Balance * pool.Rate
+	})),
+	// The balance of a yearn vault is already in units of its token, as the share price is applied by the vault.
+	"YFI": sharePriceModel(func(pool *models.FarmingPool) float64 { return pool.Rate + 1 }, func(pool *models.FarmingPool) float64 {
+		return pool.Balance
+	}),
+	"BARNBRIDGE": emissionModel("BOND", func(pool *models.FarmingPool) float64 {
+		return pool.Rate * barnbridgeEpochsPerYear
+	}),
+}
+
+// NormaliseYield sets the yield fields of @pool according to the yield model of its protocol.
+// Pools of protocols without a model are left unchanged.
+func NormaliseYield(datastore models.Datastore, pool *models.FarmingPool) error {
+	model, ok := yieldModels[pool.ProtocolName]
+	if !ok {
+		return nil
+	}
+	return model(datastore, pool)
+}
+
+// sharePriceModel returns the model of pools whose yield is the growth of the share price returned by @sharePrice.
+// If @lockedAmount is set, the TVL is the amount of input assets it returns valued with their common price,
+// see pegPrice.
+func sharePriceModel(sharePrice func(pool *models.FarmingPool) float64, lockedAmount func(pool *models.FarmingPool) float64) yieldModel {
+	return func(datastore models.Datastore, pool *models.FarmingPool) error {
+		end := pool.TimeStamp.Add(-sharePriceLookback)
+		previous, err := datastore.GetFarmingPoolData(end.Add(-6*sharePriceLookback), end, pool.ProtocolName, pool.PoolID)
+		if err != nil {
+			return err
+		}
+		// States are ordered by time descending. The yield is left zero until the pool was scraped for a day.
+		if len(previous) > 0 {
+			var baseAPY float64
+			pool.BaseAPR, baseAPY = models.SharePriceYield(sharePrice(&previous[0]), sharePrice(pool), pool.TimeStamp.Sub(previous[0].TimeStamp))
+			pool.APY = models.FarmingPoolAPY(baseAPY, 0)
+		}
+
+		if lockedAmount != nil {
+			price, err := pegPrice(datastore, pool.InputAsset)
+			if err != nil {
+				return err
+			}
+			pool.TVL = lockedAmount(pool) * price
+		}
+		return nil
+	}
+}
+
+// withoutAPY returns @model without the APY, for protocols whose pools pay rewards the model does not cover.
+// Such an APY would understate the yield of the pools.
+func withoutAPY(model yieldModel) yieldModel {
+	return func(datastore models.Datastore, pool *models.FarmingPool) error {
+		err := model(datastore, pool)
+		pool.APY = 0
+		return err
+	}
+}
+
+// emissionModel returns the model of pools which pay @rewardsPerYear of @rewardAsset on the Balance valued with the
+// common price of the input assets, see pegPrice. Without a common price TVL and RewardAPR are left zero.
+func emissionModel(rewardAsset string, rewardsPerYear func(pool *models.FarmingPool) float64) yieldModel {
+	return func(datastore models.Datastore, pool *models.FarmingPool) error {
+		rewardPrice, err := datastore.GetPriceUSD(rewardAsset)
+		if err != nil {
+			return fmt.Errorf("price of reward asset %s: %v", rewardAsset, err)
+		}
+		price, err := pegPrice(datastore, pool.InputAsset)
+		if err != nil {
+			return err
+		}
+		pool.RewardAsset = rewardAsset
+		pool.RewardPrice = rewardPrice
+		pool.TVL = pool.Balance * price
+		pool.RewardAPR = models.RewardAPR(rewardsPerYear(pool), rewardPrice, pool.TVL)
+		pool.APY = models.FarmingPoolAPY(0, pool.RewardAPR)
+		return nil
+	}
+}
+
+// pegPrice returns the DIA price in USD of the input assets with @symbols if they share a price, such as the
+// stablecoins of a Curve pool, and zero otherwise. A pool amount in units of the peg cannot be valued with the
+// prices of assets of different value without the reserves of each asset.
+func pegPrice(datastore models.Datastore, symbols []string) (float64, error) {
+	if len(symbols) == 0 {
+		return 0, fmt.Errorf("pool has no input assets")
+	}
+	prices := make([]float64, len(symbols))
+	for i, symbol := range symbols {
+		price, err := datastore.GetPriceUSD(symbol)
+		if err != nil {
+			return 0, fmt.Errorf("price of input asset %s: %v", symbol, err)
+		}
+		prices[i] = price
+	}
+	return commonPrice(prices), nil
+}
+
+// commonPrice returns the mean of @prices if none of them deviates from it by more than pegTolerance, and zero otherwise.
+func commonPrice(prices []float64) float64 {
+	var mean float64
+	for _, price := range prices {
+		mean += price
+	}
+	mean /= float64(len(prices))
+	if mean <= 0 {
+		return 0
+	}
+	for _, price := range prices {
+		if math.Abs(price-mean)/mean > pegTolerance {
+			return 0
+		}
+	}
+	return mean
+}
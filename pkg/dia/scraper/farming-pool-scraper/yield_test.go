@@ -0,0 +1,35 @@
+package pool
+
+import (
+	"testing"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestCommonPrice(t *testing.T) {
+	tables := []struct {
+		prices []float64
+		price  float64
+	}{
+		{[]float64{1.01, 0.99, 1}, 1},
+		{[]float64{2500}, 2500},
+		{[]float64{1, 2500}, 0},
+		{[]float64{0, 0}, 0},
+	}
+	for _, table := range tables {
+		if price := commonPrice(table.prices); price != table.price {
+			t.Errorf("common price of %v was incorrect, got: %v, want: %v.", table.prices, price, table.price)
+		}
+	}
+}
+
+func TestWithoutAPY(t *testing.T) {
+	model := withoutAPY(func(datastore models.Datastore, pool *models.FarmingPool) error {
+		pool.BaseAPR, pool.APY = 4, 4.08
+		return nil
+	})
+	pool := &models.FarmingPool{}
+	if err := model(nil, pool); err != nil || pool.BaseAPR != 4 || pool.APY != 0 {
+		t.Errorf("yield was incorrect, got: %v %v %v.", pool.BaseAPR, pool.APY, err)
+	}
+}
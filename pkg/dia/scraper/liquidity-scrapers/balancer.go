@@ -0,0 +1,56 @@
+package liquidityscrapers
+
+import (
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/balancer/balancerpool"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// balancerReader reads Balancer v1 pools, which hold their tokens and are their own LP token.
+type balancerReader struct {
+	*poolReader
+}
+
+func (r *balancerReader) ReadPool(address common.Address, blockNumber uint64) (pool dia.Pool, err error) {
+	bpool, err := balancerpool.NewBalancerpoolCaller(address, r.client)
+	if err != nil {
+		return
+	}
+	opts := r.callOpts(blockNumber)
+	pool = r.pool(address, dia.WeightedPool, blockNumber)
+
+	tokens, err := bpool.GetCurrentTokens(opts)
+	if err != nil {
+		return
+	}
+	for _, token := range tokens {
+		var asset dia.Asset
+		if asset, err = r.asset(token); err != nil {
+			return
+		}
+		balance, err := bpool.GetBalance(opts, token)
+		if err != nil {
+			return pool, err
+		}
+		weight, err := bpool.GetNormalizedWeight(opts, token)
+		if err != nil {
+			return pool, err
+		}
+		pool.Assets = append(pool.Assets, dia.PoolAsset{Asset: asset, Reserve: amount(balance, asset.Decimals), Weight: amount(weight, 18)})
+	}
+
+	fee, err := bpool.GetSwapFee(opts)
+	if err != nil {
+		return
+	}
+	pool.Fee = amount(fee, 18)
+	if pool.LPToken, err = r.asset(address); err != nil {
+		return
+	}
+	totalSupply, err := bpool.TotalSupply(opts)
+	if err != nil {
+		return
+	}
+	pool.TotalSupply = amount(totalSupply, pool.LPToken.Decimals)
+	return
+}
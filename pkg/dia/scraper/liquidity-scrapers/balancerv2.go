@@ -0,0 +1,98 @@
+package liquidityscrapers
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	balancervault "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/balancerv2/vault"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/liquidity-scrapers/balancerv2pool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// balancerV2Reader reads weighted and stable pools of Balancer v2 and its forks, whose tokens are held by the vault.
+type balancerV2Reader struct {
+	*poolReader
+	vault *balancervault.BalancerVaultCaller
+}
+
+func newBalancerV2Reader(base *poolReader, vaultAddress common.Address) (PoolReader, *ethclient.Client, error) {
+	vault, err := balancervault.NewBalancerVaultCaller(vaultAddress, base.client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &balancerV2Reader{poolReader: base, vault: vault}, base.client, nil
+}
+
+// ReadPool reads the pool at @address. Pools with weights are weighted pools and pools with an amplification
+// parameter stable pools, other pool types are not supported. Pools which hold their own LP token, as composable
+// stable pools do, are read without it and their supply is reduced by the LP tokens they hold.
+func (r *balancerV2Reader) ReadPool(address common.Address, blockNumber uint64) (pool dia.Pool, err error) {
+	bpool, err := balancerv2pool.NewBalancerV2PoolCaller(address, r.client)
+	if err != nil {
+		return
+	}
+	opts := r.callOpts(blockNumber)
+
+	var weights []*big.Int
+	var poolType dia.PoolType
+	var amplification float64
+	if weights, err = bpool.GetNormalizedWeights(opts); err == nil {
+		poolType = dia.WeightedPool
+	} else {
+		amp, ampErr := bpool.GetAmplificationParameter(opts)
+		if ampErr != nil {
+			return pool, fmt.Errorf("pool is neither weighted (%v) nor stable (%v)", err, ampErr)
+		}
+		err = nil
+		poolType = dia.StableSwapPool
+		amplification = amount(amp.Value, 0) / amount(amp.Precision, 0)
+	}
+	pool = r.pool(address, poolType, blockNumber)
+	pool.Amplification = amplification
+
+	poolID, err := bpool.GetPoolId(opts)
+	if err != nil {
+		return
+	}
+	tokens, err := r.vault.GetPoolTokens(opts, poolID)
+	if err != nil {
+		return
+	}
+	if weights != nil && len(weights) != len(tokens.Tokens) {
+		return pool, fmt.Errorf("%d weights for %d tokens", len(weights), len(tokens.Tokens))
+	}
+
+	if pool.LPToken, err = r.asset(address); err != nil {
+		return
+	}
+	totalSupply, err := bpool.TotalSupply(opts)
+	if err != nil {
+		return
+	}
+	pool.TotalSupply = amount(totalSupply, pool.LPToken.Decimals)
+
+	for i, token := range tokens.Tokens {
+		if token == address {
+			pool.TotalSupply -= amount(tokens.Balances[i], pool.LPToken.Decimals)
+			continue
+		}
+		var asset dia.Asset
+		if asset, err = r.asset(token); err != nil {
+			return
+		}
+		poolAsset := dia.PoolAsset{Asset: asset, Reserve: amount(tokens.Balances[i], asset.Decimals)}
+		if weights != nil {
+			poolAsset.Weight = amount(weights[i], 18)
+		}
+		pool.Assets = append(pool.Assets, poolAsset)
+	}
+
+	fee, err := bpool.GetSwapFeePercentage(opts)
+	if err != nil {
+		return
+	}
+	pool.Fee = amount(fee, 18)
+	return
+}
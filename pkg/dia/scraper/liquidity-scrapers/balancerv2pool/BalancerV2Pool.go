@@ -0,0 +1,416 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package balancerv2pool
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// BalancerV2PoolMetaData contains all meta data concerning the BalancerV2Pool contract.
+var BalancerV2PoolMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"name\":\"getPoolId\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getNormalizedWeights\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getSwapFeePercentage\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getAmplificationParameter\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isUpdating\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"precision\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"internalType\":\"uint8\",\"name\":\"\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"symbol\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// BalancerV2PoolABI is the input ABI used to generate the binding from.
+// Deprecated: Use BalancerV2PoolMetaData.ABI instead.
+var BalancerV2PoolABI = BalancerV2PoolMetaData.ABI
+
+// BalancerV2Pool is an auto generated Go binding around an Ethereum contract.
+type BalancerV2Pool struct {
+	BalancerV2PoolCaller     // Read-only binding to the contract
+	BalancerV2PoolTransactor // Write-only binding to the contract
+	BalancerV2PoolFilterer   // Log filterer for contract events
+}
+
+// BalancerV2PoolCaller is an auto generated read-only Go binding around an Ethereum contract.
+type BalancerV2PoolCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BalancerV2PoolTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type BalancerV2PoolTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BalancerV2PoolFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type BalancerV2PoolFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BalancerV2PoolSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type BalancerV2PoolSession struct {
+	Contract     *BalancerV2Pool   // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// BalancerV2PoolCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type BalancerV2PoolCallerSession struct {
+	Contract *BalancerV2PoolCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts         // Call options to use throughout this session
+}
+
+// BalancerV2PoolTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type BalancerV2PoolTransactorSession struct {
+	Contract     *BalancerV2PoolTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// BalancerV2PoolRaw is an auto generated low-level Go binding around an Ethereum contract.
+type BalancerV2PoolRaw struct {
+	Contract *BalancerV2Pool // Generic contract binding to access the raw methods on
+}
+
+// BalancerV2PoolCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type BalancerV2PoolCallerRaw struct {
+	Contract *BalancerV2PoolCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// BalancerV2PoolTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type BalancerV2PoolTransactorRaw struct {
+	Contract *BalancerV2PoolTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewBalancerV2Pool creates a new instance of BalancerV2Pool, bound to a specific deployed contract.
+func NewBalancerV2Pool(address common.Address, backend bind.ContractBackend) (*BalancerV2Pool, error) {
+	contract, err := bindBalancerV2Pool(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &BalancerV2Pool{BalancerV2PoolCaller: BalancerV2PoolCaller{contract: contract}, BalancerV2PoolTransactor: BalancerV2PoolTransactor{contract: contract}, BalancerV2PoolFilterer: BalancerV2PoolFilterer{contract: contract}}, nil
+}
+
+// NewBalancerV2PoolCaller creates a new read-only instance of BalancerV2Pool, bound to a specific deployed contract.
+func NewBalancerV2PoolCaller(address common.Address, caller bind.ContractCaller) (*BalancerV2PoolCaller, error) {
+	contract, err := bindBalancerV2Pool(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BalancerV2PoolCaller{contract: contract}, nil
+}
+
+// NewBalancerV2PoolTransactor creates a new write-only instance of BalancerV2Pool, bound to a specific deployed contract.
+func NewBalancerV2PoolTransactor(address common.Address, transactor bind.ContractTransactor) (*BalancerV2PoolTransactor, error) {
+	contract, err := bindBalancerV2Pool(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BalancerV2PoolTransactor{contract: contract}, nil
+}
+
+// NewBalancerV2PoolFilterer creates a new log filterer instance of BalancerV2Pool, bound to a specific deployed contract.
+func NewBalancerV2PoolFilterer(address common.Address, filterer bind.ContractFilterer) (*BalancerV2PoolFilterer, error) {
+	contract, err := bindBalancerV2Pool(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &BalancerV2PoolFilterer{contract: contract}, nil
+}
+
+// bindBalancerV2Pool binds a generic wrapper to an already deployed contract.
+func bindBalancerV2Pool(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(BalancerV2PoolABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_BalancerV2Pool *BalancerV2PoolRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _BalancerV2Pool.Contract.BalancerV2PoolCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_BalancerV2Pool *BalancerV2PoolRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _BalancerV2Pool.Contract.BalancerV2PoolTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_BalancerV2Pool *BalancerV2PoolRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _BalancerV2Pool.Contract.BalancerV2PoolTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_BalancerV2Pool *BalancerV2PoolCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _BalancerV2Pool.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_BalancerV2Pool *BalancerV2PoolTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _BalancerV2Pool.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_BalancerV2Pool *BalancerV2PoolTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _BalancerV2Pool.Contract.contract.Transact(opts, method, params...)
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() view returns(uint8)
+func (_BalancerV2Pool *BalancerV2PoolCaller) Decimals(opts *bind.CallOpts) (uint8, error) {
+	var out []interface{}
+	err := _BalancerV2Pool.contract.Call(opts, &out, "decimals")
+
+	if err != nil {
+		return *new(uint8), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(uint8)).(*uint8)
+
+	return out0, err
+
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() view returns(uint8)
+func (_BalancerV2Pool *BalancerV2PoolSession) Decimals() (uint8, error) {
+	return _BalancerV2Pool.Contract.Decimals(&_BalancerV2Pool.CallOpts)
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() view returns(uint8)
+func (_BalancerV2Pool *BalancerV2PoolCallerSession) Decimals() (uint8, error) {
+	return _BalancerV2Pool.Contract.Decimals(&_BalancerV2Pool.CallOpts)
+}
+
+// GetAmplificationParameter is a free data retrieval call binding the contract method 0x6daccffa.
+//
+// Solidity: function getAmplificationParameter() view returns(uint256 value, bool isUpdating, uint256 precision)
+func (_BalancerV2Pool *BalancerV2PoolCaller) GetAmplificationParameter(opts *bind.CallOpts) (struct {
+	Value      *big.Int
+	IsUpdating bool
+	Precision  *big.Int
+}, error) {
+	var out []interface{}
+	err := _BalancerV2Pool.contract.Call(opts, &out, "getAmplificationParameter")
+
+	outstruct := new(struct {
+		Value      *big.Int
+		IsUpdating bool
+		Precision  *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Value = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.IsUpdating = *abi.ConvertType(out[1], new(bool)).(*bool)
+	outstruct.Precision = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// GetAmplificationParameter is a free data retrieval call binding the contract method 0x6daccffa.
+//
+// Solidity: function getAmplificationParameter() view returns(uint256 value, bool isUpdating, uint256 precision)
+func (_BalancerV2Pool *BalancerV2PoolSession) GetAmplificationParameter() (struct {
+	Value      *big.Int
+	IsUpdating bool
+	Precision  *big.Int
+}, error) {
+	return _BalancerV2Pool.Contract.GetAmplificationParameter(&_BalancerV2Pool.CallOpts)
+}
+
+// GetAmplificationParameter is a free data retrieval call binding the contract method 0x6daccffa.
+//
+// Solidity: function getAmplificationParameter() view returns(uint256 value, bool isUpdating, uint256 precision)
+func (_BalancerV2Pool *BalancerV2PoolCallerSession) GetAmplificationParameter() (struct {
+	Value      *big.Int
+	IsUpdating bool
+	Precision  *big.Int
+}, error) {
+	return _BalancerV2Pool.Contract.GetAmplificationParameter(&_BalancerV2Pool.CallOpts)
+}
+
+// GetNormalizedWeights is a free data retrieval call binding the contract method 0xf89f27ed.
+//
+// Solidity: function getNormalizedWeights() view returns(uint256[])
+func (_BalancerV2Pool *BalancerV2PoolCaller) GetNormalizedWeights(opts *bind.CallOpts) ([]*big.Int, error) {
+	var out []interface{}
+	err := _BalancerV2Pool.contract.Call(opts, &out, "getNormalizedWeights")
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// GetNormalizedWeights is a free data retrieval call binding the contract method 0xf89f27ed.
+//
+// Solidity: function getNormalizedWeights() view returns(uint256[])
+func (_BalancerV2Pool *BalancerV2PoolSession) GetNormalizedWeights() ([]*big.Int, error) {
+	return _BalancerV2Pool.Contract.GetNormalizedWeights(&_BalancerV2Pool.CallOpts)
+}
+
+// GetNormalizedWeights is a free data retrieval call binding the contract method 0xf89f27ed.
+//
+// Solidity: function getNormalizedWeights() view returns(uint256[])
+func (_BalancerV2Pool *BalancerV2PoolCallerSession) GetNormalizedWeights() ([]*big.Int, error) {
+	return _BalancerV2Pool.Contract.GetNormalizedWeights(&_BalancerV2Pool.CallOpts)
+}
+
+// GetPoolId is a free data retrieval call binding the contract method 0x38fff2d0.
+//
+// Solidity: function getPoolId() view returns(bytes32)
+func (_BalancerV2Pool *BalancerV2PoolCaller) GetPoolId(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _BalancerV2Pool.contract.Call(opts, &out, "getPoolId")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// GetPoolId is a free data retrieval call binding the contract method 0x38fff2d0.
+//
+// Solidity: function getPoolId() view returns(bytes32)
+func (_BalancerV2Pool *BalancerV2PoolSession) GetPoolId() ([32]byte, error) {
+	return _BalancerV2Pool.Contract.GetPoolId(&_BalancerV2Pool.CallOpts)
+}
+
+// GetPoolId is a free data retrieval call binding the contract method 0x38fff2d0.
+//
+// Solidity: function getPoolId() view returns(bytes32)
+func (_BalancerV2Pool *BalancerV2PoolCallerSession) GetPoolId() ([32]byte, error) {
+	return _BalancerV2Pool.Contract.GetPoolId(&_BalancerV2Pool.CallOpts)
+}
+
+// GetSwapFeePercentage is a free data retrieval call binding the contract method 0x55c67628.
+//
+// Solidity: function getSwapFeePercentage() view returns(uint256)
+func (_BalancerV2Pool *BalancerV2PoolCaller) GetSwapFeePercentage(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _BalancerV2Pool.contract.Call(opts, &out, "getSwapFeePercentage")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetSwapFeePercentage is a free data retrieval call binding the contract method 0x55c67628.
+//
+// Solidity: function getSwapFeePercentage() view returns(uint256)
+func (_BalancerV2Pool *BalancerV2PoolSession) GetSwapFeePercentage() (*big.Int, error) {
+	return _BalancerV2Pool.Contract.GetSwapFeePercentage(&_BalancerV2Pool.CallOpts)
+}
+
+// GetSwapFeePercentage is a free data retrieval call binding the contract method 0x55c67628.
+//
+// Solidity: function getSwapFeePercentage() view returns(uint256)
+func (_BalancerV2Pool *BalancerV2PoolCallerSession) GetSwapFeePercentage() (*big.Int, error) {
+	return _BalancerV2Pool.Contract.GetSwapFeePercentage(&_BalancerV2Pool.CallOpts)
+}
+
+// Symbol is a free data retrieval call binding the contract method 0x95d89b41.
+//
+// Solidity: function symbol() view returns(string)
+func (_BalancerV2Pool *BalancerV2PoolCaller) Symbol(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := _BalancerV2Pool.contract.Call(opts, &out, "symbol")
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// Symbol is a free data retrieval call binding the contract method 0x95d89b41.
+//
+// Solidity: function symbol() view returns(string)
+func (_BalancerV2Pool *BalancerV2PoolSession) Symbol() (string, error) {
+	return _BalancerV2Pool.Contract.Symbol(&_BalancerV2Pool.CallOpts)
+}
+
+// Symbol is a free data retrieval call binding the contract method 0x95d89b41.
+//
+// Solidity: function symbol() view returns(string)
+func (_BalancerV2Pool *BalancerV2PoolCallerSession) Symbol() (string, error) {
+	return _BalancerV2Pool.Contract.Symbol(&_BalancerV2Pool.CallOpts)
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_BalancerV2Pool *BalancerV2PoolCaller) TotalSupply(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _BalancerV2Pool.contract.Call(opts, &out, "totalSupply")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_BalancerV2Pool *BalancerV2PoolSession) TotalSupply() (*big.Int, error) {
+	return _BalancerV2Pool.Contract.TotalSupply(&_BalancerV2Pool.CallOpts)
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_BalancerV2Pool *BalancerV2PoolCallerSession) TotalSupply() (*big.Int, error) {
+	return _BalancerV2Pool.Contract.TotalSupply(&_BalancerV2Pool.CallOpts)
+}
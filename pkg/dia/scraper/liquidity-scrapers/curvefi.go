@@ -0,0 +1,115 @@
+package liquidityscrapers
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/curvefi"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/curvefi/token"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// curveFeeDecimals is the precision of the fees of Curve pools.
+const curveFeeDecimals = 10
+
+// curvefiReader reads Curve pools through the Curve registry.
+type curvefiReader struct {
+	*poolReader
+	registry *curvefi.CurvefiCaller
+}
+
+func newCurvefiReader(base *poolReader, registryAddress common.Address) (PoolReader, *ethclient.Client, error) {
+	registry, err := curvefi.NewCurvefiCaller(registryAddress, base.client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &curvefiReader{poolReader: base, registry: registry}, base.client, nil
+}
+
+// PoolAddresses returns the pools in config/liquidity/Curvefi.json or, if there is none, all pools of the registry.
+func (r *curvefiReader) PoolAddresses() ([]common.Address, error) {
+	if addresses, err := r.poolReader.PoolAddresses(); err == nil {
+		return addresses, nil
+	}
+	count, err := r.registry.PoolCount(nil)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []common.Address
+	for i := int64(0); i < count.Int64(); i++ {
+		address, err := r.registry.PoolList(nil, big.NewInt(i))
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// ReadPool reads the pool at @address. The stableswap invariant is computed on the reserves, so pools of coins
+// with exchange rates such as lending and meta pools are not supported.
+func (r *curvefiReader) ReadPool(address common.Address, blockNumber uint64) (pool dia.Pool, err error) {
+	opts := r.callOpts(blockNumber)
+	pool = r.pool(address, dia.StableSwapPool, blockNumber)
+
+	nCoins, err := r.registry.GetNCoins(opts, address)
+	if err != nil {
+		return
+	}
+	n := int(nCoins[0].Int64())
+	coins, err := r.registry.GetCoins(opts, address)
+	if err != nil {
+		return
+	}
+	balances, err := r.registry.GetBalances(opts, address)
+	if err != nil {
+		return
+	}
+	rates, err := r.registry.GetRates(opts, address)
+	if err != nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		var asset dia.Asset
+		if asset, err = r.asset(coins[i]); err != nil {
+			return
+		}
+		// Coins without exchange rate are scaled to 18 decimals by their rate.
+		precision := new(big.Int).Exp(big.NewInt(10), big.NewInt(36-int64(asset.Decimals)), nil)
+		if rates[i].Cmp(precision) != 0 {
+			return pool, errors.New("pools of coins with exchange rates are not supported")
+		}
+		pool.Assets = append(pool.Assets, dia.PoolAsset{Asset: asset, Reserve: amount(balances[i], asset.Decimals)})
+	}
+
+	amp, err := r.registry.GetA(opts, address)
+	if err != nil {
+		return
+	}
+	pool.Amplification = amount(amp, 0)
+	fees, err := r.registry.GetFees(opts, address)
+	if err != nil {
+		return
+	}
+	pool.Fee = amount(fees[0], curveFeeDecimals)
+
+	lpToken, err := r.registry.GetLpToken(opts, address)
+	if err != nil {
+		return
+	}
+	if pool.LPToken, err = r.asset(lpToken); err != nil {
+		return
+	}
+	lpCaller, err := token.NewTokenCaller(lpToken, r.client)
+	if err != nil {
+		return
+	}
+	totalSupply, err := lpCaller.TotalSupply(opts)
+	if err != nil {
+		return
+	}
+	pool.TotalSupply = amount(totalSupply, pool.LPToken.Decimals)
+	return
+}
@@ -0,0 +1,256 @@
+package liquidityscrapers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+const restDialEth = "http://159.69.120.42:8545/"
+
+var (
+	log = logrus.New()
+	// nativeCoinAddress is the address AMMs such as Curve use for the native coin.
+	nativeCoinAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+)
+
+type nothing struct{}
+
+// PoolReader reads the states of the liquidity pools of an exchange.
+type PoolReader interface {
+	// PoolAddresses returns the addresses of the pools to read.
+	PoolAddresses() ([]common.Address, error)
+	// ReadPool returns the state of the pool at @address in block @blockNumber.
+	ReadPool(address common.Address, blockNumber uint64) (dia.Pool, error)
+}
+
+// NewPoolReader returns the reader of the pools of the AMM @exchange. The node of the blockchain of @exchange is
+// read from the environment variable <BLOCKCHAIN>_URI_REST as done by the exchange scrapers.
+func NewPoolReader(exchange dia.Exchange) (PoolReader, *ethclient.Client, error) {
+	blockchain := exchange.BlockChain.Name
+	if blockchain == "" {
+		blockchain = dia.ETHEREUM
+	}
+	var restDial string
+	if blockchain == dia.ETHEREUM {
+		restDial = restDialEth
+	}
+	client, err := ethclient.Dial(utils.Getenv(strings.ToUpper(blockchain)+"_URI_REST", restDial))
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial node of %s: %v", blockchain, err)
+	}
+	base := &poolReader{client: client, exchange: exchange.Name, blockchain: blockchain, assets: make(map[common.Address]dia.Asset)}
+
+	switch exchange.Name {
+	case dia.CurveFIExchange:
+		return newCurvefiReader(base, exchange.Contract)
+	case dia.BalancerExchange:
+		return &balancerReader{poolReader: base}, client, nil
+	case dia.BalancerV2Exchange:
+		return newBalancerV2Reader(base, exchange.Contract)
+	}
+	if fee, ok := uniswapV2Exchanges[exchange.Name]; ok {
+		return &uniswapV2Reader{poolReader: base, fee: fee}, client, nil
+	}
+	return nil, nil, fmt.Errorf("pools of exchange %s are not supported", exchange.Name)
+}
+
+// poolReader holds what readers of all exchanges have in common.
+type poolReader struct {
+	client     *ethclient.Client
+	exchange   string
+	blockchain string
+
+	assetsMu sync.Mutex
+	assets   map[common.Address]dia.Asset
+}
+
+// PoolAddresses returns the addresses of the pools in config/liquidity/<exchange>.json.
+func (r *poolReader) PoolAddresses() ([]common.Address, error) {
+	return poolAddressesFromConfig("liquidity/" + r.exchange)
+}
+
+// callOpts returns the options of calls in block @blockNumber.
+func (r *poolReader) callOpts(blockNumber uint64) *bind.CallOpts {
+	return &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(blockNumber), Context: context.Background()}
+}
+
+// pool returns a pool of the exchange at @address with the metadata of the read set.
+func (r *poolReader) pool(address common.Address, poolType dia.PoolType, blockNumber uint64) dia.Pool {
+	return dia.Pool{
+		Exchange:    r.exchange,
+		Blockchain:  r.blockchain,
+		Address:     address.Hex(),
+		Type:        poolType,
+		BlockNumber: blockNumber,
+		Time:        time.Now(),
+	}
+}
+
+// asset returns the token at @address. Tokens are cached, as their metadata does not change.
+func (r *poolReader) asset(address common.Address) (dia.Asset, error) {
+	r.assetsMu.Lock()
+	defer r.assetsMu.Unlock()
+	if asset, ok := r.assets[address]; ok {
+		return asset, nil
+	}
+	var asset dia.Asset
+	if address == nativeCoinAddress {
+		asset = dia.Asset{Symbol: "ETH", Name: "Ether", Address: "0x0000000000000000000000000000000000000000", Decimals: 18, Blockchain: r.blockchain}
+	} else {
+		var err error
+		if asset, err = ethhelper.ETHAddressToAsset(address, r.client, r.blockchain); err != nil {
+			return dia.Asset{}, fmt.Errorf("token %s: %v", address.Hex(), err)
+		}
+	}
+	r.assets[address] = asset
+	return asset, nil
+}
+
+// amount returns the integer @amount of a token with @decimals as a float.
+func amount(amount *big.Int, decimals uint8) float64 {
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))).Float64()
+	return value
+}
+
+// poolAddressesFromConfig returns the pool addresses in the config file @filename, which lists pools as the
+// config files of the exchange scrapers listening by address do.
+func poolAddressesFromConfig(filename string) (addresses []common.Address, err error) {
+	jsonFile, err := os.Open(configCollectors.ConfigFileConnectors(filename, ".json"))
+	if err != nil {
+		return
+	}
+	defer func() {
+		if cerr := jsonFile.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	byteData, err := ioutil.ReadAll(jsonFile)
+	if err != nil {
+		return
+	}
+	var pools struct {
+		Pools []struct {
+			Address     string `json:"Address"`
+			ForeignName string `json:"ForeignName"`
+		} `json:"Pools"`
+	}
+	if err = json.Unmarshal(byteData, &pools); err != nil {
+		return
+	}
+	for _, pool := range pools.Pools {
+		if !common.IsHexAddress(pool.Address) {
+			return nil, fmt.Errorf("pool %s in %s is not an address", pool.Address, filename)
+		}
+		addresses = append(addresses, common.HexToAddress(pool.Address))
+	}
+	if len(addresses) == 0 {
+		err = errors.New("no pools in " + filename)
+	}
+	return
+}
+
+// LiquidityScraper periodically reads the states of the pools of an exchange.
+type LiquidityScraper struct {
+	reader PoolReader
+	client *ethclient.Client
+	period time.Duration
+
+	// signaling channels
+	shutdown     chan nothing
+	shutdownDone chan nothing
+	chanPools    chan *dia.Pool
+	closed       bool
+}
+
+// NewLiquidityScraper returns a scraper which reads the pools of @exchange every @period, starting immediately.
+func NewLiquidityScraper(exchange dia.Exchange, period time.Duration) (*LiquidityScraper, error) {
+	reader, client, err := NewPoolReader(exchange)
+	if err != nil {
+		return nil, err
+	}
+	s := &LiquidityScraper{
+		reader:       reader,
+		client:       client,
+		period:       period,
+		shutdown:     make(chan nothing),
+		shutdownDone: make(chan nothing),
+		chanPools:    make(chan *dia.Pool),
+	}
+	go s.mainLoop()
+	return s, nil
+}
+
+// mainLoop reads all pools every period until the scraper is closed.
+func (s *LiquidityScraper) mainLoop() {
+	defer close(s.shutdownDone)
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+	for {
+		s.readPools()
+		select {
+		case <-ticker.C:
+		case <-s.shutdown:
+			log.Println("LiquidityScraper shutting down")
+			return
+		}
+	}
+}
+
+// readPools reads the states of all pools at the latest block. Pools whose state cannot be read are skipped.
+func (s *LiquidityScraper) readPools() {
+	addresses, err := s.reader.PoolAddresses()
+	if err != nil {
+		log.Error("pool addresses: ", err)
+		return
+	}
+	blockNumber, err := s.client.BlockNumber(context.Background())
+	if err != nil {
+		log.Error("block number: ", err)
+		return
+	}
+	for _, address := range addresses {
+		pool, err := s.reader.ReadPool(address, blockNumber)
+		if err != nil {
+			log.Errorf("read pool %s: %v", address.Hex(), err)
+			continue
+		}
+		select {
+		case s.chanPools <- &pool:
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// PoolChannel returns the channel the pool states are sent to.
+func (s *LiquidityScraper) PoolChannel() chan *dia.Pool {
+	return s.chanPools
+}
+
+// Close stops the scraper.
+func (s *LiquidityScraper) Close() error {
+	if s.closed {
+		return errors.New("LiquidityScraper: Already closed")
+	}
+	s.closed = true
+	close(s.shutdown)
+	<-s.shutdownDone
+	return nil
+}
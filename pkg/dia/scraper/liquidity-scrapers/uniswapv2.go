@@ -0,0 +1,64 @@
+package liquidityscrapers
+
+import (
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/uniswap"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// uniswapV2Exchanges maps the UniswapV2 forks whose pools are listed in config/liquidity to their swap fee.
+// UniswapV3 is not read, as its positions are NFTs rather than a fungible LP token that could be priced.
+var uniswapV2Exchanges = map[string]float64{
+	dia.UniswapExchange:   0.003,
+	dia.SushiSwapExchange: 0.003,
+}
+
+// uniswapV2Reader reads pairs of UniswapV2 and its forks, which are their own LP token.
+type uniswapV2Reader struct {
+	*poolReader
+	fee float64
+}
+
+func (r *uniswapV2Reader) ReadPool(address common.Address, blockNumber uint64) (pool dia.Pool, err error) {
+	pair, err := uniswap.NewIUniswapV2PairCaller(address, r.client)
+	if err != nil {
+		return
+	}
+	opts := r.callOpts(blockNumber)
+	pool = r.pool(address, dia.ConstantProductPool, blockNumber)
+	pool.Fee = r.fee
+
+	token0, err := pair.Token0(opts)
+	if err != nil {
+		return
+	}
+	token1, err := pair.Token1(opts)
+	if err != nil {
+		return
+	}
+	reserves, err := pair.GetReserves(opts)
+	if err != nil {
+		return
+	}
+	for i, token := range []common.Address{token0, token1} {
+		var asset dia.Asset
+		if asset, err = r.asset(token); err != nil {
+			return
+		}
+		reserve := reserves.Reserve0
+		if i == 1 {
+			reserve = reserves.Reserve1
+		}
+		pool.Assets = append(pool.Assets, dia.PoolAsset{Asset: asset, Reserve: amount(reserve, asset.Decimals)})
+	}
+
+	if pool.LPToken, err = r.asset(address); err != nil {
+		return
+	}
+	totalSupply, err := pair.TotalSupply(opts)
+	if err != nil {
+		return
+	}
+	pool.TotalSupply = amount(totalSupply, pool.LPToken.Decimals)
+	return
+}
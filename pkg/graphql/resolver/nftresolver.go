@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/graph-gophers/graphql-go"
 )
 
@@ -63,15 +64,15 @@ func (tr *NFTTradeResolver) Price(ctx context.Context) (*string, error) {
 }
 
 func (tr *NFTTradeResolver) CurrencyAddress(ctx context.Context) (*string, error) {
-	return &tr.trade.CurrencyAddress, nil
+	return &tr.trade.Currency.Address, nil
 }
 
 func (tr *NFTTradeResolver) CurrencySymbol(ctx context.Context) (*string, error) {
-	return &tr.trade.CurrencySymbol, nil
+	return &tr.trade.Currency.Symbol, nil
 }
 
 func (tr *NFTTradeResolver) CurrencyDecimals(ctx context.Context) (*int32, error) {
-	decimals := tr.trade.CurrencyDecimals
+	decimals := int32(tr.trade.Currency.Decimals)
 	return &decimals, nil
 }
 
@@ -100,6 +101,26 @@ func (tr *NFTTradeResolver) Exchange(ctx context.Context) (*string, error) {
 	return &tr.trade.Exchange, nil
 }
 
+func (tr *NFTTradeResolver) Quantity(ctx context.Context) (*int32, error) {
+	quantity := int32(1)
+	if tr.trade.Quantity > 0 {
+		quantity = int32(tr.trade.Quantity)
+	}
+	return &quantity, nil
+}
+
+func (tr *NFTTradeResolver) BundleID(ctx context.Context) (*string, error) {
+	return &tr.trade.BundleID, nil
+}
+
+func (tr *NFTTradeResolver) BundleSize(ctx context.Context) (*int32, error) {
+	bundleSize := int32(1)
+	if tr.trade.BundleSize > 0 {
+		bundleSize = int32(tr.trade.BundleSize)
+	}
+	return &bundleSize, nil
+}
+
 // -----------------------------------------------------------------------------
 
 type NFTOfferResolver struct {
@@ -237,3 +258,103 @@ func (br *NFTBidResolver) TxHash(ctx context.Context) (*string, error) {
 func (br *NFTBidResolver) Exchange(ctx context.Context) (*string, error) {
 	return &br.bid.Exchange, nil
 }
+
+type NFTFloorResolver struct {
+	nftClass dia.NFTClass
+	estimate models.NFTFloorEstimate
+}
+
+func (fr *NFTFloorResolver) Address(ctx context.Context) (*string, error) {
+	return &fr.nftClass.Address, nil
+}
+
+func (fr *NFTFloorResolver) Blockchain(ctx context.Context) (*string, error) {
+	return &fr.nftClass.Blockchain, nil
+}
+
+func (fr *NFTFloorResolver) Floor(ctx context.Context) (*float64, error) {
+	return &fr.estimate.Floor, nil
+}
+
+func (fr *NFTFloorResolver) FloorUSD(ctx context.Context) (*float64, error) {
+	return &fr.estimate.FloorUSD, nil
+}
+
+func (fr *NFTFloorResolver) CurrencySymbol(ctx context.Context) (*string, error) {
+	return &fr.estimate.Currency.Symbol, nil
+}
+
+func (fr *NFTFloorResolver) CurrencyAddress(ctx context.Context) (*string, error) {
+	return &fr.estimate.Currency.Address, nil
+}
+
+func (fr *NFTFloorResolver) Time(ctx context.Context) (*graphql.Time, error) {
+	return &graphql.Time{Time: fr.estimate.Time}, nil
+}
+
+func (fr *NFTFloorResolver) Source(ctx context.Context) (*string, error) {
+	source := dia.Diadata
+	return &source, nil
+}
+
+func (fr *NFTFloorResolver) Confidence(ctx context.Context) (*NFTFloorConfidenceResolver, error) {
+	return &NFTFloorConfidenceResolver{c: fr.estimate.Confidence}, nil
+}
+
+type NFTFloorConfidenceResolver struct {
+	c models.NFTFloorConfidence
+}
+
+func (cr *NFTFloorConfidenceResolver) Score(ctx context.Context) (*float64, error) {
+	return &cr.c.Score, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) Quantile(ctx context.Context) (*float64, error) {
+	return &cr.c.Quantile, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) NumTrades(ctx context.Context) (*int32, error) {
+	n := int32(cr.c.NumTrades)
+	return &n, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) NumUsed(ctx context.Context) (*int32, error) {
+	n := int32(cr.c.NumUsed)
+	return &n, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) NumWashTrades(ctx context.Context) (*int32, error) {
+	n := int32(cr.c.NumWashTrades)
+	return &n, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) NumZeroPrice(ctx context.Context) (*int32, error) {
+	n := int32(cr.c.NumZeroPrice)
+	return &n, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) NumUnconverted(ctx context.Context) (*int32, error) {
+	n := int32(cr.c.NumUnconverted)
+	return &n, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) NumBundleShares(ctx context.Context) (*int32, error) {
+	n := int32(cr.c.NumBundleShares)
+	return &n, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) MinPrice(ctx context.Context) (*float64, error) {
+	return &cr.c.MinPrice, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) MedianPrice(ctx context.Context) (*float64, error) {
+	return &cr.c.MedianPrice, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) WindowStart(ctx context.Context) (*graphql.Time, error) {
+	return &graphql.Time{Time: cr.c.WindowStart}, nil
+}
+
+func (cr *NFTFloorConfidenceResolver) WindowEnd(ctx context.Context) (*graphql.Time, error) {
+	return &graphql.Time{Time: cr.c.WindowEnd}, nil
+}
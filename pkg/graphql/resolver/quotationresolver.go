@@ -38,3 +38,80 @@ func (qr *QuotationResolver) Time(ctx context.Context) (*graphql.Time, error) {
 func (qr *QuotationResolver) MAIR(ctx context.Context) (*graphql.Time, error) {
 	return &graphql.Time{Time: qr.q.Time}, nil
 }
+
+type PointInTimeQuotationResolver struct {
+	q models.PointInTimeQuotation
+}
+
+func (qr *PointInTimeQuotationResolver) Symbol(ctx context.Context) (*string, error) {
+	return &qr.q.Asset.Symbol, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Name(ctx context.Context) (*string, error) {
+	return &qr.q.Asset.Name, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Address(ctx context.Context) (*string, error) {
+	return &qr.q.Asset.Address, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Blockchain(ctx context.Context) (*string, error) {
+	return &qr.q.Asset.Blockchain, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Price(ctx context.Context) (*float64, error) {
+	return &qr.q.Price, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Source(ctx context.Context) (*string, error) {
+	return &qr.q.Source, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Time(ctx context.Context) (*graphql.Time, error) {
+	return &graphql.Time{Time: qr.q.Time}, nil
+}
+
+func (qr *PointInTimeQuotationResolver) RequestTime(ctx context.Context) (*graphql.Time, error) {
+	return &graphql.Time{Time: qr.q.RequestTime}, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Policy(ctx context.Context) (*string, error) {
+	policy := string(qr.q.Policy)
+	return &policy, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Filter(ctx context.Context) (*string, error) {
+	return &qr.q.Filter, nil
+}
+
+func (qr *PointInTimeQuotationResolver) AgeSeconds(ctx context.Context) (*float64, error) {
+	return &qr.q.AgeSeconds, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Stale(ctx context.Context) (*bool, error) {
+	return &qr.q.Stale, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Interpolated(ctx context.Context) (*bool, error) {
+	return &qr.q.Interpolated, nil
+}
+
+func (qr *PointInTimeQuotationResolver) Points(ctx context.Context) (*[]*QuotationPointResolver, error) {
+	var pr []*QuotationPointResolver
+	for _, point := range qr.q.Points {
+		pr = append(pr, &QuotationPointResolver{p: point})
+	}
+	return &pr, nil
+}
+
+type QuotationPointResolver struct {
+	p models.QuotationPoint
+}
+
+func (pr *QuotationPointResolver) Price(ctx context.Context) (*float64, error) {
+	return &pr.p.Price, nil
+}
+
+func (pr *QuotationPointResolver) Time(ctx context.Context) (*graphql.Time, error) {
+	return &graphql.Time{Time: pr.p.Time}, nil
+}
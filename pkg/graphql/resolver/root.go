@@ -30,6 +30,38 @@ func (r *DiaResolver) GetQuotation(ctx context.Context, args struct{ Symbol grap
 	return &QuotationResolver{q: *q}, nil
 }
 
+// GetAssetQuotationAt returns the quotation of an asset at a point in time.
+func (r *DiaResolver) GetAssetQuotationAt(ctx context.Context, args struct {
+	Address             graphql.NullString
+	Blockchain          graphql.NullString
+	Timestamp           graphql.NullTime
+	MaxStalenessSeconds graphql.NullInt
+	Policy              graphql.NullString
+}) (*PointInTimeQuotationResolver, error) {
+	var maxStaleness time.Duration
+	if args.MaxStalenessSeconds.Value != nil {
+		maxStaleness = time.Duration(*args.MaxStalenessSeconds.Value) * time.Second
+	}
+	var policyName string
+	if args.Policy.Value != nil {
+		policyName = *args.Policy.Value
+	}
+	policy, err := models.ParseQuotationPolicy(policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := r.RelDB.GetAsset(*args.Address.Value, *args.Blockchain.Value)
+	if err != nil {
+		return nil, err
+	}
+	q, err := r.DS.GetAssetQuotationAt(asset, args.Timestamp.Value.Time, maxStaleness, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &PointInTimeQuotationResolver{q: *q}, nil
+}
+
 func (r *DiaResolver) GetSupply(ctx context.Context, args struct{ Symbol graphql.NullString }) (*SupplyResolver, error) {
 	q, err := r.DS.GetLatestSupply(*args.Symbol.Value, &r.RelDB)
 	if err != nil {
@@ -329,6 +361,36 @@ func (r *DiaResolver) GetNFTTrades(ctx context.Context, args struct {
 	return &tr, nil
 }
 
+// GetNFTFloor returns the floor price of an nft class. Timestamp defaults to now and the floor window to 24h.
+func (r *DiaResolver) GetNFTFloor(ctx context.Context, args struct {
+	Address            graphql.NullString
+	Blockchain         graphql.NullString
+	Timestamp          graphql.NullTime
+	FloorWindowSeconds graphql.NullInt
+	Quantile           graphql.NullFloat
+}) (*NFTFloorResolver, error) {
+	timestamp := time.Now()
+	if args.Timestamp.Value != nil {
+		timestamp = args.Timestamp.Value.Time
+	}
+	floorWindow := 24 * time.Hour
+	if args.FloorWindowSeconds.Value != nil {
+		floorWindow = time.Duration(*args.FloorWindowSeconds.Value) * time.Second
+	}
+	quantile := models.DefaultNFTFloorQuantile
+	if args.Quantile.Value != nil {
+		quantile = *args.Quantile.Value
+	}
+
+	nftClass := dia.NFTClass{Address: *args.Address.Value, Blockchain: *args.Blockchain.Value}
+	stepBackLimit := 40
+	estimate, err := r.RelDB.GetNFTFloorEstimate(nftClass, timestamp, floorWindow, quantile, stepBackLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &NFTFloorResolver{nftClass: nftClass, estimate: estimate}, nil
+}
+
 // GetNFTOffers returns offers of an NFT by address, blockchain, token_id and time range
 func (r *DiaResolver) GetNFTOffers(ctx context.Context, args struct {
 	Address    graphql.NullString
@@ -0,0 +1,178 @@
+// Package openapi generates an OpenAPI 3 document for the routes registered on a gin engine.
+// Each route needs an entry in a Spec holding its documentation and response type. The
+// schemas are derived from the Go types by reflection.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route documents a single route.
+type Route struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	// Query parameters. Path parameters are taken from the route itself.
+	Query []Parameter
+	// Body is a value of the type expected as json request body, if any.
+	Body interface{}
+	// Response is a value of the type returned on success.
+	Response interface{}
+	// Security is the name of the security scheme protecting the route, if any.
+	Security string
+}
+
+// Spec documents the routes of an API. Keys are of the form "GET /v1/quotation/:symbol",
+// i.e. the method and the path as registered on the gin engine.
+type Spec struct {
+	Info    Info
+	Servers []Server
+	// Prefix restricts the document to routes starting with Prefix.
+	Prefix string
+	Routes map[string]Route
+	// PathParams holds descriptions of path parameters by name.
+	PathParams map[string]string
+	// Error is a value of the type returned on errors.
+	Error           interface{}
+	SecuritySchemes map[string]SecurityScheme
+}
+
+// Key returns the key of the route with @method and @path in a Spec.
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+// QueryParam returns an optional query parameter with @name of the json schema type @typ.
+func QueryParam(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+// Missing returns the keys of all registered routes in @routes without an entry in @spec.
+func (spec *Spec) Missing(routes gin.RoutesInfo) (missing []string) {
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, spec.Prefix) {
+			continue
+		}
+		if _, ok := spec.Routes[Key(route.Method, route.Path)]; !ok {
+			missing = append(missing, Key(route.Method, route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return
+}
+
+// Document returns the OpenAPI document of @routes. Routes without an entry in @spec
+// are left out and reported in the returned error.
+func (spec *Spec) Document(routes gin.RoutesInfo) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    spec.Info,
+		Servers: spec.Servers,
+		Paths:   make(map[string]PathItem),
+	}
+	registry := newSchemaRegistry()
+	errorSchema := registry.schemaOf(spec.Error)
+
+	for _, info := range routes {
+		if !strings.HasPrefix(info.Path, spec.Prefix) {
+			continue
+		}
+		route, ok := spec.Routes[Key(info.Method, info.Path)]
+		if !ok {
+			continue
+		}
+		oasPath, pathParams := convertPath(info.Path)
+
+		operation := &Operation{
+			OperationID: operationID(info.Method, info.Path),
+			Summary:     route.Summary,
+			Description: route.Description,
+			Tags:        route.Tags,
+			Deprecated:  route.Deprecated,
+			Responses:   make(map[string]Response),
+		}
+		for _, name := range pathParams {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:        name,
+				In:          "path",
+				Description: spec.PathParams[name],
+				Required:    true,
+				Schema:      &Schema{Type: "string"},
+			})
+		}
+		operation.Parameters = append(operation.Parameters, route.Query...)
+		if route.Body != nil {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(registry.schemaOf(route.Body)),
+			}
+		}
+		operation.Responses[fmt.Sprint(http.StatusOK)] = Response{
+			Description: http.StatusText(http.StatusOK),
+			Content:     jsonContent(registry.schemaOf(route.Response)),
+		}
+		if errorSchema != nil {
+			operation.Responses["default"] = Response{Description: "Error", Content: jsonContent(errorSchema)}
+		}
+		if route.Security != "" {
+			operation.Security = []map[string][]string{{route.Security: {}}}
+		}
+
+		if doc.Paths[oasPath] == nil {
+			doc.Paths[oasPath] = make(PathItem)
+		}
+		doc.Paths[oasPath][strings.ToLower(info.Method)] = operation
+	}
+
+	doc.Components.Schemas = registry.schemas
+	doc.Components.SecuritySchemes = spec.SecuritySchemes
+
+	if missing := spec.Missing(routes); len(missing) > 0 {
+		return doc, fmt.Errorf("routes without spec entry: %s", strings.Join(missing, ", "))
+	}
+	return doc, nil
+}
+
+// convertPath converts the gin path @ginPath into an OpenAPI path and returns the names
+// of its parameters, e.g. /v1/quotation/:symbol becomes /v1/quotation/{symbol}.
+func convertPath(ginPath string) (string, []string) {
+	var params []string
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID returns a unique identifier for the route, e.g. getV1QuotationBySymbol.
+func operationID(method, ginPath string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(ginPath, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			b.WriteString("By")
+			segment = segment[1:]
+		}
+		b.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+	return b.String()
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	if schema == nil {
+		return nil
+	}
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testAsset struct {
+	Symbol  string
+	Address string `json:"address"`
+	Hidden  string `json:"-"`
+	Amount  int64  `json:"amount,string"`
+}
+
+type testQuotation struct {
+	testAsset
+	Price    float64
+	Time     time.Time
+	Sources  []string
+	Volumes  map[string]float64
+	Previous *testQuotation
+	internal int
+}
+
+func TestConvertPath(t *testing.T) {
+	tables := []struct {
+		ginPath string
+		oasPath string
+		params  int
+	}{
+		{"/v1/exchanges", "/v1/exchanges", 0},
+		{"/v1/quotation/:symbol", "/v1/quotation/{symbol}", 1},
+		{"/v1/NFT/:blockchain/:address/:id", "/v1/NFT/{blockchain}/{address}/{id}", 3},
+		{"/v1/chart/*filepath", "/v1/chart/{filepath}", 1},
+	}
+	for _, table := range tables {
+		oasPath, params := convertPath(table.ginPath)
+		if oasPath != table.oasPath || len(params) != table.params {
+			t.Errorf("path conversion of %s was incorrect, got: %s %v, want: %s.", table.ginPath, oasPath, params, table.oasPath)
+		}
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	registry := newSchemaRegistry()
+	schema := registry.schemaOf([]testQuotation{})
+	if schema.Type != "array" || schema.Items.Ref != "#/components/schemas/openapi.testQuotation" {
+		t.Fatalf("schema was incorrect, got: %+v.", schema)
+	}
+
+	quotation := registry.schemas["openapi.testQuotation"]
+	if quotation == nil {
+		t.Fatal("missing component openapi.testQuotation")
+	}
+	expected := map[string]string{
+		"Symbol":   "string",
+		"address":  "string",
+		"amount":   "string",
+		"Price":    "number",
+		"Time":     "string",
+		"Sources":  "array",
+		"Volumes":  "object",
+		"Previous": "",
+	}
+	if len(quotation.Properties) != len(expected) {
+		t.Errorf("number of properties was incorrect, got: %d, want: %d.", len(quotation.Properties), len(expected))
+	}
+	for name, typ := range expected {
+		property, ok := quotation.Properties[name]
+		if !ok {
+			t.Errorf("missing property %s", name)
+			continue
+		}
+		if property.Type != typ {
+			t.Errorf("type of %s was incorrect, got: %s, want: %s.", name, property.Type, typ)
+		}
+	}
+	if quotation.Properties["Previous"].Ref != "#/components/schemas/openapi.testQuotation" {
+		t.Errorf("recursive reference was incorrect, got: %s.", quotation.Properties["Previous"].Ref)
+	}
+}
+
+func TestMissingAndDocument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	handler := func(c *gin.Context) {}
+	engine.GET("/v1/quotation/:symbol", handler)
+	engine.POST("/v1/quotation", handler)
+	engine.GET("/v1/undocumented", handler)
+	engine.GET("/login", handler)
+
+	spec := Spec{
+		Prefix: "/v1",
+		Routes: map[string]Route{
+			Key(http.MethodGet, "/v1/quotation/:symbol"): {Summary: "quotation", Response: testQuotation{}},
+			Key(http.MethodPost, "/v1/quotation"):        {Body: []string{}, Security: "bearerAuth"},
+		},
+	}
+
+	missing := spec.Missing(engine.Routes())
+	if len(missing) != 1 || missing[0] != "GET /v1/undocumented" {
+		t.Errorf("missing routes were incorrect, got: %v.", missing)
+	}
+
+	doc, err := spec.Document(engine.Routes())
+	if err == nil {
+		t.Error("expected error for undocumented route")
+	}
+	operation := doc.Paths["/v1/quotation/{symbol}"]["get"]
+	if operation == nil || len(operation.Parameters) != 1 || operation.Parameters[0].In != "path" {
+		t.Fatalf("operation was incorrect, got: %+v.", operation)
+	}
+	if operation.OperationID != "getV1QuotationBySymbol" {
+		t.Errorf("operation id was incorrect, got: %s.", operation.OperationID)
+	}
+	if doc.Paths["/v1/quotation"]["post"].RequestBody == nil {
+		t.Error("missing request body")
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Errorf("marshal document: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package restClient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultBaseURL is the public DIA REST API.
+	DefaultBaseURL = "https://api.diadata.org"
+
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// Client is a typed client for the /v1 routes of the DIA REST API.
+// It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying http client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates all requests with @apiKey. Keys are subject to the rate
+// limits of their plan instead of the limits for anonymous requests.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithRetries sets the number of retries for failed requests and the bounds of the
+// exponential backoff in between. @maxRetries=0 disables retrying.
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// NewClient returns a client for the API reachable at @baseURL.
+// If @baseURL is empty, DefaultBaseURL is used.
+func NewClient(baseURL string, options ...Option) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// APIError is returned whenever the API answers with a non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("dia api: status %d: %s", e.StatusCode, e.Message)
+}
+
+// get performs a GET request on @path and decodes the json response into @out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// post performs a POST request on @path with the json encoding of @in as body and decodes
+// the json response into @out.
+func (c *Client) post(ctx context.Context, path string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, path, nil, body, out)
+}
+
+// do sends the request and retries it with exponential backoff on network errors,
+// rate limiting and temporary server errors.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body []byte, out interface{}) error {
+	requestURL := c.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := c.backoff(attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		var retry bool
+		retry, lastErr = c.doOnce(ctx, method, requestURL, body, out)
+		if lastErr == nil || !retry {
+			return lastErr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	var re *retryableError
+	if errors.As(lastErr, &re) {
+		return re.APIError
+	}
+	return lastErr
+}
+
+// doOnce sends a single request. The returned bool reports whether the request may be retried.
+func (c *Client) doOnce(ctx context.Context, method string, requestURL string, body []byte, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-KEY", c.apiKey)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Error("close response body: ", cerr)
+		}
+	}()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &retryableError{
+			APIError:   &APIError{StatusCode: resp.StatusCode, Message: errorMessage(data)},
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+		if isRetryableStatus(resp.StatusCode) {
+			return true, apiErr
+		}
+		return false, apiErr.APIError
+	}
+
+	if out == nil || len(data) == 0 {
+		return false, nil
+	}
+	return false, json.Unmarshal(data, out)
+}
+
+// retryableError carries the delay the server asked for via the Retry-After header.
+type retryableError struct {
+	*APIError
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.APIError
+}
+
+// backoff returns the delay before the retry with number @attempt.
+func (c *Client) backoff(attempt int, lastErr error) time.Duration {
+	var re *retryableError
+	if errors.As(lastErr, &re) && re.retryAfter > 0 {
+		return re.retryAfter
+	}
+	wait := c.minBackoff << uint(attempt-1)
+	if wait > c.maxBackoff || wait <= 0 {
+		wait = c.maxBackoff
+	}
+	return wait
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses the seconds format of the Retry-After header.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// errorMessage extracts the error message from a restApi.APIError body if possible.
+func errorMessage(data []byte) string {
+	var apiErr restApi.APIError
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.ErrorMessage != "" {
+		return apiErr.ErrorMessage
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// pathOf joins @segments to an escaped path below /v1.
+func pathOf(segments ...string) string {
+	var b strings.Builder
+	b.WriteString("/v1")
+	for _, segment := range segments {
+		b.WriteString("/")
+		b.WriteString(url.PathEscape(segment))
+	}
+	return b.String()
+}
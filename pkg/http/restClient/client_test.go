@@ -0,0 +1,428 @@
+package restClient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestRetryOnUnavailable(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/v1/assetQuotation/Ethereum/0x0000000000000000000000000000000000000000" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"Symbol":"ETH","Price":3000.5}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetries(3, time.Millisecond, 5*time.Millisecond))
+	quotation, err := client.AssetQuotation(context.Background(), "Ethereum", "0x0000000000000000000000000000000000000000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("number of calls was incorrect, got: %d, want: %d.", calls, 3)
+	}
+	if quotation.Symbol != "ETH" || quotation.Price != 3000.5 {
+		t.Errorf("quotation was incorrect, got: %v.", quotation)
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errorcode":404,"errormessage":"no quotation available"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetries(3, time.Millisecond, 5*time.Millisecond))
+	_, err := client.Quotation(context.Background(), "BTC")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got: %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "no quotation available" {
+		t.Errorf("error was incorrect, got: %v.", apiErr)
+	}
+	if calls != 1 {
+		t.Errorf("number of calls was incorrect, got: %d, want: %d.", calls, 1)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	client := NewClient(server.URL, WithRetries(10, time.Second, time.Second))
+	_, err := client.Exchanges(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got: %v", err)
+	}
+}
+
+func TestGetListWrapsSingleObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Ticker":"BTC-USD","Value":42}`))
+	}))
+	defer server.Close()
+
+	values, err := NewClient(server.URL).VwapFirefly(context.Background(), "BTC-USD", TimeRange{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 1 || values[0].Value != 42 {
+		t.Errorf("values were incorrect, got: %v.", values)
+	}
+}
+
+func TestNFTClassPager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/NFTClasses/2/0":
+			_, _ = w.Write([]byte(`[{"Address":"0x1"},{"Address":"0x2"}]`))
+		case "/v1/NFTClasses/2/2":
+			_, _ = w.Write([]byte(`[{"Address":"0x3"},{"Address":"0x4"}]`))
+		case "/v1/NFTClasses/2/4":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	nftClasses, err := NewClient(server.URL).AllNFTClassesPaged(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nftClasses) != 4 || nftClasses[3].Address != "0x4" {
+		t.Errorf("nft classes were incorrect, got: %v.", nftClasses)
+	}
+}
+
+func TestNFTClassPagerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/NFTClasses/2/0":
+			_, _ = w.Write([]byte(`[{"Address":"0x1"},{"Address":"0x2"}]`))
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetries(0, time.Millisecond, time.Millisecond))
+	nftClasses, err := client.AllNFTClassesPaged(context.Background(), 2)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected rate limit error, got: %v", err)
+	}
+	if len(nftClasses) != 2 {
+		t.Errorf("nft classes were incorrect, got: %v.", nftClasses)
+	}
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	start := time.Unix(0, 0)
+	tables := []struct {
+		timeRange TimeRange
+		step      time.Duration
+		num       int
+	}{
+		{TimeRange{start, start.Add(10 * time.Hour)}, time.Hour, 10},
+		{TimeRange{start, start.Add(90 * time.Minute)}, time.Hour, 2},
+		{TimeRange{start, start.Add(time.Hour)}, 0, 1},
+		{TimeRange{}, time.Hour, 1},
+	}
+	for _, table := range tables {
+		ranges := SplitTimeRange(table.timeRange, table.step)
+		if len(ranges) != table.num {
+			t.Errorf("number of ranges was incorrect, got: %d, want: %d.", len(ranges), table.num)
+		}
+		if !ranges[len(ranges)-1].End.Equal(table.timeRange.End) {
+			t.Errorf("end of last range was incorrect, got: %v, want: %v.", ranges[len(ranges)-1].End, table.timeRange.End)
+		}
+	}
+}
+
+func TestAPIKeyHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "dia_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`["Ethereum"]`))
+	}))
+	defer server.Close()
+
+	blockchains, err := NewClient(server.URL, WithAPIKey("dia_test")).Blockchains(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(blockchains) != 1 {
+		t.Errorf("blockchains were incorrect, got: %v.", blockchains)
+	}
+}
+
+func TestAssetQuotations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/assetQuotations" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"Assets":[{"Blockchain":"Ethereum","Address":"0x1"}],"Timestamp":1000}` {
+			t.Errorf("unexpected body %s", body)
+		}
+		_, _ = w.Write([]byte(`[{"Symbol":"ABC","Price":2}]`))
+	}))
+	defer server.Close()
+
+	assets := []dia.Asset{{Symbol: "ABC", Blockchain: "Ethereum", Address: "0x1"}}
+	quotations, err := NewClient(server.URL).AssetQuotations(context.Background(), assets, time.Unix(1000, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(quotations) != 1 || quotations[0].Price != 2 {
+		t.Errorf("quotations were incorrect, got: %v.", quotations)
+	}
+}
+
+func TestAssetQuotationAt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/assetQuotationAt/Ethereum/0x1" || r.URL.RawQuery != "maxStaleness=60&policy=interpolate&timestamp=1000" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Price":2,"AgeSeconds":30,"Filter":"MAIR120","Interpolated":true}`))
+	}))
+	defer server.Close()
+
+	quotation, err := NewClient(server.URL).AssetQuotationAt(context.Background(), "Ethereum", "0x1", time.Unix(1000, 0), time.Minute, models.QuotationPolicyInterpolate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quotation.Price != 2 || quotation.AgeSeconds != 30 || quotation.Filter != "MAIR120" || !quotation.Interpolated {
+		t.Errorf("quotation was incorrect, got: %v.", quotation)
+	}
+}
+
+func TestNFTValuation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/NFTValuation/Ethereum/0x1/42" || r.URL.RawQuery != "timestamp=1000" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"TokenID":"42","Value":12,"Lower_Bound":10,"Upper_Bound":14,"Currency":"ETH","Components":{"Floor":10,"RarityMultiplier":1.2}}`))
+	}))
+	defer server.Close()
+
+	valuation, err := NewClient(server.URL).NFTValuation(context.Background(), "Ethereum", "0x1", "42", time.Unix(1000, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valuation.TokenID != "42" || valuation.Value != 12 || valuation.Lower != 10 || valuation.Upper != 14 || valuation.Components.Floor != 10 || valuation.Components.RarityMultiplier != 1.2 {
+		t.Errorf("valuation was incorrect, got: %v.", valuation)
+	}
+}
+
+func TestNFTClassRarity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/NFTRarity/Ethereum/0x1" || r.URL.RawQuery != "limit=2&method=statistical&offset=4" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Method":"statistical","Total":10,"Limit":2,"Offset":4,"Rarities":[{"TokenID":"7","StatisticalRank":5,"Traits":[{"Key":"hat","Value":"cap","Count":1,"Frequency":0.1}]},{"TokenID":"3","StatisticalRank":6}]}`))
+	}))
+	defer server.Close()
+
+	page, err := NewClient(server.URL).NFTClassRarity(context.Background(), "Ethereum", "0x1", models.NFTRarityStatistical, 2, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Total != 10 || len(page.Rarities) != 2 || page.Rarities[0].TokenID != "7" || page.Rarities[0].StatisticalRank != 5 || page.Rarities[0].Traits[0].Key != "hat" {
+		t.Errorf("page was incorrect, got: %v.", page)
+	}
+}
+
+func TestNFTMarketStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/NFTMarketStats/Ethereum/0x1" || r.URL.RawQuery != "endtime=7200&interval=1h&starttime=3600" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Interval":"1h","Stats":[{"Time":"1970-01-01T01:00:00Z","Currency":{"Symbol":"ETH"},"Volume":37,"NumSales":3,"HolderCount":120,"FloorPrice":3,"MedianPrice":10}]}`))
+	}))
+	defer server.Close()
+
+	timeRange := TimeRange{Start: time.Unix(3600, 0), End: time.Unix(7200, 0)}
+	stats, err := NewClient(server.URL).NFTMarketStats(context.Background(), "Ethereum", "0x1", models.NFTMarketStatsHourly, timeRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats.Stats) != 1 || stats.Stats[0].Currency.Symbol != "ETH" || stats.Stats[0].Volume != 37 || stats.Stats[0].HolderCount != 120 || !stats.Stats[0].Time.Equal(time.Unix(3600, 0)) {
+		t.Errorf("stats were incorrect, got: %v.", stats)
+	}
+}
+
+func TestNFTOrders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/NFTOrders/Ethereum/0x1/42" || r.URL.RawQuery != "status=placed" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"TokenID":"42","Orders":[{"OrderID":"bid-42","Side":"bid","Status":"placed","Price":1.5,"StartPrice":"1500000000000000000","Currency":{"Symbol":"ETH"}}]}`))
+	}))
+	defer server.Close()
+
+	book, err := NewClient(server.URL).NFTOrders(context.Background(), "Ethereum", "0x1", "42", dia.NFTOrderPlaced)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(book.Orders) != 1 || book.Orders[0].OrderID != "bid-42" || book.Orders[0].Price != 1.5 || book.Orders[0].Currency.Symbol != "ETH" {
+		t.Errorf("orders were incorrect, got: %v.", book)
+	}
+}
+
+func TestNFTBestBidOffer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/NFTBestBidOffer/Ethereum/0x1" || r.URL.RawQuery != "" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Books":[{"Currency":{"Symbol":"ETH"},"BestBid":{"OrderID":"bid-1","Price":60},"BestOffer":null,"NumBids":3,"NumOffers":0}]}`))
+	}))
+	defer server.Close()
+
+	books, err := NewClient(server.URL).NFTBestBidOffer(context.Background(), "Ethereum", "0x1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(books.Books) != 1 || books.Books[0].BestBid == nil || books.Books[0].BestBid.Price != 60 || books.Books[0].BestOffer != nil || books.Books[0].NumBids != 3 {
+		t.Errorf("best bid and offer were incorrect, got: %v.", books)
+	}
+}
+
+func TestNFTOracleUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/NFTOracleUpdates/1/0x42/CryptoPunks" || r.URL.RawQuery != "starttime=3600" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Oracle":"0x42","Chain_ID":1,"Key":"CryptoPunks","Updates":[{"Floor_Price":60,"Values":[6000000000,0,0,0,12],"Reason":"heartbeat","Tx_Hash":"0xabc"}]}`))
+	}))
+	defer server.Close()
+
+	updates, err := NewClient(server.URL).NFTOracleUpdates(context.Background(), 1, "0x42", "CryptoPunks", TimeRange{Start: time.Unix(3600, 0)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updates.Updates) != 1 || updates.Updates[0].Values[0] != 6000000000 || updates.Updates[0].Reason != "heartbeat" || updates.Updates[0].TxHash != "0xabc" {
+		t.Errorf("updates were incorrect, got: %v.", updates)
+	}
+}
+
+func TestDefiCollateralAtRisk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/defiCollateralAtRisk/COMPOUND/ETH" || r.URL.RawQuery != "maxDrop=30&step=2.5" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Protocol":"COMPOUND","Asset":{"Symbol":"ETH"},"Price":2000,"LiquidationThreshold":0.825,"Curve":[{"PriceDrop":0,"Price":2000},{"PriceDrop":0.025,"Price":1950,"Collateral":12.5,"NumAccounts":2}]}`))
+	}))
+	defer server.Close()
+
+	curve, err := NewClient(server.URL).DefiCollateralAtRisk(context.Background(), "COMPOUND", "ETH", 30, 2.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if curve.Asset.Symbol != "ETH" || curve.LiquidationThreshold != 0.825 || len(curve.Curve) != 2 || curve.Curve[1].Collateral != 12.5 || curve.Curve[1].NumAccounts != 2 {
+		t.Errorf("curve was incorrect, got: %v.", curve)
+	}
+}
+
+func TestDefiLiquidations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/defiLiquidations/AAVEv2" || r.URL.RawQuery != "endtime=7200&starttime=3600" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Protocol":"AAVEv2","Liquidations":[{"Borrower":"0x1","DebtAsset":{"Symbol":"USDC"},"DebtAmount":1500,"ValueUSD":1500,"TxHash":"0xabc"}]}`))
+	}))
+	defer server.Close()
+
+	timeRange := TimeRange{Start: time.Unix(3600, 0), End: time.Unix(7200, 0)}
+	liquidations, err := NewClient(server.URL).DefiLiquidations(context.Background(), "AAVEv2", timeRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(liquidations.Liquidations) != 1 || liquidations.Liquidations[0].DebtAsset.Symbol != "USDC" || liquidations.Liquidations[0].ValueUSD != 1500 {
+		t.Errorf("liquidations were incorrect, got: %v.", liquidations)
+	}
+}
+
+func TestMakerDAOVaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/defiMakerDAOVaults/ETH-A" || r.URL.RawQuery != "endtime=7200&starttime=3600" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`[{"Time":"2021-11-01T12:00:00Z","Ilk":"ETH-A","NumVaults":2,"Debt":6500,"NumUnsafe":1,"Distribution":[{"MinCollateralisation":0,"MaxCollateralisation":1.5,"NumVaults":1,"Debt":1500}]}]`))
+	}))
+	defer server.Close()
+
+	timeRange := TimeRange{Start: time.Unix(3600, 0), End: time.Unix(7200, 0)}
+	stats, err := NewClient(server.URL).MakerDAOVaults(context.Background(), "ETH-A", timeRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 1 || stats[0].NumUnsafe != 1 || len(stats[0].Distribution) != 1 || stats[0].Distribution[0].Debt != 1500 {
+		t.Errorf("vault stats were incorrect, got: %v.", stats)
+	}
+}
+
+func TestDefiTVL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/defiTVL/AAVEv2" || r.URL.RawQuery != "blockchain=Polygon&endtime=7200&starttime=3600" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`[{"Time":"2021-11-01T12:00:00Z","Protocol":"AAVEv2","Blockchain":"Polygon","ValueUSD":2500000,"NumAssets":12,"NumUnpriced":1}]`))
+	}))
+	defer server.Close()
+
+	timeRange := TimeRange{Start: time.Unix(3600, 0), End: time.Unix(7200, 0)}
+	tvls, err := NewClient(server.URL).DefiTVL(context.Background(), "AAVEv2", "Polygon", timeRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tvls) != 1 || tvls[0].Blockchain != "Polygon" || tvls[0].ValueUSD != 2500000 || tvls[0].NumUnpriced != 1 {
+		t.Errorf("tvl was incorrect, got: %v.", tvls)
+	}
+}
+
+func TestIBORFallbackRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/compoundedRateFallback/USD-LIBOR/3M" || r.URL.RawQuery != "dateInit=2021-03-15" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Symbol":"USD-LIBOR_3M_fallback_by_DIA","Value":0.30161,"Convention":"observationShift","Shift":2,"IBOR":"USD-LIBOR","Tenor":"3M","SpreadAdjustment":0.26161,"CompoundedValue":0.04}`))
+	}))
+	defer server.Close()
+
+	dateInit := time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)
+	rate, err := NewClient(server.URL).IBORFallbackRate(context.Background(), "USD-LIBOR", "3M", dateInit, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rate.Convention != ratederivatives.ConventionObservationShift || rate.Shift != 2 || rate.SpreadAdjustment != 0.26161 {
+		t.Errorf("fallback rate was incorrect, got: %v.", rate)
+	}
+}
@@ -0,0 +1,782 @@
+package restClient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+const dateFormat = "2006-01-02"
+
+// getList decodes the response into the slice @out. Some endpoints return a single
+// object instead of a slice of length 1. These are wrapped accordingly.
+func (c *Client) getList(ctx context.Context, path string, query url.Values, out interface{}) error {
+	var raw json.RawMessage
+	err := c.get(ctx, path, query, &raw)
+	if err != nil {
+		return err
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if raw[0] == '{' {
+		raw = append(append([]byte{'['}, raw...), ']')
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// -----------------------------------------------------------------------------
+// QUOTATIONS AND TRADES
+// -----------------------------------------------------------------------------
+
+// Quotation returns the quotation of the asset with highest volume among all assets with @symbol.
+func (c *Client) Quotation(ctx context.Context, symbol string) (quotation models.AssetQuotationFull, err error) {
+	err = c.get(ctx, pathOf("quotation", symbol), nil, &quotation)
+	return
+}
+
+// AssetQuotation returns the quotation of the asset uniquely defined by @blockchain and @address.
+func (c *Client) AssetQuotation(ctx context.Context, blockchain, address string) (quotation models.AssetQuotationFull, err error) {
+	err = c.get(ctx, pathOf("assetQuotation", blockchain, address), nil, &quotation)
+	return
+}
+
+// AssetQuotationAt returns the quotation of an asset at @timestamp derived according to @policy.
+// A zero @maxStaleness leaves the age of the price points unbounded.
+func (c *Client) AssetQuotationAt(ctx context.Context, blockchain, address string, timestamp time.Time, maxStaleness time.Duration, policy models.QuotationPolicy) (quotation models.PointInTimeQuotation, err error) {
+	query := url.Values{}
+	query.Set("timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+	if maxStaleness > 0 {
+		query.Set("maxStaleness", strconv.FormatInt(int64(maxStaleness/time.Second), 10))
+	}
+	if policy != "" {
+		query.Set("policy", string(policy))
+	}
+	err = c.get(ctx, pathOf("assetQuotationAt", blockchain, address), query, &quotation)
+	return
+}
+
+// AssetQuotations returns the quotations of all @assets in a single request. Only blockchain and
+// address of the assets are used. If @timestamp is zero, the latest quotations are returned.
+// Assets without quotation are omitted from the result.
+func (c *Client) AssetQuotations(ctx context.Context, assets []dia.Asset, timestamp time.Time) (quotations []models.AssetQuotationFull, err error) {
+	type requestAsset struct {
+		Blockchain string
+		Address    string
+	}
+	request := struct {
+		Assets    []requestAsset
+		Timestamp int64 `json:",omitempty"`
+	}{}
+	for _, asset := range assets {
+		request.Assets = append(request.Assets, requestAsset{Blockchain: asset.Blockchain, Address: asset.Address})
+	}
+	if !timestamp.IsZero() {
+		request.Timestamp = timestamp.Unix()
+	}
+	err = c.post(ctx, pathOf("assetQuotations"), request, &quotations)
+	return
+}
+
+// LastTrades returns the last 1000 trades of the asset with highest volume among all assets with @symbol.
+func (c *Client) LastTrades(ctx context.Context, symbol string) (trades []dia.Trade, err error) {
+	err = c.get(ctx, pathOf("lastTrades", symbol), nil, &trades)
+	return
+}
+
+// LastTradesAsset returns the last @numTrades trades of an asset, optionally restricted to @exchange.
+// @numTrades=0 uses the default of the API.
+func (c *Client) LastTradesAsset(ctx context.Context, blockchain, address, exchange string, numTrades int) (trades []dia.Trade, err error) {
+	query := url.Values{}
+	if exchange != "" {
+		query.Set("exchange", exchange)
+	}
+	if numTrades > 0 {
+		query.Set("numTrades", strconv.Itoa(numTrades))
+	}
+	err = c.get(ctx, pathOf("lastTradesAsset", blockchain, address), query, &trades)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// SUPPLIES AND VOLUMES
+// -----------------------------------------------------------------------------
+
+// Supply returns the latest supply of the token with @symbol.
+func (c *Client) Supply(ctx context.Context, symbol string) (supply dia.Supply, err error) {
+	err = c.get(ctx, pathOf("supply", symbol), nil, &supply)
+	return
+}
+
+// AssetSupply returns the supplies of an asset in @timeRange, or the latest supply if @timeRange is empty.
+func (c *Client) AssetSupply(ctx context.Context, blockchain, address string, timeRange TimeRange) (supplies []dia.Supply, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.getList(ctx, pathOf("assetSupply", blockchain, address), query, &supplies)
+	return
+}
+
+// Supplies returns the supplies of the token with @symbol in @timeRange.
+func (c *Client) Supplies(ctx context.Context, symbol string, timeRange TimeRange) (supplies []dia.Supply, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("supplies", symbol), query, &supplies)
+	return
+}
+
+// DiaTotalSupply returns the total supply of the DIA token.
+func (c *Client) DiaTotalSupply(ctx context.Context) (supply float64, err error) {
+	err = c.get(ctx, pathOf("diaTotalSupply"), nil, &supply)
+	return
+}
+
+// DiaCirculatingSupply returns the circulating supply of the DIA token.
+func (c *Client) DiaCirculatingSupply(ctx context.Context) (supply float64, err error) {
+	err = c.get(ctx, pathOf("diaCirculatingSupply"), nil, &supply)
+	return
+}
+
+// Volume returns the trading volume of @symbol in @timeRange.
+func (c *Client) Volume(ctx context.Context, symbol string, timeRange TimeRange) (volume float64, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("volume", symbol), query, &volume)
+	return
+}
+
+// Volume24 returns the 24h trading volume on @exchange.
+func (c *Client) Volume24(ctx context.Context, exchange string) (volume float64, err error) {
+	err = c.get(ctx, pathOf("volume24", exchange), nil, &volume)
+	return
+}
+
+// FeedStats returns volume and trade statistics of an asset. If @timeRange is empty,
+// the statistics of the last 24h are returned.
+func (c *Client) FeedStats(ctx context.Context, blockchain, address string, timeRange TimeRange) (stats []FeedStats, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.getList(ctx, pathOf("feedStats", blockchain, address), query, &stats)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// SYMBOLS, ASSETS AND EXCHANGES
+// -----------------------------------------------------------------------------
+
+// Symbols returns all symbols, optionally restricted to those containing @substring,
+// those traded on @exchange or the @top symbols by volume. Empty values are ignored.
+func (c *Client) Symbols(ctx context.Context, substring, exchange string, top int) (symbols []string, err error) {
+	query := url.Values{}
+	if exchange != "" {
+		query.Set("exchange", exchange)
+	}
+	if top > 0 {
+		query.Set("top", strconv.Itoa(top))
+	}
+	path := pathOf("symbols")
+	if substring != "" {
+		path = pathOf("symbols", substring)
+	}
+	err = c.get(ctx, path, query, &symbols)
+	return
+}
+
+// Pairs returns all exchange pairs.
+func (c *Client) Pairs(ctx context.Context) (pairs []dia.ExchangePair, err error) {
+	var p models.Pairs
+	err = c.get(ctx, pathOf("pairs"), nil, &p)
+	pairs = p.Pairs
+	return
+}
+
+// Exchanges returns all exchanges.
+func (c *Client) Exchanges(ctx context.Context) (exchanges []string, err error) {
+	err = c.get(ctx, pathOf("exchanges"), nil, &exchanges)
+	return
+}
+
+// MissingTokens returns all unverified symbols on @exchange.
+func (c *Client) MissingTokens(ctx context.Context, exchange string) (symbols []string, err error) {
+	err = c.get(ctx, pathOf("missingToken", exchange), nil, &symbols)
+	return
+}
+
+// Tokens returns all assets with @symbol.
+func (c *Client) Tokens(ctx context.Context, symbol string) (assets []dia.Asset, err error) {
+	err = c.get(ctx, pathOf("token", symbol), nil, &assets)
+	return
+}
+
+// TokenExchanges returns all exchanges on which @symbol is traded.
+func (c *Client) TokenExchanges(ctx context.Context, symbol string) (exchanges []string, err error) {
+	err = c.get(ctx, pathOf("tokenexchanges", symbol), nil, &exchanges)
+	return
+}
+
+// Blockchains returns the names of all blockchains.
+func (c *Client) Blockchains(ctx context.Context) (blockchains []string, err error) {
+	err = c.get(ctx, pathOf("blockchains"), nil, &blockchains)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// CHART POINTS
+// -----------------------------------------------------------------------------
+
+// ChartPoints returns the values of @filter for @symbol on @exchange in @timeRange.
+// @scale is one of 5m 30m 1h 4h 1d 1w and is ignored if empty.
+func (c *Client) ChartPoints(ctx context.Context, filter, exchange, symbol, scale string, timeRange TimeRange) (points models.Points, err error) {
+	query := url.Values{}
+	if scale != "" {
+		query.Set("scale", scale)
+	}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("chartPoints", filter, exchange, symbol), query, &points)
+	return
+}
+
+// AssetChartPoints returns the values of @filter for an asset in @timeRange, optionally restricted to @exchange.
+func (c *Client) AssetChartPoints(ctx context.Context, filter, blockchain, address, exchange string, timeRange TimeRange) (points models.Points, err error) {
+	query := url.Values{}
+	if exchange != "" {
+		query.Set("exchange", exchange)
+	}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("assetChartPoints", filter, blockchain, address), query, &points)
+	return
+}
+
+// ChartPointsAllExchanges returns the values of @filter for @symbol across all exchanges in @timeRange.
+func (c *Client) ChartPointsAllExchanges(ctx context.Context, filter, symbol, scale string, timeRange TimeRange) (points models.Points, err error) {
+	query := url.Values{}
+	if scale != "" {
+		query.Set("scale", scale)
+	}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("chartPointsAllExchanges", filter, symbol), query, &points)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// INDICES
+// -----------------------------------------------------------------------------
+
+// CviIndex returns the values of the CVI index in @timeRange, optionally for @symbol.
+func (c *Client) CviIndex(ctx context.Context, symbol string, timeRange TimeRange) (values []dia.CviDataPoint, err error) {
+	query := url.Values{}
+	if symbol != "" {
+		query.Set("symbol", symbol)
+	}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("cviIndex"), query, &values)
+	return
+}
+
+// Index returns at most @maxResults values of the crypto index with @symbol in @timeRange.
+func (c *Client) Index(ctx context.Context, symbol string, timeRange TimeRange, maxResults int) (indices []models.CryptoIndex, err error) {
+	query := url.Values{}
+	if maxResults > 0 {
+		query.Set("maxResults", strconv.Itoa(maxResults))
+	}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("index", symbol), query, &indices)
+	return
+}
+
+// IndexValues returns the values of the crypto index with @symbol in @timeRange.
+// If @frequency is set, values are spaced accordingly.
+func (c *Client) IndexValues(ctx context.Context, symbol string, timeRange TimeRange, frequency string) (values []IndexValue, err error) {
+	query := url.Values{}
+	if frequency != "" {
+		query.Set("frequency", frequency)
+	}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("indexValue", symbol), query, &values)
+	return
+}
+
+// BenchmarkedIndexValue returns the values of the benchmarked index with @symbol in @timeRange.
+func (c *Client) BenchmarkedIndexValue(ctx context.Context, symbol string, timeRange TimeRange) (index models.BenchmarkedIndex, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("benchmarkedIndexValue", symbol), query, &index)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// DEFI AND FARMING POOLS
+// -----------------------------------------------------------------------------
+
+// DefiLendingProtocols returns all DeFi lending protocols.
+func (c *Client) DefiLendingProtocols(ctx context.Context) (protocols []dia.DefiProtocol, err error) {
+	err = c.get(ctx, pathOf("defiLendingProtocols"), nil, &protocols)
+	return
+}
+
+// DefiLendingRate returns the latest lending market data of @asset on @protocol before @timestamp.
+// @asset is the symbol or the address of the asset. A zero @timestamp returns the most recent data.
+func (c *Client) DefiLendingRate(ctx context.Context, protocol, asset string, timestamp time.Time) (rate dia.DefiRate, err error) {
+	path := pathOf("defiLendingRate", protocol, asset)
+	if !timestamp.IsZero() {
+		path = pathOf("defiLendingRate", protocol, asset, strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	err = c.get(ctx, path, nil, &rate)
+	return
+}
+
+// DefiLendingRates returns all lending market data of @asset on @protocol in @timeRange.
+func (c *Client) DefiLendingRates(ctx context.Context, protocol, asset string, timeRange TimeRange) (rates []dia.DefiRate, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "dateInit", "dateFinal")
+	err = c.get(ctx, pathOf("defiLendingRate", protocol, asset), query, &rates)
+	return
+}
+
+// DefiLendingState returns the latest state of @protocol before @timestamp.
+// A zero @timestamp returns the most recent state.
+func (c *Client) DefiLendingState(ctx context.Context, protocol string, timestamp time.Time) (state dia.DefiProtocolState, err error) {
+	path := pathOf("defiLendingState", protocol)
+	if !timestamp.IsZero() {
+		path = pathOf("defiLendingState", protocol, strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	err = c.get(ctx, path, nil, &state)
+	return
+}
+
+// DefiLendingStates returns all states of @protocol in @timeRange.
+func (c *Client) DefiLendingStates(ctx context.Context, protocol string, timeRange TimeRange) (states []dia.DefiProtocolState, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "dateInit", "dateFinal")
+	err = c.get(ctx, pathOf("defiLendingState", protocol), query, &states)
+	return
+}
+
+// DefiCollateralAtRisk returns the collateral-at-risk curve of @asset on @protocol for price drops up to @maxDrop
+// in steps of @step, both in per cent. Zero values use the defaults of the API, i.e. 50 and 5 per cent.
+func (c *Client) DefiCollateralAtRisk(ctx context.Context, protocol, asset string, maxDrop, step float64) (curve DefiCollateralAtRisk, err error) {
+	query := url.Values{}
+	if maxDrop > 0 {
+		query.Set("maxDrop", strconv.FormatFloat(maxDrop, 'f', -1, 64))
+	}
+	if step > 0 {
+		query.Set("step", strconv.FormatFloat(step, 'f', -1, 64))
+	}
+	err = c.get(ctx, pathOf("defiCollateralAtRisk", protocol, asset), query, &curve)
+	return
+}
+
+// DefiHealthFactors returns the distribution of the health factors of the borrowers on @protocol.
+func (c *Client) DefiHealthFactors(ctx context.Context, protocol string) (healthFactors DefiHealthFactors, err error) {
+	err = c.get(ctx, pathOf("defiHealthFactors", protocol), nil, &healthFactors)
+	return
+}
+
+// DefiLiquidations returns the liquidations on @protocol in @timeRange.
+// An empty @timeRange uses the default of the API, i.e. the last 7 days.
+func (c *Client) DefiLiquidations(ctx context.Context, protocol string, timeRange TimeRange) (liquidations DefiLiquidations, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("defiLiquidations", protocol), query, &liquidations)
+	return
+}
+
+// MakerDAOIlks returns the latest state of all MakerDAO collateral types (ilks).
+func (c *Client) MakerDAOIlks(ctx context.Context) (ilks []models.MakerDAOIlk, err error) {
+	err = c.get(ctx, pathOf("defiMakerDAOIlks"), nil, &ilks)
+	return
+}
+
+// MakerDAOIlk returns the states of the MakerDAO collateral type @ilk in @timeRange.
+// An empty @timeRange uses the default of the API, i.e. the last 7 days.
+func (c *Client) MakerDAOIlk(ctx context.Context, ilk string, timeRange TimeRange) (states []models.MakerDAOIlk, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("defiMakerDAOIlk", ilk), query, &states)
+	return
+}
+
+// MakerDAOVaults returns the collateralisation distributions of the vaults of @ilk in @timeRange.
+// An empty @timeRange uses the default of the API, i.e. the last 7 days.
+func (c *Client) MakerDAOVaults(ctx context.Context, ilk string, timeRange TimeRange) (stats []models.MakerDAOVaultStats, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("defiMakerDAOVaults", ilk), query, &stats)
+	return
+}
+
+// MakerDAOSavings returns the states of the MakerDAO DAI savings rate in @timeRange.
+// An empty @timeRange uses the default of the API, i.e. the last 7 days.
+func (c *Client) MakerDAOSavings(ctx context.Context, timeRange TimeRange) (savings []models.MakerDAOSavings, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("defiMakerDAOSavings"), query, &savings)
+	return
+}
+
+// DefiTVL returns the TVL of @protocol in @timeRange on @blockchain, or summed over all blockchains if it is empty.
+// An empty @timeRange uses the default of the API, i.e. the last 7 days.
+func (c *Client) DefiTVL(ctx context.Context, protocol string, blockchain string, timeRange TimeRange) (tvls []models.DefiTVLTotal, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	if blockchain != "" {
+		query.Set("blockchain", blockchain)
+	}
+	err = c.get(ctx, pathOf("defiTVL", protocol), query, &tvls)
+	return
+}
+
+// DefiTVLAssets returns the values of the assets held by @protocol in @timeRange on @blockchain, or on all
+// blockchains if it is empty. An empty @timeRange returns the assets of the latest update per blockchain.
+func (c *Client) DefiTVLAssets(ctx context.Context, protocol string, blockchain string, timeRange TimeRange) (tvls []models.DefiTVL, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	if blockchain != "" {
+		query.Set("blockchain", blockchain)
+	}
+	err = c.get(ctx, pathOf("defiTVLAssets", protocol), query, &tvls)
+	return
+}
+
+// FarmingPools returns all farming pools.
+func (c *Client) FarmingPools(ctx context.Context) (pools []models.FarmingPoolType, err error) {
+	err = c.get(ctx, pathOf("farmingPools"), nil, &pools)
+	return
+}
+
+// FarmingPoolData returns the latest data of the pool @poolID on @protocol before @timestamp.
+// A zero @timestamp returns the most recent data.
+func (c *Client) FarmingPoolData(ctx context.Context, protocol, poolID string, timestamp time.Time) (pool models.FarmingPool, err error) {
+	path := pathOf("farmingPoolData", protocol, poolID)
+	if !timestamp.IsZero() {
+		path = pathOf("farmingPoolData", protocol, poolID, strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	err = c.get(ctx, path, nil, &pool)
+	return
+}
+
+// FarmingPoolDataRange returns all data of the pool @poolID on @protocol in @timeRange.
+func (c *Client) FarmingPoolDataRange(ctx context.Context, protocol, poolID string, timeRange TimeRange) (pools []models.FarmingPool, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "dateInit", "dateFinal")
+	err = c.get(ctx, pathOf("farmingPoolData", protocol, poolID), query, &pools)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// INTEREST RATES
+// -----------------------------------------------------------------------------
+
+// InterestRates returns the meta information of all interest rates.
+func (c *Client) InterestRates(ctx context.Context) (rates []models.InterestRateMeta, err error) {
+	err = c.get(ctx, pathOf("interestrates"), nil, &rates)
+	return
+}
+
+// InterestRate returns the value of the interest rate with @symbol at @date.
+// A zero @date returns the most recent value.
+func (c *Client) InterestRate(ctx context.Context, symbol string, date time.Time) (rate models.InterestRate, err error) {
+	path := pathOf("interestrate", symbol)
+	if !date.IsZero() {
+		path = pathOf("interestrate", symbol, date.Format(dateFormat))
+	}
+	err = c.get(ctx, path, nil, &rate)
+	return
+}
+
+// InterestRateRange returns the values of the interest rate with @symbol from @dateInit to @dateFinal.
+func (c *Client) InterestRateRange(ctx context.Context, symbol string, dateInit, dateFinal time.Time) (rates []models.InterestRate, err error) {
+	query := url.Values{}
+	query.Set("dateInit", dateInit.Format(dateFormat))
+	query.Set("dateFinal", dateFinal.Format(dateFormat))
+	err = c.get(ctx, pathOf("interestrate", symbol), query, &rates)
+	return
+}
+
+// CompoundedRate returns the compounded index of the rate with @symbol at @date
+// using @daysPerYear as day count convention. A zero @date returns the most recent value.
+func (c *Client) CompoundedRate(ctx context.Context, symbol string, daysPerYear int, date time.Time) (rate models.InterestRate, err error) {
+	path := pathOf("compoundedRate", symbol, strconv.Itoa(daysPerYear))
+	if !date.IsZero() {
+		path = pathOf("compoundedRate", symbol, strconv.Itoa(daysPerYear), date.Format(dateFormat))
+	}
+	err = c.get(ctx, path, nil, &rate)
+	return
+}
+
+// CompoundedRateRange returns the compounded index of the rate with @symbol from @dateInit to @dateFinal.
+func (c *Client) CompoundedRateRange(ctx context.Context, symbol string, daysPerYear int, dateInit, dateFinal time.Time) (rates []models.InterestRate, err error) {
+	query := url.Values{}
+	query.Set("dateInit", dateInit.Format(dateFormat))
+	query.Set("dateFinal", dateFinal.Format(dateFormat))
+	err = c.get(ctx, pathOf("compoundedRate", symbol, strconv.Itoa(daysPerYear)), query, &rates)
+	return
+}
+
+// CompoundedRateInArrears returns the rate with @symbol compounded in arrears from @dateInit to @dateFinal
+// with @convention and a shift of @shift business days.
+func (c *Client) CompoundedRateInArrears(ctx context.Context, symbol string, daysPerYear int, dateInit, dateFinal time.Time, convention ratederivatives.Convention, shift int) (rate models.CompoundedRateInArrears, err error) {
+	query := url.Values{}
+	query.Set("dateInit", dateInit.Format(dateFormat))
+	query.Set("dateFinal", dateFinal.Format(dateFormat))
+	query.Set("convention", string(convention))
+	query.Set("shift", strconv.Itoa(shift))
+	err = c.get(ctx, pathOf("compoundedRateInArrears", symbol, strconv.Itoa(daysPerYear)), query, &rate)
+	return
+}
+
+// IBORFallbackRate returns the ISDA fallback rate of @ibor for @tenor starting on @dateInit. The period ends
+// at the end of @tenor for a zero @dateFinal.
+func (c *Client) IBORFallbackRate(ctx context.Context, ibor, tenor string, dateInit, dateFinal time.Time) (rate models.CompoundedRateInArrears, err error) {
+	query := url.Values{}
+	query.Set("dateInit", dateInit.Format(dateFormat))
+	if !dateFinal.IsZero() {
+		query.Set("dateFinal", dateFinal.Format(dateFormat))
+	}
+	err = c.get(ctx, pathOf("compoundedRateFallback", ibor, tenor), query, &rate)
+	return
+}
+
+// CompoundedAvg returns the average of the rate with @symbol compounded over the
+// @calDays calendar days before @date.
+func (c *Client) CompoundedAvg(ctx context.Context, symbol string, calDays, daysPerYear int, date time.Time) (rate models.InterestRate, err error) {
+	err = c.get(ctx, pathOf("compoundedAvg", symbol, strconv.Itoa(calDays), strconv.Itoa(daysPerYear), date.Format(dateFormat)), nil, &rate)
+	return
+}
+
+// CompoundedAvgRange returns compounded averages of the rate with @symbol from @dateInit to @dateFinal.
+func (c *Client) CompoundedAvgRange(ctx context.Context, symbol string, calDays, daysPerYear int, dateInit, dateFinal time.Time) (rates []models.InterestRate, err error) {
+	query := url.Values{}
+	query.Set("dateInit", dateInit.Format(dateFormat))
+	query.Set("dateFinal", dateFinal.Format(dateFormat))
+	err = c.get(ctx, pathOf("compoundedAvg", symbol, strconv.Itoa(calDays), strconv.Itoa(daysPerYear)), query, &rates)
+	return
+}
+
+// CompoundedAvgDIA returns the compounded average of the rate with @symbol at @date
+// following the DIA methodology, i.e. with a rate for every calendar day.
+func (c *Client) CompoundedAvgDIA(ctx context.Context, symbol string, calDays, daysPerYear int, date time.Time) (rates []models.InterestRate, err error) {
+	err = c.get(ctx, pathOf("compoundedAvgDIA", symbol, strconv.Itoa(calDays), strconv.Itoa(daysPerYear), date.Format(dateFormat)), nil, &rates)
+	return
+}
+
+// CompoundedAvgDIARange returns compounded averages following the DIA methodology from @dateInit to @dateFinal.
+func (c *Client) CompoundedAvgDIARange(ctx context.Context, symbol string, calDays, daysPerYear int, dateInit, dateFinal time.Time) (rates []models.InterestRate, err error) {
+	query := url.Values{}
+	query.Set("dateInit", dateInit.Format(dateFormat))
+	query.Set("dateFinal", dateFinal.Format(dateFormat))
+	err = c.get(ctx, pathOf("compoundedAvgDIA", symbol, strconv.Itoa(calDays), strconv.Itoa(daysPerYear)), query, &rates)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// FIAT, FOREIGN AND CUSTOMIZED QUOTATIONS
+// -----------------------------------------------------------------------------
+
+// FiatQuotations returns quotations of fiat currencies vs USD.
+func (c *Client) FiatQuotations(ctx context.Context) (change models.Change, err error) {
+	err = c.get(ctx, pathOf("fiatQuotations"), nil, &change)
+	return
+}
+
+// ForeignQuotation returns the quotation of @symbol published by @source before @timestamp.
+// A zero @timestamp returns the most recent quotation.
+func (c *Client) ForeignQuotation(ctx context.Context, source, symbol string, timestamp time.Time) (quotation models.ForeignQuotation, err error) {
+	query := url.Values{}
+	if !timestamp.IsZero() {
+		query.Set("time", strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	err = c.get(ctx, pathOf("foreignQuotation", source, symbol), query, &quotation)
+	return
+}
+
+// ForeignSymbols returns all symbols available from @source.
+func (c *Client) ForeignSymbols(ctx context.Context, source string) (symbols []models.SymbolShort, err error) {
+	err = c.get(ctx, pathOf("foreignSymbols", source), nil, &symbols)
+	return
+}
+
+// VwapFirefly returns the VWAP values of @ticker in @timeRange, or the latest value if @timeRange is empty.
+func (c *Client) VwapFirefly(ctx context.Context, ticker string, timeRange TimeRange) (values []VwapFirefly, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.getList(ctx, pathOf("custom", "vwapFirefly", ticker), query, &values)
+	return
+}
+
+// GoldPaxgOunces returns the PAXG quotation per troy ounce.
+func (c *Client) GoldPaxgOunces(ctx context.Context) (quotation models.Quotation, err error) {
+	err = c.get(ctx, pathOf("goldPaxgOunces"), nil, &quotation)
+	return
+}
+
+// GoldPaxgGrams returns the PAXG quotation per gram.
+func (c *Client) GoldPaxgGrams(ctx context.Context) (quotation models.Quotation, err error) {
+	err = c.get(ctx, pathOf("goldPaxgGrams"), nil, &quotation)
+	return
+}
+
+// -----------------------------------------------------------------------------
+// NFT
+// -----------------------------------------------------------------------------
+
+// AllNFTClasses returns all NFT classes on @blockchain.
+func (c *Client) AllNFTClasses(ctx context.Context, blockchain string) (nftClasses []dia.NFTClass, err error) {
+	err = c.get(ctx, pathOf("AllNFTClasses", blockchain), nil, &nftClasses)
+	return
+}
+
+// NFTClasses returns at most @limit NFT classes starting at @offset.
+// See NFTClassPager for iterating over all classes.
+func (c *Client) NFTClasses(ctx context.Context, limit, offset uint64) (nftClasses []dia.NFTClass, err error) {
+	err = c.get(ctx, pathOf("NFTClasses", strconv.FormatUint(limit, 10), strconv.FormatUint(offset, 10)), nil, &nftClasses)
+	return
+}
+
+// NFTCategories returns all NFT categories.
+func (c *Client) NFTCategories(ctx context.Context) (categories []string, err error) {
+	err = c.get(ctx, pathOf("NFTCategories"), nil, &categories)
+	return
+}
+
+// NFT returns the NFT with @id in the collection given by @blockchain and @address.
+func (c *Client) NFT(ctx context.Context, blockchain, address, id string) (nft dia.NFT, err error) {
+	err = c.get(ctx, pathOf("NFT", blockchain, address, id), nil, &nft)
+	return
+}
+
+// NFTTrades returns all trades of the NFT with @id.
+func (c *Client) NFTTrades(ctx context.Context, blockchain, address, id string) (trades []dia.NFTTrade, err error) {
+	err = c.get(ctx, pathOf("NFTTrades", blockchain, address, id), nil, &trades)
+	return
+}
+
+// NFTTradesCurrent returns all recent trades of the NFT with @id.
+func (c *Client) NFTTradesCurrent(ctx context.Context, blockchain, address, id string) (trades []dia.NFTTrade, err error) {
+	err = c.get(ctx, pathOf("NFTTradesCurrent", blockchain, address, id), nil, &trades)
+	return
+}
+
+// NFTFloor returns the floor price of a collection at @timestamp computed as the @quantile of the sales in @floorWindow.
+// Zero values use the defaults of the API, i.e. now, 24h and models.DefaultNFTFloorQuantile.
+func (c *Client) NFTFloor(ctx context.Context, blockchain, address string, timestamp time.Time, floorWindow time.Duration, quantile float64) (floor NFTFloor, err error) {
+	query := url.Values{}
+	if !timestamp.IsZero() {
+		query.Set("timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	if floorWindow > 0 {
+		query.Set("floorWindow", strconv.FormatInt(int64(floorWindow/time.Second), 10))
+	}
+	if quantile > 0 {
+		query.Set("quantile", strconv.FormatFloat(quantile, 'f', -1, 64))
+	}
+	err = c.get(ctx, pathOf("NFTFloor", blockchain, address), query, &floor)
+	return
+}
+
+// NFTValuation returns the latest appraisal of the nft @tokenID at or before @timestamp.
+// A zero @timestamp returns the latest appraisal.
+func (c *Client) NFTValuation(ctx context.Context, blockchain, address, tokenID string, timestamp time.Time) (valuation NFTValuation, err error) {
+	query := url.Values{}
+	if !timestamp.IsZero() {
+		query.Set("timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	err = c.get(ctx, pathOf("NFTValuation", blockchain, address, tokenID), query, &valuation)
+	return
+}
+
+// NFTRarity returns the rarity scores and ranks of the nft @tokenID within its collection.
+func (c *Client) NFTRarity(ctx context.Context, blockchain, address, tokenID string) (rarity NFTRarity, err error) {
+	err = c.get(ctx, pathOf("NFTRarity", blockchain, address, tokenID), nil, &rarity)
+	return
+}
+
+// NFTClassRarity returns at most @limit nfts of a collection from @offset on, sorted from rarest to most common
+// by the ranking @method. An empty @method and zero @limit use the defaults of the API.
+func (c *Client) NFTClassRarity(ctx context.Context, blockchain, address, method string, limit, offset uint64) (page NFTRarityPage, err error) {
+	query := url.Values{}
+	if method != "" {
+		query.Set("method", method)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.FormatUint(limit, 10))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.FormatUint(offset, 10))
+	}
+	err = c.get(ctx, pathOf("NFTRarity", blockchain, address), query, &page)
+	return
+}
+
+// NFTMarketStats returns the market statistics of a collection per @interval in @timeRange.
+// An empty @interval and @timeRange use the defaults of the API, i.e. daily statistics of the last 30 days.
+func (c *Client) NFTMarketStats(ctx context.Context, blockchain, address, interval string, timeRange TimeRange) (stats NFTMarketStatsRange, err error) {
+	query := url.Values{}
+	if interval != "" {
+		query.Set("interval", interval)
+	}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("NFTMarketStats", blockchain, address), query, &stats)
+	return
+}
+
+// NFTOrders returns the bids and offers of the nft with @tokenID. A non-empty @status restricts
+// them to orders with this status, see dia.NFTOrderPlaced.
+func (c *Client) NFTOrders(ctx context.Context, blockchain, address, tokenID, status string) (book NFTOrderBook, err error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	err = c.get(ctx, pathOf("NFTOrders", blockchain, address, tokenID), query, &book)
+	return
+}
+
+// NFTBestBidOffer returns the best open bid and offer of a collection per currency.
+func (c *Client) NFTBestBidOffer(ctx context.Context, blockchain, address string) (books NFTBestBidOffers, err error) {
+	err = c.get(ctx, pathOf("NFTBestBidOffer", blockchain, address), nil, &books)
+	return
+}
+
+// NFTOracleUpdates returns the updates pushed under @key to the nft oracle @oracle on the chain @chainID in @timeRange.
+// An empty @timeRange uses the default of the API, i.e. the last 7 days.
+func (c *Client) NFTOracleUpdates(ctx context.Context, chainID int64, oracle, key string, timeRange TimeRange) (updates NFTOracleUpdates, err error) {
+	query := url.Values{}
+	timeRange.addUnix(query, "starttime", "endtime")
+	err = c.get(ctx, pathOf("NFTOracleUpdates", strconv.FormatInt(chainID, 10), oracle, key), query, &updates)
+	return
+}
+
+// NFTFloorMA returns the moving average of the floor price of a collection over @lookback.
+// Zero values use the defaults of the API, i.e. 30 days and 24h.
+func (c *Client) NFTFloorMA(ctx context.Context, blockchain, address string, lookback, floorWindow time.Duration) (floor NFTFloorMA, err error) {
+	query := url.Values{}
+	if lookback > 0 {
+		query.Set("lookbackSeconds", strconv.FormatInt(int64(lookback/time.Second), 10))
+	}
+	if floorWindow > 0 {
+		query.Set("floorWindow", strconv.FormatInt(int64(floorWindow/time.Second), 10))
+	}
+	err = c.get(ctx, pathOf("NFTFloorMA", blockchain, address), query, &floor)
+	return
+}
+
+// NFTDownday returns downward movement statistics of the floor price of a collection.
+// Zero values use the defaults of the API, i.e. 90 days and 24h.
+func (c *Client) NFTDownday(ctx context.Context, blockchain, address string, lookback, floorWindow time.Duration) (downday NFTDownday, err error) {
+	query := url.Values{}
+	if lookback > 0 {
+		query.Set("lookbackSeconds", strconv.FormatInt(int64(lookback/time.Second), 10))
+	}
+	if floorWindow > 0 {
+		query.Set("floorWindow", strconv.FormatInt(int64(floorWindow/time.Second), 10))
+	}
+	err = c.get(ctx, pathOf("NFTDownday", blockchain, address), query, &downday)
+	return
+}
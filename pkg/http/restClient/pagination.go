@@ -0,0 +1,91 @@
+package restClient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// NFTClassPager iterates over all NFT classes using the /v1/NFTClasses/:limit/:offset route.
+type NFTClassPager struct {
+	client   *Client
+	pageSize uint64
+	offset   uint64
+	done     bool
+}
+
+// NewNFTClassPager returns a pager that fetches @pageSize NFT classes per request.
+func (c *Client) NewNFTClassPager(pageSize uint64) *NFTClassPager {
+	return &NFTClassPager{client: c, pageSize: pageSize}
+}
+
+// Next returns the next page of NFT classes. It returns an empty slice and
+// false once all classes have been fetched.
+func (p *NFTClassPager) Next(ctx context.Context) ([]dia.NFTClass, bool, error) {
+	if p.done {
+		return []dia.NFTClass{}, false, nil
+	}
+	nftClasses, err := p.client.NFTClasses(ctx, p.pageSize, p.offset)
+	if err != nil {
+		// The API answers with 404 instead of an empty slice once the offset is exhausted.
+		// Any other error, e.g. a rate limit or an unavailable server, is returned to the caller.
+		var apiErr *APIError
+		if p.offset > 0 && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			p.done = true
+			return []dia.NFTClass{}, false, nil
+		}
+		return nil, false, err
+	}
+	p.offset += uint64(len(nftClasses))
+	if uint64(len(nftClasses)) < p.pageSize {
+		p.done = true
+	}
+	return nftClasses, len(nftClasses) > 0, nil
+}
+
+// AllNFTClassesPaged collects all NFT classes fetching @pageSize classes per request.
+func (c *Client) AllNFTClassesPaged(ctx context.Context, pageSize uint64) (nftClasses []dia.NFTClass, err error) {
+	pager := c.NewNFTClassPager(pageSize)
+	for {
+		page, ok, err := pager.Next(ctx)
+		if err != nil {
+			return nftClasses, err
+		}
+		if !ok {
+			return nftClasses, nil
+		}
+		nftClasses = append(nftClasses, page...)
+	}
+}
+
+// SplitTimeRange splits @timeRange into consecutive ranges of length @step.
+// It is used to page through range endpoints that limit the size of their responses.
+func SplitTimeRange(timeRange TimeRange, step time.Duration) (ranges []TimeRange) {
+	if step <= 0 || !timeRange.Start.Before(timeRange.End) {
+		return []TimeRange{timeRange}
+	}
+	for start := timeRange.Start; start.Before(timeRange.End); start = start.Add(step) {
+		end := start.Add(step)
+		if end.After(timeRange.End) {
+			end = timeRange.End
+		}
+		ranges = append(ranges, TimeRange{Start: start, End: end})
+	}
+	return
+}
+
+// AssetChartPointsPaged fetches asset chart points for @timeRange in chunks of length @step.
+func (c *Client) AssetChartPointsPaged(ctx context.Context, filter, blockchain, address, exchange string, timeRange TimeRange, step time.Duration) (pages []models.Points, err error) {
+	for _, tr := range SplitTimeRange(timeRange, step) {
+		points, err := c.AssetChartPoints(ctx, filter, blockchain, address, exchange, tr)
+		if err != nil {
+			return pages, err
+		}
+		pages = append(pages, points)
+	}
+	return
+}
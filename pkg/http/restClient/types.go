@@ -0,0 +1,250 @@
+package restClient
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// TimeRange restricts range queries. Zero values are omitted, in which case
+// the API falls back to the default range of the respective endpoint.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// addUnix adds the range as unix timestamps to @query using the keys @startKey and @endKey.
+func (tr TimeRange) addUnix(query url.Values, startKey, endKey string) {
+	if !tr.Start.IsZero() {
+		query.Set(startKey, strconv.FormatInt(tr.Start.Unix(), 10))
+	}
+	if !tr.End.IsZero() {
+		query.Set(endKey, strconv.FormatInt(tr.End.Unix(), 10))
+	}
+}
+
+// NFTFloor is the response of /v1/NFTFloor.
+type NFTFloor struct {
+	Floor      float64                   `json:"Floor_Price"`
+	FloorUSD   float64                   `json:"Floor_Price_USD"`
+	Currency   string                    `json:"Currency"`
+	Time       time.Time                 `json:"Time"`
+	Source     string                    `json:"Source"`
+	Confidence models.NFTFloorConfidence `json:"Confidence"`
+}
+
+// NFTValuation is the response of /v1/NFTValuation.
+type NFTValuation struct {
+	Address    string                        `json:"Address"`
+	Blockchain string                        `json:"Blockchain"`
+	TokenID    string                        `json:"TokenID"`
+	Value      float64                       `json:"Value"`
+	ValueUSD   float64                       `json:"Value_USD"`
+	Lower      float64                       `json:"Lower_Bound"`
+	Upper      float64                       `json:"Upper_Bound"`
+	Currency   string                        `json:"Currency"`
+	Time       time.Time                     `json:"Time"`
+	Source     string                        `json:"Source"`
+	Components models.NFTValuationComponents `json:"Components"`
+}
+
+// NFTRarity is the response of /v1/NFTRarity/:blockchain/:address/:id.
+type NFTRarity struct {
+	TokenID                string                  `json:"TokenID"`
+	StatisticalRarity      float64                 `json:"StatisticalRarity"`
+	StatisticalRank        int                     `json:"StatisticalRank"`
+	InformationContent     float64                 `json:"InformationContent"`
+	InformationContentRank int                     `json:"InformationContentRank"`
+	TraitCount             int                     `json:"TraitCount"`
+	Traits                 []models.NFTTraitRarity `json:"Traits"`
+	Time                   time.Time               `json:"Time"`
+}
+
+// NFTRarityPage is the response of /v1/NFTRarity/:blockchain/:address.
+type NFTRarityPage struct {
+	Address    string      `json:"Address"`
+	Blockchain string      `json:"Blockchain"`
+	Method     string      `json:"Method"`
+	Total      int         `json:"Total"`
+	Limit      uint64      `json:"Limit"`
+	Offset     uint64      `json:"Offset"`
+	Rarities   []NFTRarity `json:"Rarities"`
+}
+
+// DefiCollateralAtRisk is the response of /v1/defiCollateralAtRisk/:protocol/:asset.
+type DefiCollateralAtRisk struct {
+	Protocol             string                        `json:"Protocol"`
+	Asset                dia.Asset                     `json:"Asset"`
+	Price                float64                       `json:"Price"`
+	LiquidationThreshold float64                       `json:"LiquidationThreshold"`
+	Curve                []models.DefiCollateralAtRisk `json:"Curve"`
+}
+
+// DefiHealthFactors is the response of /v1/defiHealthFactors/:protocol.
+type DefiHealthFactors struct {
+	Protocol    string                          `json:"Protocol"`
+	NumAccounts int                             `json:"NumAccounts"`
+	Buckets     []models.DefiHealthFactorBucket `json:"Buckets"`
+}
+
+// DefiLiquidations is the response of /v1/defiLiquidations/:protocol.
+type DefiLiquidations struct {
+	Protocol     string                   `json:"Protocol"`
+	Liquidations []models.DefiLiquidation `json:"Liquidations"`
+}
+
+// NFTMarketStats are the market statistics of a collection in one interval.
+type NFTMarketStats struct {
+	Time          time.Time `json:"Time"`
+	Currency      dia.Asset `json:"Currency"`
+	Volume        float64   `json:"Volume"`
+	VolumeUSD     float64   `json:"VolumeUSD"`
+	NumSales      int       `json:"NumSales"`
+	UniqueBuyers  int       `json:"UniqueBuyers"`
+	UniqueSellers int       `json:"UniqueSellers"`
+	HolderCount   int       `json:"HolderCount"`
+	FloorPrice    float64   `json:"FloorPrice"`
+	MedianPrice   float64   `json:"MedianPrice"`
+	MeanPrice     float64   `json:"MeanPrice"`
+}
+
+// NFTMarketStatsRange is the response of /v1/NFTMarketStats/:blockchain/:address.
+type NFTMarketStatsRange struct {
+	Address    string           `json:"Address"`
+	Blockchain string           `json:"Blockchain"`
+	Interval   string           `json:"Interval"`
+	Stats      []NFTMarketStats `json:"Stats"`
+}
+
+// NFTOrder is a bid or an offer of the nft order book. Price is the current price in units of Currency,
+// StartPrice and EndPrice are raw amounts in the smallest unit of Currency.
+type NFTOrder struct {
+	TokenID    string    `json:"TokenID"`
+	Exchange   string    `json:"Exchange"`
+	OrderID    string    `json:"OrderID"`
+	Side       string    `json:"Side"`
+	Status     string    `json:"Status"`
+	Maker      string    `json:"Maker"`
+	Price      float64   `json:"Price"`
+	StartPrice string    `json:"StartPrice"`
+	EndPrice   string    `json:"EndPrice,omitempty"`
+	Duration   int64     `json:"DurationSeconds,omitempty"`
+	Currency   dia.Asset `json:"Currency"`
+	Expiry     time.Time `json:"Expiry"`
+	Time       time.Time `json:"Time"`
+	UpdateTime time.Time `json:"UpdateTime"`
+	TxHash     string    `json:"TxHash"`
+}
+
+// NFTOrderBook is the response of /v1/NFTOrders/:blockchain/:address/:id.
+type NFTOrderBook struct {
+	Address    string     `json:"Address"`
+	Blockchain string     `json:"Blockchain"`
+	TokenID    string     `json:"TokenID"`
+	Orders     []NFTOrder `json:"Orders"`
+}
+
+// NFTBestBidOffer is the best bid and offer of a collection in one currency. BestBid and BestOffer are nil
+// if there is no open order on their side.
+type NFTBestBidOffer struct {
+	Currency  dia.Asset `json:"Currency"`
+	BestBid   *NFTOrder `json:"BestBid"`
+	BestOffer *NFTOrder `json:"BestOffer"`
+	Spread    float64   `json:"Spread"`
+	NumBids   int       `json:"NumBids"`
+	NumOffers int       `json:"NumOffers"`
+}
+
+// NFTBestBidOffers is the response of /v1/NFTBestBidOffer/:blockchain/:address.
+type NFTBestBidOffers struct {
+	Address    string            `json:"Address"`
+	Blockchain string            `json:"Blockchain"`
+	Time       time.Time         `json:"Time"`
+	Books      []NFTBestBidOffer `json:"Books"`
+}
+
+// NFTOracleUpdate is an update pushed to an nft oracle contract.
+type NFTOracleUpdate struct {
+	Address    string    `json:"Address"`
+	Blockchain string    `json:"Blockchain"`
+	Floor      float64   `json:"Floor_Price"`
+	FloorMA    float64   `json:"Moving_Average_Floor_Price"`
+	FloorUSD   float64   `json:"Floor_Price_USD"`
+	Currency   string    `json:"Currency"`
+	Confidence float64   `json:"Confidence"`
+	NumTrades  int       `json:"Num_Trades"`
+	Values     [5]uint64 `json:"Values"`
+	ValueTime  time.Time `json:"Value_Time"`
+	Reason     string    `json:"Reason"`
+	Signer     string    `json:"Signer"`
+	Signature  string    `json:"Signature"`
+	TxHash     string    `json:"Tx_Hash"`
+	Status     string    `json:"Status"`
+	Time       time.Time `json:"Time"`
+}
+
+// NFTOracleUpdates is the response of /v1/NFTOracleUpdates/:chainID/:oracle/:key.
+type NFTOracleUpdates struct {
+	Oracle  string            `json:"Oracle"`
+	ChainID int64             `json:"Chain_ID"`
+	Key     string            `json:"Key"`
+	Updates []NFTOracleUpdate `json:"Updates"`
+}
+
+// NFTFloorMA is the response of /v1/NFTFloorMA.
+type NFTFloorMA struct {
+	Floor    float64   `json:"Moving_Average_Floor_Price"`
+	Currency string    `json:"Currency"`
+	Time     time.Time `json:"Time"`
+	Source   string    `json:"Source"`
+}
+
+// NFTDownday is the response of /v1/NFTDownday.
+type NFTDownday struct {
+	WeeklyDrawdown   float64   `json:"Weekly_Drawdown"`
+	DowndayAverage   float64   `json:"Downday_Average"`
+	DowndayDeviation float64   `json:"Downday_Deviation"`
+	Time             time.Time `json:"Time"`
+	Source           string    `json:"Source"`
+}
+
+// FeedStats is an element of the response of /v1/feedStats.
+type FeedStats struct {
+	Timestamp          time.Time
+	TotalVolume        float64
+	Price              float64
+	TradesDistribution TradesDistribution
+	ExchangeVolumes    []dia.ExchangeVolume
+	PairVolumes        []dia.PairVolume
+}
+
+// TradesDistribution is the reduced trades distribution returned as part of FeedStats.
+type TradesDistribution struct {
+	NumTradesTotal   int     `json:"NumTradesTotal"`
+	NumBins          int     `json:"NumBins"`
+	NumLowBins       int     `json:"NumberLowBins"`
+	Threshold        int     `json:"Threshold"`
+	SizeBinSeconds   int64   `json:"SizeBin"`
+	AvgNumPerBin     float64 `json:"AverageNumberPerBin"`
+	StdDeviation     float64 `json:"StandardDeviation"`
+	TimeRangeSeconds int64   `json:"TimeRangeSeconds"`
+}
+
+// IndexValue is an element of the response of /v1/indexValue.
+type IndexValue struct {
+	Symbol          string
+	Address         string
+	Blockchain      string
+	Value           float64
+	CalculationTime time.Time
+}
+
+// VwapFirefly is an element of the response of /v1/custom/vwapFirefly.
+type VwapFirefly struct {
+	Ticker    string
+	Value     float64
+	Timestamp time.Time
+}
@@ -0,0 +1,318 @@
+package diaApi
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	apiKeyHeader     = "X-API-KEY"
+	apiKeyQueryParam = "apikey"
+	anonymousPlan    = "free"
+)
+
+// defaultAnonymousLimit applies to requests without API key if the free plan cannot be loaded.
+var defaultAnonymousLimit = models.RouteLimit{Rate: 1, Burst: 10}
+
+// APIKeyAuth returns a middleware identifying the caller by the API key given in the
+// X-API-KEY header or the apikey query parameter. It enforces the per-route rate limits and
+// the daily quota of the key's plan and accounts the request. Requests without a key are
+// rejected if @required is set, otherwise they are limited per client IP with the free plan.
+// The client IP is the remote address unless it is one of the @trustedProxies, see ClientIP.
+func (env *Env) APIKeyAuth(required bool, trustedProxies []*net.IPNet) gin.HandlerFunc {
+	freePlan, err := env.RelDB.GetAPIPlan(anonymousPlan)
+	if err != nil {
+		log.Errorf("get %s api plan, falling back to default limit: %v", anonymousPlan, err)
+		freePlan = models.APIPlan{Name: anonymousPlan, Limit: defaultAnonymousLimit}
+	}
+
+	return func(c *gin.Context) {
+		subject := "ip:" + ClientIP(c.Request, trustedProxies)
+		plan := freePlan
+
+		key := c.GetHeader(apiKeyHeader)
+		if key == "" {
+			key = c.Query(apiKeyQueryParam)
+		}
+		if key != "" {
+			apiKey, err := env.RelDB.GetAPIKey(key)
+			if err != nil {
+				if errors.Is(err, models.ErrAPIKeyUnknown) || errors.Is(err, models.ErrAPIKeyRevoked) {
+					restApi.SendError(c, http.StatusUnauthorized, err)
+				} else {
+					log.Error("get api key: ", err)
+					restApi.SendError(c, http.StatusInternalServerError, errors.New("could not verify api key"))
+				}
+				c.Abort()
+				return
+			}
+			subject = apiKey.ID
+			plan = apiKey.Plan
+		} else if required {
+			restApi.SendError(c, http.StatusUnauthorized, errors.New("missing api key"))
+			c.Abort()
+			return
+		}
+
+		route := c.FullPath()
+		result, err := env.RelDB.TakeRateLimitToken(subject, route, plan.LimitForRoute(route))
+		if err != nil {
+			// Fail open, an unavailable redis should not take down the API.
+			log.Error("take rate limit token: ", err)
+			c.Next()
+			return
+		}
+		if result.Limit > 0 {
+			// Responses replayed by the page cache carry the headers of the cached request, so the
+			// headers of this request are only set when the response is written.
+			c.Writer = &rateLimitWriter{ResponseWriter: c.Writer, headers: map[string]string{
+				"X-RateLimit-Limit":     strconv.Itoa(result.Limit),
+				"X-RateLimit-Remaining": strconv.Itoa(result.Remaining),
+				"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Add(result.Reset).Unix(), 10),
+			}}
+		}
+		if !result.Allowed {
+			c.Header("Retry-After", retryAfterSeconds(result.RetryAfter))
+			restApi.SendError(c, http.StatusTooManyRequests, errors.New("rate limit exceeded"))
+			c.Abort()
+			return
+		}
+
+		// Only requests passing the rate limit are counted, and none once the quota is exhausted.
+		_, err = env.RelDB.IncrementAPIUsage(subject, route, plan.DailyQuota)
+		if errors.Is(err, models.ErrAPIQuotaExceeded) {
+			now := time.Now().UTC()
+			midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			c.Header("Retry-After", retryAfterSeconds(midnight.Sub(now)))
+			restApi.SendError(c, http.StatusTooManyRequests, err)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			log.Error("increment api usage: ", err)
+		}
+		c.Next()
+	}
+}
+
+// rateLimitWriter sets the rate limit @headers right before the response is written.
+type rateLimitWriter struct {
+	gin.ResponseWriter
+	headers map[string]string
+}
+
+func (w *rateLimitWriter) setHeaders() {
+	for key, value := range w.headers {
+		w.ResponseWriter.Header().Set(key, value)
+	}
+}
+
+func (w *rateLimitWriter) WriteHeaderNow() {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *rateLimitWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *rateLimitWriter) WriteString(s string) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ParseTrustedProxies parses the comma separated IPs and CIDR ranges of the proxies in front of the API.
+func ParseTrustedProxies(proxies string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, proxy := range strings.Split(proxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.New("invalid trusted proxy " + proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			proxy += "/" + strconv.Itoa(bits)
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ClientIP returns the IP of the client of @request. It is the remote address, unless that is one of the
+// @trustedProxies. The client is then the last address in X-Forwarded-For that is not a trusted proxy, as
+// the addresses before it can be set by the client.
+func ClientIP(request *http.Request, trustedProxies []*net.IPNet) string {
+	remote, _, err := net.SplitHostPort(strings.TrimSpace(request.RemoteAddr))
+	if err != nil {
+		remote = strings.TrimSpace(request.RemoteAddr)
+	}
+	if !trusted(net.ParseIP(remote), trustedProxies) {
+		return remote
+	}
+	forwarded := strings.Split(request.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+		if !trusted(ip, trustedProxies) {
+			return ip.String()
+		}
+	}
+	return remote
+}
+
+func trusted(ip net.IP, trustedProxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfterSeconds formats @d as value of the Retry-After header.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// FlushAPIUsage periodically persists the usage counters of the current and the previous day.
+func (env *Env) FlushAPIUsage(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		now := time.Now()
+		for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+			err := env.RelDB.FlushAPIUsage(day)
+			if err != nil {
+				log.Error("flush api usage: ", err)
+			}
+		}
+	}
+}
+
+// PostAPIKey issues a new API key. Input must be of the format:
+// '{"Owner":"owner","Plan":"free"}'
+// The plain key is only contained in the response.
+func (env *Env) PostAPIKey(c *gin.Context) {
+	var input struct {
+		Owner string
+		Plan  string
+	}
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, errors.New("ReadAll"))
+		return
+	}
+	err = json.Unmarshal(body, &input)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	key, apiKey, err := env.RelDB.CreateAPIKey(input.Owner, input.Plan)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"Key": key, "APIKey": apiKey})
+}
+
+// RevokeAPIKey revokes the API key with the ID given in the path.
+func (env *Env) RevokeAPIKey(c *gin.Context) {
+	keyID := c.Param("keyID")
+	err := env.RelDB.RevokeAPIKey(keyID)
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyUnknown) {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ID": keyID, "Revoked": true})
+}
+
+// GetAPIKeyUsage returns the daily requests per endpoint of the API key with the ID given
+// in the path. The range defaults to the last 30 days.
+func (env *Env) GetAPIKeyUsage(c *gin.Context) {
+	keyID := c.Param("keyID")
+	endtime := time.Now()
+	starttime := endtime.AddDate(0, 0, -30)
+	if starttimeStr := c.Query("starttime"); starttimeStr != "" {
+		starttimeInt, err := strconv.ParseInt(starttimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, errors.New("could not parse starttime"))
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+	if endtimeStr := c.Query("endtime"); endtimeStr != "" {
+		endtimeInt, err := strconv.ParseInt(endtimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, errors.New("could not parse endtime"))
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+
+	usage, err := env.RelDB.GetAPIUsage(keyID, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// PostAPIPlan creates or updates an API plan. Input must be of the format:
+// '{"Name":"pro","Limit":{"Rate":10,"Burst":50},"DailyQuota":100000,"RouteLimits":{"/v1/assetQuotation/:blockchain/:address":{"Rate":20,"Burst":100}}}'
+func (env *Env) PostAPIPlan(c *gin.Context) {
+	var plan models.APIPlan
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, errors.New("ReadAll"))
+		return
+	}
+	err = json.Unmarshal(body, &plan)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if plan.Name == "" {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("missing plan name"))
+		return
+	}
+
+	err = env.RelDB.SetAPIPlan(plan)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
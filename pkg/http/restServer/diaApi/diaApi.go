@@ -12,6 +12,7 @@ import (
 
 	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
 	"github.com/diadata-org/diadata/internal/pkg/indexCalculationService"
+	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/http/restApi"
@@ -158,6 +159,127 @@ func (env *Env) GetAssetQuotation(c *gin.Context) {
 
 }
 
+// GetAssetQuotationAt returns the quotation of an asset at the unix time given by the query parameter
+// timestamp. The query parameter maxStaleness bounds the age of the price points in seconds and
+// policy is one of locf (default), interpolate and failIfStale.
+func (env *Env) GetAssetQuotationAt(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := c.Param("address")
+
+	timestamp := time.Now()
+	if timestampString := c.Query("timestamp"); timestampString != "" {
+		timestampInt, err := strconv.ParseInt(timestampString, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		timestamp = time.Unix(timestampInt, 0)
+	}
+	var maxStaleness time.Duration
+	if maxStalenessString := c.Query("maxStaleness"); maxStalenessString != "" {
+		maxStalenessInt, err := strconv.ParseInt(maxStalenessString, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		maxStaleness = time.Duration(maxStalenessInt) * time.Second
+	}
+	policy, err := models.ParseQuotationPolicy(c.Query("policy"))
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	asset, err := env.RelDB.GetAsset(address, blockchain)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+
+	quotation, err := env.DataStore.GetAssetQuotationAt(asset, timestamp, maxStaleness, policy)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, quotation)
+}
+
+// maxBatchQuotations is the maximal number of assets in a request to PostAssetQuotations.
+const maxBatchQuotations = 500
+
+// assetQuotationsRequest is the body of a batch quotation request. @Timestamp is an optional
+// unix timestamp, the latest quotations are returned if it is omitted.
+type assetQuotationsRequest struct {
+	Assets []struct {
+		Blockchain string
+		Address    string
+	}
+	Timestamp int64
+}
+
+// PostAssetQuotations returns the quotations of all assets in the request body. Input must be of the format:
+// '{"Assets":[{"Blockchain":"Ethereum","Address":"0x..."}],"Timestamp":1640995200}'
+// Assets without quotation are omitted from the response.
+func (env *Env) PostAssetQuotations(c *gin.Context) {
+	var input assetQuotationsRequest
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, errors.New("ReadAll"))
+		return
+	}
+	err = json.Unmarshal(body, &input)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(input.Assets) == 0 || len(input.Assets) > maxBatchQuotations {
+		restApi.SendError(c, http.StatusBadRequest, fmt.Errorf("number of assets must be between 1 and %d", maxBatchQuotations))
+		return
+	}
+
+	// Assets are looked up in the asset table, so that checksum and case variants of an address
+	// resolve to the address the quotations are stored with. Unknown assets are omitted.
+	requested := make([]dia.Asset, len(input.Assets))
+	for i, a := range input.Assets {
+		address := a.Address
+		if common.IsHexAddress(address) {
+			address = common.HexToAddress(address).Hex()
+		}
+		requested[i] = dia.Asset{Address: address, Blockchain: a.Blockchain}
+	}
+	assets, err := env.RelDB.GetAssetsByAddress(requested)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(assets) == 0 {
+		c.JSON(http.StatusOK, []models.AssetQuotationFull{})
+		return
+	}
+
+	var quotations []models.AssetQuotationFull
+	if input.Timestamp > 0 {
+		quotations, err = env.DataStore.GetAssetQuotations(assets, time.Unix(input.Timestamp, 0))
+	} else {
+		quotations, err = env.DataStore.GetAssetQuotationsLatest(assets)
+	}
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if quotations == nil {
+		quotations = []models.AssetQuotationFull{}
+	}
+	volumes, err := env.RelDB.GetAssetVolumes24H(assets)
+	if err != nil {
+		log.Warn("get volumes yesterday: ", err)
+	}
+	for i := range quotations {
+		quotations[i].VolumeYesterdayUSD = volumes[models.AssetVolumeKey(quotations[i].Blockchain, quotations[i].Address)]
+	}
+	c.JSON(http.StatusOK, quotations)
+}
+
 // GetQuotation returns quotation of asset with highest market cap among
 // all assets with symbol ticker @symbol.
 func (env *Env) GetQuotation(c *gin.Context) {
@@ -804,10 +926,14 @@ func (env *Env) GetLendingProtocols(c *gin.Context) {
 
 // GetDefiRate is the delegate method to fetch the value(s) of
 // the defi lending rate of @asset at the exchange with @protocol.
+// @asset is either the symbol or the address of the asset.
 // Last value is retrieved. Otional query parameters allow to obtain data in a time range.
 func (env *Env) GetDefiRate(c *gin.Context) {
 	protocol := c.Param("protocol")
 	asset := c.Param("asset")
+	if common.IsHexAddress(asset) {
+		asset = common.HexToAddress(asset).Hex()
+	}
 	date := c.Param("time")
 	// Add optional query parameters for requesting a range of values
 	dateInit := c.DefaultQuery("dateInit", "noRange")
@@ -917,6 +1043,310 @@ func (env *Env) GetDefiState(c *gin.Context) {
 	}
 }
 
+// defiCollateralAtRisk is the collateral-at-risk curve of an asset returned by GetDefiCollateralAtRisk.
+type defiCollateralAtRisk struct {
+	Protocol             string                        `json:"Protocol"`
+	Asset                dia.Asset                     `json:"Asset"`
+	Price                float64                       `json:"Price"`
+	LiquidationThreshold float64                       `json:"LiquidationThreshold"`
+	Curve                []models.DefiCollateralAtRisk `json:"Curve"`
+}
+
+// defiHealthFactors is the distribution of the health factors of the borrowers returned by GetDefiHealthFactors.
+type defiHealthFactors struct {
+	Protocol    string                          `json:"Protocol"`
+	NumAccounts int                             `json:"NumAccounts"`
+	Buckets     []models.DefiHealthFactorBucket `json:"Buckets"`
+}
+
+// defiLiquidations are the liquidations on a protocol returned by GetDefiLiquidations.
+type defiLiquidations struct {
+	Protocol     string                   `json:"Protocol"`
+	Liquidations []models.DefiLiquidation `json:"Liquidations"`
+}
+
+// defiMarketRisks returns the latest DIA price and liquidation threshold of the assets of @positions on @protocol
+// by asset address. Assets without price or lending market data are left out.
+func (env *Env) defiMarketRisks(protocol string, positions []models.DefiPosition) map[string]models.DefiMarketRisk {
+	markets := make(map[string]models.DefiMarketRisk)
+	checked := make(map[string]bool)
+	endtime := time.Now()
+	for _, position := range positions {
+		if checked[position.Asset.Address] {
+			continue
+		}
+		checked[position.Asset.Address] = true
+		quotation, err := env.DataStore.GetAssetQuotationLatest(position.Asset)
+		if err != nil {
+			log.Warnf("price of %s: %v", position.Asset.Address, err)
+			continue
+		}
+		rates, err := env.DataStore.GetDefiRateInflux(endtime.AddDate(0, 0, -1), endtime, position.Asset.Address, protocol)
+		if err != nil || len(rates) == 0 {
+			log.Warnf("lending market of %s on %s: %v", position.Asset.Address, protocol, err)
+			continue
+		}
+		markets[position.Asset.Address] = models.DefiMarketRisk{
+			Price:                quotation.Price,
+			LiquidationThreshold: rates[len(rates)-1].LiquidationThreshold,
+		}
+	}
+	return markets
+}
+
+// defiMaxRiskSteps is the maximal number of price drops of a collateral-at-risk curve.
+const defiMaxRiskSteps = 100
+
+// GetDefiCollateralAtRisk returns the collateral-at-risk curve of @asset on @protocol, i.e. the collateral in
+// @asset which becomes liquidatable per drop of its price. @asset is the address or the symbol of the asset.
+// Per default the curve is computed for drops from 0 to 50 per cent in steps of 5 per cent.
+func (env *Env) GetDefiCollateralAtRisk(c *gin.Context) {
+	protocol := c.Param("protocol")
+	asset := c.Param("asset")
+	if common.IsHexAddress(asset) {
+		asset = common.HexToAddress(asset).Hex()
+	}
+
+	maxDrop, err := strconv.ParseFloat(c.DefaultQuery("maxDrop", "50"), 64)
+	if err != nil || maxDrop < 0 || maxDrop > 100 {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("maxDrop must be a percentage in [0,100]"))
+		return
+	}
+	step, err := strconv.ParseFloat(c.DefaultQuery("step", "5"), 64)
+	if err != nil || step <= 0 || maxDrop/step > defiMaxRiskSteps {
+		restApi.SendError(c, http.StatusBadRequest, fmt.Errorf("step must be a positive percentage with at most %d steps up to maxDrop", defiMaxRiskSteps))
+		return
+	}
+	var drops []float64
+	for i := 0; float64(i)*step <= maxDrop; i++ {
+		drops = append(drops, float64(i)*step/100)
+	}
+
+	positions, err := env.RelDB.GetDefiBorrowerPositions(protocol)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	response := defiCollateralAtRisk{Protocol: protocol}
+	for _, position := range positions {
+		if position.Asset.Address == asset || position.Asset.Symbol == asset {
+			response.Asset = position.Asset
+			break
+		}
+	}
+	if response.Asset.Address == "" {
+		restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no positions in %s on %s", asset, protocol))
+		return
+	}
+
+	markets := env.defiMarketRisks(protocol, positions)
+	response.Curve, err = models.ComputeDefiCollateralAtRisk(positions, markets, response.Asset.Address, drops)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	response.Price = markets[response.Asset.Address].Price
+	response.LiquidationThreshold = markets[response.Asset.Address].LiquidationThreshold
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDefiHealthFactors returns the distribution of the health factors of the borrowers on @protocol.
+func (env *Env) GetDefiHealthFactors(c *gin.Context) {
+	protocol := c.Param("protocol")
+	positions, err := env.RelDB.GetDefiBorrowerPositions(protocol)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(positions) == 0 {
+		restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no positions on %s", protocol))
+		return
+	}
+
+	response := defiHealthFactors{
+		Protocol: protocol,
+		Buckets:  models.ComputeDefiHealthFactors(positions, env.defiMarketRisks(protocol, positions), models.DefiHealthFactorBounds),
+	}
+	for _, bucket := range response.Buckets {
+		response.NumAccounts += bucket.NumAccounts
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDefiLiquidations returns the liquidations on @protocol. Per default the liquidations of the last 7 days are returned.
+func (env *Env) GetDefiLiquidations(c *gin.Context) {
+	protocol := c.Param("protocol")
+	starttime, endtime, err := defiTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	liquidations, err := env.RelDB.GetDefiLiquidations(protocol, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, defiLiquidations{Protocol: protocol, Liquidations: liquidations})
+}
+
+// GetMakerDAOIlks returns the latest state of all MakerDAO collateral types (ilks), sorted by name.
+func (env *Env) GetMakerDAOIlks(c *gin.Context) {
+	// States are stored every few minutes, so the last day holds the latest state of all active ilks.
+	endtime := time.Now()
+	states, err := env.DataStore.GetMakerDAOIlks("", endtime.AddDate(0, 0, -1), endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	latest := make(map[string]models.MakerDAOIlk)
+	for _, state := range states {
+		latest[state.Ilk] = state
+	}
+	ilks := []models.MakerDAOIlk{}
+	for _, state := range latest {
+		ilks = append(ilks, state)
+	}
+	sort.Slice(ilks, func(i, j int) bool { return ilks[i].Ilk < ilks[j].Ilk })
+	c.JSON(http.StatusOK, ilks)
+}
+
+// GetMakerDAOIlk returns the states of the MakerDAO collateral type @ilk in a time range.
+func (env *Env) GetMakerDAOIlk(c *gin.Context) {
+	ilk := c.Param("ilk")
+	starttime, endtime, err := defiTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	states, err := env.DataStore.GetMakerDAOIlks(ilk, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(states) == 0 {
+		restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no data for ilk %s", ilk))
+		return
+	}
+	c.JSON(http.StatusOK, states)
+}
+
+// GetMakerDAOVaults returns the collateralisation distributions of the vaults of the MakerDAO collateral type @ilk
+// in a time range.
+func (env *Env) GetMakerDAOVaults(c *gin.Context) {
+	ilk := c.Param("ilk")
+	starttime, endtime, err := defiTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	stats, err := env.DataStore.GetMakerDAOVaultStats(ilk, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(stats) == 0 {
+		restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no vault data for ilk %s", ilk))
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetMakerDAOSavings returns the states of the MakerDAO DAI savings rate in a time range.
+func (env *Env) GetMakerDAOSavings(c *gin.Context) {
+	starttime, endtime, err := defiTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	savings, err := env.DataStore.GetMakerDAOSavings(starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if savings == nil {
+		savings = []models.MakerDAOSavings{}
+	}
+	c.JSON(http.StatusOK, savings)
+}
+
+// GetDefiTVL returns the TVL of @protocol in a time range, summed over all its blockchains or restricted to the
+// blockchain in the query. If byBlockchain is set, the TVL is returned per blockchain.
+func (env *Env) GetDefiTVL(c *gin.Context) {
+	protocol := c.Param("protocol")
+	starttime, endtime, err := defiTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	blockchain := c.Query("blockchain")
+	tvls, err := env.DataStore.GetDefiTVL(protocol, blockchain, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(tvls) == 0 {
+		restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no tvl data for protocol %s", protocol))
+		return
+	}
+	c.JSON(http.StatusOK, models.SumDefiTVL(tvls, blockchain != "" || c.Query("byBlockchain") == "true"))
+}
+
+// GetDefiTVLAssets returns the values of the assets held by @protocol, optionally restricted to the blockchain in
+// the query. Without a time range, the assets of the latest update per blockchain are returned.
+func (env *Env) GetDefiTVLAssets(c *gin.Context) {
+	protocol := c.Param("protocol")
+	blockchain := c.Query("blockchain")
+	var tvls []models.DefiTVL
+	if c.Query("starttime") == "" && c.Query("endtime") == "" {
+		var err error
+		if tvls, err = env.DataStore.GetDefiTVLLatest(protocol, blockchain, time.Now()); err != nil {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+			return
+		}
+	} else {
+		starttime, endtime, err := defiTimeRange(c, 24*time.Hour)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		if tvls, err = env.DataStore.GetDefiTVL(protocol, blockchain, starttime, endtime); err != nil {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if len(tvls) == 0 {
+		restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no tvl data for protocol %s", protocol))
+		return
+	}
+	c.JSON(http.StatusOK, tvls)
+}
+
+// defiTimeRange returns the time range given by the unix timestamps in the query parameters starttime and endtime.
+// endtime defaults to now and starttime to @defaultRange before endtime.
+func defiTimeRange(c *gin.Context, defaultRange time.Duration) (starttime time.Time, endtime time.Time, err error) {
+	endtime = time.Now()
+	if endtimeStr := c.Query("endtime"); endtimeStr != "" {
+		var endtimeInt int64
+		if endtimeInt, err = strconv.ParseInt(endtimeStr, 10, 64); err != nil {
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+	starttime = endtime.Add(-defaultRange)
+	if starttimeStr := c.Query("starttime"); starttimeStr != "" {
+		var starttimeInt int64
+		if starttimeInt, err = strconv.ParseInt(starttimeStr, 10, 64); err != nil {
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+	if starttime.After(endtime) {
+		err = errors.New("starttime must not be after endtime")
+	}
+	return
+}
+
 // -----------------------------------------------------------------------------
 // FARMING POOLS
 // -----------------------------------------------------------------------------
@@ -970,7 +1400,10 @@ func (env *Env) GetFarmingPoolData(c *gin.Context) {
 			} else {
 				restApi.SendError(c, http.StatusInternalServerError, err)
 			}
+		} else if len(q) == 0 {
+			restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no data for pool %s on %s", poolID, protocol))
 		} else {
+			q[0].Methodology = models.FarmingPoolMethodology(protocol)
 			c.JSON(http.StatusOK, q[0])
 		}
 	} else {
@@ -990,6 +1423,9 @@ func (env *Env) GetFarmingPoolData(c *gin.Context) {
 				restApi.SendError(c, http.StatusInternalServerError, err)
 			}
 		} else {
+			for i := range q {
+				q[i].Methodology = models.FarmingPoolMethodology(protocol)
+			}
 			c.JSON(http.StatusOK, q)
 		}
 	}
@@ -1102,6 +1538,109 @@ func (env *Env) GetCompoundedRate(c *gin.Context) {
 	}
 }
 
+// GetCompoundedRateInArrears returns an interest rate compounded in arrears over the period from the query
+// parameter dateInit to dateFinal with the convention and shift in business days given by the query parameters
+// convention and shift. Conventions are plain, lookback, observationShift and lockout.
+func (env *Env) GetCompoundedRateInArrears(c *gin.Context) {
+	symbol := c.Param("symbol")
+	daysPerYear, err := strconv.Atoi(c.Param("dpy"))
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("days per year must be an integer"))
+		return
+	}
+	dateInit, err := time.Parse("2006-01-02", c.Query("dateInit"))
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("dateInit must be a date in the format 2006-01-02"))
+		return
+	}
+	dateFinal, err := time.Parse("2006-01-02", c.Query("dateFinal"))
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("dateFinal must be a date in the format 2006-01-02"))
+		return
+	}
+	convention, shift, err := inArrearsConvention(c, ratederivatives.ConventionPlain, 0)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	q, err := env.DataStore.GetCompoundedRateInArrears(symbol, dateInit, dateFinal, convention, shift, daysPerYear)
+	if err != nil {
+		restApi.SendError(c, inArrearsErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// GetIBORFallbackRate returns the ISDA fallback rate of an IBOR for a tenor, i.e. its risk-free rate compounded in
+// arrears from the query parameter dateInit plus the spread adjustment of the tenor. dateFinal defaults to the end
+// of the tenor, convention and shift to an observation shift of 2 business days.
+func (env *Env) GetIBORFallbackRate(c *gin.Context) {
+	ibor := c.Param("ibor")
+	tenor := c.Param("tenor")
+	fallback, ok := ratederivatives.IBORFallbacks[ibor]
+	if !ok {
+		restApi.SendError(c, http.StatusNotFound, fmt.Errorf("no fallback for %s", ibor))
+		return
+	}
+	if _, err := fallback.SpreadAdjustment(tenor); err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	dateInit, err := time.Parse("2006-01-02", c.Query("dateInit"))
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("dateInit must be a date in the format 2006-01-02"))
+		return
+	}
+	var dateFinal time.Time
+	if dateFinalstring := c.Query("dateFinal"); dateFinalstring != "" {
+		if dateFinal, err = time.Parse("2006-01-02", dateFinalstring); err != nil {
+			restApi.SendError(c, http.StatusBadRequest, errors.New("dateFinal must be a date in the format 2006-01-02"))
+			return
+		}
+	}
+	convention, shift, err := inArrearsConvention(c, ratederivatives.ConventionObservationShift, ratederivatives.FallbackShift)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	q, err := env.DataStore.GetIBORFallbackRate(ibor, tenor, dateInit, dateFinal, convention, shift)
+	if err != nil {
+		restApi.SendError(c, inArrearsErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// inArrearsConvention returns the convention and shift given by the query parameters convention and shift,
+// which default to @defaultConvention and @defaultShift.
+func inArrearsConvention(c *gin.Context, defaultConvention ratederivatives.Convention, defaultShift int) (ratederivatives.Convention, int, error) {
+	convention, err := ratederivatives.ParseConvention(c.DefaultQuery("convention", string(defaultConvention)))
+	if err != nil {
+		return "", 0, err
+	}
+	shift := defaultShift
+	if shiftstring := c.Query("shift"); shiftstring != "" {
+		if shift, err = strconv.Atoi(shiftstring); err != nil || shift < 0 || shift > ratederivatives.MaxShift {
+			return "", 0, fmt.Errorf("shift must be an integer from 0 to %d", ratederivatives.MaxShift)
+		}
+	}
+	return convention, shift, nil
+}
+
+// inArrearsErrorStatus returns the status of the response to a request of a rate compounded in arrears which
+// failed with @err. Periods the rate cannot be compounded over are bad requests.
+func inArrearsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ratederivatives.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, redis.Nil):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
 // GetCompoundedAvg is the delegate method to fetch averaged compounded rate values for interest rates
 func (env *Env) GetCompoundedAvg(c *gin.Context) {
 
@@ -1414,6 +1953,13 @@ func (env *Env) GetForeignSymbols(c *gin.Context) {
 // CUSTOMIZED PRODUCTS
 // -----------------------------------------------------------------------------
 
+// vwapFirefly is a vwap value of a Firefly ticker.
+type vwapFirefly struct {
+	Ticker    string
+	Value     float64
+	Timestamp time.Time
+}
+
 func (env *Env) GetVwapFirefly(c *gin.Context) {
 	foreignname := c.Param("ticker")
 	starttimeStr := c.Query("starttime")
@@ -1438,27 +1984,22 @@ func (env *Env) GetVwapFirefly(c *gin.Context) {
 		endtime = time.Unix(endtimeInt, 0)
 	}
 
-	type vwapObj struct {
-		Ticker    string
-		Value     float64
-		Timestamp time.Time
-	}
 	values, timestamps, err := env.DataStore.GetVWAPFirefly(foreignname, starttime, endtime)
 	if err != nil {
 		restApi.SendError(c, http.StatusInternalServerError, err)
 		return
 	}
 	if starttimeStr == "" || endtimeStr == "" {
-		response := vwapObj{
+		response := vwapFirefly{
 			Ticker:    foreignname,
 			Value:     values[0],
 			Timestamp: timestamps[0],
 		}
 		c.JSON(http.StatusOK, response)
 	} else {
-		var response []vwapObj
+		var response []vwapFirefly
 		for i := 0; i < len(values); i++ {
-			tmp := vwapObj{
+			tmp := vwapFirefly{
 				Ticker:    foreignname,
 				Value:     values[i],
 				Timestamp: timestamps[i],
@@ -1545,6 +2086,15 @@ func (env *Env) GetCryptoIndex(c *gin.Context) {
 	c.JSON(http.StatusOK, q)
 }
 
+// indexValue is a reduced index value as returned by GetCryptoIndexValues.
+type indexValue struct {
+	Symbol          string
+	Address         string
+	Blockchain      string
+	Value           float64
+	CalculationTime time.Time
+}
+
 func (env *Env) GetCryptoIndexValues(c *gin.Context) {
 	symbol := c.Param("symbol")
 	starttimeStr := c.Query("starttime")
@@ -1601,17 +2151,9 @@ func (env *Env) GetCryptoIndexValues(c *gin.Context) {
 		return
 	}
 
-	// local return type
-	type indexVals struct {
-		Symbol          string
-		Address         string
-		Blockchain      string
-		Value           float64
-		CalculationTime time.Time
-	}
-	var returnIndices []indexVals
+	var returnIndices []indexValue
 	for _, index := range q {
-		tmp := indexVals{
+		tmp := indexValue{
 			Symbol:          index.Asset.Symbol,
 			Address:         index.Asset.Address,
 			Blockchain:      index.Asset.Blockchain,
@@ -1815,7 +2357,7 @@ func (env *Env) PostIndexRebalance(c *gin.Context) {
 	log.Infof("got the following %d constituents", len(constituents))
 	for i, constituent := range constituents {
 		log.Infof("constituent %d: %v", i, constituent)
-		log.Infof("constituent price: %d", constituent.Price)
+		log.Infof("constituent price: %v", constituent.Price)
 		constituents[i].NumBaseTokens = ((constituent.Weight * newIndexValue) / constituent.Price) * 1e16 //((Weight * IndexPrice) / TokenPrice) * 1e18  (divided by 100 because index level is 100 = 1 usd)
 	}
 
@@ -1919,8 +2461,13 @@ func (env *Env) GetNFTClasses(c *gin.Context) {
 	}
 
 	q, err := env.RelDB.GetNFTClasses(limit, offset)
-	if len(q) == 0 || err != nil {
+	if err != nil {
 		restApi.SendError(c, http.StatusInternalServerError, nil)
+		return
+	}
+	if len(q) == 0 {
+		restApi.SendError(c, http.StatusNotFound, errors.New("no nft classes at offset"))
+		return
 	}
 	c.JSON(http.StatusOK, q)
 }
@@ -1965,7 +2512,18 @@ func (env *Env) GetNFTTradesCurrent(c *gin.Context) {
 	c.JSON(http.StatusOK, q)
 }
 
-// GetNFTPrice30Days returns the average price of the whole nft class over the last 30 days.
+// nftFloor is the floor price returned by GetNFTFloor.
+type nftFloor struct {
+	Floor      float64                   `json:"Floor_Price"`
+	FloorUSD   float64                   `json:"Floor_Price_USD"`
+	Currency   string                    `json:"Currency"`
+	Time       time.Time                 `json:"Time"`
+	Source     string                    `json:"Source"`
+	Confidence models.NFTFloorConfidence `json:"Confidence"`
+}
+
+// GetNFTFloor returns the floor price of the nft class as a low quantile of the sales in the floor window,
+// with wash trades removed. The optional query parameter quantile defaults to models.DefaultNFTFloorQuantile.
 func (env *Env) GetNFTFloor(c *gin.Context) {
 	blockchain := c.Param("blockchain")
 	address := common.HexToAddress(c.Param("address")).Hex()
@@ -1976,6 +2534,7 @@ func (env *Env) GetNFTFloor(c *gin.Context) {
 		timestampUnix, err := strconv.ParseInt(timestampUnixString, 10, 64)
 		if err != nil {
 			restApi.SendError(c, http.StatusBadRequest, err)
+			return
 		}
 		timestamp = time.Unix(timestampUnix, 0)
 	} else {
@@ -1989,35 +2548,528 @@ func (env *Env) GetNFTFloor(c *gin.Context) {
 		floorWindow, err = strconv.ParseInt(floorWindowSeconds, 10, 64)
 		if err != nil {
 			restApi.SendError(c, http.StatusBadRequest, err)
+			return
 		}
 	} else {
 		// Set floor window to default 24h.
 		floorWindow = 24 * 60 * 60
 	}
 
+	quantile := models.DefaultNFTFloorQuantile
+	if quantileString := c.Query("quantile"); quantileString != "" {
+		quantile, err = strconv.ParseFloat(quantileString, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
 	nftClass := dia.NFTClass{Address: address, Blockchain: blockchain}
 
 	// Look for floor price. Iterate backwards in time if no sales are found.
-	var floor float64
 	windowDuration := time.Duration(floorWindow) * time.Second
 	stepBackLimit := 40
-	floor, err = env.RelDB.GetNFTFloorRecursive(nftClass, timestamp, windowDuration, stepBackLimit)
+	estimate, err := env.RelDB.GetNFTFloorEstimate(nftClass, timestamp, windowDuration, quantile, stepBackLimit)
 	if err != nil {
 		restApi.SendError(c, http.StatusBadRequest, err)
 		return
 	}
-	type returnStruct struct {
-		Floor  float64   `json:"Floor_Price"`
-		Time   time.Time `json:"Time"`
-		Source string    `json:"Source"`
-	}
-	var resp returnStruct
-	resp.Floor = floor
+	var resp nftFloor
+	resp.Floor = estimate.Floor
+	resp.FloorUSD = estimate.FloorUSD
+	resp.Currency = estimate.Currency.Symbol
 	resp.Time = timestamp
 	resp.Source = dia.Diadata
+	resp.Confidence = estimate.Confidence
 	c.JSON(http.StatusOK, resp)
 }
 
+// nftValuation is the appraisal of a single nft returned by GetNFTValuation.
+type nftValuation struct {
+	Address    string                        `json:"Address"`
+	Blockchain string                        `json:"Blockchain"`
+	TokenID    string                        `json:"TokenID"`
+	Value      float64                       `json:"Value"`
+	ValueUSD   float64                       `json:"Value_USD"`
+	Lower      float64                       `json:"Lower_Bound"`
+	Upper      float64                       `json:"Upper_Bound"`
+	Currency   string                        `json:"Currency"`
+	Time       time.Time                     `json:"Time"`
+	Source     string                        `json:"Source"`
+	Components models.NFTValuationComponents `json:"Components"`
+}
+
+// GetNFTValuation returns the latest appraisal of the nft at or before the optional query parameter timestamp.
+// Appraisals combine the collection floor, the rarity of the token's traits and its last sale, see models.AppraiseNFTs.
+func (env *Env) GetNFTValuation(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	id := c.Param("id")
+
+	timestamp := time.Now()
+	if timestampString := c.Query("timestamp"); timestampString != "" {
+		timestampUnix, err := strconv.ParseInt(timestampString, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		timestamp = time.Unix(timestampUnix, 0)
+	}
+
+	valuation, err := env.RelDB.GetNFTValuation(address, blockchain, id, timestamp)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, nftValuation{
+		Address:    valuation.NFT.NFTClass.Address,
+		Blockchain: valuation.NFT.NFTClass.Blockchain,
+		TokenID:    valuation.NFT.TokenID,
+		Value:      valuation.Value,
+		ValueUSD:   valuation.ValueUSD,
+		Lower:      valuation.Lower,
+		Upper:      valuation.Upper,
+		Currency:   valuation.Currency.Symbol,
+		Time:       valuation.Time,
+		Source:     dia.Diadata,
+		Components: valuation.Components,
+	})
+}
+
+// nftRarity is the rarity of a single nft returned by GetNFTRarity and GetNFTClassRarity.
+type nftRarity struct {
+	TokenID                string                  `json:"TokenID"`
+	StatisticalRarity      float64                 `json:"StatisticalRarity"`
+	StatisticalRank        int                     `json:"StatisticalRank"`
+	InformationContent     float64                 `json:"InformationContent"`
+	InformationContentRank int                     `json:"InformationContentRank"`
+	TraitCount             int                     `json:"TraitCount"`
+	Traits                 []models.NFTTraitRarity `json:"Traits"`
+	Time                   time.Time               `json:"Time"`
+}
+
+// nftRarityPage is a page of the rarities of a collection returned by GetNFTClassRarity.
+type nftRarityPage struct {
+	Address    string      `json:"Address"`
+	Blockchain string      `json:"Blockchain"`
+	Method     string      `json:"Method"`
+	Total      int         `json:"Total"`
+	Limit      uint64      `json:"Limit"`
+	Offset     uint64      `json:"Offset"`
+	Rarities   []nftRarity `json:"Rarities"`
+}
+
+func newNFTRarity(rarity models.NFTRarity) nftRarity {
+	return nftRarity{
+		TokenID:                rarity.NFT.TokenID,
+		StatisticalRarity:      rarity.StatisticalRarity,
+		StatisticalRank:        rarity.StatisticalRank,
+		InformationContent:     rarity.InformationContent,
+		InformationContentRank: rarity.InformationContentRank,
+		TraitCount:             rarity.TraitCount,
+		Traits:                 rarity.Traits,
+		Time:                   rarity.Time,
+	}
+}
+
+// GetNFTRarity returns the rarity scores and ranks of the nft within its collection.
+func (env *Env) GetNFTRarity(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	id := c.Param("id")
+
+	rarity, err := env.RelDB.GetNFTRarity(address, blockchain, id)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, newNFTRarity(rarity))
+}
+
+// GetNFTClassRarity returns the nfts of a collection sorted from rarest to most common. The query parameter method
+// selects the ranking, either models.NFTRarityInformationContent (default) or models.NFTRarityStatistical.
+// The page is given by the query parameters limit (default 100, at most 1000) and offset.
+func (env *Env) GetNFTClassRarity(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	method := c.DefaultQuery("method", models.NFTRarityInformationContent)
+
+	limit, err := strconv.ParseUint(c.DefaultQuery("limit", "100"), 10, 64)
+	if err != nil || limit == 0 || limit > 1000 {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("limit must be in [1,1000]"))
+		return
+	}
+	offset, err := strconv.ParseUint(c.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	nftClass := dia.NFTClass{Address: address, Blockchain: blockchain}
+	rarities, total, err := env.RelDB.GetNFTClassRarity(nftClass, method, limit, offset)
+	if err != nil {
+		if errors.Is(err, models.ErrUnknownNFTRarityMethod) {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	page := nftRarityPage{
+		Address:    address,
+		Blockchain: blockchain,
+		Method:     method,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		Rarities:   make([]nftRarity, len(rarities)),
+	}
+	for i, rarity := range rarities {
+		page.Rarities[i] = newNFTRarity(rarity)
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// nftMarketStats are the market statistics of a collection in one interval returned by GetNFTMarketStats.
+type nftMarketStats struct {
+	Time          time.Time `json:"Time"`
+	Currency      dia.Asset `json:"Currency"`
+	Volume        float64   `json:"Volume"`
+	VolumeUSD     float64   `json:"VolumeUSD"`
+	NumSales      int       `json:"NumSales"`
+	UniqueBuyers  int       `json:"UniqueBuyers"`
+	UniqueSellers int       `json:"UniqueSellers"`
+	HolderCount   int       `json:"HolderCount"`
+	FloorPrice    float64   `json:"FloorPrice"`
+	MedianPrice   float64   `json:"MedianPrice"`
+	MeanPrice     float64   `json:"MeanPrice"`
+}
+
+// nftMarketStatsRange is the series of market statistics of a collection returned by GetNFTMarketStats.
+type nftMarketStatsRange struct {
+	Address    string           `json:"Address"`
+	Blockchain string           `json:"Blockchain"`
+	Interval   string           `json:"Interval"`
+	Stats      []nftMarketStats `json:"Stats"`
+}
+
+// GetNFTMarketStats returns the hourly or daily market statistics of an nft collection.
+// Per default the daily statistics of the last 30 days are returned.
+func (env *Env) GetNFTMarketStats(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	interval := c.DefaultQuery("interval", models.NFTMarketStatsDaily)
+	if _, err := models.NFTMarketStatsPeriod(interval); err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	endtime := time.Now()
+	if endtimeStr := c.Query("endtime"); endtimeStr != "" {
+		endtimeInt, err := strconv.ParseInt(endtimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+	starttime := endtime.AddDate(0, 0, -30)
+	if starttimeStr := c.Query("starttime"); starttimeStr != "" {
+		starttimeInt, err := strconv.ParseInt(starttimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+	if starttime.After(endtime) {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("starttime must not be after endtime"))
+		return
+	}
+
+	nftClass := dia.NFTClass{Address: address, Blockchain: blockchain}
+	stats, err := env.RelDB.GetNFTMarketStats(nftClass, interval, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := nftMarketStatsRange{
+		Address:    address,
+		Blockchain: blockchain,
+		Interval:   interval,
+		Stats:      make([]nftMarketStats, len(stats)),
+	}
+	for i, s := range stats {
+		response.Stats[i] = nftMarketStats{
+			Time:          s.Time,
+			Currency:      s.Currency,
+			Volume:        s.Volume,
+			VolumeUSD:     s.VolumeUSD,
+			NumSales:      s.NumSales,
+			UniqueBuyers:  s.UniqueBuyers,
+			UniqueSellers: s.UniqueSellers,
+			HolderCount:   s.HolderCount,
+			FloorPrice:    s.FloorPrice,
+			MedianPrice:   s.MedianPrice,
+			MeanPrice:     s.MeanPrice,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// nftOrder is an order of the nft order book returned by GetNFTOrders and GetNFTBestBidOffer.
+// Price is the current price in units of Currency, the raw amounts are in the smallest unit.
+type nftOrder struct {
+	TokenID    string    `json:"TokenID"`
+	Exchange   string    `json:"Exchange"`
+	OrderID    string    `json:"OrderID"`
+	Side       string    `json:"Side"`
+	Status     string    `json:"Status"`
+	Maker      string    `json:"Maker"`
+	Price      float64   `json:"Price"`
+	StartPrice string    `json:"StartPrice"`
+	EndPrice   string    `json:"EndPrice,omitempty"`
+	Duration   int64     `json:"DurationSeconds,omitempty"`
+	Currency   dia.Asset `json:"Currency"`
+	Expiry     time.Time `json:"Expiry"`
+	Time       time.Time `json:"Time"`
+	UpdateTime time.Time `json:"UpdateTime"`
+	TxHash     string    `json:"TxHash"`
+}
+
+func newNFTOrder(order models.NFTOrder, timestamp time.Time) *nftOrder {
+	o := &nftOrder{
+		TokenID:    order.NFT.TokenID,
+		Exchange:   order.Exchange,
+		OrderID:    order.OrderID,
+		Side:       order.Side,
+		Status:     order.Status,
+		Maker:      order.Maker,
+		Price:      order.CurrentAmount(timestamp),
+		StartPrice: order.Price.String(),
+		Duration:   int64(order.Duration / time.Second),
+		Currency:   order.Currency,
+		Expiry:     order.Expiry,
+		Time:       order.Time,
+		UpdateTime: order.UpdateTime,
+		TxHash:     order.TxHash,
+	}
+	if order.EndPrice != nil {
+		o.EndPrice = order.EndPrice.String()
+	}
+	return o
+}
+
+// nftOrderBook are the orders of a single nft returned by GetNFTOrders.
+type nftOrderBook struct {
+	Address    string     `json:"Address"`
+	Blockchain string     `json:"Blockchain"`
+	TokenID    string     `json:"TokenID"`
+	Orders     []nftOrder `json:"Orders"`
+}
+
+// GetNFTOrders returns the orders of an nft, most recently updated first.
+// The query parameter status restricts the orders to placed, filled, cancelled or expired ones.
+func (env *Env) GetNFTOrders(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	tokenID := c.Param("id")
+	status := c.Query("status")
+	switch status {
+	case "", dia.NFTOrderPlaced, dia.NFTOrderFilled, dia.NFTOrderCancelled, dia.NFTOrderExpired:
+	default:
+		restApi.SendError(c, http.StatusBadRequest, fmt.Errorf("unknown order status %q", status))
+		return
+	}
+
+	orders, err := env.RelDB.GetNFTOrders(address, blockchain, tokenID, status)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+
+	now := time.Now()
+	response := nftOrderBook{
+		Address:    address,
+		Blockchain: blockchain,
+		TokenID:    tokenID,
+		Orders:     make([]nftOrder, len(orders)),
+	}
+	for i, order := range orders {
+		// Expired orders may not be marked yet.
+		if order.Status == dia.NFTOrderPlaced && !order.IsOpen(now) {
+			order.Status = dia.NFTOrderExpired
+		}
+		response.Orders[i] = *newNFTOrder(order, now)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// nftBestBidOffer is the best bid and offer of a collection in one currency returned by GetNFTBestBidOffer.
+type nftBestBidOffer struct {
+	Currency  dia.Asset `json:"Currency"`
+	BestBid   *nftOrder `json:"BestBid"`
+	BestOffer *nftOrder `json:"BestOffer"`
+	Spread    float64   `json:"Spread"`
+	NumBids   int       `json:"NumBids"`
+	NumOffers int       `json:"NumOffers"`
+}
+
+// nftBestBidOffers are the best bids and offers of a collection returned by GetNFTBestBidOffer.
+type nftBestBidOffers struct {
+	Address    string            `json:"Address"`
+	Blockchain string            `json:"Blockchain"`
+	Time       time.Time         `json:"Time"`
+	Books      []nftBestBidOffer `json:"Books"`
+}
+
+// GetNFTBestBidOffer returns the highest open bid and the lowest open offer of an nft collection,
+// one pair per currency orders are placed in. The currency with most open orders comes first.
+func (env *Env) GetNFTBestBidOffer(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	nftClass := dia.NFTClass{Address: address, Blockchain: blockchain}
+
+	orders, err := env.RelDB.GetNFTClassOpenOrders(nftClass)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+
+	now := time.Now()
+	books := models.ComputeNFTBestBidOffer(nftClass, orders, now)
+	response := nftBestBidOffers{
+		Address:    address,
+		Blockchain: blockchain,
+		Time:       now,
+		Books:      make([]nftBestBidOffer, len(books)),
+	}
+	for i, book := range books {
+		response.Books[i] = nftBestBidOffer{
+			Currency:  book.Currency,
+			NumBids:   book.NumBids,
+			NumOffers: book.NumOffers,
+		}
+		if book.BestBid != nil {
+			response.Books[i].BestBid = newNFTOrder(*book.BestBid, now)
+		}
+		if book.BestOffer != nil {
+			response.Books[i].BestOffer = newNFTOrder(*book.BestOffer, now)
+		}
+		if book.BestBid != nil && book.BestOffer != nil {
+			response.Books[i].Spread = response.Books[i].BestOffer.Price - response.Books[i].BestBid.Price
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// nftOracleUpdate is an update pushed to an nft oracle contract returned by GetNFTOracleUpdates.
+type nftOracleUpdate struct {
+	Address    string    `json:"Address"`
+	Blockchain string    `json:"Blockchain"`
+	Floor      float64   `json:"Floor_Price"`
+	FloorMA    float64   `json:"Moving_Average_Floor_Price"`
+	FloorUSD   float64   `json:"Floor_Price_USD"`
+	Currency   string    `json:"Currency"`
+	Confidence float64   `json:"Confidence"`
+	NumTrades  int       `json:"Num_Trades"`
+	Values     [5]uint64 `json:"Values"`
+	ValueTime  time.Time `json:"Value_Time"`
+	Reason     string    `json:"Reason"`
+	Signer     string    `json:"Signer"`
+	Signature  string    `json:"Signature"`
+	TxHash     string    `json:"Tx_Hash"`
+	Status     string    `json:"Status"`
+	Time       time.Time `json:"Time"`
+}
+
+// nftOracleUpdates is the audit trail of an oracle key returned by GetNFTOracleUpdates.
+type nftOracleUpdates struct {
+	Oracle  string            `json:"Oracle"`
+	ChainID int64             `json:"Chain_ID"`
+	Key     string            `json:"Key"`
+	Updates []nftOracleUpdate `json:"Updates"`
+}
+
+// GetNFTOracleUpdates returns the floor price updates pushed to an nft oracle contract under a key.
+// Per default the updates of the last 7 days are returned.
+func (env *Env) GetNFTOracleUpdates(c *gin.Context) {
+	chainID, err := strconv.ParseInt(c.Param("chainID"), 10, 64)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	oracle := common.HexToAddress(c.Param("oracle")).Hex()
+	key := c.Param("key")
+
+	endtime := time.Now()
+	if endtimeStr := c.Query("endtime"); endtimeStr != "" {
+		endtimeInt, err := strconv.ParseInt(endtimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+	starttime := endtime.AddDate(0, 0, -7)
+	if starttimeStr := c.Query("starttime"); starttimeStr != "" {
+		starttimeInt, err := strconv.ParseInt(starttimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+	if starttime.After(endtime) {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("starttime must not be after endtime"))
+		return
+	}
+
+	updates, err := env.RelDB.GetNFTOracleUpdates(oracle, chainID, key, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := nftOracleUpdates{
+		Oracle:  oracle,
+		ChainID: chainID,
+		Key:     key,
+		Updates: make([]nftOracleUpdate, len(updates)),
+	}
+	for i, u := range updates {
+		response.Updates[i] = nftOracleUpdate{
+			Address:    u.NFTClass.Address,
+			Blockchain: u.NFTClass.Blockchain,
+			Floor:      u.Floor,
+			FloorMA:    u.FloorMA,
+			FloorUSD:   u.FloorUSD,
+			Currency:   u.Currency,
+			Confidence: u.Confidence,
+			NumTrades:  u.NumTrades,
+			Values:     u.Values,
+			ValueTime:  u.ValueTime,
+			Reason:     u.Reason,
+			Signer:     u.Signer,
+			Signature:  u.Signature,
+			TxHash:     u.TxHash,
+			Status:     u.Status,
+			Time:       u.Time,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// nftFloorMA is the moving average floor price returned by GetNFTFloorMA.
+type nftFloorMA struct {
+	Floor    float64   `json:"Moving_Average_Floor_Price"`
+	Currency string    `json:"Currency"`
+	Time     time.Time `json:"Time"`
+	Source   string    `json:"Source"`
+}
+
 // GetNFTFloorMA returns the moving average floor price of the nft class over the last 30 days.
 func (env *Env) GetNFTFloorMA(c *gin.Context) {
 
@@ -2046,7 +3098,12 @@ func (env *Env) GetNFTFloorMA(c *gin.Context) {
 	stepBackLimit := 120
 
 	t := time.Now()
-	floorPrices, err := env.RelDB.GetNFTFloorRange(nftClass, starttime, endtime, floorWindow, stepBackLimit)
+	// Floors are all in units of currency, so that they can be averaged.
+	floorPrices, currency, err := env.RelDB.GetNFTFloorRange(nftClass, starttime, endtime, floorWindow, stepBackLimit)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
 	log.Infof("took %v time to compute floorPrices: %v", time.Since(t), floorPrices)
 
 	cleanFloorPrices, indices := filters.RemoveOutliers(floorPrices, 1.5)
@@ -2064,18 +3121,23 @@ func (env *Env) GetNFTFloorMA(c *gin.Context) {
 		log.Info("nothing discarded.")
 	}
 
-	type returnStruct struct {
-		Floor  float64   `json:"Moving_Average_Floor_Price"`
-		Time   time.Time `json:"Time"`
-		Source string    `json:"Source"`
-	}
-	var resp returnStruct
+	var resp nftFloorMA
 	resp.Floor = floorMA
+	resp.Currency = currency.Symbol
 	resp.Time = endtime
 	resp.Source = dia.Diadata
 	c.JSON(http.StatusOK, resp)
 }
 
+// nftDownday is the downday statistics returned by GetNFTDownday.
+type nftDownday struct {
+	WeeklyDrawdown   float64   `json:"Weekly_Drawdown"`
+	DowndayAverage   float64   `json:"Downday_Average"`
+	DowndayDeviation float64   `json:"Downday_Deviation"`
+	Time             time.Time `json:"Time"`
+	Source           string    `json:"Source"`
+}
+
 // GetNFTDownday returns the moving average floor price of the nft class over the last 30 days.
 func (env *Env) GetNFTDownday(c *gin.Context) {
 
@@ -2102,7 +3164,11 @@ func (env *Env) GetNFTDownday(c *gin.Context) {
 	endtime := time.Now()
 	starttime := endtime.Add(-time.Duration(lookbackInt) * time.Second)
 	stepBackLimit := 120
-	floorPrices, err := env.RelDB.GetNFTFloorRange(nftClass, starttime, endtime, floorWindow, stepBackLimit)
+	floorPrices, _, err := env.RelDB.GetNFTFloorRange(nftClass, starttime, endtime, floorWindow, stepBackLimit)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
 
 	log.Info("floorPrices: ", floorPrices)
 
@@ -2124,14 +3190,7 @@ func (env *Env) GetNFTDownday(c *gin.Context) {
 	}
 	log.Info("movement: ", movement)
 
-	type Downward struct {
-		WeeklyDrawdown   float64   `json:"Weekly_Drawdown"`
-		DowndayAverage   float64   `json:"Downday_Average"`
-		DowndayDeviation float64   `json:"Downday_Deviation"`
-		Time             time.Time `json:"Time"`
-		Source           string    `json:"Source"`
-	}
-	var response Downward
+	var response nftDownday
 
 	response.DowndayAverage = utils.Average(downwardMovement)
 	response.DowndayDeviation = utils.StandardDeviation(downwardMovement)
@@ -2167,6 +3226,28 @@ func (env *Env) GetNFTDownday(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// tradesDistributionReduced is a trades distribution without asset and compute time.
+type tradesDistributionReduced struct {
+	NumTradesTotal   int     `json:"NumTradesTotal"`
+	NumBins          int     `json:"NumBins"`
+	NumLowBins       int     `json:"NumberLowBins"`
+	Threshold        int     `json:"Threshold"`
+	SizeBinSeconds   int64   `json:"SizeBin"`
+	AvgNumPerBin     float64 `json:"AverageNumberPerBin"`
+	StdDeviation     float64 `json:"StandardDeviation"`
+	TimeRangeSeconds int64   `json:"TimeRangeSeconds"`
+}
+
+// feedStats are the statistics of an asset returned by GetFeedStats.
+type feedStats struct {
+	Timestamp          time.Time
+	TotalVolume        float64
+	Price              float64
+	TradesDistribution tradesDistributionReduced
+	ExchangeVolumes    []dia.ExchangeVolume
+	PairVolumes        []dia.PairVolume
+}
+
 func (env *Env) GetFeedStats(c *gin.Context) {
 
 	blockchain := c.Param("blockchain")
@@ -2217,19 +3298,9 @@ func (env *Env) GetFeedStats(c *gin.Context) {
 		restApi.SendError(c, http.StatusInternalServerError, nil)
 	}
 
-	type localDistType struct {
-		NumTradesTotal   int     `json:"NumTradesTotal"`
-		NumBins          int     `json:"NumBins"`
-		NumLowBins       int     `json:"NumberLowBins"`
-		Threshold        int     `json:"Threshold"`
-		SizeBinSeconds   int64   `json:"SizeBin"`
-		AvgNumPerBin     float64 `json:"AverageNumberPerBin"`
-		StdDeviation     float64 `json:"StandardDeviation"`
-		TimeRangeSeconds int64   `json:"TimeRangeSeconds"`
-	}
-	var tradesDistReduced []localDistType
+	var tradesDistReduced []tradesDistributionReduced
 	for _, val := range tradesDist {
-		tradesDistReduced = append(tradesDistReduced, localDistType{
+		tradesDistReduced = append(tradesDistReduced, tradesDistributionReduced{
 			NumTradesTotal:   val.NumTradesTotal,
 			NumBins:          int(val.TimeRangeSeconds) / int(val.SizeBinSeconds),
 			NumLowBins:       val.NumLowBins,
@@ -2241,20 +3312,11 @@ func (env *Env) GetFeedStats(c *gin.Context) {
 		})
 	}
 
-	type localReturn struct {
-		Timestamp          time.Time
-		TotalVolume        float64
-		Price              float64
-		TradesDistribution localDistType
-		ExchangeVolumes    []dia.ExchangeVolume
-		PairVolumes        []dia.PairVolume
-	}
-
-	var retVal []localReturn
+	var retVal []feedStats
 
 	// Fill local return type.
 	for i := range exchVolumes {
-		var l localReturn
+		var l feedStats
 		var price float64
 		sort.Slice(exchVolumes[i].Volumes, func(m, n int) bool { return exchVolumes[i].Volumes[m].Volume > exchVolumes[i].Volumes[n].Volume })
 		l.ExchangeVolumes = exchVolumes[i].Volumes
@@ -0,0 +1,107 @@
+package diaApi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+)
+
+// testRateStore compounds the rates @fixings in arrears.
+type testRateStore struct {
+	models.Datastore
+	fixings []ratederivatives.Fixing
+}
+
+func (s *testRateStore) GetCompoundedRateInArrears(symbol string, dateInit, dateFinal time.Time, convention ratederivatives.Convention, shift int, daysPerYear int) (*models.CompoundedRateInArrears, error) {
+	result, err := ratederivatives.CompoundInArrears(s.fixings, dateInit, dateFinal, convention, shift, daysPerYear)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CompoundedRateInArrears{Symbol: symbol, Value: result.Rate, Factor: result.Factor}, nil
+}
+
+func TestGetCompoundedRateInArrearsStatus(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	env := &Env{DataStore: &testRateStore{fixings: []ratederivatives.Fixing{{Date: date("2021-04-05"), Value: 1}, {Date: date("2021-04-06"), Value: 1}}}}
+	r.GET("/compoundedRateInArrears/:symbol/:dpy", env.GetCompoundedRateInArrears)
+
+	for _, c := range []struct {
+		query string
+		want  int
+	}{
+		{"dateInit=2021-04-05&dateFinal=2021-04-07", http.StatusOK},
+		// No fixing on the initial date.
+		{"dateInit=2021-04-04&dateFinal=2021-04-07", http.StatusBadRequest},
+		// Lockout longer than the period.
+		{"dateInit=2021-04-06&dateFinal=2021-04-07&convention=lockout&shift=2", http.StatusBadRequest},
+		{"dateInit=2021-04-05", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/compoundedRateInArrears/SOFR/360?"+c.query, nil))
+		if w.Code != c.want {
+			t.Errorf("status of %s was incorrect, got: %v, want: %v. body: %s", c.query, w.Code, c.want, w.Body.String())
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		remote    string
+		forwarded string
+		want      string
+	}{
+		// Clients cannot set their IP without a trusted proxy.
+		{"203.0.113.7:4711", "198.51.100.1", "203.0.113.7"},
+		{"10.1.2.3:4711", "198.51.100.1", "198.51.100.1"},
+		// Addresses prepended by the client are ignored.
+		{"10.1.2.3:4711", "198.51.100.9, 198.51.100.1, 192.168.1.1", "198.51.100.1"},
+		{"192.168.1.1:4711", "", "192.168.1.1"},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/v1/quotation/BTC", nil)
+		request.RemoteAddr = c.remote
+		if c.forwarded != "" {
+			request.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+		if got := ClientIP(request, proxies); got != c.want {
+			t.Errorf("client ip of %s forwarded for %q was incorrect, got: %v, want: %v.", c.remote, c.forwarded, got, c.want)
+		}
+	}
+	if _, err := ParseTrustedProxies("10.0.0.0/8,proxy"); err == nil {
+		t.Error("invalid trusted proxy was accepted")
+	}
+}
+
+// TestRateLimitWriter checks that the rate limit headers of a request override the ones of a cached response.
+func TestRateLimitWriter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/quotation", func(c *gin.Context) {
+		c.Writer = &rateLimitWriter{ResponseWriter: c.Writer, headers: map[string]string{"X-RateLimit-Remaining": "3"}}
+		c.Next()
+	}, func(c *gin.Context) {
+		// A page cache replays the headers of the response it cached.
+		c.Writer.WriteHeader(http.StatusOK)
+		c.Writer.Header().Set("X-RateLimit-Remaining", "9")
+		c.Writer.Write([]byte("{}"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotation", nil))
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "3" {
+		t.Errorf("rate limit header was incorrect, got: %v, want: %v.", got, "3")
+	}
+}
@@ -0,0 +1,572 @@
+package diaApi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/http/openapi"
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	timeRangeQuery = []openapi.Parameter{
+		openapi.QueryParam("starttime", "integer", "Unix timestamp of the beginning of the range."),
+		openapi.QueryParam("endtime", "integer", "Unix timestamp of the end of the range."),
+	}
+	unixRangeQuery = []openapi.Parameter{
+		openapi.QueryParam("dateInit", "integer", "Unix timestamp of the beginning of the range."),
+		openapi.QueryParam("dateFinal", "integer", "Unix timestamp of the end of the range."),
+	}
+	dateRangeQuery = []openapi.Parameter{
+		openapi.QueryParam("dateInit", "string", "First day of the range in the format 2006-01-02."),
+		openapi.QueryParam("dateFinal", "string", "Last day of the range in the format 2006-01-02."),
+	}
+	defiRangeQuery = []openapi.Parameter{
+		openapi.QueryParam("starttime", "integer", "Unix timestamp. Defaults to 7 days before endtime."),
+		openapi.QueryParam("endtime", "integer", "Unix timestamp. Defaults to now."),
+	}
+	defiTVLQuery = []openapi.Parameter{
+		openapi.QueryParam("blockchain", "string", "Blockchain the TVL is restricted to. Defaults to all blockchains of the protocol."),
+	}
+	inArrearsQuery = []openapi.Parameter{
+		openapi.QueryParam("convention", "string", "One of plain, lookback, observationShift and lockout."),
+		openapi.QueryParam("shift", "integer", "Lookback, observation shift or lockout in business days, at most 9."),
+	}
+	nftFloorQuery = []openapi.Parameter{
+		openapi.QueryParam("floorWindow", "integer", "Window in seconds the floor price is computed on."),
+	}
+)
+
+func withQuery(params ...[]openapi.Parameter) (query []openapi.Parameter) {
+	for _, p := range params {
+		query = append(query, p...)
+	}
+	return
+}
+
+// OpenAPISpec documents all routes of the /v1 API. Every route registered on the
+// REST server needs an entry here.
+var OpenAPISpec = openapi.Spec{
+	Info: openapi.Info{
+		Title:       "diadata.org API",
+		Description: "The world's crowd-driven financial data community has a professional API made for you.",
+		Version:     "1.0",
+	},
+	Servers: []openapi.Server{{URL: "https://api.diadata.org"}},
+	Prefix:  "/v1",
+	Error:   restApi.APIError{},
+	SecuritySchemes: map[string]openapi.SecurityScheme{
+		"bearerAuth": {Type: "http", Scheme: "bearer"},
+		"apiKey":     {Type: "apiKey", In: "header", Name: apiKeyHeader},
+	},
+	PathParams: map[string]string{
+		"symbol":     "Ticker symbol such as BTC.",
+		"blockchain": "Name of the blockchain such as Ethereum.",
+		"address":    "Address of the asset or contract on @blockchain.",
+		"exchange":   "Name of the exchange such as Binance.",
+		"filter":     "Name of the filter such as MA120.",
+		"protocol":   "Name of the protocol.",
+		"asset":      "Symbol of the asset.",
+		"time":       "Unix timestamp or, for interest rates, a day in the format 2006-01-02.",
+		"id":         "Token ID.",
+		"source":     "Name of the data source.",
+	},
+	Routes: map[string]openapi.Route{
+		// Authenticated writes
+		openapi.Key(http.MethodPost, "/v1/supply"): {
+			Summary: "Set the circulating supply of an asset.", Tags: []string{"Supply"},
+			Body: dia.Supply{}, Response: dia.Supply{}, Security: "bearerAuth",
+		},
+		openapi.Key(http.MethodPost, "/v1/indexRebalance/:symbol"): {
+			Summary: "Rebalance an index with the list of [blockchain, address] pairs in the body.", Tags: []string{"Index"},
+			Body: [][]string{}, Response: []models.CryptoIndexConstituent{}, Security: "bearerAuth",
+		},
+		openapi.Key(http.MethodPost, "/v1/quotation"): {
+			Summary: "Set a quotation given as [blockchain, address, price] in the cache.", Tags: []string{"Quotations"},
+			Body: []string{}, Security: "bearerAuth",
+		},
+
+		openapi.Key(http.MethodGet, "/v1/openapi.json"): {
+			Summary: "OpenAPI document of this API.", Tags: []string{"Meta"},
+			Response: openapi.Document{},
+		},
+
+		// Cryptocurrencies and exchanges
+		openapi.Key(http.MethodGet, "/v1/quotation/:symbol"): {
+			Summary: "Latest quotation of the asset with the highest market cap among all assets with @symbol.", Tags: []string{"Quotations"},
+			Response: models.AssetQuotationFull{},
+		},
+		openapi.Key(http.MethodGet, "/v1/assetQuotation/:blockchain/:address"): {
+			Summary: "Latest quotation of an asset.", Tags: []string{"Quotations"},
+			Response: models.AssetQuotationFull{},
+		},
+		openapi.Key(http.MethodGet, "/v1/assetQuotationAt/:blockchain/:address"): {
+			Summary:     "Quotation of an asset at a point in time.",
+			Description: "Returns the quotation at timestamp together with its age, the filter that produced it and the price points it is derived from. The policy locf carries the last price forward, interpolate interpolates linearly between the surrounding prices and failIfStale fails if the last price is older than maxStaleness.",
+			Tags:        []string{"Quotations"},
+			Query: []openapi.Parameter{
+				openapi.QueryParam("timestamp", "integer", "Unix timestamp. Defaults to now."),
+				openapi.QueryParam("maxStaleness", "integer", "Maximal age of the price points in seconds. Unbounded if omitted."),
+				openapi.QueryParam("policy", "string", "One of locf (default), interpolate and failIfStale."),
+			},
+			Response: models.PointInTimeQuotation{},
+		},
+		openapi.Key(http.MethodPost, "/v1/assetQuotations"): {
+			Summary:     "Quotations of up to 500 assets.",
+			Description: "Returns the latest quotations or, if a timestamp is given, the latest quotations before the timestamp. Assets without quotation are omitted.",
+			Tags:        []string{"Quotations"},
+			Body:        assetQuotationsRequest{}, Response: []models.AssetQuotationFull{},
+		},
+		openapi.Key(http.MethodGet, "/v1/lastTrades/:symbol"): {
+			Summary: "Latest trades of the asset with @symbol.", Tags: []string{"Trades"},
+			Response: []dia.Trade{},
+		},
+		openapi.Key(http.MethodGet, "/v1/lastTradesAsset/:blockchain/:address"): {
+			Summary: "Latest trades of an asset.", Tags: []string{"Trades"},
+			Query: []openapi.Parameter{
+				openapi.QueryParam("exchange", "string", "Restrict trades to an exchange."),
+				openapi.QueryParam("numTrades", "integer", "Number of trades."),
+			},
+			Response: []dia.Trade{},
+		},
+		openapi.Key(http.MethodGet, "/v1/supply/:symbol"): {
+			Summary: "Latest circulating supply of the asset with @symbol.", Tags: []string{"Supply"},
+			Response: dia.Supply{},
+		},
+		openapi.Key(http.MethodGet, "/v1/assetSupply/:blockchain/:address"): {
+			Summary:     "Circulating supply of an asset.",
+			Description: "Returns the latest supply or, if a range is given, a list of supplies.",
+			Tags:        []string{"Supply"}, Query: timeRangeQuery,
+			Response: []dia.Supply{},
+		},
+		openapi.Key(http.MethodGet, "/v1/supplies/:symbol"): {
+			Summary: "Circulating supplies of the asset with @symbol.", Tags: []string{"Supply"},
+			Query: timeRangeQuery, Response: []dia.Supply{},
+		},
+		openapi.Key(http.MethodGet, "/v1/symbols"): {
+			Summary: "All symbols.", Tags: []string{"Assets"},
+			Query: []openapi.Parameter{
+				openapi.QueryParam("exchange", "string", "Restrict symbols to an exchange."),
+				openapi.QueryParam("top", "integer", "Number of symbols with the highest volume."),
+			},
+			Response: []string{},
+		},
+		openapi.Key(http.MethodGet, "/v1/symbols/:substring"): {
+			Summary: "All symbols containing @substring.", Tags: []string{"Assets"},
+			Response: []string{},
+		},
+		openapi.Key(http.MethodGet, "/v1/volume/:symbol"): {
+			Summary: "Trading volume of the asset with @symbol.", Tags: []string{"Volume"},
+			Query: timeRangeQuery, Response: float64(0),
+		},
+		openapi.Key(http.MethodGet, "/v1/volume24/:exchange"): {
+			Summary: "Trading volume of an exchange in the last 24 hours.", Tags: []string{"Volume"},
+			Response: float64(0),
+		},
+		openapi.Key(http.MethodGet, "/v1/pairs"): {
+			Summary: "All exchange pairs.", Tags: []string{"Exchanges"},
+			Response: models.Pairs{},
+		},
+		openapi.Key(http.MethodGet, "/v1/exchanges"): {
+			Summary: "All exchanges.", Tags: []string{"Exchanges"},
+			Response: []string{},
+		},
+		openapi.Key(http.MethodGet, "/v1/chartPoints/:filter/:exchange/:symbol"): {
+			Summary: "Filter values of a symbol on an exchange.", Tags: []string{"Charts"},
+			Query:    withQuery(timeRangeQuery, []openapi.Parameter{openapi.QueryParam("scale", "string", "Scale such as 5m or 1h.")}),
+			Response: models.Points{},
+		},
+		openapi.Key(http.MethodGet, "/v1/assetChartPoints/:filter/:blockchain/:address"): {
+			Summary: "Filter values of an asset.", Tags: []string{"Charts"},
+			Query:    withQuery(timeRangeQuery, []openapi.Parameter{openapi.QueryParam("exchange", "string", "Restrict filter values to an exchange.")}),
+			Response: models.Points{},
+		},
+		openapi.Key(http.MethodGet, "/v1/chartPointsAllExchanges/:filter/:symbol"): {
+			Summary: "Filter values of a symbol across all exchanges.", Tags: []string{"Charts"},
+			Query:    withQuery(timeRangeQuery, []openapi.Parameter{openapi.QueryParam("scale", "string", "Scale such as 5m or 1h.")}),
+			Response: models.Points{},
+		},
+		openapi.Key(http.MethodGet, "/v1/missingToken/:exchange"): {
+			Summary: "Unverified symbols of an exchange.", Tags: []string{"Exchanges"},
+			Response: []string{},
+		},
+		openapi.Key(http.MethodGet, "/v1/token/:symbol"): {
+			Summary: "All assets with @symbol.", Tags: []string{"Assets"},
+			Response: []dia.Asset{},
+		},
+		openapi.Key(http.MethodGet, "/v1/tokenexchanges/:symbol"): {
+			Summary: "All exchanges the asset with @symbol is traded on.", Tags: []string{"Assets"},
+			Response: []string{},
+		},
+		openapi.Key(http.MethodGet, "/v1/blockchains"): {
+			Summary: "All blockchains.", Tags: []string{"Assets"},
+			Response: []string{},
+		},
+		openapi.Key(http.MethodGet, "/v1/feedStats/:blockchain/:address"): {
+			Summary: "Volume, price and trades distribution of an asset.", Tags: []string{"Volume"},
+			Query: timeRangeQuery, Response: []feedStats{},
+		},
+
+		// Crypto derivatives
+		openapi.Key(http.MethodGet, "/v1/cryptoDerivatives/:type/:name"): {
+			Summary: "Information on a crypto derivative.", Tags: []string{"Derivatives"},
+		},
+		openapi.Key(http.MethodGet, "/v1/CryptoDerivatives/:type/:name"): {
+			Summary: "Deprecated alias of /v1/cryptoDerivatives/{type}/{name}.", Tags: []string{"Derivatives"},
+			Deprecated: true,
+		},
+		openapi.Key(http.MethodGet, "/v1/cviIndex"): {
+			Summary: "Values of the crypto volatility index.", Tags: []string{"Index"},
+			Query:    withQuery(timeRangeQuery, []openapi.Parameter{openapi.QueryParam("symbol", "string", "Symbol of the volatility index.")}),
+			Response: []dia.CviDataPoint{},
+		},
+
+		// DeFi lending
+		openapi.Key(http.MethodGet, "/v1/defiLendingProtocols"): {
+			Summary: "All DeFi lending protocols.", Tags: []string{"DeFi"},
+			Response: []dia.DefiProtocol{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiLendingRate/:protocol/:asset"): {
+			Summary:     "Lending market of an asset on a protocol.",
+			Description: "Returns rates, supply, borrows, utilisation and risk parameters of the market. @asset is the symbol or the address of the asset. Returns the latest data point or, if a range is given, a list of data points.",
+			Tags:        []string{"DeFi"}, Query: unixRangeQuery,
+			Response: []dia.DefiRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiLendingRate/:protocol/:asset/:time"): {
+			Summary: "Lending market of an asset on a protocol at @time.", Tags: []string{"DeFi"},
+			Response: dia.DefiRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiLendingState/:protocol"): {
+			Summary:     "State of a lending protocol.",
+			Description: "Returns the latest state or, if a range is given, a list of states.",
+			Tags:        []string{"DeFi"}, Query: unixRangeQuery,
+			Response: []dia.DefiProtocolState{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiLendingState/:protocol/:time"): {
+			Summary: "State of a lending protocol at @time.", Tags: []string{"DeFi"},
+			Response: dia.DefiProtocolState{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiCollateralAtRisk/:protocol/:asset"): {
+			Summary:     "Collateral-at-risk curve of an asset on a lending protocol.",
+			Description: "Per drop of the DIA price of the asset, the collateral in the asset posted by borrowers whose health factor falls below 1, together with the total collateral and debt of these borrowers in USD. The drop applies to collateral and debt in the asset. Positions are read from the protocol contracts and count collateral enabled as such only. @asset is the address or the symbol of the asset.",
+			Tags:        []string{"DeFi"},
+			Query: []openapi.Parameter{
+				openapi.QueryParam("maxDrop", "number", "Largest price drop in per cent. Defaults to 50."),
+				openapi.QueryParam("step", "number", "Step between two price drops in per cent, with at most 100 steps up to maxDrop. Defaults to 5."),
+			},
+			Response: defiCollateralAtRisk{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiHealthFactors/:protocol"): {
+			Summary:     "Distribution of the health factors of the borrowers on a lending protocol.",
+			Description: "The health factor of a borrower is the collateral weighted with the liquidation thresholds over the debt, both valued with DIA prices. Borrowers with a health factor below 1 can be liquidated. MaxHealthFactor is zero for the last, unbounded bucket.",
+			Tags:        []string{"DeFi"},
+			Response:    defiHealthFactors{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiLiquidations/:protocol"): {
+			Summary:     "Liquidations on a lending protocol.",
+			Description: "Liquidations in the time range, oldest first. ValueUSD is the value of the repaid debt at the time the liquidation was indexed.",
+			Tags:        []string{"DeFi"},
+			Query:       defiRangeQuery,
+			Response:    defiLiquidations{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiMakerDAOIlks"): {
+			Summary:     "Latest state of all MakerDAO collateral types.",
+			Description: "Per collateral type (ilk), the debt in DAI, the debt ceiling and its usage, the minimum debt of a vault, the yearly stability fee in per cent, the liquidation ratio and the collateral price used by MakerDAO.",
+			Tags:        []string{"DeFi"},
+			Response:    []models.MakerDAOIlk{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiMakerDAOIlk/:ilk"): {
+			Summary:     "States of a MakerDAO collateral type.",
+			Description: "States of @ilk such as ETH-A in the time range, oldest first.",
+			Tags:        []string{"DeFi"}, Query: defiRangeQuery,
+			Response: []models.MakerDAOIlk{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiMakerDAOVaults/:ilk"): {
+			Summary:     "Collateralisation distribution of the vaults of a MakerDAO collateral type.",
+			Description: "Statistics of the vaults with debt of @ilk opened through the CDP manager in the time range, oldest first. The collateralisation of a vault is the value of its collateral over its debt. NumUnsafe vaults are below the liquidation ratio. MaxCollateralisation is zero for the last, unbounded bucket.",
+			Tags:        []string{"DeFi"}, Query: defiRangeQuery,
+			Response: []models.MakerDAOVaultStats{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiMakerDAOSavings"): {
+			Summary:     "MakerDAO DAI savings rate.",
+			Description: "States of the DSR in the time range, oldest first, with the yearly rate in per cent, the DAI deposited and the total debt and debt ceiling of MakerDAO.",
+			Tags:        []string{"DeFi"}, Query: defiRangeQuery,
+			Response: []models.MakerDAOSavings{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiTVL/:protocol"): {
+			Summary:     "Total value locked in a protocol.",
+			Description: "TVL of @protocol in the time range, oldest first. It is the sum of the balances held by the contracts of the protocol valued with DIA prices. Borrowed funds are not held by the contracts and do not add to the TVL. NumUnpriced assets have no DIA price and are left out. The TVL is summed over all blockchains unless byBlockchain is set or a blockchain is given.",
+			Tags:        []string{"DeFi"},
+			Query: withQuery(defiRangeQuery, defiTVLQuery, []openapi.Parameter{
+				openapi.QueryParam("byBlockchain", "boolean", "Return the TVL per blockchain."),
+			}),
+			Response: []models.DefiTVLTotal{},
+		},
+		openapi.Key(http.MethodGet, "/v1/defiTVLAssets/:protocol"): {
+			Summary:     "Assets locked in a protocol.",
+			Description: "Balances and values of the assets held by @protocol, oldest first. Without a time range, the assets of the latest update per blockchain are returned. PriceSource is the source of the price followed by the lookup it was found by, either cache or stored.",
+			Tags:        []string{"DeFi"},
+			Query: withQuery([]openapi.Parameter{
+				openapi.QueryParam("starttime", "integer", "Unix timestamp. Defaults to a day before endtime."),
+				openapi.QueryParam("endtime", "integer", "Unix timestamp. Defaults to now."),
+			}, defiTVLQuery),
+			Response: []models.DefiTVL{},
+		},
+
+		// Farming pools
+		openapi.Key(http.MethodGet, "/v1/farmingPools"): {
+			Summary: "All farming pools.", Tags: []string{"Farming"},
+			Response: []models.FarmingPoolType{},
+		},
+		openapi.Key(http.MethodGet, "/v1/farmingPoolData/:protocol/:poolID"): {
+			Summary:     "Data of a farming pool.",
+			Description: "Returns the latest data or, if a range is given, a list of data points. BaseAPR, RewardAPR and APY are yearly yields in per cent and TVL the value of the stake in USD, all zero where they cannot be determined. Methodology describes how Rate, Balance and the yield of the pools of the protocol are defined.",
+			Tags:        []string{"Farming"}, Query: unixRangeQuery,
+			Response: []models.FarmingPool{},
+		},
+		openapi.Key(http.MethodGet, "/v1/farmingPoolData/:protocol/:poolID/:time"): {
+			Summary: "Data of a farming pool at @time.", Tags: []string{"Farming"},
+			Response: models.FarmingPool{},
+		},
+		openapi.Key(http.MethodGet, "/v1/FarmingPools"): {
+			Summary: "Deprecated alias of /v1/farmingPools.", Tags: []string{"Farming"},
+			Deprecated: true, Response: []models.FarmingPoolType{},
+		},
+		openapi.Key(http.MethodGet, "/v1/FarmingPoolData/:protocol/:poolID"): {
+			Summary: "Deprecated alias of /v1/farmingPoolData/{protocol}/{poolID}.", Tags: []string{"Farming"},
+			Deprecated: true, Query: unixRangeQuery, Response: []models.FarmingPool{},
+		},
+		openapi.Key(http.MethodGet, "/v1/FarmingPoolData/:protocol/:poolID/:time"): {
+			Summary: "Deprecated alias of /v1/farmingPoolData/{protocol}/{poolID}/{time}.", Tags: []string{"Farming"},
+			Deprecated: true, Response: models.FarmingPool{},
+		},
+
+		// Interest rates
+		openapi.Key(http.MethodGet, "/v1/interestrates"): {
+			Summary: "Metadata of all interest rates.", Tags: []string{"Interest rates"},
+			Response: []models.InterestRateMeta{},
+		},
+		openapi.Key(http.MethodGet, "/v1/interestrate/:symbol"): {
+			Summary: "Latest value or range of an interest rate.", Tags: []string{"Interest rates"},
+			Query: dateRangeQuery, Response: []models.InterestRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/interestrate/:symbol/:time"): {
+			Summary: "Value of an interest rate on a day given in the format 2006-01-02.", Tags: []string{"Interest rates"},
+			Response: models.InterestRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedRate/:symbol/:dpy"): {
+			Summary: "Compounded index of an interest rate with @dpy days per year.", Tags: []string{"Interest rates"},
+			Query: dateRangeQuery, Response: []models.InterestRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedRate/:symbol/:dpy/:time"): {
+			Summary: "Compounded index of an interest rate on a day.", Tags: []string{"Interest rates"},
+			Response: models.InterestRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedRateInArrears/:symbol/:dpy"): {
+			Summary: "Interest rate compounded in arrears from dateInit to dateFinal with an explicit convention.", Tags: []string{"Interest rates"},
+			Query: withQuery(dateRangeQuery, inArrearsQuery), Response: models.CompoundedRateInArrears{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedRateFallback/:ibor/:tenor"): {
+			Summary: "ISDA fallback rate of an IBOR for a tenor, i.e. its compounded risk-free rate plus the spread adjustment.", Tags: []string{"Interest rates"},
+			Query: withQuery(dateRangeQuery, inArrearsQuery), Response: models.CompoundedRateInArrears{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedAvg/:symbol/:days/:dpy"): {
+			Summary: "Compounded average of an interest rate over @days calendar days.", Tags: []string{"Interest rates"},
+			Query: dateRangeQuery, Response: []models.InterestRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedAvg/:symbol/:days/:dpy/:time"): {
+			Summary: "Compounded average of an interest rate on a day.", Tags: []string{"Interest rates"},
+			Response: models.InterestRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedAvgDIA/:symbol/:days/:dpy"): {
+			Summary: "Compounded average of an interest rate using DIA's methodology.", Tags: []string{"Interest rates"},
+			Query: dateRangeQuery, Response: []models.InterestRate{},
+		},
+		openapi.Key(http.MethodGet, "/v1/compoundedAvgDIA/:symbol/:days/:dpy/:time"): {
+			Summary: "Compounded average of an interest rate using DIA's methodology on a day.", Tags: []string{"Interest rates"},
+			Response: []models.InterestRate{},
+		},
+
+		// Fiat, foreign sources and custom products
+		openapi.Key(http.MethodGet, "/v1/fiatQuotations"): {
+			Summary: "Latest fiat currency exchange rates.", Tags: []string{"Fiat"},
+			Response: models.Change{},
+		},
+		openapi.Key(http.MethodGet, "/v1/foreignQuotation/:source/:symbol"): {
+			Summary: "Latest quotation of @symbol from a foreign source.", Tags: []string{"Foreign quotations"},
+			Query:    []openapi.Parameter{openapi.QueryParam("time", "integer", "Unix timestamp.")},
+			Response: models.ForeignQuotation{},
+		},
+		openapi.Key(http.MethodGet, "/v1/foreignQuotation/:source/:symbol/:time"): {
+			Summary: "Quotation of @symbol from a foreign source at @time.", Tags: []string{"Foreign quotations"},
+			Response: models.ForeignQuotation{},
+		},
+		openapi.Key(http.MethodGet, "/v1/foreignSymbols/:source"): {
+			Summary: "All symbols of a foreign source.", Tags: []string{"Foreign quotations"},
+			Response: []models.SymbolShort{},
+		},
+		openapi.Key(http.MethodGet, "/v1/custom/vwapFirefly/:ticker"): {
+			Summary:     "VWAP of a Firefly ticker.",
+			Description: "Returns the latest value or, if a range is given, a list of values.",
+			Tags:        []string{"Custom"}, Query: timeRangeQuery,
+			Response: []vwapFirefly{},
+		},
+		openapi.Key(http.MethodGet, "/v1/goldPaxgOunces"): {
+			Summary: "Gold price per troy ounce derived from PAXG.", Tags: []string{"Quotations"},
+			Response: models.Quotation{},
+		},
+		openapi.Key(http.MethodGet, "/v1/goldPaxgGrams"): {
+			Summary: "Gold price per gram derived from PAXG.", Tags: []string{"Quotations"},
+			Response: models.Quotation{},
+		},
+
+		// Index
+		openapi.Key(http.MethodGet, "/v1/index/:symbol"): {
+			Summary: "Crypto index with its constituents.", Tags: []string{"Index"},
+			Query:    withQuery(timeRangeQuery, []openapi.Parameter{openapi.QueryParam("maxResults", "integer", "Maximal number of index values.")}),
+			Response: []models.CryptoIndex{},
+		},
+		openapi.Key(http.MethodGet, "/v1/indexValue/:symbol"): {
+			Summary: "Values of a crypto index.", Tags: []string{"Index"},
+			Query:    withQuery(timeRangeQuery, []openapi.Parameter{openapi.QueryParam("frequency", "string", "Sampling frequency of the values.")}),
+			Response: []indexValue{},
+		},
+		openapi.Key(http.MethodGet, "/v1/benchmarkedIndexValue/:symbol"): {
+			Summary: "Values of a benchmarked index.", Tags: []string{"Index"},
+			Query: timeRangeQuery, Response: models.BenchmarkedIndex{},
+		},
+
+		// External supply reports
+		openapi.Key(http.MethodGet, "/v1/diaTotalSupply"): {
+			Summary: "Total supply of DIA.", Tags: []string{"Supply"},
+			Response: float64(0),
+		},
+		openapi.Key(http.MethodGet, "/v1/diaCirculatingSupply"): {
+			Summary: "Circulating supply of DIA.", Tags: []string{"Supply"},
+			Response: float64(0),
+		},
+
+		// NFT
+		openapi.Key(http.MethodGet, "/v1/AllNFTClasses/:blockchain"): {
+			Summary: "All NFT classes on a blockchain.", Tags: []string{"NFT"},
+			Response: []dia.NFTClass{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTClasses/:limit/:offset"): {
+			Summary: "Page of NFT classes.", Tags: []string{"NFT"},
+			Response: []dia.NFTClass{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTCategories"): {
+			Summary: "All NFT categories.", Tags: []string{"NFT"},
+			Response: []string{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFT/:blockchain/:address/:id"): {
+			Summary: "A single NFT.", Tags: []string{"NFT"},
+			Response: dia.NFT{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTTrades/:blockchain/:address/:id"): {
+			Summary: "All trades of an NFT.", Tags: []string{"NFT"},
+			Response: []dia.NFTTrade{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTTradesCurrent/:blockchain/:address/:id"): {
+			Summary: "Recent trades of an NFT.", Tags: []string{"NFT"},
+			Response: []dia.NFTTrade{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTFloor/:blockchain/:address"): {
+			Summary:     "Floor price of an NFT collection.",
+			Description: "The floor price is a low quantile of the sale prices in the floor window after removing wash trades and zero price sales. Prices are normalised to the currency most sales were made in, using the decimals of each sale's currency and USD prices for sales in other currencies.",
+			Tags:        []string{"NFT"},
+			Query: withQuery([]openapi.Parameter{
+				openapi.QueryParam("timestamp", "integer", "Unix timestamp."),
+				openapi.QueryParam("quantile", "number", "Quantile of the sale prices in [0,1]. Defaults to 0.1."),
+			}, nftFloorQuery),
+			Response: nftFloor{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTValuation/:blockchain/:address/:id"): {
+			Summary:     "Estimated fair value of a single NFT.",
+			Description: "The latest appraisal of the token. It combines the collection floor, the rarity of the token's traits and the token's last sale, and comes with a confidence interval. Appraisals are computed periodically for all collections.",
+			Tags:        []string{"NFT"},
+			Query:       []openapi.Parameter{openapi.QueryParam("timestamp", "integer", "Unix timestamp. Returns the latest appraisal at or before it.")},
+			Response:    nftValuation{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTRarity/:blockchain/:address"): {
+			Summary:     "NFTs of a collection sorted by rarity.",
+			Description: "Rarity scores are computed from the normalised traits of all NFTs in the collection. A missing trait counts as value none and the number of traits as additional trait. The statistical rarity is the product of the trait frequencies, the information content the sum of their negative binary logarithms. Rank 1 is the rarest NFT.",
+			Tags:        []string{"NFT"},
+			Query: []openapi.Parameter{
+				openapi.QueryParam("method", "string", "Ranking, either informationContent (default) or statistical."),
+				openapi.QueryParam("limit", "integer", "Page size in [1,1000]. Defaults to 100."),
+				openapi.QueryParam("offset", "integer", "Number of NFTs to skip."),
+			},
+			Response: nftRarityPage{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTRarity/:blockchain/:address/:id"): {
+			Summary: "Rarity scores and ranks of a single NFT within its collection.", Tags: []string{"NFT"},
+			Response: nftRarity{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTMarketStats/:blockchain/:address"): {
+			Summary:     "Hourly or daily market statistics of an NFT collection.",
+			Description: "Per interval the trading volume in the main sale currency and in USD, the number of sales, unique buyers and sellers, the number of holders and the floor, median and mean price per item. Zero price transfers are not counted as sales.",
+			Tags:        []string{"NFT"},
+			Query: []openapi.Parameter{
+				openapi.QueryParam("interval", "string", "Either 1h or 1d (default)."),
+				openapi.QueryParam("starttime", "integer", "Unix timestamp. Defaults to 30 days before endtime."),
+				openapi.QueryParam("endtime", "integer", "Unix timestamp. Defaults to now."),
+			},
+			Response: nftMarketStatsRange{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTOrders/:blockchain/:address/:id"): {
+			Summary:     "Bids and offers of a single NFT.",
+			Description: "The order book state of the token, most recently updated order first. Orders are placed, filled, cancelled or expired as observed from marketplace events. Price is the current price in units of the order currency, which for auctions moves from the start to the end price.",
+			Tags:        []string{"NFT"},
+			Query:       []openapi.Parameter{openapi.QueryParam("status", "string", "Only orders with this status: placed, filled, cancelled or expired.")},
+			Response:    nftOrderBook{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTBestBidOffer/:blockchain/:address"): {
+			Summary:     "Best bid and best offer of an NFT collection.",
+			Description: "The highest open bid and the lowest open offer over all tokens of the collection, one pair per currency. Ties go to the older order. The currency with most open orders comes first.",
+			Tags:        []string{"NFT"},
+			Response:    nftBestBidOffers{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTOracleUpdates/:chainID/:oracle/:key"): {
+			Summary:     "Floor price updates pushed to an NFT oracle contract.",
+			Description: "The audit trail of the values written under a key of the NFT oracle contract on the chain with the given ID, oldest first. Values are the floor, the moving average floor, the floor in USD and the confidence score with 8 decimals, and the number of sales in the floor window. Signature is the signature of Signer over chain ID, oracle address, key, values and value timestamp, see the nftOracle package. Status is pending until the transaction is mined, then confirmed or failed.",
+			Tags:        []string{"NFT"},
+			Query: []openapi.Parameter{
+				openapi.QueryParam("starttime", "integer", "Unix timestamp. Defaults to 7 days before endtime."),
+				openapi.QueryParam("endtime", "integer", "Unix timestamp. Defaults to now."),
+			},
+			Response: nftOracleUpdates{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTFloorMA/:blockchain/:address"): {
+			Summary: "Moving average of the floor price of an NFT collection.", Tags: []string{"NFT"},
+			Query:    withQuery([]openapi.Parameter{openapi.QueryParam("lookbackSeconds", "integer", "Length of the moving average window in seconds.")}, nftFloorQuery),
+			Response: nftFloorMA{},
+		},
+		openapi.Key(http.MethodGet, "/v1/NFTDownday/:blockchain/:address"): {
+			Summary: "Downday statistics of the floor price of an NFT collection.", Tags: []string{"NFT"},
+			Query:    withQuery([]openapi.Parameter{openapi.QueryParam("lookbackSeconds", "integer", "Length of the lookback window in seconds.")}, nftFloorQuery),
+			Response: nftDownday{},
+		},
+	},
+}
+
+// OpenAPIHandler serves the OpenAPI document of all routes registered on @engine.
+// The document is generated on the first request, once all routes are registered.
+func OpenAPIHandler(engine *gin.Engine) gin.HandlerFunc {
+	var once sync.Once
+	var doc *openapi.Document
+	return func(c *gin.Context) {
+		once.Do(func() {
+			var err error
+			doc, err = OpenAPISpec.Document(engine.Routes())
+			if err != nil {
+				log.Warn("generate openapi document: ", err)
+			}
+		})
+		c.JSON(http.StatusOK, doc)
+	}
+}
@@ -0,0 +1,425 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	apiKeyPrefix     = "dia_"
+	apiKeyCacheTTL   = 5 * time.Minute
+	apiUsageTTL      = 48 * time.Hour
+	usageDateFormat  = "2006-01-02"
+	usageFieldSep    = "|"
+	keyAPIKeyCache   = "dia_apikey_"
+	keyAPIRateLimit  = "dia_ratelimit_"
+	keyAPIQuota      = "dia_apiquota_"
+	keyAPIUsage      = "dia_apiusage_"
+	defaultAPIPlan   = "free"
+	rateLimitKeyIdle = 10 * time.Minute
+)
+
+var (
+	// ErrAPIKeyRevoked is returned when a revoked API key is used.
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+	// ErrAPIKeyUnknown is returned when an API key does not exist.
+	ErrAPIKeyUnknown = errors.New("unknown api key")
+	// ErrAPIQuotaExceeded is returned when the daily quota of an API key is exhausted.
+	ErrAPIQuotaExceeded = errors.New("daily quota exceeded")
+)
+
+// RouteLimit is a token bucket configuration. Tokens are refilled with @Rate per second
+// up to the capacity @Burst. Each request consumes one token.
+type RouteLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// APIPlan collects the limits that apply to all keys of the plan.
+// @RouteLimits overrides the default limit for single routes given by their gin path
+// such as /v1/assetQuotation/:blockchain/:address.
+type APIPlan struct {
+	Name        string
+	Limit       RouteLimit
+	DailyQuota  int64
+	RouteLimits map[string]RouteLimit
+}
+
+// LimitForRoute returns the rate limit of @plan that applies to @route.
+func (plan *APIPlan) LimitForRoute(route string) RouteLimit {
+	if limit, ok := plan.RouteLimits[route]; ok {
+		return limit
+	}
+	return plan.Limit
+}
+
+// APIKey is an API key as stored in postgres. The plain key is only known at creation
+// time, afterwards only its hash is persisted.
+type APIKey struct {
+	ID        string
+	Prefix    string
+	Owner     string
+	Plan      APIPlan
+	CreatedAt time.Time
+	RevokedAt time.Time
+}
+
+// MarshalBinary for api keys
+func (k *APIKey) MarshalBinary() ([]byte, error) {
+	return json.Marshal(k)
+}
+
+// UnmarshalBinary for api keys
+func (k *APIKey) UnmarshalBinary(data []byte) error {
+	if err := json.Unmarshal(data, &k); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RateLimitResult is the state of the token bucket after a request.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is refilled completely.
+	Reset time.Duration
+}
+
+// APIUsage is the number of requests of an API key on an endpoint on a given day.
+type APIUsage struct {
+	KeyID    string
+	Endpoint string
+	Date     time.Time
+	Requests int64
+}
+
+// hashAPIKey returns the hex encoded sha256 hash of @key.
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// newAPIKey returns a random API key.
+func newAPIKey() (string, error) {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// SetAPIPlan stores @plan in postgres or updates the plan with the same name.
+func (rdb *RelDB) SetAPIPlan(plan APIPlan) error {
+	routeLimits, err := json.Marshal(plan.RouteLimits)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (name,rate_per_second,burst,daily_quota,route_limits) VALUES ($1,$2,$3,$4,$5) ON CONFLICT (name) DO UPDATE SET rate_per_second=EXCLUDED.rate_per_second,burst=EXCLUDED.burst,daily_quota=EXCLUDED.daily_quota,route_limits=EXCLUDED.route_limits", apiplanTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query, plan.Name, plan.Limit.Rate, plan.Limit.Burst, plan.DailyQuota, routeLimits)
+	return err
+}
+
+// GetAPIPlan returns the plan with @name.
+func (rdb *RelDB) GetAPIPlan(name string) (plan APIPlan, err error) {
+	query := fmt.Sprintf("SELECT name,rate_per_second,burst,daily_quota,route_limits FROM %s WHERE name=$1", apiplanTable)
+	var routeLimits []byte
+	err = rdb.postgresClient.QueryRow(context.Background(), query, name).Scan(&plan.Name, &plan.Limit.Rate, &plan.Limit.Burst, &plan.DailyQuota, &routeLimits)
+	if err != nil {
+		return
+	}
+	if len(routeLimits) > 0 {
+		err = json.Unmarshal(routeLimits, &plan.RouteLimits)
+	}
+	return
+}
+
+// CreateAPIKey issues a new API key for @owner on the plan with name @planName.
+// The plain key is returned once and cannot be retrieved afterwards.
+func (rdb *RelDB) CreateAPIKey(owner string, planName string) (key string, apiKey APIKey, err error) {
+	if planName == "" {
+		planName = defaultAPIPlan
+	}
+	apiKey.Plan, err = rdb.GetAPIPlan(planName)
+	if err != nil {
+		return
+	}
+	key, err = newAPIKey()
+	if err != nil {
+		return
+	}
+	apiKey.Owner = owner
+	apiKey.Prefix = key[:len(apiKeyPrefix)+6]
+	apiKey.CreatedAt = time.Now()
+
+	query := fmt.Sprintf("INSERT INTO %s (key_hash,prefix,owner,plan,created_at) VALUES ($1,$2,$3,$4,$5) RETURNING apikey_id", apikeyTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, hashAPIKey(key), apiKey.Prefix, owner, planName, apiKey.CreatedAt).Scan(&apiKey.ID)
+	return
+}
+
+// GetAPIKey returns the API key belonging to the plain @key. It uses the redis cache if available.
+func (rdb *RelDB) GetAPIKey(key string) (apiKey APIKey, err error) {
+	keyHash := hashAPIKey(key)
+	if rdb.redisClient != nil {
+		err = rdb.redisClient.Get(keyAPIKeyCache + keyHash).Scan(&apiKey)
+		if err == nil {
+			if !apiKey.RevokedAt.IsZero() {
+				err = ErrAPIKeyRevoked
+			}
+			return
+		}
+		if !errors.Is(err, redis.Nil) {
+			log.Error("get api key from cache: ", err)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT apikey_id,prefix,owner,plan,created_at,revoked_at FROM %s WHERE key_hash=$1", apikeyTable)
+	var owner sql.NullString
+	var revokedAt sql.NullTime
+	err = rdb.postgresClient.QueryRow(context.Background(), query, keyHash).Scan(&apiKey.ID, &apiKey.Prefix, &owner, &apiKey.Plan.Name, &apiKey.CreatedAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrAPIKeyUnknown
+		}
+		return
+	}
+	if owner.Valid {
+		apiKey.Owner = owner.String
+	}
+	if revokedAt.Valid {
+		apiKey.RevokedAt = revokedAt.Time
+	}
+	apiKey.Plan, err = rdb.GetAPIPlan(apiKey.Plan.Name)
+	if err != nil {
+		return
+	}
+
+	if rdb.redisClient != nil {
+		errCache := rdb.redisClient.Set(keyAPIKeyCache+keyHash, &apiKey, apiKeyCacheTTL).Err()
+		if errCache != nil {
+			log.Error("set api key cache: ", errCache)
+		}
+	}
+	if !apiKey.RevokedAt.IsZero() {
+		err = ErrAPIKeyRevoked
+	}
+	return
+}
+
+// RevokeAPIKey revokes the API key with @keyID and removes it from the cache.
+func (rdb *RelDB) RevokeAPIKey(keyID string) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at=$1 WHERE apikey_id=$2 AND revoked_at IS NULL RETURNING key_hash", apikeyTable)
+	var keyHash string
+	err := rdb.postgresClient.QueryRow(context.Background(), query, time.Now(), keyID).Scan(&keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAPIKeyUnknown
+		}
+		return err
+	}
+	if rdb.redisClient != nil {
+		return rdb.redisClient.Del(keyAPIKeyCache + keyHash).Err()
+	}
+	return nil
+}
+
+// tokenBucketScript atomically refills and consumes the token bucket stored in the hash KEYS[1].
+// ARGV: rate per second, burst, current time in milliseconds, ttl in milliseconds.
+// Returns {allowed, remaining tokens, milliseconds until the next token is available,
+// milliseconds until the bucket is full}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HMSET", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("PEXPIRE", KEYS[1], ttl)
+local wait = 0
+if tokens < 1 then
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+local reset = math.ceil((burst - tokens) * 1000 / rate)
+return {allowed, math.floor(tokens), wait, reset}
+`)
+
+// quotaIncrScript counts a request in the usage hash KEYS[1] and the daily quota counter KEYS[2]
+// unless the quota is exhausted. ARGV: usage field (empty to not count the request in the usage hash),
+// daily quota (0 for unlimited), ttl in seconds.
+// Returns the number of requests on the day including this one, or -1 if the quota is exhausted.
+var quotaIncrScript = redis.NewScript(`
+local quota = tonumber(ARGV[2])
+local total = tonumber(redis.call("GET", KEYS[2]) or "0")
+if quota > 0 and total >= quota then
+	return -1
+end
+if ARGV[1] ~= "" then
+	redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+	redis.call("EXPIRE", KEYS[1], ARGV[3])
+end
+total = redis.call("INCR", KEYS[2])
+redis.call("EXPIRE", KEYS[2], ARGV[3])
+return total
+`)
+
+// TakeRateLimitToken consumes a token from the bucket of @subject on @route. The bucket
+// lives in redis, so the limit is shared across all replicas of the API. Without redis
+// all requests are allowed.
+func (rdb *RelDB) TakeRateLimitToken(subject string, route string, limit RouteLimit) (result RateLimitResult, err error) {
+	if rdb.redisClient == nil {
+		result.Allowed = true
+		return
+	}
+	result.Limit = limit.Burst
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		result.Allowed = true
+		return
+	}
+	key := keyAPIRateLimit + subject + "_" + route
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	res, err := tokenBucketScript.Run(rdb.redisClient, []string{key}, limit.Rate, limit.Burst, now, rateLimitKeyIdle.Milliseconds()).Result()
+	if err != nil {
+		return
+	}
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 4 {
+		err = errors.New("unexpected token bucket response")
+		return
+	}
+	result.Allowed = values[0].(int64) == 1
+	result.Remaining = int(values[1].(int64))
+	result.RetryAfter = time.Duration(values[2].(int64)) * time.Millisecond
+	result.Reset = time.Duration(values[3].(int64)) * time.Millisecond
+	return
+}
+
+// IncrementAPIUsage counts a request of @subject on @endpoint unless the @dailyQuota of
+// @subject is exhausted, in which case ErrAPIQuotaExceeded is returned and nothing is counted.
+// It returns the number of requests of @subject on the current day. A @dailyQuota of 0 is
+// unlimited. Without redis requests are neither counted nor limited. Anonymous subjects, which
+// are not API key IDs, only have a quota counter expiring with the day, as their usage is not
+// persisted.
+func (rdb *RelDB) IncrementAPIUsage(subject string, endpoint string, dailyQuota int64) (int64, error) {
+	if rdb.redisClient == nil {
+		return 0, nil
+	}
+	date := time.Now().UTC().Format(usageDateFormat)
+	usageKey := keyAPIUsage + date
+	quotaKey := keyAPIQuota + subject + "_" + date
+	usageField := ""
+	if isUUID(subject) {
+		usageField = subject + usageFieldSep + endpoint
+	}
+	total, err := quotaIncrScript.Run(rdb.redisClient, []string{usageKey, quotaKey}, usageField, dailyQuota, int64(apiUsageTTL.Seconds())).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if total < 0 {
+		return 0, ErrAPIQuotaExceeded
+	}
+	return total, nil
+}
+
+// FlushAPIUsage persists the request counters of @date from redis into postgres.
+// Counters are absolute, so flushing the same day repeatedly is safe.
+func (rdb *RelDB) FlushAPIUsage(date time.Time) error {
+	if rdb.redisClient == nil {
+		return nil
+	}
+	day := date.UTC().Format(usageDateFormat)
+	counters, err := rdb.redisClient.HGetAll(keyAPIUsage + day).Result()
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (apikey_id,endpoint,usage_date,requests) VALUES ($1,$2,$3,$4) ON CONFLICT (apikey_id,endpoint,usage_date) DO UPDATE SET requests=EXCLUDED.requests", apiusageTable)
+	for field, value := range counters {
+		fields := strings.SplitN(field, usageFieldSep, 2)
+		if len(fields) != 2 || !isUUID(fields[0]) {
+			// Anonymous requests are only rate limited, not accounted.
+			continue
+		}
+		requests, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Errorf("parse usage counter %s: %v", field, err)
+			continue
+		}
+		_, err = rdb.postgresClient.Exec(context.Background(), query, fields[0], fields[1], usageDay(date), requests)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAPIUsage returns the usage of the API key with @keyID per endpoint and day in [@starttime,@endtime].
+func (rdb *RelDB) GetAPIUsage(keyID string, starttime time.Time, endtime time.Time) (usage []APIUsage, err error) {
+	var rows pgx.Rows
+	query := fmt.Sprintf("SELECT endpoint,usage_date,requests FROM %s WHERE apikey_id=$1 AND usage_date>=$2 AND usage_date<=$3 ORDER BY usage_date DESC, endpoint", apiusageTable)
+	rows, err = rdb.postgresClient.Query(context.Background(), query, keyID, usageDay(starttime), usageDay(endtime))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u := APIUsage{KeyID: keyID}
+		err = rows.Scan(&u.Endpoint, &u.Date, &u.Requests)
+		if err != nil {
+			return
+		}
+		usage = append(usage, u)
+	}
+	return
+}
+
+// usageDay returns the UTC day of @t usage is accounted for.
+func usageDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// isUUID is a cheap check for the canonical textual representation of a UUID.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitWithoutRedis(t *testing.T) {
+	rdb := &RelDB{}
+	result, err := rdb.TakeRateLimitToken("ip:127.0.0.1", "/v1/quotation/:symbol", RouteLimit{Rate: 1, Burst: 10})
+	if err != nil || !result.Allowed {
+		t.Errorf("request without redis was not allowed, got: %+v, %v.", result, err)
+	}
+	if _, err := rdb.IncrementAPIUsage("ip:127.0.0.1", "/v1/quotation/:symbol", 1); err != nil {
+		t.Errorf("usage without redis was not accepted: %v", err)
+	}
+	if err := rdb.FlushAPIUsage(time.Now()); err != nil {
+		t.Errorf("flush without redis failed: %v", err)
+	}
+}
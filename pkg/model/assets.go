@@ -78,6 +78,38 @@ func (rdb *RelDB) GetAsset(address, blockchain string) (asset dia.Asset, err err
 	return
 }
 
+// GetAssetsByAddress returns the assets of the asset table with the address and blockchain of one of @assets,
+// using a single query. Assets without entry are omitted.
+func (rdb *RelDB) GetAssetsByAddress(assets []dia.Asset) (result []dia.Asset, err error) {
+	addresses := make([]string, len(assets))
+	blockchains := make([]string, len(assets))
+	for i, asset := range assets {
+		addresses[i], blockchains[i] = asset.Address, asset.Blockchain
+	}
+	query := fmt.Sprintf("SELECT symbol,name,address,decimals,blockchain FROM %s WHERE (address,blockchain) IN (SELECT * FROM unnest($1::text[],$2::text[]))", assetTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, addresses, blockchains)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var decimals string
+		var asset dia.Asset
+		if err = rows.Scan(&asset.Symbol, &asset.Name, &asset.Address, &decimals, &asset.Blockchain); err != nil {
+			return
+		}
+		decimalsInt, err := strconv.Atoi(decimals)
+		if err != nil {
+			return nil, err
+		}
+		asset.Decimals = uint8(decimalsInt)
+		result = append(result, asset)
+	}
+	err = rows.Err()
+	return
+}
+
 // GetAssetByID returns an asset by its uuid
 func (rdb *RelDB) GetAssetByID(assetID string) (asset dia.Asset, err error) {
 	var decimals string
@@ -189,28 +221,33 @@ func (rdb *RelDB) GetFiatAssetBySymbol(symbol string) (asset dia.Asset, err erro
 // of 'United States dollar'? On idea would be to add a table with alternative names for
 // symbol tickers, so WBTC -> [Wrapped Bitcoin, Wrapped bitcoin, Wrapped BTC,...]
 func (rdb *RelDB) IdentifyAsset(asset dia.Asset) (assets []dia.Asset, err error) {
-	query := fmt.Sprintf("SELECT symbol,name,address,decimals,blockchain FROM %s WHERE ", assetTable)
-	var and string
+	var conditions []string
+	var args []interface{}
+	addCondition := func(column string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s=$%d", column, len(args)))
+	}
 	if asset.Symbol != "" {
-		query += fmt.Sprintf("symbol='%s'", asset.Symbol)
-		and = " AND "
+		addCondition("symbol", asset.Symbol)
 	}
 	if asset.Name != "" {
-		query += fmt.Sprintf(and+"name='%s'", asset.Name)
-		and = " AND "
+		addCondition("name", asset.Name)
 	}
 	if asset.Address != "" {
-		query += fmt.Sprintf(and+"address='%s'", common.HexToAddress(asset.Address).Hex())
-		and = " AND "
+		addCondition("address", common.HexToAddress(asset.Address).Hex())
 	}
 	if asset.Decimals != 0 {
-		query += fmt.Sprintf(and+"decimals='%d'", asset.Decimals)
-		and = " AND "
+		addCondition("decimals", strconv.Itoa(int(asset.Decimals)))
 	}
 	if asset.Blockchain != "" {
-		query += fmt.Sprintf(and+"blockchain='%s'", asset.Blockchain)
+		addCondition("blockchain", asset.Blockchain)
 	}
-	rows, err := rdb.postgresClient.Query(context.Background(), query)
+	if len(conditions) == 0 {
+		err = errors.New("asset has no fields to identify it by")
+		return
+	}
+	query := fmt.Sprintf("SELECT symbol,name,address,decimals,blockchain FROM %s WHERE %s", assetTable, strings.Join(conditions, " AND "))
+	rows, err := rdb.postgresClient.Query(context.Background(), query, args...)
 	if err != nil {
 		return
 	}
@@ -328,8 +365,8 @@ func (rdb *RelDB) GetExchangeSymbols(exchange string, substring string) (symbols
 	var rows pgx.Rows
 	if exchange != "" {
 		if substring != "" {
-			query = fmt.Sprintf("SELECT symbol FROM %s WHERE exchange=$1 AND symbol ILIKE '%s%%'", exchangesymbolTable, substring)
-			rows, err = rdb.postgresClient.Query(context.Background(), query, exchange)
+			query = fmt.Sprintf("SELECT symbol FROM %s WHERE exchange=$1 AND symbol ILIKE $2", exchangesymbolTable)
+			rows, err = rdb.postgresClient.Query(context.Background(), query, exchange, likePrefix(substring))
 
 		} else {
 			query = fmt.Sprintf("SELECT symbol FROM %s WHERE exchange=$1", exchangesymbolTable)
@@ -337,9 +374,8 @@ func (rdb *RelDB) GetExchangeSymbols(exchange string, substring string) (symbols
 		}
 	} else {
 		if substring != "" {
-			query = fmt.Sprintf("SELECT symbol FROM %s WHERE symbol ILIKE '%s%%'", exchangesymbolTable, substring)
-			log.Info("query: ", query)
-			rows, err = rdb.postgresClient.Query(context.Background(), query)
+			query = fmt.Sprintf("SELECT symbol FROM %s WHERE symbol ILIKE $1", exchangesymbolTable)
+			rows, err = rdb.postgresClient.Query(context.Background(), query, likePrefix(substring))
 		} else {
 			query = fmt.Sprintf("SELECT symbol FROM %s", exchangesymbolTable)
 			rows, err = rdb.postgresClient.Query(context.Background(), query)
@@ -487,21 +523,21 @@ func (rdb *RelDB) SetExchangePair(exchange string, pair dia.ExchangePair, cache
 		log.Error(err)
 	}
 	if basetokenID != "" {
-		query = fmt.Sprintf("UPDATE %s SET id_basetoken='%s' WHERE foreignname='%s' AND exchange='%s'", exchangepairTable, basetokenID, pair.ForeignName, exchange)
-		_, err = rdb.postgresClient.Exec(context.Background(), query)
+		query = fmt.Sprintf("UPDATE %s SET id_basetoken=$1 WHERE foreignname=$2 AND exchange=$3", exchangepairTable)
+		_, err = rdb.postgresClient.Exec(context.Background(), query, basetokenID, pair.ForeignName, exchange)
 		if err != nil {
 			return err
 		}
 	}
 	if quotetokenID != "" {
-		query = fmt.Sprintf("UPDATE %s SET id_quotetoken='%s' WHERE foreignname='%s' AND exchange='%s'", exchangepairTable, quotetokenID, pair.ForeignName, exchange)
-		_, err = rdb.postgresClient.Exec(context.Background(), query)
+		query = fmt.Sprintf("UPDATE %s SET id_quotetoken=$1 WHERE foreignname=$2 AND exchange=$3", exchangepairTable)
+		_, err = rdb.postgresClient.Exec(context.Background(), query, quotetokenID, pair.ForeignName, exchange)
 		if err != nil {
 			return err
 		}
 	}
-	query = fmt.Sprintf("UPDATE %s SET verified='%v' WHERE foreignname='%s' AND exchange='%s'", exchangepairTable, pair.Verified, pair.ForeignName, exchange)
-	_, err = rdb.postgresClient.Exec(context.Background(), query)
+	query = fmt.Sprintf("UPDATE %s SET verified=$1 WHERE foreignname=$2 AND exchange=$3", exchangepairTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query, pair.Verified, pair.ForeignName, exchange)
 	if err != nil {
 		return err
 	}
@@ -683,12 +719,8 @@ func (rdb *RelDB) GetExchangePairCache(exchange string, foreignName string) (dia
 
 func (rdb *RelDB) SetAssetVolume24H(asset dia.Asset, volume float64) error {
 
-	initialStr := fmt.Sprintf("INSERT INTO %s (asset_id,volume) VALUES ", assetVolumeTable)
-	substring := fmt.Sprintf("((SELECT asset_id FROM asset WHERE address='%s' AND blockchain='%s'),%f)", asset.Address, asset.Blockchain, volume)
-	conflict := " ON CONFLICT (asset_id) do UPDATE SET volume = EXCLUDED.volume "
-
-	query := initialStr + substring + conflict
-	_, err := rdb.postgresClient.Exec(context.Background(), query)
+	query := fmt.Sprintf("INSERT INTO %s (asset_id,volume) VALUES ((SELECT asset_id FROM %s WHERE address=$1 AND blockchain=$2),$3) ON CONFLICT (asset_id) do UPDATE SET volume = EXCLUDED.volume", assetVolumeTable, assetTable)
+	_, err := rdb.postgresClient.Exec(context.Background(), query, asset.Address, asset.Blockchain, volume)
 	if err != nil {
 		return err
 	}
@@ -701,6 +733,38 @@ func (rdb *RelDB) GetAssetVolume24H(asset dia.Asset) (volume float64, err error)
 	return
 }
 
+// GetAssetVolumes24H returns the volumes of the last 24h of @assets by blockchain and address, using a
+// single query. Assets without volume are omitted.
+func (rdb *RelDB) GetAssetVolumes24H(assets []dia.Asset) (map[string]float64, error) {
+	addresses := make([]string, len(assets))
+	blockchains := make([]string, len(assets))
+	for i, asset := range assets {
+		addresses[i], blockchains[i] = asset.Address, asset.Blockchain
+	}
+	query := fmt.Sprintf("SELECT address,blockchain,volume FROM %s INNER JOIN %s ON assetvolume.asset_id = asset.asset_id WHERE (address,blockchain) IN (SELECT * FROM unnest($1::text[],$2::text[]))", assetVolumeTable, assetTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, addresses, blockchains)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	volumes := make(map[string]float64)
+	for rows.Next() {
+		var address, blockchain string
+		var volume float64
+		if err = rows.Scan(&address, &blockchain, &volume); err != nil {
+			return nil, err
+		}
+		volumes[AssetVolumeKey(blockchain, address)] = volume
+	}
+	return volumes, rows.Err()
+}
+
+// AssetVolumeKey is the key of the volume of the asset with @address on @blockchain returned by GetAssetVolumes24H.
+func AssetVolumeKey(blockchain, address string) string {
+	return blockchain + "-" + address
+}
+
 func (rdb *RelDB) GetTopAssetByVolume(symbol string) (assets []dia.Asset, err error) {
 	query := fmt.Sprintf("SELECT symbol,name,address,decimals,blockchain FROM %s INNER JOIN %s ON asset.asset_id = assetvolume.asset_id WHERE symbol=$1 ORDER BY volume DESC", assetTable, assetVolumeTable)
 	var rows pgx.Rows
@@ -802,28 +866,20 @@ func (rdb *RelDB) GetActiveAsset(limit, skip int) (assets []dia.Asset, assetIds
 // If @numAssets==0, all assets are returned.
 // If @substring is not the empty string, results are filtered by the first letters being @substring.
 func (rdb *RelDB) GetAssetsWithVOL(numAssets int64, substring string) (volumeSortedAssets []dia.Asset, err error) {
-	var queryString string
-	var query string
 	var rows pgx.Rows
-	if numAssets == 0 {
-		if substring == "" {
-			queryString = "SELECT symbol,name,address,decimals,blockchain FROM %s INNER JOIN %s ON (asset.asset_id = assetvolume.asset_id) ORDER BY assetvolume.volume DESC"
-			query = fmt.Sprintf(queryString, assetTable, assetVolumeTable)
-		} else {
-			queryString = "SELECT symbol,name,address,decimals,blockchain FROM %s INNER JOIN %s ON (asset.asset_id = assetvolume.asset_id) WHERE symbol ILIKE '%s%%' ORDER BY assetvolume.volume DESC"
-			query = fmt.Sprintf(queryString, assetTable, assetVolumeTable, substring)
-		}
-	} else {
-		if substring == "" {
-			queryString = "SELECT symbol,name,address,decimals,blockchain FROM %s INNER JOIN %s ON (asset.asset_id = assetvolume.asset_id) ORDER BY assetvolume.volume DESC LIMIT %d"
-			query = fmt.Sprintf(queryString, assetTable, assetVolumeTable, numAssets)
-		} else {
-			queryString = "SELECT symbol,name,address,decimals,blockchain FROM %s INNER JOIN %s ON (asset.asset_id = assetvolume.asset_id) WHERE symbol ILIKE '%s%%' ORDER BY assetvolume.volume DESC LIMIT %d"
-			query = fmt.Sprintf(queryString, assetTable, assetVolumeTable, substring, numAssets)
-		}
+	var args []interface{}
+	query := fmt.Sprintf("SELECT symbol,name,address,decimals,blockchain FROM %s INNER JOIN %s ON (asset.asset_id = assetvolume.asset_id)", assetTable, assetVolumeTable)
+	if substring != "" {
+		args = append(args, likePrefix(substring))
+		query += fmt.Sprintf(" WHERE symbol ILIKE $%d", len(args))
+	}
+	query += " ORDER BY assetvolume.volume DESC"
+	if numAssets != 0 {
+		args = append(args, numAssets)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
 	}
 
-	rows, err = rdb.postgresClient.Query(context.Background(), query)
+	rows, err = rdb.postgresClient.Query(context.Background(), query, args...)
 	if err != nil {
 		return
 	}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/db"
 
 	"github.com/diadata-org/diadata/pkg/dia"
@@ -91,7 +92,11 @@ type Datastore interface {
 	SetAssetQuotation(quotation *AssetQuotation) error
 	GetAssetQuotation(asset dia.Asset, timestamp time.Time) (*AssetQuotation, error)
 	GetAssetQuotationLatest(asset dia.Asset) (*AssetQuotation, error)
+	GetAssetQuotationAt(asset dia.Asset, timestamp time.Time, maxStaleness time.Duration, policy QuotationPolicy) (*PointInTimeQuotation, error)
 	GetSortedAssetQuotations(assets []dia.Asset) ([]AssetQuotation, error)
+	GetAssetQuotations(assets []dia.Asset, timestamp time.Time) ([]AssetQuotationFull, error)
+	GetAssetQuotationsLatest(assets []dia.Asset) ([]AssetQuotationFull, error)
+	GetAssetQuotationsCache(assets []dia.Asset) ([]*AssetQuotation, error)
 	AddAssetQuotationsToBatch(quotations []*AssetQuotation) error
 	SetAssetQuotationCache(quotation *AssetQuotation, check bool) (bool, error)
 	GetAssetQuotationCache(asset dia.Asset) (*AssetQuotation, error)
@@ -108,6 +113,8 @@ type Datastore interface {
 	GetInterestRate(symbol, date string) (*InterestRate, error)
 	GetInterestRateRange(symbol, dateInit, dateFinal string) ([]*InterestRate, error)
 	GetRatesMeta() (RatesMeta []InterestRateMeta, err error)
+	GetCompoundedRateInArrears(symbol string, dateInit, dateFinal time.Time, convention ratederivatives.Convention, shift int, daysPerYear int) (*CompoundedRateInArrears, error)
+	GetIBORFallbackRate(ibor, tenor string, dateInit, dateFinal time.Time, convention ratederivatives.Convention, shift int) (*CompoundedRateInArrears, error)
 	GetCompoundedIndex(symbol string, date time.Time, daysPerYear int, rounding int) (*InterestRate, error)
 	GetCompoundedIndexRange(symbol string, dateInit, dateFinal time.Time, daysPerYear int, rounding int) ([]*InterestRate, error)
 	GetCompoundedAvg(symbol string, date time.Time, calDays, daysPerYear int, rounding int) (*InterestRate, error)
@@ -134,6 +141,22 @@ type Datastore interface {
 	GetDefiStateInflux(time.Time, time.Time, string) ([]dia.DefiProtocolState, error)
 	SetDefiStateInflux(state *dia.DefiProtocolState) error
 
+	// DeFi TVL
+	SetDefiTVL(tvls []DefiTVL) error
+	GetDefiTVL(protocol string, blockchain string, starttime time.Time, endtime time.Time) ([]DefiTVL, error)
+	GetDefiTVLLatest(protocol string, blockchain string, endtime time.Time) ([]DefiTVL, error)
+
+	// Liquidity pools of AMMs
+	SetLiquidityPool(pool *dia.Pool) error
+
+	// MakerDAO analytics
+	SetMakerDAOIlk(ilk MakerDAOIlk) error
+	GetMakerDAOIlks(ilk string, starttime time.Time, endtime time.Time) ([]MakerDAOIlk, error)
+	SetMakerDAOSavings(savings MakerDAOSavings) error
+	GetMakerDAOSavings(starttime time.Time, endtime time.Time) ([]MakerDAOSavings, error)
+	SetMakerDAOVaultStats(stats MakerDAOVaultStats) error
+	GetMakerDAOVaultStats(ilk string, starttime time.Time, endtime time.Time) ([]MakerDAOVaultStats, error)
+
 	// Foreign quotation methods
 	SaveForeignQuotationInflux(fq ForeignQuotation) error
 	GetForeignQuotationInflux(symbol, source string, timestamp time.Time) (ForeignQuotation, error)
@@ -205,6 +228,11 @@ const (
 	influxDbDefiRateTable                = "defiRate"
 	influxDbDefiStateTable               = "defiState"
 	influxDbPoolTable                    = "defiPools"
+	influxDbDefiTVLTable                 = "defiTVL"
+	influxDbLiquidityPoolTable           = "liquidityPools"
+	influxDbMakerDAOIlkTable             = "makerdaoIlk"
+	influxDbMakerDAOSavingsTable         = "makerdaoSavings"
+	influxDbMakerDAOVaultsTable          = "makerdaoVaults"
 	influxDbCryptoIndexTable             = "cryptoindex"
 	influxDbCryptoIndexConstituentsTable = "cryptoindexconstituents"
 	influxDbGithubCommitTable            = "githubcommits"
@@ -717,6 +745,12 @@ func (datastore *DB) SetFarmingPool(pool *FarmingPool) error {
 		"rate":        pool.Rate,
 		"balance":     pool.Balance,
 		"blockNumber": pool.BlockNumber,
+		"baseAPR":     pool.BaseAPR,
+		"rewardAPR":   pool.RewardAPR,
+		"apy":         pool.APY,
+		"tvl":         pool.TVL,
+		"rewardAsset": pool.RewardAsset,
+		"rewardPrice": pool.RewardPrice,
 	}
 	inputAssetBytes, err := json.Marshal(pool.InputAsset)
 	if err != nil {
@@ -801,7 +835,7 @@ func (datastore *DB) GetFarmingPools() ([]FarmingPoolType, error) {
 // time, balance, blocknumber, inputAssets, outputAssets, poolID, protocol, rate
 func (datastore *DB) GetFarmingPoolData(starttime, endtime time.Time, protocol, poolID string) ([]FarmingPool, error) {
 	retval := []FarmingPool{}
-	influxQuery := "SELECT balance,blockNumber,\"inputAssets\",\"outputAssets\",\"poolID\",\"protocol\",rate FROM %s WHERE time > %d and time <= %d and protocol = '%s' and poolID='%s' order by desc"
+	influxQuery := "SELECT balance,blockNumber,\"inputAssets\",\"outputAssets\",\"poolID\",\"protocol\",rate,baseAPR,rewardAPR,apy,tvl,rewardAsset,rewardPrice FROM %s WHERE time > %d and time <= %d and protocol = '%s' and poolID='%s' order by desc"
 	q := fmt.Sprintf(influxQuery, influxDbPoolTable, starttime.UnixNano(), endtime.UnixNano(), protocol, poolID)
 	res, err := queryInfluxDB(datastore.influxClient, q)
 	if err != nil {
@@ -840,6 +874,16 @@ func (datastore *DB) GetFarmingPoolData(starttime, endtime time.Time, protocol,
 			if err != nil {
 				return retval, err
 			}
+			// The yield fields are empty in states stored before the yield was normalised.
+			for k, field := range []*float64{&pool.BaseAPR, &pool.RewardAPR, &pool.APY, &pool.TVL} {
+				if *field, err = defiFloat(res[0].Series[0].Values[i][8+k]); err != nil {
+					return retval, err
+				}
+			}
+			pool.RewardAsset, _ = res[0].Series[0].Values[i][12].(string)
+			if pool.RewardPrice, err = defiFloat(res[0].Series[0].Values[i][13]); err != nil {
+				return retval, err
+			}
 
 			retval = append(retval, pool)
 		}
@@ -851,13 +895,12 @@ func (datastore *DB) GetFarmingPoolData(starttime, endtime time.Time, protocol,
 }
 
 func (datastore *DB) SetDefiRateInflux(rate *dia.DefiRate) error {
-	fields := map[string]interface{}{
-		"lendingRate": rate.LendingRate,
-		"borrowRate":  rate.BorrowingRate,
-	}
+	fields := defiRateFields(rate)
 	tags := map[string]string{
-		"asset":    rate.Asset,
-		"protocol": rate.Protocol,
+		"asset":           rate.Asset,
+		"assetAddress":    rate.AssetAddress,
+		"assetBlockchain": rate.AssetBlockchain,
+		"protocol":        rate.Protocol,
 	}
 	pt, err := clientInfluxdb.NewPoint(influxDbDefiRateTable, tags, fields, rate.Timestamp)
 	if err != nil {
@@ -874,32 +917,19 @@ func (datastore *DB) SetDefiRateInflux(rate *dia.DefiRate) error {
 	return err
 }
 
+// GetDefiRateInflux returns the lending market data of @asset on @protocol in the given time range.
+// @asset is either the symbol or the address of the asset.
 func (datastore *DB) GetDefiRateInflux(starttime time.Time, endtime time.Time, asset string, protocol string) ([]dia.DefiRate, error) {
 	retval := []dia.DefiRate{}
-	influxQuery := "SELECT \"asset\",borrowRate,lendingRate,\"protocol\" FROM %s WHERE time > %d and time < %d and asset = '%s' and protocol = '%s'"
-	q := fmt.Sprintf(influxQuery, influxDbDefiRateTable, starttime.UnixNano(), endtime.UnixNano(), asset, protocol)
-	fmt.Println("influx query: ", q)
+	influxQuery := "SELECT %s FROM %s WHERE time > %d and time < %d and (asset = '%s' or assetAddress = '%s') and protocol = '%s'"
+	q := fmt.Sprintf(influxQuery, defiRateColumns, influxDbDefiRateTable, starttime.UnixNano(), endtime.UnixNano(), asset, asset, protocol)
 	res, err := queryInfluxDB(datastore.influxClient, q)
-	fmt.Println("res, err: ", res, err)
 	if err != nil {
 		return retval, err
 	}
 	if len(res) > 0 && len(res[0].Series) > 0 {
 		for i := 0; i < len(res[0].Series[0].Values); i++ {
-			currentRate := dia.DefiRate{}
-			currentRate.Timestamp, err = time.Parse(time.RFC3339, res[0].Series[0].Values[i][0].(string))
-			if err != nil {
-				return retval, err
-			}
-			currentRate.Asset = res[0].Series[0].Values[i][1].(string)
-			if err != nil {
-				return retval, err
-			}
-			currentRate.BorrowingRate, err = res[0].Series[0].Values[i][2].(json.Number).Float64()
-			if err != nil {
-				return retval, err
-			}
-			currentRate.LendingRate, err = res[0].Series[0].Values[i][3].(json.Number).Float64()
+			currentRate, err := parseDefiRate(res[0].Series[0].Values[i])
 			if err != nil {
 				return retval, err
 			}
@@ -914,8 +944,9 @@ func (datastore *DB) GetDefiRateInflux(starttime time.Time, endtime time.Time, a
 
 func (datastore *DB) SetDefiStateInflux(state *dia.DefiProtocolState) error {
 	fields := map[string]interface{}{
-		"totalUSD": state.TotalUSD,
-		"totalETH": state.TotalETH,
+		"totalUSD":       state.TotalUSD,
+		"totalETH":       state.TotalETH,
+		"totalBorrowUSD": state.TotalBorrowUSD,
 	}
 	tags := map[string]string{
 		"protocol": state.Protocol.Name,
@@ -936,35 +967,25 @@ func (datastore *DB) SetDefiStateInflux(state *dia.DefiProtocolState) error {
 }
 
 func (datastore *DB) GetDefiStateInflux(starttime time.Time, endtime time.Time, protocol string) (retval []dia.DefiProtocolState, err error) {
-	influxQuery := "SELECT totalETH,totalUSD FROM %s WHERE time > %d and time < %d and protocol = '%s'"
-	q := fmt.Sprintf(influxQuery, influxDbDefiStateTable, starttime.UnixNano(), endtime.UnixNano(), protocol)
+	influxQuery := "SELECT %s FROM %s WHERE time > %d and time < %d and protocol = '%s'"
+	q := fmt.Sprintf(influxQuery, defiStateColumns, influxDbDefiStateTable, starttime.UnixNano(), endtime.UnixNano(), protocol)
 	res, err := queryInfluxDB(datastore.influxClient, q)
 	if err != nil {
 		return retval, err
 	}
 	if len(res) > 0 && len(res[0].Series) > 0 {
+		var defiProtocol dia.DefiProtocol
+		defiProtocol, err = datastore.GetDefiProtocol(protocol)
+		if err != nil {
+			return
+		}
 		for i := 0; i < len(res[0].Series[0].Values); i++ {
-			defiState := dia.DefiProtocolState{}
-			defiState.Timestamp, err = time.Parse(time.RFC3339, res[0].Series[0].Values[i][0].(string))
-			if err != nil {
-				return
-			}
-			// defiState.Protocol.Name = res[0].Series[0].Values[i][1].(string)
-			// if err != nil {
-			// 	return
-			// }
-			defiState.TotalETH, err = res[0].Series[0].Values[i][1].(json.Number).Float64()
-			if err != nil {
-				return
-			}
-			defiState.TotalUSD, err = res[0].Series[0].Values[i][2].(json.Number).Float64()
-			if err != nil {
-				return
-			}
-			defiState.Protocol, err = datastore.GetDefiProtocol(protocol)
+			var defiState dia.DefiProtocolState
+			defiState, err = parseDefiState(res[0].Series[0].Values[i])
 			if err != nil {
 				return
 			}
+			defiState.Protocol = defiProtocol
 			retval = append(retval, defiState)
 		}
 	} else {
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// defiRateColumns are the columns of influxDbDefiRateTable in the order parsed by parseDefiRate.
+const defiRateColumns = `"asset","assetAddress","assetBlockchain",lendingRate,borrowRate,stableBorrowRate,variableBorrowRate,totalSupplied,totalBorrowed,availableLiquidity,totalReserves,utilisation,reserveFactor,collateralFactor,liquidationThreshold`
+
+// defiStateColumns are the columns of influxDbDefiStateTable in the order parsed by parseDefiState.
+const defiStateColumns = "totalETH,totalUSD,totalBorrowUSD"
+
+func defiRateFields(rate *dia.DefiRate) map[string]interface{} {
+	return map[string]interface{}{
+		"lendingRate":          rate.LendingRate,
+		"borrowRate":           rate.BorrowingRate,
+		"stableBorrowRate":     rate.StableBorrowRate,
+		"variableBorrowRate":   rate.VariableBorrowRate,
+		"totalSupplied":        rate.TotalSupplied,
+		"totalBorrowed":        rate.TotalBorrowed,
+		"availableLiquidity":   rate.AvailableLiquidity,
+		"totalReserves":        rate.TotalReserves,
+		"utilisation":          rate.Utilisation,
+		"reserveFactor":        rate.ReserveFactor,
+		"collateralFactor":     rate.CollateralFactor,
+		"liquidationThreshold": rate.LiquidationThreshold,
+	}
+}
+
+// parseDefiRate parses a row of defiRateColumns as retrieved from influx.
+func parseDefiRate(row []interface{}) (rate dia.DefiRate, err error) {
+	if len(row) != 16 {
+		err = fmt.Errorf("unexpected number of columns in defi rate: %d", len(row))
+		return
+	}
+	timestamp, ok := row[0].(string)
+	if !ok {
+		err = fmt.Errorf("unexpected time in defi rate: %v", row[0])
+		return
+	}
+	rate.Timestamp, err = time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return
+	}
+	rate.Asset, _ = row[1].(string)
+	rate.AssetAddress, _ = row[2].(string)
+	rate.AssetBlockchain, _ = row[3].(string)
+
+	for i, field := range []*float64{
+		&rate.LendingRate,
+		&rate.BorrowingRate,
+		&rate.StableBorrowRate,
+		&rate.VariableBorrowRate,
+		&rate.TotalSupplied,
+		&rate.TotalBorrowed,
+		&rate.AvailableLiquidity,
+		&rate.TotalReserves,
+		&rate.Utilisation,
+		&rate.ReserveFactor,
+		&rate.CollateralFactor,
+		&rate.LiquidationThreshold,
+	} {
+		*field, err = defiFloat(row[i+4])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// parseDefiState parses a row of defiStateColumns as retrieved from influx.
+func parseDefiState(row []interface{}) (state dia.DefiProtocolState, err error) {
+	if len(row) != 4 {
+		err = fmt.Errorf("unexpected number of columns in defi state: %d", len(row))
+		return
+	}
+	timestamp, ok := row[0].(string)
+	if !ok {
+		err = fmt.Errorf("unexpected time in defi state: %v", row[0])
+		return
+	}
+	state.Timestamp, err = time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return
+	}
+	if state.TotalETH, err = defiFloat(row[1]); err != nil {
+		return
+	}
+	if state.TotalUSD, err = defiFloat(row[2]); err != nil {
+		return
+	}
+	state.TotalBorrowUSD, err = defiFloat(row[3])
+	return
+}
+
+// defiFloat returns the value of a numeric field. Fields missing in points written
+// before the field was introduced are returned as zero.
+func defiFloat(value interface{}) (float64, error) {
+	if value == nil {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("unexpected field value: %v", value)
+	}
+	return number.Float64()
+}
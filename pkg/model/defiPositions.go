@@ -0,0 +1,385 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jackc/pgx/v4"
+)
+
+// DefiPosition is the collateral supplied and the debt owed by an account in one market of a lending protocol.
+// Amounts are in units of the underlying asset. Positions are read from the protocol contracts after each
+// change and on refreshes, so they include the interest accrued until the last read. Collateral only counts
+// supplied assets the account enabled as collateral.
+type DefiPosition struct {
+	Protocol   string
+	Account    string
+	Asset      dia.Asset
+	Collateral float64
+	Debt       float64
+	Time       time.Time
+}
+
+// DefiPositionChange changes the position of Account in the market of Asset. Collateral and Debt are added
+// to the position. If SetCollateral or SetDebt is true, Collateral or Debt is the new amount of the position instead.
+// BlockNumber and LogIndex are the position of the causing event on chain.
+type DefiPositionChange struct {
+	Account       string
+	Asset         dia.Asset
+	Collateral    float64
+	Debt          float64
+	SetCollateral bool
+	SetDebt       bool
+	BlockNumber   uint64
+	LogIndex      uint
+	Time          time.Time
+}
+
+// DefiLiquidation is a liquidation of a borrower on a lending protocol, in which Liquidator repaid
+// DebtAmount of DebtAsset and seized CollateralAmount of CollateralAsset.
+type DefiLiquidation struct {
+	Protocol         string
+	Borrower         string
+	Liquidator       string
+	CollateralAsset  dia.Asset
+	CollateralAmount float64
+	DebtAsset        dia.Asset
+	DebtAmount       float64
+	// ValueUSD is the value of the repaid debt at the time the liquidation was indexed.
+	ValueUSD    float64
+	BlockNumber uint64
+	TxHash      string
+	LogIndex    uint
+	Time        time.Time
+}
+
+// DefiMarketRisk are the price in USD and the liquidation threshold of an asset on a lending protocol.
+type DefiMarketRisk struct {
+	Price                float64
+	LiquidationThreshold float64
+}
+
+// DefiCollateralAtRisk is a point of the collateral-at-risk curve of an asset. At a drop of the price
+// of the asset by PriceDrop, the accounts with collateral in the asset and a health factor below 1
+// can be liquidated.
+type DefiCollateralAtRisk struct {
+	// PriceDrop is a fraction in [0,1], Price the price of the asset after the drop.
+	PriceDrop float64
+	Price     float64
+	// Collateral is the amount of the asset posted as collateral by the liquidatable accounts.
+	// CollateralUSD and DebtUSD are the total collateral and debt of these accounts after the drop.
+	Collateral    float64
+	CollateralUSD float64
+	DebtUSD       float64
+	NumAccounts   int
+}
+
+// DefiHealthFactorBucket aggregates the borrowers with a health factor in [MinHealthFactor, MaxHealthFactor).
+// MaxHealthFactor is zero for the last, unbounded bucket.
+type DefiHealthFactorBucket struct {
+	MinHealthFactor float64
+	MaxHealthFactor float64
+	NumAccounts     int
+	CollateralUSD   float64
+	DebtUSD         float64
+}
+
+// DefiHealthFactorBounds are the default lower bounds of the buckets returned by ComputeDefiHealthFactors.
+var DefiHealthFactorBounds = []float64{0, 1, 1.05, 1.1, 1.25, 1.5, 2, 5}
+
+// defiAccountValue is the value of the positions of an account.
+type defiAccountValue struct {
+	// Collateral is the amount of the asset of interest posted as collateral.
+	Collateral    float64
+	CollateralUSD float64
+	// WeightedCollateralUSD is the collateral weighted with the liquidation thresholds.
+	WeightedCollateralUSD float64
+	DebtUSD               float64
+}
+
+// HealthFactor returns the weighted collateral over the debt. Accounts without debt have an infinite health factor.
+func (value defiAccountValue) HealthFactor() float64 {
+	if value.DebtUSD == 0 {
+		return math.Inf(1)
+	}
+	return value.WeightedCollateralUSD / value.DebtUSD
+}
+
+// defiAccountValues returns the value of the positions per account, where the price of @asset is multiplied
+// by @priceFactor. Accounts with a position in a market missing in @markets cannot be valued and are left out.
+func defiAccountValues(positions []DefiPosition, markets map[string]DefiMarketRisk, asset string, priceFactor float64) map[string]defiAccountValue {
+	values := make(map[string]defiAccountValue)
+	unknown := make(map[string]bool)
+	for _, position := range positions {
+		market, ok := markets[position.Asset.Address]
+		if !ok {
+			unknown[position.Account] = true
+			continue
+		}
+		price := market.Price
+		value := values[position.Account]
+		if position.Asset.Address == asset {
+			price *= priceFactor
+			value.Collateral += position.Collateral
+		}
+		value.CollateralUSD += position.Collateral * price
+		value.WeightedCollateralUSD += position.Collateral * price * market.LiquidationThreshold
+		value.DebtUSD += position.Debt * price
+		values[position.Account] = value
+	}
+	for account := range unknown {
+		delete(values, account)
+	}
+	return values
+}
+
+// DefiHealthFactor returns the health factor of an account with @positions, i.e. the sum of its collateral
+// weighted with the liquidation thresholds over the sum of its debt, both in USD. An account with a health
+// factor below 1 can be liquidated. Returns an error if a market of the positions is missing in @markets.
+func DefiHealthFactor(positions []DefiPosition, markets map[string]DefiMarketRisk) (float64, error) {
+	var value defiAccountValue
+	for _, position := range positions {
+		market, ok := markets[position.Asset.Address]
+		if !ok {
+			return 0, fmt.Errorf("no price and liquidation threshold for asset %s", position.Asset.Address)
+		}
+		value.WeightedCollateralUSD += position.Collateral * market.Price * market.LiquidationThreshold
+		value.DebtUSD += position.Debt * market.Price
+	}
+	return value.HealthFactor(), nil
+}
+
+// ComputeDefiCollateralAtRisk returns the collateral-at-risk curve of the asset with address @asset for each
+// price drop in @drops. The drop applies to the collateral and the debt in the asset. @positions are
+// the positions of all accounts and @markets the price and liquidation threshold per asset address.
+// The positions are valued once, as the values of an account are linear in the price of @asset.
+func ComputeDefiCollateralAtRisk(positions []DefiPosition, markets map[string]DefiMarketRisk, asset string, drops []float64) ([]DefiCollateralAtRisk, error) {
+	market, ok := markets[asset]
+	if !ok {
+		return nil, fmt.Errorf("no price and liquidation threshold for asset %s", asset)
+	}
+	for _, drop := range drops {
+		if drop < 0 || drop > 1 {
+			return nil, fmt.Errorf("price drop %v is not in [0,1]", drop)
+		}
+	}
+
+	// Values of the accounts with collateral in @asset without and with the full value of @asset.
+	var without, with []defiAccountValue
+	withoutAsset := defiAccountValues(positions, markets, asset, 0)
+	for account, value := range defiAccountValues(positions, markets, asset, 1) {
+		if value.Collateral == 0 {
+			continue
+		}
+		without = append(without, withoutAsset[account])
+		with = append(with, value)
+	}
+
+	curve := make([]DefiCollateralAtRisk, len(drops))
+	for i, drop := range drops {
+		point := DefiCollateralAtRisk{PriceDrop: drop, Price: market.Price * (1 - drop)}
+		for j := range with {
+			value := defiAccountValue{
+				Collateral:            with[j].Collateral,
+				CollateralUSD:         without[j].CollateralUSD + (1-drop)*(with[j].CollateralUSD-without[j].CollateralUSD),
+				WeightedCollateralUSD: without[j].WeightedCollateralUSD + (1-drop)*(with[j].WeightedCollateralUSD-without[j].WeightedCollateralUSD),
+				DebtUSD:               without[j].DebtUSD + (1-drop)*(with[j].DebtUSD-without[j].DebtUSD),
+			}
+			if value.HealthFactor() >= 1 {
+				continue
+			}
+			point.Collateral += value.Collateral
+			point.CollateralUSD += value.CollateralUSD
+			point.DebtUSD += value.DebtUSD
+			point.NumAccounts++
+		}
+		curve[i] = point
+	}
+	return curve, nil
+}
+
+// ComputeDefiHealthFactors returns the distribution of the health factors of all borrowers with @positions.
+// @bounds are the ascending lower bounds of the buckets.
+func ComputeDefiHealthFactors(positions []DefiPosition, markets map[string]DefiMarketRisk, bounds []float64) []DefiHealthFactorBucket {
+	buckets := make([]DefiHealthFactorBucket, len(bounds))
+	for i, bound := range bounds {
+		buckets[i].MinHealthFactor = bound
+		if i+1 < len(bounds) {
+			buckets[i].MaxHealthFactor = bounds[i+1]
+		}
+	}
+	for _, value := range defiAccountValues(positions, markets, "", 1) {
+		if value.DebtUSD == 0 {
+			continue
+		}
+		i := sort.SearchFloat64s(bounds, value.HealthFactor())
+		if i == len(bounds) || bounds[i] != value.HealthFactor() {
+			i--
+		}
+		if i < 0 {
+			continue
+		}
+		buckets[i].NumAccounts++
+		buckets[i].CollateralUSD += value.CollateralUSD
+		buckets[i].DebtUSD += value.DebtUSD
+	}
+	return buckets
+}
+
+// UpdateDefiPositions applies @changes to the positions on @protocol in the order of their events.
+// Amounts are bounded below by zero.
+func (rdb *RelDB) UpdateDefiPositions(protocol string, changes []DefiPositionChange) error {
+	batch := &pgx.Batch{}
+	queueDefiPositionChanges(batch, protocol, changes)
+	return rdb.sendDefiBatch(batch)
+}
+
+// SetDefiPositionEvents applies @changes to the positions on @protocol, stores @liquidations and sets the state
+// @state of the indexer @scraperName in a single transaction, such that the additive changes are applied exactly
+// once per indexed block range.
+func (rdb *RelDB) SetDefiPositionEvents(protocol string, changes []DefiPositionChange, liquidations []DefiLiquidation, scraperName string, state ScraperState) error {
+	batch := &pgx.Batch{}
+	queueDefiPositionChanges(batch, protocol, changes)
+	queueDefiLiquidations(batch, liquidations)
+	return rdb.sendBatchWithScraperState(context.Background(), batch, scraperName, state)
+}
+
+// queueDefiPositionChanges queues the updates of the positions on @protocol by @changes in the order of their events.
+func queueDefiPositionChanges(batch *pgx.Batch, protocol string, changes []DefiPositionChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].BlockNumber != changes[j].BlockNumber {
+			return changes[i].BlockNumber < changes[j].BlockNumber
+		}
+		return changes[i].LogIndex < changes[j].LogIndex
+	})
+	query := fmt.Sprintf(`INSERT INTO %s (protocol,account,asset_address,asset_blockchain,asset_symbol,collateral,debt,update_time) VALUES ($1,$2,$3,$4,$5,GREATEST($6::double precision,0),GREATEST($7::double precision,0),$8)
+	ON CONFLICT (protocol,account,asset_address) DO UPDATE SET
+	collateral=CASE WHEN $9 THEN EXCLUDED.collateral ELSE GREATEST(%s.collateral+$6::double precision,0) END,
+	debt=CASE WHEN $10 THEN EXCLUDED.debt ELSE GREATEST(%s.debt+$7::double precision,0) END,
+	update_time=EXCLUDED.update_time`, defipositionTable, defipositionTable, defipositionTable)
+
+	for _, change := range changes {
+		batch.Queue(query, protocol, change.Account, change.Asset.Address, change.Asset.Blockchain, change.Asset.Symbol, change.Collateral, change.Debt, change.Time, change.SetCollateral, change.SetDebt)
+	}
+}
+
+// sendDefiBatch executes the queries in @batch.
+func (rdb *RelDB) sendDefiBatch(batch *pgx.Batch) error {
+	if batch.Len() == 0 {
+		return nil
+	}
+	results := rdb.postgresClient.SendBatch(context.Background(), batch)
+	defer results.Close()
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDefiBorrowerPositions returns all positions of the accounts with debt on @protocol.
+func (rdb *RelDB) GetDefiBorrowerPositions(protocol string) (positions []DefiPosition, err error) {
+	query := fmt.Sprintf(`SELECT account,asset_address,asset_blockchain,asset_symbol,collateral,debt,update_time FROM %s
+	WHERE protocol=$1 AND account IN (SELECT account FROM %s WHERE protocol=$1 AND debt>0)`, defipositionTable, defipositionTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, protocol)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		position := DefiPosition{Protocol: protocol}
+		err = rows.Scan(
+			&position.Account,
+			&position.Asset.Address,
+			&position.Asset.Blockchain,
+			&position.Asset.Symbol,
+			&position.Collateral,
+			&position.Debt,
+			&position.Time,
+		)
+		if err != nil {
+			return
+		}
+		positions = append(positions, position)
+	}
+	err = rows.Err()
+	return
+}
+
+// SetDefiLiquidations stores @liquidations. Liquidations which are already stored are skipped.
+func (rdb *RelDB) SetDefiLiquidations(liquidations []DefiLiquidation) error {
+	batch := &pgx.Batch{}
+	queueDefiLiquidations(batch, liquidations)
+	return rdb.sendDefiBatch(batch)
+}
+
+// queueDefiLiquidations queues the inserts of @liquidations. Known liquidations are skipped.
+func queueDefiLiquidations(batch *pgx.Batch, liquidations []DefiLiquidation) {
+	query := fmt.Sprintf(`INSERT INTO %s (protocol,borrower,liquidator,collateral_address,collateral_blockchain,collateral_symbol,collateral_amount,debt_address,debt_blockchain,debt_symbol,debt_amount,value_usd,block_number,tx_hash,log_index,liquidation_time)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16) ON CONFLICT (protocol,tx_hash,log_index) DO NOTHING`, defiliquidationTable)
+
+	for _, l := range liquidations {
+		batch.Queue(query,
+			l.Protocol,
+			l.Borrower,
+			l.Liquidator,
+			l.CollateralAsset.Address,
+			l.CollateralAsset.Blockchain,
+			l.CollateralAsset.Symbol,
+			l.CollateralAmount,
+			l.DebtAsset.Address,
+			l.DebtAsset.Blockchain,
+			l.DebtAsset.Symbol,
+			l.DebtAmount,
+			l.ValueUSD,
+			l.BlockNumber,
+			l.TxHash,
+			l.LogIndex,
+			l.Time,
+		)
+	}
+}
+
+// GetDefiLiquidations returns the liquidations on @protocol in the time range [@starttime, @endtime], oldest first.
+func (rdb *RelDB) GetDefiLiquidations(protocol string, starttime time.Time, endtime time.Time) (liquidations []DefiLiquidation, err error) {
+	query := fmt.Sprintf(`SELECT borrower,liquidator,collateral_address,collateral_blockchain,collateral_symbol,collateral_amount,debt_address,debt_blockchain,debt_symbol,debt_amount,value_usd,block_number,tx_hash,log_index,liquidation_time
+	FROM %s WHERE protocol=$1 AND liquidation_time>=$2 AND liquidation_time<=$3 ORDER BY liquidation_time,block_number,log_index`, defiliquidationTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, protocol, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l := DefiLiquidation{Protocol: protocol}
+		err = rows.Scan(
+			&l.Borrower,
+			&l.Liquidator,
+			&l.CollateralAsset.Address,
+			&l.CollateralAsset.Blockchain,
+			&l.CollateralAsset.Symbol,
+			&l.CollateralAmount,
+			&l.DebtAsset.Address,
+			&l.DebtAsset.Blockchain,
+			&l.DebtAsset.Symbol,
+			&l.DebtAmount,
+			&l.ValueUSD,
+			&l.BlockNumber,
+			&l.TxHash,
+			&l.LogIndex,
+			&l.Time,
+		)
+		if err != nil {
+			return
+		}
+		liquidations = append(liquidations, l)
+	}
+	err = rows.Err()
+	return
+}
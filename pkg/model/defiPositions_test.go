@@ -0,0 +1,101 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+var (
+	testDefiETH  = dia.Asset{Address: "0x0000000000000000000000000000000000000000", Blockchain: dia.ETHEREUM, Symbol: "ETH"}
+	testDefiUSDC = dia.Asset{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Blockchain: dia.ETHEREUM, Symbol: "USDC"}
+	testDefiWBTC = dia.Asset{Address: "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599", Blockchain: dia.ETHEREUM, Symbol: "WBTC"}
+
+	testDefiMarkets = map[string]DefiMarketRisk{
+		testDefiETH.Address:  {Price: 2000, LiquidationThreshold: 0.8},
+		testDefiUSDC.Address: {Price: 1, LiquidationThreshold: 0.9},
+	}
+)
+
+func testDefiPositions() []DefiPosition {
+	return []DefiPosition{
+		// Health factor 10*2000*0.8/10000 = 1.6.
+		{Account: "a", Asset: testDefiETH, Collateral: 10},
+		{Account: "a", Asset: testDefiUSDC, Debt: 10000},
+		// Health factor 1*2000*0.8/1500 = 1.0667.
+		{Account: "b", Asset: testDefiETH, Collateral: 1},
+		{Account: "b", Asset: testDefiUSDC, Debt: 1500},
+		// Borrows ETH against USDC: health factor 5000*0.9/(1*2000) = 2.25, which rises if ETH drops.
+		{Account: "c", Asset: testDefiUSDC, Collateral: 5000},
+		{Account: "c", Asset: testDefiETH, Debt: 1},
+		// Cannot be valued without a price of WBTC.
+		{Account: "d", Asset: testDefiETH, Collateral: 1},
+		{Account: "d", Asset: testDefiWBTC, Debt: 1},
+	}
+}
+
+func TestDefiHealthFactor(t *testing.T) {
+	positions := testDefiPositions()
+	for _, c := range []struct {
+		positions []DefiPosition
+		want      float64
+	}{
+		{positions[0:2], 1.6},
+		{positions[2:4], 1600.0 / 1500},
+		{positions[4:6], 2.25},
+		{positions[0:1], math.Inf(1)},
+	} {
+		hf, err := DefiHealthFactor(c.positions, testDefiMarkets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(hf-c.want) > 1e-9 && !(math.IsInf(hf, 1) && math.IsInf(c.want, 1)) {
+			t.Errorf("health factor was incorrect, got: %v, want: %v.", hf, c.want)
+		}
+	}
+	if _, err := DefiHealthFactor(positions[6:8], testDefiMarkets); err == nil {
+		t.Error("expected error for unknown market")
+	}
+}
+
+func TestComputeDefiCollateralAtRisk(t *testing.T) {
+	curve, err := ComputeDefiCollateralAtRisk(testDefiPositions(), testDefiMarkets, testDefiETH.Address, []float64{0, 0.1, 0.4, 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []DefiCollateralAtRisk{
+		{PriceDrop: 0, Price: 2000},
+		// b is liquidatable below a price of 1875.
+		{PriceDrop: 0.1, Price: 1800, Collateral: 1, CollateralUSD: 1800, DebtUSD: 1500, NumAccounts: 1},
+		// a is liquidatable below a price of 1250, c is never, as its debt drops with the price.
+		{PriceDrop: 0.4, Price: 1200, Collateral: 11, CollateralUSD: 13200, DebtUSD: 11500, NumAccounts: 2},
+		{PriceDrop: 0.9, Price: 200, Collateral: 11, CollateralUSD: 2200, DebtUSD: 11500, NumAccounts: 2},
+	} {
+		got := curve[i]
+		if math.Abs(got.Price-want.Price) > 1e-9 || got.Collateral != want.Collateral || math.Abs(got.CollateralUSD-want.CollateralUSD) > 1e-6 || got.DebtUSD != want.DebtUSD || got.NumAccounts != want.NumAccounts {
+			t.Errorf("curve at drop %v was incorrect, got: %+v, want: %+v.", want.PriceDrop, got, want)
+		}
+	}
+
+	if _, err := ComputeDefiCollateralAtRisk(testDefiPositions(), testDefiMarkets, testDefiWBTC.Address, []float64{0}); err == nil {
+		t.Error("expected error for unknown asset")
+	}
+	if _, err := ComputeDefiCollateralAtRisk(testDefiPositions(), testDefiMarkets, testDefiETH.Address, []float64{1.5}); err == nil {
+		t.Error("expected error for invalid price drop")
+	}
+}
+
+func TestComputeDefiHealthFactors(t *testing.T) {
+	buckets := ComputeDefiHealthFactors(testDefiPositions(), testDefiMarkets, []float64{0, 1, 1.5, 2})
+	for i, want := range []DefiHealthFactorBucket{
+		{MinHealthFactor: 0, MaxHealthFactor: 1},
+		{MinHealthFactor: 1, MaxHealthFactor: 1.5, NumAccounts: 1, CollateralUSD: 2000, DebtUSD: 1500},
+		{MinHealthFactor: 1.5, MaxHealthFactor: 2, NumAccounts: 1, CollateralUSD: 20000, DebtUSD: 10000},
+		{MinHealthFactor: 2, NumAccounts: 1, CollateralUSD: 5000, DebtUSD: 2000},
+	} {
+		if buckets[i] != want {
+			t.Errorf("bucket %d was incorrect, got: %+v, want: %+v.", i, buckets[i], want)
+		}
+	}
+}
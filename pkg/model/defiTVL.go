@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+)
+
+// DefiTVL is the value of an asset held by the contracts of a protocol on a blockchain.
+type DefiTVL struct {
+	Time       time.Time
+	Protocol   string
+	Blockchain string
+	Asset      dia.Asset
+	// Balance is the amount of the asset held, Price its DIA price in USD and ValueUSD their product.
+	Balance  float64
+	Price    float64
+	ValueUSD float64
+	// PriceSource is the source of the DIA price followed by the lookup it was found by, e.g. diadata.org/cache,
+	// and PriceTime the time it was computed at. Both are empty if the asset has no price, in which case it does
+	// not add to the TVL.
+	PriceSource string
+	PriceTime   time.Time
+}
+
+// DefiTVLTotal is the TVL of a protocol, either on a single blockchain or summed over all its blockchains.
+type DefiTVLTotal struct {
+	Time     time.Time
+	Protocol string
+	// Blockchain is empty for the total over all blockchains.
+	Blockchain string
+	ValueUSD   float64
+	// NumAssets is the number of assets held and NumUnpriced the number of those without a price.
+	NumAssets   int
+	NumUnpriced int
+}
+
+// Lookups of the prices of DefiTVL. Cached prices are preferred over the latest stored ones.
+const (
+	DefiTVLPriceCache  = "cache"
+	DefiTVLPriceStored = "stored"
+)
+
+const defiTVLColumns = "blockchain,symbol,address,decimals,balance,price,valueUSD,priceSource,priceTime"
+
+// SumDefiTVL returns the totals of the asset values @tvls of a protocol per time, oldest first.
+// If @byBlockchain is set, totals are computed per time and blockchain.
+func SumDefiTVL(tvls []DefiTVL, byBlockchain bool) []DefiTVLTotal {
+	type key struct {
+		time       int64
+		blockchain string
+	}
+	totals := make(map[key]*DefiTVLTotal)
+	for _, tvl := range tvls {
+		k := key{time: tvl.Time.UnixNano()}
+		if byBlockchain {
+			k.blockchain = tvl.Blockchain
+		}
+		total, ok := totals[k]
+		if !ok {
+			total = &DefiTVLTotal{Time: tvl.Time, Protocol: tvl.Protocol, Blockchain: k.blockchain}
+			totals[k] = total
+		}
+		total.ValueUSD += tvl.ValueUSD
+		total.NumAssets++
+		if tvl.PriceSource == "" {
+			total.NumUnpriced++
+		}
+	}
+	result := make([]DefiTVLTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Time.Equal(result[j].Time) {
+			return result[i].Time.Before(result[j].Time)
+		}
+		return result[i].Blockchain < result[j].Blockchain
+	})
+	return result
+}
+
+// SetDefiTVL stores the asset values @tvls.
+func (datastore *DB) SetDefiTVL(tvls []DefiTVL) error {
+	for _, tvl := range tvls {
+		tags := map[string]string{
+			"protocol":   tvl.Protocol,
+			"blockchain": tvl.Blockchain,
+			"address":    tvl.Asset.Address,
+		}
+		fields := map[string]interface{}{
+			"symbol":      tvl.Asset.Symbol,
+			"decimals":    int64(tvl.Asset.Decimals),
+			"balance":     tvl.Balance,
+			"price":       tvl.Price,
+			"valueUSD":    tvl.ValueUSD,
+			"priceSource": tvl.PriceSource,
+			"priceTime":   tvl.PriceTime.Unix(),
+		}
+		pt, err := clientInfluxdb.NewPoint(influxDbDefiTVLTable, tags, fields, tvl.Time)
+		if err != nil {
+			return err
+		}
+		datastore.addPoint(pt)
+	}
+	return datastore.WriteBatchInflux()
+}
+
+// GetDefiTVL returns the asset values of @protocol in the given time range, oldest first.
+// The values on all blockchains are returned for an empty @blockchain.
+func (datastore *DB) GetDefiTVL(protocol string, blockchain string, starttime time.Time, endtime time.Time) (tvls []DefiTVL, err error) {
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE time >= %d AND time <= %d AND protocol = '%s'", defiTVLColumns, influxDbDefiTVLTable, starttime.UnixNano(), endtime.UnixNano(), escapeInfluxString(protocol))
+	if blockchain != "" {
+		q += fmt.Sprintf(" AND blockchain = '%s'", escapeInfluxString(blockchain))
+	}
+	res, err := queryInfluxDB(datastore.influxClient, q+" ORDER BY time ASC")
+	if err != nil {
+		return
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return
+	}
+	for _, row := range res[0].Series[0].Values {
+		var tvl DefiTVL
+		if tvl, err = parseDefiTVL(row); err != nil {
+			return
+		}
+		tvl.Protocol = protocol
+		tvls = append(tvls, tvl)
+	}
+	return
+}
+
+// GetDefiTVLLatest returns the asset values of the latest update of @protocol before @endtime.
+// Updates older than a day before @endtime are not considered.
+func (datastore *DB) GetDefiTVLLatest(protocol string, blockchain string, endtime time.Time) ([]DefiTVL, error) {
+	tvls, err := datastore.GetDefiTVL(protocol, blockchain, endtime.AddDate(0, 0, -1), endtime)
+	if err != nil || len(tvls) == 0 {
+		return tvls, err
+	}
+	return LatestDefiTVL(tvls), nil
+}
+
+// LatestDefiTVL returns the asset values of the latest update per blockchain in @tvls, which are ordered by time.
+func LatestDefiTVL(tvls []DefiTVL) (latest []DefiTVL) {
+	latestTimes := make(map[string]time.Time)
+	for _, tvl := range tvls {
+		if tvl.Time.After(latestTimes[tvl.Blockchain]) {
+			latestTimes[tvl.Blockchain] = tvl.Time
+		}
+	}
+	for _, tvl := range tvls {
+		if tvl.Time.Equal(latestTimes[tvl.Blockchain]) {
+			latest = append(latest, tvl)
+		}
+	}
+	return
+}
+
+// parseDefiTVL parses a row of defiTVLColumns as retrieved from influx.
+func parseDefiTVL(row []interface{}) (tvl DefiTVL, err error) {
+	if len(row) != 10 {
+		err = fmt.Errorf("unexpected number of columns in tvl point: %d", len(row))
+		return
+	}
+	timestamp, ok := row[0].(string)
+	if !ok {
+		err = fmt.Errorf("unexpected time in tvl point: %v", row[0])
+		return
+	}
+	if tvl.Time, err = time.Parse(time.RFC3339, timestamp); err != nil {
+		return
+	}
+	tvl.Blockchain, _ = row[1].(string)
+	tvl.Asset.Symbol, _ = row[2].(string)
+	tvl.Asset.Address, _ = row[3].(string)
+	tvl.Asset.Blockchain = tvl.Blockchain
+	var decimals, priceTime float64
+	for i, field := range []*float64{&decimals, &tvl.Balance, &tvl.Price, &tvl.ValueUSD} {
+		if *field, err = defiFloat(row[i+4]); err != nil {
+			return
+		}
+	}
+	tvl.Asset.Decimals = uint8(decimals)
+	tvl.PriceSource, _ = row[8].(string)
+	if priceTime, err = defiFloat(row[9]); err != nil {
+		return
+	}
+	if tvl.PriceSource != "" {
+		tvl.PriceTime = time.Unix(int64(priceTime), 0).UTC()
+	}
+	return
+}
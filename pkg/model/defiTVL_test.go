@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestSumDefiTVL(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+	tvls := []DefiTVL{
+		{Time: t0, Protocol: "AAVEv2", Blockchain: "Ethereum", ValueUSD: 100, PriceSource: "diadata.org/cache"},
+		{Time: t0, Protocol: "AAVEv2", Blockchain: "Polygon", ValueUSD: 50, PriceSource: "diadata.org/stored"},
+		{Time: t0, Protocol: "AAVEv2", Blockchain: "Polygon"},
+		{Time: t1, Protocol: "AAVEv2", Blockchain: "Ethereum", ValueUSD: 120, PriceSource: "diadata.org/cache"},
+	}
+
+	total := SumDefiTVL(tvls, false)
+	wantTotal := []DefiTVLTotal{
+		{Time: t0, Protocol: "AAVEv2", ValueUSD: 150, NumAssets: 3, NumUnpriced: 1},
+		{Time: t1, Protocol: "AAVEv2", ValueUSD: 120, NumAssets: 1},
+	}
+	if !reflect.DeepEqual(total, wantTotal) {
+		t.Errorf("total tvl was incorrect, got: %v, want: %v.", total, wantTotal)
+	}
+
+	byBlockchain := SumDefiTVL(tvls, true)
+	wantByBlockchain := []DefiTVLTotal{
+		{Time: t0, Protocol: "AAVEv2", Blockchain: "Ethereum", ValueUSD: 100, NumAssets: 1},
+		{Time: t0, Protocol: "AAVEv2", Blockchain: "Polygon", ValueUSD: 50, NumAssets: 2, NumUnpriced: 1},
+		{Time: t1, Protocol: "AAVEv2", Blockchain: "Ethereum", ValueUSD: 120, NumAssets: 1},
+	}
+	if !reflect.DeepEqual(byBlockchain, wantByBlockchain) {
+		t.Errorf("tvl per blockchain was incorrect, got: %v, want: %v.", byBlockchain, wantByBlockchain)
+	}
+}
+
+func TestLatestDefiTVL(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+	tvls := []DefiTVL{
+		{Time: t0, Blockchain: "Ethereum", ValueUSD: 1},
+		{Time: t0, Blockchain: "Polygon", ValueUSD: 2},
+		{Time: t1, Blockchain: "Ethereum", ValueUSD: 3},
+		{Time: t1, Blockchain: "Ethereum", ValueUSD: 4},
+	}
+	latest := LatestDefiTVL(tvls)
+	want := []DefiTVL{tvls[1], tvls[2], tvls[3]}
+	if !reflect.DeepEqual(latest, want) {
+		t.Errorf("latest tvl was incorrect, got: %v, want: %v.", latest, want)
+	}
+}
+
+func TestParseDefiTVL(t *testing.T) {
+	row := []interface{}{
+		"2021-11-01T12:00:00Z", "Ethereum", "USDC", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		json.Number("6"), json.Number("1000.5"), json.Number("1.001"), json.Number("1001.5005"),
+		"diadata.org/cache", json.Number("1635768000"),
+	}
+	tvl, err := parseDefiTVL(row)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := DefiTVL{
+		Time:        time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC),
+		Blockchain:  "Ethereum",
+		Asset:       dia.Asset{Symbol: "USDC", Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Blockchain: "Ethereum", Decimals: 6},
+		Balance:     1000.5,
+		Price:       1.001,
+		ValueUSD:    1001.5005,
+		PriceSource: "diadata.org/cache",
+		PriceTime:   time.Unix(1635768000, 0).UTC(),
+	}
+	if !reflect.DeepEqual(tvl, want) {
+		t.Errorf("parsed tvl was incorrect, got: %v, want: %v.", tvl, want)
+	}
+
+	row[8] = ""
+	if tvl, err = parseDefiTVL(row); err != nil || !tvl.PriceTime.IsZero() {
+		t.Errorf("price time of unpriced tvl was incorrect, got: %v, want zero.", tvl.PriceTime)
+	}
+}
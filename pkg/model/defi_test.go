@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestParseDefiRate(t *testing.T) {
+	timestamp := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := []interface{}{
+		timestamp.Format(time.RFC3339), "USDC", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "Ethereum",
+		json.Number("2.5"), json.Number("4.1"), json.Number("0"), json.Number("4.1"),
+		json.Number("1000"), json.Number("800"), json.Number("200"), json.Number("12"),
+		json.Number("0.8"), json.Number("0.075"), json.Number("0.75"), json.Number("0.75"),
+	}
+	want := dia.DefiRate{
+		Timestamp:            timestamp,
+		Asset:                "USDC",
+		AssetAddress:         "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		AssetBlockchain:      "Ethereum",
+		LendingRate:          2.5,
+		BorrowingRate:        4.1,
+		VariableBorrowRate:   4.1,
+		TotalSupplied:        1000,
+		TotalBorrowed:        800,
+		AvailableLiquidity:   200,
+		TotalReserves:        12,
+		Utilisation:          0.8,
+		ReserveFactor:        0.075,
+		CollateralFactor:     0.75,
+		LiquidationThreshold: 0.75,
+	}
+	rate, err := parseDefiRate(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != want {
+		t.Errorf("rate was incorrect, got: %+v, want: %+v.", rate, want)
+	}
+
+	// Points written before the market fields were introduced only carry the rates.
+	legacy := []interface{}{timestamp.Format(time.RFC3339), "DAI", nil, nil, json.Number("1.5"), json.Number("3"), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}
+	rate, err = parseDefiRate(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate.Asset != "DAI" || rate.LendingRate != 1.5 || rate.BorrowingRate != 3 || rate.TotalSupplied != 0 || rate.AssetAddress != "" {
+		t.Errorf("legacy rate was incorrect, got: %+v.", rate)
+	}
+
+	if _, err := parseDefiRate(row[:4]); err == nil {
+		t.Error("expected error for missing columns")
+	}
+	invalid := append([]interface{}{}, row...)
+	invalid[5] = "4.1"
+	if _, err := parseDefiRate(invalid); err == nil {
+		t.Error("expected error for invalid field")
+	}
+}
+
+func TestParseDefiState(t *testing.T) {
+	timestamp := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		row  []interface{}
+		want dia.DefiProtocolState
+	}{
+		{
+			[]interface{}{timestamp.Format(time.RFC3339), json.Number("10"), json.Number("37000"), json.Number("12000")},
+			dia.DefiProtocolState{Timestamp: timestamp, TotalETH: 10, TotalUSD: 37000, TotalBorrowUSD: 12000},
+		},
+		{
+			[]interface{}{timestamp.Format(time.RFC3339), json.Number("10"), json.Number("37000"), nil},
+			dia.DefiProtocolState{Timestamp: timestamp, TotalETH: 10, TotalUSD: 37000},
+		},
+	} {
+		state, err := parseDefiState(c.row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != c.want {
+			t.Errorf("state was incorrect, got: %+v, want: %+v.", state, c.want)
+		}
+	}
+}
+
+func TestDefiUtilisation(t *testing.T) {
+	for _, c := range []struct {
+		borrowed, supplied, want float64
+	}{
+		{800, 1000, 0.8},
+		{0, 1000, 0},
+		{0, 0, 0},
+	} {
+		if got := dia.DefiUtilisation(c.borrowed, c.supplied); got != c.want {
+			t.Errorf("utilisation of %v/%v was incorrect, got: %v, want: %v.", c.borrowed, c.supplied, got, c.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 )
 
@@ -17,6 +18,19 @@ type FarmingPool struct {
 	// OutputAsset is the list of tokens that you get back for staking.
 	OutputAsset []string
 	InputAsset  []string // some pools have more than 2 input assets
+	// BaseAPR is the yearly yield in per cent from the growth of the value of a pool share, RewardAPR the
+	// yearly yield in per cent from reward emissions and APY the compounded yield of both.
+	// The yield fields are zero if they cannot be determined for a pool, see FarmingPoolMethodology.
+	BaseAPR   float64
+	RewardAPR float64
+	APY       float64
+	// TVL is the value of the staked assets in USD.
+	TVL float64
+	// RewardAsset is the symbol of the reward token and RewardPrice its DIA price in USD.
+	RewardAsset string
+	RewardPrice float64
+	// Methodology describes how the yield of the pools of the protocol is computed. It is not stored.
+	Methodology string `json:",omitempty"`
 }
 
 type FarmingPoolType struct {
@@ -35,3 +49,54 @@ func (fp *FarmingPool) UnmarshalBinary(data []byte) error {
 func (fp *FarmingPool) MarshalBinary() ([]byte, error) {
 	return json.Marshal(fp)
 }
+
+// farmingPoolMethodologies document how the yield of the pools of a protocol is derived from their raw Rate and Balance.
+var farmingPoolMethodologies = map[string]string{
+	"BALANCER": "Rate is the value of the pool invariant per pool token. BaseAPR is the growth of Rate from the latest state at least a day before, annualised without compounding. " +
+		"BAL liquidity mining rewards are not covered, so APY is not computed. TVL is not computed.",
+	"CURVEFI": "Rate is the virtual price of the pool token. BaseAPR is the growth of Rate from the latest state at least a day before, annualised without compounding. " +
+		"TVL is Balance times Rate valued with the DIA price of the input assets if they are pegged to each other, i.e. all prices are within 2% of their mean, and is not computed otherwise. " +
+		"CRV gauge rewards are not covered, so APY is not computed.",
+	"YFI": "Rate is the price per vault share minus 1. BaseAPR and APY are the growth of the price per share from the latest state at least a day before, annualised without and with compounding. " +
+		"TVL is Balance, the vault's holdings in units of its token, valued with the DIA price of the token. The vaults pay no rewards.",
+	"BARNBRIDGE": "Rate is the BOND reward per weekly epoch and Balance the staked amount. RewardAPR is Rate times 52 valued with the DIA price of BOND over TVL, which is Balance valued with the DIA price of the input assets " +
+		"if they are pegged to each other. RewardAPR is not computed for pools of input assets of different value. APY assumes daily compounding of the rewards. " +
+		"BarnBridge is the only protocol whose rewards are included in the yield.",
+	"CVAULT":    "Rate is the accumulated reward per pool token, which cannot be valued without a price of the pool token. The yield is not computed.",
+	"LOOPRING":  "Rate is the remaining reward in the fee vault, which holds claimable fees rather than an emission rate. The yield is not computed.",
+	"SYNTHETIX": "Rate is the total system debt and Balance the supply of the synth, which do not determine a yield. The yield is not computed.",
+}
+
+// FarmingPoolMethodology returns how the yield of the pools of @protocol is computed.
+func FarmingPoolMethodology(protocol string) string {
+	if methodology, ok := farmingPoolMethodologies[protocol]; ok {
+		return methodology
+	}
+	return "The yield is not computed for pools of this protocol."
+}
+
+// SharePriceYield returns the yearly yield in per cent of a share whose value grew from @previous to @current
+// in @elapsed, without (apr) and with compounding (apy).
+func SharePriceYield(previous, current float64, elapsed time.Duration) (apr float64, apy float64) {
+	if previous <= 0 || current <= 0 || elapsed <= 0 {
+		return 0, 0
+	}
+	years := elapsed.Hours() / (365 * 24)
+	growth := current / previous
+	return (growth - 1) / years * 100, (math.Pow(growth, 1/years) - 1) * 100
+}
+
+// RewardAPR returns the yearly yield in per cent of @rewardsPerYear reward tokens priced at @rewardPrice
+// on a stake with value @tvl.
+func RewardAPR(rewardsPerYear, rewardPrice, tvl float64) float64 {
+	if tvl <= 0 {
+		return 0
+	}
+	return rewardsPerYear * rewardPrice / tvl * 100
+}
+
+// FarmingPoolAPY returns the compounded yearly yield in per cent of a pool with a compounded base yield @baseAPY
+// whose rewards with yield @rewardAPR are reinvested daily. Both are in per cent.
+func FarmingPoolAPY(baseAPY, rewardAPR float64) float64 {
+	return ((1+baseAPY/100)*math.Pow(1+rewardAPR/100/365, 365) - 1) * 100
+}
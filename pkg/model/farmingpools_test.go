@@ -0,0 +1,52 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSharePriceYield(t *testing.T) {
+	for _, c := range []struct {
+		previous, current float64
+		elapsed           time.Duration
+		wantAPR, wantAPY  float64
+	}{
+		// 1% growth in half a year.
+		{1, 1.01, 365 * 12 * time.Hour, 2, 2.01},
+		{1.2, 1.2, 24 * time.Hour, 0, 0},
+		{0, 1.01, 24 * time.Hour, 0, 0},
+		{1, 1.01, 0, 0, 0},
+	} {
+		apr, apy := SharePriceYield(c.previous, c.current, c.elapsed)
+		if math.Abs(apr-c.wantAPR) > 1e-9 || math.Abs(apy-c.wantAPY) > 1e-9 {
+			t.Errorf("yield of %v to %v in %v was incorrect, got: %v, %v, want: %v, %v.", c.previous, c.current, c.elapsed, apr, apy, c.wantAPR, c.wantAPY)
+		}
+	}
+}
+
+func TestRewardAPR(t *testing.T) {
+	// 52 weekly epochs of 1000 BOND at 25 USD on 1.3M USD staked.
+	if got := RewardAPR(52000, 25, 1.3e6); math.Abs(got-100) > 1e-9 {
+		t.Errorf("reward apr was incorrect, got: %v, want: %v.", got, 100)
+	}
+	if got := RewardAPR(52000, 25, 0); got != 0 {
+		t.Errorf("reward apr without tvl was incorrect, got: %v, want: %v.", got, 0)
+	}
+}
+
+func TestFarmingPoolAPY(t *testing.T) {
+	for _, c := range []struct {
+		baseAPY, rewardAPR float64
+		want               float64
+	}{
+		{0, 0, 0},
+		{5, 0, 5},
+		{0, 36.5, (math.Pow(1.001, 365) - 1) * 100},
+		{10, 36.5, (1.1*math.Pow(1.001, 365) - 1) * 100},
+	} {
+		if got := FarmingPoolAPY(c.baseAPY, c.rewardAPR); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("apy of %v and %v was incorrect, got: %v, want: %v.", c.baseAPY, c.rewardAPR, got, c.want)
+		}
+	}
+}
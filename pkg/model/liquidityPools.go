@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	"github.com/jackc/pgx/v4"
+)
+
+// SetLiquidityPool stores the state of an AMM pool. The assets of the pool along with their reserves are stored
+// as json.
+func (datastore *DB) SetLiquidityPool(pool *dia.Pool) error {
+	assets, err := json.Marshal(pool.Assets)
+	if err != nil {
+		return err
+	}
+	tags := map[string]string{
+		"exchange":   pool.Exchange,
+		"blockchain": pool.Blockchain,
+		"address":    pool.Address,
+	}
+	fields := map[string]interface{}{
+		"type":          string(pool.Type),
+		"assets":        string(assets),
+		"lpToken":       pool.LPToken.Address,
+		"lpSymbol":      pool.LPToken.Symbol,
+		"totalSupply":   pool.TotalSupply,
+		"fee":           pool.Fee,
+		"amplification": pool.Amplification,
+		"blockNumber":   int64(pool.BlockNumber),
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbLiquidityPoolTable, tags, fields, pool.Time)
+	if err != nil {
+		return err
+	}
+	datastore.addPoint(pt)
+	return datastore.WriteBatchInflux()
+}
+
+// SetLPToken registers the LP token of @pool in the asset table, such that its quotation is served like the
+// quotation of any other asset. LP tokens that are registered already are left unchanged.
+func (rdb *RelDB) SetLPToken(pool *dia.Pool) error {
+	_, err := rdb.GetAsset(pool.LPToken.Address, pool.LPToken.Blockchain)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	return rdb.SetAsset(pool.LPToken)
+}
+
+// AssetQuotationStore reads and stores the quotations of assets.
+type AssetQuotationStore interface {
+	GetAssetQuotationLatest(asset dia.Asset) (*AssetQuotation, error)
+	SetAssetQuotation(quotation *AssetQuotation) error
+}
+
+// SetLPTokenQuotation stores the fair price of the LP token of @pool as its quotation in @store and returns it.
+// The assets of the pool are valued with their latest DIA prices, so the LP token is only priced if all assets are.
+func SetLPTokenQuotation(store AssetQuotationStore, pool *dia.Pool) (*AssetQuotation, error) {
+	prices := make([]float64, len(pool.Assets))
+	for i, asset := range pool.Assets {
+		quotation, err := store.GetAssetQuotationLatest(asset.Asset)
+		if err != nil {
+			return nil, fmt.Errorf("price of %s: %w", asset.Asset.Symbol, err)
+		}
+		prices[i] = quotation.Price
+	}
+	price, err := pool.FairLPTokenPrice(prices)
+	if err != nil {
+		return nil, err
+	}
+	quotation := &AssetQuotation{
+		Asset:  pool.LPToken,
+		Price:  price,
+		Source: dia.Diadata,
+		Time:   pool.Time,
+	}
+	if err := store.SetAssetQuotation(quotation); err != nil {
+		return nil, err
+	}
+	return quotation, nil
+}
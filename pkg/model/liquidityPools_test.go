@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+type testAssetQuotationStore struct {
+	quotations map[string]*AssetQuotation
+}
+
+func (s *testAssetQuotationStore) GetAssetQuotationLatest(asset dia.Asset) (*AssetQuotation, error) {
+	quotation, ok := s.quotations[asset.Address]
+	if !ok {
+		return nil, errors.New("no quotation")
+	}
+	return quotation, nil
+}
+
+func (s *testAssetQuotationStore) SetAssetQuotation(quotation *AssetQuotation) error {
+	s.quotations[quotation.Asset.Address] = quotation
+	return nil
+}
+
+func TestSetLPTokenQuotation(t *testing.T) {
+	weth := dia.Asset{Symbol: "WETH", Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", Blockchain: dia.ETHEREUM}
+	usdc := dia.Asset{Symbol: "USDC", Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Blockchain: dia.ETHEREUM}
+	timestamp := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	pool := &dia.Pool{
+		Exchange: dia.UniswapExchange,
+		Type:     dia.ConstantProductPool,
+		Address:  "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc",
+		// The reserves are skewed by a swap, the fair value of 4e6 is that of the balanced pool.
+		Assets:      []dia.PoolAsset{{Asset: weth, Reserve: 2000}, {Asset: usdc, Reserve: 1e6}},
+		LPToken:     dia.Asset{Symbol: "UNI-V2", Address: "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc", Blockchain: dia.ETHEREUM},
+		TotalSupply: 1000,
+		Time:        timestamp,
+	}
+	store := &testAssetQuotationStore{quotations: map[string]*AssetQuotation{
+		weth.Address: {Asset: weth, Price: 2000},
+	}}
+
+	// Without a price of every asset, the LP token is not priced.
+	if _, err := SetLPTokenQuotation(store, pool); err == nil || store.quotations[pool.LPToken.Address] != nil {
+		t.Errorf("LP token was priced without price of USDC, got: %v.", err)
+	}
+
+	store.quotations[usdc.Address] = &AssetQuotation{Asset: usdc, Price: 1}
+	quotation, err := SetLPTokenQuotation(store, pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := store.quotations[pool.LPToken.Address]
+	if stored != quotation || math.Abs(quotation.Price-4000) > 1e-6 || quotation.Source != dia.Diadata || !quotation.Time.Equal(timestamp) {
+		t.Errorf("quotation was incorrect, got: %+v.", quotation)
+	}
+}
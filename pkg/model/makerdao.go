@@ -0,0 +1,301 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+)
+
+// MakerDAOIlk is the state of a collateral type (ilk) of MakerDAO. Amounts are in DAI.
+type MakerDAOIlk struct {
+	Time time.Time
+	Ilk  string
+	// Debt is the DAI drawn against the ilk, DebtCeiling the maximum debt of the ilk and
+	// DebtCeilingUsage the fraction of it in use. DebtFloor is the minimum debt of a vault.
+	Debt             float64
+	DebtCeiling      float64
+	DebtCeilingUsage float64
+	DebtFloor        float64
+	// StabilityFee is the yearly borrow rate in per cent.
+	StabilityFee float64
+	// LiquidationRatio is the minimum collateralisation of a vault, e.g. 1.5 for 150 per cent.
+	LiquidationRatio float64
+	// Price is the price of the collateral in USD as used by MakerDAO for liquidations.
+	Price float64
+}
+
+// MakerDAOSavings is the state of the DAI savings rate (DSR) and the total debt of MakerDAO.
+type MakerDAOSavings struct {
+	Time time.Time
+	// DSR is the yearly savings rate in per cent, Chi its accumulator.
+	DSR float64
+	Chi float64
+	// TotalSavings is the DAI deposited in the DSR.
+	TotalSavings float64
+	// TotalDebt is the DAI drawn against all ilks, DebtCeiling the global debt ceiling.
+	TotalDebt   float64
+	DebtCeiling float64
+}
+
+// MakerDAOVault is a vault of an ilk. Collateral is in units of the collateral of the ilk, Debt in DAI.
+type MakerDAOVault struct {
+	ID         uint64
+	Ilk        string
+	Urn        string
+	Collateral float64
+	Debt       float64
+}
+
+// MakerDAOVaultBucket aggregates the vaults with a collateralisation in [MinCollateralisation, MaxCollateralisation).
+// MaxCollateralisation is zero for the last, unbounded bucket.
+type MakerDAOVaultBucket struct {
+	MinCollateralisation float64
+	MaxCollateralisation float64
+	NumVaults            int
+	Collateral           float64
+	Debt                 float64
+}
+
+// MakerDAOVaultStats are the statistics of the vaults with debt of an ilk.
+type MakerDAOVaultStats struct {
+	Time       time.Time
+	Ilk        string
+	NumVaults  int
+	Collateral float64
+	Debt       float64
+	// Collateralisation is the value of the collateral of all vaults over their debt.
+	// NumUnsafe is the number of vaults below the liquidation ratio, which can be liquidated.
+	Collateralisation float64
+	NumUnsafe         int
+	Distribution      []MakerDAOVaultBucket
+}
+
+// MakerDAOCollateralisationBounds are the default lower bounds of the buckets of the vault distribution.
+var MakerDAOCollateralisationBounds = []float64{0, 1, 1.25, 1.5, 1.75, 2, 2.5, 3, 5}
+
+const (
+	makerdaoIlkColumns     = "ilk,debt,debtCeiling,debtCeilingUsage,debtFloor,stabilityFee,liquidationRatio,price"
+	makerdaoSavingsColumns = "dsr,chi,totalSavings,totalDebt,debtCeiling"
+	makerdaoVaultsColumns  = "ilk,numVaults,collateral,debt,collateralisation,numUnsafe,distribution"
+)
+
+// ComputeMakerDAOVaultStats returns the statistics of the vaults of @ilk with debt, where the collateral is
+// valued with the price of @ilk. @bounds are the ascending lower bounds of the buckets of the distribution.
+// Vaults of other ilks are ignored.
+func ComputeMakerDAOVaultStats(ilk MakerDAOIlk, vaults []MakerDAOVault, bounds []float64) MakerDAOVaultStats {
+	stats := MakerDAOVaultStats{
+		Time:         ilk.Time,
+		Ilk:          ilk.Ilk,
+		Distribution: make([]MakerDAOVaultBucket, len(bounds)),
+	}
+	for i, bound := range bounds {
+		stats.Distribution[i].MinCollateralisation = bound
+		if i+1 < len(bounds) {
+			stats.Distribution[i].MaxCollateralisation = bounds[i+1]
+		}
+	}
+	for _, vault := range vaults {
+		if vault.Ilk != ilk.Ilk || vault.Debt == 0 {
+			continue
+		}
+		stats.NumVaults++
+		stats.Collateral += vault.Collateral
+		stats.Debt += vault.Debt
+
+		collateralisation := vault.Collateral * ilk.Price / vault.Debt
+		if collateralisation < ilk.LiquidationRatio {
+			stats.NumUnsafe++
+		}
+		i := sort.SearchFloat64s(bounds, collateralisation)
+		if i == len(bounds) || bounds[i] != collateralisation {
+			i--
+		}
+		if i < 0 {
+			continue
+		}
+		stats.Distribution[i].NumVaults++
+		stats.Distribution[i].Collateral += vault.Collateral
+		stats.Distribution[i].Debt += vault.Debt
+	}
+	if stats.Debt > 0 {
+		stats.Collateralisation = stats.Collateral * ilk.Price / stats.Debt
+	}
+	return stats
+}
+
+// SetMakerDAOIlk stores the state of an ilk.
+func (datastore *DB) SetMakerDAOIlk(ilk MakerDAOIlk) error {
+	fields := map[string]interface{}{
+		"debt":             ilk.Debt,
+		"debtCeiling":      ilk.DebtCeiling,
+		"debtCeilingUsage": ilk.DebtCeilingUsage,
+		"debtFloor":        ilk.DebtFloor,
+		"stabilityFee":     ilk.StabilityFee,
+		"liquidationRatio": ilk.LiquidationRatio,
+		"price":            ilk.Price,
+	}
+	return datastore.setMakerDAOPoint(influxDbMakerDAOIlkTable, map[string]string{"ilk": ilk.Ilk}, fields, ilk.Time)
+}
+
+// GetMakerDAOIlks returns the states of @ilk in the given time range, oldest first.
+// The states of all ilks are returned for an empty @ilk.
+func (datastore *DB) GetMakerDAOIlks(ilk string, starttime time.Time, endtime time.Time) (ilks []MakerDAOIlk, err error) {
+	rows, err := datastore.queryMakerDAO(makerdaoIlkColumns, influxDbMakerDAOIlkTable, ilk, starttime, endtime)
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		var state MakerDAOIlk
+		if state.Time, err = makerdaoTime(row, 9); err != nil {
+			return
+		}
+		state.Ilk, _ = row[1].(string)
+		for i, field := range []*float64{
+			&state.Debt,
+			&state.DebtCeiling,
+			&state.DebtCeilingUsage,
+			&state.DebtFloor,
+			&state.StabilityFee,
+			&state.LiquidationRatio,
+			&state.Price,
+		} {
+			if *field, err = defiFloat(row[i+2]); err != nil {
+				return
+			}
+		}
+		ilks = append(ilks, state)
+	}
+	return
+}
+
+// SetMakerDAOSavings stores the state of the DSR.
+func (datastore *DB) SetMakerDAOSavings(savings MakerDAOSavings) error {
+	fields := map[string]interface{}{
+		"dsr":          savings.DSR,
+		"chi":          savings.Chi,
+		"totalSavings": savings.TotalSavings,
+		"totalDebt":    savings.TotalDebt,
+		"debtCeiling":  savings.DebtCeiling,
+	}
+	return datastore.setMakerDAOPoint(influxDbMakerDAOSavingsTable, nil, fields, savings.Time)
+}
+
+// GetMakerDAOSavings returns the states of the DSR in the given time range, oldest first.
+func (datastore *DB) GetMakerDAOSavings(starttime time.Time, endtime time.Time) (savings []MakerDAOSavings, err error) {
+	rows, err := datastore.queryMakerDAO(makerdaoSavingsColumns, influxDbMakerDAOSavingsTable, "", starttime, endtime)
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		var state MakerDAOSavings
+		if state.Time, err = makerdaoTime(row, 6); err != nil {
+			return
+		}
+		for i, field := range []*float64{
+			&state.DSR,
+			&state.Chi,
+			&state.TotalSavings,
+			&state.TotalDebt,
+			&state.DebtCeiling,
+		} {
+			if *field, err = defiFloat(row[i+1]); err != nil {
+				return
+			}
+		}
+		savings = append(savings, state)
+	}
+	return
+}
+
+// SetMakerDAOVaultStats stores the statistics of the vaults of an ilk.
+func (datastore *DB) SetMakerDAOVaultStats(stats MakerDAOVaultStats) error {
+	distribution, err := json.Marshal(stats.Distribution)
+	if err != nil {
+		return err
+	}
+	fields := map[string]interface{}{
+		"numVaults":         stats.NumVaults,
+		"collateral":        stats.Collateral,
+		"debt":              stats.Debt,
+		"collateralisation": stats.Collateralisation,
+		"numUnsafe":         stats.NumUnsafe,
+		"distribution":      string(distribution),
+	}
+	return datastore.setMakerDAOPoint(influxDbMakerDAOVaultsTable, map[string]string{"ilk": stats.Ilk}, fields, stats.Time)
+}
+
+// GetMakerDAOVaultStats returns the statistics of the vaults of @ilk in the given time range, oldest first.
+func (datastore *DB) GetMakerDAOVaultStats(ilk string, starttime time.Time, endtime time.Time) (stats []MakerDAOVaultStats, err error) {
+	rows, err := datastore.queryMakerDAO(makerdaoVaultsColumns, influxDbMakerDAOVaultsTable, ilk, starttime, endtime)
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		var s MakerDAOVaultStats
+		s, err = parseMakerDAOVaultStats(row)
+		if err != nil {
+			return
+		}
+		stats = append(stats, s)
+	}
+	return
+}
+
+// parseMakerDAOVaultStats parses a row of makerdaoVaultsColumns as retrieved from influx.
+func parseMakerDAOVaultStats(row []interface{}) (stats MakerDAOVaultStats, err error) {
+	if stats.Time, err = makerdaoTime(row, 8); err != nil {
+		return
+	}
+	stats.Ilk, _ = row[1].(string)
+	var numVaults, numUnsafe float64
+	for i, field := range []*float64{&numVaults, &stats.Collateral, &stats.Debt, &stats.Collateralisation, &numUnsafe} {
+		if *field, err = defiFloat(row[i+2]); err != nil {
+			return
+		}
+	}
+	stats.NumVaults, stats.NumUnsafe = int(numVaults), int(numUnsafe)
+	if distribution, ok := row[7].(string); ok && distribution != "" {
+		err = json.Unmarshal([]byte(distribution), &stats.Distribution)
+	}
+	return
+}
+
+func (datastore *DB) setMakerDAOPoint(table string, tags map[string]string, fields map[string]interface{}, timestamp time.Time) error {
+	pt, err := clientInfluxdb.NewPoint(table, tags, fields, timestamp)
+	if err != nil {
+		return err
+	}
+	datastore.addPoint(pt)
+	return datastore.WriteBatchInflux()
+}
+
+// queryMakerDAO returns the rows of @columns in @table in the given time range, restricted to @ilk if not empty.
+func (datastore *DB) queryMakerDAO(columns string, table string, ilk string, starttime time.Time, endtime time.Time) ([][]interface{}, error) {
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE time >= %d AND time <= %d", columns, table, starttime.UnixNano(), endtime.UnixNano())
+	if ilk != "" {
+		q += fmt.Sprintf(" AND ilk = '%s'", escapeInfluxString(ilk))
+	}
+	res, err := queryInfluxDB(datastore.influxClient, q+" ORDER BY time ASC")
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return nil, nil
+	}
+	return res[0].Series[0].Values, nil
+}
+
+// makerdaoTime returns the time of a row with @numColumns columns as retrieved from influx.
+func makerdaoTime(row []interface{}, numColumns int) (time.Time, error) {
+	if len(row) != numColumns {
+		return time.Time{}, fmt.Errorf("unexpected number of columns in makerdao point: %d", len(row))
+	}
+	timestamp, ok := row[0].(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected time in makerdao point: %v", row[0])
+	}
+	return time.Parse(time.RFC3339, timestamp)
+}
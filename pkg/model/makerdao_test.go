@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestComputeMakerDAOVaultStats(t *testing.T) {
+	ilk := MakerDAOIlk{Ilk: "ETH-A", Price: 2000, LiquidationRatio: 1.5}
+	vaults := []MakerDAOVault{
+		// Collateralisation 1*2000/1500 = 1.333, can be liquidated.
+		{ID: 1, Ilk: "ETH-A", Collateral: 1, Debt: 1500},
+		// Collateralisation exactly on a bound falls into the bucket above.
+		{ID: 2, Ilk: "ETH-A", Collateral: 3, Debt: 3000},
+		{ID: 3, Ilk: "ETH-A", Collateral: 10, Debt: 2000},
+		// Vaults without debt and of other ilks are ignored.
+		{ID: 4, Ilk: "ETH-A", Collateral: 5},
+		{ID: 5, Ilk: "WBTC-A", Collateral: 1, Debt: 10000},
+	}
+	stats := ComputeMakerDAOVaultStats(ilk, vaults, []float64{0, 1.5, 2})
+
+	if stats.NumVaults != 3 || stats.Collateral != 14 || stats.Debt != 6500 || stats.NumUnsafe != 1 {
+		t.Errorf("stats were incorrect, got: %+v.", stats)
+	}
+	if want := 14.0 * 2000 / 6500; math.Abs(stats.Collateralisation-want) > 1e-9 {
+		t.Errorf("collateralisation was incorrect, got: %v, want: %v.", stats.Collateralisation, want)
+	}
+	for i, want := range []MakerDAOVaultBucket{
+		{MinCollateralisation: 0, MaxCollateralisation: 1.5, NumVaults: 1, Collateral: 1, Debt: 1500},
+		{MinCollateralisation: 1.5, MaxCollateralisation: 2},
+		{MinCollateralisation: 2, NumVaults: 2, Collateral: 13, Debt: 5000},
+	} {
+		if stats.Distribution[i] != want {
+			t.Errorf("bucket %d was incorrect, got: %+v, want: %+v.", i, stats.Distribution[i], want)
+		}
+	}
+}
+
+func TestParseMakerDAOVaultStats(t *testing.T) {
+	want := MakerDAOVaultStats{
+		Ilk:               "ETH-A",
+		NumVaults:         3,
+		Collateral:        14,
+		Debt:              6500,
+		Collateralisation: 4.3,
+		NumUnsafe:         1,
+		Distribution:      []MakerDAOVaultBucket{{MaxCollateralisation: 1.5, NumVaults: 1, Collateral: 1, Debt: 1500}},
+	}
+	distribution, err := json.Marshal(want.Distribution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := []interface{}{"2021-11-01T12:00:00Z", "ETH-A", json.Number("3"), json.Number("14"), json.Number("6500"), json.Number("4.3"), json.Number("1"), string(distribution)}
+	got, err := parseMakerDAOVaultStats(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Time = got.Time
+	if !reflect.DeepEqual(got, want) || got.Time.Unix() != 1635768000 {
+		t.Errorf("stats were incorrect, got: %+v, want: %+v.", got, want)
+	}
+
+	if _, err := parseMakerDAOVaultStats(row[:7]); err == nil {
+		t.Error("expected error for missing column")
+	}
+}
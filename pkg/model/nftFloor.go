@@ -0,0 +1,409 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+const (
+	// DefaultNFTFloorQuantile is the quantile of the normalised sale prices used as floor price.
+	DefaultNFTFloorQuantile = 0.1
+	// nftFloorSampleHalfSaturation is the number of sales at which the sample size contributes 0.5 to the confidence score.
+	nftFloorSampleHalfSaturation = 5
+	// nftDefaultDecimals is assumed for sales without currency, which are sales in the native 18 decimals currency.
+	nftDefaultDecimals = 18
+)
+
+var (
+	// ErrNoNFTFloor is returned if there are no sales to compute a floor price from.
+	ErrNoNFTFloor = errors.New("no result in given time-range")
+	// ErrNFTFloorCurrencies is returned if floor prices in different currencies cannot be converted to one currency.
+	ErrNFTFloorCurrencies = errors.New("floor prices in different currencies")
+)
+
+// NFTFloorConfidence describes the data a floor price is computed from.
+type NFTFloorConfidence struct {
+	// Score in [0,1] is the product of a sample size factor n/(n+5) and the ratio of floor to median price.
+	Score    float64
+	Quantile float64
+	// NumTrades is the number of sales in the window, NumUsed the number of sales the floor is computed from.
+	NumTrades      int
+	NumUsed        int
+	NumWashTrades  int
+	NumZeroPrice   int
+	NumUnconverted int
+	// NumBundleShares is the number of sales of tokens sold in a bundle, whose prices are allocated shares of the bundle price.
+	NumBundleShares int
+	MinPrice        float64
+	MedianPrice     float64
+	WindowStart     time.Time
+	WindowEnd       time.Time
+}
+
+// NFTFloorEstimate is the floor price of an nft class in units of Currency, the currency most sales
+// in the window were made in. Sales in other currencies are converted using their USD prices.
+type NFTFloorEstimate struct {
+	Floor float64
+	// FloorUSD is zero if no USD price of Currency is known from the sales in the window.
+	FloorUSD   float64
+	Currency   dia.Asset
+	Time       time.Time
+	Confidence NFTFloorConfidence
+}
+
+// EstimateNFTFloor returns the floor price of @trades as the @quantile of the normalised prices of all sales
+// that are not wash trades. A sale is considered a wash trade if seller and buyer coincide, if it is part of a
+// round trip of a token back to a previous seller, or if seller and buyer belong to the same cluster of addresses
+// connected by round trips. Sales with zero price, such as private transfers, and sales of tokens in a bundle
+// are not taken into account.
+func EstimateNFTFloor(trades []dia.NFTTrade, quantile float64) (estimate NFTFloorEstimate, err error) {
+	if quantile < 0 || quantile > 1 {
+		err = fmt.Errorf("quantile %v not in [0,1]", quantile)
+		return
+	}
+	estimate.Confidence.Quantile = quantile
+	estimate.Confidence.NumTrades = len(trades)
+
+	wash := flagNFTWashTrades(trades)
+	valid := make([]bool, len(trades))
+	for i, trade := range trades {
+		if trade.Price == nil || trade.Price.Sign() <= 0 {
+			estimate.Confidence.NumZeroPrice++
+			continue
+		}
+		if wash[i] {
+			estimate.Confidence.NumWashTrades++
+			continue
+		}
+		if isNFTBundleShare(trade) {
+			estimate.Confidence.NumBundleShares++
+			continue
+		}
+		valid[i] = true
+	}
+
+	normaliser, ok := newNFTPriceNormaliser(trades, valid)
+	if !ok {
+		err = ErrNoNFTFloor
+		return
+	}
+	estimate.Currency = normaliser.currency
+
+	var prices []float64
+	for i, trade := range trades {
+		if !valid[i] {
+			continue
+		}
+		price, ok := normaliser.normalise(trade)
+		if !ok {
+			estimate.Confidence.NumUnconverted++
+			continue
+		}
+		prices = append(prices, price)
+	}
+	sort.Float64s(prices)
+
+	estimate.Floor = quantileSorted(prices, quantile)
+	estimate.FloorUSD = estimate.Floor * normaliser.referenceRate
+	estimate.Confidence.NumUsed = len(prices)
+	estimate.Confidence.MinPrice = prices[0]
+	estimate.Confidence.MedianPrice = quantileSorted(prices, 0.5)
+	n := float64(len(prices))
+	estimate.Confidence.Score = n / (n + nftFloorSampleHalfSaturation)
+	if estimate.Confidence.MedianPrice > 0 {
+		estimate.Confidence.Score *= estimate.Floor / estimate.Confidence.MedianPrice
+	}
+	return
+}
+
+// isNFTBundleShare returns true if @trade is the sale of a token sold together with others in a single order.
+// Its price is the share of the bundle price allocated to the token, not a market price of the token.
+func isNFTBundleShare(trade dia.NFTTrade) bool {
+	return trade.BundleSize > 1
+}
+
+// nftPriceNormaliser converts sale prices per item to units of its reference currency, the currency most
+// sales were made in. Prices in other currencies are converted with the median USD prices of the currencies.
+type nftPriceNormaliser struct {
+	reference     string
+	currency      dia.Asset
+	referenceRate float64
+	rates         map[string]float64
+}
+
+// newNFTPriceNormaliser returns the normaliser for the sales in @trades flagged by @use.
+// It returns false if no sale is flagged.
+func newNFTPriceNormaliser(trades []dia.NFTTrade, use []bool) (n nftPriceNormaliser, ok bool) {
+	currencies := make(map[string]dia.Asset)
+	counts := make(map[string]int)
+	usdRates := make(map[string][]float64)
+	for i, trade := range trades {
+		if !use[i] {
+			continue
+		}
+		key := nftCurrencyKey(trade.Currency)
+		currencies[key] = trade.Currency
+		counts[key]++
+		if amount := nftTradeAmount(trade); trade.PriceUSD > 0 && amount > 0 {
+			usdRates[key] = append(usdRates[key], trade.PriceUSD/(amount*float64(nftTradeQuantity(trade))))
+		}
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	for key, count := range counts {
+		if count > counts[n.reference] || (count == counts[n.reference] && key < n.reference) {
+			n.reference = key
+		}
+	}
+	n.currency = currencies[n.reference]
+	n.rates = make(map[string]float64)
+	for key, rates := range usdRates {
+		n.rates[key] = median(rates)
+	}
+	n.referenceRate = n.rates[n.reference]
+	return n, true
+}
+
+// normalise returns the price per item of @trade in units of the reference currency. It returns
+// false if the price cannot be converted for lack of USD prices.
+func (n nftPriceNormaliser) normalise(trade dia.NFTTrade) (float64, bool) {
+	key := nftCurrencyKey(trade.Currency)
+	if key == n.reference {
+		return nftTradeAmount(trade), true
+	}
+	rate := n.rates[key]
+	if rate == 0 || n.referenceRate == 0 {
+		return 0, false
+	}
+	return nftTradeAmount(trade) * rate / n.referenceRate, true
+}
+
+// flagNFTWashTrades returns for each sale in @trades whether it is a wash trade.
+func flagNFTWashTrades(trades []dia.NFTTrade) []bool {
+	wash := make([]bool, len(trades))
+	clusters := newAddressClusters()
+
+	// Group sales by token in chronological order.
+	tokens := make(map[string][]int)
+	for i, trade := range trades {
+		if strings.EqualFold(trade.FromAddress, trade.ToAddress) {
+			wash[i] = true
+			continue
+		}
+		tokens[trade.NFT.TokenID] = append(tokens[trade.NFT.TokenID], i)
+	}
+	for _, indices := range tokens {
+		sort.SliceStable(indices, func(a, b int) bool {
+			return trades[indices[a]].Timestamp.Before(trades[indices[b]].Timestamp)
+		})
+		// A token returning to a previous seller closes a round trip.
+		sellers := make(map[string]int)
+		for k, i := range indices {
+			buyer := strings.ToLower(trades[i].ToAddress)
+			if start, ok := sellers[buyer]; ok {
+				for _, j := range indices[start : k+1] {
+					wash[j] = true
+					clusters.union(trades[j].FromAddress, trades[j].ToAddress)
+				}
+			}
+			seller := strings.ToLower(trades[i].FromAddress)
+			if _, ok := sellers[seller]; !ok {
+				sellers[seller] = k
+			}
+		}
+	}
+
+	for i, trade := range trades {
+		if !wash[i] && clusters.connected(trade.FromAddress, trade.ToAddress) {
+			wash[i] = true
+		}
+	}
+	return wash
+}
+
+// addressClusters is a union-find structure on case insensitive addresses.
+type addressClusters map[string]string
+
+func newAddressClusters() addressClusters {
+	return make(addressClusters)
+}
+
+func (ac addressClusters) find(address string) string {
+	address = strings.ToLower(address)
+	for {
+		parent, ok := ac[address]
+		if !ok || parent == address {
+			return address
+		}
+		ac[address] = ac[parent]
+		address = parent
+	}
+}
+
+func (ac addressClusters) union(a, b string) {
+	rootA, rootB := ac.find(a), ac.find(b)
+	ac[rootA] = rootB
+	ac[rootB] = rootB
+}
+
+func (ac addressClusters) connected(a, b string) bool {
+	if _, ok := ac[strings.ToLower(a)]; !ok {
+		return false
+	}
+	return ac.find(a) == ac.find(b)
+}
+
+// nftTradeAmount returns the price per item of @trade in units of its currency.
+func nftTradeAmount(trade dia.NFTTrade) float64 {
+	decimals := int(trade.Currency.Decimals)
+	if trade.Currency.Address == "" && decimals == 0 {
+		decimals = nftDefaultDecimals
+	}
+	amount, _ := new(big.Float).Quo(new(big.Float).SetInt(trade.Price), new(big.Float).SetFloat64(math.Pow10(decimals))).Float64()
+	return amount / float64(nftTradeQuantity(trade))
+}
+
+// nftTradeQuantity returns the number of items sold in @trade, which is 1 if not set.
+func nftTradeQuantity(trade dia.NFTTrade) uint64 {
+	if trade.Quantity == 0 {
+		return 1
+	}
+	return trade.Quantity
+}
+
+func nftCurrencyKey(currency dia.Asset) string {
+	return currency.Blockchain + "-" + strings.ToLower(currency.Address)
+}
+
+// quantileSorted returns the @q quantile of the sorted slice @values by linear interpolation.
+func quantileSorted(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	position := q * float64(len(values)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return values[lower] + (position-float64(lower))*(values[upper]-values[lower])
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return quantileSorted(sorted, 0.5)
+}
+
+// GetNFTClassTrades returns all sales of nfts from @nftClass in the time range (@starttime, @endtime].
+func (rdb *RelDB) GetNFTClassTrades(nftClass dia.NFTClass, starttime time.Time, endtime time.Time) (trades []dia.NFTTrade, err error) {
+	query := fmt.Sprintf(`SELECT nf.token_id,tr.price,tr.price_usd,tr.transfer_from,tr.transfer_to,tr.currency_id,tr.block_number,tr.trade_time,tr.tx_hash,tr.marketplace,tr.quantity,tr.bundle_id,tr.bundle_size
+	FROM %s tr
+	INNER JOIN %s nf ON tr.nft_id=nf.nft_id
+	INNER JOIN %s n ON tr.nftclass_id=n.nftclass_id
+	WHERE n.address=$1 AND n.blockchain=$2 AND tr.trade_time>to_timestamp($3) AND tr.trade_time<=to_timestamp($4)`,
+		NfttradeCurrTable,
+		nftTable,
+		nftclassTable,
+	)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftClass.Address, nftClass.Blockchain, starttime.Unix(), endtime.Unix())
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var trade dia.NFTTrade
+		var price string
+		var currencyID sql.NullString
+		var bundle nftTradeBundle
+		err = rows.Scan(
+			&trade.NFT.TokenID,
+			&price,
+			&trade.PriceUSD,
+			&trade.FromAddress,
+			&trade.ToAddress,
+			&currencyID,
+			&trade.BlockNumber,
+			&trade.Timestamp,
+			&trade.TxHash,
+			&trade.Exchange,
+			&bundle.quantity,
+			&bundle.bundleID,
+			&bundle.bundleSize,
+		)
+		if err != nil {
+			return
+		}
+		bundle.assignTo(&trade)
+		var ok bool
+		trade.Price, ok = new(big.Int).SetString(price, 10)
+		if !ok {
+			err = fmt.Errorf("cannot parse price %s", price)
+			return
+		}
+		if currencyID.Valid {
+			trade.Currency = rdb.currencyByID(currencyID.String)
+		}
+		trade.NFT.NFTClass = nftClass
+		trades = append(trades, trade)
+	}
+	return
+}
+
+// GetNFTFloorEstimate returns the floor price of @nftClass computed by EstimateNFTFloor from the sales in the window
+// of length @floorWindow ending at @timestamp. If there are no sales it steps back at most @stepBackLimit windows.
+func (rdb *RelDB) GetNFTFloorEstimate(nftClass dia.NFTClass, timestamp time.Time, floorWindow time.Duration, quantile float64, stepBackLimit int) (estimate NFTFloorEstimate, err error) {
+	if quantile < 0 || quantile > 1 {
+		err = fmt.Errorf("quantile %v not in [0,1]", quantile)
+		return
+	}
+	err = ErrNoNFTFloor
+	for count := 0; count < stepBackLimit; count++ {
+		var trades []dia.NFTTrade
+		trades, err = rdb.GetNFTClassTrades(nftClass, timestamp.Add(-floorWindow), timestamp)
+		if err != nil {
+			return
+		}
+		estimate, err = EstimateNFTFloor(trades, quantile)
+		if err != nil && !errors.Is(err, ErrNoNFTFloor) {
+			return
+		}
+		if err == nil {
+			estimate.Time = timestamp
+			estimate.Confidence.WindowStart = timestamp.Add(-floorWindow)
+			estimate.Confidence.WindowEnd = timestamp
+			return
+		}
+		timestamp = timestamp.Add(-floorWindow)
+	}
+	return
+}
+
+// currencyByID returns the asset with postgres id @currencyID, cached in memory.
+// Failed lookups are not cached.
+func (rdb *RelDB) currencyByID(currencyID string) dia.Asset {
+	currencyCacheMu.RLock()
+	asset, ok := currencyCache[currencyID]
+	currencyCacheMu.RUnlock()
+	if ok {
+		return asset
+	}
+	asset, err := rdb.GetAssetByID(currencyID)
+	if err != nil {
+		log.Errorf("cannot fetch asset with postgres id %s", currencyID)
+		return asset
+	}
+	currencyCacheMu.Lock()
+	currencyCache[currencyID] = asset
+	currencyCacheMu.Unlock()
+	return asset
+}
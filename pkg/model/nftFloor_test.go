@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func nftSale(tokenID string, from, to string, price *big.Int, priceUSD float64, currency dia.Asset, minute int) dia.NFTTrade {
+	return dia.NFTTrade{
+		NFT:         dia.NFT{TokenID: tokenID},
+		Price:       price,
+		PriceUSD:    priceUSD,
+		FromAddress: from,
+		ToAddress:   to,
+		Currency:    currency,
+		Timestamp:   time.Unix(1640995200, 0).Add(time.Duration(minute) * time.Minute),
+	}
+}
+
+func TestEstimateNFTFloor(t *testing.T) {
+	eth := dia.Asset{Symbol: "ETH", Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Decimals: 18}
+	usdc := dia.Asset{Symbol: "USDC", Blockchain: dia.ETHEREUM, Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+	ether := func(x int64) *big.Int { return new(big.Int).Mul(big.NewInt(x), big.NewInt(1e18)) }
+
+	trades := []dia.NFTTrade{
+		nftSale("1", "0xa", "0xb", ether(10), 30000, eth, 0),
+		nftSale("2", "0xc", "0xd", ether(12), 36000, eth, 1),
+		nftSale("3", "0xe", "0xf", ether(14), 42000, eth, 2),
+		// 9000 USDC are 3 ETH at the rates above.
+		nftSale("4", "0xg", "0xh", big.NewInt(9000e6), 9000, usdc, 3),
+		// Round trip of token 5 between 0xw and 0xx, and a sale between them on another token.
+		nftSale("5", "0xw", "0xx", ether(1), 3000, eth, 4),
+		nftSale("5", "0xx", "0xw", ether(1), 3000, eth, 5),
+		nftSale("6", "0xX", "0xW", ether(1), 3000, eth, 6),
+		// Self trade and private transfer.
+		nftSale("7", "0xs", "0xS", ether(1), 3000, eth, 7),
+		nftSale("8", "0xp", "0xq", big.NewInt(0), 0, eth, 8),
+	}
+
+	estimate, err := EstimateNFTFloor(trades, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if estimate.Currency.Symbol != "ETH" || math.Abs(estimate.Floor-3) > 1e-9 || math.Abs(estimate.FloorUSD-9000) > 1e-6 {
+		t.Errorf("floor was incorrect, got: %v %s %v, want: %v %s %v.", estimate.Floor, estimate.Currency.Symbol, estimate.FloorUSD, 3, "ETH", 9000)
+	}
+	confidence := estimate.Confidence
+	if confidence.NumTrades != 9 || confidence.NumUsed != 4 || confidence.NumWashTrades != 4 || confidence.NumZeroPrice != 1 {
+		t.Errorf("confidence was incorrect, got: %+v.", confidence)
+	}
+
+	estimate, err = EstimateNFTFloor(trades, 0.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if estimate.Floor != 11 {
+		t.Errorf("median floor was incorrect, got: %v, want: %v.", estimate.Floor, 11)
+	}
+
+	if _, err = EstimateNFTFloor(trades[7:], 0.1); err != ErrNoNFTFloor {
+		t.Errorf("error was incorrect, got: %v, want: %v.", err, ErrNoNFTFloor)
+	}
+
+	// An ERC1155 sale of 4 items for 8 ETH is a sale at 2 ETH per item.
+	multiple := nftSale("9", "0xm", "0xn", ether(8), 24000, eth, 9)
+	multiple.Quantity = 4
+	estimate, err = EstimateNFTFloor(append(trades[:4:4], multiple), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(estimate.Floor-2) > 1e-9 || math.Abs(estimate.FloorUSD-6000) > 1e-6 {
+		t.Errorf("floor per item was incorrect, got: %v %v, want: %v %v.", estimate.Floor, estimate.FloorUSD, 2, 6000)
+	}
+
+	// Two tokens sold in a bundle for 2 ETH carry allocated shares of 1 ETH, which are no market prices.
+	bundle := []dia.NFTTrade{
+		nftSale("10", "0xu", "0xv", ether(1), 3000, eth, 10),
+		nftSale("11", "0xu", "0xv", ether(1), 3000, eth, 10),
+	}
+	for i := range bundle {
+		bundle[i].BundleID = "0xbundle"
+		bundle[i].BundleSize = 2
+	}
+	estimate, err = EstimateNFTFloor(append(trades[:4:4], bundle...), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(estimate.Floor-3) > 1e-9 || estimate.Confidence.NumUsed != 4 || estimate.Confidence.NumBundleShares != 2 {
+		t.Errorf("floor with bundle was incorrect, got: %v %+v, want: %v with %v bundle shares.", estimate.Floor, estimate.Confidence, 3, 2)
+	}
+	if _, err = EstimateNFTFloor(bundle, 0); err != ErrNoNFTFloor {
+		t.Errorf("error was incorrect, got: %v, want: %v.", err, ErrNoNFTFloor)
+	}
+}
+
+func TestNFTFloorsInCurrency(t *testing.T) {
+	eth := dia.Asset{Symbol: "ETH", Blockchain: dia.ETHEREUM}
+	usdc := dia.Asset{Symbol: "USDC", Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Blockchain: dia.ETHEREUM, Decimals: 6}
+
+	floors, currency, err := NFTFloorsInCurrency([]NFTFloorEstimate{
+		{Floor: 2, FloorUSD: 6000, Currency: eth},
+		{},
+		{Floor: 9000, FloorUSD: 9000, Currency: usdc},
+		{Floor: 2.5, FloorUSD: 7500, Currency: eth},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{2, 0, 3, 2.5}
+	if currency.Symbol != "ETH" || len(floors) != len(want) {
+		t.Fatalf("floors were incorrect, got: %v %s, want: %v ETH.", floors, currency.Symbol, want)
+	}
+	for i := range want {
+		if math.Abs(floors[i]-want[i]) > 1e-9 {
+			t.Errorf("floor %d was incorrect, got: %v, want: %v.", i, floors[i], want[i])
+		}
+	}
+
+	_, _, err = NFTFloorsInCurrency([]NFTFloorEstimate{
+		{Floor: 2, Currency: eth},
+		{Floor: 2.5, Currency: eth},
+		{Floor: 9000, FloorUSD: 9000, Currency: usdc},
+	})
+	if !errors.Is(err, ErrNFTFloorCurrencies) {
+		t.Errorf("floors without USD price were converted, got: %v.", err)
+	}
+}
@@ -0,0 +1,251 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// NFTMarketStatsHourly and NFTMarketStatsDaily are the intervals market statistics are aggregated over.
+	NFTMarketStatsHourly = "1h"
+	NFTMarketStatsDaily  = "1d"
+)
+
+// ErrUnknownNFTMarketStatsInterval is returned for intervals other than NFTMarketStatsHourly and NFTMarketStatsDaily.
+var ErrUnknownNFTMarketStatsInterval = errors.New("unknown market stats interval")
+
+// NFTMarketStats are the market statistics of an nft collection in the period [Time, Time+Interval).
+// Prices and Volume are in units of Currency, the currency most sales were made in, see nftPriceNormaliser.
+type NFTMarketStats struct {
+	NFTClass      dia.NFTClass
+	Interval      string
+	Time          time.Time
+	Currency      dia.Asset
+	Volume        float64
+	VolumeUSD     float64
+	NumSales      int
+	UniqueBuyers  int
+	UniqueSellers int
+	// HolderCount is the number of addresses holding a token of the collection at the time the statistics were computed.
+	// It is zero if the holders are not known yet.
+	HolderCount int
+	// FloorPrice, MedianPrice and MeanPrice are statistics of the prices per item.
+	FloorPrice  float64
+	MedianPrice float64
+	MeanPrice   float64
+}
+
+// NFTMarketStatsPeriod returns the duration of @interval.
+func NFTMarketStatsPeriod(interval string) (time.Duration, error) {
+	switch interval {
+	case NFTMarketStatsHourly:
+		return time.Hour, nil
+	case NFTMarketStatsDaily:
+		return 24 * time.Hour, nil
+	default:
+		return 0, ErrUnknownNFTMarketStatsInterval
+	}
+}
+
+// ComputeNFTMarketStats returns the market statistics of the sales in @trades, which are the sales of a collection
+// in the period of length @interval starting at @starttime. Sales with zero price are left out. Sales in currencies
+// other than the reference currency without USD price count towards NumSales and the unique addresses only.
+// A bundle counts as one sale and adds to the volume, but the allocated shares of its tokens are left out of the prices.
+func ComputeNFTMarketStats(trades []dia.NFTTrade, interval string, starttime time.Time) (stats NFTMarketStats) {
+	stats.Interval = interval
+	stats.Time = starttime
+
+	use := make([]bool, len(trades))
+	buyers := make(map[string]bool)
+	sellers := make(map[string]bool)
+	bundles := make(map[string]bool)
+	for i, trade := range trades {
+		if trade.Price == nil || trade.Price.Sign() <= 0 {
+			continue
+		}
+		use[i] = true
+		if !isNFTBundleShare(trade) {
+			stats.NumSales++
+		} else if !bundles[trade.BundleID] {
+			bundles[trade.BundleID] = true
+			stats.NumSales++
+		}
+		stats.VolumeUSD += trade.PriceUSD
+		buyers[strings.ToLower(trade.ToAddress)] = true
+		sellers[strings.ToLower(trade.FromAddress)] = true
+	}
+	stats.UniqueBuyers = len(buyers)
+	stats.UniqueSellers = len(sellers)
+
+	normaliser, ok := newNFTPriceNormaliser(trades, use)
+	if !ok {
+		return
+	}
+	stats.Currency = normaliser.currency
+
+	var prices []float64
+	var items, itemsVolume float64
+	for i, trade := range trades {
+		if !use[i] {
+			continue
+		}
+		price, ok := normaliser.normalise(trade)
+		if !ok {
+			continue
+		}
+		quantity := float64(nftTradeQuantity(trade))
+		stats.Volume += price * quantity
+		if isNFTBundleShare(trade) {
+			continue
+		}
+		itemsVolume += price * quantity
+		items += quantity
+		prices = append(prices, price)
+	}
+	if len(prices) == 0 {
+		return
+	}
+	sort.Float64s(prices)
+	stats.FloorPrice = prices[0]
+	stats.MedianPrice = quantileSorted(prices, 0.5)
+	stats.MeanPrice = itemsVolume / items
+	return
+}
+
+// ComputeNFTClassMarketStats returns the market statistics of @nftClass in the period of length @interval
+// starting at @starttime, including the current holder count.
+func (rdb *RelDB) ComputeNFTClassMarketStats(nftClass dia.NFTClass, interval string, starttime time.Time) (stats NFTMarketStats, err error) {
+	period, err := NFTMarketStatsPeriod(interval)
+	if err != nil {
+		return
+	}
+	// GetNFTClassTrades covers (starttime,endtime], which is shifted to [starttime,endtime).
+	trades, err := rdb.GetNFTClassTrades(nftClass, starttime.Add(-time.Second), starttime.Add(period).Add(-time.Second))
+	if err != nil {
+		return
+	}
+	stats = ComputeNFTMarketStats(trades, interval, starttime)
+	stats.NFTClass = nftClass
+	stats.HolderCount, err = rdb.GetNFTHolderCount(nftClass)
+	return
+}
+
+// SetNFTMarketStats stores @stats, replacing previous statistics of the same collection, interval and time.
+// A zero HolderCount keeps the stored holder count.
+func (rdb *RelDB) SetNFTMarketStats(stats NFTMarketStats) error {
+	nftClassID, err := rdb.GetNFTClassID(stats.NFTClass.Address, stats.NFTClass.Blockchain)
+	if err != nil {
+		return err
+	}
+	var currencyID string
+	if stats.Currency.Address != "" || stats.Currency.Blockchain != "" {
+		currencyID, err = rdb.GetAssetID(stats.Currency)
+		if err != nil {
+			log.Error("get currency ID: ", err)
+		}
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (nftclass_id,interval,time_start,currency_id,volume,volume_usd,num_sales,unique_buyers,unique_sellers,holder_count,floor_price,median_price,mean_price)
+	VALUES ($1,$2,$3,NULLIF($4,'')::uuid,$5,$6,$7,$8,$9,NULLIF($10,0),$11,$12,$13)
+	ON CONFLICT (nftclass_id,interval,time_start) DO UPDATE SET currency_id=EXCLUDED.currency_id,volume=EXCLUDED.volume,volume_usd=EXCLUDED.volume_usd,
+	num_sales=EXCLUDED.num_sales,unique_buyers=EXCLUDED.unique_buyers,unique_sellers=EXCLUDED.unique_sellers,holder_count=COALESCE(EXCLUDED.holder_count,%s.holder_count),
+	floor_price=EXCLUDED.floor_price,median_price=EXCLUDED.median_price,mean_price=EXCLUDED.mean_price`, nftmarketstatsTable, nftmarketstatsTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query,
+		nftClassID,
+		stats.Interval,
+		stats.Time,
+		currencyID,
+		stats.Volume,
+		stats.VolumeUSD,
+		stats.NumSales,
+		stats.UniqueBuyers,
+		stats.UniqueSellers,
+		stats.HolderCount,
+		stats.FloorPrice,
+		stats.MedianPrice,
+		stats.MeanPrice,
+	)
+	return err
+}
+
+// GetNFTMarketStats returns the stored market statistics of @nftClass for @interval with start time in [@starttime, @endtime].
+func (rdb *RelDB) GetNFTMarketStats(nftClass dia.NFTClass, interval string, starttime time.Time, endtime time.Time) (stats []NFTMarketStats, err error) {
+	if _, err = NFTMarketStatsPeriod(interval); err != nil {
+		return
+	}
+	nftClassID, err := rdb.GetNFTClassID(nftClass.Address, nftClass.Blockchain)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf(`SELECT time_start,currency_id::text,volume,volume_usd,num_sales,unique_buyers,unique_sellers,COALESCE(holder_count,0),floor_price,median_price,mean_price
+	FROM %s WHERE nftclass_id=$1 AND interval=$2 AND time_start>=$3 AND time_start<=$4 ORDER BY time_start ASC`, nftmarketstatsTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftClassID, interval, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s := NFTMarketStats{NFTClass: nftClass, Interval: interval}
+		var currencyID *string
+		err = rows.Scan(
+			&s.Time,
+			&currencyID,
+			&s.Volume,
+			&s.VolumeUSD,
+			&s.NumSales,
+			&s.UniqueBuyers,
+			&s.UniqueSellers,
+			&s.HolderCount,
+			&s.FloorPrice,
+			&s.MedianPrice,
+			&s.MeanPrice,
+		)
+		if err != nil {
+			return
+		}
+		if currencyID != nil {
+			s.Currency = rdb.currencyByID(*currencyID)
+		}
+		stats = append(stats, s)
+	}
+	return
+}
+
+// UpdateNFTHolderBalances adds the token balance changes @deltas by address to the holder balances of @nftClass
+// and stores @state as scraper state @stateName in the same transaction. The state records up to which block
+// the balances are updated, so that changes are applied exactly once.
+func (rdb *RelDB) UpdateNFTHolderBalances(nftClass dia.NFTClass, deltas map[string]int64, stateName string, state ScraperState) error {
+	nftClassID, err := rdb.GetNFTClassID(nftClass.Address, nftClass.Blockchain)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (nftclass_id,address,balance) VALUES ($1,$2,$3)
+	ON CONFLICT (nftclass_id,address) DO UPDATE SET balance=%s.balance+EXCLUDED.balance`, nftholderTable, nftholderTable)
+
+	batch := &pgx.Batch{}
+	for address, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		batch.Queue(query, nftClassID, address, delta)
+	}
+	return rdb.sendBatchWithScraperState(context.Background(), batch, stateName, state)
+}
+
+// GetNFTHolderCount returns the number of addresses holding a token of @nftClass.
+func (rdb *RelDB) GetNFTHolderCount(nftClass dia.NFTClass) (count int, err error) {
+	nftClassID, err := rdb.GetNFTClassID(nftClass.Address, nftClass.Blockchain)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE nftclass_id=$1 AND balance>0", nftholderTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftClassID).Scan(&count)
+	return
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestComputeNFTMarketStats(t *testing.T) {
+	eth := dia.Asset{Symbol: "ETH", Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Decimals: 18}
+	usdc := dia.Asset{Symbol: "USDC", Blockchain: dia.ETHEREUM, Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+	ether := func(x int64) *big.Int { return new(big.Int).Mul(big.NewInt(x), big.NewInt(1e18)) }
+	starttime := time.Unix(1640995200, 0)
+
+	trades := []dia.NFTTrade{
+		nftSale("1", "0xa", "0xb", ether(10), 30000, eth, 0),
+		// Two items of an ERC1155 token at 12 ETH each.
+		nftSale("2", "0xc", "0xb", ether(24), 72000, eth, 1),
+		// 9000 USDC are 3 ETH at the rates above.
+		nftSale("3", "0xa", "0xd", big.NewInt(9000e6), 9000, usdc, 2),
+		// Transfers without price are no sales.
+		nftSale("4", "0xe", "0xf", big.NewInt(0), 0, eth, 3),
+		// A bundle of two tokens for 2 ETH is one sale and adds to the volume, but not to the prices.
+		nftSale("5", "0xa", "0xd", ether(1), 3000, eth, 4),
+		nftSale("6", "0xa", "0xd", ether(1), 3000, eth, 4),
+	}
+	trades[1].Quantity = 2
+	for i := 4; i < 6; i++ {
+		trades[i].BundleID = "0xbundle"
+		trades[i].BundleSize = 2
+	}
+
+	stats := ComputeNFTMarketStats(trades, NFTMarketStatsDaily, starttime)
+	if stats.NumSales != 4 || stats.UniqueBuyers != 2 || stats.UniqueSellers != 2 {
+		t.Errorf("sales were incorrect, got: %+v, want: %v sales, %v buyers, %v sellers.", stats, 4, 2, 2)
+	}
+	if stats.Currency != eth || !stats.Time.Equal(starttime) || stats.Interval != NFTMarketStatsDaily {
+		t.Errorf("metadata was incorrect, got: %+v.", stats)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"volume", stats.Volume, 39},
+		{"volume USD", stats.VolumeUSD, 117000},
+		{"floor", stats.FloorPrice, 3},
+		{"median", stats.MedianPrice, 10},
+		{"mean", stats.MeanPrice, 9.25},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s was incorrect, got: %v, want: %v.", c.name, c.got, c.want)
+		}
+	}
+
+	if empty := ComputeNFTMarketStats(nil, NFTMarketStatsHourly, starttime); empty.NumSales != 0 || empty.Volume != 0 || empty.FloorPrice != 0 {
+		t.Errorf("stats without sales were incorrect, got: %+v.", empty)
+	}
+}
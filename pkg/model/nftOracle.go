@@ -0,0 +1,159 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// NFTOracleUpdatePending is the status of an update whose transaction is sent but not mined yet.
+	NFTOracleUpdatePending = "pending"
+	// NFTOracleUpdateConfirmed is the status of an update whose transaction succeeded.
+	NFTOracleUpdateConfirmed = "confirmed"
+	// NFTOracleUpdateFailed is the status of an update whose transaction reverted.
+	NFTOracleUpdateFailed = "failed"
+)
+
+// NFTOracleUpdate is the audit record of a floor price update pushed to an nft oracle contract.
+type NFTOracleUpdate struct {
+	// Oracle is the address of the oracle contract on the chain with ChainID, Key the key the values are stored under.
+	Oracle   string
+	ChainID  int64
+	Key      string
+	NFTClass dia.NFTClass
+	// Floor, FloorMA, FloorUSD, Confidence and NumTrades are the floor statistics the values are encoded from.
+	// Floor and FloorMA are in units of Currency, the symbol of the collection's floor currency.
+	Floor      float64
+	FloorMA    float64
+	FloorUSD   float64
+	Currency   string
+	Confidence float64
+	NumTrades  int
+	// Values and ValueTime are the arguments of the contract call.
+	Values    [5]uint64
+	ValueTime time.Time
+	// Reason is the trigger of the update, e.g. deviation or heartbeat.
+	Reason string
+	// Signature is the signature of Signer over the values, such that the pushed values can be attributed.
+	Signer    string
+	Signature string
+	TxHash    string
+	// Status is one of NFTOracleUpdatePending, NFTOracleUpdateConfirmed and NFTOracleUpdateFailed.
+	Status string
+	// Time is the time the update was sent.
+	Time time.Time
+}
+
+const nftOracleUpdateColumns = "u.oracle_address,u.chain_id,u.oracle_key,c.address,c.blockchain,u.floor,u.floor_ma,u.floor_usd,COALESCE(u.currency,''),u.confidence,u.num_trades,u.value0,u.value1,u.value2,u.value3,u.value4,u.value_time,u.reason,u.signer,u.signature,u.tx_hash,u.status,u.update_time"
+
+// SetNFTOracleUpdate stores the audit record @update.
+func (rdb *RelDB) SetNFTOracleUpdate(update NFTOracleUpdate) error {
+	nftClassID, err := rdb.GetNFTClassID(update.NFTClass.Address, update.NFTClass.Blockchain)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (oracle_address,chain_id,oracle_key,nftclass_id,floor,floor_ma,floor_usd,currency,confidence,num_trades,value0,value1,value2,value3,value4,value_time,reason,signer,signature,tx_hash,status,update_time)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22)`, nftoracleupdateTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query,
+		update.Oracle,
+		update.ChainID,
+		update.Key,
+		nftClassID,
+		update.Floor,
+		update.FloorMA,
+		update.FloorUSD,
+		update.Currency,
+		update.Confidence,
+		update.NumTrades,
+		update.Values[0],
+		update.Values[1],
+		update.Values[2],
+		update.Values[3],
+		update.Values[4],
+		update.ValueTime,
+		update.Reason,
+		update.Signer,
+		update.Signature,
+		update.TxHash,
+		update.Status,
+		update.Time,
+	)
+	return err
+}
+
+// SetNFTOracleUpdateStatus sets the status of the update with transaction @txHash pushed to the oracle @oracle on the chain @chainID.
+func (rdb *RelDB) SetNFTOracleUpdateStatus(oracle string, chainID int64, txHash string, status string) error {
+	query := fmt.Sprintf("UPDATE %s SET status=$4 WHERE oracle_address=$1 AND chain_id=$2 AND tx_hash=$3", nftoracleupdateTable)
+	tag, err := rdb.postgresClient.Exec(context.Background(), query, oracle, chainID, txHash, status)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// GetLastNFTOracleUpdate returns the last confirmed update of @key pushed to the oracle @oracle on the chain @chainID.
+func (rdb *RelDB) GetLastNFTOracleUpdate(oracle string, chainID int64, key string) (NFTOracleUpdate, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s u INNER JOIN %s c ON u.nftclass_id=c.nftclass_id
+	WHERE u.oracle_address=$1 AND u.chain_id=$2 AND u.oracle_key=$3 AND u.status=$4 ORDER BY u.update_time DESC LIMIT 1`, nftOracleUpdateColumns, nftoracleupdateTable, nftclassTable)
+	return scanNFTOracleUpdate(rdb.postgresClient.QueryRow(context.Background(), query, oracle, chainID, key, NFTOracleUpdateConfirmed))
+}
+
+// GetNFTOracleUpdates returns the updates of @key pushed to the oracle @oracle on the chain @chainID
+// in the time range [@starttime, @endtime], oldest first.
+func (rdb *RelDB) GetNFTOracleUpdates(oracle string, chainID int64, key string, starttime time.Time, endtime time.Time) (updates []NFTOracleUpdate, err error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s u INNER JOIN %s c ON u.nftclass_id=c.nftclass_id
+	WHERE u.oracle_address=$1 AND u.chain_id=$2 AND u.oracle_key=$3 AND u.update_time>=$4 AND u.update_time<=$5 ORDER BY u.update_time`, nftOracleUpdateColumns, nftoracleupdateTable, nftclassTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, oracle, chainID, key, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var update NFTOracleUpdate
+		update, err = scanNFTOracleUpdate(rows)
+		if err != nil {
+			return
+		}
+		updates = append(updates, update)
+	}
+	err = rows.Err()
+	return
+}
+
+// scanNFTOracleUpdate scans a row of nftOracleUpdateColumns.
+func scanNFTOracleUpdate(row pgx.Row) (update NFTOracleUpdate, err error) {
+	err = row.Scan(
+		&update.Oracle,
+		&update.ChainID,
+		&update.Key,
+		&update.NFTClass.Address,
+		&update.NFTClass.Blockchain,
+		&update.Floor,
+		&update.FloorMA,
+		&update.FloorUSD,
+		&update.Currency,
+		&update.Confidence,
+		&update.NumTrades,
+		&update.Values[0],
+		&update.Values[1],
+		&update.Values[2],
+		&update.Values[3],
+		&update.Values[4],
+		&update.ValueTime,
+		&update.Reason,
+		&update.Signer,
+		&update.Signature,
+		&update.TxHash,
+		&update.Status,
+		&update.Time,
+	)
+	return
+}
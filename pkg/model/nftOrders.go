@@ -0,0 +1,394 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jackc/pgx/v4"
+)
+
+// ErrInvalidNFTOrderEvent is returned for order events with unknown side or type.
+var ErrInvalidNFTOrderEvent = errors.New("invalid nft order event")
+
+// NFTOrder is the current state of a bid or an offer in the order book of an nft.
+type NFTOrder struct {
+	NFT      dia.NFT
+	Exchange string
+	OrderID  string
+	Side     string
+	// Status is the type of the last event applied to the order, i.e. dia.NFTOrderPlaced for open orders.
+	Status   string
+	Maker    string
+	Price    *big.Int
+	EndPrice *big.Int
+	Duration time.Duration
+	Currency dia.Asset
+	Expiry   time.Time
+	// Time is the time the order was placed, UpdateTime the time of the last event applied to it.
+	Time          time.Time
+	UpdateTime    time.Time
+	BlockNumber   uint64
+	BlockPosition uint64
+	TxHash        string
+}
+
+// NFTBestBidOffer is the best open bid and the best open offer of a collection in one currency.
+// Bids are ranked by highest and offers by lowest current price, ties go to the older order.
+type NFTBestBidOffer struct {
+	NFTClass  dia.NFTClass
+	Currency  dia.Asset
+	BestBid   *NFTOrder
+	BestOffer *NFTOrder
+	NumBids   int
+	NumOffers int
+	Time      time.Time
+}
+
+// IsOpen returns true if @order is placed and not expired at @timestamp.
+func (order NFTOrder) IsOpen(timestamp time.Time) bool {
+	return order.Status == dia.NFTOrderPlaced && (order.Expiry.IsZero() || timestamp.Before(order.Expiry))
+}
+
+// CurrentPrice returns the price of @order at @timestamp. The price of an auction moves linearly
+// from Price to EndPrice in the period Duration after the order was placed.
+func (order NFTOrder) CurrentPrice(timestamp time.Time) *big.Int {
+	if order.Price == nil {
+		return nil
+	}
+	if order.EndPrice == nil || order.Duration <= 0 {
+		return new(big.Int).Set(order.Price)
+	}
+	elapsed := timestamp.Sub(order.Time)
+	if elapsed <= 0 {
+		return new(big.Int).Set(order.Price)
+	}
+	if elapsed >= order.Duration {
+		return new(big.Int).Set(order.EndPrice)
+	}
+	change := new(big.Int).Sub(order.EndPrice, order.Price)
+	change.Mul(change, big.NewInt(int64(elapsed/time.Second)))
+	change.Quo(change, big.NewInt(int64(order.Duration/time.Second)))
+	return change.Add(change, order.Price)
+}
+
+// CurrentAmount returns the price of @order at @timestamp in units of its currency.
+func (order NFTOrder) CurrentAmount(timestamp time.Time) float64 {
+	price := order.CurrentPrice(timestamp)
+	if price == nil {
+		return 0
+	}
+	decimals := int(order.Currency.Decimals)
+	if order.Currency.Address == "" && decimals == 0 {
+		decimals = nftDefaultDecimals
+	}
+	amount, _ := new(big.Float).Quo(new(big.Float).SetInt(price), new(big.Float).SetFloat64(math.Pow10(decimals))).Float64()
+	return amount
+}
+
+// ApplyNFTOrderEvent returns the state of @order after @event. @exists is false if there is no order with the ID of
+// @event yet. Events are ordered by their position on chain, so an event which is not newer than the last event
+// applied to @order is discarded. A placement replaces the order with the same ID. Fills, cancellations and
+// expiries only apply to open orders of the event's maker, if it is set. The returned bool is false if
+// @event was discarded.
+func ApplyNFTOrderEvent(order NFTOrder, exists bool, event dia.NFTOrderEvent) (NFTOrder, bool, error) {
+	if event.Side != dia.NFTOrderSideBid && event.Side != dia.NFTOrderSideOffer {
+		return order, false, fmt.Errorf("%w: side %q", ErrInvalidNFTOrderEvent, event.Side)
+	}
+	switch event.Type {
+	case dia.NFTOrderPlaced, dia.NFTOrderFilled, dia.NFTOrderCancelled, dia.NFTOrderExpired:
+	default:
+		return order, false, fmt.Errorf("%w: type %q", ErrInvalidNFTOrderEvent, event.Type)
+	}
+	if event.OrderID == "" {
+		return order, false, fmt.Errorf("%w: missing order ID", ErrInvalidNFTOrderEvent)
+	}
+
+	if exists && (event.BlockNumber < order.BlockNumber || (event.BlockNumber == order.BlockNumber && event.BlockPosition <= order.BlockPosition)) {
+		return order, false, nil
+	}
+
+	if event.Type == dia.NFTOrderPlaced {
+		return NFTOrder{
+			NFT:           event.NFT,
+			Exchange:      event.Exchange,
+			OrderID:       event.OrderID,
+			Side:          event.Side,
+			Status:        dia.NFTOrderPlaced,
+			Maker:         event.Maker,
+			Price:         event.Price,
+			EndPrice:      event.EndPrice,
+			Duration:      event.Duration,
+			Currency:      event.Currency,
+			Expiry:        event.Expiry,
+			Time:          event.Timestamp,
+			UpdateTime:    event.Timestamp,
+			BlockNumber:   event.BlockNumber,
+			BlockPosition: event.BlockPosition,
+			TxHash:        event.TxHash,
+		}, true, nil
+	}
+
+	if !exists || order.Status != dia.NFTOrderPlaced || order.Side != event.Side {
+		return order, false, nil
+	}
+	if event.Maker != "" && !strings.EqualFold(event.Maker, order.Maker) {
+		return order, false, nil
+	}
+	order.Status = event.Type
+	order.UpdateTime = event.Timestamp
+	order.BlockNumber = event.BlockNumber
+	order.BlockPosition = event.BlockPosition
+	order.TxHash = event.TxHash
+	return order, true, nil
+}
+
+// ComputeNFTBestBidOffer returns the best bid and best offer among the orders in @orders which are open at @timestamp,
+// one for each currency orders are placed in. Currencies are sorted by decreasing number of open orders.
+func ComputeNFTBestBidOffer(nftClass dia.NFTClass, orders []NFTOrder, timestamp time.Time) (books []NFTBestBidOffer) {
+	index := make(map[string]int)
+	var bestBidPrices, bestOfferPrices []*big.Int
+	for i := range orders {
+		order := orders[i]
+		if !order.IsOpen(timestamp) || order.Price == nil {
+			continue
+		}
+		key := nftCurrencyKey(order.Currency)
+		j, ok := index[key]
+		if !ok {
+			j = len(books)
+			index[key] = j
+			books = append(books, NFTBestBidOffer{NFTClass: nftClass, Currency: order.Currency, Time: timestamp})
+			bestBidPrices = append(bestBidPrices, nil)
+			bestOfferPrices = append(bestOfferPrices, nil)
+		}
+		price := order.CurrentPrice(timestamp)
+		switch order.Side {
+		case dia.NFTOrderSideBid:
+			books[j].NumBids++
+			if books[j].BestBid == nil || price.Cmp(bestBidPrices[j]) > 0 || (price.Cmp(bestBidPrices[j]) == 0 && order.Time.Before(books[j].BestBid.Time)) {
+				books[j].BestBid = &order
+				bestBidPrices[j] = price
+			}
+		case dia.NFTOrderSideOffer:
+			books[j].NumOffers++
+			if books[j].BestOffer == nil || price.Cmp(bestOfferPrices[j]) < 0 || (price.Cmp(bestOfferPrices[j]) == 0 && order.Time.Before(books[j].BestOffer.Time)) {
+				books[j].BestOffer = &order
+				bestOfferPrices[j] = price
+			}
+		}
+	}
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].NumBids+books[i].NumOffers > books[j].NumBids+books[j].NumOffers
+	})
+	return
+}
+
+const nftOrderColumns = "o.marketplace,o.order_id,o.side,o.status,o.maker,o.price,o.end_price,o.duration,o.currency_id::text,o.expiry,o.order_time,o.update_time,o.blocknumber,o.blockposition,o.tx_hash,n.token_id"
+
+// scanNFTOrder scans a row of nftOrderColumns into an order. Its NFT only has the token ID set.
+func (rdb *RelDB) scanNFTOrder(row pgx.Row) (order NFTOrder, err error) {
+	var (
+		price      string
+		endPrice   *string
+		duration   int64
+		currencyID *string
+		expiry     *time.Time
+	)
+	err = row.Scan(
+		&order.Exchange,
+		&order.OrderID,
+		&order.Side,
+		&order.Status,
+		&order.Maker,
+		&price,
+		&endPrice,
+		&duration,
+		&currencyID,
+		&expiry,
+		&order.Time,
+		&order.UpdateTime,
+		&order.BlockNumber,
+		&order.BlockPosition,
+		&order.TxHash,
+		&order.NFT.TokenID,
+	)
+	if err != nil {
+		return
+	}
+	var ok bool
+	if order.Price, ok = new(big.Int).SetString(price, 10); !ok {
+		err = fmt.Errorf("parse price %q of order %s", price, order.OrderID)
+		return
+	}
+	if endPrice != nil {
+		if order.EndPrice, ok = new(big.Int).SetString(*endPrice, 10); !ok {
+			err = fmt.Errorf("parse end price %q of order %s", *endPrice, order.OrderID)
+			return
+		}
+	}
+	order.Duration = time.Duration(duration) * time.Second
+	if currencyID != nil {
+		order.Currency = rdb.currencyByID(*currencyID)
+	}
+	if expiry != nil {
+		order.Expiry = *expiry
+	}
+	return
+}
+
+// ApplyNFTOrderEvent applies @event to the order book, see ApplyNFTOrderEvent. It returns false if @event was discarded.
+func (rdb *RelDB) ApplyNFTOrderEvent(event dia.NFTOrderEvent) (bool, error) {
+	nftClassID, err := rdb.GetNFTClassID(event.NFT.NFTClass.Address, event.NFT.NFTClass.Blockchain)
+	if err != nil {
+		return false, err
+	}
+	nftID, err := rdb.GetNFTID(event.NFT.NFTClass.Address, event.NFT.NFTClass.Blockchain, event.NFT.TokenID)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := context.Background()
+	tx, err := rdb.postgresClient.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			log.Error("rollback order event: ", rollbackErr)
+		}
+	}()
+
+	query := fmt.Sprintf("SELECT %s FROM %s o INNER JOIN %s n ON o.nft_id=n.nft_id WHERE o.marketplace=$1 AND o.order_id=$2 FOR UPDATE OF o", nftOrderColumns, nftorderTable, nftTable)
+	order, err := rdb.scanNFTOrder(tx.QueryRow(ctx, query, event.Exchange, event.OrderID))
+	exists := true
+	if errors.Is(err, pgx.ErrNoRows) {
+		exists = false
+	} else if err != nil {
+		return false, err
+	}
+	order.NFT = event.NFT
+
+	order, applied, err := ApplyNFTOrderEvent(order, exists, event)
+	if err != nil || !applied {
+		return false, err
+	}
+
+	var currencyID string
+	if order.Currency.Address != "" || order.Currency.Blockchain != "" {
+		currencyID, err = rdb.GetAssetID(order.Currency)
+		if err != nil {
+			log.Error("get currency ID: ", err)
+		}
+	}
+	var endPrice *string
+	if order.EndPrice != nil {
+		s := order.EndPrice.String()
+		endPrice = &s
+	}
+	var expiry *time.Time
+	if !order.Expiry.IsZero() {
+		expiry = &order.Expiry
+	}
+	query = fmt.Sprintf(`INSERT INTO %s (nft_id,nftclass_id,marketplace,order_id,side,status,maker,price,end_price,duration,currency_id,expiry,order_time,update_time,blocknumber,blockposition,tx_hash)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,NULLIF($11,'')::uuid,$12,$13,$14,$15,$16,$17)
+	ON CONFLICT (marketplace,order_id) DO UPDATE SET nft_id=EXCLUDED.nft_id,nftclass_id=EXCLUDED.nftclass_id,side=EXCLUDED.side,status=EXCLUDED.status,
+	maker=EXCLUDED.maker,price=EXCLUDED.price,end_price=EXCLUDED.end_price,duration=EXCLUDED.duration,currency_id=EXCLUDED.currency_id,expiry=EXCLUDED.expiry,
+	order_time=EXCLUDED.order_time,update_time=EXCLUDED.update_time,blocknumber=EXCLUDED.blocknumber,blockposition=EXCLUDED.blockposition,tx_hash=EXCLUDED.tx_hash`, nftorderTable)
+	_, err = tx.Exec(ctx, query,
+		nftID,
+		nftClassID,
+		order.Exchange,
+		order.OrderID,
+		order.Side,
+		order.Status,
+		order.Maker,
+		order.Price.String(),
+		endPrice,
+		int64(order.Duration/time.Second),
+		currencyID,
+		expiry,
+		order.Time,
+		order.UpdateTime,
+		order.BlockNumber,
+		order.BlockPosition,
+		order.TxHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// GetNFTOrders returns the orders of the nft with @tokenID, most recently updated first.
+// If @status is not empty, only orders with this status are returned.
+func (rdb *RelDB) GetNFTOrders(address string, blockchain string, tokenID string, status string) (orders []NFTOrder, err error) {
+	nftClass, err := rdb.GetNFTClass(address, blockchain)
+	if err != nil {
+		return
+	}
+	nftID, err := rdb.GetNFTID(address, blockchain, tokenID)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s o INNER JOIN %s n ON o.nft_id=n.nft_id WHERE o.nft_id=$1 AND ($2='' OR o.status=$2) ORDER BY o.update_time DESC", nftOrderColumns, nftorderTable, nftTable)
+	return rdb.queryNFTOrders(nftClass, query, nftID, status)
+}
+
+// GetNFTOrder returns the order with @orderID on @marketplace if it is an order for an nft of @nftClass.
+func (rdb *RelDB) GetNFTOrder(nftClass dia.NFTClass, marketplace string, orderID string) (order NFTOrder, err error) {
+	nftClassID, err := rdb.GetNFTClassID(nftClass.Address, nftClass.Blockchain)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s o INNER JOIN %s n ON o.nft_id=n.nft_id WHERE o.marketplace=$1 AND o.order_id=$2 AND o.nftclass_id=$3", nftOrderColumns, nftorderTable, nftTable)
+	order, err = rdb.scanNFTOrder(rdb.postgresClient.QueryRow(context.Background(), query, marketplace, orderID, nftClassID))
+	order.NFT.NFTClass = nftClass
+	return
+}
+
+// GetNFTClassOpenOrders returns all orders of @nftClass which are placed, expired ones included.
+func (rdb *RelDB) GetNFTClassOpenOrders(nftClass dia.NFTClass) (orders []NFTOrder, err error) {
+	nftClassID, err := rdb.GetNFTClassID(nftClass.Address, nftClass.Blockchain)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s o INNER JOIN %s n ON o.nft_id=n.nft_id WHERE o.nftclass_id=$1 AND o.status=$2", nftOrderColumns, nftorderTable, nftTable)
+	return rdb.queryNFTOrders(nftClass, query, nftClassID, dia.NFTOrderPlaced)
+}
+
+func (rdb *RelDB) queryNFTOrders(nftClass dia.NFTClass, query string, args ...interface{}) (orders []NFTOrder, err error) {
+	rows, err := rdb.postgresClient.Query(context.Background(), query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order NFTOrder
+		order, err = rdb.scanNFTOrder(rows)
+		if err != nil {
+			return
+		}
+		order.NFT.NFTClass = nftClass
+		orders = append(orders, order)
+	}
+	err = rows.Err()
+	return
+}
+
+// ExpireNFTOrders marks all placed orders with expiry not after @timestamp as expired and returns their number.
+func (rdb *RelDB) ExpireNFTOrders(timestamp time.Time) (int64, error) {
+	query := fmt.Sprintf("UPDATE %s SET status=$1,update_time=expiry WHERE status=$2 AND expiry IS NOT NULL AND expiry<=$3", nftorderTable)
+	tag, err := rdb.postgresClient.Exec(context.Background(), query, dia.NFTOrderExpired, dia.NFTOrderPlaced, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
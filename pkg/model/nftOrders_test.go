@@ -0,0 +1,184 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func nftOrderEvent(side, eventType, orderID, maker string, price int64, blockNumber uint64) dia.NFTOrderEvent {
+	return dia.NFTOrderEvent{
+		NFT:         dia.NFT{TokenID: orderID},
+		Side:        side,
+		Type:        eventType,
+		OrderID:     orderID,
+		Maker:       maker,
+		Price:       big.NewInt(price),
+		BlockNumber: blockNumber,
+		Timestamp:   time.Unix(int64(1640995200+12*blockNumber), 0),
+		Exchange:    "CryptopunkMarket",
+	}
+}
+
+func TestApplyNFTOrderEvent(t *testing.T) {
+	placed := nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderPlaced, "bid-1", "0xa", 10, 100)
+
+	for _, c := range []struct {
+		name        string
+		events      []dia.NFTOrderEvent
+		wantStatus  string
+		wantMaker   string
+		wantApplied []bool
+	}{
+		{
+			name:        "placed",
+			events:      []dia.NFTOrderEvent{placed},
+			wantStatus:  dia.NFTOrderPlaced,
+			wantMaker:   "0xa",
+			wantApplied: []bool{true},
+		},
+		{
+			name:        "filled",
+			events:      []dia.NFTOrderEvent{placed, nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderFilled, "bid-1", "", 0, 101)},
+			wantStatus:  dia.NFTOrderFilled,
+			wantMaker:   "0xa",
+			wantApplied: []bool{true, true},
+		},
+		{
+			name:        "replaced by a later bid",
+			events:      []dia.NFTOrderEvent{placed, nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderPlaced, "bid-1", "0xb", 12, 101)},
+			wantStatus:  dia.NFTOrderPlaced,
+			wantMaker:   "0xb",
+			wantApplied: []bool{true, true},
+		},
+		{
+			name:        "cancellation of another maker",
+			events:      []dia.NFTOrderEvent{placed, nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderCancelled, "bid-1", "0xb", 0, 101)},
+			wantStatus:  dia.NFTOrderPlaced,
+			wantMaker:   "0xa",
+			wantApplied: []bool{true, false},
+		},
+		{
+			name:        "stale cancellation",
+			events:      []dia.NFTOrderEvent{placed, nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderCancelled, "bid-1", "0xa", 0, 99)},
+			wantStatus:  dia.NFTOrderPlaced,
+			wantMaker:   "0xa",
+			wantApplied: []bool{true, false},
+		},
+		{
+			name:        "duplicate placement",
+			events:      []dia.NFTOrderEvent{placed, placed},
+			wantStatus:  dia.NFTOrderPlaced,
+			wantMaker:   "0xa",
+			wantApplied: []bool{true, false},
+		},
+		{
+			name: "fill of a cancelled order",
+			events: []dia.NFTOrderEvent{
+				placed,
+				nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderCancelled, "bid-1", "", 0, 101),
+				nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderFilled, "bid-1", "", 0, 102),
+			},
+			wantStatus:  dia.NFTOrderCancelled,
+			wantMaker:   "0xa",
+			wantApplied: []bool{true, true, false},
+		},
+	} {
+		var order NFTOrder
+		exists := false
+		for i, event := range c.events {
+			var applied bool
+			var err error
+			order, applied, err = ApplyNFTOrderEvent(order, exists, event)
+			if err != nil {
+				t.Fatalf("%s: apply event %d: %v", c.name, i, err)
+			}
+			if applied != c.wantApplied[i] {
+				t.Errorf("%s: event %d applied was incorrect, got: %v, want: %v.", c.name, i, applied, c.wantApplied[i])
+			}
+			exists = exists || applied
+		}
+		if order.Status != c.wantStatus || order.Maker != c.wantMaker {
+			t.Errorf("%s: order was incorrect, got: %v %v, want: %v %v.", c.name, order.Status, order.Maker, c.wantStatus, c.wantMaker)
+		}
+	}
+
+	if _, applied, _ := ApplyNFTOrderEvent(NFTOrder{}, false, nftOrderEvent(dia.NFTOrderSideBid, dia.NFTOrderFilled, "bid-2", "", 0, 100)); applied {
+		t.Error("fill of unknown order was applied.")
+	}
+	if _, _, err := ApplyNFTOrderEvent(NFTOrder{}, false, nftOrderEvent("ask", dia.NFTOrderPlaced, "ask-1", "", 0, 100)); !errors.Is(err, ErrInvalidNFTOrderEvent) {
+		t.Errorf("event with unknown side was incorrect, got: %v, want: %v.", err, ErrInvalidNFTOrderEvent)
+	}
+}
+
+func TestNFTOrderCurrentPrice(t *testing.T) {
+	placed := time.Unix(1640995200, 0)
+	auction := NFTOrder{Price: big.NewInt(100), EndPrice: big.NewInt(20), Duration: 100 * time.Second, Time: placed}
+	for _, c := range []struct {
+		elapsed time.Duration
+		want    int64
+	}{
+		{-time.Second, 100},
+		{0, 100},
+		{25 * time.Second, 80},
+		{100 * time.Second, 20},
+		{time.Hour, 20},
+	} {
+		if got := auction.CurrentPrice(placed.Add(c.elapsed)); got.Int64() != c.want {
+			t.Errorf("price after %v was incorrect, got: %v, want: %v.", c.elapsed, got, c.want)
+		}
+	}
+	if got := (NFTOrder{Price: big.NewInt(7)}).CurrentPrice(placed); got.Int64() != 7 {
+		t.Errorf("price of simple order was incorrect, got: %v, want: %v.", got, 7)
+	}
+	usdcOrder := NFTOrder{Price: big.NewInt(1500000), Currency: dia.Asset{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}}
+	if got := usdcOrder.CurrentAmount(placed); got != 1.5 {
+		t.Errorf("amount was incorrect, got: %v, want: %v.", got, 1.5)
+	}
+}
+
+func TestComputeNFTBestBidOffer(t *testing.T) {
+	eth := dia.Asset{Symbol: "ETH", Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Decimals: 18}
+	weth := dia.Asset{Symbol: "WETH", Blockchain: dia.ETHEREUM, Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", Decimals: 18}
+	timestamp := time.Unix(1640995200, 0)
+	order := func(orderID, side string, price int64, currency dia.Asset, age time.Duration) NFTOrder {
+		return NFTOrder{OrderID: orderID, Side: side, Status: dia.NFTOrderPlaced, Price: big.NewInt(price), Currency: currency, Time: timestamp.Add(-age)}
+	}
+
+	orders := []NFTOrder{
+		order("bid-1", dia.NFTOrderSideBid, 10, eth, time.Hour),
+		order("bid-2", dia.NFTOrderSideBid, 12, eth, time.Hour),
+		// Same price as bid-2 but older.
+		order("bid-3", dia.NFTOrderSideBid, 12, eth, 2*time.Hour),
+		order("offer-1", dia.NFTOrderSideOffer, 30, eth, time.Hour),
+		// Dutch auction from 40 to 20 over 2 hours, at 25 now.
+		{OrderID: "offer-2", Side: dia.NFTOrderSideOffer, Status: dia.NFTOrderPlaced, Price: big.NewInt(40), EndPrice: big.NewInt(20), Duration: 2 * time.Hour, Currency: eth, Time: timestamp.Add(-90 * time.Minute)},
+		order("offer-3", dia.NFTOrderSideOffer, 5, eth, time.Hour),
+		order("bid-4", dia.NFTOrderSideBid, 11, weth, time.Hour),
+	}
+	// offer-3 is expired and bid-5 cancelled.
+	orders[5].Expiry = timestamp
+	cancelled := order("bid-5", dia.NFTOrderSideBid, 50, eth, time.Hour)
+	cancelled.Status = dia.NFTOrderCancelled
+	orders = append(orders, cancelled)
+
+	books := ComputeNFTBestBidOffer(dia.NFTClass{}, orders, timestamp)
+	if len(books) != 2 {
+		t.Fatalf("number of currencies was incorrect, got: %v, want: %v.", len(books), 2)
+	}
+	if books[0].Currency != eth || books[0].NumBids != 3 || books[0].NumOffers != 2 {
+		t.Errorf("book was incorrect, got: %v %v bids %v offers, want: %v %v bids %v offers.", books[0].Currency.Symbol, books[0].NumBids, books[0].NumOffers, "ETH", 3, 2)
+	}
+	if books[0].BestBid == nil || books[0].BestBid.OrderID != "bid-3" {
+		t.Errorf("best bid was incorrect, got: %v, want: %v.", books[0].BestBid, "bid-3")
+	}
+	if books[0].BestOffer == nil || books[0].BestOffer.OrderID != "offer-2" {
+		t.Errorf("best offer was incorrect, got: %v, want: %v.", books[0].BestOffer, "offer-2")
+	}
+	if books[1].Currency != weth || books[1].BestBid == nil || books[1].BestBid.OrderID != "bid-4" || books[1].BestOffer != nil {
+		t.Errorf("book was incorrect, got: %+v.", books[1])
+	}
+}
@@ -0,0 +1,333 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+const (
+	// NFTRarityInformationContent ranks nfts by the information content of their traits.
+	NFTRarityInformationContent = "informationContent"
+	// NFTRarityStatistical ranks nfts by the probability of their combination of traits.
+	NFTRarityStatistical = "statistical"
+
+	// nftTraitCountKey is the meta trait holding the number of traits of an nft.
+	nftTraitCountKey = "trait_count"
+)
+
+// nftMetadataKeys are top level attributes describing an nft rather than one of its traits.
+var nftMetadataKeys = map[string]bool{
+	"name":             true,
+	"description":      true,
+	"image":            true,
+	"image_url":        true,
+	"external_url":     true,
+	"animation_url":    true,
+	"background_color": true,
+	"uri":              true,
+	"id":               true,
+	"tokenid":          true,
+	"token_id":         true,
+}
+
+// NFTRarity holds the rarity scores of an nft within its collection. Rank 1 is the rarest nft.
+type NFTRarity struct {
+	NFT dia.NFT
+	// StatisticalRarity is the probability of the nft's combination of traits, the product of the
+	// frequencies of its traits. Lower is rarer.
+	StatisticalRarity float64
+	StatisticalRank   int
+	// InformationContent is the sum of -log2 of the frequencies of the nft's traits in bits. Higher is rarer.
+	InformationContent     float64
+	InformationContentRank int
+	TraitCount             int
+	Traits                 []NFTTraitRarity
+	Time                   time.Time
+}
+
+// NFTTraitRarity is a normalised trait of an nft and the number of nfts in the collection sharing it.
+// Missing is set for a trait the nft does not have, but other nfts of the collection do. Its Value is empty,
+// so that it is not confused with a trait value such as "none".
+type NFTTraitRarity struct {
+	Key       string
+	Value     string
+	Missing   bool `json:",omitempty"`
+	Count     int
+	Frequency float64
+}
+
+// nftTrait is the value of a trait of an nft, or the absence of the trait.
+type nftTrait struct {
+	value   string
+	missing bool
+}
+
+// ComputeNFTRarity returns the rarity of each of @nfts within the collection formed by @nfts, in the order of @nfts.
+// Traits are normalised by normaliseNFTTraits. An nft lacking a trait other nfts have holds the trait as missing,
+// and the number of traits of an nft is counted as additional trait "trait_count".
+func ComputeNFTRarity(nfts []dia.NFT, timestamp time.Time) []NFTRarity {
+	traits := make([]map[string]nftTrait, len(nfts))
+	keys := make(map[string]bool)
+	for i, nft := range nfts {
+		traits[i] = make(map[string]nftTrait)
+		for key, value := range normaliseNFTTraits(nft.Attributes) {
+			traits[i][key] = nftTrait{value: value}
+			keys[key] = true
+		}
+	}
+
+	counts := make(map[string]map[nftTrait]int)
+	traitCounts := make([]int, len(nfts))
+	for i := range traits {
+		traitCounts[i] = len(traits[i])
+		for key := range keys {
+			if _, ok := traits[i][key]; !ok {
+				traits[i][key] = nftTrait{missing: true}
+			}
+		}
+		traits[i][nftTraitCountKey] = nftTrait{value: fmt.Sprint(traitCounts[i])}
+		for key, trait := range traits[i] {
+			if counts[key] == nil {
+				counts[key] = make(map[nftTrait]int)
+			}
+			counts[key][trait]++
+		}
+	}
+
+	rarities := make([]NFTRarity, len(nfts))
+	for i, nft := range nfts {
+		rarity := NFTRarity{
+			NFT:               nft,
+			StatisticalRarity: 1,
+			TraitCount:        traitCounts[i],
+			Time:              timestamp,
+		}
+		for key, trait := range traits[i] {
+			count := counts[key][trait]
+			frequency := float64(count) / float64(len(nfts))
+			rarity.StatisticalRarity *= frequency
+			rarity.InformationContent -= math.Log2(frequency)
+			rarity.Traits = append(rarity.Traits, NFTTraitRarity{Key: key, Value: trait.value, Missing: trait.missing, Count: count, Frequency: frequency})
+		}
+		// Avoid negative zero for nfts sharing all traits.
+		rarity.InformationContent = math.Abs(rarity.InformationContent)
+		sort.Slice(rarity.Traits, func(a, b int) bool { return rarity.Traits[a].Key < rarity.Traits[b].Key })
+		rarities[i] = rarity
+	}
+
+	rankNFTRarities(rarities, func(r NFTRarity) float64 { return r.StatisticalRarity }, func(r *NFTRarity, rank int) { r.StatisticalRank = rank })
+	rankNFTRarities(rarities, func(r NFTRarity) float64 { return -r.InformationContent }, func(r *NFTRarity, rank int) { r.InformationContentRank = rank })
+	return rarities
+}
+
+// rankNFTRarities sets the rank of @rarities in ascending order of @score. Equal scores share their rank,
+// and the next rank is the number of rarer nfts plus one.
+func rankNFTRarities(rarities []NFTRarity, score func(NFTRarity) float64, setRank func(*NFTRarity, int)) {
+	order := make([]int, len(rarities))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return score(rarities[order[a]]) < score(rarities[order[b]]) })
+	rank := 0
+	for position, i := range order {
+		if position == 0 || !nftRarityScoresEqual(score(rarities[i]), score(rarities[order[position-1]])) {
+			rank = position + 1
+		}
+		setRank(&rarities[i], rank)
+	}
+}
+
+func nftRarityScoresEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// normaliseNFTTraits returns the traits in @attributes as lower case key value pairs. Traits are read from a list of
+// trait objects under "attributes" or "traits", as in the OpenSea metadata standard and the data of the OpenSea API
+// stored by the nft data scrapers. Otherwise all scalar top level attributes but descriptive metadata are traits.
+func normaliseNFTTraits(attributes dia.NFTAttributes) map[string]string {
+	traits := make(map[string]string)
+	for key, value := range attributes {
+		if k := nftTraitKey(key); k != "attributes" && k != "traits" {
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range list {
+			trait, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var traitKey, traitValue interface{}
+			for field, v := range trait {
+				switch nftTraitKey(field) {
+				case "trait_type", "traittype":
+					traitKey = v
+				case "value":
+					traitValue = v
+				}
+			}
+			if traitKey == nil {
+				continue
+			}
+			if value, ok := nftTraitValue(traitValue); ok {
+				traits[nftTraitKey(traitKey)] = value
+			}
+		}
+	}
+	if len(traits) > 0 {
+		return traits
+	}
+
+	for key, value := range attributes {
+		if nftMetadataKeys[nftTraitKey(key)] {
+			continue
+		}
+		if value, ok := nftTraitValue(value); ok {
+			traits[nftTraitKey(key)] = value
+		}
+	}
+	return traits
+}
+
+func nftTraitKey(key interface{}) string {
+	return strings.ToLower(strings.TrimSpace(fmt.Sprint(key)))
+}
+
+// nftTraitValue returns @value as normalised string if it is a non-empty scalar.
+func nftTraitValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.ToLower(strings.TrimSpace(v))
+		return v, v != ""
+	case float64, bool, int, int64:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// ErrUnknownNFTRarityMethod is returned for a ranking method other than NFTRarityInformationContent and NFTRarityStatistical.
+var ErrUnknownNFTRarityMethod = errors.New("unknown rarity method")
+
+// nftRarityRankColumn returns the column holding the rank of @method.
+func nftRarityRankColumn(method string) (string, error) {
+	switch method {
+	case NFTRarityInformationContent:
+		return "information_content_rank", nil
+	case NFTRarityStatistical:
+		return "statistical_rank", nil
+	default:
+		return "", ErrUnknownNFTRarityMethod
+	}
+}
+
+// ComputeNFTClassRarity returns the rarity of all nfts of @nftClass, see ComputeNFTRarity.
+func (rdb *RelDB) ComputeNFTClassRarity(nftClass dia.NFTClass, timestamp time.Time) ([]NFTRarity, error) {
+	nfts, err := rdb.GetNFTsByClass(nftClass)
+	if err != nil {
+		return nil, err
+	}
+	return ComputeNFTRarity(nfts, timestamp), nil
+}
+
+// SetNFTRarity stores @rarity, replacing the previous rarity of the nft.
+func (rdb *RelDB) SetNFTRarity(rarity NFTRarity) error {
+	nftID, err := rdb.GetNFTID(rarity.NFT.NFTClass.Address, rarity.NFT.NFTClass.Blockchain, rarity.NFT.TokenID)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (nft_id,statistical_rarity,statistical_rank,information_content,information_content_rank,trait_count,traits,compute_time)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+	ON CONFLICT (nft_id) DO UPDATE SET statistical_rarity=EXCLUDED.statistical_rarity,statistical_rank=EXCLUDED.statistical_rank,
+	information_content=EXCLUDED.information_content,information_content_rank=EXCLUDED.information_content_rank,
+	trait_count=EXCLUDED.trait_count,traits=EXCLUDED.traits,compute_time=EXCLUDED.compute_time`, nftrarityTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query,
+		nftID,
+		rarity.StatisticalRarity,
+		rarity.StatisticalRank,
+		rarity.InformationContent,
+		rarity.InformationContentRank,
+		rarity.TraitCount,
+		rarity.Traits,
+		rarity.Time,
+	)
+	return err
+}
+
+// GetNFTRarity returns the stored rarity of the nft given by @address, @blockchain and @tokenID.
+func (rdb *RelDB) GetNFTRarity(address string, blockchain string, tokenID string) (rarity NFTRarity, err error) {
+	rarity.NFT, err = rdb.GetNFT(address, blockchain, tokenID)
+	if err != nil {
+		return
+	}
+	nftID, err := rdb.GetNFTID(address, blockchain, tokenID)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf(`SELECT statistical_rarity,statistical_rank,information_content,information_content_rank,trait_count,traits,compute_time
+	FROM %s WHERE nft_id=$1`, nftrarityTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftID).Scan(
+		&rarity.StatisticalRarity,
+		&rarity.StatisticalRank,
+		&rarity.InformationContent,
+		&rarity.InformationContentRank,
+		&rarity.TraitCount,
+		&rarity.Traits,
+		&rarity.Time,
+	)
+	return
+}
+
+// GetNFTClassRarity returns the stored rarities of the nfts of @nftClass sorted from rarest to most common
+// by the rank of @method, skipping the first @offset nfts and returning at most @limit.
+// @total is the number of nfts of the class with stored rarity.
+func (rdb *RelDB) GetNFTClassRarity(nftClass dia.NFTClass, method string, limit, offset uint64) (rarities []NFTRarity, total int, err error) {
+	rankColumn, err := nftRarityRankColumn(method)
+	if err != nil {
+		return
+	}
+	nftClassID, err := rdb.GetNFTClassID(nftClass.Address, nftClass.Blockchain)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf(`SELECT n.token_id,n.uri,r.statistical_rarity,r.statistical_rank,r.information_content,r.information_content_rank,r.trait_count,r.traits,r.compute_time,count(*) OVER()
+	FROM %s r INNER JOIN %s n ON r.nft_id=n.nft_id
+	WHERE n.nftclass_id=$1
+	ORDER BY r.%s ASC, n.token_id ASC
+	LIMIT $2 OFFSET $3`, nftrarityTable, nftTable, rankColumn)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftClassID, limit, offset)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rarity := NFTRarity{NFT: dia.NFT{NFTClass: nftClass}}
+		err = rows.Scan(
+			&rarity.NFT.TokenID,
+			&rarity.NFT.URI,
+			&rarity.StatisticalRarity,
+			&rarity.StatisticalRank,
+			&rarity.InformationContent,
+			&rarity.InformationContentRank,
+			&rarity.TraitCount,
+			&rarity.Traits,
+			&rarity.Time,
+			&total,
+		)
+		if err != nil {
+			return
+		}
+		rarities = append(rarities, rarity)
+	}
+	return
+}
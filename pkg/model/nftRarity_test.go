@@ -0,0 +1,108 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestNormaliseNFTTraits(t *testing.T) {
+	cases := []struct {
+		attributes dia.NFTAttributes
+		want       map[string]string
+	}{
+		{
+			attributes: dia.NFTAttributes{"attributes": []interface{}{
+				map[string]interface{}{"trait_type": "Background", "value": " Blue"},
+				map[string]interface{}{"trait_type": "Level", "value": float64(3)},
+				map[string]interface{}{"value": "no trait type"},
+			}},
+			want: map[string]string{"background": "blue", "level": "3"},
+		},
+		{
+			// Traits of the OpenSea API as stored by the nft data scrapers.
+			attributes: dia.NFTAttributes{"Traits": []interface{}{
+				map[string]interface{}{"TraitType": "Accessory", "Value": "Earring", "TraitCount": float64(2459)},
+				map[string]interface{}{"TraitType": "type", "Value": ""},
+			}},
+			want: map[string]string{"accessory": "earring"},
+		},
+		{
+			attributes: dia.NFTAttributes{"Type": "Alien", "accessories": []interface{}{"cap"}, "genesis": true, "name": "Punk #1"},
+			want:       map[string]string{"type": "alien", "genesis": "true"},
+		},
+	}
+
+	for i, c := range cases {
+		traits := normaliseNFTTraits(c.attributes)
+		if len(traits) != len(c.want) {
+			t.Errorf("case %d: traits were incorrect, got: %v, want: %v.", i, traits, c.want)
+			continue
+		}
+		for key, value := range c.want {
+			if traits[key] != value {
+				t.Errorf("case %d: trait %s was incorrect, got: %v, want: %v.", i, key, traits[key], value)
+			}
+		}
+	}
+}
+
+func TestComputeNFTRarity(t *testing.T) {
+	nfts := []dia.NFT{
+		{TokenID: "1", Attributes: dia.NFTAttributes{"type": "alien", "hat": "cap"}},
+		{TokenID: "2", Attributes: dia.NFTAttributes{"type": "human", "hat": "cap"}},
+		{TokenID: "3", Attributes: dia.NFTAttributes{"type": "human"}},
+		{TokenID: "4", Attributes: dia.NFTAttributes{"type": "human"}},
+	}
+	rarities := ComputeNFTRarity(nfts, time.Unix(1640995200, 0))
+
+	cases := []struct {
+		statistical        float64
+		informationContent float64
+		statisticalRank    int
+		informationRank    int
+		traitCount         int
+	}{
+		// type 1/4 * hat 2/4 * trait_count 2/4
+		{statistical: 1.0 / 16, informationContent: 4, statisticalRank: 1, informationRank: 1, traitCount: 2},
+		// type 3/4 * hat 2/4 * trait_count 2/4
+		{statistical: 3.0 / 16, informationContent: 2 + math.Log2(4.0/3), statisticalRank: 2, informationRank: 2, traitCount: 2},
+		// type 3/4 * hat missing 2/4 * trait_count 2/4, the same for tokens 3 and 4
+		{statistical: 3.0 / 16, informationContent: 2 + math.Log2(4.0/3), statisticalRank: 2, informationRank: 2, traitCount: 1},
+		{statistical: 3.0 / 16, informationContent: 2 + math.Log2(4.0/3), statisticalRank: 2, informationRank: 2, traitCount: 1},
+	}
+	for i, c := range cases {
+		r := rarities[i]
+		if r.NFT.TokenID != nfts[i].TokenID || math.Abs(r.StatisticalRarity-c.statistical) > 1e-12 || math.Abs(r.InformationContent-c.informationContent) > 1e-12 {
+			t.Errorf("scores of token %s were incorrect, got: %v %v, want: %v %v.", nfts[i].TokenID, r.StatisticalRarity, r.InformationContent, c.statistical, c.informationContent)
+		}
+		if r.StatisticalRank != c.statisticalRank || r.InformationContentRank != c.informationRank || r.TraitCount != c.traitCount {
+			t.Errorf("ranks of token %s were incorrect, got: %v %v %v, want: %v %v %v.", nfts[i].TokenID, r.StatisticalRank, r.InformationContentRank, r.TraitCount, c.statisticalRank, c.informationRank, c.traitCount)
+		}
+		if len(r.Traits) != 3 || r.Traits[0].Key != "hat" || r.Traits[1].Key != nftTraitCountKey {
+			t.Errorf("traits of token %s were incorrect, got: %v.", nfts[i].TokenID, r.Traits)
+		}
+	}
+}
+
+func TestComputeNFTRarityNoneValue(t *testing.T) {
+	// A trait with value "None" is a trait of the nft, not the absence of the trait.
+	nfts := []dia.NFT{
+		{TokenID: "1", Attributes: dia.NFTAttributes{"type": "human", "hat": "None"}},
+		{TokenID: "2", Attributes: dia.NFTAttributes{"type": "human"}},
+	}
+	rarities := ComputeNFTRarity(nfts, time.Unix(1640995200, 0))
+	for i, want := range []NFTTraitRarity{
+		{Key: "hat", Value: "none", Count: 1, Frequency: 0.5},
+		{Key: "hat", Missing: true, Count: 1, Frequency: 0.5},
+	} {
+		if got := rarities[i].Traits[0]; got != want {
+			t.Errorf("hat of token %s was incorrect, got: %+v, want: %+v.", nfts[i].TokenID, got, want)
+		}
+	}
+	if rarities[0].TraitCount != 2 || rarities[1].TraitCount != 1 {
+		t.Errorf("trait counts were incorrect, got: %d %d, want: 2 1.", rarities[0].TraitCount, rarities[1].TraitCount)
+	}
+}
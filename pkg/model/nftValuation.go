@@ -0,0 +1,259 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// NFTValuationFloorWindow is the window of sales the collection floor of a valuation is computed from.
+	NFTValuationFloorWindow = 7 * 24 * time.Hour
+	// nftValuationStepBackLimit is the number of floor windows looked back for sales.
+	nftValuationStepBackLimit = 4
+	// nftValuationSaleLookback is the period in which the last sale of a token is taken into account.
+	nftValuationSaleLookback = 365 * 24 * time.Hour
+	// nftValuationSaleHalfLife is the age at which the last sale of a token has weight 0.5 in its valuation.
+	nftValuationSaleHalfLife = 30 * 24 * time.Hour
+	// nftValuationFloorSpread is the relative width of the confidence interval for a floor with zero confidence.
+	nftValuationFloorSpread = 0.5
+)
+
+// NFTValuation is the estimated fair value of a single nft in units of Currency, the currency of the collection floor.
+type NFTValuation struct {
+	NFT      dia.NFT
+	Value    float64
+	ValueUSD float64
+	// Lower and Upper bound the confidence interval of Value.
+	Lower      float64
+	Upper      float64
+	Currency   dia.Asset
+	Time       time.Time
+	Components NFTValuationComponents
+}
+
+// NFTValuationComponents are the inputs a valuation is combined from.
+type NFTValuationComponents struct {
+	Floor           float64
+	FloorConfidence float64
+	// RarityScore is the information content of the token's traits in bits, see ComputeNFTRarity. RarityMultiplier is the
+	// ratio of RarityScore to the mean score of the collection, but at least 1.
+	RarityScore      float64
+	RarityMultiplier float64
+	// LastSalePrice is the price per item of the token's last sale converted to units of Currency.
+	LastSalePrice  float64
+	LastSaleTime   time.Time
+	LastSaleWeight float64
+	NumSales       int
+}
+
+// AppraiseNFTs returns the valuations of @nfts at @timestamp. All @nfts belong to the collection with floor @floor,
+// @trades are the sales of the collection. The value of a token is the floor scaled by the rarity of its traits,
+// blended with its own last sale with a weight halving every 30 days. The value is at least the floor.
+// The confidence interval widens with low floor confidence, rarity premium and disagreement of the last sale.
+func AppraiseNFTs(nfts []dia.NFT, trades []dia.NFTTrade, floor NFTFloorEstimate, timestamp time.Time) []NFTValuation {
+	rarityScores := make(map[string]float64, len(nfts))
+	var meanScore float64
+	for _, rarity := range ComputeNFTRarity(nfts, timestamp) {
+		rarityScores[rarity.NFT.TokenID] = rarity.InformationContent
+		meanScore += rarity.InformationContent
+	}
+	if len(nfts) > 0 {
+		meanScore /= float64(len(nfts))
+	}
+
+	// The last sales per token. Sales in a bundle carry no price of the single token.
+	wash := flagNFTWashTrades(trades)
+	sales := make(map[string][]dia.NFTTrade)
+	for i, trade := range trades {
+		if wash[i] || isNFTBundleShare(trade) || trade.Price == nil || trade.Price.Sign() <= 0 || trade.Timestamp.After(timestamp) {
+			continue
+		}
+		sales[trade.NFT.TokenID] = append(sales[trade.NFT.TokenID], trade)
+	}
+
+	// USD price of one unit of the floor currency, used to convert sales in other currencies.
+	var floorRate float64
+	if floor.Floor > 0 {
+		floorRate = floor.FloorUSD / floor.Floor
+	}
+
+	valuations := make([]NFTValuation, len(nfts))
+	for i, nft := range nfts {
+		components := NFTValuationComponents{
+			Floor:            floor.Floor,
+			FloorConfidence:  floor.Confidence.Score,
+			RarityScore:      rarityScores[nft.TokenID],
+			RarityMultiplier: 1,
+		}
+		if meanScore > 0 && components.RarityScore > meanScore {
+			components.RarityMultiplier = components.RarityScore / meanScore
+		}
+		model := floor.Floor * components.RarityMultiplier
+
+		tokenSales := sales[nft.TokenID]
+		components.NumSales = len(tokenSales)
+		sort.Slice(tokenSales, func(a, b int) bool { return tokenSales[a].Timestamp.After(tokenSales[b].Timestamp) })
+		for _, sale := range tokenSales {
+			if nftCurrencyKey(sale.Currency) == nftCurrencyKey(floor.Currency) {
+				components.LastSalePrice = nftTradeAmount(sale)
+			} else if sale.PriceUSD > 0 && floorRate > 0 {
+				components.LastSalePrice = sale.PriceUSD / float64(nftTradeQuantity(sale)) / floorRate
+			} else {
+				continue
+			}
+			components.LastSaleTime = sale.Timestamp
+			components.LastSaleWeight = math.Pow(0.5, float64(timestamp.Sub(sale.Timestamp))/float64(nftValuationSaleHalfLife))
+			break
+		}
+
+		value := (1-components.LastSaleWeight)*model + components.LastSaleWeight*components.LastSalePrice
+		value = math.Max(value, floor.Floor)
+
+		spread := nftValuationFloorSpread*(1-floor.Confidence.Score) + (components.RarityMultiplier-1)/2
+		if model > 0 {
+			spread += components.LastSaleWeight * math.Abs(components.LastSalePrice-model) / model
+		}
+
+		valuations[i] = NFTValuation{
+			NFT:        nft,
+			Value:      value,
+			ValueUSD:   value * floorRate,
+			Lower:      math.Max(0, value*(1-spread)),
+			Upper:      value * (1 + spread),
+			Currency:   floor.Currency,
+			Time:       timestamp,
+			Components: components,
+		}
+	}
+	return valuations
+}
+
+// GetNFTsByClass returns all nfts of @nftClass.
+func (rdb *RelDB) GetNFTsByClass(nftClass dia.NFTClass) (nfts []dia.NFT, err error) {
+	address := nftClass.Address
+	if nftClass.Blockchain == dia.ETHEREUM {
+		address = common.HexToAddress(address).Hex()
+	}
+	query := fmt.Sprintf("SELECT n.token_id, n.creation_time, n.creator_address, n.uri, n.attributes FROM %s n INNER JOIN %s c ON(c.nftclass_id=n.nftclass_id AND c.address=$1 AND c.blockchain=$2)", nftTable, nftclassTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, address, nftClass.Blockchain)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		nft := dia.NFT{NFTClass: nftClass}
+		err = rows.Scan(
+			&nft.TokenID,
+			&nft.CreationTime,
+			&nft.CreatorAddress,
+			&nft.URI,
+			&nft.Attributes,
+		)
+		if err != nil {
+			return
+		}
+		nfts = append(nfts, nft)
+	}
+	return
+}
+
+// AppraiseNFTClass returns the valuations of all nfts of @nftClass at @timestamp, see AppraiseNFTs.
+func (rdb *RelDB) AppraiseNFTClass(nftClass dia.NFTClass, timestamp time.Time) ([]NFTValuation, error) {
+	floor, err := rdb.GetNFTFloorEstimate(nftClass, timestamp, NFTValuationFloorWindow, DefaultNFTFloorQuantile, nftValuationStepBackLimit)
+	if err != nil {
+		return nil, err
+	}
+	nfts, err := rdb.GetNFTsByClass(nftClass)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := rdb.GetNFTClassTrades(nftClass, timestamp.Add(-nftValuationSaleLookback), timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return AppraiseNFTs(nfts, trades, floor, timestamp), nil
+}
+
+// SetNFTValuation stores @valuation.
+func (rdb *RelDB) SetNFTValuation(valuation NFTValuation) error {
+	return rdb.SetNFTValuations([]NFTValuation{valuation})
+}
+
+// SetNFTValuations stores all @valuations in a single round trip. Nft and currency ids are resolved in
+// the insert, valuations of unknown nfts are skipped.
+func (rdb *RelDB) SetNFTValuations(valuations []NFTValuation) error {
+	if len(valuations) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (nft_id,valuation_time,value,value_usd,lower_bound,upper_bound,currency_id,components)
+	SELECT n.nft_id,$4::timestamp,$5::numeric,$6::numeric,$7::numeric,$8::numeric,
+	(SELECT asset_id FROM %s WHERE address=$9 AND blockchain=$10),$11::jsonb
+	FROM %s n INNER JOIN %s c ON n.nftclass_id=c.nftclass_id
+	WHERE c.address=$1 AND c.blockchain=$2 AND n.token_id=$3
+	ON CONFLICT (nft_id,valuation_time) DO NOTHING`, nftvaluationTable, assetTable, nftTable, nftclassTable)
+
+	batch := &pgx.Batch{}
+	for _, valuation := range valuations {
+		batch.Queue(query,
+			valuation.NFT.NFTClass.Address,
+			valuation.NFT.NFTClass.Blockchain,
+			valuation.NFT.TokenID,
+			valuation.Time,
+			valuation.Value,
+			valuation.ValueUSD,
+			valuation.Lower,
+			valuation.Upper,
+			valuation.Currency.Address,
+			valuation.Currency.Blockchain,
+			valuation.Components,
+		)
+	}
+	results := rdb.postgresClient.SendBatch(context.Background(), batch)
+	defer results.Close()
+	for range valuations {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNFTValuation returns the latest valuation of the nft given by @address, @blockchain and @tokenID
+// at or before @timestamp.
+func (rdb *RelDB) GetNFTValuation(address string, blockchain string, tokenID string, timestamp time.Time) (valuation NFTValuation, err error) {
+	valuation.NFT, err = rdb.GetNFT(address, blockchain, tokenID)
+	if err != nil {
+		return
+	}
+	nftID, err := rdb.GetNFTID(address, blockchain, tokenID)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf(`SELECT valuation_time,value,value_usd,lower_bound,upper_bound,currency_id::text,components
+	FROM %s WHERE nft_id=$1 AND valuation_time<=$2 ORDER BY valuation_time DESC LIMIT 1`, nftvaluationTable)
+	var currencyID *string
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftID, timestamp).Scan(
+		&valuation.Time,
+		&valuation.Value,
+		&valuation.ValueUSD,
+		&valuation.Lower,
+		&valuation.Upper,
+		&currencyID,
+		&valuation.Components,
+	)
+	if err != nil {
+		return
+	}
+	if currencyID != nil {
+		valuation.Currency = rdb.currencyByID(*currencyID)
+	}
+	return
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestAppraiseNFTs(t *testing.T) {
+	eth := dia.Asset{Symbol: "ETH", Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Decimals: 18}
+	ether := func(x int64) *big.Int { return new(big.Int).Mul(big.NewInt(x), big.NewInt(1e18)) }
+	timestamp := time.Unix(1640995200, 0)
+
+	// Token 1 holds the unique trait, the others share their traits.
+	nfts := []dia.NFT{
+		{TokenID: "1", Attributes: dia.NFTAttributes{"type": "alien"}},
+		{TokenID: "2", Attributes: dia.NFTAttributes{"type": "human"}},
+		{TokenID: "3", Attributes: dia.NFTAttributes{"type": "human"}},
+		{TokenID: "4", Attributes: dia.NFTAttributes{"type": "human"}},
+	}
+	floor := NFTFloorEstimate{Floor: 10, FloorUSD: 30000, Currency: eth, Confidence: NFTFloorConfidence{Score: 1}}
+
+	trades := []dia.NFTTrade{
+		// Token 2 was sold for 20 ETH one half-life ago, token 3 in a self trade.
+		nftSale("2", "0xa", "0xb", ether(20), 60000, eth, 0),
+		nftSale("3", "0xc", "0xc", ether(100), 300000, eth, 0),
+		// Token 4 was sold in a bundle, its share of the bundle price is no sale price.
+		nftSale("4", "0xd", "0xe", ether(100), 300000, eth, 0),
+	}
+	for i := range trades {
+		trades[i].Timestamp = timestamp.Add(-nftValuationSaleHalfLife)
+	}
+	trades[2].BundleID = "0xbundle"
+	trades[2].BundleSize = 3
+
+	valuations := AppraiseNFTs(nfts, trades, floor, timestamp)
+	if len(valuations) != len(nfts) {
+		t.Fatalf("number of valuations was incorrect, got: %v, want: %v.", len(valuations), len(nfts))
+	}
+
+	// Information content is 2 bits for the alien and log2(4/3) bits for humans.
+	meanScore := (2 + 3*math.Log2(4.0/3)) / 4
+	wantAlien := 10 * 2 / meanScore
+	if v := valuations[0]; math.Abs(v.Value-wantAlien) > 1e-9 || math.Abs(v.ValueUSD-3000*wantAlien) > 1e-6 || v.Lower >= v.Value || v.Upper <= v.Value {
+		t.Errorf("valuation of rare token was incorrect, got: %+v, want value: %v.", v, wantAlien)
+	}
+	if v := valuations[1]; math.Abs(v.Value-15) > 1e-9 || math.Abs(v.Components.LastSaleWeight-0.5) > 1e-9 || v.Components.NumSales != 1 {
+		t.Errorf("valuation of sold token was incorrect, got: %+v, want value: %v.", v, 15)
+	}
+	if v := valuations[2]; v.Value != 10 || v.Lower != 10 || v.Upper != 10 || v.Components.NumSales != 0 {
+		t.Errorf("valuation of token with wash trade was incorrect, got: %+v, want value: %v.", v, 10)
+	}
+	if v := valuations[3]; v.Value != 10 || v.Components.NumSales != 0 {
+		t.Errorf("valuation of token sold in a bundle was incorrect, got: %+v, want value: %v.", v, 10)
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
@@ -16,7 +16,10 @@ import (
 	"github.com/jackc/pgx/v4"
 )
 
-var currencyCache = make(map[string]dia.Asset)
+var (
+	currencyCache   = make(map[string]dia.Asset)
+	currencyCacheMu sync.RWMutex
+)
 
 // SetNFTClass stores @nftClass in postgres.
 func (rdb *RelDB) SetNFTClass(nftClass dia.NFTClass) error {
@@ -211,9 +214,9 @@ func (rdb *RelDB) GetNFTID(address string, blockchain string, tokenID string) (I
 
 // GetLastBlockheightTopshot returns the last block number before timestamp given by @upperBound.
 func (rdb *RelDB) GetLastBlockheightTopshot(upperBound time.Time) (uint64, error) {
-	query := fmt.Sprintf("SELECT attributes FROM %s WHERE nftclass_id=(select nftclass_id FROM %s WHERE address='0x0b2a3299cc857e29' AND blockchain='Flow') ORDER BY creation_time DESC LIMIT 1;", nftTable, nftclassTable)
+	query := fmt.Sprintf("SELECT attributes FROM %s WHERE nftclass_id=(select nftclass_id FROM %s WHERE address=$1 AND blockchain=$2) ORDER BY creation_time DESC LIMIT 1;", nftTable, nftclassTable)
 	attributes := make(map[string]interface{})
-	err := rdb.postgresClient.QueryRow(context.Background(), query).Scan(&attributes)
+	err := rdb.postgresClient.QueryRow(context.Background(), query, "0x0b2a3299cc857e29", dia.FLOW).Scan(&attributes)
 	if err != nil {
 		return 0, err
 	}
@@ -228,6 +231,9 @@ func (rdb *RelDB) SetNFTTrade(trade dia.NFTTrade) error {
 
 //  SetNFTTradeToTable  stores into @table.
 func (rdb *RelDB) SetNFTTradeToTable(trade dia.NFTTrade, table string) error {
+	if err := checkTable(table, nftTradeTables); err != nil {
+		return err
+	}
 	nftclassID, err := rdb.GetNFTClassID(trade.NFT.NFTClass.Address, trade.NFT.NFTClass.Blockchain)
 	if err != nil {
 		return err
@@ -241,9 +247,21 @@ func (rdb *RelDB) SetNFTTradeToTable(trade dia.NFTTrade, table string) error {
 		log.Error("get currency ID: ", err)
 	}
 	price := trade.Price.String()
-	tradeVars := "nftclass_id,nft_id,price,price_usd,transfer_from,transfer_to,currency_id,block_number,trade_time,tx_hash,marketplace"
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)", table, tradeVars)
-	_, err = rdb.postgresClient.Exec(context.Background(), query, nftclassID, nftID, price, trade.PriceUSD, trade.FromAddress, trade.ToAddress, currencyID, trade.BlockNumber, trade.Timestamp, trade.TxHash, trade.Exchange)
+	quantity := trade.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	bundleSize := trade.BundleSize
+	if bundleSize == 0 {
+		bundleSize = 1
+	}
+	var bundleID sql.NullString
+	if trade.BundleID != "" {
+		bundleID = sql.NullString{String: trade.BundleID, Valid: true}
+	}
+	tradeVars := "nftclass_id,nft_id,price,price_usd,transfer_from,transfer_to,currency_id,block_number,trade_time,tx_hash,marketplace,quantity,bundle_id,bundle_size"
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)", table, tradeVars)
+	_, err = rdb.postgresClient.Exec(context.Background(), query, nftclassID, nftID, price, trade.PriceUSD, trade.FromAddress, trade.ToAddress, currencyID, trade.BlockNumber, trade.Timestamp, trade.TxHash, trade.Exchange, quantity, bundleID, bundleSize)
 	if err != nil {
 		return err
 	}
@@ -252,8 +270,8 @@ func (rdb *RelDB) SetNFTTradeToTable(trade dia.NFTTrade, table string) error {
 
 // GetLastBlockNFTTtrade returns the last blocknumber that was scraped for trades in @nftclass.
 func (rdb *RelDB) GetLastBlockNFTTrade(nftclass dia.NFTClass) (blocknumber uint64, err error) {
-	query := fmt.Sprintf("SELECT block_number FROM %s WHERE nftclass_id=(SELECT nftclass_id FROM %s WHERE address='%s' AND blockchain='%s') ORDER BY block_number DESC LIMIT 1;", NfttradeCurrTable, nftclassTable, nftclass.Address, nftclass.Blockchain)
-	err = rdb.postgresClient.QueryRow(context.Background(), query).Scan(&blocknumber)
+	query := fmt.Sprintf("SELECT block_number FROM %s WHERE nftclass_id=(SELECT nftclass_id FROM %s WHERE address=$1 AND blockchain=$2) ORDER BY block_number DESC LIMIT 1;", NfttradeCurrTable, nftclassTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftclass.Address, nftclass.Blockchain).Scan(&blocknumber)
 	if err != nil {
 		return
 	}
@@ -263,24 +281,26 @@ func (rdb *RelDB) GetLastBlockNFTTrade(nftclass dia.NFTClass) (blocknumber uint6
 // GetNFTTrades returns all trades done on the nft given by @address, @blockchain and @tokenID.
 func (rdb *RelDB) GetNFTTradesFromTable(address string, blockchain string, tokenID string, starttime time.Time, endtime time.Time, table string) (trades []dia.NFTTrade, err error) {
 	var rows pgx.Rows
+	if err = checkTable(table, nftTradeTables); err != nil {
+		return
+	}
 	nftID, err := rdb.GetNFTID(address, blockchain, tokenID)
 	if err != nil {
 		return
 	}
-	tradeVars := "price,price_usd,transfer_from,transfer_to,currency_id,block_number,trade_time,tx_hash,marketplace"
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id='%s' AND trade_time>to_timestamp(%v) AND trade_time<to_timestamp(%v) ORDER BY trade_time DESC", tradeVars, table, nftID, starttime.Unix(), endtime.Unix())
-	rows, err = rdb.postgresClient.Query(context.Background(), query)
+	tradeVars := "price,price_usd,transfer_from,transfer_to,currency_id,block_number,trade_time,tx_hash,marketplace,quantity,bundle_id,bundle_size"
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id=$1 AND trade_time>to_timestamp($2) AND trade_time<to_timestamp($3) ORDER BY trade_time DESC", tradeVars, table)
+	rows, err = rdb.postgresClient.Query(context.Background(), query, nftID, starttime.Unix(), endtime.Unix())
 	if err != nil {
 		return
 	}
 	defer rows.Close()
 
-	log.Info("query: ", query)
-
 	for rows.Next() {
 		var trade dia.NFTTrade
 		var price string
 		var currencyID sql.NullString
+		var bundle nftTradeBundle
 		err := rows.Scan(
 			&price,
 			&trade.PriceUSD,
@@ -291,10 +311,14 @@ func (rdb *RelDB) GetNFTTradesFromTable(address string, blockchain string, token
 			&trade.Timestamp,
 			&trade.TxHash,
 			&trade.Exchange,
+			&bundle.quantity,
+			&bundle.bundleID,
+			&bundle.bundleSize,
 		)
 		if err != nil {
 			return []dia.NFTTrade{}, err
 		}
+		bundle.assignTo(&trade)
 		n := new(big.Int)
 		n, ok := n.SetString(price, 10)
 		if !ok {
@@ -303,16 +327,7 @@ func (rdb *RelDB) GetNFTTradesFromTable(address string, blockchain string, token
 		trade.Price = n
 
 		if currencyID.Valid {
-			if asset, ok := currencyCache[currencyID.String]; ok {
-				trade.Currency = asset
-			} else {
-				asset, err := rdb.GetAssetByID(currencyID.String)
-				if err != nil {
-					log.Errorf("cannot fetch asset with postgres id %s", currencyID.String)
-				}
-				trade.Currency = asset
-				currencyCache[currencyID.String] = asset
-			}
+			trade.Currency = rdb.currencyByID(currencyID.String)
 		}
 
 		trades = append(trades, trade)
@@ -320,89 +335,119 @@ func (rdb *RelDB) GetNFTTradesFromTable(address string, blockchain string, token
 	return
 }
 
+// nftTradeBundle holds the nullable quantity and bundle columns of a trade row.
+type nftTradeBundle struct {
+	quantity   sql.NullInt64
+	bundleID   sql.NullString
+	bundleSize sql.NullInt64
+}
+
+// assignTo sets quantity and bundle fields of @trade, defaulting to a single item sold on its own.
+func (b nftTradeBundle) assignTo(trade *dia.NFTTrade) {
+	trade.Quantity = 1
+	if b.quantity.Valid && b.quantity.Int64 > 0 {
+		trade.Quantity = uint64(b.quantity.Int64)
+	}
+	trade.BundleSize = 1
+	if b.bundleSize.Valid && b.bundleSize.Int64 > 0 {
+		trade.BundleSize = int(b.bundleSize.Int64)
+	}
+	trade.BundleID = b.bundleID.String
+}
+
 func (rdb *RelDB) GetNFTTrades(address string, blockchain string, tokenID string) (trades []dia.NFTTrade, err error) {
 	return rdb.GetNFTTradesFromTable(address, blockchain, tokenID, time.Time{}, time.Now(), NfttradeCurrTable)
 }
 
-// GetNFTFloor returns the floor price of @nftclass w.r.t. the last 24h.
-func (rdb *RelDB) GetNFTFloor(nftclass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration) (floor float64, err error) {
-	query := fmt.Sprintf("SELECT min(tr.price::numeric) FROM %s tr INNER JOIN %s n ON tr.nftclass_id=n.nftclass_id WHERE tr.trade_time<=to_timestamp(%d) AND tr.trade_time>to_timestamp(%d) AND tr.price::numeric>0 AND n.address='%s' and n.blockchain='%s'",
-		NfttradeCurrTable,
-		nftclassTable,
-		timestamp.Unix(),
-		timestamp.Add(-floorWindowSeconds).Unix(),
-		nftclass.Address,
-		nftclass.Blockchain,
-	)
-
-	var floorFloat sql.NullFloat64
-	err = rdb.postgresClient.QueryRow(context.Background(), query).Scan(&floorFloat)
+// GetNFTFloor returns the floor price of @nftclass in the window of length @floorWindowSeconds ending at @timestamp
+// in units of @currency. See EstimateNFTFloor for details.
+func (rdb *RelDB) GetNFTFloor(nftclass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration) (floor float64, currency dia.Asset, err error) {
+	estimate, err := rdb.GetNFTFloorEstimate(nftclass, timestamp, floorWindowSeconds, DefaultNFTFloorQuantile, 1)
 	if err != nil {
 		return
 	}
-
-	// var f *big.Float
-	if floorFloat.Valid {
-		// f = big.NewFloat(0).SetFloat64(floorFloat.Float64)
-		floor, _ = new(big.Float).Quo(big.NewFloat(0).SetFloat64(floorFloat.Float64), new(big.Float).SetFloat64(math.Pow10(18))).Float64()
-	} else {
-		err = errors.New("no result in given time-range")
-		return
-	}
-
-	return
+	return estimate.Floor, estimate.Currency, nil
 }
 
-// GetNFTFloorRecursive returns the floor price of @nftclass. If necessary, it iterates back in time until it finds a floor price.
-func (rdb *RelDB) GetNFTFloorRecursive(nftClass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (floor float64, err error) {
-	count := 0
-	foundFloor := false
-
-	for !foundFloor && count < stepBackLimit {
-		floor, err = rdb.GetNFTFloor(nftClass, timestamp, floorWindowSeconds)
-		if err != nil {
-			if strings.Contains(err.Error(), "no result") {
-				count++
-				timestamp = timestamp.Add(-floorWindowSeconds)
-				continue
-			} else {
-				return
-			}
-		} else {
-			foundFloor = true
-		}
+// GetNFTFloorRecursive returns the floor price of @nftclass in units of @currency. If necessary, it iterates back
+// in time until it finds a floor price.
+func (rdb *RelDB) GetNFTFloorRecursive(nftClass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (floor float64, currency dia.Asset, err error) {
+	estimate, err := rdb.GetNFTFloorEstimate(nftClass, timestamp, floorWindowSeconds, DefaultNFTFloorQuantile, stepBackLimit)
+	if err != nil {
+		return
 	}
-	return
+	return estimate.Floor, estimate.Currency, nil
 }
 
-// GetNFTFloorRange returns a slice of floor prices in the given time range @starttime -- @endtime.
-func (rdb *RelDB) GetNFTFloorRange(nftClass dia.NFTClass, starttime time.Time, endtime time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (floorPrices []float64, err error) {
+// GetNFTFloorRange returns a slice of floor prices in the given time range @starttime -- @endtime, all in units
+// of @currency. See NFTFloorsInCurrency for how floors of windows with a different majority currency are converted.
+func (rdb *RelDB) GetNFTFloorRange(nftClass dia.NFTClass, starttime time.Time, endtime time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (floorPrices []float64, currency dia.Asset, err error) {
+	var estimates []NFTFloorEstimate
 
 	// Find initial floor price by going back in time if necessary.
-	floor, err := rdb.GetNFTFloorRecursive(nftClass, starttime, floorWindowSeconds, stepBackLimit)
+	estimate, err := rdb.GetNFTFloorEstimate(nftClass, starttime, floorWindowSeconds, DefaultNFTFloorQuantile, stepBackLimit)
 	if err != nil {
-		if strings.Contains(err.Error(), "no result") {
+		if errors.Is(err, ErrNoNFTFloor) {
 			log.Warn("could not find initial floor price.")
 		} else {
 			return
 		}
 	}
-	floorPrices = append(floorPrices, floor)
+	estimates = append(estimates, estimate)
 	starttime = starttime.Add(floorWindowSeconds)
 
 	// Continue filling floor prices. If none is found add the last one.
 	for starttime.Before(endtime) {
-		floor, err := rdb.GetNFTFloor(nftClass, starttime, floorWindowSeconds)
+		estimate, err := rdb.GetNFTFloorEstimate(nftClass, starttime, floorWindowSeconds, DefaultNFTFloorQuantile, 1)
 		if err != nil {
-			if len(floorPrices) > 0 {
-				floorPrices = append(floorPrices, floorPrices[len(floorPrices)-1])
-			}
+			estimates = append(estimates, estimates[len(estimates)-1])
 		} else {
-			floorPrices = append(floorPrices, floor)
+			estimates = append(estimates, estimate)
 		}
 		starttime = starttime.Add(floorWindowSeconds)
 	}
 
+	return NFTFloorsInCurrency(estimates)
+}
+
+// NFTFloorsInCurrency returns the floors of @estimates in a single currency, the currency most estimates are in.
+// Floors in other currencies are converted with the USD prices of both currencies known from the estimates.
+// Estimates without floor, i.e. with zero Floor, are kept as zero. ErrNFTFloorCurrencies is returned if a floor
+// cannot be converted.
+func NFTFloorsInCurrency(estimates []NFTFloorEstimate) (floors []float64, currency dia.Asset, err error) {
+	counts := make(map[string]int)
+	currencies := make(map[string]dia.Asset)
+	usdRates := make(map[string][]float64)
+	var reference string
+	for _, estimate := range estimates {
+		if estimate.Floor <= 0 {
+			continue
+		}
+		key := nftCurrencyKey(estimate.Currency)
+		currencies[key] = estimate.Currency
+		counts[key]++
+		if estimate.FloorUSD > 0 {
+			usdRates[key] = append(usdRates[key], estimate.FloorUSD/estimate.Floor)
+		}
+		if counts[key] > counts[reference] || (counts[key] == counts[reference] && key < reference) {
+			reference = key
+		}
+	}
+	currency = currencies[reference]
+
+	floors = make([]float64, len(estimates))
+	for i, estimate := range estimates {
+		key := nftCurrencyKey(estimate.Currency)
+		if estimate.Floor <= 0 || key == reference {
+			floors[i] = estimate.Floor
+			continue
+		}
+		if len(usdRates[key]) == 0 || len(usdRates[reference]) == 0 {
+			err = fmt.Errorf("%w: %s and %s", ErrNFTFloorCurrencies, estimate.Currency.Symbol, currency.Symbol)
+			return
+		}
+		floors[i] = estimate.Floor * median(usdRates[key]) / median(usdRates[reference])
+	}
 	return
 }
 
@@ -411,8 +456,8 @@ func (rdb *RelDB) GetNFTOffers(address string, blockchain string, tokenID string
 	var rows pgx.Rows
 	nftID, err := rdb.GetNFTID(address, blockchain, tokenID)
 	tradeVars := "start_value,end_value,duration,from_address,auction_type,currency_symbol,currency_address,currency_decimals,blocknumber,offer_time,tx_hash,marketplace"
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id='%s' ORDER BY offer_time DESC", tradeVars, nftofferTable, nftID)
-	rows, err = rdb.postgresClient.Query(context.Background(), query)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id=$1 ORDER BY offer_time DESC", tradeVars, nftofferTable)
+	rows, err = rdb.postgresClient.Query(context.Background(), query, nftID)
 	if err != nil {
 		return
 	}
@@ -472,8 +517,8 @@ func (rdb *RelDB) GetNFTBids(address string, blockchain string, tokenID string)
 	var rows pgx.Rows
 	nftID, err := rdb.GetNFTID(address, blockchain, tokenID)
 	tradeVars := "bid_value,from_address,currency_symbol,currency_address,currency_decimals,blocknumber,bid_time,tx_hash,marketplace"
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id='%s' ORDER BY bid_time DESC", tradeVars, nftbidTable, nftID)
-	rows, err = rdb.postgresClient.Query(context.Background(), query)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id=$1 ORDER BY bid_time DESC", tradeVars, nftbidTable)
+	rows, err = rdb.postgresClient.Query(context.Background(), query, nftID)
 	if err != nil {
 		return
 	}
@@ -556,14 +601,14 @@ func (rdb *RelDB) GetLastNFTBid(address string, blockchain string, tokenID strin
 	nftBid.NFT.TokenID = tokenID
 
 	// First fetch biggest blocknumber<=@blockNumber for given nft.
-	subquery := fmt.Sprintf("SELECT blocknumber FROM %s WHERE nft_id='%s' AND blocknumber<=%d ORDER BY blocknumber DESC LIMIT 1", nftbidTable, nftID, blockNumber)
+	subquery := fmt.Sprintf("SELECT blocknumber FROM %s WHERE nft_id=$1 AND blocknumber<=$2 ORDER BY blocknumber DESC LIMIT 1", nftbidTable)
 	// Next, restrict to largest blockPosition in this block.
 	returnVars := "bid_value,from_address,currency_symbol,currency_address,currency_decimals,blocknumber,blockposition,bid_time,tx_hash,marketplace"
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id='%s' AND blocknumber=(%s) ORDER BY blockposition DESC LIMIT 1", returnVars, nftbidTable, nftID, subquery)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id=$1 AND blocknumber=(%s) ORDER BY blockposition DESC LIMIT 1", returnVars, nftbidTable, subquery)
 	var txHash sql.NullString
 	var bidTime sql.NullTime
 	var value string
-	err = rdb.postgresClient.QueryRow(context.Background(), query).Scan(
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftID, blockNumber).Scan(
 		&value,
 		&nftBid.FromAddress,
 		&nftBid.CurrencySymbol,
@@ -595,9 +640,8 @@ func (rdb *RelDB) GetLastNFTBid(address string, blockchain string, tokenID strin
 
 // GetLastBlockNFTBid returns the last blocknumber that was scraped for bids in @nftclass.
 func (rdb *RelDB) GetLastBlockNFTBid(nftclass dia.NFTClass) (blocknumber uint64, err error) {
-	query := fmt.Sprintf("SELECT b.blocknumber FROM %s b INNER JOIN %s n ON b.nft_id=n.nft_id INNER JOIN %s c ON(n.nftclass_id=c.nftclass_id AND c.address='%s' and c.blockchain='%s') ORDER BY b.blocknumber DESC LIMIT 1;", nftbidTable, nftTable, nftclassTable, nftclass.Address, nftclass.Blockchain)
-	log.Info("query: ", query)
-	err = rdb.postgresClient.QueryRow(context.Background(), query).Scan(&blocknumber)
+	query := fmt.Sprintf("SELECT b.blocknumber FROM %s b INNER JOIN %s n ON b.nft_id=n.nft_id INNER JOIN %s c ON(n.nftclass_id=c.nftclass_id AND c.address=$1 and c.blockchain=$2) ORDER BY b.blocknumber DESC LIMIT 1;", nftbidTable, nftTable, nftclassTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftclass.Address, nftclass.Blockchain).Scan(&blocknumber)
 	if err != nil {
 		return
 	}
@@ -606,8 +650,8 @@ func (rdb *RelDB) GetLastBlockNFTBid(nftclass dia.NFTClass) (blocknumber uint64,
 
 // GetLastBlockNFTOffer returns the last blocknumber that was scraped for offers in @nftclass.
 func (rdb *RelDB) GetLastBlockNFTOffer(nftclass dia.NFTClass) (blocknumber uint64, err error) {
-	query := fmt.Sprintf("SELECT b.blocknumber FROM %s b INNER JOIN %s n ON b.nft_id=n.nft_id INNER JOIN %s c ON(n.nftclass_id=c.nftclass_id AND c.address='%s' and c.blockchain='%s') ORDER BY b.blocknumber DESC LIMIT 1;", nftofferTable, nftTable, nftclassTable, nftclass.Address, nftclass.Blockchain)
-	err = rdb.postgresClient.QueryRow(context.Background(), query).Scan(&blocknumber)
+	query := fmt.Sprintf("SELECT b.blocknumber FROM %s b INNER JOIN %s n ON b.nft_id=n.nft_id INNER JOIN %s c ON(n.nftclass_id=c.nftclass_id AND c.address=$1 and c.blockchain=$2) ORDER BY b.blocknumber DESC LIMIT 1;", nftofferTable, nftTable, nftclassTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftclass.Address, nftclass.Blockchain).Scan(&blocknumber)
 	if err != nil {
 		return
 	}
@@ -659,15 +703,15 @@ func (rdb *RelDB) GetLastNFTOffer(address string, blockchain string, tokenID str
 	offer.NFT.TokenID = tokenID
 
 	// First fetch biggest blocknumber<=@blockNumber for given nft.
-	subquery := fmt.Sprintf("SELECT blocknumber FROM %s WHERE nft_id='%s' AND blocknumber<=%d ORDER BY blocknumber DESC LIMIT 1", nftofferTable, nftID, blockNumber)
+	subquery := fmt.Sprintf("SELECT blocknumber FROM %s WHERE nft_id=$1 AND blocknumber<=$2 ORDER BY blocknumber DESC LIMIT 1", nftofferTable)
 	// Next, restrict to largest blockPosition in this block.
 	returnVars := "start_value,end_value,duration,from_address,auction_type,currency_symbol,currency_address,currency_decimals,blocknumber,blockposition,offer_time,tx_hash,marketplace"
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id='%s' AND blocknumber=(%s) ORDER BY blockposition DESC LIMIT 1", returnVars, nftofferTable, nftID, subquery)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE nft_id=$1 AND blocknumber=(%s) ORDER BY blockposition DESC LIMIT 1", returnVars, nftofferTable, subquery)
 	var txHash sql.NullString
 	var offerTime sql.NullTime
 	var startValue string
 	var endValue string
-	err = rdb.postgresClient.QueryRow(context.Background(), query).Scan(
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftID, blockNumber).Scan(
 		&startValue,
 		&endValue,
 		&offer.Duration,
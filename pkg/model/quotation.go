@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/go-redis/redis"
+	influxModels "github.com/influxdata/influxdb1-client/models"
 	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
 )
 
@@ -254,6 +256,312 @@ func (datastore *DB) GetSortedAssetQuotations(assets []dia.Asset) ([]AssetQuotat
 	return quotationsSorted, nil
 }
 
+// GetAssetQuotationsLatest returns the latest quotations of all assets in @assets along with their
+// price a day ago. Quotations are fetched from the cache in a single round trip. Assets missing in the
+// cache and the prices a day ago are looked up with a single influx request. The result is in the
+// order of @assets and omits assets without quotation.
+func (datastore *DB) GetAssetQuotationsLatest(assets []dia.Asset) ([]AssetQuotationFull, error) {
+	cached, err := datastore.GetAssetQuotationsCache(assets)
+	if err != nil {
+		log.Error("get asset quotations from cache: ", err)
+		cached = make([]*AssetQuotation, len(assets))
+	}
+
+	var missing []dia.Asset
+	for i, quotation := range cached {
+		if quotation == nil {
+			missing = append(missing, assets[i])
+		}
+	}
+	now := time.Now()
+	historical, err := datastore.getAssetQuotationsInflux(
+		assetQuotationsSelect{assets: missing, timestamp: now},
+		assetQuotationsSelect{assets: assets, timestamp: now.AddDate(0, 0, -1)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotations []AssetQuotationFull
+	for i, asset := range assets {
+		key := getKeyAssetQuotation(asset.Blockchain, asset.Address)
+		quotation := cached[i]
+		if quotation == nil {
+			quotation = historical[0][key]
+		}
+		if quotation != nil {
+			quotations = append(quotations, assetQuotationFull(*quotation, historical[1][key]))
+		}
+	}
+	return quotations, nil
+}
+
+// GetAssetQuotations returns the latest quotations before @timestamp of all assets in @assets along
+// with their price a day before @timestamp using a single influx request. The result is in the order
+// of @assets and omits assets without quotation.
+func (datastore *DB) GetAssetQuotations(assets []dia.Asset, timestamp time.Time) ([]AssetQuotationFull, error) {
+	historical, err := datastore.getAssetQuotationsInflux(
+		assetQuotationsSelect{assets: assets, timestamp: timestamp},
+		assetQuotationsSelect{assets: assets, timestamp: timestamp.AddDate(0, 0, -1)},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var quotations []AssetQuotationFull
+	for _, asset := range assets {
+		key := getKeyAssetQuotation(asset.Blockchain, asset.Address)
+		if quotation, ok := historical[0][key]; ok {
+			quotations = append(quotations, assetQuotationFull(*quotation, historical[1][key]))
+		}
+	}
+	return quotations, nil
+}
+
+// assetQuotationFull extends @quotation by the price of @yesterday, which may be nil.
+func assetQuotationFull(quotation AssetQuotation, yesterday *AssetQuotation) AssetQuotationFull {
+	full := AssetQuotationFull{
+		Symbol:     quotation.Asset.Symbol,
+		Name:       quotation.Asset.Name,
+		Address:    quotation.Asset.Address,
+		Blockchain: quotation.Asset.Blockchain,
+		Price:      quotation.Price,
+		Time:       quotation.Time,
+		Source:     quotation.Source,
+	}
+	if yesterday != nil {
+		full.PriceYesterday = yesterday.Price
+	}
+	return full
+}
+
+// GetAssetQuotationsCache returns the cached quotations of all assets in @assets using a single
+// MGET. The i-th entry of the result is nil if there is no quotation for the i-th asset.
+func (datastore *DB) GetAssetQuotationsCache(assets []dia.Asset) ([]*AssetQuotation, error) {
+	quotations := make([]*AssetQuotation, len(assets))
+	if len(assets) == 0 {
+		return quotations, nil
+	}
+	keys := make([]string, len(assets))
+	for i, asset := range assets {
+		keys[i] = getKeyAssetQuotation(asset.Blockchain, asset.Address)
+	}
+	values, err := datastore.redisClient.MGet(keys...).Result()
+	if err != nil {
+		return quotations, err
+	}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		quotation := &AssetQuotation{}
+		if err := quotation.UnmarshalBinary([]byte(data)); err != nil {
+			log.Errorf("unmarshal cached quotation %s: %v", keys[i], err)
+			continue
+		}
+		quotations[i] = quotation
+	}
+	return quotations, nil
+}
+
+// assetQuotationLookback bounds the time range scanned for the latest quotation of an asset.
+// Assets without quotation in this range are considered to have none.
+const assetQuotationLookback = 7 * 24 * time.Hour
+
+// assetQuotationsSelect selects the latest quotations before @timestamp of all assets in @assets.
+type assetQuotationsSelect struct {
+	assets    []dia.Asset
+	timestamp time.Time
+}
+
+// getAssetQuotationsInflux runs all @selects in a single influx request. For each select it returns
+// the selected quotations mapped by their cache key.
+func (datastore *DB) getAssetQuotationsInflux(selects ...assetQuotationsSelect) ([]map[string]*AssetQuotation, error) {
+	quotations := make([]map[string]*AssetQuotation, len(selects))
+	var statements []string
+	var statementSelects []int
+	for i, sel := range selects {
+		quotations[i] = make(map[string]*AssetQuotation)
+		if len(sel.assets) == 0 {
+			continue
+		}
+		conditions := make([]string, len(sel.assets))
+		for j, asset := range sel.assets {
+			conditions[j] = fmt.Sprintf("(address='%s' AND blockchain='%s')", escapeInfluxString(asset.Address), escapeInfluxString(asset.Blockchain))
+		}
+		statements = append(statements, fmt.Sprintf(
+			"SELECT last(price) FROM %s WHERE time>%d AND time<=%d AND (%s) GROUP BY \"address\",\"blockchain\",\"symbol\",\"name\"",
+			influxDBAssetQuotationsTable,
+			sel.timestamp.Add(-assetQuotationLookback).UnixNano(),
+			sel.timestamp.UnixNano(),
+			strings.Join(conditions, " OR "),
+		))
+		statementSelects = append(statementSelects, i)
+	}
+	if len(statements) == 0 {
+		return quotations, nil
+	}
+
+	res, err := queryInfluxDB(datastore.influxClient, strings.Join(statements, ";"))
+	if err != nil {
+		return quotations, err
+	}
+	for i, result := range res {
+		if i >= len(statementSelects) {
+			break
+		}
+		if err := parseAssetQuotations(result.Series, quotations[statementSelects[i]]); err != nil {
+			return quotations, err
+		}
+	}
+	return quotations, nil
+}
+
+// parseAssetQuotations adds the quotations in @series to @quotations.
+func parseAssetQuotations(series []influxModels.Row, quotations map[string]*AssetQuotation) (err error) {
+	for _, row := range series {
+		if len(row.Values) == 0 || len(row.Values[0]) < 2 {
+			continue
+		}
+		var quotation AssetQuotation
+		quotation.Time, err = time.Parse(time.RFC3339, row.Values[0][0].(string))
+		if err != nil {
+			return
+		}
+		quotation.Price, err = row.Values[0][1].(json.Number).Float64()
+		if err != nil {
+			return
+		}
+		quotation.Asset = dia.Asset{
+			Symbol:     row.Tags["symbol"],
+			Name:       row.Tags["name"],
+			Address:    row.Tags["address"],
+			Blockchain: row.Tags["blockchain"],
+		}
+		quotation.Source = dia.Diadata
+
+		// An asset can have several series if its symbol or name changed. Keep the latest.
+		key := getKeyAssetQuotation(quotation.Asset.Blockchain, quotation.Asset.Address)
+		if previous, ok := quotations[key]; !ok || previous.Time.Before(quotation.Time) {
+			quotations[key] = &quotation
+		}
+	}
+	return
+}
+
+// escapeInfluxString escapes @s for use in a single quoted string literal of an influx query.
+func escapeInfluxString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+// ------------------------------------------------------------------------------
+// POINT IN TIME QUOTATIONS
+// ------------------------------------------------------------------------------
+
+// ErrStaleQuotation is returned for quotations older than the requested staleness bound
+// under QuotationPolicyFailIfStale.
+var ErrStaleQuotation = errors.New("quotation is stale")
+
+// ParseQuotationPolicy returns the policy with name @s. The empty string yields QuotationPolicyLOCF.
+func ParseQuotationPolicy(s string) (QuotationPolicy, error) {
+	switch QuotationPolicy(s) {
+	case "", QuotationPolicyLOCF:
+		return QuotationPolicyLOCF, nil
+	case QuotationPolicyInterpolate, QuotationPolicyFailIfStale:
+		return QuotationPolicy(s), nil
+	}
+	return "", fmt.Errorf("unknown quotation policy %s", s)
+}
+
+// GetAssetQuotationAt returns the quotation of @asset at @timestamp derived according to @policy.
+// Price points older than @maxStaleness are marked as stale. A non-positive @maxStaleness disables the bound.
+func (datastore *DB) GetAssetQuotationAt(asset dia.Asset, timestamp time.Time, maxStaleness time.Duration, policy QuotationPolicy) (*PointInTimeQuotation, error) {
+	q := fmt.Sprintf("SELECT price FROM %s WHERE address='%s' AND blockchain='%s' AND time<=%d ORDER BY DESC LIMIT 1", influxDBAssetQuotationsTable, escapeInfluxString(asset.Address), escapeInfluxString(asset.Blockchain), timestamp.UnixNano())
+	previous, err := datastore.getQuotationPoint(q)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, errors.New("no assetQuotation in influx")
+	}
+
+	var next *QuotationPoint
+	if policy == QuotationPolicyInterpolate && previous.Time.Before(timestamp) {
+		q = fmt.Sprintf("SELECT price FROM %s WHERE address='%s' AND blockchain='%s' AND time>%d ORDER BY ASC LIMIT 1", influxDBAssetQuotationsTable, escapeInfluxString(asset.Address), escapeInfluxString(asset.Blockchain), timestamp.UnixNano())
+		next, err = datastore.getQuotationPoint(q)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	quotation, err := pointInTimeQuotation(timestamp, maxStaleness, policy, *previous, next)
+	if err != nil {
+		return nil, err
+	}
+	quotation.Asset = asset
+	return quotation, nil
+}
+
+// pointInTimeQuotation derives the quotation at @timestamp from the last price point @previous at or
+// before @timestamp and the first price point @next after @timestamp, which may be nil.
+func pointInTimeQuotation(timestamp time.Time, maxStaleness time.Duration, policy QuotationPolicy, previous QuotationPoint, next *QuotationPoint) (*PointInTimeQuotation, error) {
+	quotation := &PointInTimeQuotation{
+		AssetQuotation: AssetQuotation{
+			Price:  previous.Price,
+			Source: dia.Diadata,
+			Time:   previous.Time,
+		},
+		RequestTime:         timestamp,
+		Policy:              policy,
+		Filter:              dia.FilterKing,
+		MaxStalenessSeconds: maxStaleness.Seconds(),
+		Points:              []QuotationPoint{previous},
+	}
+	age := timestamp.Sub(previous.Time)
+
+	if policy == QuotationPolicyInterpolate && next != nil && age > 0 {
+		ageNext := next.Time.Sub(timestamp)
+		if maxStaleness <= 0 || (age <= maxStaleness && ageNext <= maxStaleness) {
+			weight := age.Seconds() / next.Time.Sub(previous.Time).Seconds()
+			quotation.Price = previous.Price + weight*(next.Price-previous.Price)
+			quotation.Time = timestamp
+			quotation.Interpolated = true
+			quotation.Points = append(quotation.Points, *next)
+			if ageNext > age {
+				age = ageNext
+			}
+		}
+	}
+
+	quotation.AgeSeconds = age.Seconds()
+	quotation.Stale = maxStaleness > 0 && age > maxStaleness
+	if quotation.Stale && policy == QuotationPolicyFailIfStale {
+		return nil, fmt.Errorf("%w: age of %v exceeds %v", ErrStaleQuotation, age, maxStaleness)
+	}
+	return quotation, nil
+}
+
+// getQuotationPoint returns the price point returned by the influx query @q, or nil if there is none.
+func (datastore *DB) getQuotationPoint(q string) (*QuotationPoint, error) {
+	res, err := queryInfluxDB(datastore.influxClient, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 || len(res[0].Series[0].Values) == 0 {
+		return nil, nil
+	}
+	var point QuotationPoint
+	point.Time, err = time.Parse(time.RFC3339, res[0].Series[0].Values[0][0].(string))
+	if err != nil {
+		return nil, err
+	}
+	point.Price, err = res[0].Series[0].Values[0][1].(json.Number).Float64()
+	if err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
 // ------------------------------------------------------------------------------
 // MARKET MEASURES
 // ------------------------------------------------------------------------------
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	influxModels "github.com/influxdata/influxdb1-client/models"
+)
+
+func TestPointInTimeQuotation(t *testing.T) {
+	timestamp := time.Unix(1640995200, 0)
+	previous := QuotationPoint{Price: 100, Time: timestamp.Add(-30 * time.Second)}
+	next := &QuotationPoint{Price: 200, Time: timestamp.Add(90 * time.Second)}
+
+	tables := []struct {
+		policy       QuotationPolicy
+		maxStaleness time.Duration
+		next         *QuotationPoint
+		price        float64
+		age          float64
+		stale        bool
+		interpolated bool
+		err          error
+	}{
+		{QuotationPolicyLOCF, 0, next, 100, 30, false, false, nil},
+		{QuotationPolicyLOCF, 10 * time.Second, next, 100, 30, true, false, nil},
+		{QuotationPolicyInterpolate, 0, next, 125, 90, false, true, nil},
+		{QuotationPolicyInterpolate, 60 * time.Second, next, 100, 30, false, false, nil},
+		{QuotationPolicyInterpolate, 0, nil, 100, 30, false, false, nil},
+		{QuotationPolicyFailIfStale, 60 * time.Second, next, 100, 30, false, false, nil},
+		{QuotationPolicyFailIfStale, 10 * time.Second, next, 0, 0, false, false, ErrStaleQuotation},
+	}
+	for _, table := range tables {
+		quotation, err := pointInTimeQuotation(timestamp, table.maxStaleness, table.policy, previous, table.next)
+		if table.err != nil {
+			if !errors.Is(err, table.err) {
+				t.Errorf("error for policy %s was incorrect, got: %v, want: %v.", table.policy, err, table.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for policy %s: %v", table.policy, err)
+			continue
+		}
+		if quotation.Price != table.price || quotation.AgeSeconds != table.age || quotation.Stale != table.stale || quotation.Interpolated != table.interpolated {
+			t.Errorf("quotation for policy %s was incorrect, got: %v %v %v %v, want: %v %v %v %v.", table.policy, quotation.Price, quotation.AgeSeconds, quotation.Stale, quotation.Interpolated, table.price, table.age, table.stale, table.interpolated)
+		}
+	}
+}
+
+func TestParseAssetQuotations(t *testing.T) {
+	tags := func(symbol string) map[string]string {
+		return map[string]string{"address": "0x6B175474E89094C44Da98b954EedeAC495271d0F", "blockchain": "Ethereum", "symbol": symbol, "name": "Dai"}
+	}
+	series := []influxModels.Row{
+		{Tags: tags("SAI"), Values: [][]interface{}{{"2022-01-01T00:00:00Z", json.Number("1.01")}}},
+		{Tags: tags("DAI"), Values: [][]interface{}{{"2022-01-02T00:00:00Z", json.Number("1.002")}}},
+		{Tags: tags("DAI"), Values: [][]interface{}{}},
+	}
+	quotations := make(map[string]*AssetQuotation)
+	if err := parseAssetQuotations(series, quotations); err != nil {
+		t.Fatal(err)
+	}
+	quotation := quotations[getKeyAssetQuotation("Ethereum", "0x6B175474E89094C44Da98b954EedeAC495271d0F")]
+	if len(quotations) != 1 || quotation == nil || quotation.Asset.Symbol != "DAI" || quotation.Price != 1.002 {
+		t.Errorf("quotations were incorrect, got: %v.", quotations)
+	}
+}
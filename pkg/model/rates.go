@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/diadata-org/diadata/internal/pkg/calendars"
 	"github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
 	"math"
 	"sort"
@@ -258,19 +259,21 @@ func (datastore *DB) GetCompoundedRate(symbol string, dateInit, date time.Time,
 		return &InterestRate{}, err
 	}
 
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
+	holidays, err := rateHolidays(symbol, ratesAPI, dateInit, date)
+	if err != nil {
+		return &InterestRate{}, err
 	}
-	holidays := utils.GetHolidays(existDates, dateInit, date)
 
 	// Sort ratesApi (type []*InterestRates) in increasing order according to date
-	// and remove the data for the final date, as only past values are compounded.
+	// and remove the data from the final date on, as only past values are compounded.
 	sort.Slice(ratesAPI, func(i, j int) bool {
 		return (ratesAPI[i].EffectiveDate).Before(ratesAPI[j].EffectiveDate)
 	})
-	ratesAPI = ratesAPI[:len(ratesAPI)-1]
+	ratesAPI = ratesBefore(ratesAPI, date)
+	if len(ratesAPI) == 0 {
+		err = errors.New("no rate information for this period")
+		return &InterestRate{}, err
+	}
 
 	// Extract rates' values
 	rates := []float64{}
@@ -305,6 +308,116 @@ func (datastore *DB) GetCompoundedRate(symbol string, dateInit, date time.Time,
 	return ir, nil
 }
 
+// CompoundedRateInArrears is a rate compounded in arrears over an interest period with an explicit convention.
+type CompoundedRateInArrears struct {
+	Symbol      string
+	Value       float64
+	Factor      float64
+	DateInit    time.Time
+	DateFinal   time.Time
+	Convention  ratederivatives.Convention
+	Shift       int
+	DaysPerYear int
+	// ObservationStart and ObservationEnd delimit the days whose rates were compounded.
+	ObservationStart time.Time
+	ObservationEnd   time.Time
+	// IBOR, Tenor and SpreadAdjustment are only set for IBOR fallback rates, whose Value includes the spread
+	// adjustment. CompoundedValue is the compounded rate without it.
+	IBOR             string  `json:",omitempty"`
+	Tenor            string  `json:",omitempty"`
+	SpreadAdjustment float64 `json:",omitempty"`
+	CompoundedValue  float64 `json:",omitempty"`
+	Source           string
+}
+
+// GetCompoundedRateInArrears returns the rate @symbol compounded in arrears from @dateInit to @dateFinal with
+// the @convention and a shift of @shift business days. As for GetCompoundedRate, days without an entry in the
+// database are assumed to be holidays.
+func (datastore *DB) GetCompoundedRateInArrears(symbol string, dateInit, dateFinal time.Time, convention ratederivatives.Convention, shift int, daysPerYear int) (*CompoundedRateInArrears, error) {
+	firstPublication, err := datastore.GetFirstDate(symbol)
+	if err != nil {
+		return &CompoundedRateInArrears{}, err
+	}
+	if utils.AfterDay(firstPublication, dateInit) {
+		return &CompoundedRateInArrears{}, fmt.Errorf("%w: dateInit cannot be earlier than first publication date", ratederivatives.ErrInvalidPeriod)
+	}
+
+	// Fetch enough calendar days before @dateInit to cover the shift over weekends and holidays,
+	// but none before the first publication.
+	fetchInit := dateInit.AddDate(0, 0, -(2*shift + 10))
+	if fetchInit.Before(firstPublication) {
+		fetchInit = firstPublication
+	}
+	ratesAPI, err := datastore.GetInterestRateRange(symbol, fetchInit.Format("2006-01-02"), dateFinal.Format("2006-01-02"))
+	if err != nil {
+		return &CompoundedRateInArrears{}, err
+	}
+	if len(ratesAPI) == 0 {
+		return &CompoundedRateInArrears{}, errors.New("no rate information for this period")
+	}
+	fixings := make([]ratederivatives.Fixing, len(ratesAPI))
+	for i, rate := range ratesAPI {
+		fixings[i] = ratederivatives.Fixing{Date: rate.EffectiveDate, Value: rate.Value}
+	}
+	// Missing fixings would be taken as holidays, which is only safe where the calendar of @symbol says so.
+	if err = checkFixings(symbol, ratesAPI, dateInit, dateFinal, shift, firstPublication); err != nil {
+		return &CompoundedRateInArrears{}, err
+	}
+
+	result, err := ratederivatives.CompoundInArrears(fixings, dateInit, dateFinal, convention, shift, daysPerYear)
+	if err != nil {
+		return &CompoundedRateInArrears{}, err
+	}
+	return &CompoundedRateInArrears{
+		Symbol:           symbol + "_compounded_in_arrears_by_DIA",
+		Value:            result.Rate,
+		Factor:           result.Factor,
+		DateInit:         dateInit,
+		DateFinal:        dateFinal,
+		Convention:       convention,
+		Shift:            shift,
+		DaysPerYear:      daysPerYear,
+		ObservationStart: result.ObservationStart,
+		ObservationEnd:   result.ObservationEnd,
+		Source:           ratesAPI[0].Source,
+	}, nil
+}
+
+// GetIBORFallbackRate returns the ISDA fallback rate of @ibor for @tenor over the interest period from @dateInit
+// to @dateFinal, that is the risk-free rate of @ibor compounded in arrears plus the spread adjustment of @tenor.
+// A zero @dateFinal defaults to the end of @tenor. @convention and @shift default to the ISDA observation shift
+// of 2 business days if @convention is empty.
+func (datastore *DB) GetIBORFallbackRate(ibor, tenor string, dateInit, dateFinal time.Time, convention ratederivatives.Convention, shift int) (*CompoundedRateInArrears, error) {
+	fallback, ok := ratederivatives.IBORFallbacks[ibor]
+	if !ok {
+		return &CompoundedRateInArrears{}, fmt.Errorf("no fallback for %s", ibor)
+	}
+	spread, err := fallback.SpreadAdjustment(tenor)
+	if err != nil {
+		return &CompoundedRateInArrears{}, err
+	}
+	if dateFinal.IsZero() {
+		if dateFinal, err = ratederivatives.TenorEnd(dateInit, tenor); err != nil {
+			return &CompoundedRateInArrears{}, err
+		}
+	}
+	if convention == "" {
+		convention, shift = ratederivatives.ConventionObservationShift, ratederivatives.FallbackShift
+	}
+
+	rate, err := datastore.GetCompoundedRateInArrears(fallback.RFR, dateInit, dateFinal, convention, shift, fallback.DaysPerYear)
+	if err != nil {
+		return &CompoundedRateInArrears{}, err
+	}
+	rate.Symbol = ibor + "_" + tenor + "_fallback_by_DIA"
+	rate.IBOR = ibor
+	rate.Tenor = tenor
+	rate.SpreadAdjustment = spread
+	rate.CompoundedValue = rate.Value
+	rate.Value += spread
+	return rate, nil
+}
+
 // GetCompoundedIndex returns the compounded index over the maximal period of existence of @symbol
 func (datastore *DB) GetCompoundedIndex(symbol string, date time.Time, daysPerYear int, rounding int) (*InterestRate, error) {
 	// Get initial date for the rate with @symbol
@@ -312,6 +425,12 @@ func (datastore *DB) GetCompoundedIndex(symbol string, date time.Time, daysPerYe
 	if err != nil {
 		return &InterestRate{}, err
 	}
+	// The index is only defined on business days, other days take the value of the next business day.
+	if calendar, err := calendars.ForRate(symbol); err == nil && calendar.Covers(date) {
+		if date, err = calendar.Roll(date, calendars.Following); err != nil {
+			return &InterestRate{}, err
+		}
+	}
 	return datastore.GetCompoundedRate(symbol, dateInit, date, daysPerYear, rounding)
 }
 
@@ -343,12 +462,10 @@ func (datastore *DB) GetCompoundedIndexRange(symbol string, dateInit, dateFinal
 		return (ratesAPI[i].EffectiveDate).Before(ratesAPI[j].EffectiveDate)
 	})
 
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
+	holidays, err := rateHolidays(symbol, ratesAPI, dateInit, dateFinal)
+	if err != nil {
+		return
 	}
-	holidays := utils.GetHolidays(existDates, firstPublication, dateFinal)
 
 	// Consider previous business day if @dateFinal is holiday or weekend
 	for utils.ContainsDay(holidays, dateFinal) || !utils.CheckWeekDay(dateFinal) {
@@ -486,12 +603,10 @@ func (datastore *DB) GetCompoundedAvgRange(symbol string, dateInit, dateFinal ti
 
 	// Check, whether first day is a holiday or weekend. If so, prepend rate of
 	// preceding business day (outside the considered time range!).
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
+	holidays, err := rateHolidays(symbol, ratesAPI, dateStart, dateFinal)
+	if err != nil {
+		return
 	}
-	holidays := utils.GetHolidays(existDates, dateStart, dateFinal)
 	if utils.ContainsDay(holidays, dateStart) || !utils.CheckWeekDay(dateStart) {
 		firstRate, err := datastore.GetInterestRate(symbol, dateStart.Format("2006-01-02"))
 		if err != nil {
@@ -506,11 +621,15 @@ func (datastore *DB) GetCompoundedAvgRange(symbol string, dateInit, dateFinal ti
 	}
 
 	// Sort ratesApi (type []*InterestRates) in increasing order according to date
-	// and remove the data for the final date, as only past values are compounded.
+	// and remove the data from the final date on, as only past values are compounded.
 	sort.Slice(ratesAPI, func(i, j int) bool {
 		return (ratesAPI[i].EffectiveDate).Before(ratesAPI[j].EffectiveDate)
 	})
-	ratesAPI = ratesAPI[:len(ratesAPI)-1]
+	ratesAPI = ratesBefore(ratesAPI, dateFinal)
+	if len(ratesAPI) == 0 {
+		err = errors.New("no rate information for this period")
+		return []*InterestRate{}, err
+	}
 
 	// Iterate through interest periods of length @calDays
 	cursor := 0
@@ -628,12 +747,10 @@ func (datastore *DB) GetCompoundedAvgDIARange(symbol string, dateInit, dateFinal
 
 	// Check, whether first day is a holiday or weekend. If so, prepend rate of
 	// preceding business day (outside the considered time range!).
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
+	holidays, err := rateHolidays(symbol, ratesAPI, dateStart, dateFinal)
+	if err != nil {
+		return
 	}
-	holidays := utils.GetHolidays(existDates, dateStart, dateFinal)
 	if utils.ContainsDay(holidays, dateStart) || !utils.CheckWeekDay(dateStart) {
 		firstRate, err := datastore.GetInterestRate(symbol, dateStart.Format("2006-01-02"))
 		if err != nil {
@@ -648,11 +765,15 @@ func (datastore *DB) GetCompoundedAvgDIARange(symbol string, dateInit, dateFinal
 	}
 
 	// Sort ratesApi (type []*InterestRates) in increasing order according to date
-	// and remove the data for the final date, as only past values are compounded.
+	// and remove the data from the final date on, as only past values are compounded.
 	sort.Slice(ratesAPI, func(i, j int) bool {
 		return (ratesAPI[i].EffectiveDate).Before(ratesAPI[j].EffectiveDate)
 	})
-	ratesAPI = ratesAPI[:len(ratesAPI)-1]
+	ratesAPI = ratesBefore(ratesAPI, dateFinal)
+	if len(ratesAPI) == 0 {
+		err = errors.New("no rate information for this period")
+		return []*InterestRate{}, err
+	}
 
 	// get a rate for each calendar day in period of interest
 	mapRates := StraightRates(ratesAPI)
@@ -701,6 +822,77 @@ func (datastore *DB) GetCompoundedAvgDIARange(symbol string, dateInit, dateFinal
 // Auxiliary functions
 // ---------------------------------------------------------------------------------------
 
+// rateHolidays returns the holidays of @symbol from @dateInit to @dateFinal that are not on a weekend. They are
+// taken from the business day calendar of @symbol. Holidays on days the calendar does not cover, or of rates
+// without a calendar, are inferred from missing entries in @ratesAPI. An error is returned if the calendars
+// cannot be loaded.
+func rateHolidays(symbol string, ratesAPI []*InterestRate, dateInit, dateFinal time.Time) ([]time.Time, error) {
+	existDates := []time.Time{}
+	for _, entry := range ratesAPI {
+		existDates = append(existDates, (*entry).EffectiveDate)
+	}
+	inferred := utils.GetHolidays(existDates, dateInit, dateFinal)
+
+	calendar, err := calendars.ForRate(symbol)
+	if errors.Is(err, calendars.ErrNoCalendar) {
+		return inferred, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	holidays := calendar.HolidaysBetween(dateInit, dateFinal)
+	for _, holiday := range inferred {
+		if !calendar.Covers(holiday) {
+			holidays = append(holidays, holiday)
+		}
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Before(holidays[j]) })
+	return holidays, nil
+}
+
+// checkFixings returns an error if a business day of @symbol from @shift business days before @dateInit to
+// before @dateFinal has no entry in @ratesAPI, i.e. its publication is late. The days before @dateInit are the
+// lookback or observation window of the first days of the period. Days before @firstPublication are left to
+// the compounding to reject. Only days covered by the calendar of @symbol are checked, rates without a calendar
+// are not.
+func checkFixings(symbol string, ratesAPI []*InterestRate, dateInit, dateFinal time.Time, shift int, firstPublication time.Time) error {
+	calendar, err := calendars.ForRate(symbol)
+	if errors.Is(err, calendars.ErrNoCalendar) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	existDates := []time.Time{}
+	for _, entry := range ratesAPI {
+		existDates = append(existDates, (*entry).EffectiveDate)
+	}
+	start := dateInit
+	if observationStart, err := calendar.AddBusinessDays(dateInit, -shift); err == nil {
+		start = observationStart
+	}
+	if utils.AfterDay(firstPublication, start) {
+		start = firstPublication
+	}
+	for date := start; utils.AfterDay(dateFinal, date); date = date.AddDate(0, 0, 1) {
+		if calendar.Covers(date) && calendar.IsBusinessDay(date) && !utils.ContainsDay(existDates, date) {
+			return fmt.Errorf("no %s fixing on business day %s", symbol, date.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// ratesBefore returns the rates in @ratesAPI with an effective date before @date.
+func ratesBefore(ratesAPI []*InterestRate, date time.Time) []*InterestRate {
+	before := []*InterestRate{}
+	for _, rate := range ratesAPI {
+		if utils.AfterDay(date, rate.EffectiveDate) {
+			before = append(before, rate)
+		}
+	}
+	return before
+}
+
 // ExistInterestRate returns true if a database entry with given date stamp exists,
 // and false otherwise.
 // @date should be a substring of a string formatted as "yyyy-mm-dd hh:mm:ss".
@@ -2,7 +2,9 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -19,7 +21,9 @@ type RelDatastore interface {
 	// --- Assets methods ---
 	// --------- Persistent ---------
 	SetAsset(asset dia.Asset) error
+	SetLPToken(pool *dia.Pool) error
 	GetAsset(address, blockchain string) (dia.Asset, error)
+	GetAssetsByAddress(assets []dia.Asset) ([]dia.Asset, error)
 	GetAssetByID(ID string) (dia.Asset, error)
 	GetAssetsBySymbolName(symbol, name string) ([]dia.Asset, error)
 	GetAllAssets(blockchain string) ([]dia.Asset, error)
@@ -30,6 +34,7 @@ type RelDatastore interface {
 	Count() (uint32, error)
 	SetAssetVolume24H(asset dia.Asset, volume float64) error
 	GetAssetVolume24H(asset dia.Asset) (float64, error)
+	GetAssetVolumes24H(assets []dia.Asset) (map[string]float64, error)
 	GetAssetsWithVOL(numAssets int64, substring string) ([]dia.Asset, error)
 	SetAggregatedVolume(aggVol dia.AggregatedVolume) error
 	GetAggregatedVolumes(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.AggregatedVolume, error)
@@ -84,9 +89,25 @@ type RelDatastore interface {
 	GetNFTTradesFromTable(address string, blockchain string, tokenID string, starttime time.Time, endtime time.Time, table string) ([]dia.NFTTrade, error)
 	GetNFTOffers(address string, blockchain string, tokenID string) ([]dia.NFTOffer, error)
 	GetNFTBids(address string, blockchain string, tokenID string) ([]dia.NFTBid, error)
-	GetNFTFloor(nftclass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration) (float64, error)
-	GetNFTFloorRecursive(nftClass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (float64, error)
-	GetNFTFloorRange(nftClass dia.NFTClass, starttime time.Time, endtime time.Time, floorWindowSeconds time.Duration, stepBackLimit int) ([]float64, error)
+	GetNFTClassTrades(nftClass dia.NFTClass, starttime time.Time, endtime time.Time) ([]dia.NFTTrade, error)
+	GetNFTFloor(nftclass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration) (float64, dia.Asset, error)
+	GetNFTFloorEstimate(nftClass dia.NFTClass, timestamp time.Time, floorWindow time.Duration, quantile float64, stepBackLimit int) (NFTFloorEstimate, error)
+	GetNFTFloorRecursive(nftClass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (float64, dia.Asset, error)
+	GetNFTFloorRange(nftClass dia.NFTClass, starttime time.Time, endtime time.Time, floorWindowSeconds time.Duration, stepBackLimit int) ([]float64, dia.Asset, error)
+	GetNFTsByClass(nftClass dia.NFTClass) ([]dia.NFT, error)
+	AppraiseNFTClass(nftClass dia.NFTClass, timestamp time.Time) ([]NFTValuation, error)
+	SetNFTValuation(valuation NFTValuation) error
+	SetNFTValuations(valuations []NFTValuation) error
+	GetNFTValuation(address string, blockchain string, tokenID string, timestamp time.Time) (NFTValuation, error)
+	ComputeNFTClassRarity(nftClass dia.NFTClass, timestamp time.Time) ([]NFTRarity, error)
+	SetNFTRarity(rarity NFTRarity) error
+	GetNFTRarity(address string, blockchain string, tokenID string) (NFTRarity, error)
+	GetNFTClassRarity(nftClass dia.NFTClass, method string, limit, offset uint64) ([]NFTRarity, int, error)
+	ComputeNFTClassMarketStats(nftClass dia.NFTClass, interval string, starttime time.Time) (NFTMarketStats, error)
+	SetNFTMarketStats(stats NFTMarketStats) error
+	GetNFTMarketStats(nftClass dia.NFTClass, interval string, starttime time.Time, endtime time.Time) ([]NFTMarketStats, error)
+	UpdateNFTHolderBalances(nftClass dia.NFTClass, deltas map[string]int64, stateName string, state ScraperState) error
+	GetNFTHolderCount(nftClass dia.NFTClass) (int, error)
 	GetLastBlockheightTopshot(upperBound time.Time) (uint64, error)
 	SetNFTBid(bid dia.NFTBid) error
 	GetLastNFTBid(address string, blockchain string, tokenID string, blockNumber uint64, blockPosition uint) (dia.NFTBid, error)
@@ -95,6 +116,33 @@ type RelDatastore interface {
 	GetLastBlockNFTTrade(nftclass dia.NFTClass) (uint64, error)
 	SetNFTOffer(offer dia.NFTOffer) error
 	GetLastNFTOffer(address string, blockchain string, tokenID string, blockNumber uint64, blockPosition uint) (offer dia.NFTOffer, err error)
+	ApplyNFTOrderEvent(event dia.NFTOrderEvent) (bool, error)
+	GetNFTOrders(address string, blockchain string, tokenID string, status string) ([]NFTOrder, error)
+	GetNFTOrder(nftClass dia.NFTClass, marketplace string, orderID string) (NFTOrder, error)
+	GetNFTClassOpenOrders(nftClass dia.NFTClass) ([]NFTOrder, error)
+	ExpireNFTOrders(timestamp time.Time) (int64, error)
+	SetNFTOracleUpdate(update NFTOracleUpdate) error
+	SetNFTOracleUpdateStatus(oracle string, chainID int64, txHash string, status string) error
+	GetLastNFTOracleUpdate(oracle string, chainID int64, key string) (NFTOracleUpdate, error)
+	GetNFTOracleUpdates(oracle string, chainID int64, key string, starttime time.Time, endtime time.Time) ([]NFTOracleUpdate, error)
+
+	// DeFi lending positions
+	UpdateDefiPositions(protocol string, changes []DefiPositionChange) error
+	GetDefiBorrowerPositions(protocol string) ([]DefiPosition, error)
+	SetDefiLiquidations(liquidations []DefiLiquidation) error
+	SetDefiPositionEvents(protocol string, changes []DefiPositionChange, liquidations []DefiLiquidation, scraperName string, state ScraperState) error
+	GetDefiLiquidations(protocol string, starttime time.Time, endtime time.Time) ([]DefiLiquidation, error)
+
+	// API key methods
+	SetAPIPlan(plan APIPlan) error
+	GetAPIPlan(name string) (APIPlan, error)
+	CreateAPIKey(owner string, planName string) (string, APIKey, error)
+	GetAPIKey(key string) (APIKey, error)
+	RevokeAPIKey(keyID string) error
+	TakeRateLimitToken(subject string, route string, limit RouteLimit) (RateLimitResult, error)
+	IncrementAPIUsage(subject string, endpoint string, dailyQuota int64) (int64, error)
+	FlushAPIUsage(date time.Time) error
+	GetAPIUsage(keyID string, starttime time.Time, endtime time.Time) ([]APIUsage, error)
 
 	// General methods
 	GetKeys(table string) ([]string, error)
@@ -135,12 +183,82 @@ const (
 	NfttradeSumeriaTable = "nfttradesumeria"
 	nftbidTable          = "nftbid"
 	nftofferTable        = "nftoffer"
+	nftvaluationTable    = "nftvaluation"
+	nftrarityTable       = "nftrarity"
+	nftmarketstatsTable  = "nftmarketstats"
+	nftholderTable       = "nftholder"
+	nftorderTable        = "nftorder"
+	nftoracleupdateTable = "nftoracleupdate"
+	defipositionTable    = "defiposition"
+	defiliquidationTable = "defiliquidation"
 	scrapersTable        = "scrapers"
 
+	apiplanTable  = "apiplan"
+	apikeyTable   = "apikey"
+	apiusageTable = "apiusage"
+
 	// time format for blockchain genesis dates
 	// timeFormatBlockchain = "2006-01-02"
 )
 
+// ErrUnknownTable is returned for table names which are not in the whitelist of a RelDB method.
+var ErrUnknownTable = errors.New("unknown table")
+
+// relDBTables is the whitelist of postgres tables a RelDB method can be asked to operate on.
+// Table names cannot be passed as query parameters, so they are checked against it before
+// being formatted into a query.
+var relDBTables = map[string]bool{
+	assetTable:              true,
+	exchangepairTable:       true,
+	exchangesymbolTable:     true,
+	blockchainTable:         true,
+	assetVolumeTable:        true,
+	aggregatedVolumeTable:   true,
+	tradesDistributionTable: true,
+	blockdataTable:          true,
+	nftcategoryTable:        true,
+	nftclassTable:           true,
+	nftTable:                true,
+	nfttradeTable:           true,
+	NfttradeCurrTable:       true,
+	NfttradeSumeriaTable:    true,
+	nftbidTable:             true,
+	nftofferTable:           true,
+	nftvaluationTable:       true,
+	nftrarityTable:          true,
+	nftmarketstatsTable:     true,
+	nftholderTable:          true,
+	nftorderTable:           true,
+	nftoracleupdateTable:    true,
+	defipositionTable:       true,
+	defiliquidationTable:    true,
+	scrapersTable:           true,
+	apiplanTable:            true,
+	apikeyTable:             true,
+	apiusageTable:           true,
+}
+
+// nftTradeTables are the tables nft trades can be stored in.
+var nftTradeTables = map[string]bool{
+	nfttradeTable:        true,
+	NfttradeCurrTable:    true,
+	NfttradeSumeriaTable: true,
+}
+
+// checkTable returns ErrUnknownTable if @table is not in @whitelist.
+func checkTable(table string, whitelist map[string]bool) error {
+	if !whitelist[table] {
+		return fmt.Errorf("%w: %q", ErrUnknownTable, table)
+	}
+	return nil
+}
+
+// likePrefix returns a LIKE pattern matching all strings beginning with @prefix.
+// Wildcards in @prefix are escaped, such that they match literally.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
 // RelDB is a relative database with redis caching layer.
 type RelDB struct {
 	URI            string
@@ -160,6 +278,11 @@ func NewPostgresDataStore() (*RelDB, error) {
 	return NewRelDataStoreWithOptions(true, false)
 }
 
+// NewPostgresDataStoreWithPool returns a datastore without redis caching layer which uses the postgres client @pool.
+func NewPostgresDataStoreWithPool(pool *pgxpool.Pool) *RelDB {
+	return &RelDB{"", pool, nil, 32}
+}
+
 // NewCachingLayer returns a datastore with redis caching layer and without postgres client.
 func NewCachingLayer() (*RelDB, error) {
 	return NewRelDataStoreWithOptions(false, true)
@@ -183,8 +306,11 @@ func NewRelDataStoreWithOptions(withPostgres bool, withRedis bool) (*RelDB, erro
 
 // GetKeys returns a slice of strings holding the names of the keys of @table in postgres
 func (rdb *RelDB) GetKeys(table string) (keys []string, err error) {
-	query := fmt.Sprintf("SELECT column_name from information_schema.columns WHERE table_name='%s'", table)
-	rows, err := rdb.postgresClient.Query(context.Background(), query)
+	if err = checkTable(table, relDBTables); err != nil {
+		return
+	}
+	query := "SELECT column_name from information_schema.columns WHERE table_name=$1"
+	rows, err := rdb.postgresClient.Query(context.Background(), query, table)
 	if err != nil {
 		return
 	}
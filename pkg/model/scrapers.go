@@ -2,7 +2,10 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
+
+	"github.com/jackc/pgx/v4"
 )
 
 // ScraperConfig is a JSON compatible struct to keep the configuration of a scraper
@@ -16,7 +19,7 @@ func (rdb *RelDB) GetScraperState(ctx context.Context, scraperName string, state
 }
 
 func (rdb *RelDB) SetScraperState(ctx context.Context, scraperName string, state ScraperState) error {
-	_, err := rdb.postgresClient.Exec(ctx, fmt.Sprintf("insert into %s(name, state) values($1, $2) on conflict(name) do update set state=excluded.state", scrapersTable),
+	_, err := rdb.postgresClient.Exec(ctx, setScraperStateQuery(),
 		scraperName,
 		state,
 	)
@@ -24,6 +27,39 @@ func (rdb *RelDB) SetScraperState(ctx context.Context, scraperName string, state
 	return err
 }
 
+func setScraperStateQuery() string {
+	return fmt.Sprintf("insert into %s(name, state) values($1, $2) on conflict(name) do update set state=excluded.state", scrapersTable)
+}
+
+// sendBatchWithScraperState executes the queries in @batch followed by storing @state of @scraperName in a
+// single transaction. Scrapers applying incremental changes use it, so that a change is never applied without
+// the state recording it, nor the other way around.
+func (rdb *RelDB) sendBatchWithScraperState(ctx context.Context, batch *pgx.Batch, scraperName string, state ScraperState) error {
+	batch.Queue(setScraperStateQuery(), scraperName, state)
+
+	tx, err := rdb.postgresClient.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			log.Errorf("rollback state of %s: %v", scraperName, rollbackErr)
+		}
+	}()
+
+	results := tx.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err = results.Exec(); err != nil {
+			results.Close()
+			return err
+		}
+	}
+	if err = results.Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 func (rdb *RelDB) GetScraperConfig(ctx context.Context, scraperName string, config ScraperConfig) error {
 	return rdb.postgresClient.QueryRow(ctx, fmt.Sprintf("select conf from %s where name=$1", scrapersTable), scraperName).Scan(config)
 }
@@ -102,6 +102,45 @@ func (aq *AssetQuotation) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// QuotationPolicy determines how the quotation of an asset at a point in time
+// is derived from the stored price points around it.
+type QuotationPolicy string
+
+const (
+	// QuotationPolicyLOCF carries the last price point before the requested time forward.
+	QuotationPolicyLOCF QuotationPolicy = "locf"
+	// QuotationPolicyInterpolate interpolates linearly between the last price point before
+	// and the first price point after the requested time. It falls back to QuotationPolicyLOCF
+	// if there is no price point after the requested time within the staleness bound.
+	QuotationPolicyInterpolate QuotationPolicy = "interpolate"
+	// QuotationPolicyFailIfStale behaves like QuotationPolicyLOCF, but fails if the price
+	// point is older than the staleness bound.
+	QuotationPolicyFailIfStale QuotationPolicy = "failIfStale"
+)
+
+// QuotationPoint is a stored price point of an asset.
+type QuotationPoint struct {
+	Price float64
+	Time  time.Time
+}
+
+// PointInTimeQuotation is the quotation of an asset as of RequestTime, together with
+// the information needed to audit which price was consumed.
+type PointInTimeQuotation struct {
+	AssetQuotation
+	RequestTime time.Time
+	Policy      QuotationPolicy
+	// Filter is the filter that produced the underlying price points.
+	Filter string
+	// AgeSeconds is the distance between RequestTime and the farthest price point used.
+	AgeSeconds          float64
+	MaxStalenessSeconds float64
+	Stale               bool
+	Interpolated        bool
+	// Points are the stored price points the quotation is derived from.
+	Points []QuotationPoint
+}
+
 type AssetQuotationFull struct {
 	Symbol             string
 	Name               string